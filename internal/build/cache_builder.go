@@ -112,7 +112,7 @@ func (b CacheBuilder) CreateCacheFrom(ctx context.Context, inputs CacheInputs, s
 		return errors.Wrap(err, "CreateCacheFrom")
 	}
 
-	options := Options(dockerCtx, buildArgs)
+	options := Options(dockerCtx, model.DockerBuild{BuildArgs: buildArgs})
 	options.Tags = []string{cacheRef.String()}
 
 	// TODO(nick): I'm not sure if we should print this, or if it should
@@ -0,0 +1,78 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// If true, content-tag every Dockerfile build, as if it had content_tag=True,
+// and look for a matching image in the registry before building. Lets Tilt skip
+// the builds of anything that hasn't changed since the last session.
+//
+// Set by the --reuse-images flag.
+type ReuseImages bool
+
+// Hashes everything that goes into a docker build: the files in the build
+// context (including the Dockerfile), the build args, the target stage, and the platform.
+//
+// We deliberately skip file timestamps and ownership, so that the same
+// source tree hashes the same across checkouts and machines.
+func contentDigest(archive []byte, db model.DockerBuild) (digest.Digest, error) {
+	h := sha256.New()
+
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "contentDigest")
+		}
+
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00%o\x00%s\x00%d\x00",
+			header.Name, header.Typeflag, header.Mode, header.Linkname, header.Size)
+		_, err = io.Copy(h, tr)
+		if err != nil {
+			return "", errors.Wrap(err, "contentDigest")
+		}
+	}
+
+	keys := make([]string, 0, len(db.BuildArgs))
+	for k := range db.BuildArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(h, "arg\x00%s\x00%s\x00", k, db.BuildArgs[k])
+	}
+	_, _ = fmt.Fprintf(h, "target\x00%s\x00", db.TargetStage)
+	if db.Platform != "" {
+		_, _ = fmt.Fprintf(h, "platform\x00%s\x00", db.Platform)
+	}
+
+	return digest.NewDigestFromBytes(digest.SHA256, h.Sum(nil)), nil
+}
+
+func contentTaggedRef(ref reference.Named, archive []byte, db model.DockerBuild) (reference.NamedTagged, error) {
+	dig, err := contentDigest(archive, db)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := digestAsTag(dig)
+	if err != nil {
+		return nil, err
+	}
+	return reference.WithTag(ref, tag)
+}
@@ -0,0 +1,118 @@
+package build
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/dockerfile"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestContentDigestIgnoresTimestamps(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("a.txt", "hello")
+	df := dockerfile.Dockerfile("FROM alpine\nADD . .")
+	db := model.DockerBuild{BuildArgs: model.DockerBuildArgs{"foo": "bar"}}
+
+	d1 := contentDigestForTest(t, f, df, db)
+
+	past := time.Now().Add(-time.Hour)
+	err := os.Chtimes(f.JoinPath("a.txt"), past, past)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d2 := contentDigestForTest(t, f, df, db)
+	assert.Equal(t, d1, d2)
+}
+
+func TestContentDigestChanges(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("a.txt", "hello")
+	df := dockerfile.Dockerfile("FROM alpine\nADD . .")
+	db := model.DockerBuild{BuildArgs: model.DockerBuildArgs{"foo": "bar"}}
+	orig := contentDigestForTest(t, f, df, db)
+
+	f.WriteFile("a.txt", "goodbye")
+	assert.NotEqual(t, orig, contentDigestForTest(t, f, df, db))
+	f.WriteFile("a.txt", "hello")
+	assert.Equal(t, orig, contentDigestForTest(t, f, df, db))
+
+	assert.NotEqual(t, orig, contentDigestForTest(t, f, df.Join("RUN true"), db))
+	assert.NotEqual(t, orig, contentDigestForTest(t, f, df, model.DockerBuild{BuildArgs: model.DockerBuildArgs{"foo": "baz"}}))
+	assert.NotEqual(t, orig, contentDigestForTest(t, f, df, model.DockerBuild{BuildArgs: db.BuildArgs, TargetStage: "dev"}))
+	assert.NotEqual(t, orig, contentDigestForTest(t, f, df, model.DockerBuild{BuildArgs: db.BuildArgs, Platform: "linux/arm64"}))
+}
+
+func TestReuseImagesFromRegistry(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+	f.b.reuseImages = true
+
+	var lookups []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups = append(lookups, r.URL.Path)
+	}))
+	defer server.Close()
+
+	f.WriteFile("a.txt", "a")
+	df := dockerfile.Dockerfile("FROM alpine\nADD . .")
+	host := strings.TrimPrefix(server.URL, "http://")
+	ref, err := f.b.BuildDockerfile(f.ctx, f.ps, container.MustParseNamed(host+"/foo"), df,
+		model.EmptyMatcher, model.DockerBuild{BuildPath: f.Path()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 0, f.fakeDocker.BuildCount)
+	assert.Equal(t, []string{"/v2/foo/manifests/" + ref.Tag()}, lookups)
+}
+
+func TestReuseImagesBuildsWhenMissingFromRegistry(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+	f.b.reuseImages = true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	f.WriteFile("a.txt", "a")
+	df := dockerfile.Dockerfile("FROM alpine\nADD . .")
+	host := strings.TrimPrefix(server.URL, "http://")
+	_, err := f.b.BuildDockerfile(f.ctx, f.ps, container.MustParseNamed(host+"/foo"), df,
+		model.EmptyMatcher, model.DockerBuild{BuildPath: f.Path()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.fakeDocker.BuildCount)
+}
+
+func contentDigestForTest(t *testing.T, f *tempdir.TempDirFixture, df dockerfile.Dockerfile, db model.DockerBuild) string {
+	paths := []PathMapping{{LocalPath: f.Path(), ContainerPath: "/"}}
+	archive, err := tarContextAndUpdateDf(output.CtxForTest(), df, paths, model.EmptyMatcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := contentDigest(archive.Bytes(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.String()
+}
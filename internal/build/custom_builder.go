@@ -14,7 +14,7 @@ import (
 )
 
 type CustomBuilder interface {
-	Build(ctx context.Context, ref reference.Named, command string, expectedTag string) (reference.NamedTagged, error)
+	Build(ctx context.Context, ref reference.Named, command string, expectedTag string, platform string) (reference.NamedTagged, error)
 }
 
 type ExecCustomBuilder struct {
@@ -31,7 +31,7 @@ func NewExecCustomBuilder(dCli docker.Client, env docker.Env, clock Clock) *Exec
 	}
 }
 
-func (b *ExecCustomBuilder) Build(ctx context.Context, ref reference.Named, command string, expectedTag string) (reference.NamedTagged, error) {
+func (b *ExecCustomBuilder) Build(ctx context.Context, ref reference.Named, command string, expectedTag string, platform string) (reference.NamedTagged, error) {
 	if expectedTag == "" {
 		expectedTag = fmt.Sprintf("tilt-build-%d", b.clock.Now().Unix())
 	}
@@ -52,6 +52,11 @@ func (b *ExecCustomBuilder) Build(ctx context.Context, ref reference.Named, comm
 		env = append(env, e)
 		l.Infof("%s", e)
 	}
+	if platform != "" {
+		e := fmt.Sprintf("DOCKER_DEFAULT_PLATFORM=%s", platform)
+		env = append(env, e)
+		l.Infof("%s", e)
+	}
 	cmd.Env = env
 
 	w := l.Writer(logger.InfoLvl)
@@ -69,6 +74,11 @@ func (b *ExecCustomBuilder) Build(ctx context.Context, ref reference.Named, comm
 		return nil, err
 	}
 
+	err = checkImagePlatform(ctx, b.dCli, expectedRef.String(), platform)
+	if err != nil {
+		return nil, err
+	}
+
 	dig := digest.Digest(inspect.ID)
 
 	tag, err := digestAsTag(dig)
@@ -19,7 +19,7 @@ func TestCustomBuildSuccess(t *testing.T) {
 
 	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
 	f.dCli.Images["gcr.io/foo/bar:tilt-build-1551202573"] = types.ImageInspect{ID: string(sha)}
-	ref, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "true", "")
+	ref, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "true", "", "")
 	if err != nil {
 		f.t.Fatal(err)
 	}
@@ -30,7 +30,7 @@ func TestCustomBuildSuccess(t *testing.T) {
 func TestCustomBuildCmdFails(t *testing.T) {
 	f := newFakeCustomBuildFixture(t)
 
-	_, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "false", "")
+	_, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "false", "", "")
 	// TODO(dmiller) better error message
 	assert.EqualError(t, err, "exit status 1")
 }
@@ -38,7 +38,7 @@ func TestCustomBuildCmdFails(t *testing.T) {
 func TestCustomBuildImgNotFound(t *testing.T) {
 	f := newFakeCustomBuildFixture(t)
 
-	_, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "true", "")
+	_, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "true", "", "")
 	assert.Contains(t, err.Error(), "fake docker client error: object not found")
 }
 
@@ -47,7 +47,7 @@ func TestCustomBuildExpectedTag(t *testing.T) {
 
 	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
 	f.dCli.Images["gcr.io/foo/bar:the-tag"] = types.ImageInspect{ID: string(sha)}
-	ref, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "true", "the-tag")
+	ref, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "true", "the-tag", "")
 	if err != nil {
 		f.t.Fatal(err)
 	}
@@ -55,6 +55,28 @@ func TestCustomBuildExpectedTag(t *testing.T) {
 	assert.Equal(f.t, container.MustParseNamed("gcr.io/foo/bar:tilt-11cd0eb38bc3ceb9"), ref)
 }
 
+func TestCustomBuildPlatform(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
+	f.dCli.Images["gcr.io/foo/bar:tilt-build-1551202573"] = types.ImageInspect{ID: string(sha), Os: "linux", Architecture: "amd64"}
+	_, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), `test "$DOCKER_DEFAULT_PLATFORM" = linux/amd64`, "", "linux/amd64")
+	if err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func TestCustomBuildWrongPlatform(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
+	f.dCli.Images["gcr.io/foo/bar:tilt-build-1551202573"] = types.ImageInspect{ID: string(sha), Os: "linux", Architecture: "arm64"}
+	_, err := f.cb.Build(f.ctx, container.MustParseNamed("gcr.io/foo/bar"), "true", "", "linux/amd64")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Expected an image for platform linux/amd64, but the build produced one for linux/arm64")
+	}
+}
+
 type fakeCustomBuildFixture struct {
 	t    *testing.T
 	ctx  context.Context
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/dustin/go-humanize"
@@ -16,12 +17,10 @@ import (
 	"github.com/windmilleng/tilt/internal/logger"
 	"github.com/windmilleng/tilt/internal/model"
 
-	"github.com/docker/cli/cli/command"
-	cliflags "github.com/docker/cli/cli/flags"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/jsonmessage"
-	"github.com/docker/docker/registry"
 	controlapi "github.com/moby/buildkit/api/services/control"
 	"github.com/opencontainers/go-digest"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -36,10 +35,18 @@ type dockerImageBuilder struct {
 	//
 	// By default, all builds are labeled with a build mode.
 	extraLabels dockerfile.Labels
+
+	// Where to look for content-tagged images that we haven't built locally.
+	registry RegistryLookup
+
+	// How many times to retry pushes and base image pulls.
+	retries RegistryRetries
+
+	reuseImages ReuseImages
 }
 
 type ImageBuilder interface {
-	BuildDockerfile(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, buildPath string, filter model.PathMatcher, buildArgs map[string]string) (reference.NamedTagged, error)
+	BuildDockerfile(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, filter model.PathMatcher, db model.DockerBuild) (reference.NamedTagged, error)
 	BuildImageFromScratch(ctx context.Context, ps *PipelineState, ref reference.Named, baseDockerfile dockerfile.Dockerfile, syncs []model.Sync, filter model.PathMatcher, runs []model.Run, entrypoint model.Cmd) (reference.NamedTagged, error)
 	BuildImageFromExisting(ctx context.Context, ps *PipelineState, existing reference.NamedTagged, paths []PathMapping, filter model.PathMatcher, runs []model.Run) (reference.NamedTagged, error)
 	PushImage(ctx context.Context, name reference.NamedTagged, writer io.Writer) (reference.NamedTagged, error)
@@ -52,24 +59,31 @@ func DefaultImageBuilder(b *dockerImageBuilder) ImageBuilder {
 
 var _ ImageBuilder = &dockerImageBuilder{}
 
-func NewDockerImageBuilder(dCli docker.Client, extraLabels dockerfile.Labels) *dockerImageBuilder {
+func NewDockerImageBuilder(dCli docker.Client, extraLabels dockerfile.Labels, retries RegistryRetries, reuseImages ReuseImages) *dockerImageBuilder {
 	return &dockerImageBuilder{
 		dCli:        dCli,
 		extraLabels: extraLabels,
+		registry:    NewRegistryLookup(dCli),
+		retries:     retries,
+		reuseImages: reuseImages,
 	}
 }
 
-func (d *dockerImageBuilder) BuildDockerfile(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, buildPath string, filter model.PathMatcher, buildArgs map[string]string) (reference.NamedTagged, error) {
+func (d *dockerImageBuilder) BuildDockerfile(ctx context.Context, ps *PipelineState, ref reference.Named, df dockerfile.Dockerfile, filter model.PathMatcher, db model.DockerBuild) (reference.NamedTagged, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "dib-BuildDockerfile")
 	defer span.Finish()
 
+	if d.reuseImages {
+		db.ContentTag = true
+	}
+
 	paths := []PathMapping{
 		{
-			LocalPath:     buildPath,
+			LocalPath:     db.BuildPath,
 			ContainerPath: "/",
 		},
 	}
-	return d.buildFromDf(ctx, ps, df, paths, filter, ref, buildArgs)
+	return d.buildFromDf(ctx, ps, df, paths, filter, ref, db)
 }
 
 func (d *dockerImageBuilder) BuildImageFromScratch(ctx context.Context, ps *PipelineState, ref reference.Named, baseDockerfile dockerfile.Dockerfile,
@@ -95,7 +109,7 @@ func (d *dockerImageBuilder) BuildImageFromScratch(ctx context.Context, ps *Pipe
 	}
 
 	df = d.applyLabels(df, BuildModeScratch)
-	return d.buildFromDf(ctx, ps, df, paths, filter, ref, model.DockerBuildArgs{})
+	return d.buildFromDf(ctx, ps, df, paths, filter, ref, model.DockerBuild{})
 }
 
 func (d *dockerImageBuilder) BuildImageFromExisting(ctx context.Context, ps *PipelineState, existing reference.NamedTagged,
@@ -114,7 +128,7 @@ func (d *dockerImageBuilder) BuildImageFromExisting(ctx context.Context, ps *Pip
 	}
 
 	df = d.addRemainingRuns(df, runs)
-	return d.buildFromDf(ctx, ps, df, paths, filter, existing, model.DockerBuildArgs{})
+	return d.buildFromDf(ctx, ps, df, paths, filter, existing, model.DockerBuild{})
 }
 
 func (d *dockerImageBuilder) applyLabels(df dockerfile.Dockerfile, buildMode dockerfile.LabelValue) dockerfile.Dockerfile {
@@ -230,29 +244,19 @@ func (d *dockerImageBuilder) PushImage(ctx context.Context, ref reference.NamedT
 	span, ctx := opentracing.StartSpanFromContext(ctx, "daemon-PushImage")
 	defer span.Finish()
 
-	repoInfo, err := registry.ParseRepositoryInfo(ref)
-	if err != nil {
-		return nil, errors.Wrap(err, "PushImage#ParseRepositoryInfo")
-	}
-
 	l.Infof("%sconnecting to repository", prefix)
-	cli := command.NewDockerCli(nil, writer, writer, true)
-
-	err = cli.Initialize(cliflags.NewClientOptions())
+	authConfig, err := d.dCli.RegistryAuth(ctx, ref)
 	if err != nil {
-		return nil, errors.Wrap(err, "PushImage#InitializeCLI")
+		return nil, errors.Wrap(err, "PushImage#RegistryAuth")
 	}
-	authConfig := command.ResolveAuthConfig(ctx, cli, repoInfo.Index)
-	requestPrivilege := command.RegistryAuthenticationPrivilegedFunc(cli, repoInfo.Index, "push")
 
-	encodedAuth, err := command.EncodeAuthToBase64(authConfig)
+	encodedAuth, err := docker.EncodeRegistryAuth(authConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, "PushImage#EncodeAuthToBase64")
+		return nil, errors.Wrap(err, "PushImage#EncodeRegistryAuth")
 	}
 
 	options := types.ImagePushOptions{
-		RegistryAuth:  encodedAuth,
-		PrivilegeFunc: requestPrivilege,
+		RegistryAuth: encodedAuth,
 	}
 
 	if reference.Domain(ref) == "" {
@@ -260,30 +264,44 @@ func (d *dockerImageBuilder) PushImage(ctx context.Context, ref reference.NamedT
 	}
 
 	l.Infof("%spushing the image", prefix)
+	err = withRegistryRetries(ctx, d.retries, "Pushing image", func() error {
+		return d.pushImage(ctx, ref, options, writer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+func (d *dockerImageBuilder) pushImage(ctx context.Context, ref reference.NamedTagged, options types.ImagePushOptions, writer io.Writer) error {
 	imagePushResponse, err := d.dCli.ImagePush(
 		ctx,
 		ref.String(),
 		options)
 	if err != nil {
-		return nil, errors.Wrap(err, "PushImage#ImagePush")
+		return errors.Wrap(err, "PushImage#ImagePush")
 	}
 
 	defer func() {
 		err := imagePushResponse.Close()
 		if err != nil {
-			l.Infof("unable to close imagePushResponse: %s", err)
+			logger.Get(ctx).Infof("unable to close imagePushResponse: %s", err)
 		}
 	}()
 
 	_, err = readDockerOutput(ctx, imagePushResponse, writer)
 	if err != nil {
-		return nil, errors.Wrapf(err, "pushing image %q", ref.Name())
+		return errors.Wrapf(err, "pushing image %q", ref.Name())
 	}
-
-	return ref, nil
+	return nil
 }
 
-func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState, df dockerfile.Dockerfile, paths []PathMapping, filter model.PathMatcher, ref reference.Named, buildArgs model.DockerBuildArgs) (reference.NamedTagged, error) {
+// Build the image from the given Dockerfile.
+//
+// Only the build options of db (args, target, etc) are used. The context
+// comes from paths.
+func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState, df dockerfile.Dockerfile, paths []PathMapping, filter model.PathMatcher, ref reference.Named, db model.DockerBuild) (reference.NamedTagged, error) {
 	logger.Get(ctx).Infof("Building Dockerfile:\n%s\n", indent(df.String(), "  "))
 	span, ctx := opentracing.StartSpanFromContext(ctx, "daemon-buildFromDf")
 	defer span.Finish()
@@ -307,16 +325,167 @@ func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState,
 	ps.Printf(ctx, "Created tarball (size: %s)",
 		humanize.Bytes(uint64(archive.Len())))
 
+	var contentRef reference.NamedTagged
+	if db.ContentTag {
+		contentRef, err = contentTaggedRef(ref, archive.Bytes(), db)
+		if err != nil {
+			return nil, errors.Wrap(err, "contentTaggedRef")
+		}
+
+		// If we've built these exact inputs before, there's nothing to do.
+		_, _, err := d.dCli.ImageInspectWithRaw(ctx, contentRef.String())
+		if err == nil {
+			ps.Printf(ctx, "Build inputs unchanged. Reusing image %s", contentRef.String())
+			return contentRef, nil
+		} else if !client.IsErrNotFound(err) {
+			return nil, errors.Wrap(err, "ImageInspectWithRaw")
+		}
+
+		// Someone (maybe us, in an earlier session) may have pushed it already.
+		if d.reuseImages {
+			found, err := d.registry.HasTag(ctx, contentRef)
+			if err != nil {
+				ps.Printf(ctx, "Couldn't check registry for %s: %v", contentRef.String(), err)
+			} else if found {
+				ps.Printf(ctx, "Build inputs unchanged. Reusing image %s from registry", contentRef.String())
+				return contentRef, nil
+			}
+		}
+	}
+
+	d.pullBaseImages(ctx, ps, df, db)
+
 	ps.StartBuildStep(ctx, "Building image")
+	digest, err := d.imageBuild(ctx, ps, archive, db)
+	if err != nil {
+		return nil, wrapPlatformError(err, db.Platform)
+	}
+
+	err = checkImagePlatform(ctx, d.dCli, digest.String(), db.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentRef != nil {
+		err = d.dCli.ImageTag(ctx, digest.String(), contentRef.String())
+		if err != nil {
+			return nil, errors.Wrap(err, "TagImage#ImageTag")
+		}
+		return contentRef, nil
+	}
+
+	nt, err := d.TagImage(ctx, ref, digest)
+	if err != nil {
+		return nil, errors.Wrap(err, "PushImage")
+	}
+
+	return nt, nil
+}
+
+// Pulls the base images that the Dockerfile needs and that we don't have yet.
+//
+// Pulling can fail on a network blip, and we'd rather retry the pull than
+// the whole build. If a pull fails for good, we leave it to the build to
+// report, since the build may know better (e.g., the image might come from
+// a build arg, or the build might have its own credentials).
+func (d *dockerImageBuilder) pullBaseImages(ctx context.Context, ps *PipelineState, df dockerfile.Dockerfile, db model.DockerBuild) {
+	refs, err := baseImageRefs(df)
+	if err != nil {
+		return
+	}
+
+	for _, ref := range refs {
+		_, _, err := d.dCli.ImageInspectWithRaw(ctx, ref.String())
+		if !client.IsErrNotFound(err) {
+			continue
+		}
+
+		ps.StartBuildStep(ctx, "Pulling %s", reference.FamiliarString(ref))
+		err = withRegistryRetries(ctx, d.retries, "Pulling image", func() error {
+			return d.pullImage(ctx, ref, db.Platform, ps.Writer(ctx))
+		})
+		if err != nil {
+			ps.Printf(ctx, "Couldn't pull %s: %v", reference.FamiliarString(ref), err)
+		}
+	}
+}
+
+// The images that the Dockerfile builds FROM (or copies from), skipping
+// its own stages and "scratch".
+func baseImageRefs(df dockerfile.Dockerfile) ([]reference.Named, error) {
+	refs, err := df.FindImages()
+	if err != nil {
+		return nil, err
+	}
+	stages, err := df.Stages()
+	if err != nil {
+		return nil, err
+	}
+	isStage := make(map[string]bool, len(stages))
+	for _, stage := range stages {
+		isStage[strings.ToLower(stage)] = true
+	}
+
+	var result []reference.Named
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		name := reference.FamiliarString(ref)
+		if name == "scratch" || isStage[strings.ToLower(name)] || seen[ref.String()] {
+			continue
+		}
+		// COPY --from can name a stage by its index.
+		if _, err := strconv.Atoi(name); err == nil {
+			continue
+		}
+		seen[ref.String()] = true
+		result = append(result, ref)
+	}
+	return result, nil
+}
+
+func (d *dockerImageBuilder) pullImage(ctx context.Context, ref reference.Named, platform string, writer io.Writer) error {
+	authConfig, err := d.dCli.RegistryAuth(ctx, ref)
+	if err != nil {
+		return errors.Wrap(err, "PullImage#RegistryAuth")
+	}
+
+	encodedAuth, err := docker.EncodeRegistryAuth(authConfig)
+	if err != nil {
+		return errors.Wrap(err, "PullImage#EncodeRegistryAuth")
+	}
+
+	imagePullResponse, err := d.dCli.ImagePull(ctx, ref.String(), types.ImagePullOptions{
+		RegistryAuth: encodedAuth,
+		Platform:     platform,
+	})
+	if err != nil {
+		return errors.Wrap(err, "PullImage#ImagePull")
+	}
+
+	defer func() {
+		err := imagePullResponse.Close()
+		if err != nil {
+			logger.Get(ctx).Infof("unable to close imagePullResponse: %s", err)
+		}
+	}()
+
+	_, err = readDockerOutput(ctx, imagePullResponse, writer)
+	if err != nil {
+		return errors.Wrapf(err, "pulling image %q", reference.FamiliarString(ref))
+	}
+	return nil
+}
+
+func (d *dockerImageBuilder) imageBuild(ctx context.Context, ps *PipelineState, archive io.Reader, db model.DockerBuild) (digest.Digest, error) {
 	spanBuild, ctx := opentracing.StartSpanFromContext(ctx, "daemon-ImageBuild")
 	imageBuildResponse, err := d.dCli.ImageBuild(
 		ctx,
 		archive,
-		Options(archive, buildArgs),
+		Options(archive, db),
 	)
 	spanBuild.Finish()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	defer func() {
@@ -326,17 +495,7 @@ func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState,
 		}
 	}()
 
-	digest, err := d.getDigestFromBuildOutput(ctx, imageBuildResponse.Body, ps.Writer(ctx))
-	if err != nil {
-		return nil, err
-	}
-
-	nt, err := d.TagImage(ctx, ref, digest)
-	if err != nil {
-		return nil, errors.Wrap(err, "PushImage")
-	}
-
-	return nt, nil
+	return d.getDigestFromBuildOutput(ctx, imageBuildResponse.Body, ps.Writer(ctx))
 }
 
 func (d *dockerImageBuilder) getDigestFromBuildOutput(ctx context.Context, reader io.Reader, writer io.Writer) (digest.Digest, error) {
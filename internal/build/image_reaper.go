@@ -3,6 +3,8 @@ package build
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,10 +16,12 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/windmilleng/tilt/internal/docker"
 	"github.com/windmilleng/tilt/internal/dockerfile"
+	"github.com/windmilleng/tilt/internal/logger"
 )
 
 type ImageReaper struct {
-	docker docker.Client
+	docker   docker.Client
+	registry RegistryDeleter
 }
 
 func FilterByLabel(label dockerfile.Label) filters.KeyValuePair {
@@ -34,10 +38,16 @@ func FilterByRefName(ref reference.Named) filters.KeyValuePair {
 
 func NewImageReaper(docker docker.Client) ImageReaper {
 	return ImageReaper{
-		docker: docker,
+		docker:   docker,
+		registry: NewRegistryDeleter(docker),
 	}
 }
 
+func (r ImageReaper) WithRegistryDeleter(registry RegistryDeleter) ImageReaper {
+	r.registry = registry
+	return r
+}
+
 // Delete all Tilt builds
 //
 // For safety reasons, we only delete images with the tilt.buildMode label,
@@ -81,3 +91,91 @@ func (r ImageReaper) RemoveTiltImages(ctx context.Context, createdBefore time.Ti
 	}
 	return err
 }
+
+// Delete all but the newest `keep` Tilt-built tags of this image.
+//
+// We only consider tags that Tilt generated (with the tilt- prefix), on images
+// with the tilt.buildMode label. Images that are still in use by a container
+// are left alone.
+//
+// If pruneRegistry is set, we also delete the removed tags from the registry
+// they were pushed to.
+//
+// Returns: the tags that were removed.
+func (r ImageReaper) PruneTiltTags(ctx context.Context, ref reference.Named, keep int, pruneRegistry bool) ([]reference.NamedTagged, error) {
+	listOptions := types.ImageListOptions{
+		Filters: filters.NewArgs(FilterByLabel(BuildMode), FilterByRefName(ref)),
+	}
+
+	summaries, err := r.docker.ImageList(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "PruneTiltTags")
+	}
+
+	type taggedImage struct {
+		ref     reference.NamedTagged
+		created int64
+	}
+
+	var tagged []taggedImage
+	for _, summary := range summaries {
+		for _, repoTag := range summary.RepoTags {
+			named, err := reference.ParseNormalizedNamed(repoTag)
+			if err != nil {
+				continue
+			}
+
+			nt, ok := named.(reference.NamedTagged)
+			if !ok || nt.Name() != ref.Name() || !isTiltBuildTag(nt.Tag()) {
+				continue
+			}
+			tagged = append(tagged, taggedImage{ref: nt, created: summary.Created})
+		}
+	}
+
+	if len(tagged) <= keep {
+		return nil, nil
+	}
+
+	// Newest first
+	sort.SliceStable(tagged, func(i, j int) bool {
+		if tagged[i].created != tagged[j].created {
+			return tagged[i].created > tagged[j].created
+		}
+		return tagged[i].ref.Tag() < tagged[j].ref.Tag()
+	})
+
+	rmOptions := types.ImageRemoveOptions{
+		PruneChildren: true,
+	}
+
+	var removed []reference.NamedTagged
+	for _, t := range tagged[keep:] {
+		_, err := r.docker.ImageRemove(ctx, t.ref.String(), rmOptions)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				continue
+			}
+
+			// Most likely a container is still using this image.
+			logger.Get(ctx).Debugf("Could not remove image %s: %v", t.ref, err)
+			continue
+		}
+		removed = append(removed, t.ref)
+
+		if pruneRegistry && r.registry != nil {
+			err := r.registry.DeleteTag(ctx, t.ref)
+			if err != nil {
+				return removed, errors.Wrap(err, "PruneTiltTags")
+			}
+		}
+	}
+
+	return removed, nil
+}
+
+// Tilt-built tags start with the tag prefix, but so do the
+// images that hold cache directories, which we leave to the CacheBuilder.
+func isTiltBuildTag(tag string) bool {
+	return strings.HasPrefix(tag, ImageTagPrefix) && !strings.HasPrefix(tag, CacheTagPrefix)
+}
@@ -0,0 +1,85 @@
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestPruneTiltTags(t *testing.T) {
+	dCli := docker.NewFakeClient()
+	dCli.ImageListOutput = []types.ImageSummary{
+		{ID: "a", Created: 1, RepoTags: []string{"gcr.io/foo:tilt-1"}},
+		{ID: "b", Created: 2, RepoTags: []string{"gcr.io/foo:tilt-2", "gcr.io/foo:latest"}},
+		{ID: "c", Created: 3, RepoTags: []string{"gcr.io/foo:tilt-3"}},
+		{ID: "d", Created: 4, RepoTags: []string{"gcr.io/foo:tilt-4", "gcr.io/foobar:tilt-4"}},
+		{ID: "e", Created: 0, RepoTags: []string{"gcr.io/foo:tilt-cache-123"}},
+	}
+	registry := &fakeRegistryDeleter{}
+	reaper := NewImageReaper(dCli).WithRegistryDeleter(registry)
+
+	removed, err := reaper.PruneTiltTags(output.CtxForTest(), container.MustParseNamed("gcr.io/foo"), 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"gcr.io/foo:tilt-2", "gcr.io/foo:tilt-1"}, refStrings(removed))
+	assert.Equal(t, []string{"gcr.io/foo:tilt-1", "gcr.io/foo:tilt-2"}, dCli.RemovedImageIDs)
+	assert.Empty(t, registry.deleted)
+}
+
+func TestPruneTiltTagsRegistry(t *testing.T) {
+	dCli := docker.NewFakeClient()
+	dCli.ImageListOutput = []types.ImageSummary{
+		{ID: "a", Created: 1, RepoTags: []string{"gcr.io/foo:tilt-1"}},
+		{ID: "b", Created: 2, RepoTags: []string{"gcr.io/foo:tilt-2"}},
+	}
+	registry := &fakeRegistryDeleter{}
+	reaper := NewImageReaper(dCli).WithRegistryDeleter(registry)
+
+	_, err := reaper.PruneTiltTags(output.CtxForTest(), container.MustParseNamed("gcr.io/foo"), 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"gcr.io/foo:tilt-1"}, registry.deleted)
+}
+
+func TestPruneTiltTagsNothingToDo(t *testing.T) {
+	dCli := docker.NewFakeClient()
+	dCli.ImageListOutput = []types.ImageSummary{
+		{ID: "a", Created: 1, RepoTags: []string{"gcr.io/foo:tilt-1"}},
+	}
+	reaper := NewImageReaper(dCli)
+
+	removed, err := reaper.PruneTiltTags(output.CtxForTest(), container.MustParseNamed("gcr.io/foo"), 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, removed)
+	assert.Empty(t, dCli.RemovedImageIDs)
+}
+
+type fakeRegistryDeleter struct {
+	deleted []string
+}
+
+func (d *fakeRegistryDeleter) DeleteTag(ctx context.Context, ref reference.NamedTagged) error {
+	d.deleted = append(d.deleted, ref.String())
+	return nil
+}
+
+func refStrings(refs []reference.NamedTagged) []string {
+	result := make([]string, len(refs))
+	for i, ref := range refs {
+		result[i] = ref.String()
+	}
+	return result
+}
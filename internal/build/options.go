@@ -8,12 +8,18 @@ import (
 	"github.com/windmilleng/tilt/internal/model"
 )
 
-func Options(archive io.Reader, args model.DockerBuildArgs) docker.BuildOptions {
+func Options(archive io.Reader, db model.DockerBuild) docker.BuildOptions {
 	return docker.BuildOptions{
 		Context:    archive,
 		Dockerfile: "Dockerfile",
 		Remove:     shouldRemoveImage(),
-		BuildArgs:  manifestBuildArgsToDockerBuildArgs(args),
+		BuildArgs:  manifestBuildArgsToDockerBuildArgs(db.BuildArgs),
+		Target:     db.TargetStage.String(),
+
+		NetworkMode: db.Network,
+		ExtraHosts:  db.ExtraHosts,
+		SSHSpecs:    db.SSHSpecs,
+		Platform:    db.Platform,
 	}
 }
 
@@ -7,6 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+
 	"github.com/windmilleng/tilt/internal/logger"
 )
 
@@ -18,6 +22,10 @@ type PipelineState struct {
 	curPipelineStart       time.Time
 	curPipelineStepStart   time.Time
 	c                      Clock
+
+	// A span for the current pipeline step, so that traces show how long
+	// each phase of the update (build, push, deploy, ...) took.
+	curPipelineStepSpan opentracing.Span
 }
 
 type Clock interface {
@@ -53,6 +61,7 @@ func (ps *PipelineState) End(ctx context.Context, err error) {
 	prefix := logger.Blue(l).Sprint("  │ ")
 
 	elapsed := ps.c.Now().Sub(ps.curPipelineStart)
+	ps.finishPipelineStepSpan(err)
 
 	if err != nil {
 		prefix := logger.Red(l).Sprint("ERROR:")
@@ -71,21 +80,57 @@ func (ps *PipelineState) End(ctx context.Context, err error) {
 	ps.curBuildStep = 0
 }
 
-func (ps *PipelineState) StartPipelineStep(ctx context.Context, format string, a ...interface{}) {
+// Returns a context with the step's span, so that spans started while the
+// step runs are nested under it.
+func (ps *PipelineState) StartPipelineStep(ctx context.Context, format string, a ...interface{}) context.Context {
 	l := logger.Get(ctx)
 	line := logger.Blue(l).Sprintf("STEP %d/%d — ", ps.curPipelineStep, ps.totalPipelineStepCount)
-	l.Infof("%s%s", line, fmt.Sprintf(format, a...))
+	msg := fmt.Sprintf(format, a...)
+	l.Infof("%s%s", line, msg)
 	ps.curPipelineStep++
 	ps.curBuildStep = 1
 	ps.curPipelineStepStart = ps.c.Now()
+
+	ps.finishPipelineStepSpan(nil)
+	ps.curPipelineStepSpan, ctx = opentracing.StartSpanFromContext(ctx, pipelineStepSpanName(format))
+	ps.curPipelineStepSpan.SetTag("step", msg)
+	return ctx
 }
 
 func (ps *PipelineState) EndPipelineStep(ctx context.Context) {
 	elapsed := ps.c.Now().Sub(ps.curPipelineStepStart)
+	ps.finishPipelineStepSpan(nil)
 	logger.Get(ctx).Infof("")
 	ps.pipelineStepDurations = append(ps.pipelineStepDurations, elapsed)
 }
 
+func (ps *PipelineState) finishPipelineStepSpan(err error) {
+	span := ps.curPipelineStepSpan
+	if span == nil {
+		return
+	}
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(log.String("event", "error"), log.String("message", err.Error()))
+	}
+	span.Finish()
+	ps.curPipelineStepSpan = nil
+}
+
+// Names the span after the fixed part of the step's message, e.g.,
+// "Building Dockerfile: [%s]" is "Building Dockerfile", so that the same
+// step has the same name in every trace.
+func pipelineStepSpanName(format string) string {
+	if i := strings.IndexAny(format, ":%[`"); i != -1 {
+		format = format[:i]
+	}
+	name := strings.TrimSpace(format)
+	if name == "" {
+		return "step"
+	}
+	return name
+}
+
 func (ps *PipelineState) StartBuildStep(ctx context.Context, format string, a ...interface{}) {
 	l := logger.Get(ctx)
 	p := logger.Blue(l).Sprint("  │ ")
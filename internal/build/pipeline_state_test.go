@@ -7,6 +7,9 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+
 	"github.com/windmilleng/tilt/internal/logger"
 )
 
@@ -53,6 +56,30 @@ func TestPipelineMultilinePrint(t *testing.T) {
 	assertSnapshot(t, out.String())
 }
 
+func TestPipelineStepContextHasStepSpan(t *testing.T) {
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, &bytes.Buffer{}))
+	ps := NewPipelineState(ctx, 2, fakeClock{})
+
+	stepCtx := ps.StartPipelineStep(ctx, "Pushing %s", "gcr.io/foo")
+	assert.Equal(t, ps.curPipelineStepSpan, opentracing.SpanFromContext(stepCtx))
+	ps.EndPipelineStep(stepCtx)
+	ps.End(ctx, nil)
+}
+
+func TestPipelineStepSpanName(t *testing.T) {
+	for format, expected := range map[string]string{
+		"Building Dockerfile: [%s]":   "Building Dockerfile",
+		"Building from scratch: [%s]": "Building from scratch",
+		"Pushing %s":                  "Pushing",
+		"Deploying":                   "Deploying",
+		"Deploying with %s":           "Deploying with",
+		"Running `%s`":                "Running",
+		"%s %s":                       "step",
+	} {
+		assert.Equal(t, expected, pipelineStepSpanName(format), format)
+	}
+}
+
 func assertSnapshot(t *testing.T, output string) {
 	d1 := []byte(output)
 	gmPath := fmt.Sprintf("testdata/%s_master", t.Name())
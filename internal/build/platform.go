@@ -0,0 +1,59 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/containerd/containerd/platforms"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/docker"
+)
+
+const emulationHint = "Building for another CPU architecture requires emulation in the Docker daemon. " +
+	"Docker Desktop includes it; on Linux, you can install it with:\n" +
+	"  docker run --privileged --rm tonistiigi/binfmt --install all"
+
+// Checks that the image we built actually targets the platform we asked for.
+//
+// Some daemons silently ignore the platform, and hand us back an image
+// for their own architecture. Better to fail here than to deploy an image
+// that crash loops with "exec format error".
+func checkImagePlatform(ctx context.Context, dCli docker.Client, imageID string, platform string) error {
+	if platform == "" {
+		return nil
+	}
+
+	expected, err := platforms.Parse(platform)
+	if err != nil {
+		return errors.Wrap(err, "checkImagePlatform")
+	}
+	expected = platforms.Normalize(expected)
+
+	inspect, _, err := dCli.ImageInspectWithRaw(ctx, imageID)
+	if err != nil {
+		return errors.Wrap(err, "checkImagePlatform")
+	}
+
+	actual, err := platforms.Parse(fmt.Sprintf("%s/%s", inspect.Os, inspect.Architecture))
+	if err != nil {
+		return errors.Wrap(err, "checkImagePlatform")
+	}
+	actual = platforms.Normalize(actual)
+
+	if actual.OS != expected.OS || actual.Architecture != expected.Architecture {
+		return fmt.Errorf("Expected an image for platform %s, but the build produced one for %s/%s.\n%s",
+			platform, actual.OS, actual.Architecture, emulationHint)
+	}
+	return nil
+}
+
+// When a daemon without emulation runs a RUN step built for another architecture,
+// the only symptom is a cryptic "exec format error". Explain what went wrong.
+func wrapPlatformError(err error, platform string) error {
+	if err == nil || platform == "" || !strings.Contains(err.Error(), "exec format error") {
+		return err
+	}
+	return fmt.Errorf("%v\nThe Docker daemon can't run binaries for platform %s.\n%s", err, platform, emulationHint)
+}
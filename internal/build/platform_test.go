@@ -0,0 +1,17 @@
+package build
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapPlatformError(t *testing.T) {
+	err := fmt.Errorf("standard_init_linux.go:211: exec user process caused \"exec format error\"")
+	assert.Contains(t, wrapPlatformError(err, "linux/arm64").Error(), "can't run binaries for platform linux/arm64")
+	assert.Equal(t, err, wrapPlatformError(err, ""))
+
+	other := fmt.Errorf("no space left on device")
+	assert.Equal(t, other, wrapPlatformError(other, "linux/arm64"))
+}
@@ -0,0 +1,294 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/docker"
+)
+
+// Deletes image tags from a remote registry.
+type RegistryDeleter interface {
+	DeleteTag(ctx context.Context, ref reference.NamedTagged) error
+}
+
+// Checks whether a remote registry already has an image tag.
+type RegistryLookup interface {
+	HasTag(ctx context.Context, ref reference.NamedTagged) (bool, error)
+}
+
+// The registry doesn't allow deletes. Most self-hosted registries need
+// REGISTRY_STORAGE_DELETE_ENABLED=true, and Docker Hub never allows them.
+var ErrRegistryDeleteUnsupported = errors.New("registry does not support deleting images")
+
+// How long we wait on each request to the registry, so that a registry that
+// stops responding doesn't hold up the build.
+const registryTimeout = 30 * time.Second
+
+// The client_id we send when we trade an identity token for an access token.
+const registryClientID = "tilt"
+
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}
+
+// Talks to the registry with the Docker Registry HTTP API V2,
+// authenticating with the same credentials we push with.
+type httpRegistry struct {
+	dCli   docker.Client
+	client *http.Client
+}
+
+func NewRegistryDeleter(dCli docker.Client) RegistryDeleter {
+	return newHTTPRegistry(dCli)
+}
+
+func NewRegistryLookup(dCli docker.Client) RegistryLookup {
+	return newHTTPRegistry(dCli)
+}
+
+func newHTTPRegistry(dCli docker.Client) httpRegistry {
+	return httpRegistry{
+		dCli:   dCli,
+		client: &http.Client{Timeout: registryTimeout},
+	}
+}
+
+// Docker Hub doesn't let you delete tags through the registry API.
+func canDeleteFrom(domain string) bool {
+	return domain != "docker.io"
+}
+
+func manifestURL(ref reference.Named, name string) string {
+	domain := reference.Domain(ref)
+	scheme := "https"
+	if isLocalRegistry(domain) {
+		scheme = "http"
+	}
+	if domain == "docker.io" {
+		// docker.io is the name in image refs, but the registry API lives here.
+		domain = "registry-1.docker.io"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, domain, reference.Path(ref), name)
+}
+
+func (d httpRegistry) HasTag(ctx context.Context, ref reference.NamedTagged) (bool, error) {
+	authConfig, err := d.dCli.RegistryAuth(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := d.headManifest(ctx, ref, authConfig)
+	if err != nil {
+		return false, errors.Wrapf(err, "looking up %s", ref)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, fmt.Errorf("looking up %s: unexpected status %s", ref, resp.Status)
+}
+
+func (d httpRegistry) DeleteTag(ctx context.Context, ref reference.NamedTagged) error {
+	if !canDeleteFrom(reference.Domain(ref)) {
+		return ErrRegistryDeleteUnsupported
+	}
+
+	authConfig, err := d.dCli.RegistryAuth(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	// Manifests can only be deleted by digest, so look up the digest of the tag first.
+	resp, err := d.headManifest(ctx, ref, authConfig)
+	if err != nil {
+		return errors.Wrapf(err, "looking up %s", ref)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		// Never pushed, or already deleted.
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("looking up %s: unexpected status %s", ref, resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return fmt.Errorf("looking up %s: registry did not return a digest", ref)
+	}
+
+	req, err := http.NewRequest("DELETE", manifestURL(ref, digest), nil)
+	if err != nil {
+		return err
+	}
+	resp, err = d.do(ctx, req, authConfig)
+	if err != nil {
+		return errors.Wrapf(err, "deleting %s", ref)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	case http.StatusMethodNotAllowed, http.StatusUnsupportedMediaType:
+		return ErrRegistryDeleteUnsupported
+	}
+	return fmt.Errorf("deleting %s: unexpected status %s", ref, resp.Status)
+}
+
+func (d httpRegistry) headManifest(ctx context.Context, ref reference.NamedTagged, authConfig types.AuthConfig) (*http.Response, error) {
+	req, err := http.NewRequest("HEAD", manifestURL(ref, ref.Tag()), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	return d.do(ctx, req, authConfig)
+}
+
+func (d httpRegistry) do(ctx context.Context, req *http.Request, authConfig types.AuthConfig) (*http.Response, error) {
+	// An identity token is only good for getting an access token, below.
+	if authConfig.IdentityToken == "" && authConfig.Username != "" {
+		req.SetBasicAuth(authConfig.Username, authConfig.Password)
+	}
+
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+
+	// Registries with token auth (like Docker Hub) turn us away, and tell us
+	// where to trade our credentials for a token.
+	challenge := resp.Header.Get("Www-Authenticate")
+	if resp.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(challenge, "Bearer ") {
+		return resp, nil
+	}
+
+	token, err := d.fetchToken(ctx, challenge, authConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching registry token")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err = d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return resp, nil
+}
+
+var challengeParamRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Parses a challenge like:
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:foo:pull"
+func parseBearerChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	for _, m := range challengeParamRe.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	return params
+}
+
+// https://docs.docker.com/registry/spec/auth/token/
+func (d httpRegistry) fetchToken(ctx context.Context, challenge string, authConfig types.AuthConfig) (string, error) {
+	params := parseBearerChallenge(challenge)
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("no realm in challenge %q", challenge)
+	}
+
+	req, err := newTokenRequest(realm, params, authConfig)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// Registries that hand out identity tokens (like ACR, after `docker login`)
+// want us to trade the identity token for an access token:
+// https://docs.docker.com/registry/spec/auth/oauth/
+func newTokenRequest(realm string, params map[string]string, authConfig types.AuthConfig) (*http.Request, error) {
+	if authConfig.IdentityToken != "" {
+		form := url.Values{}
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", authConfig.IdentityToken)
+		form.Set("client_id", registryClientID)
+		for _, key := range []string{"service", "scope"} {
+			if params[key] != "" {
+				form.Set(key, params[key])
+			}
+		}
+
+		req, err := http.NewRequest("POST", realm, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	}
+
+	u, err := url.Parse(realm)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			q.Set(key, params[key])
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if authConfig.Username != "" {
+		req.SetBasicAuth(authConfig.Username, authConfig.Password)
+	}
+	return req, nil
+}
+
+func isLocalRegistry(domain string) bool {
+	host := strings.Split(domain, ":")[0]
+	return host == "localhost" || host == "127.0.0.1"
+}
@@ -0,0 +1,168 @@
+package build
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestRegistryDeleteTag(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "nick", user)
+		assert.Equal(t, "secret", pass)
+
+		switch r.Method {
+		case "HEAD":
+			assert.Equal(t, "/v2/foo/manifests/tilt-1", r.URL.Path)
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+		case "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	dCli := docker.NewFakeClient()
+	dCli.RegistryAuths[host] = types.AuthConfig{Username: "nick", Password: "secret"}
+
+	err := NewRegistryDeleter(dCli).DeleteTag(output.CtxForTest(), mustParseNamedTagged(t, host+"/foo:tilt-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"/v2/foo/manifests/sha256:abc"}, deleted)
+}
+
+func TestRegistryDeleteTagUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "HEAD":
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+		case "DELETE":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	err := NewRegistryDeleter(docker.NewFakeClient()).DeleteTag(output.CtxForTest(), mustParseNamedTagged(t, host+"/foo:tilt-1"))
+	assert.Equal(t, ErrRegistryDeleteUnsupported, err)
+}
+
+func TestRegistryHasTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "HEAD", r.Method)
+		if r.URL.Path != "/v2/foo/manifests/tilt-1" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	lookup := NewRegistryLookup(docker.NewFakeClient())
+
+	found, err := lookup.HasTag(output.CtxForTest(), mustParseNamedTagged(t, host+"/foo:tilt-1"))
+	if assert.NoError(t, err) {
+		assert.True(t, found)
+	}
+
+	found, err = lookup.HasTag(output.CtxForTest(), mustParseNamedTagged(t, host+"/foo:tilt-2"))
+	if assert.NoError(t, err) {
+		assert.False(t, found)
+	}
+}
+
+func TestRegistryHasTagWithTokenAuth(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, pass, _ := r.BasicAuth()
+			assert.Equal(t, "nick", user)
+			assert.Equal(t, "secret", pass)
+			assert.Equal(t, "registry.example.com", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:foo:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token": "abc123"}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer abc123" {
+			w.Header().Set("Www-Authenticate",
+				`Bearer realm="`+server.URL+`/token",service="registry.example.com",scope="repository:foo:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "/v2/foo/manifests/tilt-1", r.URL.Path)
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	dCli := docker.NewFakeClient()
+	dCli.RegistryAuths[host] = types.AuthConfig{Username: "nick", Password: "secret"}
+
+	found, err := NewRegistryLookup(dCli).HasTag(output.CtxForTest(), mustParseNamedTagged(t, host+"/foo:tilt-1"))
+	if assert.NoError(t, err) {
+		assert.True(t, found)
+	}
+}
+
+func TestRegistryHasTagWithIdentityToken(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			assert.Equal(t, "POST", r.Method)
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "refresh-me", r.PostForm.Get("refresh_token"))
+			assert.Equal(t, "registry.example.com", r.PostForm.Get("service"))
+			assert.Equal(t, "repository:foo:pull", r.PostForm.Get("scope"))
+			_, _ = w.Write([]byte(`{"access_token": "abc123"}`))
+			return
+		}
+
+		_, _, hasBasicAuth := r.BasicAuth()
+		assert.False(t, hasBasicAuth)
+		if r.Header.Get("Authorization") != "Bearer abc123" {
+			w.Header().Set("Www-Authenticate",
+				`Bearer realm="`+server.URL+`/oauth2/token",service="registry.example.com",scope="repository:foo:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "/v2/foo/manifests/tilt-1", r.URL.Path)
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	dCli := docker.NewFakeClient()
+	dCli.RegistryAuths[host] = types.AuthConfig{Username: "00000000-0000-0000-0000-000000000000", IdentityToken: "refresh-me"}
+
+	found, err := NewRegistryLookup(dCli).HasTag(output.CtxForTest(), mustParseNamedTagged(t, host+"/foo:tilt-1"))
+	if assert.NoError(t, err) {
+		assert.True(t, found)
+	}
+}
+
+func TestManifestURLDockerHub(t *testing.T) {
+	assert.Equal(t, "https://registry-1.docker.io/v2/library/redis/manifests/tilt-1",
+		manifestURL(container.MustParseNamed("redis"), "tilt-1"))
+	assert.Equal(t, "https://registry-1.docker.io/v2/nick/foo/manifests/tilt-1",
+		manifestURL(container.MustParseNamed("docker.io/nick/foo"), "tilt-1"))
+}
+
+func mustParseNamedTagged(t *testing.T, s string) reference.NamedTagged {
+	nt, ok := container.MustParseNamed(s).(reference.NamedTagged)
+	if !ok {
+		t.Fatalf("not a tagged ref: %s", s)
+	}
+	return nt
+}
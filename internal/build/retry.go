@@ -0,0 +1,69 @@
+package build
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/logger"
+)
+
+// How many times to retry a registry operation (a push, or pulling a
+// base image) that failed with a transient network error.
+//
+// Set by the --registry-retries flag.
+type RegistryRetries int
+
+const DefaultRegistryRetries RegistryRetries = 3
+
+// How long to wait before the first retry. Doubles on each retry after that.
+var registryRetryBackoff = time.Second
+
+// Runs f, retrying with exponential backoff if it fails with an error
+// that looks like a network blip or a registry having a bad moment.
+func withRegistryRetries(ctx context.Context, retries RegistryRetries, desc string, f func() error) error {
+	backoff := registryRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= int(retries) || !isTransientRegistryError(err) || ctx.Err() != nil {
+			return err
+		}
+
+		logger.Get(ctx).Infof("%s failed with a transient error, retrying in %s (%d/%d): %v",
+			desc, backoff, attempt+1, retries, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Docker flattens most registry errors into strings by the time they get
+// to us, so we have to match on the message.
+var transientRegistryErrorRe = regexp.MustCompile(
+	`(?i)connection reset by peer|broken pipe|i/o timeout|TLS handshake timeout|unexpected EOF|` +
+		`no such host|temporary failure in name resolution|server misbehaving|` +
+		`\b5\d\d (Internal Server Error|Bad Gateway|Service Unavailable|Gateway Timeout)\b|` +
+		`status(?: code)?:? 5\d\d\b`)
+
+func isTransientRegistryError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if netErr, ok := errors.Cause(err).(net.Error); ok && (netErr.Timeout() || netErr.Temporary()) {
+		return true
+	}
+
+	msg := err.Error()
+	if strings.Contains(msg, context.Canceled.Error()) {
+		return false
+	}
+	return transientRegistryErrorRe.MatchString(msg)
+}
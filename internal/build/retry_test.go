@@ -0,0 +1,131 @@
+package build
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/dockerfile"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestIsTransientRegistryError(t *testing.T) {
+	for _, msg := range []string{
+		"Get https://gcr.io/v2/: read tcp 10.0.0.2:51234->74.125.1.1:443: read: connection reset by peer",
+		"Get https://registry-1.docker.io/v2/: net/http: TLS handshake timeout",
+		"dial tcp: lookup gcr.io on 127.0.0.53:53: no such host",
+		"received unexpected HTTP status: 503 Service Unavailable",
+		"failed commit on ref: unexpected status: 502 Bad Gateway",
+	} {
+		assert.True(t, isTransientRegistryError(fmt.Errorf("%s", msg)), msg)
+	}
+
+	for _, msg := range []string{
+		"denied: requested access to the resource is denied",
+		"unauthorized: authentication required",
+		"manifest for alpine:nope not found",
+		"context canceled",
+	} {
+		assert.False(t, isTransientRegistryError(fmt.Errorf("%s", msg)), msg)
+	}
+}
+
+func TestPushRetriesTransientErrors(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+	defer setRegistryRetryBackoffForTest(0)()
+
+	f.fakeDocker.PushErrorsToThrow = []error{
+		fmt.Errorf("read: connection reset by peer"),
+		fmt.Errorf("received unexpected HTTP status: 503 Service Unavailable"),
+	}
+	ref := container.MustParseNamedTagged("gcr.io/foo/bar:tilt-11cd0eb38bc3ceb9")
+	_, err := f.b.PushImage(f.ctx, ref, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, f.fakeDocker.PushCount)
+}
+
+func TestPushGivesUpOnPermanentErrors(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+	defer setRegistryRetryBackoffForTest(0)()
+
+	f.fakeDocker.PushErrorsToThrow = []error{fmt.Errorf("unauthorized: authentication required")}
+	ref := container.MustParseNamedTagged("gcr.io/foo/bar:tilt-11cd0eb38bc3ceb9")
+	_, err := f.b.PushImage(f.ctx, ref, ioutil.Discard)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unauthorized")
+	}
+	assert.Equal(t, 1, f.fakeDocker.PushCount)
+}
+
+func TestPushGivesUpAfterRetries(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+	defer setRegistryRetryBackoffForTest(0)()
+
+	for i := 0; i <= int(DefaultRegistryRetries); i++ {
+		f.fakeDocker.PushErrorsToThrow = append(f.fakeDocker.PushErrorsToThrow, fmt.Errorf("i/o timeout"))
+	}
+	ref := container.MustParseNamedTagged("gcr.io/foo/bar:tilt-11cd0eb38bc3ceb9")
+	_, err := f.b.PushImage(f.ctx, ref, ioutil.Discard)
+	assert.Error(t, err)
+	assert.Equal(t, int(DefaultRegistryRetries)+1, f.fakeDocker.PushCount)
+}
+
+func TestBuildRetriesBaseImagePull(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+	defer setRegistryRetryBackoffForTest(0)()
+
+	f.WriteFile("a.txt", "a")
+	f.fakeDocker.PullErrorsToThrow = []error{
+		fmt.Errorf("failed to resolve alpine: dial tcp: lookup registry-1.docker.io: no such host"),
+	}
+
+	s := model.Sync{LocalPath: f.Path(), ContainerPath: "/src"}
+	_, err := f.b.BuildImageFromScratch(f.ctx, f.ps, f.getNameFromTest(), simpleDockerfile, []model.Sync{s}, model.EmptyMatcher, nil, model.Cmd{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the pull gets retried, not the build.
+	assert.Equal(t, []string{"docker.io/library/alpine", "docker.io/library/alpine"}, f.fakeDocker.PullImages)
+	assert.Equal(t, 1, f.fakeDocker.BuildCount)
+}
+
+func TestBaseImageRefs(t *testing.T) {
+	df := dockerfile.Dockerfile(`
+FROM golang:1.13 as builder
+FROM builder as tests
+FROM scratch
+COPY --from=builder /go/bin/app /app
+COPY --from=0 /etc/passwd /etc/passwd
+COPY --from=gcr.io/foo/config:v1 /config /config
+FROM golang:1.13
+`)
+	refs, err := baseImageRefs(df)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, ref := range refs {
+		names = append(names, ref.String())
+	}
+	assert.Equal(t, []string{"docker.io/library/golang:1.13", "gcr.io/foo/config:v1"}, names)
+}
+
+func setRegistryRetryBackoffForTest(d time.Duration) func() {
+	old := registryRetryBackoff
+	registryRetryBackoff = d
+	return func() {
+		registryRetryBackoff = old
+	}
+}
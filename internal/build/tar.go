@@ -155,6 +155,16 @@ func (a *ArchiveBuilder) entriesForPath(ctx context.Context, source, dest string
 			return errors.Wrapf(err, "error walking to %s", path)
 		}
 
+		if info.IsDir() {
+			skip, err := model.MatchesEntireDir(a.filter, path)
+			if err != nil {
+				return err
+			}
+			if skip {
+				return filepath.SkipDir
+			}
+		}
+
 		matches, err := a.filter.Matches(path, info.IsDir())
 		if err != nil {
 			return err
@@ -65,7 +65,7 @@ func newDockerBuildFixture(t testing.TB) *dockerBuildFixture {
 		t.Fatal(err)
 	}
 
-	dCli, err := docker.DefaultClient(ctx, cli, version)
+	dCli, err := docker.DefaultClient(ctx, cli, version, dEnv)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -80,7 +80,7 @@ func newDockerBuildFixture(t testing.TB) *dockerBuildFixture {
 		t:              t,
 		ctx:            ctx,
 		dCli:           dCli,
-		b:              NewDockerImageBuilder(dCli, labels),
+		b:              NewDockerImageBuilder(dCli, labels, DefaultRegistryRetries, false),
 		cb:             NewCacheBuilder(dCli),
 		reaper:         NewImageReaper(dCli),
 		ps:             ps,
@@ -101,7 +101,7 @@ func newFakeDockerBuildFixture(t testing.TB) *dockerBuildFixture {
 		t:              t,
 		ctx:            ctx,
 		fakeDocker:     dCli,
-		b:              NewDockerImageBuilder(dCli, labels),
+		b:              NewDockerImageBuilder(dCli, labels, DefaultRegistryRetries, false),
 		cb:             NewCacheBuilder(dCli),
 		reaper:         NewImageReaper(dCli),
 		ps:             ps,
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Sends a JSON payload to the HTTP server of the `tilt up` running on the given port.
+func postToTiltAPI(port int, path string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	session, err := tiltSessionToken(port)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://localhost:%d%s", port, path)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tilt-Session", session)
+
+	resp, err := doTiltAPIRequest(req)
+	if err != nil {
+		return errors.Wrapf(err, "talking to tilt (is `tilt up` running with --port=%d?)", port)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return errors.New(strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Fetches a path from the HTTP server of the `tilt up` running on the given port.
+func getFromTiltAPI(port int, path string) ([]byte, error) {
+	url := fmt.Sprintf("http://localhost:%d%s", port, path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doTiltAPIRequest(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "talking to tilt (is `tilt up` running with --port=%d?)", port)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading response from tilt")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// The token that `tilt up` requires on requests that change anything, so that
+// web pages can't make them.
+func tiltSessionToken(port int) (string, error) {
+	body, err := getFromTiltAPI(port, "/api/session")
+	if err != nil {
+		return "", err
+	}
+
+	var session struct {
+		Token string `json:"token"`
+	}
+	err = json.Unmarshal(body, &session)
+	if err != nil {
+		return "", errors.Wrap(err, "reading session from tilt")
+	}
+	return session.Token, nil
+}
+
+// If `tilt up` has a --web-token, it's in $TILT_WEB_TOKEN.
+func doTiltAPIRequest(req *http.Request) (*http.Response, error) {
+	if token := os.Getenv("TILT_WEB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return http.DefaultClient.Do(req)
+}
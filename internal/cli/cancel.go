@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type cancelCmd struct {
+	port int
+}
+
+func (c *cancelCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel <resource>",
+		Short: "cancel the current build of a resource in a running `tilt up`",
+		Long: `Tells the tilt up that's running in this directory to stop building the named
+resource, as if you'd pressed c on it in the HUD. The resource keeps whatever it
+had deployed before the build started.`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *cancelCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.cancel", nil)
+	defer analyticsService.Flush(time.Second)
+
+	err := postToTiltAPI(c.port, "/api/cancel", map[string]string{"name": args[0]})
+	if err != nil {
+		return errors.Wrap(err, "tilt cancel")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+
+	"github.com/windmilleng/tilt/internal/engine"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/tiltfile"
+)
+
+const DefaultCITimeout = 30 * time.Minute
+
+type ciCmd struct {
+	timeout  time.Duration
+	fileName string
+	port     int
+}
+
+func (c *ciCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci [<name>] [<name2>] [...]",
+		Short: "build and deploy everything once, then exit when it's all ready",
+		Long: `Builds and deploys every resource (or just the named ones), and waits for them
+to be ready, including local resources. Exits 0 once they're all ready.
+
+If any resource fails to build, crashes, or isn't ready before the timeout,
+exits 1 and prints the logs of the resources that failed.
+
+For using your Tiltfile as the harness for integration tests in CI.`,
+	}
+
+	cmd.Flags().DurationVar(&c.timeout, "timeout", DefaultCITimeout, "How long to wait for everything to be ready before giving up. Set to 0 to wait forever.")
+	cmd.Flags().StringVar(&c.fileName, "file", tiltfile.FileName, "Path to Tiltfile")
+	cmd.Flags().IntVar(&c.port, "port", 0, "Port for the Tilt HTTP server. Off by default.")
+	addWatchFlags(cmd)
+
+	return cmd
+}
+
+func (c *ciCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.ci", nil)
+	defer analyticsService.Flush(time.Second)
+
+	webPort = c.port
+	grpcPort = 0
+	threads, err := wireThreads(ctx)
+	if err != nil {
+		return err
+	}
+
+	upper := threads.upper
+	upper.AddSubscriber(ctx, engine.NewCIController(c.timeout))
+
+	l := engine.NewLogActionLogger(ctx, upper.Dispatch)
+	ctx = logger.WithLogger(ctx, l)
+
+	log.SetOutput(l.Writer(logger.InfoLvl))
+	klog.SetOutput(l.Writer(logger.InfoLvl))
+
+	logOutput(fmt.Sprintf("Starting Tilt CI (%s)…", buildStamp()))
+
+	// We watch for changes (even though we won't get any) so that we keep
+	// watching the resources until they're ready. The CIController tells us when to exit.
+	return upper.Start(ctx, args, threads.tiltBuild, true, model.TriggerAuto, c.fileName, false, false)
+}
@@ -45,8 +45,19 @@ func Execute() {
 	}
 
 	addCommand(rootCmd, &upCmd{})
+	addCommand(rootCmd, &ciCmd{})
 	addCommand(rootCmd, &doctorCmd{})
 	addCommand(rootCmd, &downCmd{})
+	addCommand(rootCmd, &execCmd{})
+	addCommand(rootCmd, &triggerCmd{})
+	addCommand(rootCmd, &cancelCmd{})
+	addCommand(rootCmd, &enableCmd{})
+	addCommand(rootCmd, &disableCmd{})
+	addCommand(rootCmd, &pauseCmd{})
+	addCommand(rootCmd, &resumeCmd{})
+	addCommand(rootCmd, &replayCmd{})
+	addCommand(rootCmd, &snapshotCmd{})
+	rootCmd.AddCommand(newDumpCmd())
 	addCommand(rootCmd, &demoCmd{})
 	addCommand(rootCmd, &versionCmd{})
 
@@ -54,7 +65,7 @@ func Execute() {
 	globalFlags.BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
 	globalFlags.BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
 	globalFlags.BoolVar(&trace, "trace", false, "Enable tracing")
-	globalFlags.StringVar(&traceType, "traceBackend", "windmill", "Which tracing backend to use. Valid values are: 'windmill', 'lightstep', 'jaeger'")
+	globalFlags.StringVar(&traceType, "traceBackend", "windmill", "Which tracing backend to use. Valid values are: 'windmill', 'lightstep', 'jaeger', 'otlp' (an OpenTelemetry collector, set with $OTEL_EXPORTER_OTLP_ENDPOINT)")
 	globalFlags.IntVar(&klogLevel, "klog", 0, "Enable Kubernetes API logging. Uses klog v-levels (0-4 are debug logs, 5-9 are tracing logs)")
 	err = globalFlags.MarkHidden("klog")
 	if err != nil {
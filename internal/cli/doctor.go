@@ -3,13 +3,21 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"runtime"
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/tiltfile"
 )
 
 type doctorCmd struct {
+	rbac     bool
+	fileName string
 }
 
 func (c *doctorCmd) register() *cobra.Command {
@@ -17,13 +25,22 @@ func (c *doctorCmd) register() *cobra.Command {
 		Use:   "doctor",
 		Short: "Print diagnostic information about the Tilt environment, for filing bug reports",
 	}
+
+	cmd.Flags().BoolVar(&c.rbac, "rbac", false, "Check that you have the Kubernetes permissions that the Tiltfile's resources need, and print a Role that grants them")
+	cmd.Flags().StringVar(&c.fileName, "file", tiltfile.FileName, "Path to Tiltfile (with --rbac)")
 	return cmd
 }
 
 func (c *doctorCmd) run(ctx context.Context, args []string) error {
-	analyticsService.Incr("cmd.doctor", map[string]string{})
+	analyticsService.Incr("cmd.doctor", map[string]string{
+		"rbac": fmt.Sprintf("%t", c.rbac),
+	})
 	defer analyticsService.Flush(time.Second)
 
+	if c.rbac {
+		return c.runRBAC(ctx, os.Stdout)
+	}
+
 	fmt.Printf("Tilt: %s\n", buildStamp())
 	fmt.Printf("System: %s-%s\n", runtime.GOOS, runtime.GOARCH)
 
@@ -83,6 +100,105 @@ func (c *doctorCmd) run(ctx context.Context, args []string) error {
 	return nil
 }
 
+// The name of the roles that --rbac prints.
+const rbacRoleName = "tilt-deployer"
+
+func (c *doctorCmd) runRBAC(ctx context.Context, w io.Writer) error {
+	deps, err := wireDownDeps(ctx)
+	if err != nil {
+		return err
+	}
+
+	ns, err := wireNamespace(ctx)
+	if err != nil {
+		return err
+	}
+
+	tlr, err := deps.tfl.Load(ctx, c.fileName, nil, false)
+	if err != nil {
+		return err
+	}
+
+	// Each connection has its own credentials, so check each one separately.
+	var conns []k8s.Connection
+	manifestsByConn := map[k8s.Connection][]model.Manifest{}
+	for _, m := range tlr.Manifests {
+		if !m.IsK8s() {
+			continue
+		}
+		conn := deps.kClients.Normalize(k8s.ConnectionForTarget(m.K8sTarget()))
+		if _, ok := manifestsByConn[conn]; !ok {
+			conns = append(conns, conn)
+		}
+		manifestsByConn[conn] = append(manifestsByConn[conn], m)
+	}
+
+	if len(conns) == 0 {
+		fmt.Fprintln(w, "No Kubernetes resources in the Tiltfile, so Tilt doesn't need any Kubernetes permissions.")
+		return nil
+	}
+
+	missing := 0
+	for _, conn := range conns {
+		perms, err := k8s.RequiredPermissions(manifestsByConn[conn], ns)
+		if err != nil {
+			return err
+		}
+
+		kCli, err := deps.kClients.ClientFor(ctx, conn)
+		if err != nil {
+			return err
+		}
+		checks, err := kCli.CheckPermissions(ctx, perms)
+		if err != nil {
+			return err
+		}
+
+		rolesYAML, err := k8s.RolesYAML(rbacRoleName, perms)
+		if err != nil {
+			return err
+		}
+		missing += printRBACReport(w, conn, checks, rolesYAML)
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("missing %d Kubernetes permissions", missing)
+	}
+	return nil
+}
+
+// Prints which permissions we have and which we're missing, and a Role that
+// grants all of them. Returns the number of missing permissions.
+func printRBACReport(w io.Writer, conn k8s.Connection, checks []k8s.PermissionCheck, rolesYAML string) int {
+	fmt.Fprintln(w, "---")
+	fmt.Fprintf(w, "Kubernetes permissions (%s)\n", conn)
+
+	missing := 0
+	for _, check := range checks {
+		if check.Allowed {
+			fmt.Fprintf(w, "- OK: %s\n", check.Permission)
+			continue
+		}
+
+		missing++
+		if check.Reason != "" {
+			fmt.Fprintf(w, "- MISSING: %s (%s)\n", check.Permission, check.Reason)
+		} else {
+			fmt.Fprintf(w, "- MISSING: %s\n", check.Permission)
+		}
+	}
+
+	if missing == 0 {
+		fmt.Fprintf(w, "You have all %d permissions that Tilt needs.\n", len(checks))
+	} else {
+		fmt.Fprintf(w, "Missing %d of %d permissions that Tilt needs.\n", missing, len(checks))
+	}
+	fmt.Fprintln(w, "These roles grant them all (bind them to the user that Tilt deploys as with a RoleBinding or ClusterRoleBinding):")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, rolesYAML)
+	return missing
+}
+
 func printField(name string, v interface{}, err error) {
 	if err != nil {
 		fmt.Printf("- %s: Error: %v\n", name, err)
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+)
+
+func TestPrintRBACReport(t *testing.T) {
+	checks := []k8s.PermissionCheck{
+		{Permission: k8s.Permission{Namespace: "default", Resource: "pods", Verb: "watch"}, Allowed: true},
+		{Permission: k8s.Permission{Namespace: "default", Resource: "pods", Subresource: "exec", Verb: "create"}, Reason: "no RBAC policy matched"},
+	}
+
+	buf := &bytes.Buffer{}
+	missing := printRBACReport(buf, k8s.Connection{ImpersonateUser: "ci-bot"}, checks, "kind: Role")
+	assert.Equal(t, 1, missing)
+
+	out := buf.String()
+	assert.Contains(t, out, `Kubernetes permissions (impersonating "ci-bot")`)
+	assert.Contains(t, out, `- OK: watch pods in namespace "default"`)
+	assert.Contains(t, out, `- MISSING: create pods/exec in namespace "default" (no RBAC policy matched)`)
+	assert.Contains(t, out, "Missing 1 of 2 permissions")
+	assert.Contains(t, out, "kind: Role")
+}
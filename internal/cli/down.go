@@ -3,27 +3,37 @@ package cli
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"time"
 
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/windmilleng/tilt/internal/engine"
+	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/tiltfile"
 )
 
 type downCmd struct {
-	fileName string
+	fileName         string
+	deleteNamespaces bool
+	deleteVolumes    bool
 }
 
 func (c *downCmd) register() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "down",
+		Use:   "down [<resource> ...]",
 		Short: "delete kubernetes resources",
+		Long: `Deletes the objects that your Tiltfile deploys.
+
+If you name resources, deletes only their objects (and stops their docker-compose services),
+and leaves the other resources and their namespaces alone.`,
 	}
 
 	cmd.Flags().StringVar(&c.fileName, "file", tiltfile.FileName, "Path to Tiltfile")
+	cmd.Flags().BoolVar(&c.deleteNamespaces, "delete-namespaces", false, "Also delete the namespaces that resources were deployed to (except default and kube-*)")
+	cmd.Flags().BoolVar(&c.deleteVolumes, "delete-volumes", false, "Also delete the volume claims that StatefulSets leave behind (and the data in them)")
 
 	return cmd
 }
@@ -39,39 +49,106 @@ func (c *downCmd) run(ctx context.Context, args []string) error {
 		return err
 	}
 
-	tlr, err := downDeps.tfl.Load(ctx, c.fileName, nil, false)
+	if len(args) > 0 && c.deleteNamespaces {
+		return fmt.Errorf("--delete-namespaces can't be used with resource names, because other resources might use the namespaces")
+	}
+
+	matching := map[string]bool{}
+	for _, arg := range args {
+		matching[arg] = true
+	}
+
+	tlr, err := downDeps.tfl.Load(ctx, c.fileName, matching, false)
 	if err != nil {
 		return err
 	}
 
-	entities, err := engine.ParseYAMLFromManifests(tlr.Manifests...)
-	if err != nil {
-		return errors.Wrap(err, "Parsing manifest YAML")
+	manifests := tlr.Manifests
+	if len(args) > 0 {
+		// The Tiltfile always returns the YAML that isn't part of any resource, so leave it out.
+		manifests = nil
+		for _, m := range tlr.Manifests {
+			if matching[m.Name.String()] {
+				manifests = append(manifests, m)
+			}
+		}
 	}
 
-	err = downDeps.kClient.Delete(ctx, entities)
-	if err != nil {
-		logger.Get(ctx).Infof("error deleting k8s entities: %v", err)
+	// Resources can deploy to different kube contexts (and with different credentials),
+	// so delete each connection's resources with a client for that connection.
+	var conns []k8s.Connection
+	manifestsByConn := map[k8s.Connection][]model.Manifest{}
+	for _, m := range manifests {
+		if !m.IsK8s() {
+			continue
+		}
+		conn := downDeps.kClients.Normalize(k8s.ConnectionForTarget(m.K8sTarget()))
+		if _, ok := manifestsByConn[conn]; !ok {
+			conns = append(conns, conn)
+		}
+		manifestsByConn[conn] = append(manifestsByConn[conn], m)
+	}
+
+	opts := engine.TearDownOptions{
+		DeleteNamespaces:   c.deleteNamespaces,
+		DeleteVolumeClaims: c.deleteVolumes,
+	}
+	for _, conn := range conns {
+		kCli, err := downDeps.kClients.ClientFor(ctx, conn)
+		if err != nil {
+			logger.Get(ctx).Infof("error deleting k8s entities: %v", err)
+			continue
+		}
+
+		if len(args) > 0 {
+			err = engine.TearDownK8sResources(ctx, kCli, manifestsByConn[conn], opts)
+		} else {
+			err = engine.TearDownK8s(ctx, kCli, manifestsByConn[conn], opts)
+		}
+		if err != nil {
+			logger.Get(ctx).Infof("error deleting k8s entities: %v", err)
+		}
 	}
 
 	var dcConfigPath string
-	for _, m := range tlr.Manifests {
+	var dcServices []model.TargetName
+	for _, m := range manifests {
 		if m.IsDC() {
 			// TODO(maia): when we support up-ing from multiple docker-compose files, we'll
 			// need to support down-ing as well. For now, we `down` the first one we find.
-			dcConfigPath = m.DockerComposeTarget().ConfigPath
-			break
+			if dcConfigPath == "" {
+				dcConfigPath = m.DockerComposeTarget().ConfigPath
+			}
+			dcServices = append(dcServices, m.DockerComposeTarget().Name)
 		}
 	}
 
+	// Everything we deployed is gone (or some of it, if we named resources),
+	// so the next `tilt up` can't skip any deploys.
+	absTfPath, err := filepath.Abs(c.fileName)
+	if err == nil {
+		err = engine.DeletePersistedState(absTfPath)
+	}
+	if err != nil {
+		logger.Get(ctx).Debugf("error deleting tilt state: %v", err)
+	}
+
 	if dcConfigPath != "" {
 		// TODO(maia): when we support up-ing from multiple docker-compose files, we'll need to support down-ing as well
-		// TODO(maia): a way to `down` specific services?
 
 		dcc := downDeps.dcClient
-		err = dcc.Down(ctx, dcConfigPath, logger.Get(ctx).Writer(logger.InfoLvl), logger.Get(ctx).Writer(logger.InfoLvl))
-		if err != nil {
-			logger.Get(ctx).Infof("error running `docker-compose down`: %v", err)
+		stdout := logger.Get(ctx).Writer(logger.InfoLvl)
+		stderr := logger.Get(ctx).Writer(logger.InfoLvl)
+		if len(args) > 0 {
+			err = dcc.Rm(ctx, dcConfigPath, dcServices, stdout, stderr)
+			if err != nil {
+				logger.Get(ctx).Infof("error running `docker-compose rm`: %v", err)
+			}
+		} else {
+			err = dcc.Down(ctx, dcConfigPath, stdout, stderr)
+			if err != nil {
+				logger.Get(ctx).Infof("error running `docker-compose down`: %v", err)
+			}
 		}
 	}
 	return nil
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newDumpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "dump internal state of a running `tilt up`, for debugging",
+		Long: `Prints some of what the tilt up that's running in this directory knows, as JSON.
+
+The output isn't stable, and may change between versions of Tilt.`,
+	}
+
+	addCommand(cmd, &dumpWatchesCmd{})
+	addCommand(cmd, &dumpAPICmd{
+		use:   "engine",
+		short: "print the engine state",
+		long: `Prints everything in the engine state: each resource's manifest, build
+statuses, pods, and logs, plus the session-wide state like the trigger queue.
+
+Errors and image refs show up as strings. Useful for figuring out why Tilt
+did (or didn't) build something.`,
+		path: "/api/dump/engine",
+	})
+	addCommand(cmd, &dumpAPICmd{
+		use:   "images",
+		short: "print the image refs that Tilt injects into your YAML",
+		long: `Prints, for each image that Tilt builds: the image name from the Tiltfile,
+and the ref of the image Tilt last built for it, which replaces the image name
+in the resource's YAML when Tilt deploys it.`,
+		path: "/api/dump/images",
+	})
+	addCommand(cmd, &dumpAPICmd{
+		use:   "logstore",
+		short: "print how much log Tilt is holding",
+		long: `Prints, for the global log, the Tiltfile's log, and each resource's log: how
+many bytes and lines it's holding, by where they came from (tilt, build,
+or runtime), and how many bytes it's dropped to stay under the limit.`,
+		path: "/api/dump/logstore",
+	})
+
+	return cmd
+}
+
+// Prints the JSON from one of the /api/dump endpoints.
+type dumpAPICmd struct {
+	use   string
+	short string
+	long  string
+	path  string
+
+	port int
+}
+
+func (c *dumpAPICmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   c.use,
+		Short: c.short,
+		Long:  c.long,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *dumpAPICmd) run(ctx context.Context, args []string) error {
+	body, err := getFromTiltAPI(c.port, c.path)
+	if err != nil {
+		return errors.Wrapf(err, "tilt dump %s", c.use)
+	}
+	return printJSON(body)
+}
+
+type dumpWatchesCmd struct {
+	port int
+}
+
+func (c *dumpWatchesCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watches",
+		Short: "print what the file watcher is doing",
+		Long: `Prints, for each set of files that Tilt watches: the paths it watches, how many
+files and directories under them aren't ignored, which directories are skipped
+because they're ignored, and how many file changes it's seen (overall, and in the
+last minute).
+
+Useful for checking that your ignores are working, or for finding the
+directory that keeps kicking off builds.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *dumpWatchesCmd) run(ctx context.Context, args []string) error {
+	body, err := getFromTiltAPI(c.port, "/api/dump/watches")
+	if err != nil {
+		return errors.Wrap(err, "tilt dump watches")
+	}
+	return printJSON(body)
+}
+
+func printJSON(body []byte) error {
+	var out bytes.Buffer
+	err := json.Indent(&out, body, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "reading response from tilt")
+	}
+	_, err = out.WriteTo(os.Stdout)
+	return err
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type enableCmd struct {
+	port int
+}
+
+func (c *enableCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable <resource>",
+		Short: "turn a disabled resource back on in a running `tilt up`",
+		Long: `Tells the tilt up that's running in this directory to start watching the named
+resource again, and rebuild it.`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *enableCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.enable", nil)
+	defer analyticsService.Flush(time.Second)
+
+	err := postToTiltAPI(c.port, "/api/enable", map[string]interface{}{
+		"name":    args[0],
+		"enabled": true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "tilt enable")
+	}
+	return nil
+}
+
+type disableCmd struct {
+	port          int
+	deleteObjects bool
+}
+
+func (c *disableCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable <resource>",
+		Short: "turn off a resource in a running `tilt up`",
+		Long: `Tells the tilt up that's running in this directory to stop watching, building,
+port-forwarding, and streaming logs for the named resource, until you run
+tilt enable. Its objects keep running, unless you pass --delete.`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().BoolVar(&c.deleteObjects, "delete", false, "Also delete the resource's objects")
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *disableCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.disable", map[string]string{
+		"delete": fmt.Sprintf("%t", c.deleteObjects),
+	})
+	defer analyticsService.Flush(time.Second)
+
+	err := postToTiltAPI(c.port, "/api/enable", map[string]interface{}{
+		"name":    args[0],
+		"enabled": false,
+		"delete":  c.deleteObjects,
+	})
+	if err != nil {
+		return errors.Wrap(err, "tilt disable")
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/pkg/term"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/tiltfile"
+)
+
+// Opens bash if the container has it, and sh otherwise.
+var defaultExecCommand = []string{"sh", "-c", "if command -v bash > /dev/null; then exec bash; else exec sh; fi"}
+
+type execCmd struct {
+	fileName  string
+	container string
+}
+
+func (c *execCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec <resource> [-- command...]",
+		Short: "open a shell (or run a command) in a resource's container",
+		Long: `Opens a shell in the container that runs the resource's image, in the
+most recently started running pod of the resource.
+
+Runs the given command instead of a shell, if there is one. Use -- to separate
+the command from tilt's flags, e.g., tilt exec frontend -- ls -l /app`,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	cmd.Flags().StringVar(&c.fileName, "file", tiltfile.FileName, "Path to Tiltfile")
+	cmd.Flags().StringVarP(&c.container, "container", "c", "", "Container to exec in (defaults to the container running the resource's image)")
+
+	return cmd
+}
+
+func (c *execCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.exec", map[string]string{
+		"command": fmt.Sprintf("%t", len(args) > 1),
+	})
+	defer analyticsService.Flush(time.Second)
+
+	deps, err := wireDownDeps(ctx)
+	if err != nil {
+		return err
+	}
+
+	tlr, err := deps.tfl.Load(ctx, c.fileName, nil, false)
+	if err != nil {
+		return err
+	}
+
+	m, err := findExecManifest(tlr.Manifests, args[0])
+	if err != nil {
+		return err
+	}
+
+	kCli, err := deps.kClients.ClientFor(ctx, k8s.ConnectionForTarget(m.K8sTarget()))
+	if err != nil {
+		return err
+	}
+
+	pods, err := listExecPods(ctx, kCli, m)
+	if err != nil {
+		return err
+	}
+
+	pod, err := pickExecPod(pods)
+	if err != nil {
+		return errors.Wrapf(err, "resource %q", m.Name)
+	}
+
+	cName, err := pickExecContainer(pod, m.ImageTargets, c.container)
+	if err != nil {
+		return errors.Wrapf(err, "pod %s", pod.Name)
+	}
+
+	cmd := defaultExecCommand
+	if len(args) > 1 {
+		cmd = args[1:]
+	}
+
+	podID := k8s.PodIDFromPod(&pod)
+	ns := k8s.NamespaceFromPod(&pod)
+
+	fd, isTerm := term.GetFdInfo(os.Stdin)
+	if !isTerm {
+		return kCli.Exec(ctx, podID, cName, ns, cmd, os.Stdin, os.Stdout, os.Stderr)
+	}
+
+	state, err := term.SetRawTerminal(fd)
+	if err != nil {
+		return errors.Wrap(err, "setting up terminal")
+	}
+	defer func() {
+		_ = term.RestoreTerminal(fd, state)
+	}()
+
+	sizes := newTerminalSizeQueue(ctx, fd)
+	return kCli.ExecTTY(ctx, podID, cName, ns, cmd, os.Stdin, os.Stdout, sizes)
+}
+
+func findExecManifest(manifests []model.Manifest, name string) (model.Manifest, error) {
+	var names []string
+	for _, m := range manifests {
+		if m.Name.String() == name {
+			if !m.IsK8s() {
+				return model.Manifest{}, fmt.Errorf("resource %q doesn't run on Kubernetes", name)
+			}
+			return m, nil
+		}
+		if m.IsK8s() {
+			names = append(names, m.Name.String())
+		}
+	}
+	return model.Manifest{}, fmt.Errorf("no resource named %q. Resources: %v", name, names)
+}
+
+// The pods that Tilt deployed for the manifest, and the pods that
+// its extra_pod_selectors pick out, in the namespaces that it deploys to.
+func listExecPods(ctx context.Context, kCli k8s.Client, m model.Manifest) ([]v1.Pod, error) {
+	var result []v1.Pod
+	for _, ns := range k8s.TargetNamespaces(m.K8sTarget(), kCli.ConfigNamespace()) {
+		for _, ls := range execPodSelectors(m) {
+			matches, err := kCli.ListPods(ctx, ns, ls)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, matches...)
+		}
+	}
+	return result, nil
+}
+
+func execPodSelectors(m model.Manifest) []labels.Selector {
+	kTarget := m.K8sTarget()
+	result := []labels.Selector{
+		labels.Set{k8s.ManifestLabelKey(kTarget): m.Name.String()}.AsSelector(),
+	}
+	return append(result, kTarget.ExtraPodSelectors...)
+}
+
+// Picks the most recently started pod that's running.
+func pickExecPod(pods []v1.Pod) (v1.Pod, error) {
+	var running []v1.Pod
+	seen := map[string]bool{}
+	for _, pod := range pods {
+		key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		if seen[key] || pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		seen[key] = true
+		running = append(running, pod)
+	}
+
+	if len(running) == 0 {
+		return v1.Pod{}, fmt.Errorf("no running pods (is the resource deployed?)")
+	}
+
+	sort.SliceStable(running, func(i, j int) bool {
+		return running[j].CreationTimestamp.Before(&running[i].CreationTimestamp)
+	})
+	return running[0], nil
+}
+
+// Picks the container with the given name, or if there's no name, the first
+// container running one of the manifest's images, or if there isn't one,
+// the first container in the pod.
+func pickExecContainer(pod v1.Pod, iTargets []model.ImageTarget, name string) (container.Name, error) {
+	if len(pod.Spec.Containers) == 0 {
+		return "", fmt.Errorf("no containers")
+	}
+
+	if name != "" {
+		var names []string
+		for _, c := range pod.Spec.Containers {
+			if c.Name == name {
+				return container.Name(c.Name), nil
+			}
+			names = append(names, c.Name)
+		}
+		return "", fmt.Errorf("no container named %q. Containers: %v", name, names)
+	}
+
+	for _, iTarget := range iTargets {
+		selectors := []container.RefSelector{iTarget.ConfigurationRef}
+		if iTarget.DeploymentRef != nil {
+			selectors = append(selectors, container.NameSelector(iTarget.DeploymentRef))
+		}
+
+		for _, c := range pod.Spec.Containers {
+			ref, err := container.ParseNamed(c.Image)
+			if err != nil {
+				continue
+			}
+			for _, s := range selectors {
+				if !s.Empty() && s.Matches(ref) {
+					return container.Name(c.Name), nil
+				}
+			}
+		}
+	}
+
+	return container.Name(pod.Spec.Containers[0].Name), nil
+}
+
+// Reports the size of the local terminal, first when the exec starts,
+// then whenever the terminal is resized.
+type terminalSizeQueue struct {
+	fd      uintptr
+	resized chan os.Signal
+	ctx     context.Context
+	first   bool
+}
+
+func newTerminalSizeQueue(ctx context.Context, fd uintptr) *terminalSizeQueue {
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	return &terminalSizeQueue{fd: fd, resized: resized, ctx: ctx, first: true}
+}
+
+func (q *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	if q.first {
+		q.first = false
+	} else {
+		select {
+		case <-q.resized:
+		case <-q.ctx.Done():
+			signal.Stop(q.resized)
+			return nil
+		}
+	}
+
+	ws, err := term.GetWinsize(q.fd)
+	if err != nil {
+		return nil
+	}
+	return &remotecommand.TerminalSize{Width: ws.Width, Height: ws.Height}
+}
+
+var _ remotecommand.TerminalSizeQueue = &terminalSizeQueue{}
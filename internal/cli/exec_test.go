@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestPickExecPodMostRecentRunning(t *testing.T) {
+	now := time.Now()
+	pods := []v1.Pod{
+		execTestPod("old", now.Add(-time.Hour), v1.PodRunning),
+		execTestPod("new", now, v1.PodRunning),
+		execTestPod("pending", now.Add(time.Minute), v1.PodPending),
+	}
+
+	pod, err := pickExecPod(pods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "new", pod.Name)
+}
+
+func TestPickExecPodNoneRunning(t *testing.T) {
+	_, err := pickExecPod([]v1.Pod{execTestPod("pending", time.Now(), v1.PodPending)})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no running pods")
+	}
+}
+
+func TestPickExecContainer(t *testing.T) {
+	pod := execTestPod("pod", time.Now(), v1.PodRunning)
+	iTarget := model.NewImageTarget(container.MustParseSelector("gcr.io/some-project-162817/sancho"))
+
+	cName, err := pickExecContainer(pod, []model.ImageTarget{iTarget}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, container.Name("sancho"), cName)
+
+	// With no image to match, use the first container.
+	cName, err = pickExecContainer(pod, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, container.Name("sidecar"), cName)
+
+	cName, err = pickExecContainer(pod, []model.ImageTarget{iTarget}, "sidecar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, container.Name("sidecar"), cName)
+
+	_, err = pickExecContainer(pod, nil, "nope")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `no container named "nope". Containers: [sidecar sancho]`)
+	}
+}
+
+func TestFindExecManifest(t *testing.T) {
+	manifests := []model.Manifest{
+		model.Manifest{Name: "frontend"}.WithDeployTarget(model.K8sTarget{Name: "frontend"}),
+		model.Manifest{Name: "db"}.WithDeployTarget(model.DockerComposeTarget{Name: "db"}),
+	}
+
+	m, err := findExecManifest(manifests, "frontend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, model.ManifestName("frontend"), m.Name)
+
+	_, err = findExecManifest(manifests, "db")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `resource "db" doesn't run on Kubernetes`)
+	}
+
+	_, err = findExecManifest(manifests, "backend")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `no resource named "backend". Resources: [frontend]`)
+	}
+}
+
+func TestListExecPodsInManifestNamespaces(t *testing.T) {
+	m := model.Manifest{Name: "sancho"}.WithDeployTarget(model.K8sTarget{Name: "sancho", YAML: testyaml.SanchoYAML})
+	inNs := execTestPod("sancho-1", time.Now(), v1.PodRunning)
+	inNs.Namespace = "sancho-ns"
+	elsewhere := execTestPod("sancho-2", time.Now(), v1.PodRunning)
+	elsewhere.Namespace = "someone-elses-ns"
+	for _, pod := range []*v1.Pod{&inNs, &elsewhere} {
+		pod.Labels = map[string]string{k8s.ManifestNameLabel: "sancho"}
+	}
+
+	kCli := k8s.NewFakeK8sClient()
+	kCli.Pods = []v1.Pod{inNs, elsewhere}
+
+	pods, err := listExecPods(context.Background(), kCli, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 1, len(pods)) {
+		assert.Equal(t, "sancho-1", pods[0].Name)
+	}
+}
+
+func execTestPod(name string, created time.Time, phase v1.PodPhase) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "sidecar", Image: "envoyproxy/envoy:v1.10.0"},
+				{Name: "sancho", Image: "gcr.io/some-project-162817/sancho:tilt-deadbeef"},
+			},
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
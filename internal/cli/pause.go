@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type pauseCmd struct {
+	port int
+}
+
+func (c *pauseCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause [<resource> ...]",
+		Short: "stop building file changes in a running `tilt up`, until you resume",
+		Long: `Tells the tilt up that's running in this directory to stop building file changes
+to the named resources (or, with no names, to any resource, and to the Tiltfile).
+
+Tilt keeps track of the files that change while it's paused, and builds them all
+at once when you run tilt resume. Handy during a big rebase, or while a code
+generator churns through your repo.`,
+	}
+
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *pauseCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.pause", map[string]string{
+		"count": fmt.Sprintf("%d", len(args)),
+	})
+	defer analyticsService.Flush(time.Second)
+
+	err := postToTiltAPI(c.port, "/api/pause", map[string]interface{}{
+		"names":  args,
+		"paused": true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "tilt pause")
+	}
+	return nil
+}
+
+type resumeCmd struct {
+	port int
+}
+
+func (c *resumeCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume [<resource> ...]",
+		Short: "build the file changes that piled up while a running `tilt up` was paused",
+		Long: `Tells the tilt up that's running in this directory to resume updates to the named
+resources (or, with no names, to the whole session), and build the files that
+changed while it was paused.`,
+	}
+
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *resumeCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.resume", map[string]string{
+		"count": fmt.Sprintf("%d", len(args)),
+	})
+	defer analyticsService.Flush(time.Second)
+
+	err := postToTiltAPI(c.port, "/api/pause", map[string]interface{}{
+		"names":  args,
+		"paused": false,
+	})
+	if err != nil {
+		return errors.Wrap(err, "tilt resume")
+	}
+	return nil
+}
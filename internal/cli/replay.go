@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/windmilleng/tilt/internal/assets"
+	"github.com/windmilleng/tilt/internal/engine"
+	"github.com/windmilleng/tilt/internal/hud"
+	"github.com/windmilleng/tilt/internal/hud/server"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/network"
+	"github.com/windmilleng/tilt/internal/sail/client"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+type replayCmd struct {
+	step    int
+	web     bool
+	port    int
+	webMode model.WebMode
+}
+
+func (c *replayCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay <snapshot|recording>",
+		Short: "show a snapshot of Tilt's state in the HUD",
+		Long: `Shows a snapshot of Tilt's state (e.g., one attached to a bug report) in the HUD,
+without building or deploying anything.
+
+To take a snapshot, run 'tilt snapshot' or press ctrl-D in the HUD of a running
+tilt up. With --web, shows the snapshot in the browser instead of the HUD.
+
+Also plays back action recordings from 'tilt up --record-actions'. Press [ and ]
+to step backwards and forwards through the actions, and see the state after each one.`,
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().IntVar(&c.step, "step", 0, "For action recordings, the action to start at (0 is the first)")
+	cmd.Flags().BoolVar(&c.web, "web", false, "Show the snapshot in the browser, instead of the HUD")
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort+1, "With --web, the port to serve the snapshot on")
+	c.webMode = model.DefaultWebMode
+	cmd.Flags().Var(&c.webMode, "web-mode", "Values: local, prod. Controls whether to use prod assets or a local dev server")
+	return cmd
+}
+
+func (c *replayCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.replay", nil)
+	defer analyticsService.Flush(time.Second)
+
+	reducer := engine.ReplayReducer
+	var initAction store.Action
+	isRecording := store.IsRecording(args[0])
+	if isRecording {
+		if c.web {
+			return fmt.Errorf("%s is an action recording, and --web only shows snapshots", args[0])
+		}
+		rec, err := store.LoadRecording(args[0])
+		if err != nil {
+			return err
+		}
+		reducer = engine.NewRecordingReplayReducer(rec)
+		initAction = hud.StepReplayAction{Delta: c.step}
+	} else {
+		snapshot, err := store.LoadSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		initAction = engine.SnapshotLoadedAction{State: snapshot.EngineState()}
+	}
+
+	st := store.NewStore(reducer, store.LogActionsFlag(false))
+	if c.web {
+		return c.serveWeb(ctx, st, initAction)
+	}
+
+	h, err := hud.NewDefaultHeadsUpDisplay(hud.NewRenderer(time.Now, hud.ThemeDefault), model.WebURL{}, analyticsService)
+	if err != nil {
+		return err
+	}
+	if isRecording {
+		h.EnableReplaySteps()
+	}
+	st.AddSubscriber(ctx, h)
+
+	g, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	g.Go(func() error {
+		defer cancel()
+		return h.Run(ctx, st.Dispatch, hud.DefaultRefreshInterval)
+	})
+	g.Go(func() error {
+		return st.Loop(ctx)
+	})
+	st.Dispatch(initAction)
+
+	err = g.Wait()
+	if err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+// Serves the web UI for the snapshot. The replay reducer ignores everything
+// the buttons do, so it's read-only.
+func (c *replayCmd) serveWeb(ctx context.Context, st *store.Store, initAction store.Action) error {
+	addr := network.LocalhostBindAddr(c.port)
+	err := network.IsBindAddrFree(addr)
+	if err != nil {
+		return errors.Wrapf(err, "Maybe another process is already running on port %d? Use --port to set a custom port", c.port)
+	}
+
+	webMode, err := resolveWebMode(c.webMode, provideTiltInfo())
+	if err != nil {
+		return err
+	}
+	assetServer, err := assets.ProvideAssetServer(ctx, webMode, provideWebVersion(provideTiltInfo()), provideWebDevPort())
+	if err != nil {
+		return err
+	}
+	defer assetServer.TearDown(context.Background())
+
+	hudServer := server.ProvideHeadsUpServer(st, assetServer, analyticsService, client.NewFakeSailClient(), nil, model.WebAuth{})
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: hudServer.Router(),
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return st.Loop(ctx)
+	})
+	g.Go(func() error {
+		return assetServer.Serve(ctx)
+	})
+	g.Go(func() error {
+		<-ctx.Done()
+		return httpServer.Shutdown(context.Background())
+	})
+	g.Go(func() error {
+		err := httpServer.ListenAndServe()
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	})
+	st.Dispatch(initAction)
+
+	fmt.Printf("Showing the snapshot at http://localhost:%d/ (ctrl-C to stop)\n", c.port)
+
+	err = g.Wait()
+	if err != context.Canceled {
+		return err
+	}
+	return nil
+}
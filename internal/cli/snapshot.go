@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type snapshotCmd struct {
+	port int
+}
+
+func (c *snapshotCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot [file]",
+		Short: "save a snapshot of a running `tilt up`, to share with a teammate",
+		Long: `Saves everything that the tilt up running in this directory shows (each
+resource's status, builds, pods, and logs) to a file. Send the file to a
+teammate, and they can look at it with:
+
+  tilt replay <file>          # in the HUD
+  tilt replay --web <file>    # in the browser
+
+By default, writes to tilt-snapshot-<time>.json in the current directory.`,
+		Args: cobra.MaximumNArgs(1),
+	}
+
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *snapshotCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.snapshot", nil)
+	defer analyticsService.Flush(time.Second)
+
+	path := fmt.Sprintf("tilt-snapshot-%s.json", time.Now().Format("20060102-150405"))
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	body, err := getFromTiltAPI(c.port, "/api/snapshot")
+	if err != nil {
+		return errors.Wrap(err, "tilt snapshot")
+	}
+
+	// The logs in a snapshot can have things in them that shouldn't be
+	// world-readable, so only the user can read it until they share it.
+	err = ioutil.WriteFile(path, body, 0600)
+	if err != nil {
+		return errors.Wrap(err, "tilt snapshot")
+	}
+
+	fmt.Printf("Saved a snapshot to %s\nView it with: tilt replay --web %s\n", path, path)
+	return nil
+}
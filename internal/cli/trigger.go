@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type triggerCmd struct {
+	all  bool
+	port int
+}
+
+func (c *triggerCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trigger [<resource> ...]",
+		Short: "build resources in a running `tilt up`",
+		Long: `Tells the tilt up that's running in this directory to build the named resources,
+as if you'd pressed space on them in the HUD.
+
+With --all, rebuilds every resource from scratch, dependencies first (e.g., after
+pulling changes, or changing a file that Tilt doesn't watch).`,
+	}
+
+	cmd.Flags().BoolVar(&c.all, "all", false, "Rebuild every resource")
+	cmd.Flags().IntVar(&c.port, "port", DefaultWebPort, "Port of the running Tilt's HTTP server")
+
+	return cmd
+}
+
+func (c *triggerCmd) run(ctx context.Context, args []string) error {
+	analyticsService.Incr("cmd.trigger", map[string]string{
+		"all":   fmt.Sprintf("%t", c.all),
+		"count": fmt.Sprintf("%d", len(args)),
+	})
+	defer analyticsService.Flush(time.Second)
+
+	if c.all == (len(args) > 0) {
+		return fmt.Errorf("name the resources to trigger, or pass --all (but not both)")
+	}
+
+	err := postToTiltAPI(c.port, "/api/trigger", map[string]interface{}{
+		"names": args,
+		"all":   c.all,
+	})
+	if err != nil {
+		return errors.Wrap(err, "tilt trigger")
+	}
+	return nil
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/opentracing/opentracing-go"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
@@ -18,10 +21,13 @@ import (
 	"github.com/windmilleng/tilt/internal/hud"
 	"github.com/windmilleng/tilt/internal/logger"
 	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/network"
 	"github.com/windmilleng/tilt/internal/output"
+	"github.com/windmilleng/tilt/internal/rty"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/internal/tiltfile"
 	"github.com/windmilleng/tilt/internal/tracer"
+	"github.com/windmilleng/tilt/pkg/tiltapi"
 )
 
 const DefaultWebPort = 10350
@@ -30,17 +36,39 @@ const DefaultWebDevPort = 46764
 var updateModeFlag string = string(engine.UpdateModeAuto)
 var webModeFlag model.WebMode = model.DefaultWebMode
 var webPort = 0
+var grpcPort = 0
+var webHost = network.Localhost
+var webTokenFlag = ""
+var webAllowedOriginsFlag []string
+var webInsecureFlag = false
 var webDevPort = 0
 var logActionsFlag bool = false
 var enableSail = false
+var imageGCKeep = 0
+var imageGCRegistry = false
+var registryRetries = int(build.DefaultRegistryRetries)
+var reuseImagesFlag = false
+var watchModeFlag = ""
+var pollIntervalFlag time.Duration
+var pollCompareFlag = ""
+var dedupByChecksumFlag = false
+var hudThemeFlag = ""
+var hudMouseFlag = true
+var hudHyperlinksFlag = false
 
 type upCmd struct {
 	watch       bool
 	browserMode string
 	traceTags   string
 	hud         bool
+	stream      bool
 	autoDeploy  bool
 	fileName    string
+
+	recordActions string
+
+	// So that we can tell which flags the user set.
+	cmd *cobra.Command
 }
 
 func (c *upCmd) register() *cobra.Command {
@@ -48,6 +76,7 @@ func (c *upCmd) register() *cobra.Command {
 		Use:   "up [<name>] [<name2>] [...]",
 		Short: "stand up one or more manifests",
 	}
+	c.cmd = cmd
 
 	cmd.Flags().BoolVar(&c.watch, "watch", true, "If true, services will be automatically rebuilt and redeployed when files change. Otherwise, each service will be started once.")
 	cmd.Flags().StringVar(&c.browserMode, "browser", "", "deprecated. TODO(nick): remove this flag")
@@ -58,12 +87,40 @@ func (c *upCmd) register() *cobra.Command {
 	cmd.Flags().StringVar(&build.ImageTagPrefix, "image-tag-prefix", build.ImageTagPrefix,
 		"For integration tests. Customize the image tag prefix so tests can write to a public registry")
 	cmd.Flags().BoolVar(&c.hud, "hud", true, "If true, tilt will open in HUD mode.")
+	cmd.Flags().BoolVar(&c.stream, "stream", false,
+		"If true, print every resource's logs and status changes to stdout instead of opening the HUD, for CI logs and piping into other tools. Defaults to true when stdout isn't a terminal")
+	cmd.Flags().BoolVar(&hudMouseFlag, "hud-mouse", true,
+		"If true, click and scroll in the HUD with the mouse. Most terminals only select text with shift held down while this is on")
+	cmd.Flags().BoolVar(&hudHyperlinksFlag, "hud-hyperlinks", rty.TerminalSupportsHyperlinks(),
+		"If true, make endpoints and file paths in the HUD clickable with OSC 8 hyperlinks. Defaults to true in terminals known to support them. Set FORCE_HYPERLINK=1 to override")
+	cmd.Flags().StringVar(&hudThemeFlag, "hud-theme", os.Getenv("TILT_HUD_THEME"),
+		fmt.Sprintf("Colors for the HUD. Values: %s. Defaults to $TILT_HUD_THEME, or none if $NO_COLOR is set", strings.Join(hud.ThemeNames(), ", ")))
 	cmd.Flags().BoolVar(&c.autoDeploy, "auto-deploy", true, "If false, tilt will wait on <spacebar> to trigger builds")
 	cmd.Flags().BoolVar(&logActionsFlag, "logactions", false, "log all actions and state changes")
 	cmd.Flags().IntVar(&webPort, "port", DefaultWebPort, "Port for the Tilt HTTP server. Set to 0 to disable.")
+	cmd.Flags().IntVar(&grpcPort, "grpc-port", tiltapi.DefaultPort, "Port for the gRPC API that editor plugins and scripts use (see pkg/tiltapi). Set to 0 to disable.")
+	cmd.Flags().StringVar(&webHost, "host", network.Localhost,
+		"Host for the Tilt HTTP server and gRPC API to listen on. Set to 0.0.0.0 to let other machines connect, and set --web-token too")
+	cmd.Flags().StringVar(&webTokenFlag, "web-token", os.Getenv("TILT_WEB_TOKEN"),
+		"If set, the Tilt HTTP server only answers requests with this token, in an 'Authorization: Bearer' header or a ?token= parameter. Defaults to $TILT_WEB_TOKEN, which tilt commands like 'tilt trigger' also use")
+	cmd.Flags().StringSliceVar(&webAllowedOriginsFlag, "web-allowed-origins", nil,
+		"Origins of other web pages (e.g., https://dash.example.com) that may call the Tilt HTTP server's API from the browser. '*' allows any, and requires --web-token")
+	cmd.Flags().BoolVar(&webInsecureFlag, "insecure", false,
+		"If true, let the Tilt HTTP server listen on a --host that other machines can reach without a --web-token")
 	cmd.Flags().IntVar(&webDevPort, "webdev-port", DefaultWebDevPort, "Port for the Tilt Dev Webpack server. Only applies when using --web-mode=local")
 	cmd.Flags().BoolVar(&enableSail, "enable-sail", false, "Open a connection to the sail server on startup")
+	cmd.Flags().IntVar(&imageGCKeep, "image-gc-keep", 0,
+		"If set, the number of Tilt-built tags to keep for each image. Older tags are removed on startup and every hour. Off by default.")
+	cmd.Flags().BoolVar(&imageGCRegistry, "image-gc-registry", false,
+		"If true, also delete old Tilt-built tags from the registry they were pushed to. Only applies with --image-gc-keep.")
+	cmd.Flags().IntVar(&registryRetries, "registry-retries", int(build.DefaultRegistryRetries),
+		"Number of times to retry a push, or a pull of a base image, after a transient registry or network error. Set to 0 to disable.")
+	cmd.Flags().BoolVar(&reuseImagesFlag, "reuse-images", false,
+		"If true, tag images with a hash of their build inputs, and skip building any image that already exists locally or in the registry.")
+	addWatchFlags(cmd)
 	cmd.Flags().Lookup("logactions").Hidden = true
+	cmd.Flags().StringVar(&c.recordActions, "record-actions", "", "Record all actions and the state after each one to this file, for stepping through with 'tilt replay'")
+	cmd.Flags().Lookup("record-actions").Hidden = true
 	cmd.Flags().StringVar(&c.fileName, "file", tiltfile.FileName, "Path to Tiltfile")
 	err := cmd.Flags().MarkHidden("image-tag-prefix")
 	if err != nil {
@@ -82,6 +139,20 @@ func (c *upCmd) register() *cobra.Command {
 }
 
 func (c *upCmd) run(ctx context.Context, args []string) error {
+	err := checkWebAuth(webPort != 0 || grpcPort != 0, webHost, webTokenFlag, webAllowedOriginsFlag, webInsecureFlag)
+	if err != nil {
+		return err
+	}
+
+	// Nobody can see a HUD that isn't in a terminal, so stream instead,
+	// unless the user told us what they want.
+	if !c.stream && !c.cmd.Flags().Changed("hud") && !c.cmd.Flags().Changed("stream") && !isatty.IsTerminal(os.Stdout.Fd()) {
+		c.stream = true
+	}
+	if c.stream {
+		c.hud = false
+	}
+
 	analyticsService.Incr("cmd.up", map[string]string{
 		"watch": fmt.Sprintf("%v", c.watch),
 		"mode":  string(updateModeFlag),
@@ -91,6 +162,16 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Up")
 	defer span.Finish()
 
+	theme, err := hud.ThemeByName(hudThemeFlag)
+	if err != nil {
+		return err
+	}
+	hud.SetMouseEnabled(hudMouseFlag)
+	rty.EnableHyperlinks(hudHyperlinksFlag)
+	if theme.NoColor {
+		color.NoColor = true
+	}
+
 	tags := tracer.TagStrToMap(c.traceTags)
 
 	for k, v := range tags {
@@ -104,6 +185,18 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 
 	upper := threads.upper
 	h := threads.hud
+	if c.stream {
+		h.StreamTo(os.Stdout)
+	}
+
+	if c.recordActions != "" {
+		recorder, err := store.NewActionRecorder(c.recordActions)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = recorder.Close() }()
+		upper.RecordActions(recorder)
+	}
 
 	l := engine.NewLogActionLogger(ctx, upper.Dispatch)
 	ctx = logger.WithLogger(ctx, l)
@@ -117,6 +210,11 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 		logOutput("Tilt analytics manually disabled by environment")
 	}
 
+	if (webPort != 0 || grpcPort != 0) && webTokenFlag == "" && !network.IsLoopbackHost(webHost) {
+		logger.Get(ctx).Infof("WARNING: the Tilt HTTP server and gRPC API are listening on %s without a --web-token (--insecure). "+
+			"Anyone who can reach this machine can see and change your resources.", webHost)
+	}
+
 	if trace {
 		traceID, err := tracer.TraceID(ctx)
 		if err != nil {
@@ -167,14 +265,64 @@ func provideUpdateModeFlag() engine.UpdateModeFlag {
 	return engine.UpdateModeFlag(updateModeFlag)
 }
 
+func provideHudTheme() (hud.Theme, error) {
+	return hud.ThemeByName(hudThemeFlag)
+}
+
+func provideRegistryRetries() build.RegistryRetries {
+	return build.RegistryRetries(registryRetries)
+}
+
+func provideReuseImages() build.ReuseImages {
+	return build.ReuseImages(reuseImagesFlag)
+}
+
+func provideImageGCConfig() engine.ImageGCConfig {
+	return engine.ImageGCConfig{
+		Keep:     imageGCKeep,
+		Registry: imageGCRegistry,
+	}
+}
+
+// Flags for how to watch files. They override the Tiltfile's watch_settings.
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&watchModeFlag, "watch-mode", "",
+		fmt.Sprintf("How to watch files for changes. Possible values: %v. Use poll on file systems without native change notifications, like NFS. Use watchman to get changes from a running Watchman daemon.", model.AllWatchModes))
+	cmd.Flags().DurationVar(&pollIntervalFlag, "poll-interval", 0,
+		fmt.Sprintf("With --watch-mode=poll, how often to scan files for changes (default %s)", model.DefaultPollInterval))
+	cmd.Flags().StringVar(&pollCompareFlag, "poll-compare", "",
+		fmt.Sprintf("With --watch-mode=poll, how to tell that a file changed. Possible values: %v (default %s)", model.AllPollCompares, model.PollCompareMtime))
+	cmd.Flags().BoolVar(&dedupByChecksumFlag, "dedup-by-checksum", false,
+		"Ignore file changes that don't change the file's contents (e.g., on file systems or with tools that rewrite files with the same bytes)")
+}
+
+func provideWatchSettingsFlag() (engine.WatchSettingsFlag, error) {
+	settings := model.WatchSettings{
+		Mode:         model.WatchMode(watchModeFlag),
+		PollInterval: pollIntervalFlag,
+		PollCompare:  model.PollCompare(pollCompareFlag),
+
+		DedupByChecksum: dedupByChecksumFlag,
+	}
+	err := settings.Validate()
+	if err != nil {
+		return engine.WatchSettingsFlag{}, err
+	}
+	return engine.WatchSettingsFlag(settings), nil
+}
+
 func provideLogActions() store.LogActionsFlag {
 	return store.LogActionsFlag(logActionsFlag)
 }
 
 func provideWebMode(b model.TiltBuild) (model.WebMode, error) {
-	switch webModeFlag {
+	return resolveWebMode(webModeFlag, b)
+}
+
+func resolveWebMode(flag model.WebMode, b model.TiltBuild) (model.WebMode, error) {
+	switch flag {
 	case model.LocalWebMode, model.ProdWebMode, model.PrecompiledWebMode:
-		return webModeFlag, nil
+		return flag, nil
 	case model.DefaultWebMode:
 		if b.Dev {
 			return model.LocalWebMode, nil
@@ -182,18 +330,55 @@ func provideWebMode(b model.TiltBuild) (model.WebMode, error) {
 			return model.ProdWebMode, nil
 		}
 	}
-	return "", model.UnrecognizedWebModeError(string(webModeFlag))
+	return "", model.UnrecognizedWebModeError(string(flag))
 }
 
 func provideWebPort() model.WebPort {
 	return model.WebPort(webPort)
 }
 
+func provideGRPCPort() model.GRPCPort {
+	return model.GRPCPort(grpcPort)
+}
+
+// The Tilt HTTP server and gRPC API can change the user's resources (and run their local commands),
+// so we don't let other machines or web pages at them without a token unless the user insists.
+func checkWebAuth(serving bool, host string, token string, allowedOrigins []string, insecure bool) error {
+	if !serving || token != "" {
+		return nil
+	}
+
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			return fmt.Errorf("--web-allowed-origins '*' lets any web page call the Tilt HTTP server, so it requires a --web-token")
+		}
+	}
+
+	if !network.IsLoopbackHost(host) && !insecure {
+		return fmt.Errorf("the Tilt HTTP server and gRPC API would listen on %s, where anyone who can reach this machine could see and change your resources. "+
+			"Set a --web-token, or pass --insecure if you're sure", host)
+	}
+	return nil
+}
+
+func provideWebHost() model.WebHost {
+	return model.WebHost(webHost)
+}
+
+func provideWebAuth() model.WebAuth {
+	return model.WebAuth{
+		Token:          webTokenFlag,
+		AllowedOrigins: webAllowedOriginsFlag,
+		Host:           model.WebHost(webHost),
+	}
+}
+
 func provideWebDevPort() model.WebDevPort {
 	return model.WebDevPort(webDevPort)
 }
 
-func provideWebURL(webPort model.WebPort) (model.WebURL, error) {
+// With a token, the URL carries it, so that the browser we open can log in.
+func provideWebURL(webPort model.WebPort, auth model.WebAuth) (model.WebURL, error) {
 	if webPort == 0 {
 		return model.WebURL{}, nil
 	}
@@ -202,6 +387,9 @@ func provideWebURL(webPort model.WebPort) (model.WebURL, error) {
 	if err != nil {
 		return model.WebURL{}, err
 	}
+	if auth.Token != "" {
+		u.RawQuery = url.Values{"token": []string{auth.Token}}.Encode()
+	}
 	return model.WebURL(*u), nil
 }
 
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckWebAuth(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		serving  bool
+		host     string
+		token    string
+		origins  []string
+		insecure bool
+		err      string
+	}{
+		{name: "localhost", serving: true, host: "localhost"},
+		{name: "loopback ip", serving: true, host: "127.0.0.1"},
+		{name: "remote host", serving: true, host: "0.0.0.0", err: "Set a --web-token, or pass --insecure"},
+		{name: "remote host with token", serving: true, host: "0.0.0.0", token: "sekret"},
+		{name: "remote host insecure", serving: true, host: "0.0.0.0", insecure: true},
+		{name: "server disabled", host: "0.0.0.0"},
+		{name: "any origin", serving: true, host: "localhost", origins: []string{"*"}, err: "requires a --web-token"},
+		{name: "any origin insecure", serving: true, host: "localhost", origins: []string{"*"}, insecure: true, err: "requires a --web-token"},
+		{name: "any origin with token", serving: true, host: "localhost", origins: []string{"*"}, token: "sekret"},
+		{name: "one origin", serving: true, host: "localhost", origins: []string{"https://dash.example.com"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkWebAuth(tc.serving, tc.host, tc.token, tc.origins, tc.insecure)
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
@@ -39,17 +39,18 @@ var K8sWireSet = wire.NewSet(
 	k8s.ProvidePortForwarder,
 	k8s.ProvideConfigNamespace,
 	k8s.ProvideKubectlRunner,
+	k8s.ProvideHelmRunner,
 	k8s.ProvideContainerRuntime,
 	k8s.ProvideServerVersion,
-	k8s.ProvideK8sClient)
+	k8s.ProvideK8sClient,
+	k8s.ProvideClientRegistry)
 
 var BaseWireSet = wire.NewSet(
 	K8sWireSet,
 
 	docker.ProvideDockerClient,
 	docker.ProvideDockerVersion,
-	docker.DefaultClient,
-	wire.Bind(new(docker.Client), new(docker.Cli)),
+	docker.ProvideClient,
 
 	dockercompose.NewDockerComposeClient,
 
@@ -63,13 +64,26 @@ var BaseWireSet = wire.NewSet(
 	engine.NewBuildController,
 	engine.NewPodWatcher,
 	engine.NewServiceWatcher,
+	engine.NewEventWatcher,
+	engine.NewReplicaSetWatcher,
+	engine.NewKubeContextWatcher,
+	engine.NewPodMetricsWatcher,
+	engine.NewDriftWatcher,
+	engine.NewReadinessChecker,
+	engine.NewEventSinkController,
+	engine.NewLocalServeController,
+	engine.NewOrphanCollector,
+	engine.NewTearDownController,
+	engine.NewRestartController,
 	engine.NewImageController,
 	engine.NewConfigsController,
+	engine.ProvideStatePersister,
 	engine.NewDockerComposeEventWatcher,
 	engine.NewDockerComposeLogManager,
 	engine.NewProfilerManager,
 
 	provideClock,
+	provideHudTheme,
 	hud.NewRenderer,
 	hud.NewDefaultHeadsUpDisplay,
 
@@ -82,9 +96,15 @@ var BaseWireSet = wire.NewSet(
 	engine.NewUpper,
 	provideAnalytics,
 	engine.ProvideAnalyticsReporter,
+	engine.NewMetricsReporter,
 	provideUpdateModeFlag,
+	provideImageGCConfig,
+	provideRegistryRetries,
+	provideReuseImages,
 	engine.NewWatchManager,
+	wire.Bind(new(store.WatchStatsReporter), new(engine.WatchManager)),
 	engine.ProvideFsWatcherMaker,
+	provideWatchSettingsFlag,
 	engine.ProvideTimerMaker,
 
 	provideWebVersion,
@@ -92,6 +112,9 @@ var BaseWireSet = wire.NewSet(
 	provideWebURL,
 	provideWebPort,
 	provideWebDevPort,
+	provideWebHost,
+	provideGRPCPort,
+	provideWebAuth,
 	server.ProvideHeadsUpServer,
 	assets.ProvideAssetServer,
 	server.ProvideHeadsUpServerController,
@@ -176,17 +199,17 @@ func wireDownDeps(ctx context.Context) (DownDeps, error) {
 type DownDeps struct {
 	tfl      tiltfile.TiltfileLoader
 	dcClient dockercompose.DockerComposeClient
-	kClient  k8s.Client
+	kClients *k8s.ClientRegistry
 }
 
 func ProvideDownDeps(
 	tfl tiltfile.TiltfileLoader,
 	dcClient dockercompose.DockerComposeClient,
-	kClient k8s.Client) DownDeps {
+	kClients *k8s.ClientRegistry) DownDeps {
 	return DownDeps{
 		tfl:      tfl,
 		dcClient: dcClient,
-		kClient:  kClient,
+		kClients: kClients,
 	}
 }
 
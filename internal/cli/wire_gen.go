@@ -37,9 +37,14 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch) (demo.Script, error)
 	storeLogActionsFlag := provideLogActions()
 	storeStore := store.NewStore(reducer, storeLogActionsFlag)
 	v := provideClock()
-	renderer := hud.NewRenderer(v)
+	theme, err := provideHudTheme()
+	if err != nil {
+		return demo.Script{}, err
+	}
+	renderer := hud.NewRenderer(v, theme)
 	modelWebPort := provideWebPort()
-	webURL, err := provideWebURL(modelWebPort)
+	webAuth := provideWebAuth()
+	webURL, err := provideWebURL(modelWebPort, webAuth)
 	if err != nil {
 		return demo.Script{}, err
 	}
@@ -64,26 +69,41 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch) (demo.Script, error)
 		return demo.Script{}, err
 	}
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
-	podWatcher := engine.NewPodWatcher(k8sClient)
+	helmRunner := k8s.ProvideHelmRunner(kubeContext)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, helmRunner, clientConfig)
+	clientRegistry := k8s.ProvideClientRegistry(kubeContext, k8sClient)
+	podWatcher := engine.NewPodWatcher(clientRegistry)
 	nodeIP, err := k8s.DetectNodeIP(ctx, env)
 	if err != nil {
 		return demo.Script{}, err
 	}
-	serviceWatcher := engine.NewServiceWatcher(k8sClient, nodeIP)
-	podLogManager := engine.NewPodLogManager(k8sClient)
-	portForwardController := engine.NewPortForwardController(k8sClient)
+	serviceWatcher := engine.NewServiceWatcher(clientRegistry, nodeIP)
+	eventWatcher := engine.NewEventWatcher(clientRegistry)
+	replicaSetWatcher := engine.NewReplicaSetWatcher(clientRegistry)
+	kubeContextWatcher := engine.NewKubeContextWatcher(kubeContext, namespace, env)
+	podMetricsWatcher := engine.NewPodMetricsWatcher(clientRegistry)
+	driftWatcher := engine.NewDriftWatcher(clientRegistry)
+	readinessChecker := engine.NewReadinessChecker()
+	eventSinkController := engine.NewEventSinkController()
+	localServeController := engine.NewLocalServeController()
+	orphanCollector := engine.NewOrphanCollector(clientRegistry)
+	podLogManager := engine.NewPodLogManager(clientRegistry)
+	portForwardController := engine.NewPortForwardController(clientRegistry)
+	engineWatchSettingsFlag, err := provideWatchSettingsFlag()
+	if err != nil {
+		return demo.Script{}, err
+	}
 	fsWatcherMaker := engine.ProvideFsWatcherMaker()
 	timerMaker := engine.ProvideTimerMaker()
-	watchManager := engine.NewWatchManager(fsWatcherMaker, timerMaker)
-	syncletManager := engine.NewSyncletManager(k8sClient)
+	watchManager := engine.NewWatchManager(fsWatcherMaker, timerMaker, engineWatchSettingsFlag)
+	syncletManager := engine.NewSyncletManager(clientRegistry)
 	engineUpdateModeFlag := provideUpdateModeFlag()
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	updateMode, err := engine.ProvideUpdateMode(engineUpdateModeFlag, env, runtime)
 	if err != nil {
 		return demo.Script{}, err
 	}
-	syncletBuildAndDeployer := engine.NewSyncletBuildAndDeployer(syncletManager, k8sClient, updateMode)
+	syncletBuildAndDeployer := engine.NewSyncletBuildAndDeployer(syncletManager, clientRegistry, updateMode)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	dockerEnv, err := docker.ProvideEnv(ctx, env, runtime, minikubeClient)
 	if err != nil {
@@ -97,30 +117,40 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch) (demo.Script, error)
 	if err != nil {
 		return demo.Script{}, err
 	}
-	cli, err := docker.DefaultClient(ctx, clientClient, version)
+	dockerClient, err := docker.ProvideClient(ctx, clientClient, version, dockerEnv)
 	if err != nil {
 		return demo.Script{}, err
 	}
-	containerUpdater := build.NewContainerUpdater(cli)
-	localContainerBuildAndDeployer := engine.NewLocalContainerBuildAndDeployer(containerUpdater, analytics, env)
+	containerUpdater := build.NewContainerUpdater(dockerClient)
+	localContainerBuildAndDeployer := engine.NewLocalContainerBuildAndDeployer(containerUpdater, analytics, env, clientRegistry)
 	labels := _wireLabelsValue
-	dockerImageBuilder := build.NewDockerImageBuilder(cli, labels)
+	registryRetries := provideRegistryRetries()
+	reuseImages := provideReuseImages()
+	dockerImageBuilder := build.NewDockerImageBuilder(dockerClient, labels, registryRetries, reuseImages)
 	imageBuilder := build.DefaultImageBuilder(dockerImageBuilder)
-	cacheBuilder := build.NewCacheBuilder(cli)
+	cacheBuilder := build.NewCacheBuilder(dockerClient)
 	clock := build.ProvideClock()
-	execCustomBuilder := build.NewExecCustomBuilder(cli, dockerEnv, clock)
-	kindPusher := engine.NewKINDPusher()
-	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, k8sClient, env, analytics, updateMode, clock, runtime, kindPusher)
+	execCustomBuilder := build.NewExecCustomBuilder(dockerClient, dockerEnv, clock)
+	kindPusher := engine.NewKINDPusher(kubeContext, dockerClient)
+	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, clientRegistry, env, analytics, updateMode, clock, runtime, kindPusher, dockerClient, dockerEnv, reuseImages)
 	dockerComposeClient := dockercompose.NewDockerComposeClient(dockerEnv)
+	tearDownController := engine.NewTearDownController(clientRegistry, dockerComposeClient)
+	restartController := engine.NewRestartController(clientRegistry, dockerComposeClient)
 	imageAndCacheBuilder := engine.NewImageAndCacheBuilder(imageBuilder, cacheBuilder, execCustomBuilder, updateMode)
-	dockerComposeBuildAndDeployer := engine.NewDockerComposeBuildAndDeployer(dockerComposeClient, cli, imageAndCacheBuilder, clock)
-	buildOrder := engine.DefaultBuildOrder(syncletBuildAndDeployer, localContainerBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, env, updateMode, runtime)
+	dockerComposeBuildAndDeployer := engine.NewDockerComposeBuildAndDeployer(dockerComposeClient, dockerClient, imageAndCacheBuilder, clock)
+	localTargetBuildAndDeployer := engine.NewLocalTargetBuildAndDeployer(clock)
+	buildOrder := engine.DefaultBuildOrder(syncletBuildAndDeployer, localContainerBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, localTargetBuildAndDeployer, env, updateMode, runtime)
 	compositeBuildAndDeployer := engine.NewCompositeBuildAndDeployer(buildOrder)
 	buildController := engine.NewBuildController(compositeBuildAndDeployer)
-	imageReaper := build.NewImageReaper(cli)
-	imageController := engine.NewImageController(imageReaper)
+	imageReaper := build.NewImageReaper(dockerClient)
+	imageGCConfig := provideImageGCConfig()
+	imageController := engine.NewImageController(imageReaper, imageGCConfig)
 	tiltfileLoader := tiltfile.ProvideTiltfileLoader(analytics, dockerComposeClient, webURL)
-	configsController := engine.NewConfigsController(tiltfileLoader)
+	statePersister, err := engine.ProvideStatePersister(dockerClient, clientRegistry)
+	if err != nil {
+		return demo.Script{}, err
+	}
+	configsController := engine.NewConfigsController(tiltfileLoader, statePersister)
 	dockerComposeEventWatcher := engine.NewDockerComposeEventWatcher(dockerComposeClient)
 	dockerComposeLogManager := engine.NewDockerComposeLogManager(dockerComposeClient)
 	profilerManager := engine.NewProfilerManager()
@@ -143,9 +173,12 @@ func wireDemo(ctx context.Context, branch demo.RepoBranch) (demo.Script, error)
 	sailRoomer := client.ProvideSailRoomer(sailURL)
 	sailDialer := client.ProvideSailDialer()
 	sailClient := client.ProvideSailClient(sailURL, sailRoomer, sailDialer)
-	headsUpServer := server.ProvideHeadsUpServer(storeStore, assetsServer, analytics, sailClient)
-	headsUpServerController := server.ProvideHeadsUpServerController(modelWebPort, headsUpServer, assetsServer)
-	v2 := engine.ProvideSubscribers(headsUpDisplay, podWatcher, serviceWatcher, podLogManager, portForwardController, watchManager, buildController, imageController, configsController, dockerComposeEventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, sailClient)
+	headsUpServer := server.ProvideHeadsUpServer(storeStore, assetsServer, analytics, sailClient, watchManager, webAuth)
+	modelWebHost := provideWebHost()
+	modelGRPCPort := provideGRPCPort()
+	headsUpServerController := server.ProvideHeadsUpServerController(modelWebHost, modelWebPort, modelGRPCPort, headsUpServer, assetsServer)
+	metricsReporter := engine.NewMetricsReporter()
+	v2 := engine.ProvideSubscribers(headsUpDisplay, podWatcher, serviceWatcher, eventWatcher, replicaSetWatcher, kubeContextWatcher, podMetricsWatcher, driftWatcher, orphanCollector, tearDownController, restartController, podLogManager, portForwardController, readinessChecker, eventSinkController, localServeController, watchManager, buildController, imageController, configsController, statePersister, dockerComposeEventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, metricsReporter, headsUpServerController, sailClient)
 	upper := engine.NewUpper(ctx, storeStore, v2)
 	script := demo.NewScript(upper, headsUpDisplay, k8sClient, env, storeStore, branch, runtime, tiltfileLoader)
 	return script, nil
@@ -158,9 +191,14 @@ var (
 
 func wireThreads(ctx context.Context) (Threads, error) {
 	v := provideClock()
-	renderer := hud.NewRenderer(v)
+	theme, err := provideHudTheme()
+	if err != nil {
+		return Threads{}, err
+	}
+	renderer := hud.NewRenderer(v, theme)
 	modelWebPort := provideWebPort()
-	webURL, err := provideWebURL(modelWebPort)
+	webAuth := provideWebAuth()
+	webURL, err := provideWebURL(modelWebPort, webAuth)
 	if err != nil {
 		return Threads{}, err
 	}
@@ -188,26 +226,41 @@ func wireThreads(ctx context.Context) (Threads, error) {
 		return Threads{}, err
 	}
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
-	podWatcher := engine.NewPodWatcher(k8sClient)
+	helmRunner := k8s.ProvideHelmRunner(kubeContext)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, helmRunner, clientConfig)
+	clientRegistry := k8s.ProvideClientRegistry(kubeContext, k8sClient)
+	podWatcher := engine.NewPodWatcher(clientRegistry)
 	nodeIP, err := k8s.DetectNodeIP(ctx, env)
 	if err != nil {
 		return Threads{}, err
 	}
-	serviceWatcher := engine.NewServiceWatcher(k8sClient, nodeIP)
-	podLogManager := engine.NewPodLogManager(k8sClient)
-	portForwardController := engine.NewPortForwardController(k8sClient)
+	serviceWatcher := engine.NewServiceWatcher(clientRegistry, nodeIP)
+	eventWatcher := engine.NewEventWatcher(clientRegistry)
+	replicaSetWatcher := engine.NewReplicaSetWatcher(clientRegistry)
+	kubeContextWatcher := engine.NewKubeContextWatcher(kubeContext, namespace, env)
+	podMetricsWatcher := engine.NewPodMetricsWatcher(clientRegistry)
+	driftWatcher := engine.NewDriftWatcher(clientRegistry)
+	readinessChecker := engine.NewReadinessChecker()
+	eventSinkController := engine.NewEventSinkController()
+	localServeController := engine.NewLocalServeController()
+	orphanCollector := engine.NewOrphanCollector(clientRegistry)
+	podLogManager := engine.NewPodLogManager(clientRegistry)
+	portForwardController := engine.NewPortForwardController(clientRegistry)
+	engineWatchSettingsFlag, err := provideWatchSettingsFlag()
+	if err != nil {
+		return Threads{}, err
+	}
 	fsWatcherMaker := engine.ProvideFsWatcherMaker()
 	timerMaker := engine.ProvideTimerMaker()
-	watchManager := engine.NewWatchManager(fsWatcherMaker, timerMaker)
-	syncletManager := engine.NewSyncletManager(k8sClient)
+	watchManager := engine.NewWatchManager(fsWatcherMaker, timerMaker, engineWatchSettingsFlag)
+	syncletManager := engine.NewSyncletManager(clientRegistry)
 	engineUpdateModeFlag := provideUpdateModeFlag()
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	updateMode, err := engine.ProvideUpdateMode(engineUpdateModeFlag, env, runtime)
 	if err != nil {
 		return Threads{}, err
 	}
-	syncletBuildAndDeployer := engine.NewSyncletBuildAndDeployer(syncletManager, k8sClient, updateMode)
+	syncletBuildAndDeployer := engine.NewSyncletBuildAndDeployer(syncletManager, clientRegistry, updateMode)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	dockerEnv, err := docker.ProvideEnv(ctx, env, runtime, minikubeClient)
 	if err != nil {
@@ -221,30 +274,40 @@ func wireThreads(ctx context.Context) (Threads, error) {
 	if err != nil {
 		return Threads{}, err
 	}
-	cli, err := docker.DefaultClient(ctx, clientClient, version)
+	dockerClient, err := docker.ProvideClient(ctx, clientClient, version, dockerEnv)
 	if err != nil {
 		return Threads{}, err
 	}
-	containerUpdater := build.NewContainerUpdater(cli)
-	localContainerBuildAndDeployer := engine.NewLocalContainerBuildAndDeployer(containerUpdater, analytics, env)
+	containerUpdater := build.NewContainerUpdater(dockerClient)
+	localContainerBuildAndDeployer := engine.NewLocalContainerBuildAndDeployer(containerUpdater, analytics, env, clientRegistry)
 	labels := _wireLabelsValue
-	dockerImageBuilder := build.NewDockerImageBuilder(cli, labels)
+	registryRetries := provideRegistryRetries()
+	reuseImages := provideReuseImages()
+	dockerImageBuilder := build.NewDockerImageBuilder(dockerClient, labels, registryRetries, reuseImages)
 	imageBuilder := build.DefaultImageBuilder(dockerImageBuilder)
-	cacheBuilder := build.NewCacheBuilder(cli)
+	cacheBuilder := build.NewCacheBuilder(dockerClient)
 	clock := build.ProvideClock()
-	execCustomBuilder := build.NewExecCustomBuilder(cli, dockerEnv, clock)
-	kindPusher := engine.NewKINDPusher()
-	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, k8sClient, env, analytics, updateMode, clock, runtime, kindPusher)
+	execCustomBuilder := build.NewExecCustomBuilder(dockerClient, dockerEnv, clock)
+	kindPusher := engine.NewKINDPusher(kubeContext, dockerClient)
+	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, clientRegistry, env, analytics, updateMode, clock, runtime, kindPusher, dockerClient, dockerEnv, reuseImages)
 	dockerComposeClient := dockercompose.NewDockerComposeClient(dockerEnv)
+	tearDownController := engine.NewTearDownController(clientRegistry, dockerComposeClient)
+	restartController := engine.NewRestartController(clientRegistry, dockerComposeClient)
 	imageAndCacheBuilder := engine.NewImageAndCacheBuilder(imageBuilder, cacheBuilder, execCustomBuilder, updateMode)
-	dockerComposeBuildAndDeployer := engine.NewDockerComposeBuildAndDeployer(dockerComposeClient, cli, imageAndCacheBuilder, clock)
-	buildOrder := engine.DefaultBuildOrder(syncletBuildAndDeployer, localContainerBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, env, updateMode, runtime)
+	dockerComposeBuildAndDeployer := engine.NewDockerComposeBuildAndDeployer(dockerComposeClient, dockerClient, imageAndCacheBuilder, clock)
+	localTargetBuildAndDeployer := engine.NewLocalTargetBuildAndDeployer(clock)
+	buildOrder := engine.DefaultBuildOrder(syncletBuildAndDeployer, localContainerBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, localTargetBuildAndDeployer, env, updateMode, runtime)
 	compositeBuildAndDeployer := engine.NewCompositeBuildAndDeployer(buildOrder)
 	buildController := engine.NewBuildController(compositeBuildAndDeployer)
-	imageReaper := build.NewImageReaper(cli)
-	imageController := engine.NewImageController(imageReaper)
+	imageReaper := build.NewImageReaper(dockerClient)
+	imageGCConfig := provideImageGCConfig()
+	imageController := engine.NewImageController(imageReaper, imageGCConfig)
 	tiltfileLoader := tiltfile.ProvideTiltfileLoader(analytics, dockerComposeClient, webURL)
-	configsController := engine.NewConfigsController(tiltfileLoader)
+	statePersister, err := engine.ProvideStatePersister(dockerClient, clientRegistry)
+	if err != nil {
+		return Threads{}, err
+	}
+	configsController := engine.NewConfigsController(tiltfileLoader, statePersister)
 	dockerComposeEventWatcher := engine.NewDockerComposeEventWatcher(dockerComposeClient)
 	dockerComposeLogManager := engine.NewDockerComposeLogManager(dockerComposeClient)
 	profilerManager := engine.NewProfilerManager()
@@ -267,9 +330,12 @@ func wireThreads(ctx context.Context) (Threads, error) {
 	sailRoomer := client.ProvideSailRoomer(sailURL)
 	sailDialer := client.ProvideSailDialer()
 	sailClient := client.ProvideSailClient(sailURL, sailRoomer, sailDialer)
-	headsUpServer := server.ProvideHeadsUpServer(storeStore, assetsServer, analytics, sailClient)
-	headsUpServerController := server.ProvideHeadsUpServerController(modelWebPort, headsUpServer, assetsServer)
-	v2 := engine.ProvideSubscribers(headsUpDisplay, podWatcher, serviceWatcher, podLogManager, portForwardController, watchManager, buildController, imageController, configsController, dockerComposeEventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, sailClient)
+	headsUpServer := server.ProvideHeadsUpServer(storeStore, assetsServer, analytics, sailClient, watchManager, webAuth)
+	modelWebHost := provideWebHost()
+	modelGRPCPort := provideGRPCPort()
+	headsUpServerController := server.ProvideHeadsUpServerController(modelWebHost, modelWebPort, modelGRPCPort, headsUpServer, assetsServer)
+	metricsReporter := engine.NewMetricsReporter()
+	v2 := engine.ProvideSubscribers(headsUpDisplay, podWatcher, serviceWatcher, eventWatcher, replicaSetWatcher, kubeContextWatcher, podMetricsWatcher, driftWatcher, orphanCollector, tearDownController, restartController, podLogManager, portForwardController, readinessChecker, eventSinkController, localServeController, watchManager, buildController, imageController, configsController, statePersister, dockerComposeEventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, metricsReporter, headsUpServerController, sailClient)
 	upper := engine.NewUpper(ctx, storeStore, v2)
 	threads := provideThreads(headsUpDisplay, upper, tiltBuild)
 	return threads, nil
@@ -289,7 +355,8 @@ func wireK8sClient(ctx context.Context) (k8s.Client, error) {
 		return nil, err
 	}
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	helmRunner := k8s.ProvideHelmRunner(kubeContext)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, helmRunner, clientConfig)
 	return k8sClient, nil
 }
 
@@ -345,7 +412,8 @@ func wireRuntime(ctx context.Context) (container.Runtime, error) {
 		return "", err
 	}
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	helmRunner := k8s.ProvideHelmRunner(kubeContext)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, helmRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	return runtime, nil
 }
@@ -381,7 +449,8 @@ func wireDockerVersion(ctx context.Context) (types.Version, error) {
 		return types.Version{}, err
 	}
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	helmRunner := k8s.ProvideHelmRunner(kubeContext)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, helmRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	dockerEnv, err := docker.ProvideEnv(ctx, env, runtime, minikubeClient)
@@ -413,7 +482,8 @@ func wireDockerEnv(ctx context.Context) (docker.Env, error) {
 		return docker.Env{}, err
 	}
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	helmRunner := k8s.ProvideHelmRunner(kubeContext)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, helmRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	dockerEnv, err := docker.ProvideEnv(ctx, env, runtime, minikubeClient)
@@ -441,7 +511,8 @@ func wireDownDeps(ctx context.Context) (DownDeps, error) {
 		return DownDeps{}, err
 	}
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext)
-	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	helmRunner := k8s.ProvideHelmRunner(kubeContext)
+	k8sClient := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, helmRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, k8sClient)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	dockerEnv, err := docker.ProvideEnv(ctx, env, runtime, minikubeClient)
@@ -450,25 +521,30 @@ func wireDownDeps(ctx context.Context) (DownDeps, error) {
 	}
 	dockerComposeClient := dockercompose.NewDockerComposeClient(dockerEnv)
 	modelWebPort := provideWebPort()
-	webURL, err := provideWebURL(modelWebPort)
+	webAuth := provideWebAuth()
+	webURL, err := provideWebURL(modelWebPort, webAuth)
 	if err != nil {
 		return DownDeps{}, err
 	}
 	tiltfileLoader := tiltfile.ProvideTiltfileLoader(analytics, dockerComposeClient, webURL)
-	downDeps := ProvideDownDeps(tiltfileLoader, dockerComposeClient, k8sClient)
+	clientRegistry := k8s.ProvideClientRegistry(kubeContext, k8sClient)
+	downDeps := ProvideDownDeps(tiltfileLoader, dockerComposeClient, clientRegistry)
 	return downDeps, nil
 }
 
 // wire.go:
 
-var K8sWireSet = wire.NewSet(k8s.ProvideEnv, k8s.DetectNodeIP, k8s.ProvideKubeContext, k8s.ProvideKubeConfig, k8s.ProvideClientConfig, k8s.ProvideClientSet, k8s.ProvideRESTConfig, k8s.ProvidePortForwarder, k8s.ProvideConfigNamespace, k8s.ProvideKubectlRunner, k8s.ProvideContainerRuntime, k8s.ProvideServerVersion, k8s.ProvideK8sClient)
+var K8sWireSet = wire.NewSet(k8s.ProvideEnv, k8s.DetectNodeIP, k8s.ProvideKubeContext, k8s.ProvideKubeConfig, k8s.ProvideClientConfig, k8s.ProvideClientSet, k8s.ProvideRESTConfig, k8s.ProvidePortForwarder, k8s.ProvideConfigNamespace, k8s.ProvideKubectlRunner, k8s.ProvideHelmRunner, k8s.ProvideContainerRuntime, k8s.ProvideServerVersion, k8s.ProvideK8sClient, k8s.ProvideClientRegistry)
 
 var BaseWireSet = wire.NewSet(
-	K8sWireSet, docker.ProvideDockerClient, docker.ProvideDockerVersion, docker.DefaultClient, wire.Bind(new(docker.Client), new(docker.Cli)), dockercompose.NewDockerComposeClient, build.NewImageReaper, tiltfile.ProvideTiltfileLoader, engine.DeployerWireSet, engine.NewPodLogManager, engine.NewPortForwardController, engine.NewBuildController, engine.NewPodWatcher, engine.NewServiceWatcher, engine.NewImageController, engine.NewConfigsController, engine.NewDockerComposeEventWatcher, engine.NewDockerComposeLogManager, engine.NewProfilerManager, provideClock, hud.NewRenderer, hud.NewDefaultHeadsUpDisplay, provideLogActions, store.NewStore, wire.Bind(new(store.RStore), new(store.Store)), provideTiltInfo, engine.ProvideSubscribers, engine.NewUpper, provideAnalytics, engine.ProvideAnalyticsReporter, provideUpdateModeFlag, engine.NewWatchManager, engine.ProvideFsWatcherMaker, engine.ProvideTimerMaker, provideWebVersion,
+	K8sWireSet, docker.ProvideDockerClient, docker.ProvideDockerVersion, docker.ProvideClient, dockercompose.NewDockerComposeClient, build.NewImageReaper, tiltfile.ProvideTiltfileLoader, engine.DeployerWireSet, engine.NewPodLogManager, engine.NewPortForwardController, engine.NewBuildController, engine.NewPodWatcher, engine.NewServiceWatcher, engine.NewEventWatcher, engine.NewReplicaSetWatcher, engine.NewImageController, engine.NewConfigsController, engine.ProvideStatePersister, engine.NewDockerComposeEventWatcher, engine.NewDockerComposeLogManager, engine.NewProfilerManager, provideClock, provideHudTheme, hud.NewRenderer, hud.NewDefaultHeadsUpDisplay, provideLogActions, store.NewStore, wire.Bind(new(store.RStore), new(store.Store)), provideTiltInfo, engine.ProvideSubscribers, engine.NewUpper, provideAnalytics, engine.ProvideAnalyticsReporter, engine.NewMetricsReporter, provideUpdateModeFlag, provideImageGCConfig, provideRegistryRetries, provideReuseImages, engine.NewWatchManager, wire.Bind(new(store.WatchStatsReporter), new(engine.WatchManager)), engine.ProvideFsWatcherMaker, provideWatchSettingsFlag, engine.ProvideTimerMaker, provideWebVersion,
 	provideWebMode,
 	provideWebURL,
 	provideWebPort,
-	provideWebDevPort, server.ProvideHeadsUpServer, assets.ProvideAssetServer, server.ProvideHeadsUpServerController, provideSailURL, client.SailWireSet, provideThreads, engine.NewKINDPusher,
+	provideWebDevPort,
+	provideWebHost,
+	provideGRPCPort,
+	provideWebAuth, server.ProvideHeadsUpServer, assets.ProvideAssetServer, server.ProvideHeadsUpServerController, provideSailURL, client.SailWireSet, provideThreads, engine.NewKINDPusher,
 )
 
 type Threads struct {
@@ -484,17 +560,17 @@ func provideThreads(h hud.HeadsUpDisplay, upper engine.Upper, b model.TiltBuild)
 type DownDeps struct {
 	tfl      tiltfile.TiltfileLoader
 	dcClient dockercompose.DockerComposeClient
-	kClient  k8s.Client
+	kClients *k8s.ClientRegistry
 }
 
 func ProvideDownDeps(
 	tfl tiltfile.TiltfileLoader,
 	dcClient dockercompose.DockerComposeClient,
-	kClient k8s.Client) DownDeps {
+	kClients *k8s.ClientRegistry) DownDeps {
 	return DownDeps{
 		tfl:      tfl,
 		dcClient: dcClient,
-		kClient:  kClient,
+		kClients: kClients,
 	}
 }
 
@@ -83,7 +83,7 @@ func (m *podMonitor) OnChange(ctx context.Context, st store.RStore) {
 		m.healthy = false
 	}
 
-	if state.CurrentlyBuilding != "" {
+	if len(state.CurrentlyBuilding) > 0 {
 		m.healthy = false
 	}
 
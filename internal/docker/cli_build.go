@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/pkg/errors"
+)
+
+// The vendored Docker API client can't forward an SSH agent into a build,
+// so builds that need `--ssh` shell out to the docker CLI instead.
+//
+// We re-encode the CLI output as the same JSON message stream that the
+// ImageBuild API returns, so that callers can read both the same way.
+func (c *Cli) imageBuildWithCLI(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
+	iidFile, err := ioutil.TempFile("", "tilt-iid")
+	if err != nil {
+		return types.ImageBuildResponse{}, errors.Wrap(err, "imageBuildWithCLI")
+	}
+	iidPath := iidFile.Name()
+	_ = iidFile.Close()
+
+	cmd := exec.CommandContext(ctx, "docker", cliBuildArgs(options, iidPath)...)
+	cmd.Stdin = buildContext
+	cmd.Env = append(os.Environ(), c.env.AsEnviron()...)
+	cmd.Env = append(cmd.Env, "DOCKER_BUILDKIT=1")
+
+	pr, pw := io.Pipe()
+	out := &jsonStreamWriter{encoder: json.NewEncoder(pw)}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	go func() {
+		defer func() {
+			_ = os.Remove(iidPath)
+		}()
+
+		err := cmd.Run()
+		if err == nil {
+			err = out.writeImageID(iidPath)
+		}
+		if err != nil {
+			out.writeError(fmt.Errorf("docker build: %v", err))
+		}
+		_ = pw.Close()
+	}()
+
+	return types.ImageBuildResponse{Body: pr}, nil
+}
+
+// The arguments to `docker build`, reading the context as a tarball from stdin.
+func cliBuildArgs(options BuildOptions, iidPath string) []string {
+	args := []string{"build"}
+	for _, spec := range options.SSHSpecs {
+		args = append(args, "--ssh", spec)
+	}
+	if options.NetworkMode != "" {
+		args = append(args, "--network", options.NetworkMode)
+	}
+	for _, host := range options.ExtraHosts {
+		args = append(args, "--add-host", host)
+	}
+	if options.Platform != "" {
+		args = append(args, "--platform", options.Platform)
+	}
+
+	keys := make([]string, 0, len(options.BuildArgs))
+	for k := range options.BuildArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := options.BuildArgs[k]
+		if v == nil {
+			args = append(args, "--build-arg", k)
+		} else {
+			args = append(args, "--build-arg", fmt.Sprintf("%s=%s", k, *v))
+		}
+	}
+
+	if options.Target != "" {
+		args = append(args, "--target", options.Target)
+	}
+	for _, tag := range options.Tags {
+		args = append(args, "--tag", tag)
+	}
+	if !options.Remove {
+		args = append(args, "--rm=false")
+	}
+
+	args = append(args, "--iidfile", iidPath)
+	if options.Dockerfile != "" {
+		args = append(args, "--file", options.Dockerfile)
+	}
+	return append(args, "-")
+}
+
+// exec.Cmd only calls Write from one goroutine at a time when
+// Stdout and Stderr are the same writer, so this needs no locking.
+type jsonStreamWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonStreamWriter) Write(p []byte) (int, error) {
+	err := w.encoder.Encode(jsonmessage.JSONMessage{Stream: string(p)})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *jsonStreamWriter) writeImageID(iidPath string) error {
+	contents, err := ioutil.ReadFile(iidPath)
+	if err != nil {
+		return errors.Wrap(err, "reading image ID")
+	}
+
+	id := strings.TrimSpace(string(contents))
+	if id == "" {
+		return fmt.Errorf("no image ID in %s", iidPath)
+	}
+
+	aux, err := json.Marshal(map[string]string{"ID": id})
+	if err != nil {
+		return err
+	}
+	raw := json.RawMessage(aux)
+	return w.encoder.Encode(jsonmessage.JSONMessage{Aux: &raw})
+}
+
+func (w *jsonStreamWriter) writeError(err error) {
+	_ = w.encoder.Encode(jsonmessage.JSONMessage{
+		Error:        &jsonmessage.JSONError{Message: err.Error()},
+		ErrorMessage: err.Error(),
+	})
+}
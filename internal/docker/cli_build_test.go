@@ -0,0 +1,36 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCLIBuildArgs(t *testing.T) {
+	version := "1.2"
+	args := cliBuildArgs(BuildOptions{
+		Dockerfile:  "Dockerfile",
+		Remove:      true,
+		BuildArgs:   map[string]*string{"VERSION": &version, "EMPTY": nil},
+		Target:      "dev",
+		NetworkMode: "host",
+		ExtraHosts:  []string{"git.internal:10.0.0.1"},
+		SSHSpecs:    []string{"default", "deploy=/keys/deploy"},
+		Platform:    "linux/amd64",
+	}, "/tmp/iid")
+
+	assert.Equal(t, []string{
+		"build",
+		"--ssh", "default",
+		"--ssh", "deploy=/keys/deploy",
+		"--network", "host",
+		"--add-host", "git.internal:10.0.0.1",
+		"--platform", "linux/amd64",
+		"--build-arg", "EMPTY",
+		"--build-arg", "VERSION=1.2",
+		"--target", "dev",
+		"--iidfile", "/tmp/iid",
+		"--file", "Dockerfile",
+		"-",
+	}, args)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/blang/semver"
 	"github.com/docker/cli/cli/config"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/tlsconfig"
@@ -35,6 +36,11 @@ type Env struct {
 	APIVersion string
 	TLSVerify  string
 	CertPath   string
+
+	// Whether the Kubernetes cluster runs its containers on this Docker daemon
+	// (e.g., Docker for Desktop, or Minikube with docker-env), so images we
+	// build are already available to the cluster without a push.
+	SharedWithCluster bool
 }
 
 // Serializes this back to environment variables for os.Environ
@@ -90,11 +96,19 @@ type Client interface {
 	ExecInContainer(ctx context.Context, cID container.ID, cmd model.Cmd, out io.Writer) error
 
 	ImagePush(ctx context.Context, image string, options types.ImagePushOptions) (io.ReadCloser, error)
+	ImagePull(ctx context.Context, image string, options types.ImagePullOptions) (io.ReadCloser, error)
 	ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error)
 	ImageTag(ctx context.Context, source, target string) error
 	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
 	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
 	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+
+	// Export images as a tarball, like `docker save`.
+	ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error)
+
+	// Credentials for the registry that hosts this image, as configured
+	// in the user's docker config.
+	RegistryAuth(ctx context.Context, ref reference.Named) (types.AuthConfig, error)
 }
 
 type ExitError struct {
@@ -116,16 +130,21 @@ var _ Client = &Cli{}
 
 type Cli struct {
 	*client.Client
+	env              Env
 	supportsBuildkit bool
 
-	creds     dockerCreds
-	initError error
-	initDone  chan bool
+	creds       dockerCreds
+	cloudTokens *cloudTokenCache
+	initError   error
+	initDone    chan bool
 }
 
 func ProvideEnv(ctx context.Context, env k8s.Env, runtime container.Runtime, minikubeClient minikube.Client) (Env, error) {
 	result := Env{}
 
+	// The docker host that the cluster runs its containers on, if we know it.
+	clusterHost := ""
+
 	if runtime == container.RuntimeDocker {
 		if env == k8s.EnvMinikube {
 			// If we're running Minikube with a docker runtime, talk to Minikube's docker socket.
@@ -137,6 +156,7 @@ func ProvideEnv(ctx context.Context, env k8s.Env, runtime container.Runtime, min
 			host := envMap["DOCKER_HOST"]
 			if host != "" {
 				result.Host = host
+				clusterHost = host
 			}
 
 			apiVersion := envMap["DOCKER_API_VERSION"]
@@ -156,6 +176,7 @@ func ProvideEnv(ctx context.Context, env k8s.Env, runtime container.Runtime, min
 		} else if env == k8s.EnvMicroK8s {
 			// If we're running Microk8s with a docker runtime, talk to Microk8s's docker socket.
 			result.Host = microK8sDockerHost
+			clusterHost = microK8sDockerHost
 		}
 	}
 
@@ -164,6 +185,9 @@ func ProvideEnv(ctx context.Context, env k8s.Env, runtime container.Runtime, min
 		// If the docker host is set from the env, ignore all the variables
 		// from minikube/microk8s
 		result = Env{Host: host}
+	} else if result.Host == "" {
+		// If Docker isn't running but Podman is, talk to Podman.
+		result.Host = podmanHostIfNoDocker()
 	}
 
 	apiVersion := os.Getenv("DOCKER_API_VERSION")
@@ -181,9 +205,24 @@ func ProvideEnv(ctx context.Context, env k8s.Env, runtime container.Runtime, min
 		result.TLSVerify = tlsVerify
 	}
 
+	if runtime == container.RuntimeDocker {
+		if env == k8s.EnvDockerDesktop {
+			// Docker for Desktop runs its cluster on the default local daemon.
+			result.SharedWithCluster = isDefaultHost(result.Host)
+		} else if clusterHost != "" {
+			// If the user pointed DOCKER_HOST somewhere else, we're not
+			// building on the cluster's daemon anymore.
+			result.SharedWithCluster = result.Host == clusterHost
+		}
+	}
+
 	return result, nil
 }
 
+func isDefaultHost(host string) bool {
+	return host == "" || host == client.DefaultDockerHost || host == "unix://"+defaultDockerSocket
+}
+
 func ProvideDockerClient(ctx context.Context, env Env) (*client.Client, error) {
 	opts, err := CreateClientOpts(ctx, env)
 	if err != nil {
@@ -204,7 +243,7 @@ func ProvideDockerVersion(ctx context.Context, client *client.Client) (types.Ver
 	return v, err
 }
 
-func DefaultClient(ctx context.Context, d *client.Client, serverVersion types.Version) (*Cli, error) {
+func DefaultClient(ctx context.Context, d *client.Client, serverVersion types.Version, env Env) (*Cli, error) {
 	if !SupportedVersion(serverVersion) {
 		return nil, fmt.Errorf("Tilt requires a Docker server newer than %s. Current Docker server: %s",
 			minDockerVersion, serverVersion.APIVersion)
@@ -212,7 +251,9 @@ func DefaultClient(ctx context.Context, d *client.Client, serverVersion types.Ve
 
 	cli := &Cli{
 		Client:           d,
+		env:              env,
 		supportsBuildkit: SupportsBuildkit(serverVersion),
+		cloudTokens:      newCloudTokenCache(),
 		initDone:         make(chan bool),
 	}
 
@@ -355,9 +396,6 @@ func (c *Cli) initCreds(ctx context.Context) dockerCreds {
 
 // Initialization that we do in the background, because
 // it may need to read from files or call out to gcloud.
-//
-// TODO(nick): Update ImagePush to use these auth credentials. This is less important
-// for local k8s (Minikube, Docker-for-Mac, MicroK8s) because they don't push.
 func (c *Cli) backgroundInit(ctx context.Context) {
 	result := make(chan dockerCreds, 1)
 
@@ -398,6 +436,17 @@ func (c *Cli) ImageBuild(ctx context.Context, buildContext io.Reader, options Bu
 	opts.BuildArgs = options.BuildArgs
 	opts.Dockerfile = options.Dockerfile
 	opts.Tags = options.Tags
+	opts.Target = options.Target
+	opts.NetworkMode = options.NetworkMode
+	opts.ExtraHosts = options.ExtraHosts
+	opts.Platform = options.Platform
+
+	if len(options.SSHSpecs) > 0 {
+		if !c.supportsBuildkit {
+			return types.ImageBuildResponse{}, fmt.Errorf("SSH forwarding requires BuildKit, which is not supported by this Docker server")
+		}
+		return c.imageBuildWithCLI(ctx, buildContext, options)
+	}
 
 	return c.Client.ImageBuild(ctx, buildContext, opts)
 }
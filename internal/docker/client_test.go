@@ -90,7 +90,7 @@ func TestProvideEnv(t *testing.T) {
 		{
 			env:      k8s.EnvMicroK8s,
 			runtime:  container.RuntimeDocker,
-			expected: Env{Host: microK8sDockerHost},
+			expected: Env{Host: microK8sDockerHost, SharedWithCluster: true},
 		},
 		{
 			env:     k8s.EnvMicroK8s,
@@ -106,10 +106,11 @@ func TestProvideEnv(t *testing.T) {
 				"DOCKER_API_VERSION": "1.35",
 			},
 			expected: Env{
-				TLSVerify:  "1",
-				Host:       "tcp://192.168.99.100:2376",
-				CertPath:   "/home/nick/.minikube/certs",
-				APIVersion: "1.35",
+				TLSVerify:         "1",
+				Host:              "tcp://192.168.99.100:2376",
+				CertPath:          "/home/nick/.minikube/certs",
+				APIVersion:        "1.35",
+				SharedWithCluster: true,
 			},
 		},
 		{
@@ -139,6 +140,34 @@ func TestProvideEnv(t *testing.T) {
 			},
 			expected: Env{},
 		},
+		{
+			// The user ran `eval $(minikube docker-env)`
+			env:     k8s.EnvMinikube,
+			runtime: container.RuntimeDocker,
+			mkEnv: map[string]string{
+				"DOCKER_HOST": "tcp://192.168.99.100:2376",
+			},
+			osEnv: map[string]string{
+				"DOCKER_HOST": "tcp://192.168.99.100:2376",
+			},
+			expected: Env{
+				Host:              "tcp://192.168.99.100:2376",
+				SharedWithCluster: true,
+			},
+		},
+		{
+			env:      k8s.EnvDockerDesktop,
+			runtime:  container.RuntimeDocker,
+			expected: Env{SharedWithCluster: true},
+		},
+		{
+			env:     k8s.EnvDockerDesktop,
+			runtime: container.RuntimeDocker,
+			osEnv: map[string]string{
+				"DOCKER_HOST": "tcp://remote-builder:2376",
+			},
+			expected: Env{Host: "tcp://remote-builder:2376"},
+		},
 	}
 
 	for i, c := range cases {
@@ -0,0 +1,164 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Cloud registries hand out short-lived tokens. If a user runs `docker login`
+// with one of these tokens at the start of the day, pushes will start failing
+// once the token expires, even though `tilt up` is still running.
+//
+// So for registries we recognize, we fetch tokens with the cloud's CLI instead,
+// and fetch a new one shortly before the old one expires.
+type cloudProvider string
+
+const (
+	cloudProviderECR cloudProvider = "ECR"
+	cloudProviderGCR cloudProvider = "GCR"
+	cloudProviderACR cloudProvider = "ACR"
+)
+
+// Refresh tokens when they have less than this much time left,
+// so that they don't expire in the middle of a push.
+const cloudTokenRefreshMargin = 10 * time.Minute
+
+// e.g., 123456789012.dkr.ecr.us-east-1.amazonaws.com
+var ecrHostRe = regexp.MustCompile(`^[0-9]{12}\.dkr\.ecr(-fips)?\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+
+// e.g., gcr.io, us.gcr.io, us-central1-docker.pkg.dev
+var gcrHostRe = regexp.MustCompile(`^(([a-z0-9-]+\.)?gcr\.io|[a-z0-9-]+-docker\.pkg\.dev)$`)
+
+// e.g., myregistry.azurecr.io
+var acrHostRe = regexp.MustCompile(`^([a-z0-9]+)\.azurecr\.io$`)
+
+type cloudRegistry struct {
+	provider cloudProvider
+	host     string
+
+	// The AWS region (for ECR) or the registry name (for ACR).
+	param string
+}
+
+func parseCloudRegistry(host string) (cloudRegistry, bool) {
+	host = strings.ToLower(host)
+	if match := ecrHostRe.FindStringSubmatch(host); match != nil {
+		return cloudRegistry{provider: cloudProviderECR, host: host, param: match[2]}, true
+	}
+	if gcrHostRe.MatchString(host) {
+		return cloudRegistry{provider: cloudProviderGCR, host: host}, true
+	}
+	if match := acrHostRe.FindStringSubmatch(host); match != nil {
+		return cloudRegistry{provider: cloudProviderACR, host: host, param: match[1]}, true
+	}
+	return cloudRegistry{}, false
+}
+
+// The command that prints a registry token, the username that goes with it,
+// and how long the token lasts.
+func (r cloudRegistry) tokenCommand() (argv []string, username string, lifetime time.Duration) {
+	switch r.provider {
+	case cloudProviderECR:
+		return []string{"aws", "ecr", "get-login-password", "--region", r.param}, "AWS", 12 * time.Hour
+	case cloudProviderGCR:
+		return []string{"gcloud", "auth", "print-access-token"}, "oauth2accesstoken", time.Hour
+	case cloudProviderACR:
+		return []string{"az", "acr", "login", "--name", r.param, "--expose-token", "--output", "tsv", "--query", "accessToken"},
+			"00000000-0000-0000-0000-000000000000", 3 * time.Hour
+	}
+	return nil, "", 0
+}
+
+// What the user needs to do if we can't get a token.
+func (r cloudRegistry) credentialsHint() string {
+	switch r.provider {
+	case cloudProviderECR:
+		return "Make sure the AWS CLI is installed and logged in (try `aws sts get-caller-identity`)"
+	case cloudProviderGCR:
+		return "Make sure the gcloud CLI is installed and logged in (try `gcloud auth login`)"
+	case cloudProviderACR:
+		return "Make sure the Azure CLI is installed and logged in (try `az login`)"
+	}
+	return ""
+}
+
+type cloudToken struct {
+	auth   types.AuthConfig
+	expiry time.Time
+}
+
+type commandRunner func(ctx context.Context, argv []string) ([]byte, error)
+
+// Caches cloud registry tokens, and fetches new ones before they expire.
+type cloudTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]cloudToken
+	run    commandRunner
+	now    func() time.Time
+}
+
+func newCloudTokenCache() *cloudTokenCache {
+	return &cloudTokenCache{
+		tokens: make(map[string]cloudToken),
+		run:    runTokenCommand,
+		now:    time.Now,
+	}
+}
+
+func (c *cloudTokenCache) authFor(ctx context.Context, r cloudRegistry) (types.AuthConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	token, ok := c.tokens[r.host]
+	if ok && now.Add(cloudTokenRefreshMargin).Before(token.expiry) {
+		return token.auth, nil
+	}
+
+	argv, username, lifetime := r.tokenCommand()
+	out, err := c.run(ctx, argv)
+	if err != nil {
+		return types.AuthConfig{}, fmt.Errorf("Fetching %s token for %s: %v\n%s", r.provider, r.host, err, r.credentialsHint())
+	}
+
+	password := strings.TrimSpace(string(out))
+	if password == "" {
+		return types.AuthConfig{}, fmt.Errorf("Fetching %s token for %s: `%s` printed an empty token\n%s",
+			r.provider, r.host, strings.Join(argv, " "), r.credentialsHint())
+	}
+
+	token = cloudToken{
+		auth: types.AuthConfig{
+			Username:      username,
+			Password:      password,
+			ServerAddress: r.host,
+		},
+		expiry: now.Add(lifetime),
+	}
+	c.tokens[r.host] = token
+	return token.auth, nil
+}
+
+func runTokenCommand(ctx context.Context, argv []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
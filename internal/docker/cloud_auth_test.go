@@ -0,0 +1,148 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestParseCloudRegistry(t *testing.T) {
+	for _, tc := range []struct {
+		host     string
+		provider cloudProvider
+		param    string
+	}{
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com", cloudProviderECR, "us-east-1"},
+		{"123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn", cloudProviderECR, "cn-north-1"},
+		{"gcr.io", cloudProviderGCR, ""},
+		{"eu.gcr.io", cloudProviderGCR, ""},
+		{"us-central1-docker.pkg.dev", cloudProviderGCR, ""},
+		{"myregistry.azurecr.io", cloudProviderACR, "myregistry"},
+		{"quay.io", "", ""},
+		{"localhost:5000", "", ""},
+	} {
+		t.Run(tc.host, func(t *testing.T) {
+			reg, ok := parseCloudRegistry(tc.host)
+			assert.Equal(t, tc.provider != "", ok)
+			assert.Equal(t, tc.provider, reg.provider)
+			assert.Equal(t, tc.param, reg.param)
+		})
+	}
+}
+
+func TestCloudTokenRefresh(t *testing.T) {
+	f := newCloudTokenFixture()
+	reg, _ := parseCloudRegistry("123456789012.dkr.ecr.us-east-1.amazonaws.com")
+
+	auth, err := f.cache.authFor(f.ctx, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "AWS", auth.Username)
+	assert.Equal(t, "token-1", auth.Password)
+	assert.Equal(t, "aws ecr get-login-password --region us-east-1", f.calls[0])
+
+	// An hour later, we should still be using the cached token.
+	f.now = f.now.Add(time.Hour)
+	auth, err = f.cache.authFor(f.ctx, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "token-1", auth.Password)
+	assert.Equal(t, 1, len(f.calls))
+
+	// Shortly before the token expires, we should fetch a new one.
+	f.now = f.now.Add(11 * time.Hour)
+	auth, err = f.cache.authFor(f.ctx, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "token-2", auth.Password)
+	assert.Equal(t, 2, len(f.calls))
+}
+
+func TestCloudTokenError(t *testing.T) {
+	f := newCloudTokenFixture()
+	f.err = fmt.Errorf("exec: \"gcloud\": executable file not found in $PATH")
+	reg, _ := parseCloudRegistry("gcr.io")
+
+	_, err := f.cache.authFor(f.ctx, reg)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Fetching GCR token for gcr.io")
+		assert.Contains(t, err.Error(), "gcloud auth login")
+	}
+}
+
+func TestRegistryAuthPrefersCredentialHelper(t *testing.T) {
+	f := newCloudTokenFixture()
+	configFile := configfile.New("")
+	configFile.CredentialHelpers = map[string]string{"gcr.io": "gcloud"}
+
+	ref, err := reference.ParseNormalizedNamed("gcr.io/some-project-162817/sancho")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The credential helper isn't installed, so we get an error,
+	// but we shouldn't have tried to fetch a token ourselves.
+	_, _ = registryAuth(f.ctx, configFile, f.cache, ref)
+	assert.Equal(t, 0, len(f.calls))
+}
+
+func TestRegistryAuthFallsBackToDockerConfig(t *testing.T) {
+	f := newCloudTokenFixture()
+	f.err = fmt.Errorf("not logged in")
+	configFile := configfile.New("")
+	configFile.AuthConfigs["gcr.io"] = types.AuthConfig{Username: "oauth2accesstoken", Password: "old-token"}
+
+	ref, err := reference.ParseNormalizedNamed("gcr.io/some-project-162817/sancho")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := registryAuth(f.ctx, configFile, f.cache, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "old-token", auth.Password)
+
+	configFile.AuthConfigs = map[string]types.AuthConfig{}
+	_, err = registryAuth(f.ctx, configFile, f.cache, ref)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not logged in")
+	}
+}
+
+type cloudTokenFixture struct {
+	ctx   context.Context
+	cache *cloudTokenCache
+	calls []string
+	now   time.Time
+	err   error
+}
+
+func newCloudTokenFixture() *cloudTokenFixture {
+	f := &cloudTokenFixture{
+		ctx: output.CtxForTest(),
+		now: time.Date(2019, 1, 1, 1, 1, 1, 1, time.UTC),
+	}
+	f.cache = newCloudTokenCache()
+	f.cache.now = func() time.Time { return f.now }
+	f.cache.run = func(ctx context.Context, argv []string) ([]byte, error) {
+		f.calls = append(f.calls, strings.Join(argv, " "))
+		if f.err != nil {
+			return nil, f.err
+		}
+		return []byte(fmt.Sprintf("token-%d\n", len(f.calls))), nil
+	}
+	return f
+}
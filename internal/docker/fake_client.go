@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"sort"
 	"time"
 
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/model"
@@ -71,6 +73,14 @@ type FakeClient struct {
 	PushOptions types.ImagePushOptions
 	PushOutput  string
 
+	// Errors that the next calls to ImagePush will throw, in order.
+	PushErrorsToThrow []error
+
+	// The images passed to each call to ImagePull, in order,
+	// and the errors that the next calls will throw.
+	PullImages        []string
+	PullErrorsToThrow []error
+
 	BuildCount        int
 	BuildOptions      BuildOptions
 	BuildOutput       string
@@ -91,8 +101,15 @@ type FakeClient struct {
 
 	RestartsByContainer map[string]int
 	RemovedImageIDs     []string
+	SavedImageIDs       []string
+
+	// If set, returned by ImageList instead of one summary per build.
+	ImageListOutput []types.ImageSummary
 
 	Images map[string]types.ImageInspect
+
+	// Credentials returned by RegistryAuth, keyed by RegistryAuthKey.
+	RegistryAuths map[string]types.AuthConfig
 }
 
 func NewFakeClient() *FakeClient {
@@ -102,6 +119,7 @@ func NewFakeClient() *FakeClient {
 		ContainerListOutput: make(map[string][]types.Container),
 		RestartsByContainer: make(map[string]int),
 		Images:              make(map[string]types.ImageInspect),
+		RegistryAuths:       make(map[string]types.AuthConfig),
 	}
 }
 
@@ -159,9 +177,32 @@ func (c *FakeClient) ImagePush(ctx context.Context, image string, options types.
 	c.PushCount++
 	c.PushImage = image
 	c.PushOptions = options
+	if len(c.PushErrorsToThrow) > 0 {
+		err := c.PushErrorsToThrow[0]
+		c.PushErrorsToThrow = c.PushErrorsToThrow[1:]
+		return nil, err
+	}
 	return NewFakeDockerResponse(c.PushOutput), nil
 }
 
+func (c *FakeClient) ImagePull(ctx context.Context, image string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	c.PullImages = append(c.PullImages, image)
+	if len(c.PullErrorsToThrow) > 0 {
+		err := c.PullErrorsToThrow[0]
+		c.PullErrorsToThrow = c.PullErrorsToThrow[1:]
+		return nil, err
+	}
+	return NewFakeDockerResponse(""), nil
+}
+
+func (c *FakeClient) RegistryAuth(ctx context.Context, ref reference.Named) (types.AuthConfig, error) {
+	key, err := RegistryAuthKey(ref)
+	if err != nil {
+		return types.AuthConfig{}, err
+	}
+	return c.RegistryAuths[key], nil
+}
+
 func (c *FakeClient) ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
 	c.BuildCount++
 	c.BuildOptions = options
@@ -192,6 +233,10 @@ func (c *FakeClient) ImageInspectWithRaw(ctx context.Context, imageID string) (t
 }
 
 func (c *FakeClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	if c.ImageListOutput != nil {
+		return c.ImageListOutput, nil
+	}
+
 	summaries := make([]types.ImageSummary, c.BuildCount)
 	for i := range summaries {
 		summaries[i] = types.ImageSummary{
@@ -208,6 +253,11 @@ func (c *FakeClient) ImageRemove(ctx context.Context, imageID string, options ty
 	return nil, nil
 }
 
+func (c *FakeClient) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	c.SavedImageIDs = append(c.SavedImageIDs, imageIDs...)
+	return ioutil.NopCloser(bytes.NewReader(nil)), nil
+}
+
 var _ Client = &FakeClient{}
 
 type fakeDockerResponse struct {
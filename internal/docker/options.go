@@ -8,4 +8,17 @@ type BuildOptions struct {
 	Remove     bool
 	BuildArgs  map[string]*string
 	Tags       []string
+	Target     string
+
+	// Equivalent to `docker build --network`
+	NetworkMode string
+
+	// Equivalent to `docker build --add-host`, in host:ip form.
+	ExtraHosts []string
+
+	// Equivalent to `docker build --ssh`, e.g. "default" to forward the SSH agent.
+	SSHSpecs []string
+
+	// Equivalent to `docker build --platform`, e.g. "linux/amd64".
+	Platform string
 }
@@ -0,0 +1,219 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/pkg/errors"
+)
+
+// Podman serves a Docker-compatible API on its own socket, so most of the
+// Docker client works against it unchanged. PodmanCli papers over the places
+// where Podman's answers differ from Docker's.
+type PodmanCli struct {
+	*Cli
+}
+
+var _ Client = PodmanCli{}
+
+func NewPodmanClient(ctx context.Context, d *client.Client, env Env) (PodmanCli, error) {
+	cli := &Cli{
+		Client: d,
+		env:    env,
+
+		// Podman reports a recent API version, but doesn't support
+		// BuildKit or build sessions.
+		supportsBuildkit: false,
+		cloudTokens:      newCloudTokenCache(),
+		initDone:         make(chan bool),
+	}
+
+	go cli.backgroundInit(ctx)
+
+	return PodmanCli{Cli: cli}, nil
+}
+
+// Talk to Podman if that's what's on the other end of the socket,
+// and Docker otherwise.
+func ProvideClient(ctx context.Context, d *client.Client, serverVersion types.Version, env Env) (Client, error) {
+	if IsPodman(serverVersion) {
+		return NewPodmanClient(ctx, d, env)
+	}
+	return DefaultClient(ctx, d, serverVersion, env)
+}
+
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// The sockets where Podman serves its Docker-compatible API,
+// for rootless and rootful Podman.
+func podmanSockets() []string {
+	var result []string
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir != "" {
+		result = append(result, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	return append(result, "/run/podman/podman.sock")
+}
+
+func podmanHostIfNoDocker() string {
+	if _, err := os.Stat(defaultDockerSocket); err == nil {
+		return ""
+	}
+
+	for _, socket := range podmanSockets() {
+		if _, err := os.Stat(socket); err == nil {
+			return "unix://" + socket
+		}
+	}
+	return ""
+}
+
+func IsPodman(v types.Version) bool {
+	for _, c := range v.Components {
+		if strings.Contains(strings.ToLower(c.Name), "podman") {
+			return true
+		}
+	}
+	return strings.Contains(strings.ToLower(v.Platform.Name), "podman")
+}
+
+func (c PodmanCli) ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
+	if len(options.SSHSpecs) > 0 {
+		return types.ImageBuildResponse{}, fmt.Errorf("SSH forwarding (ssh=) is not supported when building with Podman")
+	}
+
+	resp, err := c.Cli.ImageBuild(ctx, buildContext, options)
+	if err != nil {
+		return resp, err
+	}
+	resp.Body = normalizePodmanBuildOutput(resp.Body)
+	return resp, nil
+}
+
+// Older versions of Podman finish a build by printing the bare image ID,
+// rather than sending it in an aux message like Docker.
+var podmanImageIDRegexp = regexp.MustCompile(`^(sha256:)?([0-9a-f]{64})\s*$`)
+
+// Rewrites Podman's build output into the message stream that Docker sends.
+func normalizePodmanBuildOutput(body io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer func() {
+			_ = body.Close()
+		}()
+
+		decoder := json.NewDecoder(body)
+		encoder := json.NewEncoder(pw)
+		sawAux := false
+		imageID := ""
+		for decoder.More() {
+			message := jsonmessage.JSONMessage{}
+			err := decoder.Decode(&message)
+			if err != nil {
+				_ = pw.CloseWithError(errors.Wrap(err, "decoding podman output"))
+				return
+			}
+
+			if message.Aux != nil {
+				sawAux = true
+			}
+
+			match := podmanImageIDRegexp.FindStringSubmatch(message.Stream)
+			if match != nil {
+				imageID = "sha256:" + match[2]
+				continue
+			}
+
+			err = encoder.Encode(message)
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+
+		if !sawAux && imageID != "" {
+			aux := json.RawMessage(fmt.Sprintf(`{"ID":%q}`, imageID))
+			err := encoder.Encode(jsonmessage.JSONMessage{Aux: &aux})
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+		_ = pw.Close()
+	}()
+	return pr
+}
+
+// Podman doesn't understand wildcards in the reference filter (e.g., "my-image:*"),
+// may leave the sha256: prefix off of image IDs, and puts images without
+// a registry under localhost/. So we filter by reference ourselves,
+// and normalize the IDs and tags.
+func (c PodmanCli) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	refPatterns := options.Filters.Get("reference")
+	if len(refPatterns) > 0 {
+		options.Filters = options.Filters.Clone()
+		for _, p := range refPatterns {
+			options.Filters.Del("reference", p)
+		}
+	}
+
+	summaries, err := c.Cli.ImageList(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return normalizePodmanImages(summaries, refPatterns), nil
+}
+
+func normalizePodmanImages(summaries []types.ImageSummary, refPatterns []string) []types.ImageSummary {
+	result := make([]types.ImageSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if !strings.HasPrefix(summary.ID, "sha256:") {
+			summary.ID = "sha256:" + summary.ID
+		}
+
+		repoTags := make([]string, 0, len(summary.RepoTags))
+		for _, repoTag := range summary.RepoTags {
+			repoTags = append(repoTags, strings.TrimPrefix(repoTag, podmanLocalPrefix))
+		}
+		summary.RepoTags = repoTags
+
+		if len(refPatterns) > 0 && !podmanMatchesReference(summary.RepoTags, refPatterns) {
+			continue
+		}
+		result = append(result, summary)
+	}
+	return result
+}
+
+// Where Podman puts images that were tagged without a registry.
+const podmanLocalPrefix = "localhost/"
+
+func podmanMatchesReference(repoTags []string, patterns []string) bool {
+	for _, repoTag := range repoTags {
+		named, err := reference.ParseNormalizedNamed(repoTag)
+		if err != nil {
+			continue
+		}
+		candidates := []string{repoTag, named.String(), reference.FamiliarString(named)}
+
+		for _, pattern := range patterns {
+			for _, candidate := range candidates {
+				if ok, _ := path.Match(pattern, candidate); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPodman(t *testing.T) {
+	assert.True(t, IsPodman(types.Version{
+		Components: []types.ComponentVersion{{Name: "Podman Engine", Version: "2.0.0"}},
+	}))
+	assert.False(t, IsPodman(types.Version{
+		Components: []types.ComponentVersion{{Name: "Engine", Version: "19.03.1"}},
+	}))
+}
+
+func TestNormalizePodmanBuildOutputBareImageID(t *testing.T) {
+	id := strings.Repeat("ab", 32)
+	input := `{"stream":"STEP 1: FROM alpine\n"}
+{"stream":"` + id + `\n"}
+`
+	messages := readPodmanBuildOutput(t, input)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "STEP 1: FROM alpine\n", messages[0].Stream)
+		if assert.NotNil(t, messages[1].Aux) {
+			assert.JSONEq(t, `{"ID":"sha256:`+id+`"}`, string(*messages[1].Aux))
+		}
+	}
+}
+
+func TestNormalizePodmanBuildOutputWithAux(t *testing.T) {
+	id := strings.Repeat("ab", 32)
+	input := `{"stream":"` + id + `\n"}
+{"aux":{"ID":"sha256:` + id + `"}}
+`
+	messages := readPodmanBuildOutput(t, input)
+	if assert.Len(t, messages, 1) {
+		assert.JSONEq(t, `{"ID":"sha256:`+id+`"}`, string(*messages[0].Aux))
+	}
+}
+
+func TestNormalizePodmanImages(t *testing.T) {
+	summaries := []types.ImageSummary{
+		{ID: "abc", RepoTags: []string{"localhost/frontend:tilt-123"}},
+		{ID: "sha256:def", RepoTags: []string{"docker.io/library/frontend:tilt-456"}},
+		{ID: "sha256:ghi", RepoTags: []string{"gcr.io/project/backend:tilt-789"}},
+	}
+
+	result := normalizePodmanImages(summaries, []string{"docker.io/library/frontend:*"})
+	if assert.Len(t, result, 2) {
+		assert.Equal(t, "sha256:abc", result[0].ID)
+		assert.Equal(t, []string{"frontend:tilt-123"}, result[0].RepoTags)
+		assert.Equal(t, "sha256:def", result[1].ID)
+	}
+
+	assert.Len(t, normalizePodmanImages(summaries, nil), 3)
+}
+
+func readPodmanBuildOutput(t *testing.T, input string) []jsonmessage.JSONMessage {
+	out, err := ioutil.ReadAll(normalizePodmanBuildOutput(ioutil.NopCloser(strings.NewReader(input))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result []jsonmessage.JSONMessage
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		message := jsonmessage.JSONMessage{}
+		err := decoder.Decode(&message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, message)
+	}
+	return result
+}
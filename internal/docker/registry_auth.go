@@ -0,0 +1,142 @@
+package docker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/registry"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/logger"
+)
+
+// The key that the docker config file uses for the registry that hosts this image.
+//
+// Images on Docker Hub are stored under the legacy index URL, everything else
+// under the registry hostname.
+func RegistryAuthKey(ref reference.Named) (string, error) {
+	repoInfo, err := registry.ParseRepositoryInfo(ref)
+	if err != nil {
+		return "", errors.Wrap(err, "RegistryAuthKey")
+	}
+
+	if repoInfo.Index.Official {
+		return registry.IndexServer, nil
+	}
+	return repoInfo.Index.Name, nil
+}
+
+// Looks up the credentials for the registry that hosts this image
+// in ~/.docker/config.json, including any credential helpers and
+// credential stores configured there.
+//
+// For cloud registries with short-lived tokens (ECR, GCR, ACR), we fetch
+// and refresh tokens with the cloud's CLI, unless the docker config already
+// has a credential helper for the registry.
+//
+// Returns an empty AuthConfig if there are no credentials for this registry.
+func (c *Cli) RegistryAuth(ctx context.Context, ref reference.Named) (types.AuthConfig, error) {
+	return registryAuth(ctx, config.LoadDefaultConfigFile(ioutil.Discard), c.cloudTokens, ref)
+}
+
+func registryAuth(ctx context.Context, configFile *configfile.ConfigFile, cloudTokens *cloudTokenCache, ref reference.Named) (types.AuthConfig, error) {
+	key, err := RegistryAuthKey(ref)
+	if err != nil {
+		return types.AuthConfig{}, err
+	}
+
+	reg, isCloud := parseCloudRegistry(key)
+	if !isCloud || cloudTokens == nil || configFile.CredentialHelpers[key] != "" {
+		// Credential helpers fetch a fresh token every time we ask.
+		return registryAuthFromConfig(configFile, ref)
+	}
+
+	authConfig, err := cloudTokens.authFor(ctx, reg)
+	if err == nil {
+		return authConfig, nil
+	}
+
+	// If the user logged in some other way, try those credentials,
+	// but warn them that they may expire.
+	fallback, fallbackErr := registryAuthFromConfig(configFile, ref)
+	if fallbackErr != nil || (fallback.Username == "" && fallback.IdentityToken == "") {
+		return types.AuthConfig{}, err
+	}
+	logger.Get(ctx).Infof("%v\nFalling back to the credentials in your docker config. These may expire.", err)
+	return fallback, nil
+}
+
+func registryAuthFromConfig(configFile *configfile.ConfigFile, ref reference.Named) (types.AuthConfig, error) {
+	key, err := RegistryAuthKey(ref)
+	if err != nil {
+		return types.AuthConfig{}, err
+	}
+
+	authConfig, err := configFile.GetAuthConfig(key)
+	if err != nil {
+		return types.AuthConfig{}, errors.Wrapf(err, "reading credentials for %s", key)
+	}
+	if authConfig.ServerAddress == "" {
+		authConfig.ServerAddress = key
+	}
+	return authConfig, nil
+}
+
+// Encodes credentials in the format that the docker daemon expects
+// in the X-Registry-Auth header (e.g., for ImagePush).
+func EncodeRegistryAuth(authConfig types.AuthConfig) (string, error) {
+	buf, err := json.Marshal(authConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "EncodeRegistryAuth")
+	}
+	return base64.URLEncoding.EncodeToString(buf), nil
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// Serializes credentials in the .dockerconfigjson format that
+// Kubernetes expects in an image pull secret, keyed by registry.
+//
+// Kubernetes only understands username/password credentials, so entries
+// without them (e.g., identity tokens) are skipped.
+func DockerConfigJSON(authConfigs map[string]types.AuthConfig) ([]byte, error) {
+	result := dockerConfigJSON{Auths: make(map[string]dockerConfigEntry, len(authConfigs))}
+	for key, ac := range authConfigs {
+		if ac.Username == "" || ac.Password == "" {
+			continue
+		}
+
+		result.Auths[key] = dockerConfigEntry{
+			Username: ac.Username,
+			Password: ac.Password,
+			Email:    ac.Email,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", ac.Username, ac.Password))),
+		}
+	}
+
+	if len(result.Auths) == 0 {
+		return nil, nil
+	}
+
+	buf, err := json.Marshal(result)
+	if err != nil {
+		return nil, errors.Wrap(err, "DockerConfigJSON")
+	}
+	return buf, nil
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryAuthKey(t *testing.T) {
+	for _, tc := range []struct {
+		ref      string
+		expected string
+	}{
+		{"gcr.io/some-project-162817/sancho", "gcr.io"},
+		{"localhost:5000/sancho", "localhost:5000"},
+		{"windmill/sancho", "https://index.docker.io/v1/"},
+		{"sancho", "https://index.docker.io/v1/"},
+	} {
+		t.Run(tc.ref, func(t *testing.T) {
+			ref, err := reference.ParseNormalizedNamed(tc.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := RegistryAuthKey(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expected, key)
+		})
+	}
+}
+
+func TestRegistryAuthFromConfig(t *testing.T) {
+	configFile := configfile.New("")
+	configFile.AuthConfigs["gcr.io"] = types.AuthConfig{Username: "nick", Password: "secret"}
+
+	ref, err := reference.ParseNormalizedNamed("gcr.io/some-project-162817/sancho")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authConfig, err := registryAuthFromConfig(configFile, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "nick", authConfig.Username)
+	assert.Equal(t, "secret", authConfig.Password)
+	assert.Equal(t, "gcr.io", authConfig.ServerAddress)
+
+	ref, err = reference.ParseNormalizedNamed("quay.io/windmill/sancho")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authConfig, err = registryAuthFromConfig(configFile, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", authConfig.Username)
+}
+
+func TestDockerConfigJSON(t *testing.T) {
+	buf, err := DockerConfigJSON(map[string]types.AuthConfig{
+		"gcr.io":  {Username: "nick", Password: "secret"},
+		"quay.io": {IdentityToken: "token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual map[string]map[string]map[string]string
+	err = json.Unmarshal(buf, &actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]map[string]map[string]string{
+		"auths": {
+			"gcr.io": {
+				"username": "nick",
+				"password": "secret",
+				"auth":     "bmljazpzZWNyZXQ=",
+			},
+		},
+	}, actual)
+}
+
+func TestDockerConfigJSONEmpty(t *testing.T) {
+	buf, err := DockerConfigJSON(map[string]types.AuthConfig{
+		"quay.io": {IdentityToken: "token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, buf)
+}
@@ -22,6 +22,12 @@ type FakeDCClient struct {
 	ServicesOutput    string
 
 	UpCalls []UpCall
+
+	// The services passed to each call to Rm, in order.
+	RmCalls [][]model.TargetName
+
+	// The services passed to each call to Restart, in order.
+	RestartCalls []model.TargetName
 }
 
 // Represents a single call to Up
@@ -50,6 +56,16 @@ func (c *FakeDCClient) Down(ctx context.Context, pathToConfig string, stdout, st
 	return nil
 }
 
+func (c *FakeDCClient) Rm(ctx context.Context, pathToConfig string, serviceNames []model.TargetName, stdout, stderr io.Writer) error {
+	c.RmCalls = append(c.RmCalls, serviceNames)
+	return nil
+}
+
+func (c *FakeDCClient) Restart(ctx context.Context, pathToConfig string, serviceName model.TargetName, stdout, stderr io.Writer) error {
+	c.RestartCalls = append(c.RestartCalls, serviceName)
+	return nil
+}
+
 func (c *FakeDCClient) StreamLogs(ctx context.Context, pathToConfig string, serviceName model.TargetName) (io.ReadCloser, error) {
 	output := c.RunLogOutput[serviceName]
 	reader, writer := io.Pipe()
@@ -2,11 +2,13 @@ package dockerfile
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/docker/distribution/reference"
 	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/windmilleng/tilt/internal/model"
 )
@@ -126,6 +128,82 @@ func (d Dockerfile) ValidateBaseDockerfile() error {
 	})
 }
 
+// Stages returns the names of all the named build stages in this dockerfile,
+// in the order they're declared (i.e., `FROM golang:1.12 AS builder` declares "builder").
+func (d Dockerfile) Stages() ([]string, error) {
+	var result []string
+	err := d.visitFroms(func(node *parser.Node, stage string) bool {
+		if stage != "" {
+			result = append(result, stage)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TruncateToStage drops all the build stages after the given stage.
+//
+// When Docker builds with a --target, the stages after the target
+// never make it into the final image, so this is the part of the dockerfile
+// that describes the image's filesystem.
+func (d Dockerfile) TruncateToStage(name string) (Dockerfile, error) {
+	found := false
+	endLine := -1
+	err := d.visitFroms(func(node *parser.Node, stage string) bool {
+		if found {
+			endLine = node.StartLine
+			return false
+		}
+		found = strings.EqualFold(stage, name)
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		return "", fmt.Errorf("target stage %q not found in Dockerfile", name)
+	}
+
+	// The target stage is the last stage.
+	if endLine == -1 {
+		return d, nil
+	}
+
+	// line numbers in dockerfile nodes are 1-based instead of 0-based
+	lines := strings.Split(string(d), "\n")
+	return Dockerfile(strings.Join(lines[:endLine-1], "\n")), nil
+}
+
+// Visit each FROM directive in order, along with the name of the stage it declares (if any).
+// Stops early if the visitor returns false.
+func (d Dockerfile) visitFroms(visit func(node *parser.Node, stage string) bool) error {
+	ast, err := ParseAST(d)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range ast.result.AST.Children {
+		if node.Value != command.From {
+			continue
+		}
+
+		stage := ""
+		if node.Next != nil && node.Next.Next != nil && node.Next.Next.Next != nil &&
+			strings.EqualFold(node.Next.Next.Value, "as") {
+			stage = node.Next.Next.Next.Value
+		}
+
+		if !visit(node, stage) {
+			return nil
+		}
+	}
+	return nil
+}
+
 // Find all images referenced in this dockerfile.
 func (d Dockerfile) FindImages() ([]reference.Named, error) {
 	result := []reference.Named{}
@@ -205,6 +283,73 @@ func nodeToSync(node *parser.Node, context string) (model.Sync, error) {
 	}, nil
 }
 
+// The paths in the build context that this Dockerfile reads with ADD and COPY,
+// relative to the root of the context. Paths may contain globs.
+//
+// Returns false if we can't tell which files the Dockerfile reads
+// (e.g., it copies the whole context, or uses variables in its source paths),
+// in which case any file in the build context might affect the image.
+func (d Dockerfile) ContextSources() ([]string, bool, error) {
+	sources := []string{}
+	readsAll := false
+	err := d.traverse(func(node *parser.Node) error {
+		switch node.Value {
+		case command.Add, command.Copy:
+			inst, err := instructions.ParseInstruction(node)
+			if err != nil {
+				return err
+			}
+
+			var sd instructions.SourcesAndDest
+			switch inst := inst.(type) {
+			case *instructions.AddCommand:
+				sd = inst.SourcesAndDest
+			case *instructions.CopyCommand:
+				if inst.From != "" {
+					// Copies from another stage or image, not the build context.
+					return nil
+				}
+				sd = inst.SourcesAndDest
+			}
+
+			for _, src := range sd.Sources() {
+				if node.Value == command.Add && strings.Contains(src, "://") {
+					// Remote URL
+					continue
+				}
+				if strings.Contains(src, "$") {
+					readsAll = true
+					continue
+				}
+
+				// Docker resolves absolute source paths relative to the build context.
+				src = path.Clean(strings.TrimPrefix(filepath.ToSlash(src), "/"))
+				if src == "." || src == ".." || strings.HasPrefix(src, "../") {
+					readsAll = true
+					continue
+				}
+				sources = append(sources, src)
+			}
+
+		case command.Run:
+			// With BuildKit, RUN --mount can read anything from the build context.
+			for _, flag := range node.Flags {
+				if strings.HasPrefix(flag, "--mount") {
+					readsAll = true
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if readsAll {
+		return nil, false, nil
+	}
+	return sources, true, nil
+}
+
 func (d Dockerfile) String() string {
 	return string(d)
 }
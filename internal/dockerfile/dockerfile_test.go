@@ -96,6 +96,44 @@ RUN echo bye
 	assert.True(t, ok)
 }
 
+func TestStages(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.12 AS builder
+RUN go build
+
+FROM alpine as dev
+COPY --from=builder /go/bin/app /app
+
+FROM alpine
+`)
+	stages, err := df.Stages()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"builder", "dev"}, stages)
+}
+
+func TestTruncateToStage(t *testing.T) {
+	df := Dockerfile(`FROM golang:1.12 AS dev
+RUN go install
+
+FROM gcr.io/image-a AS prod
+COPY --from=dev /go/bin/app /app
+`)
+	truncated, err := df.TruncateToStage("dev")
+	assert.NoError(t, err)
+	assert.Equal(t, `FROM golang:1.12 AS dev
+RUN go install
+`, string(truncated))
+
+	truncated, err = df.TruncateToStage("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, df, truncated)
+
+	_, err = df.TruncateToStage("test")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `target stage "test" not found`)
+	}
+}
+
 func TestDeriveSyncs(t *testing.T) {
 	df := Dockerfile(`RUN echo 'hi'
 COPY foo /bar
@@ -136,6 +174,52 @@ func TestNoAddsToNoSyncs(t *testing.T) {
 	assert.Empty(t, syncs)
 }
 
+func TestContextSources(t *testing.T) {
+	df := Dockerfile(`FROM golang:1.10 as builder
+COPY go.mod go.sum /src/
+COPY --chown=1000 ./cmd /src/cmd
+ADD ["/pkg/*.go", "/src/pkg/"]
+ADD https://example.com/archive.tgz /tmp/
+RUN go build ./...
+
+FROM alpine
+COPY --from=builder /go/bin/app /app
+COPY static/ /static/`)
+	sources, ok, err := df.ContextSources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ok)
+	assert.Equal(t, []string{"go.mod", "go.sum", "cmd", "pkg/*.go", "static"}, sources)
+}
+
+func TestContextSourcesNoCopies(t *testing.T) {
+	df := Dockerfile(`FROM alpine
+RUN echo 'hi'`)
+	sources, ok, err := df.ContextSources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ok)
+	assert.Empty(t, sources)
+}
+
+func TestContextSourcesReadsAll(t *testing.T) {
+	for _, df := range []Dockerfile{
+		"FROM alpine\nCOPY . /src",
+		"FROM alpine\nADD / /src",
+		"FROM alpine\nCOPY ../other /src",
+		"FROM alpine\nARG DIR\nCOPY $DIR /src",
+		"FROM alpine\nRUN --mount=type=bind,target=/src make",
+	} {
+		_, ok, err := df.ContextSources()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, ok, "Dockerfile: %s", df)
+	}
+}
+
 func TestFindImages(t *testing.T) {
 	df := Dockerfile(`FROM gcr.io/image-a`)
 	images, err := df.FindImages()
@@ -23,6 +23,15 @@ func (i dockerPathMatcher) Matches(f string, isDir bool) (bool, error) {
 	return i.matcher.Matches(rp)
 }
 
+func (i dockerPathMatcher) MatchesEntireDir(f string) (bool, error) {
+	// An exception (like !node_modules/keep.js) can bring back any file
+	// under an ignored directory, so we have to look at all of them.
+	if i.matcher.Exclusions() {
+		return false, nil
+	}
+	return i.Matches(f, true)
+}
+
 func (i dockerPathMatcher) AsMatchPatterns() []string {
 	result := []string{}
 	for _, p := range i.matcher.Patterns() {
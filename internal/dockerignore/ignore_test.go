@@ -54,6 +54,19 @@ func TestException(t *testing.T) {
 	tf.AssertResult(tf.JoinPath("docs", "README.md"), false)
 }
 
+func TestMatchesEntireDir(t *testing.T) {
+	tf := newTestFixture(t, "node_modules")
+	defer tf.TearDown()
+	tf.AssertEntireDir(tf.JoinPath("node_modules"), true)
+	tf.AssertEntireDir(tf.JoinPath("src"), false)
+}
+
+func TestExceptionDoesNotMatchEntireDir(t *testing.T) {
+	tf := newTestFixture(t, "docs", "!docs/README.md")
+	defer tf.TearDown()
+	tf.AssertEntireDir(tf.JoinPath("docs"), false)
+}
+
 func TestNoDockerignoreFile(t *testing.T) {
 	tf := newTestFixture(t)
 	defer tf.TearDown()
@@ -104,6 +117,13 @@ func (tf *testFixture) AssertResult(path string, expectedMatches bool) {
 	}
 }
 
+func (tf *testFixture) AssertEntireDir(path string, expected bool) {
+	actual, err := model.MatchesEntireDir(tf.tester, path)
+	if assert.NoError(tf.t, err) {
+		assert.Equalf(tf.t, expected, actual, "Expected MatchesEntireDir to be %t for dir %s", expected, path)
+	}
+}
+
 func (tf *testFixture) TearDown() {
 	tf.repoRoot.TearDown()
 }
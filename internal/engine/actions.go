@@ -4,7 +4,9 @@ import (
 	"net/url"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
 
 	"github.com/windmilleng/tilt/internal/dockercompose"
 	"github.com/windmilleng/tilt/internal/k8s"
@@ -37,6 +39,115 @@ func NewServiceChangeAction(service *v1.Service, url *url.URL) ServiceChangeActi
 	return ServiceChangeAction{Service: service, URL: url}
 }
 
+type IngressChangeAction struct {
+	Ingress *extv1beta1.Ingress
+
+	// Set if the Ingress was deleted.
+	Deleted bool
+}
+
+func (IngressChangeAction) Action() {}
+
+type ReplicaSetChangeAction struct {
+	ReplicaSet *appsv1.ReplicaSet
+}
+
+func (ReplicaSetChangeAction) Action() {}
+
+// A k8s event that explains why one of our pods is failing.
+type K8sEventAction struct {
+	Event *v1.Event
+
+	// Where objects that don't set a namespace in their YAML go,
+	// on the cluster that the event came from.
+	ConfigNamespace k8s.Namespace
+}
+
+func (K8sEventAction) Action() {}
+
+// Sent when we apply a target's objects, with the YAML we applied.
+type K8sAppliedAction struct {
+	TargetID model.TargetID
+	YAML     string
+}
+
+func (K8sAppliedAction) Action() {}
+
+// Sent when the objects in the cluster stop (or start again) matching
+// what we applied, e.g., because someone ran `kubectl edit`.
+type K8sDriftAction struct {
+	ManifestName model.ManifestName
+
+	// The YAML we compared against, so that we can ignore the result
+	// if we applied something new in the meantime.
+	AppliedYAML string
+
+	// Empty if the objects match.
+	Drift string
+}
+
+func (K8sDriftAction) Action() {}
+
+// Sent each time a manifest's readiness check runs, until it passes.
+type ReadinessCheckAction struct {
+	ManifestName model.ManifestName
+
+	// The deploy that we checked, so that we can ignore the result
+	// if the manifest redeployed in the meantime.
+	DeployTime time.Time
+
+	// Nil if the check passed.
+	Error error
+}
+
+func (ReadinessCheckAction) Action() {}
+
+// Sent when a crashing manifest has waited long enough to rebuild.
+type CrashBackoffExpiredAction struct {
+	ManifestName model.ManifestName
+
+	// The backoff that expired, so that we can ignore it
+	// if the manifest started a new one in the meantime.
+	Until time.Time
+}
+
+func (CrashBackoffExpiredAction) Action() {}
+
+// Sent when we've deleted the objects of a resource that the user tore down.
+type TearDownCompleteAction struct {
+	ManifestName model.ManifestName
+	Error        error
+}
+
+func (TearDownCompleteAction) Action() {}
+
+type RestartCompleteAction struct {
+	ManifestName model.ManifestName
+	Error        error
+}
+
+func (RestartCompleteAction) Action() {}
+
+// Sent when we've handled a request to delete the objects from removed resources.
+type OrphansDeletedAction struct{}
+
+func (OrphansDeletedAction) Action() {}
+
+// Sent with how much CPU and memory pods are using, from metrics-server.
+type PodMetricsAction struct {
+	Metrics []k8s.PodMetrics
+}
+
+func (PodMetricsAction) Action() {}
+
+// Sent when the kubeconfig starts (or stops) pointing somewhere other than where Tilt started.
+type KubeContextChangeAction struct {
+	// Empty when the kubeconfig points back to where Tilt started.
+	Alert string
+}
+
+func (KubeContextChangeAction) Action() {}
+
 type BuildLogAction struct {
 	store.LogEvent
 	ManifestName model.ManifestName
@@ -44,6 +155,8 @@ type BuildLogAction struct {
 
 func (BuildLogAction) Action() {}
 
+func (BuildLogAction) Source() model.LogSource { return model.LogSourceBuild }
+
 type PodLogAction struct {
 	store.LogEvent
 	ManifestName model.ManifestName
@@ -52,6 +165,17 @@ type PodLogAction struct {
 
 func (PodLogAction) Action() {}
 
+func (PodLogAction) Source() model.LogSource { return model.LogSourceRuntime }
+
+type PortForwardStatusAction struct {
+	ManifestName model.ManifestName
+	PodID        k8s.PodID
+	LocalPort    int
+	Status       store.PortForwardStatus
+}
+
+func (PortForwardStatusAction) Action() {}
+
 type DeployIDAction struct {
 	TargetID model.TargetID
 	DeployID model.DeployID
@@ -78,16 +202,21 @@ func NewDeployIDActionsForTargets(ids []model.TargetID, dID model.DeployID) []De
 }
 
 type BuildCompleteAction struct {
-	Result store.BuildResultSet
-	Error  error
+	ManifestName model.ManifestName
+	Result       store.BuildResultSet
+	Error        error
+
+	// Why the build fell back from the update method we tried first, if it did.
+	FallbackReason string
 }
 
 func (BuildCompleteAction) Action() {}
 
-func NewBuildCompleteAction(result store.BuildResultSet, err error) BuildCompleteAction {
+func NewBuildCompleteAction(mn model.ManifestName, result store.BuildResultSet, err error) BuildCompleteAction {
 	return BuildCompleteAction{
-		Result: result,
-		Error:  err,
+		ManifestName: mn,
+		Result:       result,
+		Error:        err,
 	}
 }
 
@@ -151,6 +280,18 @@ type ConfigsReloadedAction struct {
 	TiltIgnoreContents string
 	ConfigFiles        []string
 
+	// Manifests whose build results we loaded from the last run of tilt,
+	// so they don't need an initial build.
+	RestoredManifests map[model.ManifestName]RestoredManifest
+
+	MaxParallelUpdates int
+	EventSinks         []model.EventSink
+	WatchSettings      model.WatchSettings
+
+	// Every resource in the Tiltfile, including the ones that
+	// `tilt up <names>` left out of Manifests.
+	AllManifestNames []model.ManifestName
+
 	StartTime  time.Time
 	FinishTime time.Time
 	Err        error
@@ -172,8 +313,33 @@ type DockerComposeLogAction struct {
 
 func (DockerComposeLogAction) Action() {}
 
+func (DockerComposeLogAction) Source() model.LogSource { return model.LogSourceRuntime }
+
+type LocalServeLogAction struct {
+	store.LogEvent
+	ManifestName model.ManifestName
+}
+
+func (LocalServeLogAction) Action() {}
+
+func (LocalServeLogAction) Source() model.LogSource { return model.LogSourceRuntime }
+
+// Sent when a serve_cmd starts or exits. StartTime identifies the process,
+// so that we can ignore exits from a process we already replaced.
+type LocalServeStatusAction struct {
+	ManifestName model.ManifestName
+	StartTime    time.Time
+	Status       store.LocalServeStatus
+	PID          int
+	Error        error
+}
+
+func (LocalServeStatusAction) Action() {}
+
 type TiltfileLogAction struct {
 	store.LogEvent
 }
 
 func (TiltfileLogAction) Action() {}
+
+func (TiltfileLogAction) Source() model.LogSource { return model.LogSourceBuild }
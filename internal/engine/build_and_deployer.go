@@ -71,7 +71,11 @@ func (composite *CompositeBuildAndDeployer) BuildAndDeploy(ctx context.Context,
 		if redirectErr, ok := err.(RedirectToNextBuilder); ok {
 			s := fmt.Sprintf("falling back to next update method because: %v\n", err)
 			logger.Get(ctx).Write(redirectErr.level, s)
+			if redirectErr.level != logger.DebugLvl {
+				recordFallback(ctx, err)
+			}
 		} else {
+			recordFallback(ctx, err)
 			lastUnexpectedErr = err
 			if i+1 < len(composite.builders) {
 				logger.Get(ctx).Infof("got unexpected error during build/deploy: %v", err)
@@ -87,34 +91,34 @@ func (composite *CompositeBuildAndDeployer) BuildAndDeploy(ctx context.Context,
 	return store.BuildResultSet{}, lastErr
 }
 
-func DefaultBuildOrder(sbad *SyncletBuildAndDeployer, cbad *LocalContainerBuildAndDeployer, ibad *ImageBuildAndDeployer, dcbad *DockerComposeBuildAndDeployer, env k8s.Env, updMode UpdateMode, runtime container.Runtime) BuildOrder {
+func DefaultBuildOrder(sbad *SyncletBuildAndDeployer, cbad *LocalContainerBuildAndDeployer, ibad *ImageBuildAndDeployer, dcbad *DockerComposeBuildAndDeployer, ltbad *LocalTargetBuildAndDeployer, env k8s.Env, updMode UpdateMode, runtime container.Runtime) BuildOrder {
 
 	if updMode == UpdateModeImage || updMode == UpdateModeNaive {
-		return BuildOrder{dcbad, ibad}
+		return BuildOrder{ltbad, dcbad, ibad}
 	}
 
 	if updMode == UpdateModeKubectlExec {
-		return BuildOrder{sbad, dcbad, ibad}
+		return BuildOrder{ltbad, sbad, dcbad, ibad}
 	}
 
 	if updMode == UpdateModeContainer {
-		return BuildOrder{cbad, dcbad, ibad}
+		return BuildOrder{ltbad, cbad, dcbad, ibad}
 	}
 
 	if updMode == UpdateModeSynclet {
 		if runtime == container.RuntimeDocker {
 			ibad.SetInjectSynclet(true)
 		}
-		return BuildOrder{sbad, dcbad, ibad}
+		return BuildOrder{ltbad, sbad, dcbad, ibad}
 	}
 
 	if env.IsLocalCluster() && runtime == container.RuntimeDocker {
-		return BuildOrder{cbad, dcbad, ibad}
+		return BuildOrder{ltbad, cbad, dcbad, ibad}
 	}
 
 	if runtime == container.RuntimeDocker {
 		ibad.SetInjectSynclet(true)
 	}
 
-	return BuildOrder{sbad, cbad, dcbad, ibad}
+	return BuildOrder{ltbad, sbad, cbad, dcbad, ibad}
 }
@@ -159,6 +159,7 @@ func TestContainerBuildLocal(t *testing.T) {
 	_, hasResult := result[id]
 	assert.True(t, hasResult)
 	assert.Equal(t, k8s.MagicTestContainerID, result.OneAndOnlyContainerID().String())
+	assert.Equal(t, model.UpdateTypeLiveUpdate, result.UpdateType())
 }
 
 func TestContainerBuildSynclet(t *testing.T) {
@@ -421,7 +422,8 @@ func TestFallBackToImageDeploy(t *testing.T) {
 
 	manifest := NewSanchoFastBuildManifest(f)
 	targets := buildTargets(manifest)
-	_, err := f.bd.BuildAndDeploy(f.ctx, f.st, targets, bs)
+	ctx, fallback := withFallbackRecorder(f.ctx)
+	result, err := f.bd.BuildAndDeploy(ctx, f.st, targets, bs)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -430,6 +432,8 @@ func TestFallBackToImageDeploy(t *testing.T) {
 	if f.docker.BuildCount != 1 {
 		t.Errorf("Expected 1 docker build, actual: %d", f.docker.BuildCount)
 	}
+	assert.Equal(t, model.UpdateTypeImageBuild, result.UpdateType())
+	assert.Contains(t, fallback.reason, "some random error")
 }
 
 func TestNoFallbackForDontFallBackError(t *testing.T) {
@@ -31,6 +31,29 @@ func RedirectToNextBuilderInfof(msg string, a ...interface{}) RedirectToNextBuil
 
 var _ error = RedirectToNextBuilder{}
 
+type fallbackKey struct{}
+
+// Remembers why a build fell back from one builder to the next, so that we
+// can tell the user why they got an image build instead of a live update.
+type fallbackRecorder struct {
+	reason string
+}
+
+func withFallbackRecorder(ctx context.Context) (context.Context, *fallbackRecorder) {
+	r := &fallbackRecorder{}
+	return context.WithValue(ctx, fallbackKey{}, r), r
+}
+
+// Only the first fallback that we show the user counts: that's the builder
+// they expected to run.
+func recordFallback(ctx context.Context, err error) {
+	r, ok := ctx.Value(fallbackKey{}).(*fallbackRecorder)
+	if !ok || r.reason != "" {
+		return
+	}
+	r.reason = err.Error()
+}
+
 // Something is wrong enough that we shouldn't bother falling back to other
 // BaD's -- they won't work.
 type DontFallBackError struct {
@@ -47,6 +70,24 @@ func DontFallBackErrorf(msg string, a ...interface{}) DontFallBackError {
 
 var _ error = DontFallBackError{}
 
+// The build was canceled before it finished, either because newer changes
+// superseded it or because the user asked. Not a permanent error: the next
+// build starts fresh from the latest state.
+type BuildCanceledError struct {
+	reason string
+}
+
+func (e BuildCanceledError) Error() string {
+	return fmt.Sprintf("Build canceled: %s", e.reason)
+}
+
+var _ error = BuildCanceledError{}
+
+func isBuildCanceled(err error) bool {
+	_, ok := errors.Cause(err).(BuildCanceledError)
+	return ok
+}
+
 // A permanent error indicates that the whole build pipeline needs to stop.
 // It will never recover, even on subsequent rebuilds.
 func isPermanentError(err error) bool {
@@ -3,8 +3,14 @@ package engine
 import (
 	"context"
 	"sort"
+	"sync"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	v1 "k8s.io/api/core/v1"
+
 	"github.com/windmilleng/tilt/internal/logger"
 	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/ospath"
@@ -13,8 +19,43 @@ import (
 
 type BuildController struct {
 	b                  BuildAndDeployer
-	lastActionCount    int
+	buildsStartedCount int
 	disabledForTesting bool
+
+	// The builds in progress, so that we can cancel them if newer
+	// changes come in or the user asks.
+	mu       sync.Mutex
+	building map[model.ManifestName]*buildInProgress
+
+	// The crash backoffs that we've scheduled a wake-up for.
+	crashBackoffs map[model.ManifestName]time.Time
+
+	// The updates that built, and whose resources we're waiting on to be
+	// ready, so that the update's trace covers the whole edit-to-ready time.
+	// Guarded by mu.
+	waitingForReady map[model.ManifestName]readyWait
+}
+
+type buildInProgress struct {
+	cancel context.CancelFunc
+	reason string
+}
+
+type readyWait struct {
+	update     opentracing.Span
+	wait       opentracing.Span
+	buildStart time.Time
+}
+
+// If the resource won't be ready (e.g., because a newer build started),
+// the reason why.
+func (w readyWait) finish(reason string) {
+	w.wait.SetTag("ready", reason == "")
+	if reason != "" {
+		w.wait.LogFields(log.String("event", reason))
+	}
+	w.wait.Finish()
+	w.update.Finish()
 }
 
 type buildEntry struct {
@@ -23,12 +64,18 @@ type buildEntry struct {
 	buildStateSet store.BuildStateSet
 	buildReason   model.BuildReason
 	firstBuild    bool
+
+	// Whether other builds can run at the same time, and interleave
+	// their logs with this one's.
+	parallel bool
 }
 
 func NewBuildController(b BuildAndDeployer) *BuildController {
 	return &BuildController{
 		b:               b,
-		lastActionCount: -1,
+		building:        make(map[model.ManifestName]*buildInProgress),
+		crashBackoffs:   make(map[model.ManifestName]time.Time),
+		waitingForReady: make(map[model.ManifestName]readyWait),
 	}
 }
 
@@ -40,9 +87,47 @@ func nextTargetToBuild(state store.EngineState) *store.ManifestTarget {
 		return nil
 	}
 
+	if state.AvailableBuildSlots() < 1 {
+		return nil
+	}
+
+	// Two manifests that build the same image can't build at the same time,
+	// or they'd step on each other's builds.
+	buildingImages := make(map[model.TargetID]bool)
+	for mn := range state.CurrentlyBuilding {
+		mt, ok := state.ManifestTargets[mn]
+		if !ok {
+			continue
+		}
+		for _, iTarget := range mt.Manifest.ImageTargets {
+			buildingImages[iTarget.ID()] = true
+		}
+	}
+
 	// put no-build manifests first since they're more likely to be
 	// 1. fast and 2. dependencies of other services (e.g., redis)
-	targets := append([]*store.ManifestTarget{}, state.Targets()...)
+	var targets []*store.ManifestTarget
+	for _, mt := range state.EnabledTargets() {
+		if state.CurrentlyBuilding[mt.Manifest.Name] || sharesImage(mt.Manifest, buildingImages) {
+			continue
+		}
+		// Don't deploy to k8s while the kubeconfig points somewhere else.
+		// The changes stay pending, so we'll deploy them once it points back.
+		if state.KubeContextAlert != "" && mt.Manifest.IsK8s() {
+			continue
+		}
+		// Don't build anything, even a first build or a trigger, while the user
+		// has updates paused. It all stays pending until they resume.
+		if state.UpdatesPausedForManifest(mt.Manifest.Name) {
+			continue
+		}
+		// Don't deploy a resource until the resources it depends on are ready.
+		// Its changes stay pending (or its trigger stays queued) until then.
+		if len(state.UnreadyDependencies(mt.Manifest)) > 0 {
+			continue
+		}
+		targets = append(targets, mt)
+	}
 	sort.Sort(newNoBuildsManifestsFirst(targets))
 
 	// First, go through all the manifests in order.
@@ -53,42 +138,52 @@ func nextTargetToBuild(state store.EngineState) *store.ManifestTarget {
 		}
 	}
 
-	// Next go through all the manifests, and check:
-	// 1) all pending file changes, and
-	// 2) all pending manifest changes
-	// The earliest one is the one we want.
-	var choice *store.ManifestTarget
-	earliest := time.Now()
+	// Builds that the user asked for explicitly jump the queue,
+	// most recent trigger first.
+	for i := len(state.TriggerQueue) - 1; i >= 0; i-- {
+		for _, mt := range targets {
+			if mt.Manifest.Name == state.TriggerQueue[i] {
+				return mt
+			}
+		}
+	}
 
 	// always use a stable iteration order
 	for _, mt := range targets {
-		// Always prioritize builds that crashes and have an out-of-sync.
-		if mt.State.NeedsRebuildFromCrash {
+		// Always prioritize builds that crashes and have an out-of-sync,
+		// unless it keeps crashing and we're backing off.
+		if mt.State.NeedsRebuildFromCrash && mt.State.CrashBackoffUntil.IsZero() {
 			return mt
 		}
 	}
 
-	if state.TriggerMode == model.TriggerManual && len(state.TriggerQueue) > 0 {
-		mn := state.TriggerQueue[0]
-		mt, ok := state.ManifestTargets[mn]
-		if ok {
-			return mt
+	// Otherwise, build the manifest with the most recent pending change,
+	// since that's probably the one the user is working on right now.
+	var choice *store.ManifestTarget
+	var latest time.Time
+	for _, mt := range targets {
+		if state.TriggerModeForManifest(mt.Manifest) != model.TriggerAuto {
+			continue
 		}
-	}
-
-	if state.TriggerMode == model.TriggerAuto {
-		for _, mt := range targets {
-			ok, newTime := mt.State.HasPendingChangesBefore(earliest)
-			if ok {
-				choice = mt
-				earliest = newTime
-			}
+		ok, newTime := mt.State.MostRecentPendingChange()
+		if ok && newTime.After(latest) {
+			choice = mt
+			latest = newTime
 		}
 	}
 
 	return choice
 }
 
+func sharesImage(m model.Manifest, images map[model.TargetID]bool) bool {
+	for _, iTarget := range m.ImageTargets {
+		if images[iTarget.ID()] {
+			return true
+		}
+	}
+	return false
+}
+
 type noBuildManifestsFirst struct {
 	mts             []*store.ManifestTarget
 	origIndexByName map[string]int
@@ -145,9 +240,9 @@ func (c *BuildController) needsBuild(ctx context.Context, st store.RStore) (buil
 	state := st.RLockState()
 	defer st.RUnlockState()
 
-	// Don't start the next build until the previous action has been recorded,
+	// Don't start the next build until the last one we started has been recorded,
 	// so that we don't accidentally repeat the same build.
-	if c.lastActionCount == state.BuildControllerActionCount {
+	if c.buildsStartedCount != state.StartedBuildCount {
 		return buildEntry{}, false
 	}
 
@@ -156,14 +251,15 @@ func (c *BuildController) needsBuild(ctx context.Context, st store.RStore) (buil
 		return buildEntry{}, false
 	}
 
-	c.lastActionCount = state.BuildControllerActionCount
+	c.buildsStartedCount++
 	ms := mt.State
 	manifest := mt.Manifest
 	firstBuild := !ms.StartedFirstBuild()
 
 	buildReason := ms.NextBuildReason()
 	targets := buildTargets(manifest)
-	buildStateSet := buildStateSet(manifest, targets, ms)
+	redeploy := firstBuild || ms.NeedsRebuildFromCrash || isInTriggerQueue(state, manifest.Name)
+	buildStateSet := buildStateSet(manifest, targets, ms, redeploy)
 
 	return buildEntry{
 		name:          manifest.Name,
@@ -171,9 +267,22 @@ func (c *BuildController) needsBuild(ctx context.Context, st store.RStore) (buil
 		firstBuild:    firstBuild,
 		buildReason:   buildReason,
 		buildStateSet: buildStateSet,
+		parallel:      state.MaxParallelUpdates > 1,
 	}, true
 }
 
+// With parallel builds, the normal log stream interleaves their logs, so mark
+// each line with the resource it came from, like we do for pod logs.
+// The resource's own build log doesn't need it.
+func withBuildLogPrefix(ctx context.Context, entry buildEntry) context.Context {
+	if !entry.parallel {
+		return ctx
+	}
+	l := logger.Get(ctx)
+	w := logger.NewPrefixedWriter(logPrefix(entry.name.String()), l.Writer(logger.InfoLvl))
+	return logger.WithLogger(ctx, logger.NewLogger(l.Level(), w))
+}
+
 func (c *BuildController) DisableForTesting() {
 	c.disabledForTesting = true
 }
@@ -182,33 +291,213 @@ func (c *BuildController) OnChange(ctx context.Context, st store.RStore) {
 	if c.disabledForTesting {
 		return
 	}
+	c.maybeCancelBuilds(st)
+	c.scheduleCrashBackoffs(ctx, st)
+	c.finishReadyWaits(st)
+
 	entry, ok := c.needsBuild(ctx, st)
 	if !ok {
 		return
 	}
 
+	buildCtx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.building[entry.name] = &buildInProgress{cancel: cancel}
+	c.mu.Unlock()
+
 	go func() {
+		defer cancel()
+
 		// Send the logs to both the EngineState and the normal log stream.
 		actionWriter := BuildLogActionWriter{
 			store:        st,
 			manifestName: entry.name,
 		}
-		ctx = logger.CtxWithForkedOutput(ctx, actionWriter)
+		buildCtx := logger.CtxWithForkedOutput(withBuildLogPrefix(buildCtx, entry), actionWriter)
 
+		// Each update is its own trace, rather than part of the one for
+		// the whole `tilt up`, so that they're easy to find and compare.
 		filesChanged := entry.buildStateSet.FilesChanged()
+		startTime := time.Now()
+		span := opentracing.StartSpan("update", opentracing.StartTime(startTime))
+		span.SetTag("resource", entry.name.String())
+		span.SetTag("first_build", entry.firstBuild)
+		span.SetTag("files_changed", len(filesChanged))
+		buildCtx = opentracing.ContextWithSpan(buildCtx, span)
+
 		st.Dispatch(BuildStartedAction{
 			ManifestName: entry.name,
-			StartTime:    time.Now(),
+			StartTime:    startTime,
 			FilesChanged: filesChanged,
 			Reason:       entry.buildReason,
 		})
-		c.logBuildEntry(ctx, entry, filesChanged)
+		c.logBuildEntry(buildCtx, entry, filesChanged)
 
-		result, err := c.buildAndDeploy(ctx, st, entry)
-		st.Dispatch(NewBuildCompleteAction(result, err))
+		buildCtx, fallback := withFallbackRecorder(buildCtx)
+		result, err := c.buildAndDeploy(buildCtx, st, entry)
+
+		c.mu.Lock()
+		reason := c.building[entry.name].reason
+		delete(c.building, entry.name)
+		c.mu.Unlock()
+
+		// If the whole engine is shutting down, let the cancellation through
+		// as a permanent error. Otherwise, we canceled this build on purpose.
+		if err != nil && reason != "" && ctx.Err() == nil {
+			err = BuildCanceledError{reason: reason}
+		}
+
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(log.String("event", "error"), log.String("message", err.Error()))
+			span.Finish()
+		} else {
+			c.mu.Lock()
+			c.waitingForReady[entry.name] = readyWait{
+				update:     span,
+				wait:       opentracing.StartSpan("wait-for-ready", opentracing.ChildOf(span.Context())),
+				buildStart: startTime,
+			}
+			c.mu.Unlock()
+		}
+
+		action := NewBuildCompleteAction(entry.name, result, err)
+		action.FallbackReason = fallback.reason
+		st.Dispatch(action)
 	}()
 }
 
+// Nothing else changes in the store when a crash backoff runs out,
+// so we have to tell it, or the crash rebuild would never happen.
+func (c *BuildController) scheduleCrashBackoffs(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	for _, mt := range state.Targets() {
+		name := mt.Manifest.Name
+		until := mt.State.CrashBackoffUntil
+		if until.IsZero() || c.crashBackoffs[name].Equal(until) {
+			continue
+		}
+		c.crashBackoffs[name] = until
+
+		go func() {
+			select {
+			case <-time.After(time.Until(until)):
+				st.Dispatch(CrashBackoffExpiredAction{ManifestName: name, Until: until})
+			case <-ctx.Done():
+			}
+		}()
+	}
+}
+
+// Ends the trace of each update whose resource is now ready, or won't be,
+// because a newer build started, a pod failed (e.g., a Job's), or the
+// resource went away.
+func (c *BuildController) finishReadyWaits(st store.RStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.waitingForReady) == 0 {
+		return
+	}
+
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	for name, w := range c.waitingForReady {
+		mt, ok := state.ManifestTargets[name]
+		switch {
+		case !ok || mt.State.Disabled:
+			w.finish("resource removed")
+		case mt.State.CurrentBuild.StartTime.After(w.buildStart) || mt.State.LastBuild().StartTime.After(w.buildStart):
+			w.finish("superseded by a newer build")
+		case mt.IsReady():
+			w.finish("")
+		case hasFailedPod(mt.State.PodSet):
+			w.finish("pod failed")
+		default:
+			continue
+		}
+		delete(c.waitingForReady, name)
+	}
+}
+
+// A failed pod won't get restarted (e.g., a Job's, once it's out of retries),
+// so the resource won't become ready until the next build.
+func hasFailedPod(podSet store.PodSet) bool {
+	for _, pod := range podSet.Pods {
+		if !pod.Deleting && pod.Phase == v1.PodFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Ends the traces of the updates we're still waiting on, so that they're
+// sent before Tilt exits.
+func (c *BuildController) TearDown(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, w := range c.waitingForReady {
+		w.finish("tilt exited")
+		delete(c.waitingForReady, name)
+	}
+}
+
+// Cancel the builds in progress that are out of date, or that the user asked us to.
+func (c *BuildController) maybeCancelBuilds(st store.RStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, b := range c.building {
+		if b.reason != "" {
+			continue
+		}
+
+		reason := buildCancelReason(st, name)
+		if reason == "" {
+			continue
+		}
+
+		b.reason = reason
+		b.cancel()
+	}
+}
+
+func buildCancelReason(st store.RStore, name model.ManifestName) string {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	if !state.CurrentlyBuilding[name] {
+		return ""
+	}
+
+	ms, ok := state.ManifestState(name)
+	if !ok {
+		if state.TornDownManifests[name] {
+			return "torn down by user"
+		}
+		return ""
+	}
+
+	if ms.CancelBuildRequested {
+		return "canceled by user"
+	}
+
+	if ms.Disabled {
+		return "disabled by user"
+	}
+
+	// In manual mode (or while updates are paused), new changes wait,
+	// so they shouldn't interrupt the current build.
+	mt := state.ManifestTargets[name]
+	if state.TriggerModeForManifest(mt.Manifest) == model.TriggerAuto && !state.UpdatesPausedForManifest(name) &&
+		ms.HasChangesSinceCurrentBuildStarted() {
+		return "superseded by newer changes"
+	}
+	return ""
+}
+
 func (c *BuildController) buildAndDeploy(ctx context.Context, st store.RStore, entry buildEntry) (store.BuildResultSet, error) {
 	targets := entry.targets
 	for _, target := range targets {
@@ -274,18 +563,29 @@ func buildTargets(manifest model.Manifest) []model.TargetSpec {
 		result = append(result, manifest.DockerComposeTarget())
 	} else if manifest.IsK8s() {
 		result = append(result, manifest.K8sTarget())
+	} else if manifest.IsLocal() {
+		result = append(result, manifest.LocalTarget())
 	}
 
 	return result
 }
 
 // Extract a set of build states from a manifest for BuildAndDeploy.
-func buildStateSet(manifest model.Manifest, specs []model.TargetSpec, ms *store.ManifestState) store.BuildStateSet {
+// If redeploy is false, the state of each k8s target says what we deployed
+// last time, so that the builder can skip deploying the same thing again.
+func buildStateSet(manifest model.Manifest, specs []model.TargetSpec, ms *store.ManifestState, redeploy bool) store.BuildStateSet {
 	buildStateSet := store.BuildStateSet{}
 
 	for _, spec := range specs {
 		id := spec.ID()
-		if id.Type != model.TargetTypeImage && id.Type != model.TargetTypeDockerCompose {
+		if id.Type == model.TargetTypeK8s {
+			result := ms.BuildStatus(id).LastSuccessfulResult
+			if !redeploy && !result.IsEmpty() {
+				buildStateSet[id] = store.NewBuildState(result, nil)
+			}
+			continue
+		}
+		if id.Type != model.TargetTypeImage && id.Type != model.TargetTypeDockerCompose && id.Type != model.TargetTypeLocal {
 			continue
 		}
 
@@ -325,4 +625,14 @@ func buildStateSet(manifest model.Manifest, specs []model.TargetSpec, ms *store.
 	return buildStateSet
 }
 
+func isInTriggerQueue(state store.EngineState, mn model.ManifestName) bool {
+	for _, name := range state.TriggerQueue {
+		if name == mn {
+			return true
+		}
+	}
+	return false
+}
+
 var _ store.Subscriber = &BuildController{}
+var _ store.TearDowner = &BuildController{}
@@ -1,16 +1,24 @@
 package engine
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
 	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
 	"github.com/windmilleng/tilt/internal/watch"
 )
 
@@ -189,6 +197,330 @@ func TestBuildControllerManualTrigger(t *testing.T) {
 	f.waitForCompletedBuildCount(2)
 }
 
+func TestBuildControllerPauseUpdates(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	sync := model.Sync{LocalPath: f.Path(), ContainerPath: "/go"}
+	manifest := f.newManifest("fe", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	f.store.Dispatch(view.PauseUpdatesAction{Name: "fe"})
+	f.WaitUntilManifestState("paused", "fe", func(ms store.ManifestState) bool {
+		return ms.UpdatesPaused
+	})
+
+	f.fsWatcher.events <- watch.FileEvent{Path: f.JoinPath("main.go")}
+	f.fsWatcher.events <- watch.FileEvent{Path: f.JoinPath("README")}
+	f.WaitUntil("pending changes appear", func(st store.EngineState) bool {
+		return len(st.BuildStatus(manifest.ImageTargetAt(0).ID()).PendingFileChanges) == 2
+	})
+	f.assertNoCall()
+
+	// On resume, all the changes that piled up go in one build.
+	f.store.Dispatch(view.ResumeUpdatesAction{Name: "fe"})
+	call := f.nextCall()
+	assert.ElementsMatch(t, []string{f.JoinPath("main.go"), f.JoinPath("README")}, call.oneState().FilesChanged())
+	f.waitForCompletedBuildCount(2)
+}
+
+func TestBuildControllerPauseAllUpdates(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	sync := model.Sync{LocalPath: f.Path(), ContainerPath: "/go"}
+	manifest := f.newManifest("fe", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	f.store.Dispatch(view.PauseUpdatesAction{})
+	f.WaitUntil("paused", func(st store.EngineState) bool {
+		return st.UpdatesPaused
+	})
+
+	f.fsWatcher.events <- watch.FileEvent{Path: f.JoinPath("main.go")}
+	f.WaitUntil("pending change appears", func(st store.EngineState) bool {
+		return len(st.BuildStatus(manifest.ImageTargetAt(0).ID()).PendingFileChanges) > 0
+	})
+	f.assertNoCall()
+
+	f.store.Dispatch(view.ResumeUpdatesAction{})
+	call := f.nextCall()
+	assert.Equal(t, []string{f.JoinPath("main.go")}, call.oneState().FilesChanged())
+	f.waitForCompletedBuildCount(2)
+}
+
+func TestBuildControllerManualUpdateMode(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	sync := model.Sync{LocalPath: f.Path(), ContainerPath: "/go"}
+	manifest := f.newManifest("fe", []model.Sync{sync})
+	manifest.UpdateMode = model.UpdateModeManual
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+
+	f.fsWatcher.events <- watch.FileEvent{Path: f.JoinPath("main.go")}
+	f.WaitUntil("pending change appears", func(st store.EngineState) bool {
+		return len(st.BuildStatus(manifest.ImageTargetAt(0).ID()).PendingFileChanges) > 0
+	})
+
+	// The rest of the session is in auto mode, but this manifest waits for a trigger.
+	f.assertNoCall()
+
+	f.store.Dispatch(view.AppendToTriggerQueueAction{Name: "fe"})
+	call := f.nextCall()
+	assert.Equal(t, []string{f.JoinPath("main.go")}, call.oneState().FilesChanged())
+	f.waitForCompletedBuildCount(2)
+}
+
+func TestTriggerDuringBuildQueuesOneFollowUp(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifest := f.newManifest("fe", nil)
+	manifest.UpdateMode = model.UpdateModeManual
+	state := store.NewState()
+	state.UpsertManifestTarget(store.NewManifestTarget(manifest))
+	state.CurrentlyBuilding["fe"] = true
+
+	appendToTriggerQueue(state, "fe")
+	appendToTriggerQueue(state, "fe")
+	assert.Equal(t, []model.ManifestName{"fe"}, state.TriggerQueue)
+}
+
+func TestBuildControllerWaitsForResourceDependencies(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	sync := model.Sync{LocalPath: f.Path(), ContainerPath: "/go"}
+	app := f.newManifest("app", []model.Sync{sync})
+	app.ResourceDependencies = []model.ManifestName{"db"}
+	db := f.newManifest("db", []model.Sync{sync})
+	f.Start([]model.Manifest{app, db}, true)
+
+	// app comes first in the Tiltfile, but waits for db.
+	call := f.nextCall()
+	assert.Equal(t, "db", call.k8s().Name.String())
+	call = f.nextCall()
+	assert.Equal(t, "app", call.k8s().Name.String())
+
+	err := f.Stop()
+	assert.NoError(t, err)
+	f.assertAllBuildsConsumed()
+}
+
+func TestBuildControllerWaitsForReadinessCheck(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	// Keep the file the check looks for out of the watched directory,
+	// so that creating it doesn't trigger a build.
+	checkDir := tempdir.NewTempDirFixture(t)
+	defer checkDir.TearDown()
+
+	sync := model.Sync{LocalPath: f.Path(), ContainerPath: "/go"}
+	app := f.newManifest("app", []model.Sync{sync})
+	app.ResourceDependencies = []model.ManifestName{"db"}
+	db := f.newManifest("db", []model.Sync{sync})
+	db.ReadinessCheck = model.ReadinessCheck{
+		Exec:   model.ToShellCmd(fmt.Sprintf("test -f %s", checkDir.JoinPath("ready"))),
+		Period: 10 * time.Millisecond,
+	}
+	f.Start([]model.Manifest{app, db}, true)
+
+	call := f.nextCall()
+	assert.Equal(t, "db", call.k8s().Name.String())
+
+	f.WaitUntilManifestState("db fails its readiness check", "db", func(ms store.ManifestState) bool {
+		return ms.ReadinessCheckError != ""
+	})
+	f.assertNoCall("app shouldn't build until db passes its readiness check")
+
+	checkDir.WriteFile("ready", "")
+	call = f.nextCall()
+	assert.Equal(t, "app", call.k8s().Name.String())
+
+	err := f.Stop()
+	assert.NoError(t, err)
+	f.assertAllBuildsConsumed()
+}
+
+func TestResourceDependencyCycleFailsTiltfile(t *testing.T) {
+	state := store.NewState()
+	a := model.Manifest{Name: "a", ResourceDependencies: []model.ManifestName{"b"}}
+	b := model.Manifest{Name: "b", ResourceDependencies: []model.ManifestName{"a"}}
+
+	handleConfigsReloaded(output.CtxForTest(), state, ConfigsReloadedAction{Manifests: []model.Manifest{a, b}})
+	if assert.Error(t, state.LastTiltfileBuild.Error) {
+		assert.Contains(t, state.LastTiltfileBuild.Error.Error(), "resource dependency cycle: a -> b -> a")
+	}
+	assert.Empty(t, state.ManifestTargets)
+}
+
+func TestResourceDependencyOutsideLoadedResources(t *testing.T) {
+	state := store.NewState()
+	frontend := model.Manifest{Name: "frontend", ResourceDependencies: []model.ManifestName{"backend"}}
+
+	// `tilt up frontend` only loads frontend, but backend is still in the Tiltfile.
+	handleConfigsReloaded(output.CtxForTest(), state, ConfigsReloadedAction{
+		Manifests:        []model.Manifest{frontend},
+		AllManifestNames: []model.ManifestName{"frontend", "backend"},
+	})
+	assert.NoError(t, state.LastTiltfileBuild.Error)
+	if assert.Contains(t, state.ManifestTargets, model.ManifestName("frontend")) {
+		assert.Empty(t, state.UnreadyDependencies(frontend))
+	}
+}
+
+func TestRedeployDependents(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	db := f.newManifest("db", nil)
+	app := f.newManifest("app", nil)
+	app.ResourceDependencies = []model.ManifestName{"db"}
+	app.RedeployOnDependencyUpdate = true
+	worker := f.newManifest("worker", nil)
+	worker.ResourceDependencies = []model.ManifestName{"db"}
+
+	state := store.NewState()
+	for _, m := range []model.Manifest{db, app, worker} {
+		mt := store.NewManifestTarget(m)
+		mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now()})
+		state.UpsertManifestTarget(mt)
+	}
+
+	redeployDependents(state, "db")
+	assert.False(t, state.ManifestTargets["app"].State.PendingManifestChange.IsZero())
+	assert.True(t, state.ManifestTargets["worker"].State.PendingManifestChange.IsZero())
+}
+
+func TestNextTargetToBuildInParallel(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	a := f.newManifest("a", nil)
+	b := f.newManifest("b", nil)
+	// c builds the same image as a.
+	c := f.newManifestWithRef("c", f.imageNameForManifest("a"), nil)
+
+	state := store.NewState()
+	for _, m := range []model.Manifest{c, a, b} {
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	}
+	state.ManifestDefinitionOrder = []model.ManifestName{"c", "a", "b"}
+	state.CurrentlyBuilding["a"] = true
+
+	// By default, we only build one manifest at a time.
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state))
+
+	state.MaxParallelUpdates = 3
+	assert.Equal(t, model.ManifestName("b"), nextManifestNameToBuild(*state))
+
+	state.CurrentlyBuilding["b"] = true
+	assert.Equal(t, 1, state.AvailableBuildSlots())
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state))
+}
+
+func TestParallelBuildLogsArePrefixed(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+
+	logger.Get(withBuildLogPrefix(ctx, buildEntry{name: "fe"})).Infof("one at a time")
+	logger.Get(withBuildLogPrefix(ctx, buildEntry{name: "fe", parallel: true})).Infof("in parallel")
+
+	assert.Equal(t, "one at a time\nfe          ┊ in parallel\n", out.String())
+}
+
+func TestNextTargetToBuildPrefersRecentChangesAndTriggers(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	state := store.NewState()
+	start := time.Now().Add(-time.Minute)
+	for _, name := range []model.ManifestName{"a", "b", "c"} {
+		mt := store.NewManifestTarget(f.newManifest(name.String(), nil))
+		mt.State.AddCompletedBuild(model.BuildRecord{StartTime: start, FinishTime: start})
+		state.UpsertManifestTarget(mt)
+	}
+
+	state.ManifestTargets["a"].State.PendingManifestChange = start.Add(time.Second)
+	state.ManifestTargets["b"].State.PendingManifestChange = start.Add(3 * time.Second)
+	state.ManifestTargets["c"].State.PendingManifestChange = start.Add(2 * time.Second)
+
+	// b is the one the user touched most recently.
+	assert.Equal(t, model.ManifestName("b"), nextManifestNameToBuild(*state))
+
+	// Triggers jump the queue, most recent first.
+	appendToTriggerQueue(state, "c")
+	appendToTriggerQueue(state, "a")
+	assert.Equal(t, model.ManifestName("a"), nextManifestNameToBuild(*state))
+
+	appendToTriggerQueue(state, "c")
+	assert.Equal(t, []model.ManifestName{"a", "c"}, state.TriggerQueue)
+	assert.Equal(t, model.ManifestName("c"), nextManifestNameToBuild(*state))
+}
+
+func TestNextTargetToBuildSkipsPausedResources(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	state := store.NewState()
+	mt := store.NewManifestTarget(f.newManifest("fe", nil))
+	mt.State.UpdatesPaused = true
+	state.UpsertManifestTarget(mt)
+
+	// Not even its first build.
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state))
+
+	start := time.Now().Add(-time.Minute)
+	mt.State.AddCompletedBuild(model.BuildRecord{StartTime: start, FinishTime: start})
+	appendToTriggerQueue(state, "fe")
+	mt.State.NeedsRebuildFromCrash = true
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state))
+
+	mt.State.UpdatesPaused = false
+	assert.Equal(t, model.ManifestName("fe"), nextManifestNameToBuild(*state))
+}
+
+func TestTriggerAllRebuildsInDependencyOrder(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	app := f.newManifest("app", nil)
+	app.ResourceDependencies = []model.ManifestName{"db"}
+	db := f.newManifest("db", nil)
+	web := f.newManifest("web", nil)
+
+	state := store.NewState()
+	start := time.Now().Add(-time.Minute)
+	for _, m := range []model.Manifest{app, db, web} {
+		mt := store.NewManifestTarget(m)
+		mt.State.AddCompletedBuild(model.BuildRecord{StartTime: start, FinishTime: start})
+		state.UpsertManifestTarget(mt)
+	}
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state))
+
+	handleTriggerAllAction(state)
+	assert.Equal(t, []model.ManifestName{"web", "app", "db"}, state.TriggerQueue)
+
+	// db builds first, and app waits for it.
+	assert.Equal(t, model.ManifestName("db"), nextManifestNameToBuild(*state))
+	state.ManifestTargets["db"].State.CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+	state.CurrentlyBuilding["db"] = true
+	removeFromTriggerQueue(state, "db")
+	state.MaxParallelUpdates = 3
+	assert.Equal(t, model.ManifestName("web"), nextManifestNameToBuild(*state))
+}
+
 // any manifests without image targets should be deployed before any manifests WITH image targets
 func TestBuildControllerNoBuildManifestsFirst(t *testing.T) {
 	f := newTestFixture(t)
@@ -230,3 +562,65 @@ func TestBuildControllerNoBuildManifestsFirst(t *testing.T) {
 	}
 	assert.Equal(t, expectedBuildOrder, observedBuildOrder)
 }
+
+func TestBuildStateSetRemembersLastDeploy(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	kTarget := manifest.K8sTarget()
+	ms := store.NewManifestTarget(manifest).State
+	ms.MutableBuildStatus(kTarget.ID()).LastSuccessfulResult = store.NewK8sDeployResult(kTarget)
+
+	stateSet := buildStateSet(manifest, buildTargets(manifest), ms, false)
+	if assert.NotNil(t, stateSet[kTarget.ID()].LastResult.K8sTarget) {
+		assert.Equal(t, kTarget.YAML, stateSet[kTarget.ID()].LastResult.K8sTarget.YAML)
+	}
+
+	// When we want to deploy again no matter what, the builder doesn't get to
+	// see what we deployed last time.
+	stateSet = buildStateSet(manifest, buildTargets(manifest), ms, true)
+	_, ok := stateSet[kTarget.ID()]
+	assert.False(t, ok)
+}
+
+func TestBuildControllerFinishesReadyWaits(t *testing.T) {
+	buildStart := time.Now()
+	state := store.NewState()
+	for name, phase := range map[model.ManifestName]v1.PodPhase{"job": v1.PodFailed, "app": v1.PodRunning} {
+		m := model.Manifest{Name: name}.WithDeployTarget(model.K8sTarget{YAML: "fake-yaml", HasPods: true})
+		mt := store.NewManifestTarget(m)
+		mt.State.BuildHistory = []model.BuildRecord{{StartTime: buildStart, FinishTime: buildStart}}
+		mt.State.PodSet = store.NewPodSet(store.Pod{PodID: k8s.PodID(name), Phase: phase})
+		state.ManifestTargets[name] = mt
+		state.ManifestDefinitionOrder = append(state.ManifestDefinitionOrder, name)
+	}
+	st := store.NewTestingStore()
+	st.SetState(*state)
+
+	c := NewBuildController(nil)
+	for _, name := range []model.ManifestName{"job", "app"} {
+		c.waitingForReady[name] = readyWait{
+			update:     opentracing.NoopTracer{}.StartSpan("update"),
+			wait:       opentracing.NoopTracer{}.StartSpan("wait-for-ready"),
+			buildStart: buildStart,
+		}
+	}
+
+	// The Job's pod failed, so it won't be ready until the next build.
+	c.finishReadyWaits(st)
+	assert.Equal(t, []model.ManifestName{"app"}, readyWaitNames(c))
+
+	c.TearDown(output.CtxForTest())
+	assert.Empty(t, readyWaitNames(c))
+}
+
+func readyWaitNames(c *BuildController) []model.ManifestName {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var names []model.ManifestName
+	for name := range c.waitingForReady {
+		names = append(names, name)
+	}
+	return names
+}
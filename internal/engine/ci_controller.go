@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/hud"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// How many lines of a failing resource's logs to show when `tilt ci` exits.
+const ciLogTailLines = 100
+
+// Pod statuses that mean the pod won't come up on its own.
+var ciPodFailureStatuses = map[string]bool{
+	"Error":                      true,
+	"CrashLoopBackOff":           true,
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"OOMKilled":                  true,
+}
+
+// Decides when `tilt ci` is done: successfully, once every resource is ready,
+// or unsuccessfully, as soon as one of them fails or we run out of time.
+type CIController struct {
+	timeout time.Duration
+
+	started  bool
+	exitOnce sync.Once
+}
+
+func NewCIController(timeout time.Duration) *CIController {
+	return &CIController{timeout: timeout}
+}
+
+func (c *CIController) OnChange(ctx context.Context, st store.RStore) {
+	if !c.started {
+		c.started = true
+		if c.timeout > 0 {
+			go c.exitAfterTimeout(ctx, st)
+		}
+	}
+
+	state := st.RLockState()
+	done, err := ciResult(state)
+	st.RUnlockState()
+
+	if done {
+		c.exit(st, err)
+	}
+}
+
+func (c *CIController) exitAfterTimeout(ctx context.Context, st store.RStore) {
+	select {
+	case <-time.After(c.timeout):
+	case <-ctx.Done():
+		return
+	}
+
+	state := st.RLockState()
+	err := ciTimeoutError(state, c.timeout)
+	st.RUnlockState()
+
+	c.exit(st, err)
+}
+
+func (c *CIController) exit(st store.RStore, err error) {
+	c.exitOnce.Do(func() {
+		st.Dispatch(hud.NewExitAction(err))
+	})
+}
+
+// Whether `tilt ci` is done, and if so, why it failed (if it did).
+func ciResult(state store.EngineState) (bool, error) {
+	// Wait for the Tiltfile to load.
+	if state.LastTiltfileBuild.Empty() || !state.CurrentTiltfileBuild.Empty() {
+		return false, nil
+	}
+
+	if err := state.LastTiltfileError(); err != nil {
+		return true, fmt.Errorf("Tiltfile failed to load: %v", err)
+	}
+
+	allReady := true
+	for _, mt := range state.EnabledTargets() {
+		if reason := ciFailure(mt); reason != "" {
+			return true, ciResourceError(mt, reason)
+		}
+		if !mt.IsReady() {
+			allReady = false
+		}
+	}
+
+	return allReady, nil
+}
+
+// Why the resource failed, or "" if it hasn't (yet).
+func ciFailure(mt *store.ManifestTarget) string {
+	ms := mt.State
+	if ms.CurrentBuild.Empty() {
+		if err := ms.LastBuild().Error; err != nil {
+			return fmt.Sprintf("Build failed: %v", err)
+		}
+	}
+
+	switch {
+	case mt.Manifest.IsK8s():
+		pod := ms.MostRecentPod()
+		if ciPodFailureStatuses[pod.Status] {
+			return fmt.Sprintf("Pod has status %s", pod.Status)
+		}
+		// A Job's pod that ran to completion is ready, but one that failed never will be.
+		if pod.Phase == v1.PodFailed {
+			return fmt.Sprintf("Pod failed with status %s", pod.Status)
+		}
+	case mt.Manifest.IsDC():
+		if ms.DCResourceState().Status == dockercompose.StatusCrash {
+			return "Container crashed"
+		}
+	case mt.Manifest.IsLocal():
+		if ms.LocalResourceState().Status == store.LocalServeStatusError {
+			return "serve_cmd exited with an error"
+		}
+	}
+	return ""
+}
+
+func ciResourceError(mt *store.ManifestTarget, reason string) error {
+	return fmt.Errorf("%s: %s\n%s", mt.Manifest.Name, reason, ciLogs(mt))
+}
+
+func ciTimeoutError(state store.EngineState, timeout time.Duration) error {
+	var names []string
+	var logs []string
+	for _, mt := range state.EnabledTargets() {
+		if mt.IsReady() {
+			continue
+		}
+		names = append(names, mt.Manifest.Name.String())
+		logs = append(logs, ciLogs(mt))
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("Timed out after %s waiting for the Tiltfile to load", timeout)
+	}
+	return fmt.Errorf("Timed out after %s waiting for resources to be ready: %s\n%s",
+		timeout, strings.Join(names, ", "), strings.Join(logs, "\n"))
+}
+
+func ciLogs(mt *store.ManifestTarget) string {
+	log := mt.State.CombinedLog.Tail(ciLogTailLines)
+	if log.Empty() {
+		return fmt.Sprintf("(no logs from %s)", mt.Manifest.Name)
+	}
+	return fmt.Sprintf("Last logs from %s:\n%s", mt.Manifest.Name, log.String())
+}
+
+var _ store.Subscriber = &CIController{}
@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/windmilleng/tilt/internal/hud"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestCIWaitsForTiltfile(t *testing.T) {
+	f := newCIFixture(t)
+	defer f.TearDown()
+
+	f.state.LastTiltfileBuild = model.BuildRecord{}
+	done, _ := ciResult(*f.state)
+	assert.False(t, done)
+
+	f.state.LastTiltfileBuild = model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now(), Error: fmt.Errorf("syntax error")}
+	done, err := ciResult(*f.state)
+	assert.True(t, done)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Tiltfile failed to load: syntax error")
+	}
+}
+
+func TestCIWaitsForEveryResource(t *testing.T) {
+	f := newCIFixture(t)
+	defer f.TearDown()
+
+	done, _ := ciResult(*f.state)
+	assert.False(t, done)
+
+	f.succeed(f.api)
+	done, _ = ciResult(*f.state)
+	assert.False(t, done)
+
+	f.succeed(f.tests)
+	done, err := ciResult(*f.state)
+	assert.True(t, done)
+	assert.NoError(t, err)
+}
+
+func TestCIFailsOnBuildError(t *testing.T) {
+	f := newCIFixture(t)
+	defer f.TearDown()
+
+	f.tests.State.CombinedLog = model.NewLog("--- FAIL: TestAPI\n")
+	f.tests.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+		Error:      fmt.Errorf("exit status 1"),
+	})
+
+	done, err := ciResult(*f.state)
+	assert.True(t, done)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "tests: Build failed: exit status 1")
+		assert.Contains(t, err.Error(), "Last logs from tests:\n--- FAIL: TestAPI")
+	}
+}
+
+func TestCIFinishesWhenJobCompletes(t *testing.T) {
+	f := newCIFixture(t)
+	defer f.TearDown()
+
+	f.succeed(f.api)
+	f.succeed(f.tests)
+	migrate := f.addJob("migrate")
+	f.succeed(migrate)
+	migrate.State.PodSet = store.NewPodSet(store.Pod{PodID: "migrate-abc", Phase: v1.PodRunning, Status: "Running"})
+	done, _ := ciResult(*f.state)
+	assert.False(t, done)
+
+	// The Job's pod never passes a readiness probe, but it's done.
+	migrate.State.PodSet.Pods["migrate-abc"].Phase = v1.PodSucceeded
+	migrate.State.PodSet.Pods["migrate-abc"].Status = "Completed"
+	done, err := ciResult(*f.state)
+	assert.True(t, done)
+	assert.NoError(t, err)
+}
+
+func TestCIFailsWhenJobFails(t *testing.T) {
+	f := newCIFixture(t)
+	defer f.TearDown()
+
+	migrate := f.addJob("migrate")
+	f.succeed(migrate)
+	migrate.State.PodSet = store.NewPodSet(store.Pod{PodID: "migrate-abc", Phase: v1.PodFailed, Status: "BackoffLimitExceeded"})
+
+	done, err := ciResult(*f.state)
+	assert.True(t, done)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "migrate: Pod failed with status BackoffLimitExceeded")
+	}
+}
+
+func TestCIFailsWhenServeCmdExits(t *testing.T) {
+	f := newCIFixture(t)
+	defer f.TearDown()
+
+	f.succeed(f.api)
+	f.api.State.ResourceState = store.LocalResourceState{Status: store.LocalServeStatusError}
+
+	done, err := ciResult(*f.state)
+	assert.True(t, done)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "api: serve_cmd exited with an error")
+	}
+}
+
+func TestCITimeoutListsUnreadyResources(t *testing.T) {
+	f := newCIFixture(t)
+	defer f.TearDown()
+
+	f.succeed(f.tests)
+	err := ciTimeoutError(*f.state, time.Minute)
+	assert.Contains(t, err.Error(), "Timed out after 1m0s waiting for resources to be ready: api\n")
+	assert.Contains(t, err.Error(), "(no logs from api)")
+}
+
+func TestCIExitsOnce(t *testing.T) {
+	f := newCIFixture(t)
+	defer f.TearDown()
+
+	f.succeed(f.api)
+	f.succeed(f.tests)
+	f.st.SetState(*f.state)
+
+	c := NewCIController(0)
+	c.OnChange(output.CtxForTest(), f.st)
+	c.OnChange(output.CtxForTest(), f.st)
+	assert.Equal(t, []store.Action{hud.NewExitAction(nil)}, f.st.Actions)
+}
+
+type ciFixture struct {
+	*tempdir.TempDirFixture
+	st    *store.TestingStore
+	state *store.EngineState
+	api   *store.ManifestTarget
+	tests *store.ManifestTarget
+}
+
+func newCIFixture(t *testing.T) *ciFixture {
+	f := tempdir.NewTempDirFixture(t)
+
+	state := store.NewState()
+	state.LastTiltfileBuild = model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()}
+
+	api := store.NewManifestTarget(model.Manifest{Name: "api"}.WithDeployTarget(
+		model.NewLocalTarget("api", model.ToShellCmd("go build"), model.ToShellCmd("./api"), nil, f.Path())))
+	tests := store.NewManifestTarget(model.Manifest{Name: "tests"}.WithDeployTarget(
+		model.NewLocalTarget("tests", model.ToShellCmd("go test ./..."), model.Cmd{}, nil, f.Path())))
+	state.UpsertManifestTarget(api)
+	state.UpsertManifestTarget(tests)
+
+	st := store.NewTestingStore()
+	st.SetState(*state)
+
+	return &ciFixture{
+		TempDirFixture: f,
+		st:             st,
+		state:          state,
+		api:            api,
+		tests:          tests,
+	}
+}
+
+func (f *ciFixture) addJob(name model.ManifestName) *store.ManifestTarget {
+	mt := store.NewManifestTarget(model.Manifest{Name: name}.WithDeployTarget(
+		model.K8sTarget{Name: name.TargetName(), YAML: "fake-yaml", HasPods: true}))
+	f.state.UpsertManifestTarget(mt)
+	return mt
+}
+
+func (f *ciFixture) succeed(mt *store.ManifestTarget) {
+	mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	mt.State.LastSuccessfulDeployTime = time.Now()
+	if !mt.Manifest.LocalTarget().ServeCmd.Empty() {
+		mt.State.ResourceState = store.LocalResourceState{Status: store.LocalServeStatusRunning}
+	}
+}
@@ -6,7 +6,12 @@ import (
 	"io"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+
 	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/internal/tiltfile"
 )
@@ -14,12 +19,14 @@ import (
 type ConfigsController struct {
 	disabledForTesting bool
 	tfl                tiltfile.TiltfileLoader
+	sp                 *StatePersister
 	clock              func() time.Time
 }
 
-func NewConfigsController(tfl tiltfile.TiltfileLoader) *ConfigsController {
+func NewConfigsController(tfl tiltfile.TiltfileLoader, sp *StatePersister) *ConfigsController {
 	return &ConfigsController{
 		tfl:   tfl,
+		sp:    sp,
 		clock: time.Now,
 	}
 }
@@ -33,9 +40,10 @@ func (cc *ConfigsController) DisableForTesting(disabled bool) {
 // 2) There are pending file changes, and
 // 3) Those files have changed since the last Tiltfile build
 //    (so that we don't keep re-running a failed build)
+// 4) The user hasn't paused updates
 func (cc *ConfigsController) shouldBuild(state store.EngineState) bool {
 	isRunning := !state.CurrentTiltfileBuild.StartTime.IsZero()
-	if isRunning {
+	if isRunning || state.UpdatesPaused {
 		return false
 	}
 
@@ -88,17 +96,39 @@ func (cc *ConfigsController) OnChange(ctx context.Context, st store.RStore) {
 
 		loadCtx := logger.WithLogger(ctx, logger.NewLogger(logger.Get(ctx).Level(), multiWriter))
 
+		// Like updates, each Tiltfile load is its own trace.
+		span := opentracing.StartSpan("tiltfile", opentracing.StartTime(startTime))
+		span.SetTag("files_changed", len(filesChanged))
+		loadCtx = opentracing.ContextWithSpan(loadCtx, span)
+
 		tlr, err := cc.tfl.Load(loadCtx, tiltfilePath, matching, !state.FirstTiltfileBuildCompleted)
 		if err == nil && len(tlr.Manifests) == 0 {
 			err = fmt.Errorf("No resources found. Check out https://docs.tilt.dev/tutorial.html to get started!")
 		}
 		if err != nil {
 			logger.Get(loadCtx).Infof(err.Error())
+			ext.Error.Set(span, true)
+			span.LogFields(log.String("event", "error"), log.String("message", err.Error()))
+		} else {
+			span.SetTag("resources", len(tlr.Manifests))
 		}
+		span.Finish()
+
+		// On the first load, pick up where the last run of tilt left off.
+		var restored map[model.ManifestName]RestoredManifest
+		if err == nil && !state.FirstTiltfileBuildCompleted && cc.sp != nil {
+			restored = cc.sp.Restore(ctx, state.TiltfilePath, tlr.Manifests)
+		}
+
 		st.Dispatch(ConfigsReloadedAction{
 			Manifests:          tlr.Manifests,
+			RestoredManifests:  restored,
 			ConfigFiles:        tlr.ConfigFiles,
 			TiltIgnoreContents: tlr.TiltIgnoreContents,
+			MaxParallelUpdates: tlr.MaxParallelUpdates,
+			EventSinks:         tlr.EventSinks,
+			WatchSettings:      tlr.WatchSettings,
+			AllManifestNames:   tlr.AllManifestNames,
 			StartTime:          startTime,
 			FinishTime:         cc.clock(),
 			Err:                err,
@@ -8,7 +8,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/windmilleng/wmclient/pkg/dirs"
 
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/internal/testutils"
@@ -85,7 +88,8 @@ func newCCFixture(t *testing.T) *ccFixture {
 	f := tempdir.NewTempDirFixture(t)
 	st, getActions := store.NewStoreForTesting()
 	tfl := tiltfile.NewFakeTiltfileLoader()
-	cc := NewConfigsController(tfl)
+	sp := NewStatePersister(dirs.NewWindmillDirAt(f.JoinPath(".windmill")), docker.NewFakeClient(), k8s.NewClientRegistryForTests(k8s.NewFakeK8sClient()))
+	cc := NewConfigsController(tfl, sp)
 	fc := testutils.NewRandomFakeClock()
 	cc.clock = fc.Clock()
 	ctx := output.CtxForTest()
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// The targets in a deploy all go out together, so we retry them
+// as many times as the most patient one asks for.
+func deployRetryPolicy(kTargets []model.K8sTarget) model.DeployRetryPolicy {
+	var result model.DeployRetryPolicy
+	for _, kTarget := range kTargets {
+		if kTarget.DeployRetry.Retries > result.Retries {
+			result = kTarget.DeployRetry
+		}
+	}
+	return result
+}
+
+// Runs a step of a deploy, and if it fails with an error that's probably
+// temporary (e.g., an admission webhook that's down), waits and tries again,
+// up to policy.Retries times, before giving up and failing the resource.
+func retryDeploy(ctx context.Context, policy model.DeployRetryPolicy, deploy func() error) error {
+	backoff := policy.Backoff
+	for i := 0; ; i++ {
+		err := deploy()
+		if err == nil || i >= policy.Retries || !k8s.IsTransientDeployError(err) {
+			return err
+		}
+
+		logger.Get(ctx).Infof("Deploy failed with what looks like a temporary error; retrying in %s (%d/%d)\n%v",
+			backoff, i+1, policy.Retries, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestRetryDeployTransientError(t *testing.T) {
+	policy := model.DeployRetryPolicy{Retries: 3, Backoff: time.Millisecond}
+	calls := 0
+	err := retryDeploy(output.CtxForTest(), policy, func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf(`Internal error occurred: failed calling webhook "validate.example.com"`)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryDeployGivesUp(t *testing.T) {
+	policy := model.DeployRetryPolicy{Retries: 2, Backoff: time.Millisecond}
+	calls := 0
+	err := retryDeploy(output.CtxForTest(), policy, func() error {
+		calls++
+		return fmt.Errorf("net/http: TLS handshake timeout")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryDeployDoesntRetryPermanentError(t *testing.T) {
+	policy := model.DeployRetryPolicy{Retries: 3, Backoff: time.Millisecond}
+	calls := 0
+	err := retryDeploy(output.CtxForTest(), policy, func() error {
+		calls++
+		return fmt.Errorf(`The Deployment "sancho" is invalid`)
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
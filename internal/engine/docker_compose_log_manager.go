@@ -38,7 +38,7 @@ func (m *DockerComposeLogManager) diff(ctx context.Context, st store.RStore) (se
 
 	for _, mt := range state.ManifestTargets {
 		manifest := mt.Manifest
-		if !manifest.IsDC() {
+		if !manifest.IsDC() || mt.State.Disabled {
 			continue
 		}
 
@@ -83,8 +83,8 @@ func (m *DockerComposeLogManager) diff(ctx context.Context, st store.RStore) (se
 	}
 
 	for key, value := range m.watches {
-		_, inState := state.ManifestTargets[key]
-		if !inState {
+		mt, inState := state.ManifestTargets[key]
+		if !inState || mt.State.Disabled {
 			delete(m.watches, key)
 
 			teardown = append(teardown, value)
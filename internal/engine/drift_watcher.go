@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// kubectl diff asks the server to dry-run every object, so we don't want to do it too often.
+const driftPollInterval = 30 * time.Second
+
+// Periodically compares the objects in the cluster against what Tilt last
+// applied, so that we can tell the user when someone changed them out from
+// under us (e.g., with `kubectl edit` or `kubectl scale`).
+type DriftWatcher struct {
+	clients  *k8s.ClientRegistry
+	interval time.Duration
+
+	watching bool
+}
+
+func NewDriftWatcher(clients *k8s.ClientRegistry) *DriftWatcher {
+	return &DriftWatcher{
+		clients:  clients,
+		interval: driftPollInterval,
+	}
+}
+
+func (w *DriftWatcher) needsWatch(st store.RStore) bool {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	atLeastOneK8S := false
+	for _, m := range state.Manifests() {
+		if m.IsK8s() {
+			atLeastOneK8S = true
+		}
+	}
+	return atLeastOneK8S && state.WatchFiles && !w.watching
+}
+
+func (w *DriftWatcher) OnChange(ctx context.Context, st store.RStore) {
+	if !w.needsWatch(st) {
+		return
+	}
+	w.watching = true
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.poll(ctx, st)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+type driftCheck struct {
+	name        model.ManifestName
+	target      model.K8sTarget
+	appliedYAML string
+	drift       string
+}
+
+// The manifests whose objects we've applied, and aren't about to apply again.
+func (w *DriftWatcher) checks(st store.RStore) []driftCheck {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	var result []driftCheck
+	for _, mt := range state.Targets() {
+		ms := mt.State
+		if !mt.Manifest.IsK8s() || ms.LastAppliedYAML == "" || !ms.CurrentBuild.Empty() {
+			continue
+		}
+		if ok, _ := ms.HasPendingChanges(); ok {
+			continue
+		}
+		result = append(result, driftCheck{
+			name:        mt.Manifest.Name,
+			target:      mt.Manifest.K8sTarget(),
+			appliedYAML: ms.LastAppliedYAML,
+			drift:       ms.Drift,
+		})
+	}
+	return result
+}
+
+func (w *DriftWatcher) poll(ctx context.Context, st store.RStore) {
+	for _, c := range w.checks(st) {
+		entities, err := k8s.ParseYAMLFromString(c.appliedYAML)
+		if err != nil {
+			logger.Get(ctx).Debugf("Error parsing applied YAML for %s: %v", c.name, err)
+			continue
+		}
+
+		kCli, err := w.clients.ClientFor(ctx, k8s.ConnectionForTarget(c.target))
+		if err != nil {
+			logger.Get(ctx).Debugf("Error getting client for %s: %v", c.name, err)
+			continue
+		}
+
+		drift, err := kCli.Diff(ctx, entities, c.target.ServerSideApply)
+		if err != nil {
+			logger.Get(ctx).Debugf("Error checking %s for changes: %v", c.name, err)
+			continue
+		}
+
+		if drift != c.drift {
+			st.Dispatch(K8sDriftAction{
+				ManifestName: c.name,
+				AppliedYAML:  c.appliedYAML,
+				Drift:        drift,
+			})
+		}
+	}
+}
+
+var _ store.Subscriber = &DriftWatcher{}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+const testDrift = `Deployment default/sancho:
+-  replicas: 3
++  replicas: 1`
+
+func TestDriftWatcherPoll(t *testing.T) {
+	f := newDriftWatcherFixture(t)
+	defer f.TearDown()
+
+	f.kCli.DiffResult = testDrift
+	f.w.poll(output.CtxForTest(), f.st)
+
+	if assert.Equal(t, 1, len(f.kCli.DiffCalls)) {
+		assert.Equal(t, "sancho", f.kCli.DiffCalls[0][0].Name())
+	}
+	if assert.Equal(t, 1, len(f.st.Actions)) {
+		assert.Equal(t, K8sDriftAction{
+			ManifestName: "sancho",
+			AppliedYAML:  SanchoYAML,
+			Drift:        testDrift,
+		}, f.st.Actions[0])
+	}
+}
+
+func TestDriftWatcherNoChange(t *testing.T) {
+	f := newDriftWatcherFixture(t)
+	defer f.TearDown()
+
+	f.w.poll(output.CtxForTest(), f.st)
+	assert.Equal(t, 1, len(f.kCli.DiffCalls))
+	assert.Empty(t, f.st.Actions)
+}
+
+func TestDriftWatcherSkipsBuildingManifests(t *testing.T) {
+	f := newDriftWatcherFixture(t)
+	defer f.TearDown()
+
+	f.state.ManifestTargets["sancho"].State.CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+	f.st.SetState(*f.state)
+
+	f.w.poll(output.CtxForTest(), f.st)
+	assert.Empty(t, f.kCli.DiffCalls)
+}
+
+func TestHandleK8sDriftAction(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	state := store.NewState()
+	m := NewSanchoDockerBuildManifest(f)
+	mt := store.NewManifestTarget(m)
+	state.ManifestTargets[m.Name] = mt
+
+	handleK8sAppliedAction(state, K8sAppliedAction{TargetID: m.K8sTarget().ID(), YAML: SanchoYAML})
+	assert.Equal(t, SanchoYAML, mt.State.LastAppliedYAML)
+
+	// Ignore results for YAML we're no longer running.
+	handleK8sDriftAction(state, K8sDriftAction{ManifestName: m.Name, AppliedYAML: "old", Drift: testDrift})
+	assert.Equal(t, "", mt.State.Drift)
+
+	handleK8sDriftAction(state, K8sDriftAction{ManifestName: m.Name, AppliedYAML: SanchoYAML, Drift: testDrift})
+	assert.Equal(t, testDrift, mt.State.Drift)
+
+	iTargetID := m.ImageTargetAt(0).ID()
+	mt.State.BuildStatuses[iTargetID] = &store.BuildStatus{
+		LastSuccessfulResult: store.NewImageBuildResult(iTargetID, container.MustParseNamedTagged("gcr.io/some-project-162817/sancho:tilt-1")),
+	}
+	mt.State.BuildStatuses[m.K8sTarget().ID()] = &store.BuildStatus{
+		LastSuccessfulResult: store.NewK8sDeployResult(m.K8sTarget()),
+	}
+
+	// Re-deploys, without rebuilding the image.
+	handleReapplyAction(state, view.ReapplyAction{Name: m.Name})
+	ok, _ := mt.State.HasPendingChanges()
+	assert.True(t, ok)
+	assert.False(t, mt.State.BuildStatuses[iTargetID].LastSuccessfulResult.IsEmpty())
+	assert.Nil(t, mt.State.BuildStatuses[m.K8sTarget().ID()])
+
+	handleK8sAppliedAction(state, K8sAppliedAction{TargetID: m.K8sTarget().ID(), YAML: SanchoYAML})
+	assert.Equal(t, "", mt.State.Drift)
+}
+
+type driftWatcherFixture struct {
+	*tempdir.TempDirFixture
+	w     *DriftWatcher
+	st    *store.TestingStore
+	kCli  *k8s.FakeK8sClient
+	state *store.EngineState
+}
+
+func newDriftWatcherFixture(t *testing.T) *driftWatcherFixture {
+	f := tempdir.NewTempDirFixture(t)
+	kCli := k8s.NewFakeK8sClient()
+
+	state := store.NewState()
+	m := NewSanchoDockerBuildManifest(f)
+	mt := store.NewManifestTarget(m)
+	mt.State.LastAppliedYAML = SanchoYAML
+	state.ManifestTargets[m.Name] = mt
+	state.ManifestDefinitionOrder = []model.ManifestName{m.Name}
+
+	st := store.NewTestingStore()
+	st.SetState(*state)
+
+	return &driftWatcherFixture{
+		TempDirFixture: f,
+		w:              NewDriftWatcher(k8s.NewClientRegistryForTests(kCli)),
+		st:             st,
+		kCli:           kCli,
+		state:          state,
+	}
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/events"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// How many batches of events can wait for slow sinks before we start dropping them.
+const eventSinkQueueSize = 100
+
+// Watches the engine state for builds and changes in readiness, and sends
+// them to the event sinks from the Tiltfile.
+type EventSinkController struct {
+	newSink func(spec model.EventSink) events.Sink
+
+	specs     []model.EventSink
+	sinks     []events.Sink
+	resources map[model.ManifestName]resourceEventState
+
+	queue   chan eventBatch
+	started bool
+}
+
+// What we last told the sinks about a resource.
+type resourceEventState struct {
+	buildStart  time.Time
+	buildFinish time.Time
+	ready       bool
+}
+
+type eventBatch struct {
+	specs  []model.EventSink
+	sinks  []events.Sink
+	events []events.Event
+}
+
+func NewEventSinkController() *EventSinkController {
+	return &EventSinkController{
+		newSink:   events.NewSink,
+		resources: make(map[model.ManifestName]resourceEventState),
+		queue:     make(chan eventBatch, eventSinkQueueSize),
+	}
+}
+
+// Figure out what happened since the last time we looked.
+//
+// We track resources even when there are no sinks, so that adding a sink
+// to the Tiltfile doesn't replay everything that happened before it.
+func (c *EventSinkController) diff(st store.RStore) eventBatch {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	if !model.DeepEqual(c.specs, state.EventSinks) {
+		c.specs = append([]model.EventSink{}, state.EventSinks...)
+		c.sinks = nil
+		for _, spec := range c.specs {
+			c.sinks = append(c.sinks, c.newSink(spec))
+		}
+	}
+
+	batch := eventBatch{specs: c.specs, sinks: c.sinks}
+	for _, mt := range state.Targets() {
+		ms := mt.State
+		name := mt.Manifest.Name
+		prev, seen := c.resources[name]
+		cur := prev
+
+		// If a build started and finished since we last looked,
+		// we never saw it in CurrentBuild, but it still started.
+		lastBuild := ms.LastBuild()
+		buildStart := ms.CurrentBuild.StartTime
+		if buildStart.IsZero() && !lastBuild.FinishTime.Equal(prev.buildFinish) {
+			buildStart = lastBuild.StartTime
+		}
+
+		if !buildStart.IsZero() && !buildStart.Equal(prev.buildStart) {
+			cur.buildStart = buildStart
+			edits := ms.CurrentBuild.Edits
+			if ms.CurrentBuild.Empty() {
+				edits = lastBuild.Edits
+			}
+			batch.events = append(batch.events, events.Event{
+				Type:     events.TypeBuildStarted,
+				Resource: name,
+				Time:     buildStart,
+				Edits:    edits,
+			})
+		}
+
+		if !lastBuild.FinishTime.IsZero() && !lastBuild.FinishTime.Equal(prev.buildFinish) {
+			cur.buildFinish = lastBuild.FinishTime
+			e := events.Event{
+				Type:       events.TypeBuildSucceeded,
+				Resource:   name,
+				Time:       lastBuild.FinishTime,
+				Edits:      lastBuild.Edits,
+				DurationMs: int64(lastBuild.Duration() / time.Millisecond),
+			}
+			if lastBuild.Error != nil {
+				e.Type = events.TypeBuildFailed
+				e.Error = lastBuild.Error.Error()
+			}
+			batch.events = append(batch.events, e)
+		}
+
+		cur.ready = mt.IsReady()
+		if cur.ready != prev.ready && (seen || cur.ready) {
+			e := events.Event{Type: events.TypeResourceReady, Resource: name, Time: time.Now()}
+			if !cur.ready {
+				e.Type = events.TypeResourceUnready
+			}
+			batch.events = append(batch.events, e)
+		}
+
+		c.resources[name] = cur
+	}
+	return batch
+}
+
+func (c *EventSinkController) OnChange(ctx context.Context, st store.RStore) {
+	if !c.started {
+		c.started = true
+		go c.sendLoop(ctx)
+	}
+
+	batch := c.diff(st)
+	if len(batch.events) == 0 || len(batch.sinks) == 0 {
+		return
+	}
+
+	select {
+	case c.queue <- batch:
+	default:
+		logger.Get(ctx).Debugf("Event sinks are falling behind; dropped %d events", len(batch.events))
+	}
+}
+
+// Sends events one at a time, so that each sink gets them in order.
+func (c *EventSinkController) sendLoop(ctx context.Context) {
+	for {
+		select {
+		case batch := <-c.queue:
+			for _, e := range batch.events {
+				for i, sink := range batch.sinks {
+					err := sink.Send(ctx, e)
+					if err != nil && ctx.Err() == nil {
+						logger.Get(ctx).Infof("Error sending %s event to %s: %v", e.Type, batch.specs[i], err)
+					}
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+var _ store.Subscriber = &EventSinkController{}
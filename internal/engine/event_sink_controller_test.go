@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/events"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestEventSinkBuildEvents(t *testing.T) {
+	f := newEventSinkFixture(t)
+	defer f.TearDown()
+
+	assert.Empty(t, f.diff())
+
+	start := time.Now()
+	f.mt.State.CurrentBuild = model.BuildRecord{StartTime: start, Edits: []string{"main.go"}}
+	assert.Equal(t, []events.Event{
+		{Type: events.TypeBuildStarted, Resource: "sancho", Time: start, Edits: []string{"main.go"}},
+	}, f.diff())
+
+	// Nothing changed, so nothing to send.
+	assert.Empty(t, f.diff())
+
+	finish := start.Add(2 * time.Second)
+	f.mt.State.CurrentBuild = model.BuildRecord{}
+	f.mt.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  start,
+		FinishTime: finish,
+		Edits:      []string{"main.go"},
+		Error:      fmt.Errorf("compile error"),
+	})
+	assert.Equal(t, []events.Event{
+		{
+			Type:       events.TypeBuildFailed,
+			Resource:   "sancho",
+			Time:       finish,
+			Edits:      []string{"main.go"},
+			DurationMs: 2000,
+			Error:      "compile error",
+		},
+	}, f.diff())
+}
+
+func TestEventSinkBuildWeNeverSawStart(t *testing.T) {
+	f := newEventSinkFixture(t)
+	defer f.TearDown()
+
+	start := time.Now()
+	f.mt.State.AddCompletedBuild(model.BuildRecord{StartTime: start, FinishTime: start.Add(time.Second)})
+	f.mt.State.LastSuccessfulDeployTime = start.Add(time.Second)
+
+	batch := f.diff()
+	if assert.Equal(t, 3, len(batch)) {
+		assert.Equal(t, events.TypeBuildStarted, batch[0].Type)
+		assert.Equal(t, events.TypeBuildSucceeded, batch[1].Type)
+		assert.Equal(t, int64(1000), batch[1].DurationMs)
+		assert.Equal(t, events.TypeResourceReady, batch[2].Type)
+	}
+}
+
+func TestEventSinkReadyAndUnready(t *testing.T) {
+	f := newEventSinkFixture(t)
+	defer f.TearDown()
+
+	f.mt.Manifest.ReadinessCheck = model.ReadinessCheck{LogRegex: "listening"}
+	start := time.Now()
+	f.mt.State.AddCompletedBuild(model.BuildRecord{StartTime: start, FinishTime: start})
+	f.diff()
+
+	f.mt.State.ReadinessCheckPassed = true
+	batch := f.diff()
+	if assert.Equal(t, 1, len(batch)) {
+		assert.Equal(t, events.TypeResourceReady, batch[0].Type)
+	}
+
+	f.mt.State.CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+	batch = f.diff()
+	if assert.Equal(t, 2, len(batch)) {
+		assert.Equal(t, events.TypeBuildStarted, batch[0].Type)
+		assert.Equal(t, events.TypeResourceUnready, batch[1].Type)
+	}
+}
+
+func TestEventSinkNewSinksFromState(t *testing.T) {
+	f := newEventSinkFixture(t)
+	defer f.TearDown()
+
+	f.state.EventSinks = []model.EventSink{{URL: "http://localhost:9000/events"}}
+	f.st.SetState(*f.state)
+	batch := f.c.diff(f.st)
+	assert.Equal(t, []events.Sink{f.sink}, batch.sinks)
+	assert.Equal(t, f.state.EventSinks, f.specs)
+}
+
+func TestEventSinkSendsEvents(t *testing.T) {
+	f := newEventSinkFixture(t)
+	defer f.TearDown()
+
+	ctx, cancel := context.WithCancel(output.CtxForTest())
+	defer cancel()
+
+	f.state.EventSinks = []model.EventSink{{Cmd: model.ToShellCmd("./notify.sh")}}
+	f.mt.State.CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+	f.st.SetState(*f.state)
+	f.c.OnChange(ctx, f.st)
+
+	timeout := time.After(time.Second)
+	for len(f.sink.Events()) == 0 {
+		select {
+		case <-timeout:
+			t.Fatal("timed out waiting for the sink to get an event")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	assert.Equal(t, events.TypeBuildStarted, f.sink.Events()[0].Type)
+}
+
+type eventSinkFixture struct {
+	*tempdir.TempDirFixture
+	c     *EventSinkController
+	st    *store.TestingStore
+	mt    *store.ManifestTarget
+	state *store.EngineState
+	sink  *events.FakeSink
+	specs []model.EventSink
+}
+
+func newEventSinkFixture(t *testing.T) *eventSinkFixture {
+	f := tempdir.NewTempDirFixture(t)
+
+	state := store.NewState()
+	m := NewSanchoDockerBuildManifest(f)
+	mt := store.NewManifestTarget(m)
+	state.UpsertManifestTarget(mt)
+
+	st := store.NewTestingStore()
+	st.SetState(*state)
+
+	ret := &eventSinkFixture{
+		TempDirFixture: f,
+		c:              NewEventSinkController(),
+		st:             st,
+		mt:             mt,
+		state:          state,
+		sink:           events.NewFakeSink(),
+	}
+	ret.c.newSink = func(spec model.EventSink) events.Sink {
+		ret.specs = append(ret.specs, spec)
+		return ret.sink
+	}
+	return ret
+}
+
+func (f *eventSinkFixture) diff() []events.Event {
+	f.st.SetState(*f.state)
+	return f.c.diff(f.st).events
+}
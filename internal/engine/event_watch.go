@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Watches k8s events, to find out why pods fail in ways
+// that don't show up in the pod status (e.g., a volume that won't mount),
+// and to show the events about each resource's objects in its log.
+type EventWatcher struct {
+	clients   *k8s.ClientRegistry
+	watching  map[eventWatch]bool
+	startTime time.Time
+
+	// Connections we couldn't get a client for, so that we don't keep trying.
+	unreachable map[k8s.Connection]bool
+}
+
+type eventWatch struct {
+	conn k8s.Connection
+	ns   k8s.Namespace
+}
+
+func NewEventWatcher(clients *k8s.ClientRegistry) *EventWatcher {
+	return &EventWatcher{
+		clients:     clients,
+		watching:    make(map[eventWatch]bool),
+		unreachable: make(map[k8s.Connection]bool),
+	}
+}
+
+// The k8s targets that we deploy with each connection.
+func (w *EventWatcher) targetsByConnection(st store.RStore) (map[k8s.Connection][]model.K8sTarget, []k8s.Connection) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	if !state.WatchFiles {
+		return nil, nil
+	}
+
+	targets := make(map[k8s.Connection][]model.K8sTarget)
+	conns := k8sConnections(w.clients, state.Manifests())
+	for _, m := range state.Manifests() {
+		if m.IsK8s() {
+			conn := w.clients.Normalize(k8s.ConnectionForTarget(m.K8sTarget()))
+			targets[conn] = append(targets[conn], m.K8sTarget())
+		}
+	}
+	return targets, conns
+}
+
+func (w *EventWatcher) OnChange(ctx context.Context, st store.RStore) {
+	targets, conns := w.targetsByConnection(st)
+	for _, conn := range conns {
+		if w.unreachable[conn] {
+			continue
+		}
+		kCli, err := w.clients.ClientFor(ctx, conn)
+		if err != nil {
+			// The deploy with this connection reports the same error.
+			logger.Get(ctx).Debugf("Error watching k8s events: %v", err)
+			w.unreachable[conn] = true
+			continue
+		}
+
+		for _, kTarget := range targets[conn] {
+			for _, ns := range k8s.TargetNamespaces(kTarget, kCli.ConfigNamespace()) {
+				key := eventWatch{conn: conn, ns: ns}
+				if w.watching[key] {
+					continue
+				}
+				w.watching[key] = true
+				if w.startTime.IsZero() {
+					w.startTime = time.Now()
+				}
+
+				ch, err := kCli.WatchEvents(ctx, ns)
+				if err != nil {
+					// Events are nice-to-have, so don't stop the world if the user
+					// isn't allowed to watch them.
+					logger.Get(ctx).Debugf("Error watching k8s events in namespace %s: %v", ns, err)
+					continue
+				}
+
+				go w.dispatchEventsLoop(ctx, ch, st, kCli.ConfigNamespace())
+			}
+		}
+	}
+}
+
+func (w *EventWatcher) dispatchEventsLoop(ctx context.Context, ch <-chan *v1.Event, st store.RStore, configNs k8s.Namespace) {
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			// The cluster keeps events around for an hour, so skip the ones
+			// from before we started watching.
+			ts := k8sEventTime(event)
+			if !ts.IsZero() && ts.Before(w.startTime) {
+				continue
+			}
+
+			// Most events are noise. Only send the ones that we'll show to the user.
+			if podEventAlert(event) == "" && !w.isForOurObject(st, event, configNs) {
+				continue
+			}
+
+			st.Dispatch(K8sEventAction{Event: event, ConfigNamespace: configNs})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *EventWatcher) isForOurObject(st store.RStore, e *v1.Event, configNs k8s.Namespace) bool {
+	state := st.RLockState()
+	defer st.RUnlockState()
+	_, ok := manifestForK8sEvent(&state, e, configNs)
+	return ok
+}
+
+// Finds the resource that owns the object that the event is about.
+//
+// configNs is where objects that don't set a namespace go, so that an event
+// about someone else's object with the same name in another namespace doesn't match.
+func manifestForK8sEvent(state *store.EngineState, e *v1.Event, configNs k8s.Namespace) (model.ManifestName, bool) {
+	obj := e.InvolvedObject
+	for _, mt := range state.ManifestTargets {
+		if !mt.Manifest.IsK8s() {
+			continue
+		}
+
+		switch obj.Kind {
+		case "Pod":
+			pod, ok := mt.State.PodSet.Pods[k8s.PodID(obj.Name)]
+			if ok && pod.Namespace == k8s.Namespace(obj.Namespace) {
+				return mt.Manifest.Name, true
+			}
+		case "ReplicaSet":
+			if _, ok := mt.State.ReplicaSets[obj.Name]; ok {
+				return mt.Manifest.Name, true
+			}
+		}
+
+		if k8sTargetHasObject(mt.Manifest.K8sTarget(), obj, configNs) {
+			return mt.Manifest.Name, true
+		}
+	}
+	return "", false
+}
+
+func k8sTargetHasObject(kTarget model.K8sTarget, obj v1.ObjectReference, configNs k8s.Namespace) bool {
+	if configNs == "" {
+		configNs = k8s.DefaultNamespace
+	}
+
+	entities, err := k8s.ParseYAMLFromString(kTarget.YAML)
+	if err != nil {
+		return false
+	}
+	for _, e := range entities {
+		ns := e.ExplicitNamespace()
+		if ns == "" {
+			ns = configNs
+		}
+		if e.HasKind(obj.Kind) && e.Name() == obj.Name && ns == k8s.Namespace(obj.Namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// When the event last happened. Older clusters only set the timestamps,
+// newer ones may only set the event time.
+func k8sEventTime(e *v1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.FirstTimestamp.Time
+}
+
+// The parts of the event that we show in the resource detail view.
+func k8sEventRecord(e *v1.Event) store.K8sEvent {
+	return store.K8sEvent{
+		Time:    k8sEventTime(e),
+		Object:  fmt.Sprintf("%s/%s", strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name),
+		Type:    e.Type,
+		Reason:  e.Reason,
+		Message: strings.TrimSpace(e.Message),
+		Count:   e.Count,
+	}
+}
+
+// Formats the event like `kubectl get events` does, for the resource's log.
+func k8sEventLogEvent(e *v1.Event) store.LogEvent {
+	reason := e.Reason
+	if e.Type == v1.EventTypeWarning {
+		reason = "WARNING " + reason
+	}
+	msg := fmt.Sprintf("[event: %s/%s] %s: %s",
+		strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name, reason, strings.TrimSpace(e.Message))
+	if e.Count > 1 {
+		msg = fmt.Sprintf("%s (x%d)", msg, e.Count)
+	}
+
+	le := store.NewLogEvent([]byte(msg + "\n"))
+	if ts := k8sEventTime(e); !ts.IsZero() {
+		le.Timestamp = ts
+	}
+	return le
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestEventWatcherWatchesDeployedNamespaces(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	kCli := k8s.NewFakeK8sClient()
+	w := NewEventWatcher(k8s.NewClientRegistryForTests(kCli))
+	st := store.NewTestingStore()
+
+	sancho := NewSanchoDockerBuildManifest(f)
+	kTarget := sancho.K8sTarget()
+	kTarget.YAML = strings.Replace(kTarget.YAML, "namespace: sancho-ns", "namespace: other-ns", 1)
+	other := sancho.WithDeployTarget(kTarget)
+	other.Name = "other"
+
+	state := store.NewState()
+	state.WatchFiles = true
+	for _, m := range []model.Manifest{sancho, other} {
+		state.ManifestTargets[m.Name] = store.NewManifestTarget(m)
+		state.ManifestDefinitionOrder = append(state.ManifestDefinitionOrder, m.Name)
+	}
+	st.SetState(*state)
+
+	ctx := output.CtxForTest()
+	w.OnChange(ctx, st)
+	assert.Equal(t, []k8s.Namespace{"sancho-ns", "other-ns"}, kCli.EventWatchNamespaces())
+
+	// Don't watch the same namespace twice.
+	w.OnChange(ctx, st)
+	assert.Equal(t, []k8s.Namespace{"sancho-ns", "other-ns"}, kCli.EventWatchNamespaces())
+}
+
+func TestEventWatcherWatchesEachKubeContext(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	kCli := k8s.NewFakeK8sClient()
+	remote := k8s.NewFakeK8sClient()
+	clients := k8s.NewClientRegistryForTests(kCli)
+	clients.SetClientForTests("remote", remote)
+	w := NewEventWatcher(clients)
+	st := store.NewTestingStore()
+
+	sancho := NewSanchoDockerBuildManifest(f)
+	other := sancho.WithDeployTarget(sancho.K8sTarget().WithKubeContext("remote"))
+	other.Name = "other"
+
+	state := store.NewState()
+	state.WatchFiles = true
+	for _, m := range []model.Manifest{sancho, other} {
+		state.ManifestTargets[m.Name] = store.NewManifestTarget(m)
+		state.ManifestDefinitionOrder = append(state.ManifestDefinitionOrder, m.Name)
+	}
+	st.SetState(*state)
+
+	w.OnChange(output.CtxForTest(), st)
+	assert.Equal(t, []k8s.Namespace{"sancho-ns"}, kCli.EventWatchNamespaces())
+	assert.Equal(t, []k8s.Namespace{"sancho-ns"}, remote.EventWatchNamespaces())
+}
+
+func TestManifestForK8sEventMatchesNamespace(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	sancho := NewSanchoDockerBuildManifest(f)
+	kTarget := sancho.K8sTarget()
+	kTarget.YAML = strings.Replace(kTarget.YAML, "  namespace: sancho-ns\n", "", 1)
+	sancho = sancho.WithDeployTarget(kTarget)
+
+	state := store.NewState()
+	state.UpsertManifestTarget(store.NewManifestTarget(sancho))
+
+	event := func(ns string) *v1.Event {
+		return &v1.Event{InvolvedObject: v1.ObjectReference{Kind: "Deployment", Name: "sancho", Namespace: ns}}
+	}
+
+	// The Deployment doesn't set a namespace, so it's in the kubeconfig's namespace.
+	name, ok := manifestForK8sEvent(state, event("my-ns"), "my-ns")
+	assert.True(t, ok)
+	assert.Equal(t, sancho.Name, name)
+
+	// Someone else's Deployment with the same name.
+	_, ok = manifestForK8sEvent(state, event("other-ns"), "my-ns")
+	assert.False(t, ok)
+
+	_, ok = manifestForK8sEvent(state, event("default"), "")
+	assert.True(t, ok)
+}
@@ -89,15 +89,21 @@ func extractImageTargetsForLiveUpdates(specs []model.TargetSpec, stateSet store.
 // Returns true if the given image is deployed to one of the given k8s targets.
 // Note that some images are injected into other images, so may never be deployed.
 func isImageDeployedToK8s(iTarget model.ImageTarget, kTargets []model.K8sTarget) bool {
+	_, ok := k8sTargetForImage(iTarget, kTargets)
+	return ok
+}
+
+// Returns the k8s target that deploys the given image, if one of the given targets does.
+func k8sTargetForImage(iTarget model.ImageTarget, kTargets []model.K8sTarget) (model.K8sTarget, bool) {
 	id := iTarget.ID()
 	for _, kTarget := range kTargets {
 		for _, depID := range kTarget.DependencyIDs() {
 			if depID == id {
-				return true
+				return kTarget, true
 			}
 		}
 	}
-	return false
+	return model.K8sTarget{}, false
 }
 
 // Returns true if the given image is deployed to one of the given docker-compose targets.
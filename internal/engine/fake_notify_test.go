@@ -1,8 +1,10 @@
 package engine
 
 import (
+	"fmt"
 	"sync"
 
+	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/ospath"
 	"github.com/windmilleng/tilt/internal/watch"
 )
@@ -15,6 +17,7 @@ type fakeMultiWatcher struct {
 	watchers   []*fakeWatcher
 	subs       []chan watch.FileEvent
 	subsErrors []chan error
+	settings   []model.WatchSettings
 }
 
 func newFakeMultiWatcher() *fakeMultiWatcher {
@@ -23,18 +26,30 @@ func newFakeMultiWatcher() *fakeMultiWatcher {
 	return r
 }
 
-func (w *fakeMultiWatcher) newSub() (watch.Notify, error) {
-	subCh := make(chan watch.FileEvent)
-	errorCh := make(chan error)
+func (w *fakeMultiWatcher) newSub(paths []string, ignore watch.PathMatcher, settings model.WatchSettings) (watch.Notify, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	watcher := newFakeWatcher(subCh, errorCh)
+	w.settings = append(w.settings, settings)
+	if settings.IsWatchman() {
+		// There's no Watchman daemon in tests.
+		return nil, watch.WatchmanUnavailableError{Err: fmt.Errorf("not running")}
+	}
+
+	subCh := make(chan watch.FileEvent)
+	errorCh := make(chan error)
+	watcher := newFakeWatcher(subCh, errorCh, paths)
 	w.watchers = append(w.watchers, watcher)
 	w.subs = append(w.subs, subCh)
 	w.subsErrors = append(w.subsErrors, errorCh)
 	return watcher, nil
 }
 
+func (w *fakeMultiWatcher) getSettings() []model.WatchSettings {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]model.WatchSettings{}, w.settings...)
+}
+
 func (w *fakeMultiWatcher) getSubs() []chan watch.FileEvent {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -90,8 +105,8 @@ type fakeWatcher struct {
 	paths []string
 }
 
-func newFakeWatcher(inboundCh chan watch.FileEvent, errorCh chan error) *fakeWatcher {
-	r := &fakeWatcher{inboundCh: inboundCh, outboundCh: make(chan watch.FileEvent, 20), errorCh: errorCh}
+func newFakeWatcher(inboundCh chan watch.FileEvent, errorCh chan error, paths []string) *fakeWatcher {
+	r := &fakeWatcher{inboundCh: inboundCh, outboundCh: make(chan watch.FileEvent, 20), errorCh: errorCh, paths: paths}
 	go r.loop()
 
 	return r
@@ -106,8 +121,11 @@ func (w *fakeWatcher) matches(path string) bool {
 	return false
 }
 
-func (w *fakeWatcher) Add(name string) error {
-	w.paths = append(w.paths, name)
+func (w *fakeWatcher) WatchedPaths() []string {
+	return append([]string{}, w.paths...)
+}
+
+func (w *fakeWatcher) Start() error {
 	return nil
 }
 
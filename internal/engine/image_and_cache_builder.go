@@ -43,11 +43,15 @@ func (icb *imageAndCacheBuilder) Build(ctx context.Context, iTarget model.ImageT
 
 	switch bd := iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
-		ps.StartPipelineStep(ctx, "Building Dockerfile: [%s]", userFacingRefName)
+		if bd.TargetStage.Empty() {
+			ctx = ps.StartPipelineStep(ctx, "Building Dockerfile: [%s]", userFacingRefName)
+		} else {
+			ctx = ps.StartPipelineStep(ctx, "Building Dockerfile: [%s] (target: %s)", userFacingRefName, bd.TargetStage)
+		}
 		defer ps.EndPipelineStep(ctx)
 
 		df := icb.dockerfile(iTarget, cacheRef)
-		ref, err := icb.ib.BuildDockerfile(ctx, ps, refToBuild, df, bd.BuildPath, ignore.CreateBuildContextFilter(iTarget), bd.BuildArgs)
+		ref, err := icb.ib.BuildDockerfile(ctx, ps, refToBuild, df, ignore.CreateBuildContextFilter(iTarget), bd)
 
 		if err != nil {
 			return nil, err
@@ -58,7 +62,7 @@ func (icb *imageAndCacheBuilder) Build(ctx context.Context, iTarget model.ImageT
 	case model.FastBuild:
 		if !state.HasImage() || icb.updateMode == UpdateModeNaive {
 			// No existing image to build off of, need to build from scratch
-			ps.StartPipelineStep(ctx, "Building from scratch: [%s]", userFacingRefName)
+			ctx = ps.StartPipelineStep(ctx, "Building from scratch: [%s]", userFacingRefName)
 			defer ps.EndPipelineStep(ctx)
 
 			df := icb.baseDockerfile(bd, cacheRef, iTarget.CachePaths())
@@ -83,7 +87,7 @@ func (icb *imageAndCacheBuilder) Build(ctx context.Context, iTarget model.ImageT
 				return nil, err
 			}
 
-			ps.StartPipelineStep(ctx, "Building from existing: [%s]", userFacingRefName)
+			ctx = ps.StartPipelineStep(ctx, "Building from existing: [%s]", userFacingRefName)
 			defer ps.EndPipelineStep(ctx)
 
 			runs := bd.Runs
@@ -94,9 +98,9 @@ func (icb *imageAndCacheBuilder) Build(ctx context.Context, iTarget model.ImageT
 			n = ref
 		}
 	case model.CustomBuild:
-		ps.StartPipelineStep(ctx, "Building Dockerfile: [%s]", userFacingRefName)
+		ctx = ps.StartPipelineStep(ctx, "Building Dockerfile: [%s]", userFacingRefName)
 		defer ps.EndPipelineStep(ctx)
-		ref, err := icb.custb.Build(ctx, refToBuild, bd.Command, bd.Tag)
+		ref, err := icb.custb.Build(ctx, refToBuild, bd.Command, bd.Tag, bd.Platform)
 		if err != nil {
 			return nil, err
 		}
@@ -150,6 +154,13 @@ func (icb *imageAndCacheBuilder) baseDockerfile(fbInfo model.FastBuild,
 func (icb *imageAndCacheBuilder) createCacheInputs(iTarget model.ImageTarget) build.CacheInputs {
 	baseDockerfile := dockerfile.Dockerfile(iTarget.TopFastBuildInfo().BaseDockerfile)
 	if dbInfo, ok := iTarget.BuildDetails.(model.DockerBuild); ok {
+		// The directory cache replaces everything before the first ADD,
+		// which would also drop the stage we're targeting.
+		// We don't know how to cache multi-stage builds yet.
+		if !dbInfo.TargetStage.Empty() {
+			return build.CacheInputs{}
+		}
+
 		df := dockerfile.Dockerfile(dbInfo.Dockerfile)
 		var ok bool
 		baseDockerfile, _, ok = df.SplitIntoBaseDockerfile()
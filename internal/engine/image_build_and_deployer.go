@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
 	"time"
 
 	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
 
 	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
 	"github.com/windmilleng/tilt/internal/dockerfile"
 	"github.com/windmilleng/tilt/internal/store"
 
@@ -31,53 +36,112 @@ type KINDPusher interface {
 	PushToKIND(ctx context.Context, ref reference.NamedTagged, w io.Writer) error
 }
 
-type cmdKINDPusher struct{}
+// Sideloads images into the nodes of a KIND cluster, so that the
+// cluster never needs to pull them from a registry.
+//
+// We export the image from the daemon we built it on, and hand the archive
+// to `kind load image-archive`. Unlike `kind load docker-image`, this works
+// even if the kind CLI would talk to a different docker daemon than we do.
+type cmdKINDPusher struct {
+	clusterName string
+	dCli        docker.Client
+}
+
+func (p *cmdKINDPusher) PushToKIND(ctx context.Context, ref reference.NamedTagged, w io.Writer) error {
+	archive, err := ioutil.TempFile("", "tilt-kind-load-*.tar")
+	if err != nil {
+		return errors.Wrap(err, "PushToKIND")
+	}
+	defer func() {
+		_ = os.Remove(archive.Name())
+	}()
 
-func (*cmdKINDPusher) PushToKIND(ctx context.Context, ref reference.NamedTagged, w io.Writer) error {
-	cmd := exec.CommandContext(ctx, "kind", "load", "docker-image", ref.String())
+	err = p.saveImage(ctx, ref, archive)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "Loading %s into KIND cluster %q\n", ref, p.clusterName)
+	cmd := exec.CommandContext(ctx, "kind", "load", "image-archive", "--name", p.clusterName, archive.Name())
 	cmd.Stdout = w
 	cmd.Stderr = w
 
-	return cmd.Run()
+	err = cmd.Run()
+	if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+		return fmt.Errorf("kind CLI not found. Install it (https://kind.sigs.k8s.io/) to load images into KIND clusters")
+	}
+	return err
 }
 
-func NewKINDPusher() KINDPusher {
-	return &cmdKINDPusher{}
+func (p *cmdKINDPusher) saveImage(ctx context.Context, ref reference.NamedTagged, archive *os.File) error {
+	defer func() {
+		_ = archive.Close()
+	}()
+
+	reader, err := p.dCli.ImageSave(ctx, []string{ref.String()})
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", ref)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	_, err = io.Copy(archive, reader)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", ref)
+	}
+	return nil
+}
+
+func NewKINDPusher(kubeContext k8s.KubeContext, dCli docker.Client) KINDPusher {
+	return &cmdKINDPusher{
+		clusterName: k8s.KINDClusterName(kubeContext),
+		dCli:        dCli,
+	}
 }
 
 type ImageBuildAndDeployer struct {
 	ib            build.ImageBuilder
 	icb           *imageAndCacheBuilder
-	k8sClient     k8s.Client
+	dCli          docker.Client
+	dEnv          docker.Env
+	clients       *k8s.ClientRegistry
 	env           k8s.Env
 	runtime       container.Runtime
 	analytics     analytics.Analytics
 	injectSynclet bool
 	clock         build.Clock
 	kp            KINDPusher
+	reuseImages   build.ReuseImages
 }
 
 func NewImageBuildAndDeployer(
 	b build.ImageBuilder,
 	cacheBuilder build.CacheBuilder,
 	customBuilder build.CustomBuilder,
-	k8sClient k8s.Client,
+	clients *k8s.ClientRegistry,
 	env k8s.Env,
 	analytics analytics.Analytics,
 	updMode UpdateMode,
 	c build.Clock,
 	runtime container.Runtime,
 	kp KINDPusher,
+	dCli docker.Client,
+	dEnv docker.Env,
+	reuseImages build.ReuseImages,
 ) *ImageBuildAndDeployer {
 	return &ImageBuildAndDeployer{
-		ib:        b,
-		icb:       NewImageAndCacheBuilder(b, cacheBuilder, customBuilder, updMode),
-		k8sClient: k8sClient,
-		env:       env,
-		analytics: analytics,
-		clock:     c,
-		runtime:   runtime,
-		kp:        kp,
+		ib:          b,
+		icb:         NewImageAndCacheBuilder(b, cacheBuilder, customBuilder, updMode),
+		dCli:        dCli,
+		dEnv:        dEnv,
+		clients:     clients,
+		env:         env,
+		analytics:   analytics,
+		clock:       c,
+		runtime:     runtime,
+		kp:          kp,
+		reuseImages: reuseImages,
 	}
 }
 
@@ -113,16 +177,23 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		return store.BuildResultSet{}, err
 	}
 
+	// Each connection gets its own deploy stage.
+	kTargetGroups := groupK8sTargetsByConnection(kTargets)
+
 	numStages := q.CountDirty() * 2 // each image target has two stages: one for build, and one for push
-	if len(kTargets) > 0 {
-		numStages++
-	}
+	numStages += len(kTargetGroups)
 
 	ps := build.NewPipelineState(ctx, numStages, ibd.clock)
 	defer func() { ps.End(ctx, err) }()
 
 	var anyInPlaceBuild bool
 
+	// Content-tagged images that come out with the same ref as last time
+	// haven't changed, so we don't need to push them again, or deploy them
+	// again if the YAML hasn't changed either.
+	anyBuilt := false
+	allUnchanged := true
+
 	iTargetMap := model.ImageTargetsByID(iTargets)
 	err = q.RunBuilds(func(target model.TargetSpec, state store.BuildState, depResults []store.BuildResult) (store.BuildResult, error) {
 		iTarget, ok := target.(model.ImageTarget)
@@ -140,7 +211,11 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 			return store.BuildResult{}, err
 		}
 
-		ref, err = ibd.push(ctx, ref, ps, iTarget, kTargets)
+		anyBuilt = true
+		unchanged := ibd.isUnchangedContentTag(iTarget, state, ref)
+		allUnchanged = allUnchanged && unchanged
+
+		ref, err = ibd.push(ctx, ref, ps, iTarget, kTargets, unchanged)
 		if err != nil {
 			return store.BuildResult{}, err
 		}
@@ -153,38 +228,72 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		return store.BuildResultSet{}, err
 	}
 
+	if anyBuilt && allUnchanged && len(kTargets) > 0 && isUnchangedDeploy(kTargets, stateSet) {
+		ctx = ps.StartPipelineStep(ctx, "Deploying")
+		ps.Printf(ctx, "Images unchanged. Skipping deploy")
+		ps.EndPipelineStep(ctx)
+		return q.results, nil
+	}
+
 	// (If we pass an empty list of refs here (as we will do if only deploying
 	// yaml), we just don't inject any image refs into the yaml, nbd.
-	err = ibd.deploy(ctx, st, ps, iTargetMap, kTargets, q.results, anyInPlaceBuild)
-	if err != nil {
-		return store.BuildResultSet{}, err
+	for _, group := range kTargetGroups {
+		err = ibd.deploy(ctx, st, ps, iTargetMap, group, q.results, anyInPlaceBuild)
+		if err != nil {
+			return store.BuildResultSet{}, err
+		}
 	}
 
 	return q.results, nil
 }
 
-func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ref reference.NamedTagged, ps *build.PipelineState, iTarget model.ImageTarget, kTargets []model.K8sTarget) (reference.NamedTagged, error) {
-	ps.StartPipelineStep(ctx, "Pushing %s", ref.String())
+func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ref reference.NamedTagged, ps *build.PipelineState, iTarget model.ImageTarget, kTargets []model.K8sTarget, unchanged bool) (reference.NamedTagged, error) {
+	ctx = ps.StartPipelineStep(ctx, "Pushing %s", ref.String())
 	defer ps.EndPipelineStep(ctx)
 
+	// We already pushed this exact image last time.
+	if unchanged {
+		ps.Printf(ctx, "Image unchanged. Skipping push")
+		return ref, nil
+	}
+
+	// If we reused an image that's in the registry but not in
+	// our Docker daemon, there's nothing to push.
+	if bool(ibd.reuseImages) && ibd.isContentTagged(iTarget) {
+		_, _, err := ibd.dCli.ImageInspectWithRaw(ctx, ref.String())
+		if client.IsErrNotFound(err) {
+			ps.Printf(ctx, "Image already in registry. Skipping push")
+			return ref, nil
+		}
+	}
+
 	cbSkip := false
 	if iTarget.IsCustomBuild() {
 		cbSkip = iTarget.CustomBuildInfo().DisablePush
 	}
 
+	// Clusters on other kube contexts don't share our Docker daemon,
+	// so they always pull from a registry.
+	toOtherContext := ibd.isImageDeployedToOtherKubeContext(iTarget, kTargets)
+
 	// We can also skip the push of the image if it isn't used
 	// in any k8s resources! (e.g., it's consumed by another image).
-	if ibd.canAlwaysSkipPush() || !isImageDeployedToK8s(iTarget, kTargets) || cbSkip {
+	if ibd.canAlwaysSkipPush() && !toOtherContext {
+		ps.Printf(ctx, "Skipping push: the cluster runs on the same Docker daemon we build with")
+		return ref, nil
+	}
+
+	if !isImageDeployedToK8s(iTarget, kTargets) || cbSkip {
 		ps.Printf(ctx, "Skipping push")
 		return ref, nil
 	}
 
 	var err error
-	if ibd.env == k8s.EnvKIND {
-		ps.Printf(ctx, "Pushing to KIND")
+	if ibd.env == k8s.EnvKIND && !toOtherContext {
+		ps.Printf(ctx, "Loading image into KIND cluster")
 		err := ibd.kp.PushToKIND(ctx, ref, ps.Writer(ctx))
 		if err != nil {
-			return nil, fmt.Errorf("Error pushing to KIND: %v", err)
+			return nil, fmt.Errorf("Error loading image into KIND: %v", err)
 		}
 	} else {
 		ps.Printf(ctx, "Pushing to registry")
@@ -200,19 +309,57 @@ func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ref reference.NamedT
 // Returns: the entities deployed and the namespace of the pod with the given image name/tag.
 func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, ps *build.PipelineState,
 	iTargetMap map[model.TargetID]model.ImageTarget, k8sTargets []model.K8sTarget, results store.BuildResultSet, needsSynclet bool) error {
-	ps.StartPipelineStep(ctx, "Deploying")
+	// All the targets in a deploy share a connection.
+	conn := k8s.ConnectionForTarget(k8sTargets[0])
+	sessionCluster := ibd.clients.IsDefaultCluster(conn)
+	if ibd.clients.IsDefault(conn) {
+		ctx = ps.StartPipelineStep(ctx, "Deploying")
+	} else {
+		ctx = ps.StartPipelineStep(ctx, "Deploying with %s", conn)
+	}
 	defer ps.EndPipelineStep(ctx)
 
+	kCli, err := ibd.clients.ClientFor(ctx, conn)
+	if err != nil {
+		return err
+	}
+
 	ps.StartBuildStep(ctx, "Parsing Kubernetes config YAML")
 
 	newK8sEntities := []k8s.K8sEntity{}
 
+	// Entities that we deploy with server-side apply, grouped by whether we force conflicts.
+	serverSideEntities := map[bool][]k8s.K8sEntity{}
+
 	deployID := model.NewDeployID()
 	deployLabel := k8s.TiltDeployLabel(deployID)
 
 	var targetIDs []model.TargetID
 
+	// The YAML that we apply for each target, so that we can tell later
+	// if someone changes the objects out from under us.
+	var appliedActions []K8sAppliedAction
+
+	// Image pull secrets we need to create, keyed by name and namespace.
+	pullSecrets := map[string]k8s.K8sEntity{}
+	pullSecretKeys := []string{}
+
+	// Charts that we deploy as Helm releases, rather than applying their YAML.
+	var helmReleases []model.HelmRelease
+	var helmImageValues [][]string
+
 	for _, k8sTarget := range k8sTargets {
+		if k8sTarget.HelmRelease != nil {
+			values, err := helmImageValuesForRelease(*k8sTarget.HelmRelease, results)
+			if err != nil {
+				return err
+			}
+			helmReleases = append(helmReleases, *k8sTarget.HelmRelease)
+			helmImageValues = append(helmImageValues, values)
+			targetIDs = append(targetIDs, k8sTarget.ID())
+			continue
+		}
+
 		// TODO(nick): The parsed YAML should probably be a part of the model?
 		// It doesn't make much sense to re-parse it and inject labels on every deploy.
 		entities, err := k8s.ParseYAMLFromString(k8sTarget.YAML)
@@ -222,9 +369,11 @@ func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, p
 
 		depIDs := k8sTarget.DependencyIDs()
 		injectedDepIDs := map[model.TargetID]bool{}
+		targetEntities := []k8s.K8sEntity{}
 		for _, e := range entities {
 			injectedSynclet := false
-			e, err = k8s.InjectLabels(e, []model.LabelPair{k8s.TiltRunLabel(), {Key: k8s.ManifestNameLabel, Value: k8sTarget.Name.String()}, deployLabel})
+			manifestLabel := model.LabelPair{Key: k8s.ManifestLabelKey(k8sTarget), Value: k8sTarget.Name.String()}
+			e, err = injectObjectMetadata(e, k8sTarget, k8s.TiltRunLabel(), manifestLabel, deployLabel)
 			if err != nil {
 				return errors.Wrap(err, "deploy")
 			}
@@ -240,7 +389,7 @@ func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, p
 			// When working with a local k8s cluster, we set the pull policy to Never,
 			// to ensure that k8s fails hard if the image is missing from docker.
 			policy := v1.PullIfNotPresent
-			if ibd.canAlwaysSkipPush() {
+			if ibd.canAlwaysSkipPush() && sessionCluster {
 				policy = v1.PullNever
 			}
 
@@ -275,7 +424,32 @@ func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, p
 					}
 				}
 			}
-			newK8sEntities = append(newK8sEntities, e)
+			targetEntities = append(targetEntities, e)
+		}
+
+		targetEntities, secrets, err := ibd.injectImagePullSecret(ctx, ps, k8sTarget, targetEntities, results, sessionCluster)
+		if err != nil {
+			return err
+		}
+		for _, secret := range secrets {
+			key := fmt.Sprintf("%s/%s", secret.Namespace(), secret.Name())
+			if _, ok := pullSecrets[key]; !ok {
+				pullSecretKeys = append(pullSecretKeys, key)
+			}
+			pullSecrets[key] = secret
+		}
+
+		appliedYAML, err := k8s.SerializeYAML(targetEntities)
+		if err != nil {
+			return err
+		}
+		appliedActions = append(appliedActions, K8sAppliedAction{TargetID: k8sTarget.ID(), YAML: appliedYAML})
+
+		if k8sTarget.ServerSideApply {
+			force := k8sTarget.ForceApplyConflicts
+			serverSideEntities[force] = append(serverSideEntities[force], targetEntities...)
+		} else {
+			newK8sEntities = append(newK8sEntities, targetEntities...)
 		}
 		targetIDs = append(targetIDs, k8sTarget.ID())
 
@@ -286,20 +460,277 @@ func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, p
 		}
 	}
 
+	retry := deployRetryPolicy(k8sTargets)
+
 	deployIDActions := NewDeployIDActionsForTargets(targetIDs, deployID)
 	for _, a := range deployIDActions {
 		st.Dispatch(a)
 	}
 
-	return ibd.k8sClient.Upsert(ctx, newK8sEntities)
+	// Create the namespaces before anything that lives in them.
+	seenNamespaces := map[string]bool{}
+	for _, k8sTarget := range k8sTargets {
+		ns := k8sTarget.Namespace
+		if ns == "" || seenNamespaces[ns] {
+			continue
+		}
+		seenNamespaces[ns] = true
+
+		err := retryDeploy(ctx, retry, func() error {
+			return kCli.CreateNamespaceIfMissing(ctx, k8s.Namespace(ns))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the pull secrets first, so that they exist by the time
+	// the pods that reference them get scheduled.
+	if len(pullSecretKeys) > 0 {
+		secretEntities := make([]k8s.K8sEntity, 0, len(pullSecretKeys))
+		for _, key := range pullSecretKeys {
+			secretEntities = append(secretEntities, pullSecrets[key])
+		}
+		err := retryDeploy(ctx, retry, func() error {
+			return kCli.Upsert(ctx, secretEntities)
+		})
+		if err != nil {
+			return errors.Wrap(err, "creating image pull secret")
+		}
+	}
+
+	for _, force := range []bool{false, true} {
+		entities := serverSideEntities[force]
+		if len(entities) == 0 {
+			continue
+		}
+		err := retryDeploy(ctx, retry, func() error {
+			return kCli.ServerSideApply(ctx, entities, force)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(newK8sEntities) > 0 || (len(serverSideEntities) == 0 && len(helmReleases) == 0) {
+		err := retryDeploy(ctx, retry, func() error {
+			return kCli.Upsert(ctx, newK8sEntities)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range appliedActions {
+		st.Dispatch(a)
+	}
+
+	// Helm releases go last, so that their hooks can see everything else we deployed.
+	for i, release := range helmReleases {
+		attempts := 0
+		err := retryDeploy(ctx, retry, func() error {
+			// A failed upgrade can leave the release pending, and Helm
+			// won't upgrade it again until we clear that.
+			if attempts > 0 {
+				err := kCli.HelmClearPending(ctx, release)
+				if err != nil {
+					return err
+				}
+			}
+			attempts++
+			return kCli.HelmUpgrade(ctx, release, helmImageValues[i])
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, k8sTarget := range k8sTargets {
+		results[k8sTarget.ID()] = store.NewK8sDeployResult(k8sTarget)
+	}
+	return nil
 }
 
-// If we're using docker-for-desktop as our k8s backend,
+// Returns the key=value pairs that point the chart at the images we built.
+func helmImageValuesForRelease(release model.HelmRelease, results store.BuildResultSet) ([]string, error) {
+	var values []string
+	for _, v := range release.ImageValues {
+		ref := results[v.ImageID].Image
+		if ref == nil {
+			return nil, fmt.Errorf("Internal error: missing build result for dependency ID: %s", v.ImageID)
+		}
+
+		if v.Key != "" {
+			values = append(values, fmt.Sprintf("%s=%s", v.Key, ref.String()))
+		}
+		if v.RepositoryKey != "" {
+			values = append(values, fmt.Sprintf("%s=%s", v.RepositoryKey, ref.Name()))
+		}
+		if v.TagKey != "" {
+			values = append(values, fmt.Sprintf("%s=%s", v.TagKey, ref.Tag()))
+		}
+	}
+	return values, nil
+}
+
+// If the target asks for an image pull secret, look up the local docker credentials
+// for the registries that its images were pushed to, and attach a secret with those
+// credentials to every pod.
+//
+// Returns: the new entities, and the secrets to create (one per namespace).
+func (ibd *ImageBuildAndDeployer) injectImagePullSecret(ctx context.Context, ps *build.PipelineState,
+	k8sTarget model.K8sTarget, entities []k8s.K8sEntity, results store.BuildResultSet, sessionCluster bool) ([]k8s.K8sEntity, []k8s.K8sEntity, error) {
+	name := k8sTarget.ImagePullSecret
+	if name == "" {
+		return entities, nil, nil
+	}
+
+	// If we never push, the cluster never pulls from a registry.
+	if sessionCluster && (ibd.canAlwaysSkipPush() || ibd.env == k8s.EnvKIND) {
+		return entities, nil, nil
+	}
+
+	authConfigs := map[string]types.AuthConfig{}
+	for _, depID := range k8sTarget.DependencyIDs() {
+		ref := results[depID].Image
+		if ref == nil {
+			continue
+		}
+
+		key, err := docker.RegistryAuthKey(ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := authConfigs[key]; ok {
+			continue
+		}
+
+		authConfig, err := ibd.dCli.RegistryAuth(ctx, ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		authConfigs[key] = authConfig
+	}
+
+	if len(authConfigs) == 0 {
+		return entities, nil, nil
+	}
+
+	dockerConfigJSON, err := docker.DockerConfigJSON(authConfigs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dockerConfigJSON == nil {
+		ps.Printf(ctx, "No docker credentials found for image pull secret %q. Skipping", name)
+		return entities, nil, nil
+	}
+
+	namespaces := []k8s.Namespace{}
+	seenNamespaces := map[k8s.Namespace]bool{}
+	result := make([]k8s.K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		e, hasPods, err := k8s.InjectImagePullSecret(e, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if hasPods && !seenNamespaces[e.Namespace()] {
+			seenNamespaces[e.Namespace()] = true
+			namespaces = append(namespaces, e.Namespace())
+		}
+		result = append(result, e)
+	}
+
+	secrets := make([]k8s.K8sEntity, 0, len(namespaces))
+	for _, ns := range namespaces {
+		secret, err := injectObjectMetadata(k8s.NewImagePullSecret(name, ns, dockerConfigJSON), k8sTarget, k8s.TiltRunLabel())
+		if err != nil {
+			return nil, nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return result, secrets, nil
+}
+
+// Adds the labels that Tilt tracks the target's objects with,
+// and the labels and annotations that the user asked for.
+func injectObjectMetadata(e k8s.K8sEntity, k8sTarget model.K8sTarget, tiltLabels ...model.LabelPair) (k8s.K8sEntity, error) {
+	if k8sTarget.Project != "" {
+		tiltLabels = append(tiltLabels, k8s.TiltProjectLabel(k8sTarget.Project))
+	}
+	labels := append(tiltLabels, k8sTarget.ObjectLabels...)
+	e, err := k8s.InjectLabels(e, labels)
+	if err != nil {
+		return k8s.K8sEntity{}, err
+	}
+	return k8s.InjectAnnotations(e, k8sTarget.ObjectAnnotations)
+}
+
+// Content-tagged images get the same ref when their inputs are the same.
+// If the ref matches the one we deployed last time, the image hasn't changed.
+func (ibd *ImageBuildAndDeployer) isUnchangedContentTag(iTarget model.ImageTarget, state store.BuildState, ref reference.NamedTagged) bool {
+	if !ibd.isContentTagged(iTarget) {
+		return false
+	}
+
+	lastRef := state.LastResult.Image
+	return lastRef != nil && lastRef.String() == ref.String()
+}
+
+// Whether the last successful deploy of each target had the same spec as now.
+// The BuildController leaves out the last deploy when it wants us
+// to deploy again anyway (e.g., the user triggered it, or the pod crashed).
+func isUnchangedDeploy(kTargets []model.K8sTarget, stateSet store.BuildStateSet) bool {
+	for _, kTarget := range kTargets {
+		last := stateSet[kTarget.ID()].LastResult.K8sTarget
+		if last == nil || !model.DeepEqual(*last, kTarget) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ibd *ImageBuildAndDeployer) isContentTagged(iTarget model.ImageTarget) bool {
+	db, ok := iTarget.BuildDetails.(model.DockerBuild)
+	return ok && (db.ContentTag || bool(ibd.reuseImages))
+}
+
+// If the cluster runs its containers on the docker daemon we build with
+// (e.g., docker-for-desktop, or minikube with docker-env),
 // we don't need to push to the central registry.
 // The k8s will use the image already available
 // in the local docker daemon.
 func (ibd *ImageBuildAndDeployer) canAlwaysSkipPush() bool {
-	return ibd.env.IsLocalCluster() && ibd.runtime == container.RuntimeDocker
+	return ibd.dEnv.SharedWithCluster
+}
+
+// Whether any of the targets that deploy this image go to
+// a cluster other than the one that Tilt started with.
+func (ibd *ImageBuildAndDeployer) isImageDeployedToOtherKubeContext(iTarget model.ImageTarget, kTargets []model.K8sTarget) bool {
+	var others []model.K8sTarget
+	for _, t := range kTargets {
+		if !ibd.clients.IsDefaultCluster(k8s.ConnectionForTarget(t)) {
+			others = append(others, t)
+		}
+	}
+	return isImageDeployedToK8s(iTarget, others)
+}
+
+// Splits the targets into the groups that deploy with the same connection
+// (kube context and credentials), in the order that each connection first appears.
+func groupK8sTargetsByConnection(kTargets []model.K8sTarget) [][]model.K8sTarget {
+	var groups [][]model.K8sTarget
+	index := map[k8s.Connection]int{}
+	for _, t := range kTargets {
+		conn := k8s.ConnectionForTarget(t)
+		i, ok := index[conn]
+		if !ok {
+			i = len(groups)
+			index[conn] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], t)
+	}
+	return groups
 }
 
 // Create a new ImageTarget with the dockerfiles rewritten
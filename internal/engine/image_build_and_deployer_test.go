@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -161,6 +163,44 @@ func TestDeployPodWithMultipleLiveUpdateImages(t *testing.T) {
 		"Expected synclet to be injected once in YAML: %s", f.k8s.Yaml)
 }
 
+func TestAppliedYAMLSent(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var applied []K8sAppliedAction
+	for _, a := range f.st.Actions {
+		if action, ok := a.(K8sAppliedAction); ok {
+			applied = append(applied, action)
+		}
+	}
+	if assert.Equal(t, 1, len(applied)) {
+		assert.Equal(t, manifest.K8sTarget().ID(), applied[0].TargetID)
+		assert.Equal(t, f.k8s.Yaml, applied[0].YAML)
+	}
+}
+
+func TestProjectLabelInjected(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithProject("0123456789abcdef"))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, f.k8s.Yaml, "tilt-project: 0123456789abcdef")
+}
+
 func TestDeployIDInjectedAndSent(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvGKE)
 	defer f.TearDown()
@@ -333,6 +373,335 @@ LABEL "tilt.buildMode"="scratch"`,
 	testutils.AssertFileInTar(t, tar.NewReader(f.docker.BuildOptions.Context), expected)
 }
 
+func TestDockerBuildTargetStage(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	iTarget := NewSanchoDockerBuildImageTarget(f)
+	db := iTarget.DockerBuildInfo()
+	db.TargetStage = "stage"
+	iTarget = iTarget.WithBuildDetails(db)
+	manifest := NewSanchoDockerBuildManifest(f).WithImageTarget(iTarget)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "stage", f.docker.BuildOptions.Target)
+}
+
+func TestImagePullSecret(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	f.docker.RegistryAuths["gcr.io"] = types.AuthConfig{Username: "nick", Password: "secret"}
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithImagePullSecret("tilt-registry"))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, f.k8s.Yaml, "imagePullSecrets:\n      - name: tilt-registry")
+}
+
+func TestImagePullSecretNoCredentials(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithImagePullSecret("tilt-registry"))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotContains(t, f.k8s.Yaml, "imagePullSecrets")
+}
+
+func TestCreateNamespaceBeforeDeploy(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithNamespace("alice"))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []k8s.Namespace{"alice"}, f.k8s.CreatedNamespaces)
+}
+
+func TestDeployToOtherKubeContext(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvDockerDesktop)
+	defer f.TearDown()
+
+	remote := k8s.NewFakeK8sClient()
+	f.ibd.clients.SetClientForTests("remote", remote)
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithKubeContext("remote"))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The image can't come from the local docker-desktop daemon, so we push it,
+	// and don't tell the other cluster to skip pulling it.
+	assert.Equal(t, 1, f.docker.PushCount)
+	assert.Contains(t, remote.Yaml, "name: sancho")
+	assert.NotContains(t, remote.Yaml, "imagePullPolicy: Never")
+	assert.Equal(t, "", f.k8s.Yaml)
+}
+
+func TestDeployWithImpersonation(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvDockerDesktop)
+	defer f.TearDown()
+
+	robot := k8s.NewFakeK8sClient()
+	f.ibd.clients.SetClientForConnectionForTests(k8s.Connection{ImpersonateUser: "ci-bot"}, robot)
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithCredentials("", "", "ci-bot", nil))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same cluster, so the image doesn't need to be pushed,
+	// but the YAML is applied with the robot's credentials.
+	assert.Equal(t, 0, f.docker.PushCount)
+	assert.Contains(t, robot.Yaml, "name: sancho")
+	assert.Equal(t, "", f.k8s.Yaml)
+}
+
+func TestDeployToUnknownKubeContext(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithKubeContext("nowhere"))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err == nil || !strings.Contains(err.Error(), `kube context "nowhere"`) {
+		t.Fatalf("Expected error about kube context, got: %v", err)
+	}
+}
+
+func TestDeployObjectMetadata(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithObjectMetadata(
+		[]model.LabelPair{{Key: "team", Value: "frontend"}},
+		[]model.LabelPair{{Key: "example.com/cost-center", Value: "eng"}},
+		"example.com/component"))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// On the Deployment and its pod template.
+	assert.Equal(t, 2, strings.Count(f.k8s.Yaml, "team: frontend"))
+	assert.Equal(t, 2, strings.Count(f.k8s.Yaml, "example.com/cost-center: eng"))
+	assert.Equal(t, 2, strings.Count(f.k8s.Yaml, "example.com/component: sancho"))
+	assert.NotContains(t, f.k8s.Yaml, k8s.ManifestNameLabel)
+	assert.Contains(t, f.k8s.Yaml, k8s.TiltRunIDLabel)
+}
+
+func TestServerSideApply(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithServerSideApply(true, true))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, f.k8s.ServerSideApplied)
+	assert.True(t, f.k8s.ForcedConflicts)
+	assert.Contains(t, f.k8s.Yaml, "name: sancho")
+}
+
+func TestDeployHelmRelease(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	iTarget := NewSanchoDockerBuildImageTarget(f)
+	release := model.HelmRelease{
+		Name:  "sancho",
+		Chart: "/charts/sancho",
+		ImageValues: []model.HelmImageValue{
+			{ImageID: iTarget.ID(), Key: "image"},
+			{ImageID: iTarget.ID(), RepositoryKey: "sidecar.repository", TagKey: "sidecar.tag"},
+		},
+	}
+	manifest := assembleK8sManifest(
+		model.Manifest{Name: "sancho"},
+		model.K8sTarget{Name: "sancho"}.WithHelmRelease(release),
+		iTarget)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Equal(t, 1, len(f.k8s.UpgradedHelmReleases)) {
+		assert.Equal(t, "sancho", f.k8s.UpgradedHelmReleases[0].Name)
+	}
+	if assert.Equal(t, 3, len(f.k8s.HelmImageValues)) {
+		assert.Regexp(t, "^image=gcr.io/some-project-162817/sancho:tilt-", f.k8s.HelmImageValues[0])
+		assert.Equal(t, "sidecar.repository=gcr.io/some-project-162817/sancho", f.k8s.HelmImageValues[1])
+		assert.Regexp(t, "^sidecar.tag=tilt-", f.k8s.HelmImageValues[2])
+	}
+
+	// Helm deploys the chart, so we don't apply any YAML.
+	assert.Empty(t, f.k8s.Yaml)
+}
+
+func TestDeployHelmReleaseClearsPendingBeforeRetry(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	release := model.HelmRelease{Name: "sancho", Chart: "/charts/sancho"}
+	kTarget := model.K8sTarget{Name: "sancho"}.
+		WithHelmRelease(release).
+		WithDeployRetry(model.DeployRetryPolicy{Retries: 1, Backoff: time.Millisecond})
+	manifest := assembleK8sManifest(model.Manifest{Name: "sancho"}, kTarget)
+
+	f.k8s.HelmUpgradeError = fmt.Errorf("Error: UPGRADE FAILED: context deadline exceeded")
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, len(f.k8s.UpgradedHelmReleases))
+	if assert.Equal(t, 1, len(f.k8s.ClearedHelmReleases)) {
+		assert.Equal(t, "sancho", f.k8s.ClearedHelmReleases[0].Name)
+	}
+}
+
+func TestDockerBuildNetworkSSHAndExtraHosts(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	iTarget := NewSanchoDockerBuildImageTarget(f)
+	db := iTarget.DockerBuildInfo()
+	db.Network = "host"
+	db.SSHSpecs = []string{"default"}
+	db.ExtraHosts = []string{"git.internal:10.0.0.1"}
+	iTarget = iTarget.WithBuildDetails(db)
+	manifest := NewSanchoDockerBuildManifest(f).WithImageTarget(iTarget)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "host", f.docker.BuildOptions.NetworkMode)
+	assert.Equal(t, []string{"default"}, f.docker.BuildOptions.SSHSpecs)
+	assert.Equal(t, []string{"git.internal:10.0.0.1"}, f.docker.BuildOptions.ExtraHosts)
+}
+
+func TestContentTagSkipsUnchangedImage(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	iTarget := NewSanchoDockerBuildImageTarget(f)
+	db := iTarget.DockerBuildInfo()
+	db.ContentTag = true
+	iTarget = iTarget.WithBuildDetails(db)
+	manifest := NewSanchoDockerBuildManifest(f).WithImageTarget(iTarget)
+
+	result, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 1, f.docker.PushCount)
+	assert.NotEmpty(t, f.k8s.Yaml)
+
+	// A file was touched, but the build inputs are the same. The image
+	// already exists, so we shouldn't build, push, or deploy.
+	ref := result[iTarget.ID()].Image
+	f.docker.Images[ref.String()] = types.ImageInspect{}
+	f.k8s.Yaml = ""
+	kID := manifest.K8sTarget().ID()
+	changed := []string{f.JoinPath("main.go")}
+	stateSet := store.BuildStateSet{
+		iTarget.ID(): store.NewBuildState(result[iTarget.ID()], changed),
+		kID:          store.NewBuildState(result[kID], nil),
+	}
+	result, err = f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), stateSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ref.String(), result[iTarget.ID()].Image.String())
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 1, f.docker.PushCount)
+	assert.Empty(t, f.k8s.Yaml)
+}
+
+func TestContentTagDeploysChangedYAML(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	iTarget := NewSanchoDockerBuildImageTarget(f)
+	db := iTarget.DockerBuildInfo()
+	db.ContentTag = true
+	iTarget = iTarget.WithBuildDetails(db)
+	manifest := NewSanchoDockerBuildManifest(f).WithImageTarget(iTarget)
+
+	result, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The image is the same, but the YAML isn't, so we deploy it.
+	ref := result[iTarget.ID()].Image
+	f.docker.Images[ref.String()] = types.ImageInspect{}
+	f.k8s.Yaml = ""
+	kTarget := manifest.K8sTarget()
+	kID := kTarget.ID()
+	kTarget.YAML = strings.Replace(kTarget.YAML, "sancho", "sancho-2", 1)
+	manifest = manifest.WithDeployTarget(kTarget)
+	stateSet := store.BuildStateSet{
+		iTarget.ID(): store.NewBuildState(result[iTarget.ID()], []string{f.JoinPath("main.go")}),
+		kID:          store.NewBuildState(result[kID], nil),
+	}
+	result, err = f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), stateSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Contains(t, f.k8s.Yaml, "sancho-2")
+
+	// Without the last deploy (e.g., the user triggered the update),
+	// we deploy even though nothing changed.
+	f.k8s.Yaml = ""
+	stateSet = store.BuildStateSet{
+		iTarget.ID(): store.NewBuildState(result[iTarget.ID()], []string{f.JoinPath("main.go")}),
+	}
+	_, err = f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), stateSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, f.k8s.Yaml)
+}
+
 func TestKINDPush(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvKIND)
 	defer f.TearDown()
@@ -348,6 +717,58 @@ func TestKINDPush(t *testing.T) {
 	assert.Equal(t, 0, f.docker.PushCount)
 }
 
+func TestKINDPusherSavesImageForCluster(t *testing.T) {
+	origPath := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	defer os.Setenv("PATH", origPath)
+
+	dCli := docker.NewFakeClient()
+	kp := NewKINDPusher(k8s.KubeContext("kind-dev"), dCli)
+	assert.Equal(t, "dev", kp.(*cmdKINDPusher).clusterName)
+
+	ref := container.MustParseNamedTagged("gcr.io/some-project-162817/sancho:tilt-11cd0eb38bc3ceb9")
+	err := kp.PushToKIND(output.CtxForTest(), ref, ioutil.Discard)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "kind CLI not found")
+	}
+	assert.Equal(t, []string{ref.String()}, dCli.SavedImageIDs)
+}
+
+func TestSkipPushOnDockerDesktop(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvDockerDesktop)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 0, f.docker.PushCount)
+	assert.Contains(t, f.k8s.Yaml, "imagePullPolicy: Never")
+}
+
+func TestPushOnDockerDesktopWithRemoteBuilder(t *testing.T) {
+	origHost := os.Getenv("DOCKER_HOST")
+	os.Setenv("DOCKER_HOST", "tcp://remote-builder:2376")
+	defer os.Setenv("DOCKER_HOST", origHost)
+
+	f := newIBDFixture(t, k8s.EnvDockerDesktop)
+	defer f.TearDown()
+
+	// The images we build aren't on the cluster's daemon, so we need to push.
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 1, f.docker.PushCount)
+	assert.NotContains(t, f.k8s.Yaml, "imagePullPolicy: Never")
+}
+
 func TestCustomBuildDisablePush(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvKIND)
 	defer f.TearDown()
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/docker/distribution/reference"
@@ -12,31 +13,49 @@ import (
 	"github.com/windmilleng/tilt/internal/store"
 )
 
+const DefaultImageGCInterval = time.Hour
+
+type ImageGCConfig struct {
+	// How many Tilt-built tags to keep for each image.
+	// If zero (the default), we never prune tags.
+	Keep int
+
+	// If true, also delete pruned tags from the registry they were pushed to.
+	Registry bool
+
+	// How often to prune tags, after the first time on startup.
+	Interval time.Duration
+}
+
 // Handles image garbage collection.
 type ImageController struct {
 	reaper       build.ImageReaper
+	config       ImageGCConfig
 	hasRunReaper bool
+
+	mu                  sync.Mutex
+	pruning             bool
+	lastPrune           time.Time
+	registryUnsupported bool
 }
 
-func NewImageController(reaper build.ImageReaper) *ImageController {
+func NewImageController(reaper build.ImageReaper, config ImageGCConfig) *ImageController {
+	if config.Interval == 0 {
+		config.Interval = DefaultImageGCInterval
+	}
 	return &ImageController{
 		reaper: reaper,
+		config: config,
 	}
 }
 
-func (c *ImageController) refsToReap(st store.RStore) []reference.Named {
+func (c *ImageController) imageRefs(st store.RStore) []reference.Named {
 	state := st.RLockState()
 	defer st.RUnlockState()
 	if !state.WatchFiles || len(state.ManifestTargets) == 0 {
 		return nil
 	}
 
-	// Only run the reaper once per invocation of Tilt
-	if c.hasRunReaper {
-		return nil
-	}
-
-	c.hasRunReaper = true
 	refs := []reference.Named{}
 	for _, manifest := range state.Manifests() {
 		for _, iTarget := range manifest.ImageTargets {
@@ -47,15 +66,40 @@ func (c *ImageController) refsToReap(st store.RStore) []reference.Named {
 }
 
 func (c *ImageController) OnChange(ctx context.Context, st store.RStore) {
-	refsToReap := c.refsToReap(st)
-	if len(refsToReap) > 0 {
+	refs := c.imageRefs(st)
+	if len(refs) == 0 {
+		return
+	}
+
+	// Only run the reaper once per invocation of Tilt
+	if !c.hasRunReaper {
+		c.hasRunReaper = true
 		go func() {
-			err := c.reapOldWatchBuilds(ctx, refsToReap, time.Now())
+			err := c.reapOldWatchBuilds(ctx, refs, time.Now())
 			if err != nil {
 				logger.Get(ctx).Debugf("Error garbage collecting builds: %v", err)
 			}
 		}()
 	}
+
+	if c.shouldPrune(time.Now()) {
+		go c.pruneOldTags(ctx, refs)
+	}
+}
+
+func (c *ImageController) shouldPrune(now time.Time) bool {
+	if c.config.Keep <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pruning || (!c.lastPrune.IsZero() && now.Sub(c.lastPrune) < c.config.Interval) {
+		return false
+	}
+	c.pruning = true
+	c.lastPrune = now
+	return true
 }
 
 func (c *ImageController) reapOldWatchBuilds(ctx context.Context, refs []reference.Named, createdBefore time.Time) error {
@@ -70,3 +114,41 @@ func (c *ImageController) reapOldWatchBuilds(ctx context.Context, refs []referen
 
 	return nil
 }
+
+// Keep the last N Tilt-built tags of each image, and delete the rest.
+func (c *ImageController) pruneOldTags(ctx context.Context, refs []reference.Named) {
+	defer func() {
+		c.mu.Lock()
+		c.pruning = false
+		c.mu.Unlock()
+	}()
+
+	c.mu.Lock()
+	pruneRegistry := c.config.Registry && !c.registryUnsupported
+	c.mu.Unlock()
+
+	seen := map[string]bool{}
+	count := 0
+	for _, ref := range refs {
+		if seen[ref.Name()] {
+			continue
+		}
+		seen[ref.Name()] = true
+
+		removed, err := c.reaper.PruneTiltTags(ctx, ref, c.config.Keep, pruneRegistry)
+		count += len(removed)
+		if err != nil && errors.Cause(err) == build.ErrRegistryDeleteUnsupported {
+			logger.Get(ctx).Infof("Not pruning old images from the registry of %s: %v", ref.Name(), errors.Cause(err))
+			c.mu.Lock()
+			c.registryUnsupported = true
+			c.mu.Unlock()
+			pruneRegistry = false
+		} else if err != nil {
+			logger.Get(ctx).Debugf("Error pruning old images of %s: %v", ref.Name(), err)
+		}
+	}
+
+	if count > 0 {
+		logger.Get(ctx).Debugf("Pruned %d old image tags", count)
+	}
+}
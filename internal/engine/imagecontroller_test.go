@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/build"
+	"github.com/windmilleng/tilt/internal/docker"
+)
+
+func TestImageControllerPrunesPeriodically(t *testing.T) {
+	ic := NewImageController(build.NewImageReaper(docker.NewFakeClient()), ImageGCConfig{Keep: 3})
+	start := time.Date(2019, 1, 1, 1, 1, 1, 1, time.UTC)
+
+	assert.True(t, ic.shouldPrune(start))
+
+	// Don't start a second prune while the first is still running.
+	assert.False(t, ic.shouldPrune(start.Add(2*time.Hour)))
+	ic.pruning = false
+
+	assert.False(t, ic.shouldPrune(start.Add(time.Minute)))
+	assert.True(t, ic.shouldPrune(start.Add(DefaultImageGCInterval)))
+}
+
+func TestImageControllerPruneDisabled(t *testing.T) {
+	ic := NewImageController(build.NewImageReaper(docker.NewFakeClient()), ImageGCConfig{})
+	assert.False(t, ic.shouldPrune(time.Now()))
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+const kubeContextPollInterval = 2 * time.Second
+
+type kubeContextLoader func() (k8s.KubeContext, k8s.Namespace, error)
+
+// Watches the kubeconfig for the user switching contexts (or namespaces)
+// in another terminal while Tilt is running.
+//
+// Deploying to wherever the kubeconfig points now would surprise the user,
+// so we pause k8s deploys until the kubeconfig points back to where Tilt
+// started, and tell the user why.
+type KubeContextWatcher struct {
+	kubeContext k8s.KubeContext
+	namespace   k8s.Namespace
+	env         k8s.Env
+	load        kubeContextLoader
+	interval    time.Duration
+
+	watching  bool
+	lastAlert string
+}
+
+func NewKubeContextWatcher(kubeContext k8s.KubeContext, namespace k8s.Namespace, env k8s.Env) *KubeContextWatcher {
+	return &KubeContextWatcher{
+		kubeContext: kubeContext,
+		namespace:   namespace,
+		env:         env,
+		load:        k8s.LoadCurrentContext,
+		interval:    kubeContextPollInterval,
+	}
+}
+
+func (w *KubeContextWatcher) needsWatch(st store.RStore) bool {
+	if w.env == k8s.EnvNone {
+		return false
+	}
+
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	atLeastOneK8S := false
+	for _, m := range state.Manifests() {
+		if m.IsK8s() {
+			atLeastOneK8S = true
+		}
+	}
+	return atLeastOneK8S && state.WatchFiles && !w.watching
+}
+
+func (w *KubeContextWatcher) OnChange(ctx context.Context, st store.RStore) {
+	if !w.needsWatch(st) {
+		return
+	}
+	w.watching = true
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.check(ctx, st)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (w *KubeContextWatcher) check(ctx context.Context, st store.RStore) {
+	kubeContext, namespace, err := w.load()
+	if err != nil {
+		// The kubeconfig may be mid-write. We'll catch the change on the next poll.
+		logger.Get(ctx).Debugf("Error reading kubeconfig: %v", err)
+		return
+	}
+
+	alert := w.alert(kubeContext, namespace)
+	if alert == w.lastAlert {
+		return
+	}
+	w.lastAlert = alert
+
+	if alert != "" {
+		logger.Get(ctx).Infof("WARNING: %s", alert)
+	} else {
+		logger.Get(ctx).Infof("Kubernetes context is back to %q (namespace %q). Resuming deploys.", w.kubeContext, w.namespace)
+	}
+	st.Dispatch(KubeContextChangeAction{Alert: alert})
+}
+
+func (w *KubeContextWatcher) alert(kubeContext k8s.KubeContext, namespace k8s.Namespace) string {
+	if kubeContext != w.kubeContext {
+		return fmt.Sprintf("Kubernetes context changed from %q to %q. Deploys are paused. "+
+			"Switch back to %q to resume, or restart Tilt to deploy to %q.",
+			w.kubeContext, kubeContext, w.kubeContext, kubeContext)
+	}
+	if namespace != w.namespace {
+		return fmt.Sprintf("Kubernetes namespace changed from %q to %q. Deploys are paused. "+
+			"Switch back to %q to resume, or restart Tilt to deploy to %q.",
+			w.namespace, namespace, w.namespace, namespace)
+	}
+	return ""
+}
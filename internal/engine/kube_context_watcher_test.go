@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestKubeContextWatcherContextChange(t *testing.T) {
+	f := newKubeContextWatcherFixture()
+
+	f.check()
+	assert.Empty(t, f.st.Actions)
+
+	f.kubeContext = "prod-cluster"
+	f.check()
+	if assert.Equal(t, 1, len(f.st.Actions)) {
+		alert := f.st.Actions[0].(KubeContextChangeAction).Alert
+		assert.Contains(t, alert, `Kubernetes context changed from "docker-for-desktop" to "prod-cluster"`)
+	}
+
+	// We only tell the user once.
+	f.check()
+	assert.Equal(t, 1, len(f.st.Actions))
+
+	f.kubeContext = "docker-for-desktop"
+	f.check()
+	if assert.Equal(t, 2, len(f.st.Actions)) {
+		assert.Equal(t, KubeContextChangeAction{}, f.st.Actions[1])
+	}
+}
+
+func TestKubeContextWatcherNamespaceChange(t *testing.T) {
+	f := newKubeContextWatcherFixture()
+
+	f.namespace = "other"
+	f.check()
+	if assert.Equal(t, 1, len(f.st.Actions)) {
+		alert := f.st.Actions[0].(KubeContextChangeAction).Alert
+		assert.Contains(t, alert, `Kubernetes namespace changed from "default" to "other"`)
+	}
+}
+
+func TestNoBuildsWhileKubeContextChanged(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	state := store.NewState()
+	state.ManifestTargets[m.Name] = store.NewManifestTarget(m)
+	state.ManifestDefinitionOrder = []model.ManifestName{m.Name}
+
+	state.KubeContextAlert = "Kubernetes context changed"
+	assert.Nil(t, nextTargetToBuild(*state))
+
+	state.KubeContextAlert = ""
+	assert.NotNil(t, nextTargetToBuild(*state))
+}
+
+type kubeContextWatcherFixture struct {
+	w           *KubeContextWatcher
+	st          *store.TestingStore
+	kubeContext k8s.KubeContext
+	namespace   k8s.Namespace
+}
+
+func newKubeContextWatcherFixture() *kubeContextWatcherFixture {
+	f := &kubeContextWatcherFixture{
+		st:          store.NewTestingStore(),
+		kubeContext: "docker-for-desktop",
+		namespace:   "default",
+	}
+	f.w = NewKubeContextWatcher("docker-for-desktop", "default", k8s.EnvDockerDesktop)
+	f.w.load = func() (k8s.KubeContext, k8s.Namespace, error) {
+		return f.kubeContext, f.namespace, nil
+	}
+	return f
+}
+
+func (f *kubeContextWatcherFixture) check() {
+	f.w.check(output.CtxForTest(), f.st)
+}
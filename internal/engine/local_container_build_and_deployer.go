@@ -22,14 +22,16 @@ type LocalContainerBuildAndDeployer struct {
 	cu        *build.ContainerUpdater
 	analytics analytics.Analytics
 	env       k8s.Env
+	clients   *k8s.ClientRegistry
 }
 
 func NewLocalContainerBuildAndDeployer(cu *build.ContainerUpdater,
-	analytics analytics.Analytics, env k8s.Env) *LocalContainerBuildAndDeployer {
+	analytics analytics.Analytics, env k8s.Env, clients *k8s.ClientRegistry) *LocalContainerBuildAndDeployer {
 	return &LocalContainerBuildAndDeployer{
 		cu:        cu,
 		analytics: analytics,
 		env:       env,
+		clients:   clients,
 	}
 }
 
@@ -43,14 +45,13 @@ func (cbd *LocalContainerBuildAndDeployer) BuildAndDeploy(ctx context.Context, s
 		return store.BuildResultSet{}, SilentRedirectToNextBuilderf("Local container builder needs exactly one image target")
 	}
 
+	liveUpdateState := liveUpdateStateSet[0]
 	isDC := len(model.ExtractDockerComposeTargets(specs)) > 0
-	isK8s := len(model.ExtractK8sTargets(specs)) > 0
-	canLocalUpdate := isDC || (isK8s && cbd.env.IsLocalCluster())
+	canLocalUpdate := isDC || cbd.isOnLocalCluster(liveUpdateState.iTarget, model.ExtractK8sTargets(specs))
 	if !canLocalUpdate {
 		return store.BuildResultSet{}, SilentRedirectToNextBuilderf("Local container builder needs docker-compose or k8s cluster w/ local updates")
 	}
 
-	liveUpdateState := liveUpdateStateSet[0]
 	iTarget := liveUpdateState.iTarget
 	state := liveUpdateState.iTargetState
 	filesChanged := liveUpdateState.filesChanged
@@ -114,6 +115,17 @@ func (cbd *LocalContainerBuildAndDeployer) BuildAndDeploy(ctx context.Context, s
 	return liveUpdateState.createResultSet(), nil
 }
 
+// Whether the image runs on a cluster that shares our docker daemon.
+// Only the env of the cluster that Tilt started with tells us that,
+// so the containers on any other cluster aren't ours to update.
+func (cbd *LocalContainerBuildAndDeployer) isOnLocalCluster(iTarget model.ImageTarget, kTargets []model.K8sTarget) bool {
+	kTarget, ok := k8sTargetForImage(iTarget, kTargets)
+	if !ok {
+		return false
+	}
+	return cbd.env.IsLocalCluster() && cbd.clients.IsDefaultCluster(k8s.ConnectionForTarget(kTarget))
+}
+
 func (cbd *LocalContainerBuildAndDeployer) buildAndDeploy(ctx context.Context, iTarget model.ImageTarget, state store.BuildState, changedFiles []build.PathMapping, runs []model.Run, hotReload bool) error {
 	deployInfo := state.DeployInfo
 	logger.Get(ctx).Infof("  → Updating container…")
@@ -0,0 +1,225 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// How long a serve_cmd gets to exit after we ask it to, before we kill it.
+const localServeStopGracePeriod = 5 * time.Second
+
+// Runs the serve_cmd of each local resource. Starts it after the resource's
+// first successful update, and restarts it after each one after that.
+//
+// Each serve_cmd runs in its own process group, so that when we stop it,
+// we stop everything it started too.
+type LocalServeController struct {
+	active map[model.ManifestName]*localServeProcess
+
+	// Every process that we've started that might still be running,
+	// including the ones we're in the middle of stopping.
+	procs []*localServeProcess
+
+	gracePeriod time.Duration
+}
+
+type localServeProcess struct {
+	name       model.ManifestName
+	cmd        model.Cmd
+	workdir    string
+	deployTime time.Time
+	ctx        context.Context
+	cancel     func()
+
+	// The process that this one replaces, which has to exit before this one starts.
+	prev *localServeProcess
+
+	// Closed once the process has exited (or we've decided not to start it).
+	done chan struct{}
+}
+
+func NewLocalServeController() *LocalServeController {
+	return &LocalServeController{
+		active:      make(map[model.ManifestName]*localServeProcess),
+		gracePeriod: localServeStopGracePeriod,
+	}
+}
+
+// Figure out which serve_cmds need to (re)start, and which ones we should stop.
+func (c *LocalServeController) diff(ctx context.Context, st store.RStore) (toStart []*localServeProcess, toStop []*localServeProcess) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	wanted := make(map[model.ManifestName]bool)
+	for _, mt := range state.EnabledTargets() {
+		lt := mt.Manifest.LocalTarget()
+		ms := mt.State
+		if lt.ServeCmd.Empty() || ms.LastSuccessfulDeployTime.IsZero() {
+			continue
+		}
+
+		wanted[ms.Name] = true
+		existing, ok := c.active[ms.Name]
+		if ok && existing.deployTime.Equal(ms.LastSuccessfulDeployTime) &&
+			model.DeepEqual(existing.cmd, lt.ServeCmd) && existing.workdir == lt.Workdir {
+			continue
+		}
+		if ok {
+			toStop = append(toStop, existing)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		p := &localServeProcess{
+			name:       ms.Name,
+			cmd:        lt.ServeCmd,
+			workdir:    lt.Workdir,
+			deployTime: ms.LastSuccessfulDeployTime,
+			ctx:        ctx,
+			cancel:     cancel,
+			prev:       existing,
+			done:       make(chan struct{}),
+		}
+		toStart = append(toStart, p)
+		c.active[ms.Name] = p
+	}
+
+	for name, p := range c.active {
+		if wanted[name] {
+			continue
+		}
+		toStop = append(toStop, p)
+		delete(c.active, name)
+	}
+
+	return toStart, toStop
+}
+
+func (c *LocalServeController) OnChange(ctx context.Context, st store.RStore) {
+	toStart, toStop := c.diff(ctx, st)
+	for _, p := range toStop {
+		p.cancel()
+	}
+
+	c.pruneExited()
+	for _, p := range toStart {
+		c.procs = append(c.procs, p)
+		go c.serve(st, p)
+	}
+}
+
+// Stops every serve_cmd, and waits for them to exit, so that none of them
+// outlive Tilt.
+func (c *LocalServeController) TearDown(ctx context.Context) {
+	for _, p := range c.procs {
+		p.cancel()
+	}
+	for _, p := range c.procs {
+		<-p.done
+	}
+	c.procs = nil
+}
+
+func (c *LocalServeController) pruneExited() {
+	running := c.procs[:0]
+	for _, p := range c.procs {
+		select {
+		case <-p.done:
+		default:
+			running = append(running, p)
+		}
+	}
+	c.procs = running
+}
+
+func (c *LocalServeController) serve(st store.RStore, p *localServeProcess) {
+	defer close(p.done)
+
+	if p.prev != nil {
+		<-p.prev.done
+	}
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	l := logger.Get(p.ctx)
+	w := io.MultiWriter(
+		logger.NewPrefixedWriter(logPrefix(p.name.String()), l.Writer(logger.InfoLvl)),
+		LocalServeLogActionWriter{store: st, manifestName: p.name},
+	)
+
+	// Tell the store about the process before it can print anything,
+	// so that its output goes in the new process's log.
+	startTime := time.Now()
+	st.Dispatch(LocalServeStatusAction{ManifestName: p.name, StartTime: startTime, Status: store.LocalServeStatusRunning})
+
+	cmd := exec.Command(p.cmd.Argv[0], p.cmd.Argv[1:]...)
+	cmd.Dir = p.workdir
+	cmd.Stdout = w
+	cmd.Stderr = w
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	err := cmd.Start()
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "Error starting serve_cmd `%s`: %v\n", p.cmd, err)
+		st.Dispatch(LocalServeStatusAction{ManifestName: p.name, StartTime: startTime, Status: store.LocalServeStatusError, Error: err})
+		return
+	}
+
+	pid := cmd.Process.Pid
+	st.Dispatch(LocalServeStatusAction{ManifestName: p.name, StartTime: startTime, Status: store.LocalServeStatusRunning, PID: pid})
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		status := store.LocalServeStatusCompleted
+		if err != nil {
+			status = store.LocalServeStatusError
+			_, _ = fmt.Fprintf(w, "serve_cmd `%s` exited: %v\n", p.cmd, err)
+		} else {
+			_, _ = fmt.Fprintf(w, "serve_cmd `%s` exited\n", p.cmd)
+		}
+		st.Dispatch(LocalServeStatusAction{ManifestName: p.name, StartTime: startTime, Status: status, Error: err})
+	case <-p.ctx.Done():
+		stopProcessGroup(pid, exited, c.gracePeriod)
+	}
+}
+
+// Asks every process in the group to exit, then kills them if they haven't
+// by the end of the grace period.
+func stopProcessGroup(pgid int, exited <-chan error, gracePeriod time.Duration) {
+	_ = syscall.Kill(-pgid, syscall.SIGTERM)
+	select {
+	case <-exited:
+	case <-time.After(gracePeriod):
+		_ = syscall.Kill(-pgid, syscall.SIGKILL)
+		<-exited
+	}
+}
+
+type LocalServeLogActionWriter struct {
+	store        store.RStore
+	manifestName model.ManifestName
+}
+
+func (w LocalServeLogActionWriter) Write(p []byte) (n int, err error) {
+	w.store.Dispatch(LocalServeLogAction{
+		ManifestName: w.manifestName,
+		LogEvent:     store.NewLogEvent(append([]byte{}, p...)),
+	})
+	return len(p), nil
+}
+
+var _ store.Subscriber = &LocalServeController{}
+var _ store.TearDowner = &LocalServeController{}
@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestLocalServeStartsAfterDeploy(t *testing.T) {
+	f := newLocalServeFixture(t, "./api")
+	defer f.TearDown()
+
+	toStart, _ := f.c.diff(output.CtxForTest(), f.st)
+	assert.Empty(t, toStart)
+
+	f.deploy()
+	toStart, toStop := f.c.diff(output.CtxForTest(), f.st)
+	if assert.Equal(t, 1, len(toStart)) {
+		assert.Equal(t, model.ManifestName("api"), toStart[0].name)
+		assert.Nil(t, toStart[0].prev)
+	}
+	assert.Empty(t, toStop)
+
+	// Still serving the same deploy, so nothing to do.
+	toStart, toStop = f.c.diff(output.CtxForTest(), f.st)
+	assert.Empty(t, toStart)
+	assert.Empty(t, toStop)
+
+	// Each new deploy replaces the old process.
+	first := f.c.active["api"]
+	f.deploy()
+	toStart, toStop = f.c.diff(output.CtxForTest(), f.st)
+	if assert.Equal(t, 1, len(toStart)) {
+		assert.Equal(t, first, toStart[0].prev)
+	}
+	assert.Equal(t, []*localServeProcess{first}, toStop)
+
+	// Disabling the resource stops it.
+	f.mt.State.Disabled = true
+	f.st.SetState(*f.state)
+	toStart, toStop = f.c.diff(output.CtxForTest(), f.st)
+	assert.Empty(t, toStart)
+	assert.Equal(t, 1, len(toStop))
+}
+
+func TestLocalServeRestartStopsProcessGroup(t *testing.T) {
+	// The serve_cmd starts a child, so that we can check that
+	// stopping it stops the child too.
+	f := newLocalServeFixture(t, "sleep 60 & echo $! > child.pid; wait")
+	defer f.TearDown()
+	ctx := output.CtxForTest()
+
+	f.deploy()
+	f.c.OnChange(ctx, f.st)
+	firstChild := f.waitForPID("child.pid")
+	assert.True(t, processAlive(firstChild))
+
+	f.deploy()
+	f.c.OnChange(ctx, f.st)
+	f.waitForDead(firstChild)
+
+	secondChild := f.waitForPID("child.pid")
+	for secondChild == firstChild {
+		time.Sleep(10 * time.Millisecond)
+		secondChild = f.waitForPID("child.pid")
+	}
+	assert.True(t, processAlive(secondChild))
+
+	f.c.TearDown(ctx)
+	f.waitForDead(secondChild)
+}
+
+func TestHandleLocalServeStatusAction(t *testing.T) {
+	f := newLocalServeFixture(t, "./api")
+	defer f.TearDown()
+
+	first := time.Now()
+	second := first.Add(time.Second)
+	ms := f.mt.State
+
+	handleLocalServeStatusAction(f.state, LocalServeStatusAction{ManifestName: "api", StartTime: first, Status: store.LocalServeStatusRunning})
+	handleLocalServeStatusAction(f.state, LocalServeStatusAction{ManifestName: "api", StartTime: first, Status: store.LocalServeStatusRunning, PID: 123})
+	handleLocalServeLogAction(f.state, LocalServeLogAction{ManifestName: "api", LogEvent: store.NewLogEvent([]byte("listening\n"))})
+	assert.Equal(t, store.LocalServeStatusRunning, ms.LocalResourceState().Status)
+	assert.Equal(t, 123, ms.LocalResourceState().PID)
+	assert.Equal(t, "listening\n", ms.LocalResourceState().CurrentLog.String())
+
+	// A new process gets a fresh log.
+	handleLocalServeStatusAction(f.state, LocalServeStatusAction{ManifestName: "api", StartTime: second, Status: store.LocalServeStatusRunning})
+	assert.Equal(t, "", ms.LocalResourceState().CurrentLog.String())
+
+	// We don't care about the old process anymore.
+	handleLocalServeStatusAction(f.state, LocalServeStatusAction{ManifestName: "api", StartTime: first, Status: store.LocalServeStatusError})
+	assert.Equal(t, store.LocalServeStatusRunning, ms.LocalResourceState().Status)
+
+	handleLocalServeStatusAction(f.state, LocalServeStatusAction{ManifestName: "api", StartTime: second, Status: store.LocalServeStatusError})
+	assert.Equal(t, store.LocalServeStatusError, ms.LocalResourceState().Status)
+}
+
+type localServeFixture struct {
+	*tempdir.TempDirFixture
+	t     *testing.T
+	c     *LocalServeController
+	st    *store.TestingStore
+	mt    *store.ManifestTarget
+	state *store.EngineState
+}
+
+func newLocalServeFixture(t *testing.T, serveCmd string) *localServeFixture {
+	f := tempdir.NewTempDirFixture(t)
+
+	state := store.NewState()
+	lt := model.NewLocalTarget("api", model.Cmd{}, model.ToShellCmd(serveCmd), nil, f.Path())
+	m := model.Manifest{Name: "api"}.WithDeployTarget(lt)
+	mt := store.NewManifestTarget(m)
+	state.UpsertManifestTarget(mt)
+
+	st := store.NewTestingStore()
+	st.SetState(*state)
+
+	c := NewLocalServeController()
+	c.gracePeriod = time.Second
+
+	return &localServeFixture{
+		TempDirFixture: f,
+		t:              t,
+		c:              c,
+		st:             st,
+		mt:             mt,
+		state:          state,
+	}
+}
+
+func (f *localServeFixture) deploy() {
+	// Make sure each deploy gets a different time.
+	time.Sleep(time.Millisecond)
+	f.mt.State.LastSuccessfulDeployTime = time.Now()
+	f.st.SetState(*f.state)
+}
+
+func (f *localServeFixture) waitForPID(path string) int {
+	timeout := time.After(5 * time.Second)
+	for {
+		contents, err := ioutil.ReadFile(f.JoinPath(path))
+		if err == nil {
+			pid, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+			if err == nil {
+				return pid
+			}
+		}
+
+		select {
+		case <-timeout:
+			f.t.Fatalf("timed out waiting for %s", path)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (f *localServeFixture) waitForDead(pid int) {
+	timeout := time.After(5 * time.Second)
+	for processAlive(pid) {
+		select {
+		case <-timeout:
+			f.t.Fatalf("timed out waiting for process %d to exit", pid)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// Zombies count as dead: nobody might be around to reap a child whose parent we killed.
+func processAlive(pid int) bool {
+	out, err := exec.Command("ps", "-o", "stat=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return false
+	}
+	stat := strings.TrimSpace(string(out))
+	return stat != "" && !strings.HasPrefix(stat, "Z")
+}
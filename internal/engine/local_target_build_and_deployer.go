@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"os/exec"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/build"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Updates local resources by running their cmd on the user's machine.
+//
+// Their serve_cmd isn't part of the update; the LocalServeController
+// (re)starts it once the update succeeds.
+type LocalTargetBuildAndDeployer struct {
+	clock build.Clock
+}
+
+var _ BuildAndDeployer = &LocalTargetBuildAndDeployer{}
+
+func NewLocalTargetBuildAndDeployer(c build.Clock) *LocalTargetBuildAndDeployer {
+	return &LocalTargetBuildAndDeployer{clock: c}
+}
+
+func (bd *LocalTargetBuildAndDeployer) extract(specs []model.TargetSpec) []model.LocalTarget {
+	var targets []model.LocalTarget
+	for _, s := range specs {
+		lt, ok := s.(model.LocalTarget)
+		if !ok {
+			return nil
+		}
+		targets = append(targets, lt)
+	}
+	return targets
+}
+
+func (bd *LocalTargetBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RStore, specs []model.TargetSpec, currentState store.BuildStateSet) (store.BuildResultSet, error) {
+	targets := bd.extract(specs)
+	if len(targets) != 1 {
+		return store.BuildResultSet{}, SilentRedirectToNextBuilderf(
+			"LocalTargetBuildAndDeployer requires exactly one LocalTarget (got %d)", len(targets))
+	}
+	lt := targets[0]
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "LocalTargetBuildAndDeployer-BuildAndDeploy")
+	span.SetTag("target", lt.Name)
+	defer span.Finish()
+
+	if !lt.Cmd.Empty() {
+		var err error
+		ps := build.NewPipelineState(ctx, 1, bd.clock)
+		defer func() { ps.End(ctx, err) }()
+
+		ctx = ps.StartPipelineStep(ctx, "Running `%s`", lt.Cmd)
+		err = runLocalCmd(ctx, lt.Cmd, lt.Workdir, ps.Writer(ctx))
+		ps.EndPipelineStep(ctx)
+		if err != nil {
+			return store.BuildResultSet{}, err
+		}
+	}
+
+	return store.BuildResultSet{
+		lt.ID(): store.NewLocalBuildResult(lt.ID()),
+	}, nil
+}
+
+func runLocalCmd(ctx context.Context, cmd model.Cmd, workdir string, w io.Writer) error {
+	c := exec.CommandContext(ctx, cmd.Argv[0], cmd.Argv[1:]...)
+	c.Dir = workdir
+	c.Stdout = w
+	c.Stderr = w
+
+	err := c.Run()
+	if err != nil {
+		return errors.Wrapf(err, "Command %q failed", cmd.String())
+	}
+	return nil
+}
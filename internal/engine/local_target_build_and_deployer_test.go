@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/build"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestLocalTargetRunsCmdInWorkdir(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	bd := NewLocalTargetBuildAndDeployer(build.ProvideClock())
+	lt := model.NewLocalTarget("codegen", model.ToShellCmd("echo hi > out.txt"), model.Cmd{}, nil, f.Path())
+
+	result, err := bd.BuildAndDeploy(output.CtxForTest(), store.NewTestingStore(), []model.TargetSpec{lt}, store.BuildStateSet{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, lt.ID(), result[lt.ID()].TargetID)
+	}
+	contents, err := ioutil.ReadFile(f.JoinPath("out.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hi\n", string(contents))
+}
+
+func TestLocalTargetCmdFails(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	bd := NewLocalTargetBuildAndDeployer(build.ProvideClock())
+	lt := model.NewLocalTarget("codegen", model.ToShellCmd("exit 1"), model.Cmd{}, nil, f.Path())
+
+	_, err := bd.BuildAndDeploy(output.CtxForTest(), store.NewTestingStore(), []model.TargetSpec{lt}, store.BuildStateSet{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Command "exit 1" failed`)
+	}
+}
+
+func TestLocalTargetRedirectsOtherTargets(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	bd := NewLocalTargetBuildAndDeployer(build.ProvideClock())
+	m := NewSanchoDockerBuildManifest(f)
+
+	_, err := bd.BuildAndDeploy(output.CtxForTest(), store.NewTestingStore(), buildTargets(m), store.BuildStateSet{})
+	_, ok := err.(RedirectToNextBuilder)
+	assert.True(t, ok)
+}
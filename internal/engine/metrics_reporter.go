@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/metrics"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Records builds and the build queue for /metrics.
+type MetricsReporter struct {
+	// The start time of the newest build we've recorded for each resource.
+	lastRecorded map[model.ManifestName]time.Time
+}
+
+func NewMetricsReporter() *MetricsReporter {
+	return &MetricsReporter{
+		lastRecorded: make(map[model.ManifestName]time.Time),
+	}
+}
+
+func (mr *MetricsReporter) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	for _, mt := range state.Targets() {
+		name := mt.Manifest.Name
+		last := mr.lastRecorded[name]
+
+		// Newest first, so go backwards to record them in order.
+		history := mt.State.BuildHistory
+		for i := len(history) - 1; i >= 0; i-- {
+			b := history[i]
+			if b.Empty() || !b.StartTime.After(last) {
+				continue
+			}
+
+			result := "ok"
+			if b.Error != nil {
+				result = "error"
+			}
+			metrics.BuildDuration.
+				WithLabelValues(name.String(), string(b.UpdateType), result).
+				Observe(b.Duration().Seconds())
+			last = b.StartTime
+		}
+		mr.lastRecorded[name] = last
+	}
+
+	metrics.BuildQueueDepth.Set(float64(buildQueueDepth(state)))
+}
+
+// How many resources would build if there were enough build slots. Follows
+// nextTargetToBuild, but doesn't count builds that are waiting on something
+// other than a slot, like unready dependencies.
+func buildQueueDepth(state store.EngineState) int {
+	triggered := make(map[model.ManifestName]bool)
+	for _, name := range state.TriggerQueue {
+		triggered[name] = true
+	}
+
+	count := 0
+	for _, mt := range state.EnabledTargets() {
+		name := mt.Manifest.Name
+		ms := mt.State
+		if state.CurrentlyBuilding[name] {
+			continue
+		}
+
+		waitingForAutoBuild := false
+		if state.TriggerModeForManifest(mt.Manifest) == model.TriggerAuto && !state.UpdatesPausedForManifest(name) {
+			waitingForAutoBuild, _ = ms.MostRecentPendingChange()
+		}
+
+		if !ms.StartedFirstBuild() || triggered[name] ||
+			(ms.NeedsRebuildFromCrash && ms.CrashBackoffUntil.IsZero()) || waitingForAutoBuild {
+			count++
+		}
+	}
+	return count
+}
+
+var _ store.Subscriber = &MetricsReporter{}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/metrics"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestMetricsReporterRecordsEachBuildOnce(t *testing.T) {
+	ctx := output.CtxForTest()
+	st, _ := store.NewStoreForTesting()
+	mr := NewMetricsReporter()
+
+	start := time.Now()
+	state := st.LockMutableStateForTesting()
+	mt := store.NewManifestTarget(model.Manifest{Name: "metrics-fe"})
+	mt.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  start,
+		FinishTime: start.Add(2 * time.Second),
+		UpdateType: model.UpdateTypeLiveUpdate,
+	})
+	state.UpsertManifestTarget(mt)
+	st.UnlockMutableState()
+
+	mr.OnChange(ctx, st)
+	mr.OnChange(ctx, st)
+	assert.Equal(t, uint64(1), buildCount(t, "metrics-fe", model.UpdateTypeLiveUpdate, "ok"))
+
+	state = st.LockMutableStateForTesting()
+	state.ManifestTargets["metrics-fe"].State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  start.Add(time.Minute),
+		FinishTime: start.Add(2 * time.Minute),
+		UpdateType: model.UpdateTypeImageBuild,
+		Error:      fmt.Errorf("oops"),
+	})
+	st.UnlockMutableState()
+
+	mr.OnChange(ctx, st)
+	assert.Equal(t, uint64(1), buildCount(t, "metrics-fe", model.UpdateTypeLiveUpdate, "ok"))
+	assert.Equal(t, uint64(1), buildCount(t, "metrics-fe", model.UpdateTypeImageBuild, "error"))
+}
+
+func TestBuildQueueDepth(t *testing.T) {
+	state := store.NewState()
+	for _, name := range []model.ManifestName{"fe", "be", "db"} {
+		mt := store.NewManifestTarget(model.Manifest{Name: name})
+		state.UpsertManifestTarget(mt)
+	}
+
+	// Nothing has built yet, so everything is waiting.
+	assert.Equal(t, 3, buildQueueDepth(*state))
+
+	for _, mt := range state.ManifestTargets {
+		mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	}
+	assert.Equal(t, 0, buildQueueDepth(*state))
+
+	state.TriggerQueue = []model.ManifestName{"fe"}
+	state.ManifestTargets["be"].State.PendingManifestChange = time.Now().Add(time.Second)
+	assert.Equal(t, 2, buildQueueDepth(*state))
+
+	state.CurrentlyBuilding["fe"] = true
+	assert.Equal(t, 1, buildQueueDepth(*state))
+
+	state.UpdatesPaused = true
+	assert.Equal(t, 0, buildQueueDepth(*state))
+}
+
+func buildCount(t *testing.T, name string, updateType model.UpdateType, result string) uint64 {
+	h, err := metrics.BuildDuration.GetMetricWithLabelValues(name, string(updateType), result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m dto.Metric
+	err = h.(interface{ Write(*dto.Metric) error }).Write(&m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
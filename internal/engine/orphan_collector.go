@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// When Tilt starts, looks for objects that an earlier run of this Tiltfile
+// deployed, for resources that the Tiltfile doesn't have anymore (e.g., because
+// the user renamed them). Otherwise, they'd keep running the old code forever.
+//
+// Tells the user about them, and deletes them if the user asks.
+type OrphanCollector struct {
+	clients *k8s.ClientRegistry
+
+	checked bool
+	orphans map[k8s.Connection][]k8s.K8sEntity
+}
+
+func NewOrphanCollector(clients *k8s.ClientRegistry) *OrphanCollector {
+	return &OrphanCollector{
+		clients: clients,
+		orphans: make(map[k8s.Connection][]k8s.K8sEntity),
+	}
+}
+
+type orphanQuery struct {
+	project   string
+	labelKeys []string
+	manifests map[string]bool
+	conns     []k8s.Connection
+
+	// The targets that deploy with each connection, so that we only
+	// look in the namespaces they deploy to.
+	targets map[k8s.Connection][]model.K8sTarget
+}
+
+// What to look for, once the Tiltfile has loaded.
+func (c *OrphanCollector) query(st store.RStore) (orphanQuery, bool) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	if state.LastTiltfileBuild.Empty() || state.LastTiltfileBuild.Error != nil {
+		return orphanQuery{}, false
+	}
+
+	q := orphanQuery{
+		labelKeys: []string{k8s.ManifestNameLabel},
+		manifests: make(map[string]bool),
+		targets:   make(map[k8s.Connection][]model.K8sTarget),
+	}
+
+	// Under `tilt up <names>`, the resources that the user didn't name are still
+	// in the Tiltfile, so their objects aren't orphans.
+	for _, name := range state.TiltfileManifestNames {
+		q.manifests[name.String()] = true
+	}
+
+	seenKeys := map[string]bool{k8s.ManifestNameLabel: true}
+	for _, m := range state.Manifests() {
+		q.manifests[m.Name.String()] = true
+		if !m.IsK8s() {
+			continue
+		}
+
+		kTarget := m.K8sTarget()
+		if kTarget.Project != "" {
+			q.project = kTarget.Project
+		}
+
+		key := k8s.ManifestLabelKey(kTarget)
+		if !seenKeys[key] {
+			seenKeys[key] = true
+			q.labelKeys = append(q.labelKeys, key)
+		}
+
+		conn := c.clients.Normalize(k8s.ConnectionForTarget(kTarget))
+		if _, ok := q.targets[conn]; !ok {
+			q.conns = append(q.conns, conn)
+		}
+		q.targets[conn] = append(q.targets[conn], kTarget)
+	}
+	return q, true
+}
+
+func (c *OrphanCollector) OnChange(ctx context.Context, st store.RStore) {
+	if !c.checked {
+		q, ok := c.query(st)
+		if !ok {
+			return
+		}
+		c.checked = true
+		c.collect(ctx, q)
+	}
+
+	state := st.RLockState()
+	deleteRequested := state.DeleteOrphansRequested
+	st.RUnlockState()
+
+	if deleteRequested {
+		c.deleteOrphans(ctx)
+		st.Dispatch(OrphansDeletedAction{})
+	}
+}
+
+func (c *OrphanCollector) collect(ctx context.Context, q orphanQuery) {
+	if q.project == "" {
+		return
+	}
+
+	l := logger.Get(ctx)
+	selector := labels.Set{k8s.TiltProjectIDLabel: q.project}.AsSelector()
+	var names []string
+	for _, conn := range q.conns {
+		kCli, err := c.clients.ClientFor(ctx, conn)
+		if err != nil {
+			l.Debugf("Error getting client for %s: %v", conn, err)
+			continue
+		}
+
+		var entities []k8s.K8sEntity
+		for _, ns := range connNamespaces(kCli, q.targets[conn]) {
+			found, err := kCli.ListObjects(ctx, ns, selector)
+			if err != nil {
+				l.Debugf("Error looking for objects from removed resources in namespace %s: %v", ns, err)
+				continue
+			}
+			entities = append(entities, found...)
+		}
+
+		orphans := k8s.Orphans(entities, q.labelKeys, q.manifests)
+		if len(orphans) == 0 {
+			continue
+		}
+		c.orphans[conn] = orphans
+		for _, e := range orphans {
+			names = append(names, k8s.DisplayName(e))
+		}
+	}
+
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	l.Infof("These objects are from resources that aren't in your Tiltfile anymore:\n  %s\n"+
+		"Press d to delete them.", strings.Join(names, "\n  "))
+}
+
+// The namespaces that the targets deploy to, in a stable order.
+func connNamespaces(kCli k8s.Client, targets []model.K8sTarget) []k8s.Namespace {
+	seen := make(map[k8s.Namespace]bool)
+	var result []k8s.Namespace
+	for _, kTarget := range targets {
+		for _, ns := range k8s.TargetNamespaces(kTarget, kCli.ConfigNamespace()) {
+			if !seen[ns] {
+				seen[ns] = true
+				result = append(result, ns)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+func (c *OrphanCollector) deleteOrphans(ctx context.Context) {
+	l := logger.Get(ctx)
+	if len(c.orphans) == 0 {
+		l.Infof("No objects from removed resources to delete")
+		return
+	}
+
+	for conn, orphans := range c.orphans {
+		kCli, err := c.clients.ClientFor(ctx, conn)
+		if err != nil {
+			l.Infof("Error getting client for %s: %v", conn, err)
+			continue
+		}
+
+		var firstErr error
+		for _, group := range k8s.DeletionGroups(orphans) {
+			err := kCli.Delete(ctx, group)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if firstErr != nil {
+			l.Infof("Error deleting objects from removed resources: %v", firstErr)
+			continue
+		}
+		delete(c.orphans, conn)
+		for _, e := range orphans {
+			l.Infof("Deleted %s", k8s.DisplayName(e))
+		}
+	}
+}
+
+var _ store.Subscriber = &OrphanCollector{}
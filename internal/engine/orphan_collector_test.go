@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+const testProject = "0123456789abcdef"
+
+func TestOrphanCollectorDeletesOnRequest(t *testing.T) {
+	f := newOrphanCollectorFixture(t)
+	defer f.TearDown()
+
+	f.kCli.Objects = []k8s.K8sEntity{
+		f.deployedEntity(testyaml.SanchoYAML, "sancho", testProject),
+		f.deployedEntity(testyaml.SanchoTwinYAML, "old-twin", testProject),
+		f.deployedEntity(testyaml.SnackYaml, "old-snack", "some-other-tiltfile"),
+	}
+
+	f.c.OnChange(f.ctx, f.st)
+	assert.Contains(t, f.out.String(), "These objects are from resources that aren't in your Tiltfile anymore:\n  Deployment sancho-ns/sancho-twin\n")
+	assert.Empty(t, f.kCli.DeleteCalls)
+	assert.Empty(t, f.st.Actions)
+
+	f.state.DeleteOrphansRequested = true
+	f.st.SetState(*f.state)
+	f.c.OnChange(f.ctx, f.st)
+
+	if assert.Equal(t, 1, len(f.kCli.DeleteCalls)) {
+		assert.Equal(t, "sancho-twin", f.kCli.DeleteCalls[0][0].Name())
+	}
+	assert.Equal(t, []store.Action{OrphansDeletedAction{}}, f.st.Actions)
+	assert.Contains(t, f.out.String(), "Deleted Deployment sancho-ns/sancho-twin")
+}
+
+func TestOrphanCollectorOnlyLooksInDeployedNamespaces(t *testing.T) {
+	f := newOrphanCollectorFixture(t)
+	defer f.TearDown()
+
+	f.kCli.Objects = []k8s.K8sEntity{
+		f.deployedEntity(testyaml.DoggosDeploymentYaml, "old-doggos", testProject),
+	}
+
+	f.c.OnChange(f.ctx, f.st)
+	assert.Equal(t, []k8s.Namespace{"sancho-ns"}, f.kCli.ListObjectsNamespaces)
+	assert.NotContains(t, f.out.String(), "doggos")
+}
+
+func TestOrphanCollectorKeepsResourcesNotNamedOnCommandLine(t *testing.T) {
+	f := newOrphanCollectorFixture(t)
+	defer f.TearDown()
+
+	// `tilt up sancho`, with a Tiltfile that also has sancho-twin.
+	f.state.TiltfileManifestNames = []model.ManifestName{"sancho", "sancho-twin"}
+	f.st.SetState(*f.state)
+	f.kCli.Objects = []k8s.K8sEntity{
+		f.deployedEntity(testyaml.SanchoTwinYAML, "sancho-twin", testProject),
+	}
+
+	f.c.OnChange(f.ctx, f.st)
+	assert.NotContains(t, f.out.String(), "sancho-twin")
+}
+
+func TestOrphanCollectorWaitsForTiltfile(t *testing.T) {
+	f := newOrphanCollectorFixture(t)
+	defer f.TearDown()
+
+	f.state.LastTiltfileBuild = model.BuildRecord{}
+	f.st.SetState(*f.state)
+	f.kCli.Objects = []k8s.K8sEntity{
+		f.deployedEntity(testyaml.DoggosDeploymentYaml, "old-doggos", testProject),
+	}
+
+	f.c.OnChange(f.ctx, f.st)
+	assert.False(t, f.c.checked)
+	assert.NotContains(t, f.out.String(), "doggos")
+}
+
+type orphanCollectorFixture struct {
+	*tempdir.TempDirFixture
+	t     *testing.T
+	ctx   context.Context
+	out   *bytes.Buffer
+	c     *OrphanCollector
+	st    *store.TestingStore
+	kCli  *k8s.FakeK8sClient
+	state *store.EngineState
+}
+
+func newOrphanCollectorFixture(t *testing.T) *orphanCollectorFixture {
+	f := tempdir.NewTempDirFixture(t)
+	kCli := k8s.NewFakeK8sClient()
+	out := &bytes.Buffer{}
+	ctx := output.ForkedCtxForTest(out)
+
+	state := store.NewState()
+	m := NewSanchoDockerBuildManifest(f)
+	m = m.WithDeployTarget(m.K8sTarget().WithProject(testProject))
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	state.LastTiltfileBuild = model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()}
+
+	st := store.NewTestingStore()
+	st.SetState(*state)
+
+	return &orphanCollectorFixture{
+		TempDirFixture: f,
+		t:              t,
+		ctx:            ctx,
+		out:            out,
+		c:              NewOrphanCollector(k8s.NewClientRegistryForTests(kCli)),
+		st:             st,
+		kCli:           kCli,
+		state:          state,
+	}
+}
+
+// An object that the given manifest deployed, from the given Tiltfile.
+func (f *orphanCollectorFixture) deployedEntity(yaml string, manifest string, project string) k8s.K8sEntity {
+	entities, err := k8s.ParseYAMLFromString(yaml)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	e, err := k8s.InjectLabels(entities[0], []model.LabelPair{
+		{Key: k8s.ManifestNameLabel, Value: manifest},
+		k8s.TiltProjectLabel(project),
+	})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return e
+}
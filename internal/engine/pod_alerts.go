@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+)
+
+// Explains the common ways that a pod can fail, so that users
+// don't have to go to `kubectl describe` to find out why their pod is red.
+//
+// Skips the given sidecars, which aren't the user's to fix.
+func podStatusAlerts(pod *v1.Pod, sidecars map[string]bool) []string {
+	var alerts []string
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodScheduled && cond.Status == v1.ConditionFalse && cond.Reason == v1.PodReasonUnschedulable {
+			alerts = append(alerts, fmt.Sprintf("Pod can't be scheduled: %s", cond.Message))
+		}
+	}
+
+	statuses := append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, cStatus := range statuses {
+		if sidecars[cStatus.Name] {
+			continue
+		}
+		alert := containerStatusAlert(pod, cStatus)
+		if alert != "" {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts
+}
+
+func containerStatusAlert(pod *v1.Pod, cStatus v1.ContainerStatus) string {
+	name := cStatus.Name
+
+	if terminated := cStatus.State.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+		return oomKilledAlert(pod, name)
+	}
+
+	waiting := cStatus.State.Waiting
+	if waiting == nil {
+		return ""
+	}
+
+	switch waiting.Reason {
+	case "CrashLoopBackOff":
+		last := cStatus.LastTerminationState.Terminated
+		if last != nil && last.Reason == "OOMKilled" {
+			return oomKilledAlert(pod, name)
+		}
+		if last != nil {
+			msg := fmt.Sprintf("Container %q keeps crashing (last exit code: %d)", name, last.ExitCode)
+			if last.Message != "" {
+				msg = fmt.Sprintf("%s: %s", msg, last.Message)
+			}
+			return msg
+		}
+		return fmt.Sprintf("Container %q keeps crashing", name)
+
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+		msg := fmt.Sprintf("Container %q can't pull image %q", name, cStatus.Image)
+		if waiting.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, waiting.Message)
+		}
+		return msg + "\nCheck that the image exists, and that the cluster can authenticate to its registry."
+
+	case "CreateContainerConfigError":
+		return fmt.Sprintf("Container %q can't be created: %s", name, waiting.Message)
+	}
+
+	return ""
+}
+
+func oomKilledAlert(pod *v1.Pod, cName string) string {
+	msg := fmt.Sprintf("Container %q was killed for running out of memory (OOMKilled)", cName)
+	c := k8s.ContainerSpecOf(pod, v1.ContainerStatus{Name: cName})
+	if limit, ok := c.Resources.Limits[v1.ResourceMemory]; ok {
+		msg = fmt.Sprintf("%s. Its memory limit is %s", msg, limit.String())
+	}
+	return msg
+}
+
+// Events about a pod that explain why it's stuck.
+//
+// FailedScheduling isn't here, because the PodScheduled condition has the same message.
+var podEventAlertReasons = map[string]string{
+	"FailedMount":        "Pod can't mount a volume",
+	"FailedAttachVolume": "Pod can't attach a volume",
+}
+
+// Returns a human-readable alert for a k8s event, or the empty string
+// if the event doesn't explain a failure.
+func podEventAlert(e *v1.Event) string {
+	if e.Type != v1.EventTypeWarning || e.InvolvedObject.Kind != "Pod" {
+		return ""
+	}
+
+	prefix, ok := podEventAlertReasons[e.Reason]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s", prefix, e.Message)
+}
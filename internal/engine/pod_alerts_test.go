@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPodStatusAlertsCrashLoop(t *testing.T) {
+	pod := alertPod(v1.ContainerStatus{
+		Name:  "app",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: v1.ContainerState{
+			Terminated: &v1.ContainerStateTerminated{ExitCode: 2, Reason: "Error", Message: "config.yaml not found"},
+		},
+	})
+	assert.Equal(t, []string{`Container "app" keeps crashing (last exit code: 2): config.yaml not found`}, podStatusAlerts(pod, nil))
+}
+
+func TestPodStatusAlertsOOMKilled(t *testing.T) {
+	pod := alertPod(v1.ContainerStatus{
+		Name:  "app",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: v1.ContainerState{
+			Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+		},
+	})
+	pod.Spec.Containers = []v1.Container{
+		{
+			Name: "app",
+			Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("128Mi")},
+			},
+		},
+	}
+	assert.Equal(t,
+		[]string{`Container "app" was killed for running out of memory (OOMKilled). Its memory limit is 128Mi`},
+		podStatusAlerts(pod, nil))
+}
+
+func TestPodStatusAlertsImagePull(t *testing.T) {
+	pod := alertPod(v1.ContainerStatus{
+		Name:  "app",
+		Image: "gcr.io/foo:tilt-123",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{
+			Reason:  "ImagePullBackOff",
+			Message: `Back-off pulling image "gcr.io/foo:tilt-123"`,
+		}},
+	})
+	alerts := podStatusAlerts(pod, nil)
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Contains(t, alerts[0], `Container "app" can't pull image "gcr.io/foo:tilt-123": Back-off pulling image`)
+		assert.Contains(t, alerts[0], "authenticate to its registry")
+	}
+}
+
+func TestPodStatusAlertsUnschedulable(t *testing.T) {
+	pod := alertPod()
+	pod.Status.Conditions = []v1.PodCondition{
+		{
+			Type:    v1.PodScheduled,
+			Status:  v1.ConditionFalse,
+			Reason:  v1.PodReasonUnschedulable,
+			Message: "0/1 nodes are available: 1 Insufficient memory.",
+		},
+	}
+	assert.Equal(t, []string{"Pod can't be scheduled: 0/1 nodes are available: 1 Insufficient memory."}, podStatusAlerts(pod, nil))
+}
+
+func TestPodStatusAlertsHealthy(t *testing.T) {
+	pod := alertPod(v1.ContainerStatus{
+		Name:  "app",
+		State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+	})
+	assert.Empty(t, podStatusAlerts(pod, nil))
+}
+
+func TestPodStatusAlertsSkipsSidecars(t *testing.T) {
+	pod := alertPod(
+		v1.ContainerStatus{
+			Name:  "app",
+			State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+		},
+		v1.ContainerStatus{
+			Name:  "istio-proxy",
+			State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		},
+	)
+	assert.Empty(t, podStatusAlerts(pod, map[string]bool{"istio-proxy": true}))
+}
+
+func TestPodEventAlert(t *testing.T) {
+	e := &v1.Event{
+		Type:           v1.EventTypeWarning,
+		Reason:         "FailedMount",
+		Message:        `MountVolume.SetUp failed for volume "config" : configmap "app-config" not found`,
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "my-pod"},
+	}
+	assert.Equal(t, `Pod can't mount a volume: MountVolume.SetUp failed for volume "config" : configmap "app-config" not found`, podEventAlert(e))
+
+	e.Type = v1.EventTypeNormal
+	assert.Equal(t, "", podEventAlert(e))
+
+	e.Type = v1.EventTypeWarning
+	e.Reason = "BackOff"
+	assert.Equal(t, "", podEventAlert(e))
+}
+
+func alertPod(statuses ...v1.ContainerStatus) *v1.Pod {
+	return &v1.Pod{
+		Status: v1.PodStatus{
+			ContainerStatuses: statuses,
+		},
+	}
+}
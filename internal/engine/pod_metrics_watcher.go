@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// metrics-server scrapes the kubelets about this often, so polling more often doesn't get us anything new.
+const podMetricsPollInterval = 15 * time.Second
+
+// How long we wait to ask a cluster without metrics-server again. Doubles
+// each time it's still missing, up to podMetricsMaxBackoff.
+const podMetricsInitialBackoff = time.Minute
+const podMetricsMaxBackoff = 10 * time.Minute
+
+// Polls metrics-server for how much CPU and memory the pods that Tilt is
+// watching are using.
+//
+// If a cluster doesn't have metrics-server, we ask it less and less often,
+// in case it's still starting up or someone installs it.
+type PodMetricsWatcher struct {
+	clients  *k8s.ClientRegistry
+	interval time.Duration
+	timeNow  func() time.Time
+
+	watching    bool
+	unavailable map[k8s.Connection]metricsBackoff
+}
+
+type metricsBackoff struct {
+	retryAt time.Time
+	backoff time.Duration
+}
+
+func NewPodMetricsWatcher(clients *k8s.ClientRegistry) *PodMetricsWatcher {
+	return &PodMetricsWatcher{
+		clients:     clients,
+		interval:    podMetricsPollInterval,
+		timeNow:     time.Now,
+		unavailable: make(map[k8s.Connection]metricsBackoff),
+	}
+}
+
+func (w *PodMetricsWatcher) needsWatch(st store.RStore) bool {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	atLeastOneK8S := false
+	for _, m := range state.Manifests() {
+		if m.IsK8s() {
+			atLeastOneK8S = true
+		}
+	}
+	return atLeastOneK8S && state.WatchFiles && !w.watching
+}
+
+func (w *PodMetricsWatcher) OnChange(ctx context.Context, st store.RStore) {
+	if !w.needsWatch(st) {
+		return
+	}
+	w.watching = true
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.poll(ctx, st)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+type podMetricsQuery struct {
+	conn k8s.Connection
+	ns   k8s.Namespace
+}
+
+// The connections and namespaces of the pods that we're watching.
+func (w *PodMetricsWatcher) queries(st store.RStore) []podMetricsQuery {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	seen := make(map[podMetricsQuery]bool)
+	var result []podMetricsQuery
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsK8s() {
+			continue
+		}
+		conn := w.clients.Normalize(k8s.ConnectionForTarget(mt.Manifest.K8sTarget()))
+		if w.backingOff(conn) {
+			continue
+		}
+		for _, pod := range mt.State.PodSet.PodList() {
+			q := podMetricsQuery{conn: conn, ns: pod.Namespace}
+			if pod.PodID == "" || seen[q] {
+				continue
+			}
+			seen[q] = true
+			result = append(result, q)
+		}
+	}
+	return result
+}
+
+func (w *PodMetricsWatcher) poll(ctx context.Context, st store.RStore) {
+	var metrics []k8s.PodMetrics
+	for _, q := range w.queries(st) {
+		if w.backingOff(q.conn) {
+			continue
+		}
+
+		kCli, err := w.clients.ClientFor(ctx, q.conn)
+		if err != nil {
+			logger.Get(ctx).Debugf("Error getting client for %s: %v", q.conn, err)
+			continue
+		}
+
+		result, err := kCli.PodMetrics(ctx, q.ns)
+		if err == k8s.ErrMetricsUnavailable {
+			b := w.backOff(q.conn)
+			logger.Get(ctx).Debugf("Not showing CPU and memory use for %s: %v. Trying again in %s", q.conn, err, b.backoff)
+			continue
+		} else if err != nil {
+			logger.Get(ctx).Debugf("Error fetching pod metrics: %v", err)
+			continue
+		}
+		delete(w.unavailable, q.conn)
+		metrics = append(metrics, result...)
+	}
+
+	if len(metrics) > 0 {
+		st.Dispatch(PodMetricsAction{Metrics: metrics})
+	}
+}
+
+func (w *PodMetricsWatcher) backingOff(conn k8s.Connection) bool {
+	b, ok := w.unavailable[conn]
+	return ok && w.timeNow().Before(b.retryAt)
+}
+
+func (w *PodMetricsWatcher) backOff(conn k8s.Connection) metricsBackoff {
+	b, ok := w.unavailable[conn]
+	if !ok {
+		b.backoff = podMetricsInitialBackoff
+	} else if b.backoff*2 <= podMetricsMaxBackoff {
+		b.backoff *= 2
+	} else {
+		b.backoff = podMetricsMaxBackoff
+	}
+	b.retryAt = w.timeNow().Add(b.backoff)
+	w.unavailable[conn] = b
+	return b
+}
+
+var _ store.Subscriber = &PodMetricsWatcher{}
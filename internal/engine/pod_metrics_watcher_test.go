@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestPodMetricsWatcherPoll(t *testing.T) {
+	f := newPodMetricsWatcherFixture(t)
+	defer f.TearDown()
+
+	metrics := k8s.PodMetrics{
+		PodID:     "sancho-pod",
+		Namespace: "default",
+		Containers: []k8s.ContainerMetrics{
+			{Name: "sancho", CPU: resource.MustParse("250m"), Memory: resource.MustParse("64Mi")},
+		},
+	}
+	f.kCli.Metrics = []k8s.PodMetrics{
+		metrics,
+		{PodID: "other-pod", Namespace: "other"},
+	}
+
+	f.w.poll(output.CtxForTest(), f.st)
+	if assert.Equal(t, 1, len(f.st.Actions)) {
+		assert.Equal(t, PodMetricsAction{Metrics: []k8s.PodMetrics{metrics}}, f.st.Actions[0])
+	}
+}
+
+func TestPodMetricsWatcherBacksOffWhenUnavailable(t *testing.T) {
+	f := newPodMetricsWatcherFixture(t)
+	defer f.TearDown()
+
+	now := time.Now()
+	f.w.timeNow = func() time.Time { return now }
+
+	f.kCli.MetricsErr = k8s.ErrMetricsUnavailable
+	f.w.poll(output.CtxForTest(), f.st)
+	assert.Empty(t, f.st.Actions)
+	assert.Empty(t, f.w.queries(f.st))
+
+	// Still missing after the first backoff, so we wait twice as long.
+	now = now.Add(podMetricsInitialBackoff)
+	assert.Equal(t, 1, len(f.w.queries(f.st)))
+	f.w.poll(output.CtxForTest(), f.st)
+	now = now.Add(podMetricsInitialBackoff)
+	assert.Empty(t, f.w.queries(f.st))
+
+	// Once metrics-server shows up, we poll it as usual.
+	now = now.Add(podMetricsInitialBackoff)
+	f.kCli.MetricsErr = nil
+	f.kCli.Metrics = []k8s.PodMetrics{{PodID: "sancho-pod", Namespace: "default"}}
+	f.w.poll(output.CtxForTest(), f.st)
+	assert.Equal(t, 1, len(f.st.Actions))
+	assert.Empty(t, f.w.unavailable)
+}
+
+func TestHandlePodMetricsAction(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	state := store.NewState()
+	m := NewSanchoDockerBuildManifest(f)
+	mt := store.NewManifestTarget(m)
+	mt.State.PodSet = store.NewPodSet(store.Pod{PodID: "sancho-pod", Namespace: "default"})
+	state.ManifestTargets[m.Name] = mt
+
+	containers := []k8s.ContainerMetrics{
+		{Name: "sancho", CPU: resource.MustParse("250m"), Memory: resource.MustParse("64Mi")},
+	}
+	handlePodMetricsAction(state, PodMetricsAction{Metrics: []k8s.PodMetrics{
+		{PodID: "sancho-pod", Namespace: "default", Containers: containers},
+		{PodID: "sancho-pod", Namespace: "other"},
+	}})
+	assert.Equal(t, containers, mt.State.PodSet.Pods["sancho-pod"].ContainerMetrics)
+}
+
+type podMetricsWatcherFixture struct {
+	*tempdir.TempDirFixture
+	w    *PodMetricsWatcher
+	st   *store.TestingStore
+	kCli *k8s.FakeK8sClient
+}
+
+func newPodMetricsWatcherFixture(t *testing.T) *podMetricsWatcherFixture {
+	f := tempdir.NewTempDirFixture(t)
+	kCli := k8s.NewFakeK8sClient()
+
+	state := store.NewState()
+	m := NewSanchoDockerBuildManifest(f)
+	mt := store.NewManifestTarget(m)
+	mt.State.PodSet = store.NewPodSet(store.Pod{PodID: "sancho-pod", Namespace: "default"})
+	state.ManifestTargets[m.Name] = mt
+	state.ManifestDefinitionOrder = []model.ManifestName{m.Name}
+
+	st := store.NewTestingStore()
+	st.SetState(*state)
+
+	return &podMetricsWatcherFixture{
+		TempDirFixture: f,
+		w:              NewPodMetricsWatcher(k8s.NewClientRegistryForTests(kCli)),
+		st:             st,
+		kCli:           kCli,
+	}
+}
@@ -8,27 +8,34 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/pkg/errors"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/store"
 
 	"github.com/windmilleng/tilt/internal/k8s"
 )
 
 type PodWatcher struct {
-	kCli    k8s.Client
+	clients *k8s.ClientRegistry
 	watches []PodWatch
 }
 
-func NewPodWatcher(kCli k8s.Client) *PodWatcher {
+func NewPodWatcher(clients *k8s.ClientRegistry) *PodWatcher {
 	return &PodWatcher{
-		kCli: kCli,
+		clients: clients,
 	}
 }
 
 type PodWatch struct {
+	conn   k8s.Connection
 	labels labels.Selector
 	cancel context.CancelFunc
 }
 
+func (pw PodWatch) matches(other PodWatch) bool {
+	return pw.conn == other.conn && k8s.SelectorEqual(pw.labels, other.labels)
+}
+
 // returns all elements of `a` that are not in `b`
 func subtract(a, b []PodWatch) []PodWatch {
 	var ret []PodWatch
@@ -36,7 +43,7 @@ func subtract(a, b []PodWatch) []PodWatch {
 	for _, pwa := range a {
 		inB := false
 		for _, pwb := range b {
-			if k8s.SelectorEqual(pwa.labels, pwb.labels) {
+			if pwa.matches(pwb) {
 				inB = true
 				break
 			}
@@ -52,20 +59,27 @@ func (w *PodWatcher) diff(ctx context.Context, st store.RStore) (setup []PodWatc
 	state := st.RLockState()
 	defer st.RUnlockState()
 
-	atLeastOneK8S := false
+	// Every connection that we deploy with needs a watch for the pods that we deployed.
+	var conns []k8s.Connection
+	seenConns := map[k8s.Connection]bool{}
 	var neededWatches []PodWatch
 	for _, m := range state.Manifests() {
 		if m.IsK8s() {
-			atLeastOneK8S = true
+			conn := w.clients.Normalize(k8s.ConnectionForTarget(m.K8sTarget()))
+			if !seenConns[conn] {
+				seenConns[conn] = true
+				conns = append(conns, conn)
+			}
+
 			for _, ls := range m.K8sTarget().ExtraPodSelectors {
 				if !ls.Empty() {
-					neededWatches = append(neededWatches, PodWatch{labels: ls})
+					neededWatches = append(neededWatches, PodWatch{conn: conn, labels: ls})
 				}
 			}
 		}
 	}
-	if atLeastOneK8S {
-		neededWatches = append(neededWatches, PodWatch{labels: k8s.TiltRunSelector()})
+	for _, conn := range conns {
+		neededWatches = append(neededWatches, PodWatch{conn: conn, labels: k8s.TiltRunSelector()})
 	}
 
 	return subtract(neededWatches, w.watches), subtract(w.watches, neededWatches)
@@ -76,9 +90,18 @@ func (w *PodWatcher) OnChange(ctx context.Context, st store.RStore) {
 
 	for _, pw := range setup {
 		ctx, cancel := context.WithCancel(ctx)
-		pw = PodWatch{labels: pw.labels, cancel: cancel}
+		pw = PodWatch{conn: pw.conn, labels: pw.labels, cancel: cancel}
 		w.watches = append(w.watches, pw)
-		ch, err := w.kCli.WatchPods(ctx, pw.labels)
+
+		kCli, err := w.clients.ClientFor(ctx, pw.conn)
+		if err != nil {
+			// The deploy to this context reports the same error,
+			// so there's no need to stop the world.
+			logger.Get(ctx).Infof("Error watching pods: %v", err)
+			continue
+		}
+
+		ch, err := kCli.WatchPods(ctx, pw.labels)
 		if err != nil {
 			err = errors.Wrap(err, "Error watching pods. Are you connected to kubernetes?\n")
 			st.Dispatch(NewErrorAction(err))
@@ -97,7 +120,7 @@ func (w *PodWatcher) removeWatch(toRemove PodWatch) {
 	oldWatches := append([]PodWatch{}, w.watches...)
 	w.watches = nil
 	for _, e := range oldWatches {
-		if !k8s.SelectorEqual(e.labels, toRemove.labels) {
+		if !e.matches(toRemove) {
 			w.watches = append(w.watches, e)
 		}
 	}
@@ -118,8 +141,8 @@ func dispatchPodChangesLoop(ctx context.Context, ch <-chan *v1.Pod, st store.RSt
 }
 
 // copied from https://github.com/kubernetes/kubernetes/blob/aedeccda9562b9effe026bb02c8d3c539fc7bb77/pkg/kubectl/resource_printer.go#L692-L764
-// to match the status column of `kubectl get pods`
-func podStatusToString(pod v1.Pod) string {
+// to match the status column of `kubectl get pods`, except that we skip the given sidecars.
+func podStatusToString(pod v1.Pod, sidecars map[string]bool) string {
 	reason := string(pod.Status.Phase)
 	if pod.Status.Reason != "" {
 		reason = pod.Status.Reason
@@ -128,6 +151,9 @@ func podStatusToString(pod v1.Pod) string {
 	initializing := false
 	for i := range pod.Status.InitContainerStatuses {
 		container := pod.Status.InitContainerStatuses[i]
+		if sidecars[container.Name] {
+			continue
+		}
 		switch {
 		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
 			continue
@@ -155,6 +181,9 @@ func podStatusToString(pod v1.Pod) string {
 	if !initializing {
 		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
 			container := pod.Status.ContainerStatuses[i]
+			if sidecars[container.Name] {
+				continue
+			}
 
 			if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
 				reason = container.State.Waiting.Reason
@@ -172,3 +201,21 @@ func podStatusToString(pod v1.Pod) string {
 
 	return reason
 }
+
+// The connections that the k8s resources deploy with, normalized, in the order
+// that the resources are defined.
+func k8sConnections(clients *k8s.ClientRegistry, manifests []model.Manifest) []k8s.Connection {
+	var result []k8s.Connection
+	seen := make(map[k8s.Connection]bool)
+	for _, m := range manifests {
+		if !m.IsK8s() {
+			continue
+		}
+		conn := clients.Normalize(k8s.ConnectionForTarget(m.K8sTarget()))
+		if !seen[conn] {
+			seen[conn] = true
+			result = append(result, conn)
+		}
+	}
+	return result
+}
@@ -95,6 +95,27 @@ func TestPodWatchHandleSelectorChange(t *testing.T) {
 
 }
 
+func TestPodWatchKubeContext(t *testing.T) {
+	f := newPWFixture(t)
+	defer f.TearDown()
+
+	remote := k8s.NewFakeK8sClient()
+	f.pw.clients.SetClientForTests("remote", remote)
+
+	ls := k8s.TiltRunSelector()
+	f.addManifestWithSelectors("local")
+	f.addManifestWithKubeContext("server", "remote")
+
+	f.pw.OnChange(f.ctx, f.store)
+
+	f.assertWatchedSelectors(ls)
+	assert.Equal(t, []labels.Selector{ls}, remote.WatchedSelectors())
+
+	p := podNamed("remote-pod")
+	remote.EmitPod(ls, p)
+	f.assertObservedPods(p)
+}
+
 func podNamed(name string) *corev1.Pod {
 	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
 }
@@ -110,6 +131,18 @@ func (f *pwFixture) addManifestWithSelectors(manifestName string, ls ...labels.S
 	f.store.UnlockMutableState()
 }
 
+func (f *pwFixture) addManifestWithKubeContext(manifestName string, kubeContext string) {
+	state := f.store.LockMutableStateForTesting()
+	state.WatchFiles = true
+	mt, err := newManifestTargetWithSelectors(model.Manifest{Name: model.ManifestName(manifestName)}, nil)
+	if err != nil {
+		f.t.Fatalf("error creating manifest target: %+v", err)
+	}
+	mt.Manifest = mt.Manifest.WithDeployTarget(mt.Manifest.K8sTarget().WithKubeContext(kubeContext))
+	state.UpsertManifestTarget(mt)
+	f.store.UnlockMutableState()
+}
+
 func (f *pwFixture) removeManifest(manifestName string) {
 	mn := model.ManifestName(manifestName)
 	state := f.store.LockMutableStateForTesting()
@@ -144,13 +177,14 @@ func (pw *pwFixture) reducer(ctx context.Context, state *store.EngineState, acti
 
 func newPWFixture(t *testing.T) *pwFixture {
 	kClient := k8s.NewFakeK8sClient()
+	clients := k8s.NewClientRegistryForTests(kClient)
 
 	ctx := output.CtxForTest()
 	ctx, cancel := context.WithCancel(ctx)
 
 	ret := &pwFixture{
 		kClient: kClient,
-		pw:      NewPodWatcher(kClient),
+		pw:      NewPodWatcher(clients),
 		ctx:     ctx,
 		cancel:  cancel,
 		t:       t,
@@ -18,14 +18,14 @@ import (
 
 // Collects logs from deployed containers.
 type PodLogManager struct {
-	kClient k8s.Client
+	clients *k8s.ClientRegistry
 
 	watches map[podLogKey]PodLogWatch
 }
 
-func NewPodLogManager(kClient k8s.Client) *PodLogManager {
+func NewPodLogManager(clients *k8s.ClientRegistry) *PodLogManager {
 	return &PodLogManager{
-		kClient: kClient,
+		clients: clients,
 		watches: make(map[podLogKey]PodLogWatch),
 	}
 }
@@ -49,34 +49,49 @@ func (m *PodLogManager) diff(ctx context.Context, st store.RStore) (setup []PodL
 	}
 
 	stateWatches := make(map[podLogKey]bool)
-	for _, ms := range state.ManifestStates() {
+	for _, mt := range state.EnabledTargets() {
+		ms := mt.State
+		k8sTarget := mt.Manifest.K8sTarget()
 		for _, pod := range ms.PodSet.PodList() {
 			if pod.PodID == "" {
 				continue
 			}
 
-			if pod.ContainerName == "" || pod.ContainerID == "" {
-				continue
-			}
-
-			// Only fetch pod logs if the pod is running.
-			// Otherwise it will reject our connection.
-			if pod.Phase != v1.PodRunning {
-				continue
-			}
-
 			// NOTE(maia): setting up logWatchers using both containerInfos and pod.ContainerName etc.
 			// is a temporary hack. Put this in for backwards compatibility.
 			containerInfos := pod.ContainerInfos
 			if len(containerInfos) == 0 {
+				if pod.ContainerName == "" || pod.ContainerID == "" {
+					continue
+				}
 				containerInfos = []store.ContainerInfo{
 					store.ContainerInfo{ID: pod.ContainerID, Name: pod.ContainerName},
 				}
 			}
-			// if pod has more than one container, we should prefix logs with the container name
-			shouldPrefix := len(containerInfos) > 1
 
+			var streamable []store.ContainerInfo
 			for _, cInfo := range containerInfos {
+				if cInfo.Sidecar && !k8sTarget.ShouldStreamSidecarLogs(cInfo.Name.String()) {
+					continue
+				}
+				if k8sTarget.ShouldStreamLogs(cInfo.Name.String()) {
+					streamable = append(streamable, cInfo)
+				}
+			}
+
+			// if pod has more than one container, we should prefix logs with the container name
+			shouldPrefix := len(streamable) > 1
+
+			for _, cInfo := range streamable {
+				// Only fetch container logs once the container can be running.
+				// Otherwise it will reject our connection.
+				// Init containers run while the pod is still pending.
+				// Pods that ran to completion (e.g., from a Job) keep their logs around.
+				finished := pod.Phase == v1.PodSucceeded || pod.Phase == v1.PodFailed
+				if pod.Phase != v1.PodRunning && !finished && !(cInfo.Init && pod.Phase == v1.PodPending) {
+					continue
+				}
+
 				// Key the log watcher by the container id, so we auto-restart the
 				// watching if the container crashes.
 				key := podLogKey{
@@ -94,6 +109,12 @@ func (m *PodLogManager) diff(ctx context.Context, st store.RStore) (setup []PodL
 						continue
 					}
 
+					if cInfo.Init || finished {
+						// Init containers and finished pods have run to completion.
+						// If they're retried, they get a new ID, so there's nothing left to pick up.
+						continue
+					}
+
 					// The active pod watcher got cancelled somehow,
 					// so we need to create a new one that picks up
 					// where it left off.
@@ -108,6 +129,7 @@ func (m *PodLogManager) diff(ctx context.Context, st store.RStore) (setup []PodL
 					podID:           pod.PodID,
 					cName:           cInfo.Name,
 					namespace:       pod.Namespace,
+					conn:            k8s.ConnectionForTarget(k8sTarget),
 					startWatchTime:  startWatchTime,
 					terminationTime: make(chan time.Time, 1),
 					shouldPrefix:    shouldPrefix,
@@ -151,7 +173,13 @@ func (m *PodLogManager) consumeLogs(watch PodLogWatch, st store.RStore) {
 	containerName := watch.cName
 	ns := watch.namespace
 	startTime := watch.startWatchTime
-	readCloser, err := m.kClient.ContainerLogs(watch.ctx, pID, containerName, ns, startTime)
+	kCli, err := m.clients.ClientFor(watch.ctx, watch.conn)
+	if err != nil {
+		logger.Get(watch.ctx).Infof("Error streaming %s logs: %v", name, err)
+		return
+	}
+
+	readCloser, err := kCli.ContainerLogs(watch.ctx, pID, containerName, ns, startTime)
 	if err != nil {
 		logger.Get(watch.ctx).Infof("Error streaming %s logs: %v", name, err)
 		return
@@ -199,6 +227,7 @@ type PodLogWatch struct {
 	name            model.ManifestName
 	podID           k8s.PodID
 	namespace       k8s.Namespace
+	conn            k8s.Connection
 	cName           container.Name
 	startWatchTime  time.Time
 	terminationTime chan time.Time
@@ -199,6 +199,92 @@ func TestContainerPrefixes(t *testing.T) {
 	f.AssertOutputDoesNotContain(cNameNoPrefix.String())
 }
 
+func TestInitContainerLogsWhilePending(t *testing.T) {
+	f := newPLMFixture(t)
+	defer f.TearDown()
+
+	f.kClient.SetLogsForPodContainer(podID, "migrate", "running migrations")
+	f.kClient.SetLogsForPodContainer(podID, "app", "serving")
+
+	state := f.store.LockMutableStateForTesting()
+	state.WatchFiles = true
+	state.UpsertManifestTarget(newManifestTargetWithPod(
+		model.Manifest{Name: "server"},
+		store.Pod{
+			PodID: podID,
+			Phase: v1.PodPending,
+			ContainerInfos: []store.ContainerInfo{
+				store.ContainerInfo{ID: "cid1", Name: "migrate", Init: true},
+				store.ContainerInfo{ID: "cid2", Name: "app"},
+			},
+		}))
+	f.store.UnlockMutableState()
+
+	f.plm.OnChange(f.ctx, f.store)
+	f.AssertOutputContains("[migrate] running migrations")
+	f.AssertOutputDoesNotContain("serving")
+}
+
+func TestLogsFromCompletedJobPod(t *testing.T) {
+	f := newPLMFixture(t)
+	defer f.TearDown()
+
+	f.kClient.SetLogsForPodContainer(podID, cName, "migrations applied")
+
+	state := f.store.LockMutableStateForTesting()
+	state.WatchFiles = true
+	state.UpsertManifestTarget(newManifestTargetWithPod(
+		model.Manifest{Name: "migrate"},
+		store.Pod{
+			PodID:         podID,
+			Phase:         v1.PodSucceeded,
+			ContainerName: cName,
+			ContainerID:   cID,
+		}))
+	f.store.UnlockMutableState()
+
+	f.plm.OnChange(f.ctx, f.store)
+	f.AssertOutputContains("migrations applied")
+
+	// Once we've read all the logs of a finished pod, don't start watching it again.
+	key := podLogKey{podID: podID, cID: cID}
+	watch := f.plm.watches[key]
+	<-watch.ctx.Done()
+	f.plm.OnChange(f.ctx, f.store)
+	assert.Equal(t, watch.ctx, f.plm.watches[key].ctx)
+}
+
+func TestIgnoredLogContainers(t *testing.T) {
+	f := newPLMFixture(t)
+	defer f.TearDown()
+
+	f.kClient.SetLogsForPodContainer(podID, "app", "hello world!")
+	f.kClient.SetLogsForPodContainer(podID, "istio-proxy", "proxying")
+
+	m := model.Manifest{Name: "server"}.WithDeployTarget(
+		model.K8sTarget{}.WithLogContainers(nil, []string{"istio-proxy"}))
+
+	state := f.store.LockMutableStateForTesting()
+	state.WatchFiles = true
+	state.UpsertManifestTarget(newManifestTargetWithPod(m,
+		store.Pod{
+			PodID: podID,
+			Phase: v1.PodRunning,
+			ContainerInfos: []store.ContainerInfo{
+				store.ContainerInfo{ID: "cid1", Name: "app"},
+				store.ContainerInfo{ID: "cid2", Name: "istio-proxy"},
+			},
+		}))
+	f.store.UnlockMutableState()
+
+	f.plm.OnChange(f.ctx, f.store)
+	f.AssertOutputContains("hello world!")
+	f.AssertOutputDoesNotContain("proxying")
+
+	// Only one container is streamed, so there's no need for a prefix.
+	f.AssertOutputDoesNotContain("[app]")
+}
+
 type plmFixture struct {
 	*tempdir.TempDirFixture
 	ctx     context.Context
@@ -223,7 +309,7 @@ func newPLMFixture(t *testing.T) *plmFixture {
 	}
 
 	st := store.NewStore(store.Reducer(reducer), store.LogActionsFlag(false))
-	plm := NewPodLogManager(kClient)
+	plm := NewPodLogManager(k8s.NewClientRegistryForTests(kClient))
 
 	ctx, cancel := context.WithCancel(context.Background())
 	l := logger.NewLogger(logger.DebugLvl, out)
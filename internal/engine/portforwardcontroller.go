@@ -2,6 +2,8 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/logger"
@@ -11,15 +13,33 @@ import (
 )
 
 type PortForwardController struct {
-	kClient k8s.Client
+	clients *k8s.ClientRegistry
 
-	activeForwards map[k8s.PodID]portForwardEntry
+	activeForwards        map[k8s.PodID]portForwardEntry
+	activeServiceForwards map[serviceForwardKey]portForwardEntry
+
+	// How long to wait before re-establishing a port forward that died.
+	// Doubles on each consecutive failure, up to maxBackoff.
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	// How often to check that a Service forward's pod is still behind the Service.
+	serviceRecheckInterval time.Duration
 }
 
-func NewPortForwardController(kClient k8s.Client) *PortForwardController {
+type serviceForwardKey struct {
+	name    model.ManifestName
+	forward model.PortForward
+}
+
+func NewPortForwardController(clients *k8s.ClientRegistry) *PortForwardController {
 	return &PortForwardController{
-		kClient:        kClient,
-		activeForwards: make(map[k8s.PodID]portForwardEntry),
+		clients:                clients,
+		activeForwards:         make(map[k8s.PodID]portForwardEntry),
+		activeServiceForwards:  make(map[serviceForwardKey]portForwardEntry),
+		initialBackoff:         500 * time.Millisecond,
+		maxBackoff:             10 * time.Second,
+		serviceRecheckInterval: 5 * time.Second,
 	}
 }
 
@@ -32,7 +52,7 @@ func (m *PortForwardController) diff(ctx context.Context, st store.RStore) (toSt
 	statePods := make(map[k8s.PodID]bool, len(state.ManifestTargets))
 
 	// Find all the port-forwards that need to be created.
-	for _, mt := range state.Targets() {
+	for _, mt := range state.EnabledTargets() {
 		ms := mt.State
 		manifest := mt.Manifest
 		pod := ms.MostRecentPod()
@@ -63,6 +83,7 @@ func (m *PortForwardController) diff(ctx context.Context, st store.RStore) (toSt
 			podID:     podID,
 			name:      ms.Name,
 			namespace: pod.Namespace,
+			conn:      k8s.ConnectionForTarget(manifest.K8sTarget()),
 			forwards:  forwards,
 			ctx:       ctx,
 			cancel:    cancel,
@@ -84,6 +105,53 @@ func (m *PortForwardController) diff(ctx context.Context, st store.RStore) (toSt
 		delete(m.activeForwards, key)
 	}
 
+	// Forwards to a Service don't follow any one pod,
+	// so they stay up as long as the resource is deployed.
+	stateServices := make(map[serviceForwardKey]bool)
+	for _, mt := range state.EnabledTargets() {
+		ms := mt.State
+		if ms.LastSuccessfulDeployTime.IsZero() {
+			continue
+		}
+
+		for _, forward := range mt.Manifest.K8sTarget().PortForwards {
+			if forward.Service == "" {
+				continue
+			}
+
+			key := serviceForwardKey{name: ms.Name, forward: forward}
+			stateServices[key] = true
+
+			_, isActive := m.activeServiceForwards[key]
+			if isActive {
+				continue
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			entry := portForwardEntry{
+				name:      ms.Name,
+				namespace: k8s.Namespace(forward.ServiceNamespace),
+				conn:      k8s.ConnectionForTarget(mt.Manifest.K8sTarget()),
+				forwards:  []model.PortForward{forward},
+				ctx:       ctx,
+				cancel:    cancel,
+			}
+
+			toStart = append(toStart, entry)
+			m.activeServiceForwards[key] = entry
+		}
+	}
+
+	for key, value := range m.activeServiceForwards {
+		_, inState := stateServices[key]
+		if inState {
+			continue
+		}
+
+		toShutdown = append(toShutdown, value)
+		delete(m.activeServiceForwards, key)
+	}
+
 	return toStart, toShutdown
 }
 
@@ -94,24 +162,182 @@ func (m *PortForwardController) OnChange(ctx context.Context, st store.RStore) {
 	}
 
 	for _, entry := range toStart {
-		entry := entry
-		ns := entry.namespace
-		podID := entry.podID
 		for _, forward := range entry.forwards {
+			if forward.Service != "" {
+				connect := m.serviceConnector(entry, forward)
+				go func(entry portForwardEntry, forward model.PortForward) {
+					m.keepForwarding(st, entry, forward, connect, connect())
+				}(entry, forward)
+				continue
+			}
+
 			// TODO(nick): Handle the case where DockerForDesktop is handling
 			// the port-forwarding natively already
-			_, closer, err := m.kClient.ForwardPort(ctx, ns, podID, forward.LocalPort, forward.ContainerPort)
-			if err != nil {
-				logger.Get(ctx).Infof("Error port-forwarding %s: %v", entry.name, err)
-				continue
+			connect := m.podConnector(entry, forward)
+			go m.keepForwarding(st, entry, forward, connect, connect())
+		}
+	}
+}
+
+// The result of trying to open a port forward.
+type portForwardAttempt struct {
+	podID k8s.PodID
+	pf    k8s.PortForward
+	err   error
+}
+
+type portForwardConnector func() portForwardAttempt
+
+func (m *PortForwardController) podConnector(entry portForwardEntry, forward model.PortForward) portForwardConnector {
+	return func() portForwardAttempt {
+		kCli, err := m.clients.ClientFor(entry.ctx, entry.conn)
+		if err != nil {
+			return portForwardAttempt{podID: entry.podID, err: err}
+		}
+
+		pf, err := kCli.ForwardPort(entry.ctx, entry.namespace, entry.podID, forward.LocalPort, forward.ContainerPort)
+		return portForwardAttempt{podID: entry.podID, pf: pf, err: err}
+	}
+}
+
+// Connects to a ready pod behind the Service. Sticks with the same pod
+// as long as it stays ready, so that reconnects don't bounce between replicas.
+func (m *PortForwardController) serviceConnector(entry portForwardEntry, forward model.PortForward) portForwardConnector {
+	var current k8s.PodID
+	return func() portForwardAttempt {
+		kCli, err := m.clients.ClientFor(entry.ctx, entry.conn)
+		if err != nil {
+			return portForwardAttempt{podID: current, err: err}
+		}
+
+		eps, err := kCli.ServiceEndpoints(entry.ctx, entry.namespace, forward.Service, forward.ContainerPort)
+		if err != nil {
+			return portForwardAttempt{podID: current, err: err}
+		}
+
+		ep := eps[0]
+		for _, e := range eps {
+			if e.PodID == current {
+				ep = e
 			}
+		}
+		current = ep.PodID
+
+		pf, err := kCli.ForwardPort(entry.ctx, ep.Namespace, ep.PodID, forward.LocalPort, ep.Port)
+		return portForwardAttempt{podID: ep.PodID, pf: pf, err: err}
+	}
+}
+
+// Returns true if the pod is still a ready endpoint of the Service.
+// If we can't tell, assume that it is, and let the connection decide.
+func (m *PortForwardController) stillServing(entry portForwardEntry, forward model.PortForward, podID k8s.PodID) bool {
+	kCli, err := m.clients.ClientFor(entry.ctx, entry.conn)
+	if err != nil {
+		return true
+	}
 
-			go func() {
-				<-entry.ctx.Done()
-				closer()
-			}()
+	eps, err := kCli.ServiceEndpoints(entry.ctx, entry.namespace, forward.Service, forward.ContainerPort)
+	if err != nil {
+		return true
+	}
+	for _, ep := range eps {
+		if ep.PodID == podID {
+			return true
 		}
 	}
+	return false
+}
+
+// Keeps a port forward open until its entry is shut down.
+//
+// The connection to the pod drops whenever its container restarts (e.g., after a crash,
+// or a live update that restarts the container), so when the forward dies, we reconnect.
+//
+// Forwards to a Service also move to another ready pod when their pod leaves the Service's endpoints.
+func (m *PortForwardController) keepForwarding(st store.RStore, entry portForwardEntry, forward model.PortForward, connect portForwardConnector, attempt portForwardAttempt) {
+	l := logger.Get(entry.ctx)
+	backoff := m.initialBackoff
+	failures := 0
+	for {
+		err := attempt.err
+		if err == nil {
+			if failures > 0 {
+				l.Infof("Reconnected port forward %d → %s:%d", forward.LocalPort, entry.name, forward.ContainerPort)
+			}
+			m.dispatchStatus(st, entry, forward, attempt.podID, store.PortForwardConnected)
+			backoff = m.initialBackoff
+			failures = 0
+
+			err = m.waitForDisconnect(entry, forward, attempt)
+			if err == nil {
+				return
+			}
+		}
+
+		if failures == 0 {
+			l.Infof("Error port-forwarding %s: %v. Reconnecting…", entry.name, err)
+		} else {
+			l.Debugf("Error port-forwarding %s: %v", entry.name, err)
+		}
+		failures++
+		m.dispatchStatus(st, entry, forward, attempt.podID, store.PortForwardReconnecting)
+
+		select {
+		case <-entry.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > m.maxBackoff {
+			backoff = m.maxBackoff
+		}
+
+		attempt = connect()
+	}
+}
+
+// Blocks until the port forward dies, and returns why.
+// Returns nil if the entry was shut down.
+func (m *PortForwardController) waitForDisconnect(entry portForwardEntry, forward model.PortForward, attempt portForwardAttempt) error {
+	var recheck <-chan time.Time
+	if forward.Service != "" {
+		ticker := time.NewTicker(m.serviceRecheckInterval)
+		defer ticker.Stop()
+		recheck = ticker.C
+	}
+
+	for {
+		select {
+		case <-entry.ctx.Done():
+			attempt.pf.Close()
+			return nil
+		case err := <-attempt.pf.Done:
+			if entry.ctx.Err() != nil {
+				return nil
+			}
+			if err == nil {
+				err = fmt.Errorf("connection closed")
+			}
+			return err
+		case <-recheck:
+			if !m.stillServing(entry, forward, attempt.podID) {
+				attempt.pf.Close()
+				return fmt.Errorf("pod %s is no longer a ready endpoint of service %s", attempt.podID, forward.Service)
+			}
+		}
+	}
+}
+
+func (m *PortForwardController) dispatchStatus(st store.RStore, entry portForwardEntry, forward model.PortForward, podID k8s.PodID, status store.PortForwardStatus) {
+	if podID == "" {
+		return
+	}
+	st.Dispatch(PortForwardStatusAction{
+		ManifestName: entry.name,
+		PodID:        podID,
+		LocalPort:    forward.LocalPort,
+		Status:       status,
+	})
 }
 
 var _ store.Subscriber = &PortForwardController{}
@@ -119,18 +345,19 @@ var _ store.Subscriber = &PortForwardController{}
 type portForwardEntry struct {
 	name      model.ManifestName
 	namespace k8s.Namespace
+	conn      k8s.Connection
 	podID     k8s.PodID
 	forwards  []model.PortForward
 	ctx       context.Context
 	cancel    func()
 }
 
-// Extract the port-forward specs from the manifest. If any of them
+// Extract the pod port-forward specs from the manifest. If any of them
 // have ContainerPort = 0, populate them with the default port in the pod spec.
 // Quietly drop forwards that we can't populate.
 func PopulatePortForwards(m model.Manifest, pod store.Pod) []model.PortForward {
 	cPorts := pod.ContainerPorts
-	fwds := m.K8sTarget().PortForwards
+	fwds := m.K8sTarget().PodPortForwards()
 	forwards := make([]model.PortForward, 0, len(fwds))
 	for _, forward := range fwds {
 		if forward.ContainerPort == 0 {
@@ -2,7 +2,9 @@ package engine
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/core/v1"
@@ -10,6 +12,7 @@ import (
 	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
 	"github.com/windmilleng/tilt/internal/testutils/tempdir"
 )
 
@@ -90,24 +93,194 @@ func TestPortForwardAutoDiscovery(t *testing.T) {
 	assert.Equal(t, 8000, f.kCli.LastForwardPortRemotePort)
 }
 
+func TestPortForwardReconnectsWhenConnectionDrops(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.setupRunningPod()
+	f.plc.OnChange(f.ctx, f.st)
+	assert.Equal(t, 1, f.kCli.ForwardPortCallCount())
+
+	f.kCli.DropLastPortForward(fmt.Errorf("lost connection to pod"))
+	f.waitForForwardPortCalls(2)
+	assert.Equal(t, 1, len(f.plc.activeForwards))
+}
+
+func TestPortForwardRetriesFailedConnections(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.kCli.ForwardPortErrors = []error{
+		fmt.Errorf("container not running"),
+		fmt.Errorf("container not running"),
+	}
+	f.setupRunningPod()
+	f.plc.OnChange(f.ctx, f.st)
+	f.waitForForwardPortCalls(3)
+}
+
+func TestPortForwardStopsReconnectingWhenPodGoesAway(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.setupRunningPod()
+	f.plc.OnChange(f.ctx, f.st)
+
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.PodSet = store.PodSet{}
+	f.st.UnlockMutableState()
+	f.plc.OnChange(f.ctx, f.st)
+	assert.Equal(t, 0, len(f.plc.activeForwards))
+
+	f.kCli.DropLastPortForward(fmt.Errorf("lost connection to pod"))
+	time.Sleep(10 * f.plc.initialBackoff)
+	assert.Equal(t, 1, f.kCli.ForwardPortCallCount())
+}
+
+func TestPortForwardToService(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.kCli.SetServiceEndpoints("fe-svc", []k8s.ServiceEndpoint{
+		{PodID: "pod-a", Port: 8081},
+		{PodID: "pod-b", Port: 8081},
+	})
+	f.setupServiceForward()
+
+	// Nothing to forward to until the resource is deployed.
+	f.plc.OnChange(f.ctx, f.st)
+	assert.Equal(t, 0, len(f.plc.activeServiceForwards))
+
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.LastSuccessfulDeployTime = time.Now()
+	f.st.UnlockMutableState()
+
+	f.plc.OnChange(f.ctx, f.st)
+	assert.Equal(t, 1, len(f.plc.activeServiceForwards))
+	assert.Equal(t, 0, len(f.plc.activeForwards))
+	f.waitForForwardPortCalls(1)
+	assert.Equal(t, "pod-a", f.kCli.LastForwardPodID().String())
+
+	// When the pod leaves the service, move to another one.
+	f.kCli.SetServiceEndpoints("fe-svc", []k8s.ServiceEndpoint{
+		{PodID: "pod-b", Port: 8081},
+		{PodID: "pod-c", Port: 8081},
+	})
+	f.waitForForwardPortCalls(2)
+	assert.Equal(t, "pod-b", f.kCli.LastForwardPodID().String())
+
+	// When the connection drops, stick with the same pod if it's still ready.
+	f.kCli.SetServiceEndpoints("fe-svc", []k8s.ServiceEndpoint{
+		{PodID: "pod-a", Port: 8081},
+		{PodID: "pod-b", Port: 8081},
+	})
+	f.kCli.DropLastPortForward(fmt.Errorf("lost connection to pod"))
+	f.waitForForwardPortCalls(3)
+	assert.Equal(t, "pod-b", f.kCli.LastForwardPodID().String())
+
+	// When the forward goes away, stop forwarding.
+	state = f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.LastSuccessfulDeployTime = time.Time{}
+	f.st.UnlockMutableState()
+
+	f.plc.OnChange(f.ctx, f.st)
+	assert.Equal(t, 0, len(f.plc.activeServiceForwards))
+}
+
+func TestPortForwardToServiceWaitsForReadyPods(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.setupServiceForward()
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.LastSuccessfulDeployTime = time.Now()
+	f.st.UnlockMutableState()
+
+	f.plc.OnChange(f.ctx, f.st)
+	time.Sleep(10 * f.plc.initialBackoff)
+	assert.Equal(t, 0, f.kCli.ForwardPortCallCount())
+
+	f.kCli.SetServiceEndpoints("fe-svc", []k8s.ServiceEndpoint{{PodID: "pod-a", Port: 8081}})
+	f.waitForForwardPortCalls(1)
+	assert.Equal(t, "pod-a", f.kCli.LastForwardPodID().String())
+}
+
 type plcFixture struct {
 	*tempdir.TempDirFixture
-	ctx  context.Context
-	kCli *k8s.FakeK8sClient
-	st   *store.Store
-	plc  *PortForwardController
+	ctx    context.Context
+	cancel func()
+	kCli   *k8s.FakeK8sClient
+	st     *store.Store
+	plc    *PortForwardController
 }
 
 func newPLCFixture(t *testing.T) *plcFixture {
 	f := tempdir.NewTempDirFixture(t)
 	st, _ := store.NewStoreForTesting()
 	kCli := k8s.NewFakeK8sClient()
-	plc := NewPortForwardController(kCli)
+	plc := NewPortForwardController(k8s.NewClientRegistryForTests(kCli))
+	plc.initialBackoff = time.Millisecond
+	plc.maxBackoff = time.Millisecond
+	plc.serviceRecheckInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(output.CtxForTest())
 	return &plcFixture{
 		TempDirFixture: f,
-		ctx:            context.Background(),
+		ctx:            ctx,
+		cancel:         cancel,
 		st:             st,
 		kCli:           kCli,
 		plc:            plc,
 	}
 }
+
+func (f *plcFixture) setupRunningPod() {
+	state := f.st.LockMutableStateForTesting()
+	m := model.Manifest{
+		Name: "fe",
+	}
+	m = m.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{
+			{
+				LocalPort:     8080,
+				ContainerPort: 8081,
+			},
+		},
+	})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	state.ManifestTargets["fe"].State.PodSet = store.NewPodSet(store.Pod{PodID: "pod-id", Phase: v1.PodRunning})
+	f.st.UnlockMutableState()
+}
+
+func (f *plcFixture) setupServiceForward() {
+	state := f.st.LockMutableStateForTesting()
+	m := model.Manifest{
+		Name: "fe",
+	}
+	m = m.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{
+			{
+				LocalPort:     8080,
+				ContainerPort: 80,
+				Service:       "fe-svc",
+			},
+		},
+	})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	f.st.UnlockMutableState()
+}
+
+func (f *plcFixture) waitForForwardPortCalls(n int) {
+	timeout := time.After(time.Second)
+	for f.kCli.ForwardPortCallCount() < n {
+		select {
+		case <-timeout:
+			f.T().Fatalf("Timed out waiting for %d calls to ForwardPort. Actual: %d", n, f.kCli.ForwardPortCallCount())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func (f *plcFixture) TearDown() {
+	f.cancel()
+	f.TempDirFixture.TearDown()
+}
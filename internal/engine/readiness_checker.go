@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Runs each manifest's readiness check after it deploys, until the check passes,
+// so that the resources that depend on it don't deploy before it can serve.
+type ReadinessChecker struct {
+	active map[model.ManifestName]readinessCheckEntry
+}
+
+type readinessCheckEntry struct {
+	name       model.ManifestName
+	check      model.ReadinessCheck
+	deployTime time.Time
+	ctx        context.Context
+	cancel     func()
+}
+
+func NewReadinessChecker() *ReadinessChecker {
+	return &ReadinessChecker{
+		active: make(map[model.ManifestName]readinessCheckEntry),
+	}
+}
+
+// Figure out which manifests are waiting on a readiness check, and which
+// checks we're running that aren't needed anymore.
+func (c *ReadinessChecker) diff(ctx context.Context, st store.RStore) (toStart []readinessCheckEntry, toShutdown []readinessCheckEntry) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	waiting := make(map[model.ManifestName]bool)
+	for _, mt := range state.EnabledTargets() {
+		ms := mt.State
+		check := mt.Manifest.ReadinessCheck
+		if check.Empty() || ms.ReadinessCheckPassed || !ms.CurrentBuild.Empty() ||
+			ms.LastSuccessfulDeployTime.IsZero() || ms.LastBuild().Error != nil {
+			continue
+		}
+
+		waiting[ms.Name] = true
+		existing, ok := c.active[ms.Name]
+		if ok && existing.deployTime.Equal(ms.LastSuccessfulDeployTime) && model.DeepEqual(existing.check, check) {
+			continue
+		}
+		if ok {
+			toShutdown = append(toShutdown, existing)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		entry := readinessCheckEntry{
+			name:       ms.Name,
+			check:      check,
+			deployTime: ms.LastSuccessfulDeployTime,
+			ctx:        ctx,
+			cancel:     cancel,
+		}
+		toStart = append(toStart, entry)
+		c.active[ms.Name] = entry
+	}
+
+	for name, entry := range c.active {
+		if waiting[name] {
+			continue
+		}
+		toShutdown = append(toShutdown, entry)
+		delete(c.active, name)
+	}
+
+	return toStart, toShutdown
+}
+
+func (c *ReadinessChecker) OnChange(ctx context.Context, st store.RStore) {
+	toStart, toShutdown := c.diff(ctx, st)
+	for _, entry := range toShutdown {
+		entry.cancel()
+	}
+
+	for _, entry := range toStart {
+		go c.checkUntilReady(st, entry)
+	}
+}
+
+// Runs the check every period until it passes, or until the entry is shut down
+// (e.g., because the manifest started building again).
+func (c *ReadinessChecker) checkUntilReady(st store.RStore, entry readinessCheckEntry) {
+	ticker := time.NewTicker(entry.check.PeriodOrDefault())
+	defer ticker.Stop()
+
+	lastErr := ""
+	for {
+		err := c.check(st, entry)
+		if entry.ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			st.Dispatch(ReadinessCheckAction{ManifestName: entry.name, DeployTime: entry.deployTime})
+			return
+		}
+
+		// Only tell the store when the reason changes, so that a check that keeps
+		// failing the same way doesn't re-render the UX every period.
+		if err.Error() != lastErr {
+			lastErr = err.Error()
+			st.Dispatch(ReadinessCheckAction{ManifestName: entry.name, DeployTime: entry.deployTime, Error: err})
+		}
+
+		select {
+		case <-ticker.C:
+		case <-entry.ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *ReadinessChecker) check(st store.RStore, entry readinessCheckEntry) error {
+	check := entry.check
+	switch {
+	case check.HTTPGet != "":
+		return checkHTTPGet(entry.ctx, check.HTTPGet, check.TimeoutOrDefault())
+	case !check.Exec.Empty():
+		return checkExec(entry.ctx, check.Exec, check.Workdir, check.TimeoutOrDefault())
+	case check.LogRegex != "":
+		return checkLogRegex(st, entry.name, check.LogRegex)
+	}
+	return nil
+}
+
+func checkHTTPGet(ctx context.Context, url string, timeout time.Duration) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return errors.Wrap(err, "readiness check")
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "GET %s", url)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+func checkExec(ctx context.Context, cmd model.Cmd, workdir string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, cmd.Argv[0], cmd.Argv[1:]...)
+	c.Dir = workdir
+	out, err := c.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg != "" {
+			return fmt.Errorf("%s: %v\n%s", cmd, err, msg)
+		}
+		return fmt.Errorf("%s: %v", cmd, err)
+	}
+	return nil
+}
+
+// Checks the logs of the resource's current pod, container, or serve_cmd. Those logs reset
+// when a build starts, so they only have what the resource printed since its last deploy.
+func checkLogRegex(st store.RStore, name model.ManifestName, logRegex string) error {
+	re, err := regexp.Compile(logRegex)
+	if err != nil {
+		return errors.Wrap(err, "readiness check")
+	}
+
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	mt, ok := state.ManifestTargets[name]
+	if !ok {
+		return fmt.Errorf("no resource named %s", name)
+	}
+
+	var log model.Log
+	if mt.Manifest.IsDC() {
+		log = mt.State.DCResourceState().Log()
+	} else if mt.Manifest.IsLocal() {
+		log = mt.State.LocalResourceState().CurrentLog
+	} else {
+		log = mt.State.MostRecentPod().Log()
+	}
+
+	if !re.MatchString(log.String()) {
+		return fmt.Errorf("logs don't match /%s/ yet", logRegex)
+	}
+	return nil
+}
+
+var _ store.Subscriber = &ReadinessChecker{}
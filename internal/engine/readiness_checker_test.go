@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestReadinessCheckerStartsAfterDeploy(t *testing.T) {
+	f := newReadinessCheckerFixture(t)
+	defer f.TearDown()
+
+	toStart, _ := f.c.diff(output.CtxForTest(), f.st)
+	assert.Empty(t, toStart)
+
+	f.mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	f.mt.State.LastSuccessfulDeployTime = time.Now()
+	f.st.SetState(*f.state)
+
+	toStart, _ = f.c.diff(output.CtxForTest(), f.st)
+	if assert.Equal(t, 1, len(toStart)) {
+		assert.Equal(t, model.ManifestName("sancho"), toStart[0].name)
+	}
+
+	// Already running, so nothing new to start.
+	toStart, toShutdown := f.c.diff(output.CtxForTest(), f.st)
+	assert.Empty(t, toStart)
+	assert.Empty(t, toShutdown)
+
+	// A new build makes the running check stale.
+	f.mt.State.CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+	f.st.SetState(*f.state)
+	_, toShutdown = f.c.diff(output.CtxForTest(), f.st)
+	assert.Equal(t, 1, len(toShutdown))
+}
+
+func TestReadinessCheckHTTPGet(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	err := checkHTTPGet(output.CtxForTest(), server.URL, time.Second)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "503 Service Unavailable")
+	}
+
+	status = http.StatusOK
+	assert.NoError(t, checkHTTPGet(output.CtxForTest(), server.URL, time.Second))
+}
+
+func TestReadinessCheckExec(t *testing.T) {
+	assert.NoError(t, checkExec(output.CtxForTest(), model.ToShellCmd("true"), "", time.Second))
+
+	err := checkExec(output.CtxForTest(), model.ToShellCmd("echo not yet && false"), "", time.Second)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not yet")
+	}
+}
+
+func TestReadinessCheckExecWorkdir(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+	f.WriteFile("ready", "")
+
+	assert.NoError(t, checkExec(output.CtxForTest(), model.ToShellCmd("test -f ready"), f.Path(), time.Second))
+}
+
+func TestReadinessCheckLogRegex(t *testing.T) {
+	f := newReadinessCheckerFixture(t)
+	defer f.TearDown()
+
+	pod := store.Pod{PodID: "pod-id", CurrentLog: model.NewLog("starting up\n")}
+	f.mt.State.PodSet = store.NewPodSet(pod)
+	f.st.SetState(*f.state)
+
+	err := checkLogRegex(f.st, "sancho", `listening on :\d+`)
+	assert.Error(t, err)
+
+	pod.CurrentLog = model.NewLog("starting up\nlistening on :8080\n")
+	f.mt.State.PodSet = store.NewPodSet(pod)
+	f.st.SetState(*f.state)
+
+	assert.NoError(t, checkLogRegex(f.st, "sancho", `listening on :\d+`))
+}
+
+func TestHandleReadinessCheckAction(t *testing.T) {
+	f := newReadinessCheckerFixture(t)
+	defer f.TearDown()
+
+	ms := f.mt.State
+	ms.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	deployTime := time.Now()
+	ms.LastSuccessfulDeployTime = deployTime
+	assert.False(t, f.mt.IsReady())
+
+	// Ignore results for a deploy we're no longer running.
+	handleReadinessCheckAction(output.CtxForTest(), f.state, ReadinessCheckAction{
+		ManifestName: "sancho",
+		DeployTime:   deployTime.Add(-time.Minute),
+	})
+	assert.False(t, ms.ReadinessCheckPassed)
+
+	handleReadinessCheckAction(output.CtxForTest(), f.state, ReadinessCheckAction{
+		ManifestName: "sancho",
+		DeployTime:   deployTime,
+		Error:        assert.AnError,
+	})
+	assert.Equal(t, assert.AnError.Error(), ms.ReadinessCheckError)
+	assert.False(t, ms.ReadinessCheckPassed)
+
+	handleReadinessCheckAction(output.CtxForTest(), f.state, ReadinessCheckAction{
+		ManifestName: "sancho",
+		DeployTime:   deployTime,
+	})
+	assert.Equal(t, "", ms.ReadinessCheckError)
+	assert.True(t, ms.ReadinessCheckPassed)
+}
+
+type readinessCheckerFixture struct {
+	*tempdir.TempDirFixture
+	c     *ReadinessChecker
+	st    *store.TestingStore
+	mt    *store.ManifestTarget
+	state *store.EngineState
+}
+
+func newReadinessCheckerFixture(t *testing.T) *readinessCheckerFixture {
+	f := tempdir.NewTempDirFixture(t)
+
+	state := store.NewState()
+	m := NewSanchoDockerBuildManifest(f)
+	m.ReadinessCheck = model.ReadinessCheck{HTTPGet: "http://localhost:8080/healthz"}
+	mt := store.NewManifestTarget(m)
+	state.UpsertManifestTarget(mt)
+
+	st := store.NewTestingStore()
+	st.SetState(*state)
+
+	return &readinessCheckerFixture{
+		TempDirFixture: f,
+		c:              NewReadinessChecker(),
+		st:             st,
+		mt:             mt,
+		state:          state,
+	}
+}
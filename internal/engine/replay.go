@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/windmilleng/tilt/internal/hud"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Replaces the engine state with one loaded from a snapshot.
+type SnapshotLoadedAction struct {
+	State *store.EngineState
+}
+
+func (SnapshotLoadedAction) Action() {}
+
+// The reducer for `tilt replay`. The HUD shows the state from the snapshot,
+// and nothing the user does changes it, except for how it's displayed.
+var ReplayReducer = store.Reducer(func(ctx context.Context, state *store.EngineState, action store.Action) {
+	switch action := action.(type) {
+	case SnapshotLoadedAction:
+		*state = *action.State
+
+		// Otherwise the store thinks that every build is done, and stops
+		// before the user has looked at anything.
+		state.WatchFiles = true
+	case hud.ExitAction:
+		handleExitAction(state, action)
+	case hud.SetLogTimestampsAction:
+		handleLogTimestampsAction(state, action)
+	}
+})
+
+// The reducer for `tilt replay` of an action recording. It starts at the first action,
+// and the user steps through the rest with StepReplayActions.
+func NewRecordingReplayReducer(rec store.Recording) store.Reducer {
+	step := 0
+	return func(ctx context.Context, state *store.EngineState, action store.Action) {
+		switch action := action.(type) {
+		case hud.StepReplayAction:
+			step += action.Delta
+			if step < 0 {
+				step = 0
+			}
+			if step >= len(rec.Actions) {
+				step = len(rec.Actions) - 1
+			}
+			showRecordedState(state, rec, step)
+		default:
+			ReplayReducer(ctx, state, action)
+		}
+	}
+}
+
+// Shows the state after the given step, but keeps the user's display settings.
+func showRecordedState(state *store.EngineState, rec store.Recording, step int) {
+	logTimestamps := state.LogTimestamps
+	*state = *rec.StateAt(step)
+	state.LogTimestamps = logTimestamps
+}
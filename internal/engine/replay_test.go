@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/hud"
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestReplayReducerIgnoresChanges(t *testing.T) {
+	ctx := output.CtxForTest()
+	loaded := store.NewState()
+	loaded.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "fe"}))
+
+	state := store.NewState()
+	ReplayReducer(ctx, state, SnapshotLoadedAction{State: loaded})
+	assert.Equal(t, []model.ManifestName{"fe"}, state.ManifestDefinitionOrder)
+	assert.True(t, state.WatchFiles)
+
+	ReplayReducer(ctx, state, view.TearDownAction{Name: "fe"})
+	ReplayReducer(ctx, state, view.TriggerAllAction{})
+	assert.Equal(t, []model.ManifestName{"fe"}, state.ManifestDefinitionOrder)
+	assert.Empty(t, state.TriggerQueue)
+}
+
+func TestRecordingReplayReducerSteps(t *testing.T) {
+	ctx := output.CtxForTest()
+	fe := store.NewState()
+	fe.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "fe"}))
+	feSnapshot := store.NewSnapshot(*fe)
+	rec := store.Recording{Actions: []store.RecordedAction{
+		{Index: 0, Type: "engine.InitAction", State: &store.Snapshot{}},
+		{Index: 1, Type: "engine.ConfigsReloadedAction", State: &feSnapshot},
+		{Index: 2, Type: "store.LogAction"},
+	}}
+
+	reducer := NewRecordingReplayReducer(rec)
+	state := store.NewState()
+	reducer(ctx, state, hud.StepReplayAction{})
+	assert.Empty(t, state.ManifestDefinitionOrder)
+
+	reducer(ctx, state, hud.StepReplayAction{Delta: 1})
+	assert.Equal(t, []model.ManifestName{"fe"}, state.ManifestDefinitionOrder)
+
+	// Past the end, we stay on the last action.
+	reducer(ctx, state, hud.StepReplayAction{Delta: 5})
+	assert.Equal(t, []model.ManifestName{"fe"}, state.ManifestDefinitionOrder)
+	assert.Contains(t, state.Log.String(), "Action 3 of 3: store.LogAction")
+
+	reducer(ctx, state, view.TriggerAllAction{})
+	assert.Empty(t, state.TriggerQueue)
+
+	reducer(ctx, state, hud.StepReplayAction{Delta: -10})
+	assert.Empty(t, state.ManifestDefinitionOrder)
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Watches the ReplicaSets that our Deployments create,
+// to find out when a rollout has finished.
+type ReplicaSetWatcher struct {
+	clients  *k8s.ClientRegistry
+	watching map[k8s.Connection]bool
+}
+
+func NewReplicaSetWatcher(clients *k8s.ClientRegistry) *ReplicaSetWatcher {
+	return &ReplicaSetWatcher{
+		clients:  clients,
+		watching: make(map[k8s.Connection]bool),
+	}
+}
+
+// The connections that we deploy with, but aren't watching yet.
+func (w *ReplicaSetWatcher) connectionsToWatch(st store.RStore) []k8s.Connection {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	if !state.WatchFiles {
+		return nil
+	}
+
+	var result []k8s.Connection
+	for _, conn := range k8sConnections(w.clients, state.Manifests()) {
+		if !w.watching[conn] {
+			result = append(result, conn)
+		}
+	}
+	return result
+}
+
+func (w *ReplicaSetWatcher) OnChange(ctx context.Context, st store.RStore) {
+	for _, conn := range w.connectionsToWatch(st) {
+		w.watching[conn] = true
+
+		kCli, err := w.clients.ClientFor(ctx, conn)
+		if err != nil {
+			// The deploy with this connection reports the same error.
+			logger.Get(ctx).Debugf("Error watching k8s replica sets: %v", err)
+			continue
+		}
+
+		ch, err := kCli.WatchReplicaSets(ctx, k8s.TiltRunSelector())
+		if err != nil {
+			// Without ReplicaSets, we fall back to judging rollouts by their pods,
+			// so don't stop the world if the user isn't allowed to watch them.
+			logger.Get(ctx).Debugf("Error watching k8s replica sets: %v", err)
+			continue
+		}
+
+		go w.dispatchReplicaSetChangesLoop(ctx, ch, st)
+	}
+}
+
+func (w *ReplicaSetWatcher) dispatchReplicaSetChangesLoop(ctx context.Context, ch <-chan *appsv1.ReplicaSet, st store.RStore) {
+	for {
+		select {
+		case rs, ok := <-ch:
+			if !ok {
+				return
+			}
+			st.Dispatch(ReplicaSetChangeAction{ReplicaSet: rs})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Restarts the pods (or Docker Compose containers) of resources that the user
+// asked to restart (e.g., from the HUD).
+type RestartController struct {
+	clients *k8s.ClientRegistry
+	dcc     dockercompose.DockerComposeClient
+
+	started map[model.ManifestName]bool
+}
+
+func NewRestartController(clients *k8s.ClientRegistry, dcc dockercompose.DockerComposeClient) *RestartController {
+	return &RestartController{
+		clients: clients,
+		dcc:     dcc,
+		started: make(map[model.ManifestName]bool),
+	}
+}
+
+type pendingRestart struct {
+	manifest model.Manifest
+	pods     []store.Pod
+
+	// The YAML that we last applied, so that we can bring back the pods
+	// that no controller will replace for us.
+	lastAppliedYAML string
+}
+
+// The restarts that we haven't started yet.
+func (c *RestartController) pending(st store.RStore) []pendingRestart {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	stillPending := make(map[model.ManifestName]bool)
+	for _, mn := range state.PendingRestarts {
+		stillPending[mn] = true
+	}
+	for name := range c.started {
+		if !stillPending[name] {
+			delete(c.started, name)
+		}
+	}
+
+	var result []pendingRestart
+	for _, mn := range state.PendingRestarts {
+		// If it's building, the build is about to replace the pods anyway.
+		if c.started[mn] || state.CurrentlyBuilding[mn] {
+			continue
+		}
+		mt, ok := state.ManifestTargets[mn]
+		if !ok {
+			continue
+		}
+		result = append(result, pendingRestart{
+			manifest:        mt.Manifest,
+			pods:            mt.State.PodSet.PodList(),
+			lastAppliedYAML: mt.State.LastAppliedYAML,
+		})
+	}
+	return result
+}
+
+func (c *RestartController) OnChange(ctx context.Context, st store.RStore) {
+	for _, r := range c.pending(st) {
+		c.started[r.manifest.Name] = true
+
+		// Deleting pods waits for them to go away, which can take a while,
+		// so don't hold up the other subscribers.
+		go func(r pendingRestart) {
+			err := c.restart(ctx, r)
+			st.Dispatch(RestartCompleteAction{ManifestName: r.manifest.Name, Error: err})
+		}(r)
+	}
+}
+
+func (c *RestartController) restart(ctx context.Context, r pendingRestart) error {
+	m := r.manifest
+	if m.IsDC() {
+		dcTarget := m.DockerComposeTarget()
+		w := logger.Get(ctx).Writer(logger.InfoLvl)
+		return c.dcc.Restart(ctx, dcTarget.ConfigPath, dcTarget.Name, w, w)
+	}
+
+	if !m.IsK8s() || len(r.pods) == 0 {
+		return nil
+	}
+
+	kCli, err := c.clients.ClientFor(ctx, k8s.ConnectionForTarget(m.K8sTarget()))
+	if err != nil {
+		return err
+	}
+
+	var toDelete []store.Pod
+	var toRecreate []k8s.K8sEntity
+	for _, p := range r.pods {
+		pod, err := kCli.PodByID(ctx, p.PodID, p.Namespace)
+		if apierrors.IsNotFound(err) || (err == nil && pod == nil) {
+			// It's already gone.
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "restarting %s", p.PodID)
+		}
+
+		if metav1.GetControllerOf(pod) != nil {
+			// Its ReplicaSet (or Job, or StatefulSet...) will replace it.
+			toDelete = append(toDelete, p)
+			continue
+		}
+
+		// Nothing will replace a bare pod, so we have to create it again.
+		e, ok, err := podEntityFromYAML(r.lastAppliedYAML, p)
+		if err != nil {
+			return errors.Wrapf(err, "restarting %s", p.PodID)
+		}
+		if !ok {
+			logger.Get(ctx).Infof("Not restarting pod %s: no controller owns it, and it's not in the YAML that Tilt applied", p.PodID)
+			continue
+		}
+		toDelete = append(toDelete, p)
+		toRecreate = append(toRecreate, e)
+	}
+
+	if len(toDelete) == 0 {
+		return nil
+	}
+	err = kCli.Delete(ctx, podEntities(toDelete))
+	if err != nil {
+		return err
+	}
+	if len(toRecreate) == 0 {
+		return nil
+	}
+	return kCli.Upsert(ctx, toRecreate)
+}
+
+// Finds the pod in the YAML, if it's there.
+func podEntityFromYAML(yaml string, p store.Pod) (k8s.K8sEntity, bool, error) {
+	if yaml == "" {
+		return k8s.K8sEntity{}, false, nil
+	}
+	entities, err := k8s.ParseYAMLFromString(yaml)
+	if err != nil {
+		return k8s.K8sEntity{}, false, err
+	}
+	for _, e := range entities {
+		if !e.HasKind("Pod") || !e.HasName(p.PodID.String()) {
+			continue
+		}
+		ns := e.ExplicitNamespace()
+		if ns == "" || ns == p.Namespace {
+			return e, true, nil
+		}
+	}
+	return k8s.K8sEntity{}, false, nil
+}
+
+func podEntities(pods []store.Pod) []k8s.K8sEntity {
+	result := make([]k8s.K8sEntity, 0, len(pods))
+	for _, p := range pods {
+		pod := &v1.Pod{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      p.PodID.String(),
+				Namespace: p.Namespace.String(),
+			},
+		}
+		kind := pod.GroupVersionKind()
+		result = append(result, k8s.K8sEntity{Obj: pod, Kind: &kind})
+	}
+	return result
+}
+
+var _ store.Subscriber = &RestartController{}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestHandleRestartPodAction(t *testing.T) {
+	ctx := output.CtxForTest()
+	state := store.NewState()
+	sancho := k8s.NewK8sOnlyManifestForTesting(testyaml.SanchoYAML, nil)
+	mt := store.NewManifestTarget(sancho)
+	state.UpsertManifestTarget(mt)
+
+	// Nothing to restart until it has pods.
+	handleRestartPodAction(ctx, state, view.RestartPodAction{Name: sancho.Name})
+	assert.Empty(t, state.PendingRestarts)
+
+	mt.State.PodSet = store.NewPodSet(store.Pod{PodID: "sancho-1", Namespace: "default"})
+	handleRestartPodAction(ctx, state, view.RestartPodAction{Name: sancho.Name})
+	handleRestartPodAction(ctx, state, view.RestartPodAction{Name: sancho.Name})
+	assert.Equal(t, []model.ManifestName{sancho.Name}, state.PendingRestarts)
+
+	handleRestartCompleteAction(ctx, state, RestartCompleteAction{ManifestName: sancho.Name})
+	assert.Empty(t, state.PendingRestarts)
+}
+
+func TestRestartControllerDeletesPods(t *testing.T) {
+	f := newRestartControllerFixture(t)
+	m := k8s.NewK8sOnlyManifestForTesting(testyaml.SanchoYAML, nil)
+	mt := store.NewManifestTarget(m)
+	mt.State.PodSet = store.NewPodSet(store.Pod{PodID: "sancho-1", Namespace: "default"})
+	f.kCli.Pods = []v1.Pod{ownedPod("sancho-1", "default")}
+
+	state := store.NewState()
+	state.UpsertManifestTarget(mt)
+	state.PendingRestarts = []model.ManifestName{m.Name}
+	state.CurrentlyBuilding[m.Name] = true
+	f.st.SetState(*state)
+
+	// Wait for the build to finish: it'll replace the pods anyway.
+	f.c.OnChange(output.CtxForTest(), f.st)
+	assert.Empty(t, f.st.Actions)
+
+	delete(state.CurrentlyBuilding, m.Name)
+	f.st.SetState(*state)
+	f.c.OnChange(output.CtxForTest(), f.st)
+	actions := f.st.WaitForActions(t, 1)
+	assert.Equal(t, []store.Action{RestartCompleteAction{ManifestName: m.Name}}, actions)
+	assert.Equal(t, [][]string{{"Pod/sancho-1"}}, deleteCallNames(f.kCli))
+	assert.Equal(t, "", f.kCli.Yaml)
+
+	// Only restart once, even if we haven't seen the RestartCompleteAction yet.
+	f.c.OnChange(output.CtxForTest(), f.st)
+	assert.Equal(t, 1, len(f.kCli.DeleteCalls))
+}
+
+func TestRestartControllerRecreatesBarePods(t *testing.T) {
+	f := newRestartControllerFixture(t)
+	m := k8s.NewK8sOnlyManifestForTesting(testyaml.PodYAML, nil)
+	mt := store.NewManifestTarget(m)
+	mt.State.LastAppliedYAML = testyaml.PodYAML
+	mt.State.PodSet = store.NewPodSet(
+		store.Pod{PodID: "sleep", Namespace: "default"},
+		store.Pod{PodID: "stray", Namespace: "default"})
+	f.kCli.Pods = []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "sleep", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "stray", Namespace: "default"}},
+	}
+
+	state := store.NewState()
+	state.UpsertManifestTarget(mt)
+	state.PendingRestarts = []model.ManifestName{m.Name}
+	f.st.SetState(*state)
+
+	f.c.OnChange(output.CtxForTest(), f.st)
+	f.st.WaitForActions(t, 1)
+
+	// Nothing would bring back the stray pod if we deleted it.
+	assert.Equal(t, [][]string{{"Pod/sleep"}}, deleteCallNames(f.kCli))
+	assert.Contains(t, f.kCli.Yaml, "name: sleep")
+}
+
+func ownedPod(name, namespace string) v1.Pod {
+	isController := true
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: name + "-rs", Controller: &isController},
+			},
+		},
+	}
+}
+
+func TestRestartControllerDockerCompose(t *testing.T) {
+	f := newRestartControllerFixture(t)
+	m := model.Manifest{Name: "web"}.WithDeployTarget(model.DockerComposeTarget{Name: "web", ConfigPath: "docker-compose.yml"})
+
+	state := store.NewState()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	state.PendingRestarts = []model.ManifestName{m.Name}
+	f.st.SetState(*state)
+
+	f.c.OnChange(output.CtxForTest(), f.st)
+	f.st.WaitForActions(t, 1)
+	assert.Equal(t, []model.TargetName{"web"}, f.dcc.RestartCalls)
+}
+
+type restartControllerFixture struct {
+	c    *RestartController
+	st   *store.TestingStore
+	kCli *k8s.FakeK8sClient
+	dcc  *dockercompose.FakeDCClient
+}
+
+func newRestartControllerFixture(t *testing.T) *restartControllerFixture {
+	kCli := k8s.NewFakeK8sClient()
+	dcc := dockercompose.NewFakeDockerComposeClient(t, output.CtxForTest())
+	return &restartControllerFixture{
+		c:    NewRestartController(k8s.NewClientRegistryForTests(kCli), dcc),
+		st:   store.NewTestingStore(),
+		kCli: kCli,
+		dcc:  dcc,
+	}
+}
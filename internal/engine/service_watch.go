@@ -17,50 +17,102 @@ import (
 var watchTimeout = 10 * time.Second
 
 type ServiceWatcher struct {
-	kCli     k8s.Client
-	watching bool
+	clients  *k8s.ClientRegistry
+	watching map[k8s.Connection]bool
 	nodeIP   k8s.NodeIP
 }
 
-func NewServiceWatcher(kCli k8s.Client, nodeIP k8s.NodeIP) *ServiceWatcher {
+func NewServiceWatcher(clients *k8s.ClientRegistry, nodeIP k8s.NodeIP) *ServiceWatcher {
 	return &ServiceWatcher{
-		kCli:   kCli,
-		nodeIP: nodeIP,
+		clients:  clients,
+		watching: make(map[k8s.Connection]bool),
+		nodeIP:   nodeIP,
 	}
 }
 
-func (w *ServiceWatcher) needsWatch(st store.RStore) bool {
+// The connections that we deploy with, but aren't watching yet.
+func (w *ServiceWatcher) connectionsToWatch(st store.RStore) []k8s.Connection {
 	state := st.RLockState()
 	defer st.RUnlockState()
 
-	atLeastOneK8S := false
-	for _, m := range state.Manifests() {
-		if m.IsK8s() {
-			atLeastOneK8S = true
+	if !state.WatchFiles {
+		return nil
+	}
+
+	var result []k8s.Connection
+	for _, conn := range k8sConnections(w.clients, state.Manifests()) {
+		if !w.watching[conn] {
+			result = append(result, conn)
 		}
 	}
-	return atLeastOneK8S && state.WatchFiles && !w.watching
+	return result
 }
 
 func (w *ServiceWatcher) OnChange(ctx context.Context, st store.RStore) {
-	if !w.needsWatch(st) {
-		return
+	for _, conn := range w.connectionsToWatch(st) {
+		w.watching[conn] = true
+
+		kCli, err := w.clients.ClientFor(ctx, conn)
+		if err != nil {
+			// The deploy with this connection reports the same error,
+			// so there's no need to stop the world.
+			logger.Get(ctx).Infof("Error watching services: %v", err)
+			continue
+		}
+
+		err = w.watch(ctx, st, kCli, w.nodeIPFor(conn))
+		if err != nil {
+			st.Dispatch(NewErrorAction(err))
+			return
+		}
 	}
-	w.watching = true
+}
 
+// The node IP is only for the cluster that Tilt started with.
+func (w *ServiceWatcher) nodeIPFor(conn k8s.Connection) k8s.NodeIP {
+	if w.clients.IsDefaultCluster(conn) {
+		return w.nodeIP
+	}
+	return ""
+}
+
+func (w *ServiceWatcher) watch(ctx context.Context, st store.RStore, kCli k8s.Client, nodeIP k8s.NodeIP) error {
 	ctx2, cancel := context.WithTimeout(ctx, watchTimeout)
 	defer cancel()
-	ch, err := w.kCli.WatchServices(ctx2, []model.LabelPair{k8s.TiltRunLabel()})
+	ch, err := kCli.WatchServices(ctx2, []model.LabelPair{k8s.TiltRunLabel()})
+	if err != nil {
+		return errors.Wrap(err, "Error watching services. Are you connected to kubernetes?\n")
+	}
+
+	go w.dispatchServiceChangesLoop(ctx, ch, st, nodeIP)
+
+	// Ingresses are optional, and some users aren't allowed to watch them,
+	// so we don't treat this as an error.
+	ingCh, err := kCli.WatchIngresses(ctx, k8s.TiltRunSelector())
 	if err != nil {
-		err = errors.Wrap(err, "Error watching services. Are you connected to kubernetes?\n")
-		st.Dispatch(NewErrorAction(err))
-		return
+		logger.Get(ctx).Debugf("Not watching ingresses: %v", err)
+		return nil
 	}
 
-	go w.dispatchServiceChangesLoop(ctx, ch, st)
+	go w.dispatchIngressChangesLoop(ctx, ingCh, st)
+	return nil
+}
+
+func (w *ServiceWatcher) dispatchIngressChangesLoop(ctx context.Context, ch <-chan k8s.IngressEvent, st store.RStore) {
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			st.Dispatch(IngressChangeAction{Ingress: e.Ingress, Deleted: e.Deleted})
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
-func (w *ServiceWatcher) dispatchServiceChangesLoop(ctx context.Context, ch <-chan *v1.Service, st store.RStore) {
+func (w *ServiceWatcher) dispatchServiceChangesLoop(ctx context.Context, ch <-chan *v1.Service, st store.RStore, nodeIP k8s.NodeIP) {
 	for {
 		select {
 		case service, ok := <-ch:
@@ -68,7 +120,7 @@ func (w *ServiceWatcher) dispatchServiceChangesLoop(ctx context.Context, ch <-ch
 				return
 			}
 
-			err := dispatchServiceChange(st, service, w.nodeIP)
+			err := dispatchServiceChange(st, service, nodeIP)
 			if err != nil {
 				logger.Get(ctx).Infof("error resolving service url %s: %v", service.Name, err)
 			}
@@ -0,0 +1,450 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/pkg/errors"
+	"github.com/windmilleng/wmclient/pkg/dirs"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/internal/ignore"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Build results of a manifest that we loaded from the last run of tilt.
+type RestoredManifest struct {
+	DeployID   model.DeployID
+	DeployTime time.Time
+	Results    store.BuildResultSet
+}
+
+// Saves build and deploy results to a state file in the windmill dir,
+// so that when tilt restarts and nothing has changed, it can skip straight
+// to the deployed state instead of rebuilding and redeploying everything.
+//
+// We write the state file whenever a build completes, so that it's up-to-date
+// whenever tilt exits, even if it doesn't exit cleanly.
+//
+// Only Kubernetes resources are persisted. Docker Compose resources
+// are cheap to bring up again, so we always rebuild them.
+type StatePersister struct {
+	dir     *dirs.WindmillDir
+	dCli    docker.Client
+	clients *k8s.ClientRegistry
+
+	lastSavedBuildCount int
+}
+
+func ProvideStatePersister(dCli docker.Client, clients *k8s.ClientRegistry) (*StatePersister, error) {
+	dir, err := dirs.UseWindmillDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStatePersister(dir, dCli, clients), nil
+}
+
+func NewStatePersister(dir *dirs.WindmillDir, dCli docker.Client, clients *k8s.ClientRegistry) *StatePersister {
+	return &StatePersister{dir: dir, dCli: dCli, clients: clients}
+}
+
+// Each Tiltfile gets its own state file.
+func statePath(dir *dirs.WindmillDir, tiltfilePath string) (string, error) {
+	return dir.Abs(filepath.Join("tilt-state", fmt.Sprintf("%x.json", sha256.Sum256([]byte(tiltfilePath)))))
+}
+
+func (sp *StatePersister) statePath(tiltfilePath string) (string, error) {
+	return statePath(sp.dir, tiltfilePath)
+}
+
+// Deletes the state file for this Tiltfile (e.g., because `tilt down` deleted
+// everything it deployed), so that the next run of tilt deploys from scratch.
+func DeletePersistedState(tiltfilePath string) error {
+	dir, err := dirs.UseWindmillDir()
+	if err != nil {
+		return err
+	}
+	path, err := statePath(dir, tiltfilePath)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "DeletePersistedState")
+	}
+	return nil
+}
+
+// The parts of a manifest's state that we need to persist it,
+// copied out so that we can hash files without holding the state lock.
+type manifestSnapshot struct {
+	manifest   model.Manifest
+	deployID   model.DeployID
+	deployTime time.Time
+	results    store.BuildResultSet
+}
+
+func (sp *StatePersister) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	if state.CompletedBuildCount == sp.lastSavedBuildCount || len(state.CurrentlyBuilding) > 0 {
+		st.RUnlockState()
+		return
+	}
+	sp.lastSavedBuildCount = state.CompletedBuildCount
+	tiltfilePath := state.TiltfilePath
+	snapshots := persistableManifests(state)
+	st.RUnlockState()
+
+	path, err := sp.statePath(tiltfilePath)
+	if err != nil {
+		logger.Get(ctx).Debugf("Saving tilt state: %v", err)
+		return
+	}
+
+	persisted := store.NewPersistedState()
+	for _, s := range snapshots {
+		pm, err := sp.persistManifest(ctx, s)
+		if err != nil {
+			logger.Get(ctx).Debugf("Saving tilt state for %s: %v", s.manifest.Name, err)
+			continue
+		}
+		persisted.Manifests[s.manifest.Name] = pm
+	}
+
+	err = store.SavePersistedState(path, persisted)
+	if err != nil {
+		logger.Get(ctx).Debugf("Saving tilt state: %v", err)
+	}
+}
+
+// Only persist manifests that are deployed and up-to-date, i.e., their last build
+// succeeded and nothing has changed since then.
+func persistableManifests(state store.EngineState) []manifestSnapshot {
+	result := []manifestSnapshot{}
+	for _, mt := range state.Targets() {
+		ms := mt.State
+		manifest := mt.Manifest
+		if !manifest.IsK8s() || ms.DeployID == 0 || !ms.CurrentBuild.Empty() {
+			continue
+		}
+		if len(ms.BuildHistory) == 0 || ms.LastBuild().Error != nil {
+			continue
+		}
+		if ms.HasPendingFileChanges() || !ms.PendingManifestChange.IsZero() || ms.NeedsRebuildFromCrash {
+			continue
+		}
+
+		results := store.BuildResultSet{}
+		for _, iTarget := range manifest.ImageTargets {
+			status, ok := ms.BuildStatuses[iTarget.ID()]
+			if !ok || !status.LastSuccessfulResult.HasImage() {
+				break
+			}
+			results[iTarget.ID()] = status.LastSuccessfulResult
+		}
+		if len(results) != len(manifest.ImageTargets) {
+			continue
+		}
+
+		result = append(result, manifestSnapshot{
+			manifest:   manifest,
+			deployID:   ms.DeployID,
+			deployTime: ms.LastSuccessfulDeployTime,
+			results:    results,
+		})
+	}
+	return result
+}
+
+func (sp *StatePersister) persistManifest(ctx context.Context, s manifestSnapshot) (store.PersistedManifest, error) {
+	kTarget := s.manifest.K8sTarget()
+	conn := k8s.ConnectionForTarget(kTarget)
+	kCli, err := sp.clients.ClientFor(ctx, conn)
+	if err != nil {
+		return store.PersistedManifest{}, err
+	}
+
+	namespace := kCli.ConfigNamespace()
+	entities, err := persistedEntities(kTarget, namespace)
+	if err != nil {
+		return store.PersistedManifest{}, err
+	}
+
+	pm := store.PersistedManifest{
+		DeployID:     s.deployID,
+		DeployTime:   s.deployTime,
+		YAMLChecksum: yamlChecksum(kTarget),
+		KubeContext:  sp.clients.ContextFor(conn),
+		Namespace:    namespace,
+		Entities:     entities,
+	}
+
+	for _, iTarget := range s.manifest.ImageTargets {
+		result := s.results[iTarget.ID()]
+		hash, err := contextHash(iTarget)
+		if err != nil {
+			return store.PersistedManifest{}, err
+		}
+
+		filesReplaced := []string{}
+		for f := range result.FilesReplacedSet {
+			filesReplaced = append(filesReplaced, f)
+		}
+		sort.Strings(filesReplaced)
+
+		pm.Images = append(pm.Images, store.PersistedImage{
+			TargetID:      iTarget.ID(),
+			Ref:           result.Image.String(),
+			ContextHash:   hash,
+			FilesReplaced: filesReplaced,
+		})
+	}
+	return pm, nil
+}
+
+// Reads the state file for this Tiltfile, and returns the results of the
+// manifests that don't need to be rebuilt: the manifest definition and source files
+// haven't changed since the last run of tilt, and the images we built are still around.
+func (sp *StatePersister) Restore(ctx context.Context, tiltfilePath string, manifests []model.Manifest) map[model.ManifestName]RestoredManifest {
+	path, err := sp.statePath(tiltfilePath)
+	if err != nil {
+		logger.Get(ctx).Debugf("Restoring tilt state: %v", err)
+		return nil
+	}
+
+	persisted, err := store.LoadPersistedState(path)
+	if err != nil {
+		logger.Get(ctx).Debugf("Restoring tilt state: %v", err)
+		return nil
+	}
+
+	result := make(map[model.ManifestName]RestoredManifest)
+	for _, m := range manifests {
+		pm, ok := persisted.Manifests[m.Name]
+		if !ok {
+			continue
+		}
+
+		restored, err := sp.restoreManifest(ctx, m, pm)
+		if err != nil {
+			logger.Get(ctx).Debugf("Not restoring %s: %v", m.Name, err)
+			continue
+		}
+		logger.Get(ctx).Infof("Skipping initial build of %s: nothing has changed since the last run of tilt", m.Name)
+		result[m.Name] = restored
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func (sp *StatePersister) restoreManifest(ctx context.Context, m model.Manifest, pm store.PersistedManifest) (RestoredManifest, error) {
+	if !m.IsK8s() {
+		return RestoredManifest{}, fmt.Errorf("not a Kubernetes resource")
+	}
+	if yamlChecksum(m.K8sTarget()) != pm.YAMLChecksum {
+		return RestoredManifest{}, fmt.Errorf("YAML changed")
+	}
+
+	// The same YAML in a different cluster or namespace is a different deploy.
+	conn := k8s.ConnectionForTarget(m.K8sTarget())
+	if kubeContext := sp.clients.ContextFor(conn); kubeContext != pm.KubeContext {
+		return RestoredManifest{}, fmt.Errorf("kube context changed from %q to %q", pm.KubeContext, kubeContext)
+	}
+	kCli, err := sp.clients.ClientFor(ctx, conn)
+	if err != nil {
+		return RestoredManifest{}, err
+	}
+	if namespace := kCli.ConfigNamespace(); namespace != pm.Namespace {
+		return RestoredManifest{}, fmt.Errorf("namespace changed from %q to %q", pm.Namespace, namespace)
+	}
+	if len(pm.Images) != len(m.ImageTargets) {
+		return RestoredManifest{}, fmt.Errorf("images changed")
+	}
+
+	iTargets := model.ImageTargetsByID(m.ImageTargets)
+	results := store.BuildResultSet{}
+	for _, pi := range pm.Images {
+		iTarget, ok := iTargets[pi.TargetID]
+		if !ok {
+			return RestoredManifest{}, fmt.Errorf("images changed")
+		}
+
+		hash, err := contextHash(iTarget)
+		if err != nil {
+			return RestoredManifest{}, err
+		}
+		if hash != pi.ContextHash {
+			return RestoredManifest{}, fmt.Errorf("files changed for image %s", pi.TargetID.Name)
+		}
+
+		ref, err := container.ParseNamedTagged(pi.Ref)
+		if err != nil {
+			return RestoredManifest{}, err
+		}
+
+		_, _, err = sp.dCli.ImageInspectWithRaw(ctx, ref.String())
+		if err != nil {
+			return RestoredManifest{}, fmt.Errorf("image %s no longer exists: %v", ref, err)
+		}
+
+		result := store.NewImageBuildResult(pi.TargetID, ref)
+		if len(pi.FilesReplaced) > 0 {
+			result.FilesReplacedSet = make(map[string]bool, len(pi.FilesReplaced))
+			for _, f := range pi.FilesReplaced {
+				result.FilesReplacedSet[f] = true
+			}
+		}
+		results[pi.TargetID] = result
+	}
+
+	err = checkEntitiesExist(ctx, kCli, pm)
+	if err != nil {
+		return RestoredManifest{}, err
+	}
+
+	return RestoredManifest{
+		DeployID:   pm.DeployID,
+		DeployTime: pm.DeployTime,
+		Results:    results,
+	}, nil
+}
+
+// The objects in the YAML, with the namespace they'll go to.
+func persistedEntities(kTarget model.K8sTarget, configNamespace k8s.Namespace) ([]store.PersistedEntity, error) {
+	entities, err := k8s.ParseYAMLFromString(kTarget.YAML)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []store.PersistedEntity
+	for _, e := range entities {
+		if e.Kind == nil {
+			continue
+		}
+		m, err := meta.Accessor(e.Obj)
+		if err != nil {
+			return nil, err
+		}
+		namespace := k8s.Namespace(m.GetNamespace())
+		if namespace == "" && !k8s.IsClusterScoped(e) {
+			namespace = configNamespace
+		}
+		result = append(result, store.PersistedEntity{
+			Kind:      e.Kind.Kind,
+			Namespace: namespace,
+			Name:      e.Name(),
+		})
+	}
+	return result, nil
+}
+
+// Checks that the objects we deployed are still in the cluster, with the
+// deploy ID from our last deploy (i.e., nobody deleted them, or deployed
+// over them). We can only look for the namespaced kinds that ListObjects lists;
+// we trust that the rest are still there.
+func checkEntitiesExist(ctx context.Context, kCli k8s.Client, pm store.PersistedManifest) error {
+	var namespaces []k8s.Namespace
+	byNamespace := make(map[k8s.Namespace][]store.PersistedEntity)
+	for _, e := range pm.Entities {
+		if e.Namespace == "" || !k8s.ListsKind(e.Kind) {
+			continue
+		}
+		if _, ok := byNamespace[e.Namespace]; !ok {
+			namespaces = append(namespaces, e.Namespace)
+		}
+		byNamespace[e.Namespace] = append(byNamespace[e.Namespace], e)
+	}
+
+	deployLabel := k8s.TiltDeployLabel(pm.DeployID)
+	selector := labels.Set{deployLabel.Key: deployLabel.Value}.AsSelector()
+	for _, namespace := range namespaces {
+		objects, err := kCli.ListObjects(ctx, namespace, selector)
+		if err != nil {
+			return err
+		}
+
+		found := make(map[string]bool, len(objects))
+		for _, o := range objects {
+			if o.Kind != nil {
+				found[o.Kind.Kind+"/"+o.Name()] = true
+			}
+		}
+		for _, e := range byNamespace[namespace] {
+			if !found[e.Kind+"/"+e.Name] {
+				return fmt.Errorf("%s %s/%s is gone", e.Kind, namespace, e.Name)
+			}
+		}
+	}
+	return nil
+}
+
+var persistSpew = spew.ConfigState{
+	Indent:                  " ",
+	DisableMethods:          true,
+	DisablePointerAddresses: true,
+	DisableCapacities:       true,
+	SortKeys:                true,
+}
+
+func yamlChecksum(k8sTarget model.K8sTarget) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(persistSpew.Sdump(k8sTarget))))
+}
+
+// Hashes the build details of the image, and the path, size, mode,
+// and modification time of every file in its build context.
+func contextHash(iTarget model.ImageTarget) (string, error) {
+	h := sha256.New()
+	_, _ = persistSpew.Fprint(h, iTarget)
+
+	filter := ignore.CreateBuildContextFilter(iTarget)
+	for _, dep := range iTarget.Dependencies() {
+		err := filepath.Walk(dep, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if info.IsDir() {
+				skip, err := model.MatchesEntireDir(filter, path)
+				if err != nil {
+					return err
+				}
+				if skip {
+					return filepath.SkipDir
+				}
+			}
+
+			ignored, err := filter.Matches(path, info.IsDir())
+			if err != nil {
+				return err
+			}
+			if ignored {
+				return nil
+			}
+
+			_, _ = fmt.Fprintf(h, "%s\x00%d\x00%o\x00%d\x00", path, info.Size(), info.Mode(), info.ModTime().UnixNano())
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
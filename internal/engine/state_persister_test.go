@@ -0,0 +1,246 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/windmilleng/wmclient/pkg/dirs"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/docker"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+var persistedRef = container.MustParseNamedTagged("gcr.io/some-project-162817/sancho:tilt-deadbeef")
+
+func TestStatePersisterRestoresUnchangedManifest(t *testing.T) {
+	f := newSPFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	f.save(m, nil)
+
+	restored := f.sp.Restore(f.ctx, f.tiltfilePath(), []model.Manifest{m})
+	iTargetID := m.ImageTargetAt(0).ID()
+	expected := map[model.ManifestName]RestoredManifest{
+		m.Name: {
+			DeployID:   f.deployID,
+			DeployTime: f.deployTime,
+			Results: store.BuildResultSet{
+				iTargetID: store.NewImageBuildResult(iTargetID, persistedRef),
+			},
+		},
+	}
+	assert.Equal(t, expected, restored)
+}
+
+func TestStatePersisterRebuildsChangedFiles(t *testing.T) {
+	f := newSPFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	f.save(m, nil)
+
+	f.WriteFile("new.go", "package main")
+
+	restored := f.sp.Restore(f.ctx, f.tiltfilePath(), []model.Manifest{m})
+	assert.Nil(t, restored)
+}
+
+func TestStatePersisterRebuildsChangedYAML(t *testing.T) {
+	f := newSPFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	f.save(m, nil)
+
+	m = m.WithDeployTarget(model.K8sTarget{YAML: SanchoTwinYAML})
+	restored := f.sp.Restore(f.ctx, f.tiltfilePath(), []model.Manifest{m})
+	assert.Nil(t, restored)
+}
+
+func TestStatePersisterRebuildsMissingImage(t *testing.T) {
+	f := newSPFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	f.save(m, nil)
+
+	delete(f.docker.Images, persistedRef.String())
+
+	restored := f.sp.Restore(f.ctx, f.tiltfilePath(), []model.Manifest{m})
+	assert.Nil(t, restored)
+}
+
+func TestStatePersisterRedeploysDeletedObjects(t *testing.T) {
+	f := newSPFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	f.save(m, nil)
+
+	f.kCli.Objects = nil
+
+	restored := f.sp.Restore(f.ctx, f.tiltfilePath(), []model.Manifest{m})
+	assert.Nil(t, restored)
+}
+
+func TestStatePersisterRedeploysToNewNamespace(t *testing.T) {
+	f := newSPFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	f.save(m, nil)
+
+	f.kCli.ConfigNs = "other-ns"
+
+	restored := f.sp.Restore(f.ctx, f.tiltfilePath(), []model.Manifest{m})
+	assert.Nil(t, restored)
+}
+
+func TestStatePersisterRedeploysToNewKubeContext(t *testing.T) {
+	f := newSPFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	f.save(m, nil)
+
+	sp := NewStatePersister(f.sp.dir, f.docker, k8s.ProvideClientRegistry("docker-for-desktop", f.kCli))
+	restored := sp.Restore(f.ctx, f.tiltfilePath(), []model.Manifest{m})
+	assert.Nil(t, restored)
+}
+
+func TestStatePersisterSkipsFailedBuild(t *testing.T) {
+	f := newSPFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	f.save(m, fmt.Errorf("compile error"))
+
+	restored := f.sp.Restore(f.ctx, f.tiltfilePath(), []model.Manifest{m})
+	assert.Nil(t, restored)
+}
+
+func TestConfigsReloadedRestoresManifest(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	iTargetID := m.ImageTargetAt(0).ID()
+	deployTime := time.Now()
+
+	state := store.NewState()
+	UpperReducer(output.CtxForTest(), state, ConfigsReloadedAction{
+		Manifests: []model.Manifest{m},
+		RestoredManifests: map[model.ManifestName]RestoredManifest{
+			m.Name: {
+				DeployID:   model.DeployID(1234),
+				DeployTime: deployTime,
+				Results: store.BuildResultSet{
+					iTargetID: store.NewImageBuildResult(iTargetID, persistedRef),
+				},
+			},
+		},
+	})
+
+	ms, ok := state.ManifestState(m.Name)
+	if !ok {
+		t.Fatalf("no state for %s", m.Name)
+	}
+	assert.True(t, ms.StartedFirstBuild())
+	assert.Equal(t, model.DeployID(1234), ms.DeployID)
+	assert.Equal(t, deployTime, ms.LastSuccessfulDeployTime)
+	assert.Equal(t, persistedRef, ms.BuildStatuses[iTargetID].LastSuccessfulResult.Image)
+	assert.Equal(t, 0, state.InitialBuildsQueued)
+}
+
+type spFixture struct {
+	*tempdir.TempDirFixture
+	wmDir      *tempdir.TempDirFixture
+	ctx        context.Context
+	docker     *docker.FakeClient
+	kCli       *k8s.FakeK8sClient
+	sp         *StatePersister
+	deployID   model.DeployID
+	deployTime time.Time
+}
+
+func newSPFixture(t *testing.T) *spFixture {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("main.go", "package main")
+
+	wmDir := tempdir.NewTempDirFixture(t)
+	dCli := docker.NewFakeClient()
+	dCli.Images = map[string]types.ImageInspect{
+		persistedRef.String(): {},
+	}
+
+	kCli := k8s.NewFakeK8sClient()
+	clients := k8s.ProvideClientRegistry("gke-dev", kCli)
+
+	return &spFixture{
+		TempDirFixture: f,
+		wmDir:          wmDir,
+		ctx:            output.CtxForTest(),
+		docker:         dCli,
+		kCli:           kCli,
+		sp:             NewStatePersister(dirs.NewWindmillDirAt(wmDir.Path()), dCli, clients),
+		deployID:       model.DeployID(1234),
+		deployTime:     time.Unix(1551202573, 0).UTC(),
+	}
+}
+
+func (f *spFixture) tiltfilePath() string {
+	return f.JoinPath("Tiltfile")
+}
+
+// Save the state of a manifest that we just built with the given error.
+func (f *spFixture) save(m model.Manifest, buildErr error) {
+	state := store.NewState()
+	state.TiltfilePath = f.tiltfilePath()
+	state.CompletedBuildCount = 1
+
+	mt := store.NewManifestTarget(m)
+	ms := mt.State
+	ms.DeployID = f.deployID
+	ms.LastSuccessfulDeployTime = f.deployTime
+	for _, iTarget := range m.ImageTargets {
+		ms.MutableBuildStatus(iTarget.ID()).LastSuccessfulResult = store.NewImageBuildResult(iTarget.ID(), persistedRef)
+	}
+	ms.AddCompletedBuild(model.BuildRecord{
+		StartTime:  f.deployTime,
+		FinishTime: f.deployTime,
+		Error:      buildErr,
+	})
+	state.UpsertManifestTarget(mt)
+
+	st := store.NewTestingStore()
+	st.SetState(*state)
+	f.sp.OnChange(f.ctx, st)
+
+	// And the objects we deployed are in the cluster.
+	entities, err := k8s.ParseYAMLFromString(m.K8sTarget().YAML)
+	if err != nil {
+		f.T().Fatal(err)
+	}
+	for _, e := range entities {
+		e, err = k8s.InjectLabels(e, []model.LabelPair{k8s.TiltDeployLabel(f.deployID)})
+		if err != nil {
+			f.T().Fatal(err)
+		}
+		f.kCli.Objects = append(f.kCli.Objects, e)
+	}
+}
+
+func (f *spFixture) TearDown() {
+	f.TempDirFixture.TearDown()
+	f.wmDir.TearDown()
+}
@@ -11,34 +11,60 @@ func ProvideSubscribers(
 	hud hud.HeadsUpDisplay,
 	pw *PodWatcher,
 	sw *ServiceWatcher,
+	ew *EventWatcher,
+	rsw *ReplicaSetWatcher,
+	kcw *KubeContextWatcher,
+	pmw *PodMetricsWatcher,
+	dw *DriftWatcher,
+	oc *OrphanCollector,
+	tdc *TearDownController,
+	rstc *RestartController,
 	plm *PodLogManager,
 	pfc *PortForwardController,
+	rc *ReadinessChecker,
+	esc *EventSinkController,
+	lsc *LocalServeController,
 	fwm *WatchManager,
 	bc *BuildController,
 	ic *ImageController,
 	cc *ConfigsController,
+	sp *StatePersister,
 	dcw *DockerComposeEventWatcher,
 	dclm *DockerComposeLogManager,
 	pm *ProfilerManager,
 	sm SyncletManager,
 	ar *AnalyticsReporter,
+	mr *MetricsReporter,
 	hudsc *server.HeadsUpServerController,
 	sail client.SailClient) []store.Subscriber {
 	return []store.Subscriber{
 		hud,
 		pw,
 		sw,
+		ew,
+		rsw,
+		kcw,
+		pmw,
+		dw,
+		oc,
+		tdc,
+		rstc,
 		plm,
 		pfc,
+		rc,
+		esc,
+		lsc,
 		fwm,
 		bc,
 		ic,
 		cc,
+		sp,
 		dcw,
 		dclm,
 		pm,
 		sm,
 		ar,
+		mr,
 		hudsc,
 		sail,
 	}
@@ -22,14 +22,14 @@ var _ BuildAndDeployer = &SyncletBuildAndDeployer{}
 
 type SyncletBuildAndDeployer struct {
 	sm         SyncletManager
-	kCli       k8s.Client
+	clients    *k8s.ClientRegistry
 	updateMode UpdateMode
 }
 
-func NewSyncletBuildAndDeployer(sm SyncletManager, kCli k8s.Client, updateMode UpdateMode) *SyncletBuildAndDeployer {
+func NewSyncletBuildAndDeployer(sm SyncletManager, clients *k8s.ClientRegistry, updateMode UpdateMode) *SyncletBuildAndDeployer {
 	return &SyncletBuildAndDeployer{
 		sm:         sm,
-		kCli:       kCli,
+		clients:    clients,
 		updateMode: updateMode,
 	}
 }
@@ -46,7 +46,8 @@ func (sbd *SyncletBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store
 
 	liveUpdateState := liveUpdateStateSet[0]
 	iTarget := liveUpdateState.iTarget
-	if !isImageDeployedToK8s(iTarget, model.ExtractK8sTargets(specs)) {
+	kTarget, ok := k8sTargetForImage(iTarget, model.ExtractK8sTargets(specs))
+	if !ok {
 		return store.BuildResultSet{}, SilentRedirectToNextBuilderf("Synclet container builder can only deploy to k8s")
 	}
 
@@ -54,10 +55,11 @@ func (sbd *SyncletBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store
 	span.SetTag("target", iTarget.ConfigurationRef.String())
 	defer span.Finish()
 
-	return sbd.UpdateInCluster(ctx, liveUpdateState)
+	return sbd.UpdateInCluster(ctx, k8s.ConnectionForTarget(kTarget), liveUpdateState)
 }
 
-func (sbd *SyncletBuildAndDeployer) UpdateInCluster(ctx context.Context,
+// Updates the container in place, in the cluster that conn connects to.
+func (sbd *SyncletBuildAndDeployer) UpdateInCluster(ctx context.Context, conn k8s.Connection,
 	liveUpdateState liveUpdateStateTree) (store.BuildResultSet, error) {
 	var err error
 	var changedMappings []build.PathMapping
@@ -102,16 +104,21 @@ func (sbd *SyncletBuildAndDeployer) UpdateInCluster(ctx context.Context,
 		hotReload = !luInfo.ShouldRestart()
 	}
 
-	err = sbd.updateInCluster(ctx, iTarget, state, changedMappings, runs, hotReload)
+	err = sbd.updateInCluster(ctx, conn, iTarget, state, changedMappings, runs, hotReload)
 	if err != nil {
 		return store.BuildResultSet{}, err
 	}
 	return liveUpdateState.createResultSet(), nil
 }
 
-func (sbd *SyncletBuildAndDeployer) updateInCluster(ctx context.Context, iTarget model.ImageTarget, state store.BuildState, changedMappings []build.PathMapping, runs []model.Run, hotReload bool) error {
+func (sbd *SyncletBuildAndDeployer) updateInCluster(ctx context.Context, conn k8s.Connection, iTarget model.ImageTarget, state store.BuildState, changedMappings []build.PathMapping, runs []model.Run, hotReload bool) error {
 	l := logger.Get(ctx)
 
+	kCli, err := sbd.clients.ClientFor(ctx, conn)
+	if err != nil {
+		return err
+	}
+
 	// get files to rm
 	toRemove, toArchive, err := build.MissingLocalPaths(ctx, changedMappings)
 	if err != nil {
@@ -153,14 +160,14 @@ func (sbd *SyncletBuildAndDeployer) updateInCluster(ctx context.Context, iTarget
 	}
 
 	// TODO(dbentley): it would be even better to check if the pod has the sidecar
-	if sbd.updateMode == UpdateModeKubectlExec || sbd.kCli.ContainerRuntime(ctx) != container.RuntimeDocker {
-		if err := sbd.updateViaExec(ctx,
+	if sbd.updateMode == UpdateModeKubectlExec || kCli.ContainerRuntime(ctx) != container.RuntimeDocker {
+		if err := sbd.updateViaExec(ctx, kCli,
 			deployInfo.PodID, deployInfo.Namespace, deployInfo.ContainerName,
 			archive, archivePaths, containerPathsToRm, cmds, hotReload); err != nil {
 			return err
 		}
 	} else {
-		if err := sbd.updateViaSynclet(ctx,
+		if err := sbd.updateViaSynclet(ctx, conn,
 			deployInfo.PodID, deployInfo.Namespace, deployInfo.ContainerID,
 			archive, containerPathsToRm, cmds, hotReload); err != nil {
 			return err
@@ -170,12 +177,12 @@ func (sbd *SyncletBuildAndDeployer) updateInCluster(ctx context.Context, iTarget
 	return nil
 }
 
-func (sbd *SyncletBuildAndDeployer) updateViaSynclet(ctx context.Context,
+func (sbd *SyncletBuildAndDeployer) updateViaSynclet(ctx context.Context, conn k8s.Connection,
 	podID k8s.PodID, namespace k8s.Namespace, containerID container.ID,
 	archive *bytes.Buffer, filesToDelete []string, cmds []model.Cmd, hotReload bool) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "SyncletBuildAndDeployer-updateViaSynclet")
 	defer span.Finish()
-	sCli, err := sbd.sm.ClientForPod(ctx, podID, namespace)
+	sCli, err := sbd.sm.ClientForPod(ctx, conn, podID, namespace)
 	if err != nil {
 		return err
 	}
@@ -187,7 +194,7 @@ func (sbd *SyncletBuildAndDeployer) updateViaSynclet(ctx context.Context,
 	return err
 }
 
-func (sbd *SyncletBuildAndDeployer) updateViaExec(ctx context.Context,
+func (sbd *SyncletBuildAndDeployer) updateViaExec(ctx context.Context, kCli k8s.Client,
 	podID k8s.PodID, namespace k8s.Namespace, container container.Name,
 	archive *bytes.Buffer, archivePaths []string, filesToDelete []string, cmds []model.Cmd, hotReload bool) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "SyncletBuildAndDeployer-updateViaExec")
@@ -206,7 +213,7 @@ func (sbd *SyncletBuildAndDeployer) updateViaExec(ctx context.Context,
 		}
 
 		l.Infof("removing %v files %v", len(filesToDelete), filesToShow)
-		if err := sbd.kCli.Exec(ctx, podID, container, namespace,
+		if err := kCli.Exec(ctx, podID, container, namespace,
 			append([]string{"rm", "-rf"}, filesToDelete...), nil, w, w); err != nil {
 			return err
 		}
@@ -219,7 +226,7 @@ func (sbd *SyncletBuildAndDeployer) updateViaExec(ctx context.Context,
 			filesToShow = append(filesToShow, "...")
 		}
 		l.Infof("updating %v files %v", len(archivePaths), filesToShow)
-		if err := sbd.kCli.Exec(ctx, podID, container, namespace,
+		if err := kCli.Exec(ctx, podID, container, namespace,
 			[]string{"tar", "-x", "-f", "/dev/stdin"}, archive, w, w); err != nil {
 			return err
 		}
@@ -227,7 +234,7 @@ func (sbd *SyncletBuildAndDeployer) updateViaExec(ctx context.Context,
 
 	for i, c := range cmds {
 		l.Infof("[CMD %d/%d] %s", i+1, len(cmds), strings.Join(c.Argv, " "))
-		if err := sbd.kCli.Exec(ctx, podID, container, namespace,
+		if err := kCli.Exec(ctx, podID, container, namespace,
 			c.Argv, nil, w, w); err != nil {
 			return WrapDontFallBackError(err)
 		}
@@ -22,10 +22,10 @@ import (
 
 type newCliFn func(ctx context.Context, kCli k8s.Client, podID k8s.PodID, ns k8s.Namespace) (synclet.SyncletClient, error)
 type SyncletManager struct {
-	kCli      k8s.Client
-	mutex     *sync.Mutex
-	clients   map[k8s.PodID]synclet.SyncletClient
-	newClient newCliFn
+	k8sClients *k8s.ClientRegistry
+	mutex      *sync.Mutex
+	clients    map[k8s.PodID]synclet.SyncletClient
+	newClient  newCliFn
 
 	// Ensures that we don't try to setup a client multiple times if it keeps failing.
 	clientWarmAttempted map[k8s.PodID]bool
@@ -49,9 +49,9 @@ func (t tunneledSyncletClient) Close() error {
 	return nil
 }
 
-func NewSyncletManager(kCli k8s.Client) SyncletManager {
+func NewSyncletManager(clients *k8s.ClientRegistry) SyncletManager {
 	return SyncletManager{
-		kCli:                kCli,
+		k8sClients:          clients,
 		mutex:               new(sync.Mutex),
 		clients:             make(map[k8s.PodID]synclet.SyncletClient),
 		clientWarmAttempted: make(map[k8s.PodID]bool),
@@ -70,7 +70,7 @@ func NewSyncletManagerForTests(kCli k8s.Client, fakeCli synclet.SyncletClient) S
 	}
 
 	return SyncletManager{
-		kCli:                kCli,
+		k8sClients:          k8s.NewClientRegistryForTests(kCli),
 		mutex:               new(sync.Mutex),
 		clients:             make(map[k8s.PodID]synclet.SyncletClient),
 		clientWarmAttempted: make(map[k8s.PodID]bool),
@@ -79,6 +79,7 @@ func NewSyncletManagerForTests(kCli k8s.Client, fakeCli synclet.SyncletClient) S
 }
 
 type syncletEntry struct {
+	Conn      k8s.Connection
 	PodID     k8s.PodID
 	Namespace k8s.Namespace
 }
@@ -96,8 +97,12 @@ func (sm SyncletManager) diff(ctx context.Context, st store.RStore) (setup []syn
 
 	// Look for all the pods that have synclets, and
 	// start warming the connection.
-	for _, ms := range state.ManifestStates() {
-		for _, pod := range ms.PodSet.Pods {
+	for _, mt := range state.Targets() {
+		var conn k8s.Connection
+		if mt.Manifest.IsK8s() {
+			conn = k8s.ConnectionForTarget(mt.Manifest.K8sTarget())
+		}
+		for _, pod := range mt.State.PodSet.Pods {
 			if !pod.HasSynclet {
 				continue
 			}
@@ -111,6 +116,7 @@ func (sm SyncletManager) diff(ctx context.Context, st store.RStore) (setup []syn
 
 			sm.clientWarmAttempted[id] = true
 			setup = append(setup, syncletEntry{
+				Conn:      conn,
 				PodID:     pod.PodID,
 				Namespace: pod.Namespace,
 			})
@@ -141,26 +147,32 @@ func (sm SyncletManager) OnChange(ctx context.Context, store store.RStore) {
 
 	for _, entry := range setup {
 		logger.Get(ctx).Debugf("Warming connection to synclet: %s", entry.PodID)
-		_, err := sm.clientForPodInternal(ctx, entry.PodID, entry.Namespace)
+		_, err := sm.clientForPodInternal(ctx, entry.Conn, entry.PodID, entry.Namespace)
 		if err != nil {
 			logger.Get(ctx).Infof("Warming Synclet: %v", err)
 		}
 	}
 }
 
-func (sm SyncletManager) ClientForPod(ctx context.Context, podID k8s.PodID, ns k8s.Namespace) (synclet.SyncletClient, error) {
+// Returns a client for the synclet in the pod, which we reach with the given connection.
+func (sm SyncletManager) ClientForPod(ctx context.Context, conn k8s.Connection, podID k8s.PodID, ns k8s.Namespace) (synclet.SyncletClient, error) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
-	return sm.clientForPodInternal(ctx, podID, ns)
+	return sm.clientForPodInternal(ctx, conn, podID, ns)
 }
 
-func (sm SyncletManager) clientForPodInternal(ctx context.Context, podID k8s.PodID, ns k8s.Namespace) (synclet.SyncletClient, error) {
+func (sm SyncletManager) clientForPodInternal(ctx context.Context, conn k8s.Connection, podID k8s.PodID, ns k8s.Namespace) (synclet.SyncletClient, error) {
 	client, ok := sm.clients[podID]
 	if ok {
 		return client, nil
 	}
 
-	client, err := sm.newClient(ctx, sm.kCli, podID, ns)
+	kCli, err := sm.k8sClients.ClientFor(ctx, conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating synclet client")
+	}
+
+	client, err = sm.newClient(ctx, kCli, podID, ns)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating synclet client")
 	}
@@ -197,12 +209,12 @@ func newSyncletClient(ctx context.Context, kCli k8s.Client, podID k8s.PodID, ns
 	}
 
 	// TODO(nick): We need a better way to kill the client when the pod dies.
-	tunneledPort, tunnelCloser, err := kCli.ForwardPort(ctx, ns, podID, 0, synclet.Port)
+	tunnel, err := kCli.ForwardPort(ctx, ns, podID, 0, synclet.Port)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed opening tunnel to synclet pod '%s'", podID)
 	}
 
-	logger.Get(ctx).Verbosef("tunneling to synclet client at %s (local port %d)", podID.String(), tunneledPort)
+	logger.Get(ctx).Verbosef("tunneling to synclet client at %s (local port %d)", podID.String(), tunnel.LocalPort)
 
 	t := opentracing.GlobalTracer()
 
@@ -210,10 +222,10 @@ func newSyncletClient(ctx context.Context, kCli k8s.Client, podID k8s.PodID, ns
 	opts = append(opts, grpc.WithInsecure())
 	opts = append(opts, options.TracingInterceptorsDial(t)...)
 
-	conn, err := grpc.DialContext(ctx, fmt.Sprintf("127.0.0.1:%d", tunneledPort), opts...)
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("127.0.0.1:%d", tunnel.LocalPort), opts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "connecting to synclet")
 	}
 
-	return tunneledSyncletClient{synclet.NewGRPCClient(conn), tunnelCloser}, nil
+	return tunneledSyncletClient{synclet.NewGRPCClient(conn), tunnel.Close}, nil
 }
@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// Deletes the k8s objects of the given manifests, in an order that
+// doesn't leave anything orphaned (see k8s.DeletionGroups).
+//
+// Also uninstalls Helm releases, deletes the image pull secrets that Tilt
+// created, and deletes the namespaces that Tilt created.
+func TearDownK8s(ctx context.Context, kCli k8s.Client, manifests []model.Manifest, opts TearDownOptions) error {
+	return tearDownK8s(ctx, kCli, manifests, opts, true)
+}
+
+// Like TearDownK8s, but for only some of the resources in a Tiltfile,
+// so leaves their namespaces alone, in case the other resources use them.
+func TearDownK8sResources(ctx context.Context, kCli k8s.Client, manifests []model.Manifest, opts TearDownOptions) error {
+	opts.DeleteNamespaces = false
+	return tearDownK8s(ctx, kCli, manifests, opts, false)
+}
+
+type TearDownOptions struct {
+	// Delete every namespace that the manifests explicitly deploy to
+	// (except system namespaces). Objects without a namespace go wherever
+	// the kubeconfig points, which isn't ours to delete.
+	DeleteNamespaces bool
+
+	// Delete the volume claims that StatefulSets leave behind. They hold
+	// the StatefulSets' data, so we keep them unless asked.
+	DeleteVolumeClaims bool
+}
+
+func tearDownK8s(ctx context.Context, kCli k8s.Client, manifests []model.Manifest, opts TearDownOptions, deleteTiltNamespaces bool) error {
+	l := logger.Get(ctx)
+
+	parsed, err := ParseYAMLFromManifests(manifests...)
+	if err != nil {
+		return errors.Wrap(err, "Parsing manifest YAML")
+	}
+
+	var entities []k8s.K8sEntity
+	declaredNamespaces := map[k8s.Namespace]bool{}
+	for _, e := range parsed {
+		if e.KeptByHelm() {
+			l.Infof("Keeping %s/%s (helm.sh/resource-policy: keep)", e.Kind.Kind, e.Name())
+			continue
+		}
+		if e.HasKind("Namespace") {
+			declaredNamespaces[k8s.Namespace(e.Name())] = true
+		}
+		entities = append(entities, e)
+	}
+
+	secrets, err := imagePullSecrets(manifests)
+	if err != nil {
+		return err
+	}
+	entities = append(entities, secrets...)
+
+	if opts.DeleteVolumeClaims {
+		claims, err := statefulSetVolumeClaims(ctx, kCli, entities)
+		if err != nil {
+			l.Infof("error finding volume claims: %v", err)
+		}
+		entities = append(entities, claims...)
+	}
+
+	// Namespaces that Tilt created for a resource, to clean up after everything else.
+	var tiltNamespaces []k8s.Namespace
+	seenNamespaces := map[k8s.Namespace]bool{}
+	for _, m := range manifests {
+		ns := k8s.Namespace(m.K8sTarget().Namespace)
+		if ns == "" || seenNamespaces[ns] {
+			continue
+		}
+		seenNamespaces[ns] = true
+		tiltNamespaces = append(tiltNamespaces, ns)
+	}
+
+	if opts.DeleteNamespaces {
+		for _, e := range entities {
+			ns := e.ExplicitNamespace()
+			if ns == "" || k8s.IsSystemNamespace(ns) || declaredNamespaces[ns] {
+				continue
+			}
+			declaredNamespaces[ns] = true
+			entities = append(entities, k8s.NewNamespaceEntity(ns))
+		}
+	}
+
+	var firstErr error
+
+	// Uninstall Helm releases with Helm, so that it runs the charts' delete hooks.
+	for _, m := range manifests {
+		release := m.K8sTarget().HelmRelease
+		if release == nil {
+			continue
+		}
+		err := kCli.HelmUninstall(ctx, *release)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, group := range k8s.DeletionGroups(entities) {
+		err := kCli.Delete(ctx, group)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, ns := range tiltNamespaces {
+		if !deleteTiltNamespaces || declaredNamespaces[ns] {
+			continue
+		}
+		err := kCli.DeleteNamespaceIfCreatedByTilt(ctx, ns)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// The image pull secrets that Tilt creates when it deploys (see injectImagePullSecret),
+// which aren't in the manifests' YAML: one in each namespace with pods that use it.
+func imagePullSecrets(manifests []model.Manifest) ([]k8s.K8sEntity, error) {
+	var result []k8s.K8sEntity
+	for _, m := range manifests {
+		name := m.K8sTarget().ImagePullSecret
+		if name == "" {
+			continue
+		}
+
+		entities, err := ParseYAMLFromManifests(m)
+		if err != nil {
+			return nil, errors.Wrap(err, "Parsing manifest YAML")
+		}
+
+		seen := map[k8s.Namespace]bool{}
+		for _, e := range entities {
+			pods, err := k8s.ExtractPods(&e)
+			if err != nil {
+				return nil, err
+			}
+			if len(pods) == 0 || seen[e.Namespace()] {
+				continue
+			}
+			seen[e.Namespace()] = true
+			result = append(result, k8s.NewImagePullSecret(name, e.Namespace(), nil))
+		}
+	}
+	return result, nil
+}
+
+func statefulSetVolumeClaims(ctx context.Context, kCli k8s.Client, entities []k8s.K8sEntity) ([]k8s.K8sEntity, error) {
+	var namespaces []k8s.Namespace
+	seen := map[k8s.Namespace]bool{}
+	for _, e := range entities {
+		if !e.HasKind("StatefulSet") || seen[e.Namespace()] {
+			continue
+		}
+		seen[e.Namespace()] = true
+		namespaces = append(namespaces, e.Namespace())
+	}
+
+	var result []k8s.K8sEntity
+	for _, ns := range namespaces {
+		claims, err := kCli.ListVolumeClaims(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, k8s.StatefulSetVolumeClaims(entities, claims)...)
+	}
+	return result, nil
+}
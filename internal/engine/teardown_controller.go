@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+// Deletes the objects of resources that the user tore down during the session
+// (e.g., from the HUD), once they've stopped building.
+type TearDownController struct {
+	clients *k8s.ClientRegistry
+	dcc     dockercompose.DockerComposeClient
+
+	started map[model.ManifestName]bool
+}
+
+func NewTearDownController(clients *k8s.ClientRegistry, dcc dockercompose.DockerComposeClient) *TearDownController {
+	return &TearDownController{
+		clients: clients,
+		dcc:     dcc,
+		started: make(map[model.ManifestName]bool),
+	}
+}
+
+// The torn-down resources that we haven't started deleting yet.
+func (c *TearDownController) pending(st store.RStore) []model.Manifest {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	// A resource that we tore down once (e.g., when the user disabled it)
+	// can be torn down again later.
+	stillPending := make(map[model.ManifestName]bool)
+	for _, m := range state.PendingTearDowns {
+		stillPending[m.Name] = true
+	}
+	for name := range c.started {
+		if !stillPending[name] {
+			delete(c.started, name)
+		}
+	}
+
+	var result []model.Manifest
+	for _, m := range state.PendingTearDowns {
+		// Wait for the build to finish canceling, so that it doesn't deploy
+		// the objects again after we delete them.
+		if c.started[m.Name] || state.CurrentlyBuilding[m.Name] {
+			continue
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// Deleting can take a while (kubectl waits for the objects to go away),
+// so we do it in the background, and the other resources keep updating.
+func (c *TearDownController) OnChange(ctx context.Context, st store.RStore) {
+	for _, m := range c.pending(st) {
+		c.started[m.Name] = true
+		go func(m model.Manifest) {
+			err := c.tearDown(ctx, m)
+			st.Dispatch(TearDownCompleteAction{ManifestName: m.Name, Error: err})
+		}(m)
+	}
+}
+
+func (c *TearDownController) tearDown(ctx context.Context, m model.Manifest) error {
+	if m.IsDC() {
+		dcTarget := m.DockerComposeTarget()
+		w := logger.Get(ctx).Writer(logger.InfoLvl)
+		return c.dcc.Rm(ctx, dcTarget.ConfigPath, []model.TargetName{dcTarget.Name}, w, w)
+	}
+
+	if !m.IsK8s() {
+		return nil
+	}
+
+	kCli, err := c.clients.ClientFor(ctx, k8s.ConnectionForTarget(m.K8sTarget()))
+	if err != nil {
+		return err
+	}
+	return TearDownK8sResources(ctx, kCli, []model.Manifest{m}, TearDownOptions{})
+}
+
+var _ store.Subscriber = &TearDownController{}
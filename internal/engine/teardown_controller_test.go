@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestHandleTearDownAction(t *testing.T) {
+	ctx := output.CtxForTest()
+	state := store.NewState()
+	sancho := k8s.NewK8sOnlyManifestForTesting(testyaml.SanchoYAML, nil)
+	doggos := k8s.NewK8sOnlyManifestForTesting(testyaml.DoggosDeploymentYaml, nil)
+	doggos.Name = "doggos"
+	state.UpsertManifestTarget(store.NewManifestTarget(sancho))
+	state.UpsertManifestTarget(store.NewManifestTarget(doggos))
+	state.ManifestDefinitionOrder = []model.ManifestName{sancho.Name, doggos.Name}
+	state.TriggerQueue = []model.ManifestName{sancho.Name}
+
+	handleTearDownAction(ctx, state, view.TearDownAction{Name: sancho.Name})
+	assert.Equal(t, []model.ManifestName{doggos.Name}, state.ManifestDefinitionOrder)
+	assert.Empty(t, state.TriggerQueue)
+	assert.True(t, state.TornDownManifests[sancho.Name])
+	if assert.Equal(t, 1, len(state.PendingTearDowns)) {
+		assert.Equal(t, sancho.Name, state.PendingTearDowns[0].Name)
+	}
+
+	// Reloading the Tiltfile doesn't bring it back.
+	handleConfigsReloaded(ctx, state, ConfigsReloadedAction{Manifests: []model.Manifest{sancho, doggos}})
+	assert.Equal(t, []model.ManifestName{doggos.Name}, state.ManifestDefinitionOrder)
+
+	handleTearDownCompleteAction(ctx, state, TearDownCompleteAction{ManifestName: sancho.Name})
+	assert.Empty(t, state.PendingTearDowns)
+}
+
+func TestDisableAndEnableResource(t *testing.T) {
+	ctx := output.CtxForTest()
+	state := store.NewState()
+	sancho := k8s.NewK8sOnlyManifestForTesting(testyaml.SanchoYAML, nil)
+	mt := store.NewManifestTarget(sancho)
+	mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	mt.State.PendingManifestChange = time.Now()
+	state.UpsertManifestTarget(mt)
+	state.TriggerQueue = []model.ManifestName{sancho.Name}
+
+	handleDisableResourceAction(ctx, state, view.DisableResourceAction{Name: sancho.Name, DeleteObjects: true})
+	assert.True(t, mt.State.Disabled)
+	assert.Empty(t, state.TriggerQueue)
+	assert.Empty(t, state.EnabledManifests())
+	assert.Equal(t, 1, len(state.PendingTearDowns))
+	assert.Equal(t, model.ManifestName(""), nextManifestNameToBuild(*state))
+
+	// It stays in the session, so we can turn it back on.
+	assert.Equal(t, []model.Manifest{sancho}, state.Manifests())
+
+	handleEnableResourceAction(ctx, state, view.EnableResourceAction{Name: sancho.Name})
+	assert.False(t, mt.State.Disabled)
+	assert.Equal(t, sancho.Name, nextManifestNameToBuild(*state))
+}
+
+func TestTearDownControllerWaitsForBuild(t *testing.T) {
+	f := newTearDownControllerFixture(t)
+	m := k8s.NewK8sOnlyManifestForTesting(testyaml.SanchoYAML, nil)
+
+	state := store.NewState()
+	state.PendingTearDowns = []model.Manifest{m}
+	state.CurrentlyBuilding[m.Name] = true
+	f.st.SetState(*state)
+
+	f.c.OnChange(output.CtxForTest(), f.st)
+	assert.Empty(t, f.kCli.DeleteCalls)
+
+	delete(state.CurrentlyBuilding, m.Name)
+	f.st.SetState(*state)
+	f.c.OnChange(output.CtxForTest(), f.st)
+	actions := f.st.WaitForActions(t, 1)
+	assert.Equal(t, [][]string{{"Deployment/sancho"}}, deleteCallNames(f.kCli))
+	assert.Equal(t, []store.Action{TearDownCompleteAction{ManifestName: m.Name}}, actions)
+
+	// Only tear down once, even if we haven't seen the TearDownCompleteAction yet.
+	f.c.OnChange(output.CtxForTest(), f.st)
+	assert.Equal(t, 1, len(f.kCli.DeleteCalls))
+
+	// Once it's done, we can tear it down again (e.g., if the user disables it a second time).
+	state.PendingTearDowns = nil
+	f.st.SetState(*state)
+	f.c.OnChange(output.CtxForTest(), f.st)
+
+	state.PendingTearDowns = []model.Manifest{m}
+	f.st.SetState(*state)
+	f.c.OnChange(output.CtxForTest(), f.st)
+	f.st.WaitForActions(t, 2)
+	assert.Equal(t, 2, len(f.kCli.DeleteCalls))
+}
+
+func TestTearDownControllerDockerCompose(t *testing.T) {
+	f := newTearDownControllerFixture(t)
+	m := model.Manifest{Name: "web"}.WithDeployTarget(model.DockerComposeTarget{Name: "web", ConfigPath: "docker-compose.yml"})
+
+	state := store.NewState()
+	state.PendingTearDowns = []model.Manifest{m}
+	f.st.SetState(*state)
+
+	f.c.OnChange(output.CtxForTest(), f.st)
+	f.st.WaitForActions(t, 1)
+	assert.Equal(t, [][]model.TargetName{{"web"}}, f.dcc.RmCalls)
+}
+
+type tearDownControllerFixture struct {
+	c    *TearDownController
+	st   *store.TestingStore
+	kCli *k8s.FakeK8sClient
+	dcc  *dockercompose.FakeDCClient
+}
+
+func newTearDownControllerFixture(t *testing.T) *tearDownControllerFixture {
+	kCli := k8s.NewFakeK8sClient()
+	dcc := dockercompose.NewFakeDockerComposeClient(t, output.CtxForTest())
+	return &tearDownControllerFixture{
+		c:    NewTearDownController(k8s.NewClientRegistryForTests(kCli), dcc),
+		st:   store.NewTestingStore(),
+		kCli: kCli,
+		dcc:  dcc,
+	}
+}
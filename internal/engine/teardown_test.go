@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestTearDownK8sInOrder(t *testing.T) {
+	kCli := &k8s.FakeK8sClient{}
+	kCli.VolumeClaims = []v1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-data-test-redis-master-0", Namespace: "default"}},
+	}
+
+	yaml := strings.Join([]string{testyaml.SecretYaml, testyaml.RedisStatefulSetYAML}, "\n---\n")
+	manifests := []model.Manifest{k8s.NewK8sOnlyManifestForTesting(yaml, nil)}
+
+	err := TearDownK8s(output.CtxForTest(), kCli, manifests, TearDownOptions{DeleteVolumeClaims: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, [][]string{
+		{"StatefulSet/test-redis-master"},
+		{"Secret/mysecret", "PersistentVolumeClaim/redis-data-test-redis-master-0"},
+	}, deleteCallNames(kCli))
+	assert.Empty(t, kCli.DeletedNamespaces)
+}
+
+func TestTearDownK8sKeepsVolumeClaims(t *testing.T) {
+	kCli := &k8s.FakeK8sClient{}
+	kCli.VolumeClaims = []v1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-data-test-redis-master-0", Namespace: "default"}},
+	}
+	manifests := []model.Manifest{k8s.NewK8sOnlyManifestForTesting(testyaml.RedisStatefulSetYAML, nil)}
+
+	err := TearDownK8s(output.CtxForTest(), kCli, manifests, TearDownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, [][]string{{"StatefulSet/test-redis-master"}}, deleteCallNames(kCli))
+}
+
+func TestTearDownK8sKeepsHelmResources(t *testing.T) {
+	kCli := &k8s.FakeK8sClient{}
+	kept := strings.Replace(testyaml.SecretYaml, "name: mysecret", `name: mysecret
+  annotations:
+    helm.sh/resource-policy: keep`, 1)
+	yaml := strings.Join([]string{kept, testyaml.SanchoYAML}, "\n---\n")
+	manifests := []model.Manifest{k8s.NewK8sOnlyManifestForTesting(yaml, nil)}
+
+	err := TearDownK8s(output.CtxForTest(), kCli, manifests, TearDownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, [][]string{{"Deployment/sancho"}}, deleteCallNames(kCli))
+}
+
+func TestTearDownK8sNamespaces(t *testing.T) {
+	kCli := &k8s.FakeK8sClient{}
+	m := k8s.NewK8sOnlyManifestForTesting(testyaml.SanchoYAML, nil)
+	m = m.WithDeployTarget(m.K8sTarget().WithNamespace("my-ns"))
+
+	err := TearDownK8s(output.CtxForTest(), kCli, []model.Manifest{m}, TearDownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []k8s.Namespace{"my-ns"}, kCli.DeletedNamespaces)
+}
+
+func TestTearDownK8sResourcesKeepsNamespaces(t *testing.T) {
+	kCli := &k8s.FakeK8sClient{}
+	m := k8s.NewK8sOnlyManifestForTesting(testyaml.SanchoYAML, nil)
+	m = m.WithDeployTarget(m.K8sTarget().WithNamespace("my-ns"))
+
+	err := TearDownK8sResources(output.CtxForTest(), kCli, []model.Manifest{m}, TearDownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, [][]string{{"Deployment/sancho"}}, deleteCallNames(kCli))
+	assert.Empty(t, kCli.DeletedNamespaces)
+}
+
+func TestTearDownK8sDeleteNamespaces(t *testing.T) {
+	kCli := &k8s.FakeK8sClient{ConfigNs: "my-kubeconfig-ns"}
+	yaml := strings.Replace(testyaml.SanchoYAML, "namespace: sancho-ns", "namespace: my-ns", 1)
+	manifests := []model.Manifest{
+		k8s.NewK8sOnlyManifestForTesting(yaml, nil),
+		k8s.NewK8sOnlyManifestForTesting(testyaml.SecretYaml, nil),
+	}
+
+	err := TearDownK8s(output.CtxForTest(), kCli, manifests, TearDownOptions{DeleteNamespaces: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The secret doesn't set a namespace, so it goes in the kubeconfig's namespace,
+	// which we leave alone.
+	assert.Equal(t, [][]string{
+		{"Deployment/sancho"},
+		{"Secret/mysecret"},
+		{"Namespace/my-ns"},
+	}, deleteCallNames(kCli))
+}
+
+func TestTearDownK8sImagePullSecrets(t *testing.T) {
+	kCli := &k8s.FakeK8sClient{}
+	yaml := strings.Join([]string{testyaml.SecretYaml, testyaml.SanchoYAML}, "\n---\n")
+	m := k8s.NewK8sOnlyManifestForTesting(yaml, nil)
+	m = m.WithDeployTarget(m.K8sTarget().WithImagePullSecret("tilt-registry"))
+
+	err := TearDownK8s(output.CtxForTest(), kCli, []model.Manifest{m}, TearDownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The pull secret goes in the namespace of the pods that use it, not the other secret's.
+	assert.Equal(t, [][]string{
+		{"Deployment/sancho"},
+		{"Secret/mysecret", "Secret/tilt-registry"},
+	}, deleteCallNames(kCli))
+	assert.Equal(t, "sancho-ns", kCli.DeleteCalls[1][1].Namespace().String())
+}
+
+func TestTearDownK8sHelmRelease(t *testing.T) {
+	kCli := &k8s.FakeK8sClient{}
+	release := model.HelmRelease{Name: "frontend", Chart: "stable/frontend"}
+	m := model.Manifest{Name: "frontend"}.
+		WithDeployTarget(model.K8sTarget{Name: "frontend"}.WithHelmRelease(release))
+
+	err := TearDownK8s(output.CtxForTest(), kCli, []model.Manifest{m}, TearDownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []model.HelmRelease{release}, kCli.UninstalledHelmReleases)
+	assert.Empty(t, kCli.DeleteCalls)
+}
+
+func deleteCallNames(kCli *k8s.FakeK8sClient) [][]string {
+	var result [][]string
+	for _, call := range kCli.DeleteCalls {
+		var names []string
+		for _, e := range call {
+			names = append(names, e.Kind.Kind+"/"+e.Name())
+		}
+		result = append(result, names)
+	}
+	return result
+}
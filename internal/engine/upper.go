@@ -2,14 +2,13 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"strconv"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
-
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/windmilleng/tilt/internal/sail/client"
@@ -23,6 +22,7 @@ import (
 	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/logger"
 	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/ospath"
 	"github.com/windmilleng/tilt/internal/sliceutils"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/internal/synclet/sidecar"
@@ -46,20 +46,38 @@ var watchBufferMaxDuration = watchBufferMaxTimeInMs * time.Millisecond
 // When we kick off a build because some files changed, only print the first `maxChangedFilesToPrint`
 const maxChangedFilesToPrint = 5
 
+// When a resource keeps crashing without any new changes, wait this long before
+// the second crash rebuild, doubling each time after that, up to the max.
+const crashRebuildInitialBackoff = 10 * time.Second
+const crashRebuildMaxBackoff = 5 * time.Minute
+
 // TODO(nick): maybe this should be called 'BuildEngine' or something?
 // Upper seems like a poor and undescriptive name.
 type Upper struct {
 	store *store.Store
 }
 
-type FsWatcherMaker func() (watch.Notify, error)
+type FsWatcherMaker func(paths []string, ignore watch.PathMatcher, settings model.WatchSettings) (watch.Notify, error)
 type ServiceWatcherMaker func(context.Context, *store.Store) error
 type PodWatcherMaker func(context.Context, *store.Store) error
 type timerMaker func(d time.Duration) <-chan time.Time
 
+// Watch settings from the command line, which override the ones from the Tiltfile.
+type WatchSettingsFlag model.WatchSettings
+
 func ProvideFsWatcherMaker() FsWatcherMaker {
-	return func() (watch.Notify, error) {
-		return watch.NewWatcher()
+	return func(paths []string, ignore watch.PathMatcher, settings model.WatchSettings) (watch.Notify, error) {
+		if settings.IsPoll() {
+			// Polling with checksums already ignores files that didn't change.
+			return watch.NewPollingWatcher(paths, ignore, watch.PollOptions{
+				Interval: settings.PollIntervalOrDefault(),
+				Checksum: settings.PollCompare == model.PollCompareChecksum || settings.DedupByChecksum,
+			})
+		}
+		if settings.IsWatchman() {
+			return watch.NewWatchmanWatcher(paths, ignore)
+		}
+		return watch.NewWatcher(paths, ignore)
 	}
 }
 
@@ -81,6 +99,16 @@ func NewUpper(ctx context.Context, st *store.Store, subs []store.Subscriber) Upp
 	}
 }
 
+// For subscribers that only some commands need (e.g., `tilt ci`).
+func (u Upper) AddSubscriber(ctx context.Context, sub store.Subscriber) {
+	u.store.AddSubscriber(ctx, sub)
+}
+
+// Records every action to r, for stepping through later with `tilt replay`.
+func (u Upper) RecordActions(r *store.ActionRecorder) {
+	u.store.SetActionRecorder(r)
+}
+
 func (u Upper) Dispatch(action store.Action) {
 	u.store.Dispatch(action)
 }
@@ -141,6 +169,26 @@ var UpperReducer = store.Reducer(func(ctx context.Context, state *store.EngineSt
 		handleServiceEvent(ctx, state, action)
 	case PodLogAction:
 		handlePodLogAction(state, action)
+	case PortForwardStatusAction:
+		handlePortForwardStatusAction(state, action)
+	case ReplicaSetChangeAction:
+		handleReplicaSetChangeAction(ctx, state, action)
+	case IngressChangeAction:
+		handleIngressChangeAction(state, action)
+	case K8sEventAction:
+		handleK8sEventAction(state, action)
+	case KubeContextChangeAction:
+		state.KubeContextAlert = action.Alert
+	case PodMetricsAction:
+		handlePodMetricsAction(state, action)
+	case K8sAppliedAction:
+		handleK8sAppliedAction(state, action)
+	case K8sDriftAction:
+		handleK8sDriftAction(state, action)
+	case ReadinessCheckAction:
+		handleReadinessCheckAction(ctx, state, action)
+	case CrashBackoffExpiredAction:
+		handleCrashBackoffExpiredAction(state, action)
 	case BuildLogAction:
 		handleBuildLogAction(state, action)
 	case BuildCompleteAction:
@@ -159,8 +207,40 @@ var UpperReducer = store.Reducer(func(ctx context.Context, state *store.EngineSt
 		handleDockerComposeEvent(ctx, state, action)
 	case DockerComposeLogAction:
 		handleDockerComposeLogAction(state, action)
+	case LocalServeStatusAction:
+		handleLocalServeStatusAction(state, action)
+	case LocalServeLogAction:
+		handleLocalServeLogAction(state, action)
 	case view.AppendToTriggerQueueAction:
 		appendToTriggerQueue(state, action.Name)
+	case view.CancelBuildAction:
+		handleCancelBuildAction(state, action)
+	case view.ClearErrorAction:
+		handleClearErrorAction(state, action)
+	case view.ReapplyAction:
+		handleReapplyAction(state, action)
+	case view.TriggerAllAction:
+		handleTriggerAllAction(state)
+	case view.TearDownAction:
+		handleTearDownAction(ctx, state, action)
+	case view.DisableResourceAction:
+		handleDisableResourceAction(ctx, state, action)
+	case view.EnableResourceAction:
+		handleEnableResourceAction(ctx, state, action)
+	case view.PauseUpdatesAction:
+		handlePauseUpdatesAction(ctx, state, action)
+	case view.ResumeUpdatesAction:
+		handleResumeUpdatesAction(ctx, state, action)
+	case TearDownCompleteAction:
+		handleTearDownCompleteAction(ctx, state, action)
+	case view.RestartPodAction:
+		handleRestartPodAction(ctx, state, action)
+	case RestartCompleteAction:
+		handleRestartCompleteAction(ctx, state, action)
+	case view.DeleteOrphansAction:
+		state.DeleteOrphansRequested = true
+	case OrphansDeletedAction:
+		state.DeleteOrphansRequested = false
 	case hud.StartProfilingAction:
 		handleStartProfilingAction(state)
 	case hud.StopProfilingAction:
@@ -173,6 +253,8 @@ var UpperReducer = store.Reducer(func(ctx context.Context, state *store.EngineSt
 		handleTiltfileLogAction(ctx, state, action)
 	case hud.DumpEngineStateAction:
 		handleDumpEngineStateAction(ctx, state)
+	case hud.StepReplayAction:
+		// Only means something in `tilt replay`.
 	default:
 		err = fmt.Errorf("unrecognized action: %T", action)
 	}
@@ -184,22 +266,33 @@ var UpperReducer = store.Reducer(func(ctx context.Context, state *store.EngineSt
 
 func handleBuildStarted(ctx context.Context, state *store.EngineState, action BuildStartedAction) {
 	mn := action.ManifestName
+
+	// Count the build even if its manifest went away while it was starting,
+	// or the BuildController would wait forever for it to be recorded.
+	state.CurrentlyBuilding[mn] = true
+	state.StartedBuildCount++
+	removeFromTriggerQueue(state, mn)
+
 	ms, ok := state.ManifestState(mn)
 	if !ok {
 		return
 	}
 
+	// A file can show up as both a changed file and a changed config file.
 	edits := []string{}
 	edits = append(edits, action.FilesChanged...)
+	edits = append(edits, ms.ConfigFilesThatCausedChange...)
 
 	bs := model.BuildRecord{
-		Edits:     append(edits, ms.ConfigFilesThatCausedChange...),
+		Edits:     sliceutils.DedupedAndSorted(edits),
 		StartTime: action.StartTime,
 		Reason:    action.Reason,
 	}
 	ms.ConfigFilesThatCausedChange = []string{}
 	ms.CurrentBuild = bs
+	ms.CancelBuildRequested = false
 	ms.ExpectedContainerID = ""
+	ms.CrashBackoffUntil = time.Time{}
 
 	for _, pod := range ms.PodSet.Pods {
 		pod.CurrentLog = model.Log{}
@@ -215,18 +308,14 @@ func handleBuildStarted(ctx context.Context, state *store.EngineState, action Bu
 	if !action.Reason.IsCrashOnly() {
 		ms.CrashLog = model.Log{}
 	}
-
-	state.CurrentlyBuilding = mn
-	removeFromTriggerQueue(state, mn)
 }
 
 func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, cb BuildCompleteAction) error {
 	defer func() {
-		engineState.CurrentlyBuilding = ""
+		delete(engineState.CurrentlyBuilding, cb.ManifestName)
 	}()
 
 	engineState.CompletedBuildCount++
-	engineState.BuildControllerActionCount++
 
 	defer func() {
 		if engineState.CompletedBuildCount == engineState.InitialBuildsQueued {
@@ -236,7 +325,7 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 
 	err := cb.Error
 
-	mt, ok := engineState.ManifestTargets[engineState.CurrentlyBuilding]
+	mt, ok := engineState.ManifestTargets[cb.ManifestName]
 	if !ok {
 		return nil
 	}
@@ -244,15 +333,33 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 	ms := mt.State
 	bs := ms.CurrentBuild
 	bs.Error = err
+	bs.Canceled = isBuildCanceled(err)
 	bs.FinishTime = time.Now()
+	if err == nil {
+		bs.UpdateType = cb.Result.UpdateType()
+		if bs.UpdateType == model.UpdateTypeImageBuild {
+			bs.FallbackReason = cb.FallbackReason
+		}
+	}
 	ms.AddCompletedBuild(bs)
 
 	ms.CurrentBuild = model.BuildRecord{}
+	ms.CancelBuildRequested = false
 	ms.NeedsRebuildFromCrash = false
 
+	// A build with new changes might fix the crash, so start backing off from scratch.
+	if bs.Reason.IsCrashOnly() {
+		ms.CrashRebuildCount++
+	} else {
+		ms.CrashRebuildCount = 0
+	}
+
 	if err != nil {
 		if isPermanentError(err) {
 			return err
+		} else if isBuildCanceled(err) {
+			l := logger.Get(ctx)
+			l.Infof("%s", logger.Yellow(l).Sprintf("%v", err))
 		} else if engineState.WatchFiles {
 			l := logger.Get(ctx)
 			p := logger.Red(l).Sprintf("Build Failed:")
@@ -276,6 +383,8 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 		}
 
 		ms.LastSuccessfulDeployTime = time.Now()
+		ms.ReadinessCheckPassed = false
+		ms.ReadinessCheckError = ""
 
 		for id, result := range cb.Result {
 			ms.MutableBuildStatus(id).LastSuccessfulResult = result
@@ -285,6 +394,8 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 			// # of pod restarts from old code (shouldn't be reflected in HUD)
 			pod.OldRestarts = pod.ContainerRestarts
 		}
+
+		redeployDependents(engineState, mt.Manifest.Name)
 	}
 
 	if mt.Manifest.IsDC() {
@@ -324,6 +435,20 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 	return nil
 }
 
+// Mark the manifest as built and deployed, as if we had just done its initial build.
+func restoreManifestState(ms *store.ManifestState, restored RestoredManifest) {
+	ms.DeployID = restored.DeployID
+	ms.LastSuccessfulDeployTime = restored.DeployTime
+	for id, result := range restored.Results {
+		ms.MutableBuildStatus(id).LastSuccessfulResult = result
+	}
+	ms.AddCompletedBuild(model.BuildRecord{
+		StartTime:  restored.DeployTime,
+		FinishTime: restored.DeployTime,
+		Reason:     model.BuildReasonFlagInit,
+	})
+}
+
 func handleDeployIDAction(ctx context.Context, state *store.EngineState, action DeployIDAction) {
 	mns := state.ManifestNamesForTargetID(action.TargetID)
 	for _, mn := range mns {
@@ -336,27 +461,299 @@ func handleDeployIDAction(ctx context.Context, state *store.EngineState, action
 	}
 }
 
+func handleK8sAppliedAction(state *store.EngineState, action K8sAppliedAction) {
+	for _, mn := range state.ManifestNamesForTargetID(action.TargetID) {
+		ms, ok := state.ManifestState(mn)
+		if !ok {
+			continue
+		}
+
+		ms.LastAppliedYAML = action.YAML
+		ms.Drift = ""
+	}
+}
+
+func handleK8sDriftAction(state *store.EngineState, action K8sDriftAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+
+	// We applied something new since we started comparing, so the result is stale.
+	if ms.LastAppliedYAML != action.AppliedYAML {
+		return
+	}
+	ms.Drift = action.Drift
+}
+
+func handleReadinessCheckAction(ctx context.Context, state *store.EngineState, action ReadinessCheckAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok || !ms.LastSuccessfulDeployTime.Equal(action.DeployTime) || ms.ReadinessCheckPassed {
+		return
+	}
+
+	if action.Error != nil {
+		ms.ReadinessCheckError = action.Error.Error()
+		return
+	}
+
+	ms.ReadinessCheckPassed = true
+	ms.ReadinessCheckError = ""
+	logger.Get(ctx).Infof("%s passed its readiness check", action.ManifestName)
+}
+
+// Queues a build of a manifest that the user asked for explicitly.
+//
+// In manual mode, this is the only way the manifest builds. In auto mode, it moves
+// the manifest's pending changes ahead of everyone else's. Triggering a manifest
+// that's already queued moves it to the front.
+//
+// If a manual manifest is building right now, queues exactly one follow-up build,
+// so that changes that came in during the build don't get lost.
 func appendToTriggerQueue(state *store.EngineState, mn model.ManifestName) {
-	if state.TriggerMode != model.TriggerManual {
+	mt, ok := state.ManifestTargets[mn]
+	if !ok || mt.State.Disabled {
 		return
 	}
 
-	ms, ok := state.ManifestState(mn)
+	manual := state.TriggerModeForManifest(mt.Manifest) == model.TriggerManual
+	if !manual || !state.CurrentlyBuilding[mn] {
+		ok, _ = mt.State.HasPendingChanges()
+		if !ok {
+			return
+		}
+	}
+
+	removeFromTriggerQueue(state, mn)
+	state.TriggerQueue = append(state.TriggerQueue, mn)
+}
+
+func handleCancelBuildAction(state *store.EngineState, action view.CancelBuildAction) {
+	if !state.CurrentlyBuilding[action.Name] {
+		return
+	}
+
+	ms, ok := state.ManifestState(action.Name)
 	if !ok {
 		return
 	}
+	ms.CancelBuildRequested = true
+}
 
-	ok, _ = ms.HasPendingChanges()
+// Hides the resource's last build error, pod restarts, and crash log,
+// once the user has seen them. The next build or restart shows up as usual.
+func handleClearErrorAction(state *store.EngineState, action view.ClearErrorAction) {
+	ms, ok := state.ManifestState(action.Name)
 	if !ok {
 		return
 	}
 
-	for _, triggerName := range state.TriggerQueue {
-		if mn == triggerName {
+	ms.BuildErrorCleared = ms.LastBuild().Error != nil
+	ms.CrashLog = model.Log{}
+	for _, pod := range ms.PodSet.Pods {
+		pod.OldRestarts = pod.ContainerRestarts
+	}
+}
+
+// Deploys the manifest again, to put back the objects that someone changed
+// outside of Tilt. The images haven't changed, so we keep them, and only
+// forget the last deploy.
+func handleReapplyAction(state *store.EngineState, action view.ReapplyAction) {
+	mt, ok := state.ManifestTargets[action.Name]
+	if !ok || mt.State.Drift == "" {
+		return
+	}
+
+	ms := mt.State
+	delete(ms.BuildStatuses, mt.Manifest.K8sTarget().ID())
+	ms.PendingManifestChange = time.Now()
+	appendToTriggerQueue(state, action.Name)
+}
+
+// Rebuilds every resource from scratch (e.g., after the user pulled changes,
+// or changed a file that Tilt doesn't watch), dependencies first.
+func handleTriggerAllAction(state *store.EngineState) {
+	changeTime := time.Now()
+	manifests := model.SortByResourceDependencies(state.EnabledManifests())
+
+	// We build the most recent trigger first, so queue the dependencies last.
+	for i := len(manifests) - 1; i >= 0; i-- {
+		mn := manifests[i].Name
+		ms, ok := state.ManifestState(mn)
+		if !ok {
+			continue
+		}
+		ms.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
+		ms.PendingManifestChange = changeTime
+		appendToTriggerQueue(state, mn)
+	}
+}
+
+// Queues a redeploy of the resources that asked to redeploy whenever the given
+// resource does. They wait until it's ready, and in manual mode, for a trigger.
+func redeployDependents(state *store.EngineState, mn model.ManifestName) {
+	changeTime := time.Now()
+	for _, mt := range state.Targets() {
+		m := mt.Manifest
+		if !m.RedeployOnDependencyUpdate || !mt.State.StartedFirstBuild() {
+			continue
+		}
+		for _, dep := range m.ResourceDependencies {
+			if dep == mn {
+				mt.State.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
+				mt.State.PendingManifestChange = changeTime
+				break
+			}
+		}
+	}
+}
+
+// Takes the resource out of the session, so that we stop watching and building it,
+// and queues its objects for deletion. If it's building, cancels the build.
+func handleTearDownAction(ctx context.Context, state *store.EngineState, action view.TearDownAction) {
+	mt, ok := state.ManifestTargets[action.Name]
+	if !ok {
+		return
+	}
+
+	logger.Get(ctx).Infof("Tearing down %s", action.Name)
+	delete(state.ManifestTargets, action.Name)
+	for i, mn := range state.ManifestDefinitionOrder {
+		if mn == action.Name {
+			state.ManifestDefinitionOrder = append(state.ManifestDefinitionOrder[:i:i], state.ManifestDefinitionOrder[i+1:]...)
+			break
+		}
+	}
+	removeFromTriggerQueue(state, action.Name)
+
+	state.TornDownManifests[action.Name] = true
+	state.PendingTearDowns = append(state.PendingTearDowns, mt.Manifest)
+}
+
+// Keeps the resource in the session, but stops watching and building it.
+// If it's building, cancels the build.
+func handleDisableResourceAction(ctx context.Context, state *store.EngineState, action view.DisableResourceAction) {
+	mt, ok := state.ManifestTargets[action.Name]
+	if !ok || mt.State.Disabled {
+		return
+	}
+
+	logger.Get(ctx).Infof("Disabling %s", action.Name)
+	mt.State.Disabled = true
+	removeFromTriggerQueue(state, action.Name)
+
+	if action.DeleteObjects {
+		state.PendingTearDowns = append(state.PendingTearDowns, mt.Manifest)
+	}
+}
+
+// Turns the resource back on. We weren't watching its files while it was off,
+// so we rebuild it from scratch.
+func handleEnableResourceAction(ctx context.Context, state *store.EngineState, action view.EnableResourceAction) {
+	mt, ok := state.ManifestTargets[action.Name]
+	if !ok || !mt.State.Disabled {
+		return
+	}
+
+	logger.Get(ctx).Infof("Enabling %s", action.Name)
+	ms := mt.State
+	ms.Disabled = false
+	ms.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
+	ms.PendingManifestChange = time.Now()
+}
+
+// File changes keep coming in while updates are paused. nextTargetToBuild
+// leaves them pending until the user resumes.
+func handlePauseUpdatesAction(ctx context.Context, state *store.EngineState, action view.PauseUpdatesAction) {
+	if action.Name == "" {
+		if !state.UpdatesPaused {
+			logger.Get(ctx).Infof("Pausing updates. Run `tilt resume` (or press P) to pick them back up.")
+			state.UpdatesPaused = true
+		}
+		return
+	}
+
+	mt, ok := state.ManifestTargets[action.Name]
+	if !ok || mt.State.UpdatesPaused {
+		return
+	}
+	logger.Get(ctx).Infof("Pausing updates to %s", action.Name)
+	mt.State.UpdatesPaused = true
+}
+
+func handleResumeUpdatesAction(ctx context.Context, state *store.EngineState, action view.ResumeUpdatesAction) {
+	if action.Name == "" {
+		if state.UpdatesPaused {
+			logger.Get(ctx).Infof("Resuming updates")
+			state.UpdatesPaused = false
+		}
+		return
+	}
+
+	mt, ok := state.ManifestTargets[action.Name]
+	if !ok || !mt.State.UpdatesPaused {
+		return
+	}
+	logger.Get(ctx).Infof("Resuming updates to %s", action.Name)
+	mt.State.UpdatesPaused = false
+}
+
+// Queues the resource's pods for restart. The RestartController deletes them,
+// and their ReplicaSet brings up new ones from the same spec.
+func handleRestartPodAction(ctx context.Context, state *store.EngineState, action view.RestartPodAction) {
+	mt, ok := state.ManifestTargets[action.Name]
+	if !ok {
+		return
+	}
+
+	l := logger.Get(ctx)
+	m := mt.Manifest
+	if !m.IsK8s() && !m.IsDC() {
+		l.Infof("Can't restart %s: it doesn't run any pods or containers", action.Name)
+		return
+	}
+	if m.IsK8s() && mt.State.PodSet.Len() == 0 {
+		l.Infof("Can't restart %s: it doesn't have any pods yet", action.Name)
+		return
+	}
+
+	for _, mn := range state.PendingRestarts {
+		if mn == action.Name {
 			return
 		}
 	}
-	state.TriggerQueue = append(state.TriggerQueue, mn)
+
+	l.Infof("Restarting %s", action.Name)
+	state.PendingRestarts = append(state.PendingRestarts, action.Name)
+}
+
+func handleRestartCompleteAction(ctx context.Context, state *store.EngineState, action RestartCompleteAction) {
+	for i, mn := range state.PendingRestarts {
+		if mn == action.ManifestName {
+			state.PendingRestarts = append(state.PendingRestarts[:i:i], state.PendingRestarts[i+1:]...)
+			break
+		}
+	}
+
+	if action.Error != nil {
+		logger.Get(ctx).Infof("Error restarting %s: %v", action.ManifestName, action.Error)
+	}
+}
+
+func handleTearDownCompleteAction(ctx context.Context, state *store.EngineState, action TearDownCompleteAction) {
+	for i, m := range state.PendingTearDowns {
+		if m.Name == action.ManifestName {
+			state.PendingTearDowns = append(state.PendingTearDowns[:i:i], state.PendingTearDowns[i+1:]...)
+			break
+		}
+	}
+
+	l := logger.Get(ctx)
+	if action.Error != nil {
+		l.Infof("Error tearing down %s: %v", action.ManifestName, action.Error)
+		return
+	}
+	l.Infof("Tore down %s", action.ManifestName)
 }
 
 func removeFromTriggerQueue(state *store.EngineState, mn model.ManifestName) {
@@ -400,6 +797,23 @@ func handleFSEvent(
 		return
 	}
 
+	// Changes to the Tiltfile itself are handled by re-executing it. If the
+	// manifests come out the same, there's nothing to rebuild for a target
+	// that only happens to watch it (e.g., a local_resource with deps=['.']).
+	// But if the Tiltfile is in an image's build context, the image changed.
+	files := event.files
+	if !inImageBuildContext(*state, event.targetID, state.TiltfilePath) {
+		files = nil
+		for _, f := range event.files {
+			if f != state.TiltfilePath {
+				files = append(files, f)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
 	mns := state.ManifestNamesForTargetID(event.targetID)
 	for _, mn := range mns {
 		ms, ok := state.ManifestState(mn)
@@ -408,12 +822,26 @@ func handleFSEvent(
 		}
 
 		status := ms.MutableBuildStatus(event.targetID)
-		for _, f := range event.files {
+		for _, f := range files {
 			status.PendingFileChanges[f] = event.time
 		}
 	}
 }
 
+func inImageBuildContext(state store.EngineState, id model.TargetID, file string) bool {
+	if id.Type != model.TargetTypeImage || file == "" {
+		return false
+	}
+	for _, m := range state.Manifests() {
+		for _, iTarget := range m.ImageTargets {
+			if iTarget.ID() == id && ospath.IsChildOfOne(iTarget.LocalPaths(), file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func handleConfigsReloadStarted(
 	ctx context.Context,
 	state *store.EngineState,
@@ -440,17 +868,24 @@ func handleConfigsReloaded(
 	state.FirstTiltfileBuildCompleted = true
 	manifests := event.Manifests
 	if state.InitialBuildsQueued == 0 {
-		state.InitialBuildsQueued = len(manifests)
+		state.InitialBuildsQueued = len(manifests) - len(event.RestoredManifests)
+	}
+
+	err := event.Err
+	if err == nil {
+		// Fail fast on resources that could never deploy, rather than
+		// waiting on them forever.
+		err = model.ValidateResourceDependencies(manifests, event.AllManifestNames)
 	}
 
 	status := state.CurrentTiltfileBuild
 	status.FinishTime = event.FinishTime
-	status.Error = event.Err
+	status.Error = err
 	status.Warnings = event.Warnings
 
 	state.LastTiltfileBuild = status
 	state.CurrentTiltfileBuild = model.BuildRecord{}
-	if event.Err != nil {
+	if err != nil {
 		// There was an error, so don't update status with the new, nonexistent state
 
 		// EXCEPT for the config file list, because we want to watch new config files even when the tiltfile is broken
@@ -460,25 +895,37 @@ func handleConfigsReloaded(
 		return
 	}
 
-	newDefOrder := make([]model.ManifestName, len(manifests))
-	for i, m := range manifests {
+	// Every manifest that this reload changed gets the same change time,
+	// so that they build in Tiltfile order.
+	changeTime := time.Now()
+	newDefOrder := make([]model.ManifestName, 0, len(manifests))
+	for _, m := range manifests {
+		if state.TornDownManifests[m.ManifestName()] {
+			continue
+		}
+
 		mt, ok := state.ManifestTargets[m.ManifestName()]
 		if !ok {
 			mt = store.NewManifestTarget(m)
+			if restored, ok := event.RestoredManifests[m.ManifestName()]; ok {
+				restoreManifestState(mt.State, restored)
+			}
 		}
 
-		newDefOrder[i] = m.ManifestName()
+		newDefOrder = append(newDefOrder, m.ManifestName())
 
 		configFilesThatChanged := state.LastTiltfileBuild.Edits
 		if !m.Equal(mt.Manifest) {
-			mt.Manifest = m
-
-			// Manifest has changed, ensure we do an image build so that we apply the changes
+			// Manifest has changed. Clear the build state of the targets that changed,
+			// to ensure we do an image build so that we apply the changes.
 			state := mt.State
-			state.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
-			state.PendingManifestChange = time.Now()
+			resetChangedBuildStatuses(state, mt.Manifest, m)
+			state.PendingManifestChange = changeTime
 			state.ConfigFilesThatCausedChange = configFilesThatChanged
 		}
+		// Settings that don't change what we deploy (e.g., resource dependencies)
+		// still take effect, without a rebuild.
+		mt.Manifest = m
 		state.UpsertManifestTarget(mt)
 	}
 	// TODO(dmiller) handle deleting manifests
@@ -486,6 +933,10 @@ func handleConfigsReloaded(
 	state.ManifestDefinitionOrder = newDefOrder
 	state.ConfigFiles = event.ConfigFiles
 	state.TiltIgnoreContents = event.TiltIgnoreContents
+	state.MaxParallelUpdates = event.MaxParallelUpdates
+	state.EventSinks = event.EventSinks
+	state.WatchSettings = event.WatchSettings
+	state.TiltfileManifestNames = event.AllManifestNames
 
 	// Remove pending file changes that were consumed by this build.
 	for file, modTime := range state.PendingConfigFileChanges {
@@ -495,23 +946,76 @@ func handleConfigsReloaded(
 	}
 }
 
+// Clears the build status of every target whose spec changed between oldM and newM.
+//
+// Targets that didn't change keep their last build, so that (e.g.) editing a
+// resource's YAML redeploys it with the images we already have, rather than
+// rebuilding them all. If files were replaced in a running container since the
+// image was built, the image is out of date, so we clear that too.
+func resetChangedBuildStatuses(ms *store.ManifestState, oldM, newM model.Manifest) {
+	oldSpecs := make(map[model.TargetID]model.TargetSpec)
+	for _, spec := range oldM.TargetSpecs() {
+		oldSpecs[spec.ID()] = spec
+	}
+	newSpecs := make(map[model.TargetID]model.TargetSpec)
+	for _, spec := range newM.TargetSpecs() {
+		newSpecs[spec.ID()] = spec
+	}
+
+	for id, status := range ms.BuildStatuses {
+		oldSpec, oldOK := oldSpecs[id]
+		newSpec, newOK := newSpecs[id]
+		if !oldOK || !newOK || !model.DeepEqual(oldSpec, newSpec) ||
+			len(status.LastSuccessfulResult.FilesReplacedSet) > 0 {
+			delete(ms.BuildStatuses, id)
+		}
+	}
+}
+
+// Find the manifest that deployed an object, from the label that ties the
+// manifest's objects to it (which the manifest may have renamed).
+func manifestNameFromLabels(state *store.EngineState, objLabels map[string]string) model.ManifestName {
+	if name, ok := objLabels[k8s.ManifestNameLabel]; ok {
+		return model.ManifestName(name)
+	}
+
+	for _, m := range state.Manifests() {
+		if !m.IsK8s() || m.K8sTarget().ManifestLabel == "" {
+			continue
+		}
+		if objLabels[m.K8sTarget().ManifestLabel] == m.Name.String() {
+			return m.Name
+		}
+	}
+	return ""
+}
+
+func manifestForExtraPodSelectors(state *store.EngineState, pod *v1.Pod) model.ManifestName {
+	podLabels := labels.Set(pod.ObjectMeta.GetLabels())
+	for _, m := range state.Manifests() {
+		if !m.IsK8s() {
+			continue
+		}
+		for _, ls := range m.K8sTarget().ExtraPodSelectors {
+			if ls.Matches(podLabels) {
+				return m.Name
+			}
+		}
+	}
+	return ""
+}
+
 // Get a pointer to a mutable manifest state,
 // ensuring that some Pod exists on the state.
 //
 // Intended as a helper for pod-mutating events.
 func ensureManifestTargetWithPod(state *store.EngineState, pod *v1.Pod) (*store.ManifestTarget, *store.Pod) {
-	manifestName := model.ManifestName(pod.ObjectMeta.Labels[k8s.ManifestNameLabel])
-	if manifestName == "" {
-		// if there's no ManifestNameLabel, then maybe it matches some manifest's ExtraPodSelectors
-		for _, m := range state.Manifests() {
-			if m.IsK8s() {
-				for _, lps := range m.K8sTarget().ExtraPodSelectors {
-					if lps.Matches(labels.Set(pod.ObjectMeta.GetLabels())) {
-						manifestName = m.Name
-						break
-					}
-				}
-			}
+	manifestName := manifestNameFromLabels(state, pod.ObjectMeta.Labels)
+	if manifestName == "" || manifestName == model.UnresourcedYAMLManifestName {
+		// if there's no manifest label (e.g., an operator created the pod), or the pod came from
+		// YAML that isn't part of a resource, then maybe it matches some manifest's ExtraPodSelectors
+		if mn := manifestForExtraPodSelectors(state, pod); mn != "" {
+			manifestName = mn
 		}
 	}
 
@@ -532,7 +1036,7 @@ func ensureManifestTargetWithPod(state *store.EngineState, pod *v1.Pod) (*store.
 
 	podID := k8s.PodIDFromPod(pod)
 	startedAt := pod.CreationTimestamp.Time
-	status := podStatusToString(*pod)
+	status := podStatusToString(*pod, sidecarContainers(mt.Manifest, pod))
 	ns := k8s.NamespaceFromPod(pod)
 	hasSynclet := sidecar.PodSpecContainsSynclet(pod.Spec)
 
@@ -597,34 +1101,52 @@ func populateContainerStatus(ctx context.Context, manifest model.Manifest, podIn
 	podInfo.ContainerPorts = ports
 
 	forwards := PopulatePortForwards(manifest, *podInfo)
-	if len(forwards) < len(manifest.K8sTarget().PortForwards) {
+	if len(forwards) < len(manifest.K8sTarget().PodPortForwards()) {
 		logger.Get(ctx).Infof(
 			"WARNING: Resource %s is using port forwards, but no container ports on pod %s",
 			manifest.Name, podInfo.PodID)
 	}
+}
+
+// The containers in the pod that aren't part of the manifest's app.
+func sidecarContainers(manifest model.Manifest, pod *v1.Pod) map[string]bool {
+	if !manifest.IsK8s() {
+		return nil
+	}
+	kTarget := manifest.K8sTarget()
+	return k8s.SidecarContainers(pod, kTarget.SidecarContainers, !kTarget.DisableSidecarDetection)
+}
 
-	// HACK(maia): Go through ALL containers (except tilt-synclet), grab minimum info we need
-	// to stream logs from them.
+// HACK(maia): Go through ALL containers (except tilt-synclet), including init containers,
+// and grab the minimum info we need to stream logs from them.
+func populateContainerInfos(ctx context.Context, podInfo *store.Pod, pod *v1.Pod, sidecars map[string]bool) {
 	var cInfos []store.ContainerInfo
-	for _, cStat := range pod.Status.ContainerStatuses {
-		if cStat.Name == sidecar.SyncletContainerName {
-			// We don't want logs for the Tilt synclet.
-			continue
-		}
+	addContainerInfos := func(statuses []v1.ContainerStatus, init bool) {
+		for _, cStat := range statuses {
+			if cStat.Name == sidecar.SyncletContainerName {
+				// We don't want logs for the Tilt synclet.
+				continue
+			}
 
-		cID, err := k8s.ContainerIDFromContainerStatus(cStat)
-		if err != nil {
-			logger.Get(ctx).Debugf("Error parsing container ID: %v", err)
-			return
-		}
-		if err != nil {
-			return
+			cID, err := k8s.ContainerIDFromContainerStatus(cStat)
+			if err != nil {
+				logger.Get(ctx).Debugf("Error parsing container ID: %v", err)
+				continue
+			}
+			if cID == "" {
+				// The container hasn't started yet, so there are no logs.
+				continue
+			}
+			cInfos = append(cInfos, store.ContainerInfo{
+				ID:      cID,
+				Name:    k8s.ContainerNameFromContainerStatus(cStat),
+				Init:    init,
+				Sidecar: sidecars[cStat.Name],
+			})
 		}
-		cInfos = append(cInfos, store.ContainerInfo{
-			ID:   cID,
-			Name: k8s.ContainerNameFromContainerStatus(cStat),
-		})
 	}
+	addContainerInfos(pod.Status.InitContainerStatuses, true)
+	addContainerInfos(pod.Status.ContainerStatuses, false)
 	podInfo.ContainerInfos = cInfos
 }
 
@@ -642,10 +1164,21 @@ func handlePodChangeAction(ctx context.Context, state *store.EngineState, pod *v
 		return
 	}
 
-	// Update the status
+	// Update the status, leaving out the sidecars, so that a mesh proxy
+	// that's restarting doesn't look like the app crashing.
+	sidecars := sidecarContainers(manifest, pod)
 	podInfo.Deleting = pod.DeletionTimestamp != nil
 	podInfo.Phase = pod.Status.Phase
-	podInfo.Status = podStatusToString(*pod)
+	podInfo.Status = podStatusToString(*pod, sidecars)
+	podInfo.Ready = k8s.IsPodReadyIgnoringSidecars(pod, sidecars)
+	podInfo.StatusAlerts = podStatusAlerts(pod, sidecars)
+	podInfo.ContainerLimits = containerLimits(pod)
+	if podInfo.Phase == v1.PodRunning {
+		// Volumes are mounted by the time the pod runs,
+		// so any events about failing to mount them are stale.
+		podInfo.EventAlerts = nil
+	}
+	populateContainerInfos(ctx, podInfo, pod, sidecars)
 
 	defer prunePods(ms)
 
@@ -664,11 +1197,20 @@ func handlePodChangeAction(ctx context.Context, state *store.EngineState, pod *v
 				break
 			}
 		}
+
+		// Pods that we found with ExtraPodSelectors may not run any image we built
+		// (e.g., an operator created them), so fall back to their first container.
+		appStatuses := k8s.ContainerStatusesWithoutSidecars(pod.Status.ContainerStatuses, sidecars)
+		if cStatus.Name == "" && manifestNameFromLabels(state, pod.ObjectMeta.Labels) != manifest.Name &&
+			len(appStatuses) > 0 {
+			cStatus = appStatuses[0]
+		}
 	} else {
 		// We didn't build images for this manifest so we have no good way of figuring
-		// out which container(s) we care about; for now, take the first.
-		if len(pod.Status.ContainerStatuses) > 0 {
-			cStatus = pod.Status.ContainerStatuses[0]
+		// out which container(s) we care about; for now, take the first that isn't a sidecar.
+		appStatuses := k8s.ContainerStatusesWithoutSidecars(pod.Status.ContainerStatuses, sidecars)
+		if len(appStatuses) > 0 {
+			cStatus = appStatuses[0]
 		}
 
 	}
@@ -702,6 +1244,13 @@ func checkForPodCrash(ctx context.Context, state *store.EngineState, ms *store.M
 	ms.NeedsRebuildFromCrash = true
 	ms.ExpectedContainerID = ""
 	msg := fmt.Sprintf("Detected a container change for %s. We could be running stale code. Rebuilding and deploying a new image.", ms.Name)
+
+	// If it keeps crashing without any new changes, rebuilding right away
+	// won't help, so wait longer each time.
+	if backoff := crashRebuildBackoff(ms.CrashRebuildCount); backoff > 0 {
+		ms.CrashBackoffUntil = time.Now().Add(backoff)
+		msg = fmt.Sprintf("Detected a container change for %s. It keeps crashing; crash backoff: retrying in %s.", ms.Name, backoff)
+	}
 	le := store.NewLogEvent([]byte(msg + "\n"))
 	if len(ms.BuildHistory) > 0 {
 		ms.BuildHistory[0].Log = model.AppendLog(ms.BuildHistory[0].Log, le, state.LogTimestamps)
@@ -710,6 +1259,31 @@ func checkForPodCrash(ctx context.Context, state *store.EngineState, ms *store.M
 	logger.Get(ctx).Infof("%s", msg)
 }
 
+// How long to wait before rebuilding a resource that has crashed this many times
+// in a row without any new changes. The first crash rebuilds right away.
+func crashRebuildBackoff(crashRebuildCount int) time.Duration {
+	if crashRebuildCount == 0 {
+		return 0
+	}
+
+	backoff := crashRebuildInitialBackoff
+	for i := 1; i < crashRebuildCount && backoff < crashRebuildMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > crashRebuildMaxBackoff {
+		backoff = crashRebuildMaxBackoff
+	}
+	return backoff
+}
+
+func handleCrashBackoffExpiredAction(state *store.EngineState, action CrashBackoffExpiredAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok || !ms.CrashBackoffUntil.Equal(action.Until) {
+		return
+	}
+	ms.CrashBackoffUntil = time.Time{}
+}
+
 // If there's more than one pod, prune the deleting/dead ones so
 // that they don't clutter the output.
 func prunePods(ms *store.ManifestState) {
@@ -737,6 +1311,80 @@ func prunePods(ms *store.ManifestState) {
 	}
 }
 
+func handlePortForwardStatusAction(state *store.EngineState, action PortForwardStatusAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+
+	pod, ok := ms.PodSet.Pods[action.PodID]
+	if !ok {
+		// The forward was to a pod that's gone now.
+		return
+	}
+
+	if pod.PortForwardStatuses == nil {
+		pod.PortForwardStatuses = make(map[int]store.PortForwardStatus)
+	}
+	pod.PortForwardStatuses[action.LocalPort] = action.Status
+}
+
+func handleK8sEventAction(state *store.EngineState, action K8sEventAction) {
+	e := action.Event
+
+	if manifestName, ok := manifestForK8sEvent(state, e, action.ConfigNamespace); ok {
+		ms, _ := state.ManifestState(manifestName)
+		ms.CombinedLog = model.AppendLog(ms.CombinedLog, k8sEventLogEvent(e), state.LogTimestamps)
+		ms.AddK8sEvent(k8sEventRecord(e))
+	}
+
+	alert := podEventAlert(e)
+	if alert == "" {
+		return
+	}
+
+	podID := k8s.PodID(e.InvolvedObject.Name)
+	for _, mt := range state.ManifestTargets {
+		pod, ok := mt.State.PodSet.Pods[podID]
+		if !ok || pod.Namespace.String() != k8s.Namespace(e.InvolvedObject.Namespace).String() {
+			continue
+		}
+
+		// Events can arrive after the pod status that makes them stale
+		// (e.g., the informer re-lists them), so trust the pod's phase over them.
+		if pod.Phase == v1.PodRunning {
+			continue
+		}
+
+		if pod.EventAlerts == nil {
+			pod.EventAlerts = make(map[string]string)
+		}
+		pod.EventAlerts[e.Reason] = alert
+	}
+}
+
+func containerLimits(pod *v1.Pod) map[container.Name]v1.ResourceList {
+	result := make(map[container.Name]v1.ResourceList, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		if len(c.Resources.Limits) > 0 {
+			result[container.Name(c.Name)] = c.Resources.Limits
+		}
+	}
+	return result
+}
+
+func handlePodMetricsAction(state *store.EngineState, action PodMetricsAction) {
+	for _, pm := range action.Metrics {
+		for _, mt := range state.ManifestTargets {
+			pod, ok := mt.State.PodSet.Pods[pm.PodID]
+			if !ok || pod.Namespace != pm.Namespace {
+				continue
+			}
+			pod.ContainerMetrics = pm.Containers
+		}
+	}
+}
+
 func handlePodLogAction(state *store.EngineState, action PodLogAction) {
 	manifestName := action.ManifestName
 	ms, ok := state.ManifestState(manifestName)
@@ -772,7 +1420,7 @@ func handleBuildLogAction(state *store.EngineState, action BuildLogAction) {
 	manifestName := action.ManifestName
 	ms, ok := state.ManifestState(manifestName)
 
-	if !ok || state.CurrentlyBuilding != manifestName {
+	if !ok || !state.CurrentlyBuilding[manifestName] {
 		// This is OK. The user could have edited the manifest recently.
 		return
 	}
@@ -787,7 +1435,7 @@ func handleLogAction(state *store.EngineState, action store.LogAction) {
 
 func handleServiceEvent(ctx context.Context, state *store.EngineState, action ServiceChangeAction) {
 	service := action.Service
-	manifestName := model.ManifestName(service.ObjectMeta.Labels[k8s.ManifestNameLabel])
+	manifestName := manifestNameFromLabels(state, service.ObjectMeta.Labels)
 	if manifestName == "" || manifestName == model.UnresourcedYAMLManifestName {
 		return
 	}
@@ -799,23 +1447,95 @@ func handleServiceEvent(ctx context.Context, state *store.EngineState, action Se
 	}
 
 	ms.LBs[k8s.ServiceName(service.Name)] = action.URL
+	ms.Services[store.ServiceKey(k8s.Namespace(service.Namespace), k8s.ServiceName(service.Name))] = true
+	updateIngressURLs(state)
+}
+
+func handleIngressChangeAction(state *store.EngineState, action IngressChangeAction) {
+	ing := action.Ingress
+	key := fmt.Sprintf("%s/%s", ing.Namespace, ing.Name)
+	if state.Ingresses == nil {
+		state.Ingresses = make(map[string]store.Ingress)
+	}
+	if action.Deleted || ing.DeletionTimestamp != nil {
+		delete(state.Ingresses, key)
+	} else {
+		state.Ingresses[key] = store.Ingress{
+			Namespace: k8s.Namespace(ing.Namespace),
+			Manifest:  manifestNameFromLabels(state, ing.ObjectMeta.Labels),
+			Endpoints: k8s.IngressEndpoints(ing),
+		}
+	}
+	updateIngressURLs(state)
+}
+
+// Ingresses usually aren't part of the resource whose Services they route to
+// (they often route to several), so we give each resource the URLs of the rules
+// that route to its Services, as well as the URLs of any Ingress it deployed itself.
+// An Ingress can only route to Services in its own namespace.
+func updateIngressURLs(state *store.EngineState) {
+	for _, mt := range state.ManifestTargets {
+		ms := mt.State
+		ms.IngressURLs = nil
+		for _, ing := range state.Ingresses {
+			for _, ep := range ing.Endpoints {
+				ownsService := ms.Services[store.ServiceKey(ing.Namespace, ep.ServiceName)]
+				if ownsService || ing.Manifest == mt.Manifest.Name {
+					ms.IngressURLs = append(ms.IngressURLs, ep.URL)
+				}
+			}
+		}
+	}
+}
+
+func handleReplicaSetChangeAction(ctx context.Context, state *store.EngineState, action ReplicaSetChangeAction) {
+	rs := action.ReplicaSet
+	manifestName := manifestNameFromLabels(state, rs.ObjectMeta.Labels)
+	if manifestName == "" || manifestName == model.UnresourcedYAMLManifestName {
+		return
+	}
+
+	ms, ok := state.ManifestState(manifestName)
+	if !ok {
+		logger.Get(ctx).Debugf("got notified of replica set for unknown manifest '%s'", manifestName)
+		return
+	}
+
+	if ms.ReplicaSets == nil {
+		ms.ReplicaSets = make(map[string]store.ReplicaSet)
+	}
+	if rs.DeletionTimestamp != nil {
+		delete(ms.ReplicaSets, rs.Name)
+		return
+	}
+	ms.ReplicaSets[rs.Name] = store.ReplicaSet{
+		Name:       rs.Name,
+		Deployment: k8s.ReplicaSetOwner(rs),
+		Revision:   k8s.ReplicaSetRevision(rs),
+		Replicas:   rs.Status.Replicas,
+	}
 }
 
+// Writes a snapshot of the engine state to a temp file, so that users can
+// attach it to bug reports, and we can look at it with `tilt replay`.
 func handleDumpEngineStateAction(ctx context.Context, engineState *store.EngineState) {
-	f, err := ioutil.TempFile("", "tilt-engine-state-*.txt")
+	f, err := ioutil.TempFile("", "tilt-snapshot-*.json")
 	if err != nil {
 		logger.Get(ctx).Infof("error creating temp file to write engine state: %v", err)
 		return
 	}
 
-	logger.Get(ctx).Infof("dumped tilt engine state to %q", f.Name())
-	spew.Fdump(f, engineState)
+	err = json.NewEncoder(f).Encode(store.NewSnapshot(*engineState))
+	if err != nil {
+		logger.Get(ctx).Infof("error writing engine state: %v", err)
+	}
 
 	err = f.Close()
 	if err != nil {
 		logger.Get(ctx).Infof("error closing engine state temp file: %v", err)
 		return
 	}
+	logger.Get(ctx).Infof("dumped tilt engine state to %q (view it with `tilt replay %s`)", f.Name(), f.Name())
 }
 
 func handleInitAction(ctx context.Context, engineState *store.EngineState, action InitAction) error {
@@ -916,6 +1636,47 @@ func handleDockerComposeLogAction(state *store.EngineState, action DockerCompose
 	ms.CombinedLog = model.AppendLog(ms.CombinedLog, action, state.LogTimestamps)
 }
 
+func handleLocalServeStatusAction(state *store.EngineState, action LocalServeStatusAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+
+	// Ignore news about a serve_cmd that we've already restarted.
+	lState := ms.LocalResourceState()
+	if action.StartTime.Before(lState.StartTime) {
+		return
+	}
+
+	// A new process gets a fresh log. We hear about it before it starts, so that
+	// none of its output lands in the previous process's log.
+	if action.Status == store.LocalServeStatusRunning && !action.StartTime.Equal(lState.StartTime) {
+		ms.ResourceState = store.LocalResourceState{
+			Status:    action.Status,
+			PID:       action.PID,
+			StartTime: action.StartTime,
+		}
+		return
+	}
+
+	lState.Status = action.Status
+	lState.PID = action.PID
+	lState.StartTime = action.StartTime
+	ms.ResourceState = lState
+}
+
+func handleLocalServeLogAction(state *store.EngineState, action LocalServeLogAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+
+	lState := ms.LocalResourceState()
+	lState.CurrentLog = model.AppendLog(lState.CurrentLog, action, state.LogTimestamps)
+	ms.ResourceState = lState
+	ms.CombinedLog = model.AppendLog(ms.CombinedLog, action, state.LogTimestamps)
+}
+
 func handleTiltfileLogAction(ctx context.Context, state *store.EngineState, action TiltfileLogAction) {
 	state.CurrentTiltfileBuild.Log = model.AppendLog(state.CurrentTiltfileBuild.Log, action, state.LogTimestamps)
 	state.TiltfileCombinedLog = model.AppendLog(state.TiltfileCombinedLog, action, state.LogTimestamps)
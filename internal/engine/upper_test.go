@@ -20,9 +20,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/windmilleng/tilt/internal/assets"
 	"github.com/windmilleng/wmclient/pkg/analytics"
+	"github.com/windmilleng/wmclient/pkg/dirs"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/windmilleng/tilt/internal/build"
 	"github.com/windmilleng/tilt/internal/container"
@@ -130,6 +134,10 @@ type fakeBuildAndDeployer struct {
 	// Set this to simulate the build failing. Do not set this directly, use fixture.SetNextBuildFailure
 	nextBuildFailure error
 
+	// Set this to simulate a slow build that only finishes when it's canceled.
+	// Do not set this directly, use fixture.SetNextBuildBlocks
+	nextBuildBlocks bool
+
 	buildLogOutput map[model.TargetID]string
 }
 
@@ -184,6 +192,12 @@ func (b *fakeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RSto
 		return store.BuildResultSet{}, err
 	}
 
+	if b.nextBuildBlocks {
+		b.nextBuildBlocks = false
+		<-ctx.Done()
+		return store.BuildResultSet{}, ctx.Err()
+	}
+
 	dID := testDeployID
 	if b.nextDeployID != 0 {
 		dID = b.nextDeployID
@@ -480,6 +494,77 @@ func TestRebuildWithChangedFiles(t *testing.T) {
 	f.assertAllBuildsConsumed()
 }
 
+func TestCancelBuildSupersededByNewChanges(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	sync := model.Sync{LocalPath: f.Path(), ContainerPath: "/go"}
+	manifest := f.newManifest("foobar", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCallComplete("first build")
+
+	// Start a slow build from a change to a.go.
+	f.SetNextBuildBlocks()
+	f.fsWatcher.events <- watch.FileEvent{Path: f.JoinPath("a.go")}
+	f.WaitUntilManifestState("slow build started", "foobar", func(ms store.ManifestState) bool {
+		return !ms.CurrentBuild.StartTime.IsZero()
+	})
+
+	// A change to b.go while the build is running should cancel it...
+	f.fsWatcher.events <- watch.FileEvent{Path: f.JoinPath("b.go")}
+	call := f.nextCallComplete("canceled build")
+	assert.Equal(t, []string{f.JoinPath("a.go")}, call.oneState().FilesChanged())
+
+	// ...and start a new build with both changes.
+	call = f.nextCallComplete("build with newer changes")
+	assert.Equal(t, []string{f.JoinPath("a.go"), f.JoinPath("b.go")}, call.oneState().FilesChanged())
+	assert.Equal(t, "docker.io/library/foobar:tilt-1", call.oneState().LastImageAsString())
+
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		assert.NoError(t, ms.LastBuild().Error)
+		assert.False(t, ms.LastBuild().Canceled)
+		assert.Equal(t, BuildCanceledError{reason: "superseded by newer changes"}, ms.BuildHistory[1].Error)
+		assert.True(t, ms.BuildHistory[1].Canceled)
+	})
+
+	err := f.Stop()
+	assert.NoError(t, err)
+	f.assertAllBuildsConsumed()
+}
+
+func TestCancelBuildFromUser(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	sync := model.Sync{LocalPath: f.Path(), ContainerPath: "/go"}
+	manifest := f.newManifest("foobar", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCallComplete("first build")
+
+	f.SetNextBuildBlocks()
+	f.fsWatcher.events <- watch.FileEvent{Path: f.JoinPath("a.go")}
+	f.WaitUntilManifestState("slow build started", "foobar", func(ms store.ManifestState) bool {
+		return !ms.CurrentBuild.StartTime.IsZero()
+	})
+
+	f.store.Dispatch(view.CancelBuildAction{Name: "foobar"})
+	f.nextCallComplete("canceled build")
+
+	f.WaitUntilManifestState("canceled build recorded", "foobar", func(ms store.ManifestState) bool {
+		return ms.LastBuild().Error != nil
+	})
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		assert.Equal(t, BuildCanceledError{reason: "canceled by user"}, ms.LastBuild().Error)
+		assert.True(t, ms.LastBuild().Canceled)
+	})
+
+	// Canceling isn't a permanent error, and doesn't kick off another build.
+	f.assertNoCall("no build after cancel")
+	err := f.Stop()
+	assert.NoError(t, err)
+	f.assertAllBuildsConsumed()
+}
+
 func TestThreeBuilds(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -502,9 +587,10 @@ func TestThreeBuilds(t *testing.T) {
 	assert.Equal(t, []string{f.JoinPath("b.go")}, call.oneState().FilesChanged())
 
 	f.withManifestState("fe", func(ms store.ManifestState) {
-		assert.Equal(t, 2, len(ms.BuildHistory))
+		assert.Equal(t, 3, len(ms.BuildHistory))
 		assert.Equal(t, []string{f.JoinPath("b.go")}, ms.BuildHistory[0].Edits)
 		assert.Equal(t, []string{f.JoinPath("a.go")}, ms.BuildHistory[1].Edits)
+		assert.Empty(t, ms.BuildHistory[2].Edits)
 	})
 
 	err := f.Stop()
@@ -578,6 +664,94 @@ func TestRebuildDockerfileViaImageBuild(t *testing.T) {
 	f.assertAllBuildsConsumed()
 }
 
+func TestTiltfileChangeToYAMLKeepsImageBuildState(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	f.WriteFile("Tiltfile", `
+docker_build('gcr.io/windmill-public-containers/servantes/snack', 'src')
+k8s_yaml('snack.yaml')
+`)
+	f.WriteFile("src/Dockerfile", `FROM iron/go:prod`)
+	f.WriteFile("snack.yaml", simpleYAML)
+
+	f.loadAndStart()
+
+	call := f.nextCall("old manifest")
+	assert.False(t, call.oneState().HasImage())
+
+	f.WriteConfigFiles("snack.yaml", strings.Replace(simpleYAML, "/go/bin/snack", "/go/bin/snack-v2", 1))
+
+	// Only the YAML changed, so we redeploy with the image we already built.
+	call = f.nextCall("new yaml")
+	assert.Contains(t, call.k8s().YAML, "/go/bin/snack-v2")
+	assert.True(t, call.oneState().HasImage())
+	assert.Empty(t, call.oneState().FilesChanged())
+
+	err := f.Stop()
+	assert.NoError(t, err)
+	f.assertAllBuildsConsumed()
+}
+
+func TestTiltfileChangePendingOnlyInBuildContext(t *testing.T) {
+	state := store.NewState()
+	state.TiltfilePath = "/project/Tiltfile"
+
+	// A local_resource that watches the whole project, and an image
+	// whose build context is the whole project.
+	lint := model.Manifest{Name: "lint"}.WithDeployTarget(
+		model.NewLocalTarget("lint", model.ToShellCmd("echo lint"), model.Cmd{}, []string{"/project"}, "/project"))
+	iTarget := model.NewImageTarget(container.MustParseSelector("snack")).
+		WithBuildDetails(model.DockerBuild{BuildPath: "/project"})
+	snack := model.Manifest{Name: "snack"}.WithImageTarget(iTarget)
+	for _, m := range []model.Manifest{lint, snack} {
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	}
+
+	ctx := testoutput.CtxForTest()
+	files := []string{"/project/Tiltfile"}
+	handleFSEvent(ctx, state, targetFilesChangedAction{targetID: lint.LocalTarget().ID(), files: files, time: time.Now()})
+	handleFSEvent(ctx, state, targetFilesChangedAction{targetID: iTarget.ID(), files: files, time: time.Now()})
+
+	// Reloading the Tiltfile takes care of the local_resource,
+	// but the image has the Tiltfile in it.
+	ms, _ := state.ManifestState("lint")
+	_, pending := ms.HasPendingChanges()
+	assert.True(t, pending.IsZero())
+
+	ms, _ = state.ManifestState("snack")
+	_, ok := ms.BuildStatus(iTarget.ID()).PendingFileChanges["/project/Tiltfile"]
+	assert.True(t, ok)
+}
+
+func TestTiltfileChangeInBuildContextRebuildsImage(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	// The Tiltfile is in the build context, and gets copied into the image.
+	tiltfile := `
+docker_build('gcr.io/windmill-public-containers/servantes/snack', '.')
+k8s_yaml('snack.yaml')
+`
+	f.WriteFile("Tiltfile", tiltfile)
+	f.WriteFile("Dockerfile", "FROM iron/go:prod\nCOPY . /src")
+	f.WriteFile("snack.yaml", simpleYAML)
+
+	f.loadAndStart()
+	f.nextCall("first build")
+	f.WaitUntilManifestState("first build complete", "snack", func(ms store.ManifestState) bool {
+		return len(ms.BuildHistory) == 1
+	})
+
+	f.WriteConfigFiles("Tiltfile", tiltfile+"# just a comment\n")
+
+	call := f.nextCall("Tiltfile change in the build context")
+	assert.Equal(t, []string{f.JoinPath("Tiltfile")}, call.oneState().FilesChanged())
+
+	err := f.Stop()
+	assert.NoError(t, err)
+	f.assertAllBuildsConsumed()
+}
+
 func TestMultipleChangesOnlyDeployOneManifest(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -1157,6 +1331,277 @@ func TestPodEventContainerStatus(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestPodEventInitContainerInfos(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	sync := model.Sync{LocalPath: "/go", ContainerPath: "/go"}
+	manifest := f.newManifest("foobar", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	var ref reference.NamedTagged
+	f.WaitUntilManifestState("image appears", "foobar", func(ms store.ManifestState) bool {
+		ref = ms.BuildStatus(manifest.ImageTargetAt(0).ID()).LastSuccessfulResult.Image
+		return ref != nil
+	})
+
+	pod := f.testPod("my-pod", "foobar", "Pending", testContainer, time.Now())
+	pod.Status = k8s.FakePodStatus(ref, "Pending")
+	pod.Status.ContainerStatuses[0].ContainerID = ""
+	pod.Status.InitContainerStatuses = []v1.ContainerStatus{
+		{Name: "migrate", ContainerID: "docker://migrate-id"},
+	}
+	pod.Spec = k8s.FakePodSpec(ref)
+
+	f.podEvent(pod)
+
+	podState := store.Pod{}
+	f.WaitUntilManifestState("container infos", "foobar", func(ms store.ManifestState) bool {
+		podState = ms.MostRecentPod()
+		return podState.PodID == "my-pod" && len(podState.ContainerInfos) > 0
+	})
+
+	// The main container hasn't started yet, so we only know about the init container.
+	assert.Equal(t, []store.ContainerInfo{{ID: "migrate-id", Name: "migrate", Init: true}}, podState.ContainerInfos)
+
+	err := f.Stop()
+	assert.Nil(t, err)
+}
+
+func TestPortForwardStatus(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	sync := model.Sync{LocalPath: "/go", ContainerPath: "/go"}
+	manifest := f.newManifest("foobar", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	var ref reference.NamedTagged
+	f.WaitUntilManifestState("image appears", "foobar", func(ms store.ManifestState) bool {
+		ref = ms.BuildStatus(manifest.ImageTargetAt(0).ID()).LastSuccessfulResult.Image
+		return ref != nil
+	})
+
+	f.podEvent(f.testPod("my-pod", "foobar", "Running", testContainer, time.Now()))
+	f.WaitUntilManifestState("pod appears", "foobar", func(ms store.ManifestState) bool {
+		return ms.MostRecentPod().PodID == "my-pod"
+	})
+
+	f.store.Dispatch(PortForwardStatusAction{
+		ManifestName: "foobar",
+		PodID:        "my-pod",
+		LocalPort:    8080,
+		Status:       store.PortForwardConnected,
+	})
+	f.store.Dispatch(PortForwardStatusAction{
+		ManifestName: "foobar",
+		PodID:        "my-pod",
+		LocalPort:    8081,
+		Status:       store.PortForwardReconnecting,
+	})
+	f.WaitUntilManifestState("reconnecting", "foobar", func(ms store.ManifestState) bool {
+		return ms.MostRecentPod().PortForwardStatus() == store.PortForwardReconnecting
+	})
+
+	f.store.Dispatch(PortForwardStatusAction{
+		ManifestName: "foobar",
+		PodID:        "my-pod",
+		LocalPort:    8081,
+		Status:       store.PortForwardConnected,
+	})
+	f.WaitUntilManifestState("connected", "foobar", func(ms store.ManifestState) bool {
+		return ms.MostRecentPod().PortForwardStatus() == store.PortForwardConnected
+	})
+
+	err := f.Stop()
+	assert.Nil(t, err)
+}
+
+func TestPodAlerts(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	sync := model.Sync{LocalPath: "/go", ContainerPath: "/go"}
+	manifest := f.newManifest("foobar", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	var ref reference.NamedTagged
+	f.WaitUntilManifestState("image appears", "foobar", func(ms store.ManifestState) bool {
+		ref = ms.BuildStatus(manifest.ImageTargetAt(0).ID()).LastSuccessfulResult.Image
+		return ref != nil
+	})
+
+	pod := f.testPod("my-pod", "foobar", "Pending", testContainer, time.Now())
+	pod.Status = k8s.FakePodStatus(ref, "Pending")
+	pod.Status.ContainerStatuses[0].State = v1.ContainerState{
+		Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"},
+	}
+	f.podEvent(pod)
+
+	mountEvent := &v1.Event{
+		Type:           v1.EventTypeWarning,
+		Reason:         "FailedMount",
+		Message:        `configmap "app-config" not found`,
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "my-pod"},
+	}
+	f.store.Dispatch(K8sEventAction{Event: mountEvent})
+
+	f.WaitUntilManifestState("mount alert", "foobar", func(ms store.ManifestState) bool {
+		alerts := ms.MostRecentPod().Alerts()
+		return len(alerts) == 1 && alerts[0] == `Pod can't mount a volume: configmap "app-config" not found`
+	})
+
+	// Once the pod is running, the mount alert goes away, and we find out it's crashing.
+	pod = f.testPod("my-pod", "foobar", "Running", testContainer, time.Now())
+	pod.Status = k8s.FakePodStatus(ref, "Running")
+	pod.Status.ContainerStatuses[0].State = v1.ContainerState{
+		Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+	}
+	f.podEvent(pod)
+
+	f.WaitUntilManifestState("crash alert", "foobar", func(ms store.ManifestState) bool {
+		alerts := ms.MostRecentPod().Alerts()
+		return len(alerts) == 1 && strings.Contains(alerts[0], "keeps crashing")
+	})
+
+	// A mount event that shows up late doesn't bring the mount alert back.
+	f.store.Dispatch(K8sEventAction{Event: mountEvent})
+	f.WaitUntilManifestState("late mount event logged", "foobar", func(ms store.ManifestState) bool {
+		return strings.Count(ms.CombinedLog.String(), "FailedMount") == 2
+	})
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		alerts := ms.MostRecentPod().Alerts()
+		if assert.Equal(t, 1, len(alerts)) {
+			assert.Contains(t, alerts[0], "keeps crashing")
+		}
+	})
+
+	err := f.Stop()
+	assert.Nil(t, err)
+}
+
+func TestK8sEventsInResourceLog(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	manifest := f.newManifest("foobar", nil)
+	kTarget := manifest.K8sTarget()
+	kTarget.YAML = testyaml.SanchoYAML
+	manifest = manifest.WithDeployTarget(kTarget)
+	f.Start([]model.Manifest{manifest}, true)
+
+	pod := f.testPod("my-pod", "foobar", "Pending", testContainer, time.Now())
+	f.podEvent(pod)
+	f.WaitUntilManifestState("pod appears", "foobar", func(ms store.ManifestState) bool {
+		return ms.PodSet.ContainsID("my-pod")
+	})
+
+	f.store.Dispatch(K8sEventAction{Event: &v1.Event{
+		Type:           v1.EventTypeNormal,
+		Reason:         "ScalingReplicaSet",
+		Message:        "Scaled up replica set sancho-5d8f7b to 1",
+		InvolvedObject: v1.ObjectReference{Kind: "Deployment", Name: "sancho", Namespace: "sancho-ns"},
+	}})
+	f.store.Dispatch(K8sEventAction{Event: &v1.Event{
+		Type:           v1.EventTypeWarning,
+		Reason:         "Unhealthy",
+		Message:        "Readiness probe failed: connection refused",
+		Count:          3,
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "my-pod", Namespace: pod.Namespace},
+	}})
+
+	// Events about objects that aren't ours stay out of the log.
+	f.store.Dispatch(K8sEventAction{Event: &v1.Event{
+		Type:           v1.EventTypeNormal,
+		Reason:         "Pulled",
+		Message:        "Container image pulled",
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "someone-elses-pod"},
+	}})
+	f.store.Dispatch(K8sEventAction{Event: &v1.Event{
+		Type:           v1.EventTypeNormal,
+		Reason:         "ScalingReplicaSet",
+		Message:        "Scaled up replica set in someone else's namespace",
+		InvolvedObject: v1.ObjectReference{Kind: "Deployment", Name: "sancho", Namespace: "other-ns"},
+	}})
+
+	f.WaitUntilManifestState("events in log", "foobar", func(ms store.ManifestState) bool {
+		log := ms.CombinedLog.String()
+		return strings.Contains(log, "[event: deployment/sancho] ScalingReplicaSet: Scaled up replica set sancho-5d8f7b to 1") &&
+			strings.Contains(log, "[event: pod/my-pod] WARNING Unhealthy: Readiness probe failed: connection refused (x3)")
+	})
+
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		assert.NotContains(t, ms.CombinedLog.String(), "someone-elses-pod")
+		assert.NotContains(t, ms.CombinedLog.String(), "someone else's namespace")
+
+		// The resource detail view shows the most recent events first.
+		if assert.Equal(t, 2, len(ms.K8sEvents)) {
+			assert.Equal(t, "pod/my-pod", ms.K8sEvents[0].Object)
+			assert.Equal(t, "Unhealthy", ms.K8sEvents[0].Reason)
+			assert.Equal(t, int32(3), ms.K8sEvents[0].Count)
+			assert.Equal(t, "deployment/sancho", ms.K8sEvents[1].Object)
+		}
+	})
+
+	err := f.Stop()
+	assert.Nil(t, err)
+}
+
+func TestReplicaSetRollout(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	manifest := f.newManifest("foobar", nil)
+	f.Start([]model.Manifest{manifest}, true)
+
+	rs := func(name string, revision string, replicas int32) *appsv1.ReplicaSet {
+		isController := true
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      map[string]string{k8s.ManifestNameLabel: "foobar"},
+				Annotations: map[string]string{"deployment.kubernetes.io/revision": revision},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Deployment", Name: "foobar", Controller: &isController},
+				},
+			},
+			Status: appsv1.ReplicaSetStatus{Replicas: replicas},
+		}
+	}
+
+	f.store.Dispatch(ReplicaSetChangeAction{ReplicaSet: rs("foobar-1", "1", 1)})
+	f.store.Dispatch(ReplicaSetChangeAction{ReplicaSet: rs("foobar-2", "2", 1)})
+	f.WaitUntilManifestState("rollout in progress", "foobar", func(ms store.ManifestState) bool {
+		return len(ms.ReplicaSets) == 2 && ms.RolloutInProgress()
+	})
+
+	// The old ReplicaSet scales down once the new pods are up.
+	f.store.Dispatch(ReplicaSetChangeAction{ReplicaSet: rs("foobar-1", "1", 0)})
+	f.WaitUntilManifestState("rollout finished", "foobar", func(ms store.ManifestState) bool {
+		return len(ms.ReplicaSets) == 2 && !ms.RolloutInProgress()
+	})
+
+	err := f.Stop()
+	assert.Nil(t, err)
+}
+
+func TestReplicaSetWithCustomManifestLabel(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	manifest := f.newManifest("foobar", nil)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithObjectMetadata(nil, nil, "example.com/component"))
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.store.Dispatch(ReplicaSetChangeAction{ReplicaSet: &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foobar-1",
+			Labels:      map[string]string{"example.com/component": "foobar"},
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "1"},
+		},
+	}})
+	f.WaitUntilManifestState("replica set associated", "foobar", func(ms store.ManifestState) bool {
+		return len(ms.ReplicaSets) == 1
+	})
+
+	err := f.Stop()
+	assert.Nil(t, err)
+}
+
 func TestPodEventContainerStatusWithoutImage(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -1215,6 +1660,43 @@ func TestPodEventContainerStatusWithoutImage(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestPodEventExtraPodSelectorsWithoutBuiltImage(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	manifest := f.newManifest("foobar", nil)
+	k8sTarget := manifest.K8sTarget()
+	k8sTarget.ExtraPodSelectors = []labels.Selector{labels.Set{"app": "operated"}.AsSelector()}
+	manifest = manifest.WithDeployTarget(k8sTarget)
+	f.Start([]model.Manifest{manifest}, true)
+	f.waitForCompletedBuildCount(1)
+
+	// An operator created this pod, so it has none of our labels (except what the
+	// unresourced YAML gave it), and doesn't run the image we built.
+	ref := container.MustParseNamedTagged("quay.io/operated/db:v1")
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "operated-db-0",
+			CreationTimestamp: metav1.Now(),
+			Labels: map[string]string{
+				"app":                 "operated",
+				k8s.ManifestNameLabel: model.UnresourcedYAMLManifestName.String(),
+			},
+		},
+		Status: k8s.FakePodStatus(ref, "Running"),
+	}
+	pod.Status.ContainerStatuses[0].Name = "db"
+	pod.Status.ContainerStatuses[0].ContainerID = "docker://db-container-id"
+	f.podEvent(pod)
+
+	f.WaitUntilManifestState("pod status", "foobar", func(ms store.ManifestState) bool {
+		podState := ms.MostRecentPod()
+		return podState.PodID == "operated-db-0" && podState.ContainerName == "db"
+	})
+
+	err := f.Stop()
+	assert.Nil(t, err)
+}
+
 func TestPodUnexpectedContainerStartsImageBuild(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -1237,7 +1719,8 @@ func TestPodUnexpectedContainerStartsImageBuild(t *testing.T) {
 		StartTime:    time.Now(),
 	})
 	f.store.Dispatch(BuildCompleteAction{
-		Result: containerResultSet(manifest, "theOriginalContainer"),
+		ManifestName: manifest.Name,
+		Result:       containerResultSet(manifest, "theOriginalContainer"),
 	})
 	f.setDeployIDForManifest(manifest, testDeployID)
 
@@ -1284,7 +1767,8 @@ func TestPodUnexpectedContainerStartsImageBuildOutOfOrderEvents(t *testing.T) {
 	// ...and finish the build. Even though this action comes in AFTER the pod
 	// event w/ unexpected container,  we should still be able to detect the mismatch.
 	f.store.Dispatch(BuildCompleteAction{
-		Result: containerResultSet(manifest, "theOriginalContainer"),
+		ManifestName: manifest.Name,
+		Result:       containerResultSet(manifest, "theOriginalContainer"),
 	})
 
 	f.WaitUntilManifestState("NeedsRebuildFromCrash set to True", "foobar", func(ms store.ManifestState) bool {
@@ -1319,7 +1803,8 @@ func TestPodUnexpectedContainerAfterSuccessfulUpdate(t *testing.T) {
 	})
 	podStartTime := time.Now()
 	f.store.Dispatch(BuildCompleteAction{
-		Result: containerResultSet(manifest, "normal-container-id"),
+		ManifestName: manifest.Name,
+		Result:       containerResultSet(manifest, "normal-container-id"),
 	})
 	f.setDeployIDForManifest(manifest, testDeployID)
 
@@ -1341,7 +1826,8 @@ func TestPodUnexpectedContainerAfterSuccessfulUpdate(t *testing.T) {
 	// Simulate a pod crash, then a build completion
 	f.podEvent(f.testPod("mypod", "foobar", "Running", "funny-container-id", podStartTime))
 	f.store.Dispatch(BuildCompleteAction{
-		Result: containerResultSet(manifest, "normal-container-id"),
+		ManifestName: manifest.Name,
+		Result:       containerResultSet(manifest, "normal-container-id"),
 	})
 
 	f.WaitUntilManifestState("NeedsRebuildFromCrash set to True", "foobar", func(ms store.ManifestState) bool {
@@ -1352,6 +1838,106 @@ func TestPodUnexpectedContainerAfterSuccessfulUpdate(t *testing.T) {
 	})
 }
 
+func TestBuildStartedCoalescesEdits(t *testing.T) {
+	state := store.NewState()
+	mt := store.NewManifestTarget(model.Manifest{Name: "foobar"})
+	mt.State.ConfigFilesThatCausedChange = []string{"/src/Dockerfile", "/src/a.go"}
+	state.UpsertManifestTarget(mt)
+
+	handleBuildStarted(context.Background(), state, BuildStartedAction{
+		ManifestName: "foobar",
+		StartTime:    time.Now(),
+		FilesChanged: []string{"/src/b.go", "/src/a.go"},
+	})
+
+	assert.Equal(t, []string{"/src/Dockerfile", "/src/a.go", "/src/b.go"}, mt.State.CurrentBuild.Edits)
+	assert.Empty(t, mt.State.ConfigFilesThatCausedChange)
+}
+
+func TestBuildStartedForRemovedManifestStillCounts(t *testing.T) {
+	state := store.NewState()
+
+	handleBuildStarted(context.Background(), state, BuildStartedAction{
+		ManifestName: "foobar",
+		StartTime:    time.Now(),
+	})
+
+	assert.Equal(t, 1, state.StartedBuildCount)
+	assert.True(t, state.CurrentlyBuilding["foobar"])
+}
+
+func TestBuildCompletedRecordsUpdateType(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	f.bc.DisableForTesting()
+
+	sync := model.Sync{LocalPath: "/go", ContainerPath: "/go"}
+	manifest := f.newManifest("foobar", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.store.Dispatch(BuildStartedAction{
+		ManifestName: manifest.Name,
+		StartTime:    time.Now(),
+	})
+	f.store.Dispatch(BuildCompleteAction{
+		ManifestName:   manifest.Name,
+		Result:         containerResultSet(manifest, "theOriginalContainer"),
+		FallbackReason: "don't have info for deployed container",
+	})
+
+	f.WaitUntilManifestState("build recorded", "foobar", func(ms store.ManifestState) bool {
+		return len(ms.BuildHistory) == 1
+	})
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		assert.Equal(t, model.UpdateTypeImageBuild, ms.LastBuild().UpdateType)
+		assert.Equal(t, "don't have info for deployed container", ms.LastBuild().FallbackReason)
+	})
+}
+
+func TestClearError(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	f.bc.DisableForTesting()
+
+	sync := model.Sync{LocalPath: "/go", ContainerPath: "/go"}
+	manifest := f.newManifest("foobar", []model.Sync{sync})
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.store.Dispatch(BuildStartedAction{
+		ManifestName: manifest.Name,
+		StartTime:    time.Now(),
+	})
+	f.store.Dispatch(BuildCompleteAction{
+		ManifestName: manifest.Name,
+		Error:        fmt.Errorf("compile error"),
+	})
+	f.WaitUntilManifestState("build failed", "foobar", func(ms store.ManifestState) bool {
+		return ms.LastBuild().Error != nil
+	})
+
+	f.store.Dispatch(view.ClearErrorAction{Name: manifest.Name})
+	f.WaitUntilManifestState("error cleared", "foobar", func(ms store.ManifestState) bool {
+		return ms.BuildErrorCleared
+	})
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		// The error is still in the history, but we don't show it.
+		assert.Error(t, ms.LastBuild().Error)
+		assert.NoError(t, ms.DisplayedBuildHistory()[0].Error)
+	})
+
+	f.store.Dispatch(BuildStartedAction{
+		ManifestName: manifest.Name,
+		StartTime:    time.Now(),
+	})
+	f.store.Dispatch(BuildCompleteAction{
+		ManifestName: manifest.Name,
+		Error:        fmt.Errorf("another compile error"),
+	})
+	f.WaitUntilManifestState("next error shows", "foobar", func(ms store.ManifestState) bool {
+		return len(ms.BuildHistory) == 2 && !ms.BuildErrorCleared
+	})
+}
+
 func TestPodEventUpdateByTimestamp(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -1480,6 +2066,46 @@ func TestPodContainerStatus(t *testing.T) {
 	f.assertAllBuildsConsumed()
 }
 
+func TestPodReadyIgnoresCrashingSidecar(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	manifest := f.newManifest("fe", nil)
+	f.Start([]model.Manifest{manifest}, true)
+
+	_ = f.nextCall()
+
+	pod := f.testPod("pod-id", "fe", "Running", testContainer, time.Now())
+	pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.1.7"})
+	pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
+		Name:         "istio-proxy",
+		Image:        "docker.io/istio/proxyv2:1.1.7",
+		ContainerID:  k8s.ContainerIDPrefix + "envoy",
+		RestartCount: 3,
+		State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	})
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}}
+	f.podEvent(pod)
+
+	f.WaitUntilManifestState("pod is ready", "fe", func(ms store.ManifestState) bool {
+		p := ms.MostRecentPod()
+		return p.PodID == "pod-id" && p.Ready
+	})
+
+	f.withManifestState("fe", func(ms store.ManifestState) {
+		p := ms.MostRecentPod()
+		assert.Equal(t, "Running", p.Status)
+		assert.Empty(t, p.StatusAlerts)
+		assert.Equal(t, 0, p.ContainerRestarts)
+		assert.Equal(t, container.ID(testContainer), p.ContainerID)
+		if assert.Equal(t, 2, len(p.ContainerInfos)) {
+			assert.True(t, p.ContainerInfos[1].Sidecar)
+		}
+	})
+
+	err := f.Stop()
+	assert.NoError(t, err)
+}
+
 func TestUpper_WatchDockerIgnoredFiles(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -1672,11 +2298,76 @@ func TestUpperBuildImmediatelyAfterCrashRebuild(t *testing.T) {
 	f.assertAllBuildsConsumed()
 }
 
+func TestCrashRebuildBacksOff(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	sync := model.Sync{LocalPath: f.Path(), ContainerPath: "/go"}
+	manifest := f.newManifest("fe", []model.Sync{sync})
+	f.b.nextBuildContainer = testContainer
+	f.Start([]model.Manifest{manifest}, true)
+
+	f.nextCall()
+	f.waitForCompletedBuildCount(1)
+	f.podEvent(f.testPod("pod-id", "fe", "Running", testContainer, time.Now()))
+
+	// The first crash rebuilds right away.
+	f.b.nextBuildContainer = testContainer
+	f.podEvent(f.testPod("pod-id", "fe", "Running", "crash-1", time.Now()))
+	f.nextCall()
+	f.waitForCompletedBuildCount(2)
+	f.withManifestState("fe", func(ms store.ManifestState) {
+		assert.Equal(t, 1, ms.CrashRebuildCount)
+	})
+
+	// If it crashes again without any new changes, we wait.
+	f.b.nextBuildContainer = testContainer
+	f.podEvent(f.testPod("pod-id", "fe", "Running", "crash-2", time.Now()))
+	f.WaitUntilHUDResource("crash backoff", "fe", func(res view.Resource) bool {
+		return !res.CrashBackoffUntil.IsZero()
+	})
+	f.assertNoCall("shouldn't rebuild during a crash backoff")
+
+	var until time.Time
+	f.withManifestState("fe", func(ms store.ManifestState) {
+		assert.True(t, ms.NeedsRebuildFromCrash)
+		until = ms.CrashBackoffUntil
+	})
+	f.store.Dispatch(CrashBackoffExpiredAction{ManifestName: "fe", Until: until})
+	f.nextCall()
+	f.waitForCompletedBuildCount(3)
+	f.withManifestState("fe", func(ms store.ManifestState) {
+		assert.Equal(t, model.BuildReasonFlagCrash, ms.LastBuild().Reason)
+		assert.Equal(t, 2, ms.CrashRebuildCount)
+	})
+
+	// A build with new changes starts the backoff over.
+	f.fsWatcher.events <- watch.FileEvent{Path: f.JoinPath("main.go")}
+	f.nextCall()
+	f.waitForCompletedBuildCount(4)
+	f.withManifestState("fe", func(ms store.ManifestState) {
+		assert.Equal(t, 0, ms.CrashRebuildCount)
+	})
+
+	err := f.Stop()
+	assert.NoError(t, err)
+	f.assertAllBuildsConsumed()
+}
+
+func TestCrashRebuildBackoff(t *testing.T) {
+	assert.Equal(t, time.Duration(0), crashRebuildBackoff(0))
+	assert.Equal(t, 10*time.Second, crashRebuildBackoff(1))
+	assert.Equal(t, 20*time.Second, crashRebuildBackoff(2))
+	assert.Equal(t, 40*time.Second, crashRebuildBackoff(3))
+	assert.Equal(t, 5*time.Minute, crashRebuildBackoff(100))
+}
+
 func testService(serviceName string, manifestName string, ip string, port int) *v1.Service {
 	return &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   serviceName,
-			Labels: map[string]string{k8s.ManifestNameLabel: manifestName},
+			Name:      serviceName,
+			Namespace: "default",
+			Labels:    map[string]string{k8s.ManifestNameLabel: manifestName},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{{
@@ -1759,6 +2450,84 @@ func TestUpper_ServiceEventRemovesURL(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUpper_IngressEvent(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifest := f.newManifest("foobar", nil)
+	f.Start([]model.Manifest{manifest}, true)
+	f.waitForCompletedBuildCount(1)
+
+	// The Ingress was deployed with the unresourced YAML,
+	// so it's tied to foobar by the Service it routes to.
+	ing := testIngress("default", "myservice")
+	f.store.Dispatch(IngressChangeAction{Ingress: ing})
+
+	svc := testService("myservice", "foobar", "", 8080)
+	svc.Status = v1.ServiceStatus{}
+	dispatchServiceChange(f.store, svc, "")
+
+	f.WaitUntilManifestState("ingress url added", "foobar", func(ms store.ManifestState) bool {
+		return len(ms.IngressURLs) == 1 && ms.IngressURLs[0].String() == "http://foobar.example.com/api"
+	})
+
+	f.store.Dispatch(IngressChangeAction{Ingress: ing, Deleted: true})
+
+	f.WaitUntilManifestState("ingress url removed", "foobar", func(ms store.ManifestState) bool {
+		return len(ms.IngressURLs) == 0
+	})
+
+	err := f.Stop()
+	assert.NoError(t, err)
+}
+
+func TestUpper_IngressEventInOtherNamespace(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifest := f.newManifest("foobar", nil)
+	f.Start([]model.Manifest{manifest}, true)
+	f.waitForCompletedBuildCount(1)
+
+	// This Ingress routes to a Service with the same name in another namespace.
+	f.store.Dispatch(IngressChangeAction{Ingress: testIngress("other", "myservice")})
+
+	svc := testService("myservice", "foobar", "", 8080)
+	svc.Status = v1.ServiceStatus{}
+	dispatchServiceChange(f.store, svc, "")
+
+	f.WaitUntilManifestState("service added", "foobar", func(ms store.ManifestState) bool {
+		_, ok := ms.LBs["myservice"]
+		return ok
+	})
+	f.withManifestState("foobar", func(ms store.ManifestState) {
+		assert.Empty(t, ms.IngressURLs)
+	})
+
+	err := f.Stop()
+	assert.NoError(t, err)
+}
+
+func testIngress(ns string, serviceName string) *extv1beta1.Ingress {
+	return &extv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: ns,
+			Labels:    map[string]string{k8s.ManifestNameLabel: model.UnresourcedYAMLManifestName.String()},
+		},
+		Spec: extv1beta1.IngressSpec{
+			Rules: []extv1beta1.IngressRule{{
+				Host: "foobar.example.com",
+				IngressRuleValue: extv1beta1.IngressRuleValue{HTTP: &extv1beta1.HTTPIngressRuleValue{
+					Paths: []extv1beta1.HTTPIngressPath{
+						{Path: "/api", Backend: extv1beta1.IngressBackend{ServiceName: serviceName}},
+					},
+				}},
+			}},
+		},
+	}
+}
+
 func TestUpper_PodLogs(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -2155,7 +2924,7 @@ func TestConfigChangeThatChangesManifestIsIncludedInManifestsChangedFile(t *test
 docker_build('gcr.io/windmill-public-containers/servantes/snack', '.')
 k8s_yaml('snack.yaml')`
 	f.WriteFile("Tiltfile", tiltfile)
-	f.WriteFile("Dockerfile", `FROM iron/go:dev`)
+	f.WriteFile("Dockerfile", "FROM iron/go:dev\nADD . .")
 	f.WriteFile("snack.yaml", simpleYAML)
 
 	f.loadAndStart()
@@ -2250,9 +3019,12 @@ func newTestFixture(t *testing.T) *testFixture {
 	dockerClient := docker.NewFakeClient()
 	reaper := build.NewImageReaper(dockerClient)
 
-	k8s := k8s.NewFakeK8sClient()
-	pw := NewPodWatcher(k8s)
-	sw := NewServiceWatcher(k8s, "")
+	kCli := k8s.NewFakeK8sClient()
+	clients := k8s.NewClientRegistryForTests(kCli)
+	pw := NewPodWatcher(clients)
+	sw := NewServiceWatcher(clients, "")
+	ew := NewEventWatcher(clients)
+	rsw := NewReplicaSetWatcher(clients)
 
 	fakeHud := hud.NewFakeHud()
 
@@ -2263,7 +3035,7 @@ func newTestFixture(t *testing.T) *testFixture {
 	st := store.NewStore(UpperReducer, store.LogActionsFlag(false))
 	st.AddSubscriber(ctx, fSub)
 
-	plm := NewPodLogManager(k8s)
+	plm := NewPodLogManager(clients)
 	bc := NewBuildController(b)
 
 	err := os.Mkdir(f.JoinPath(".git"), os.FileMode(0777))
@@ -2271,9 +3043,9 @@ func newTestFixture(t *testing.T) *testFixture {
 		t.Fatal(err)
 	}
 
-	fwm := NewWatchManager(watcher.newSub, timerMaker.maker())
-	pfc := NewPortForwardController(k8s)
-	ic := NewImageController(reaper)
+	fwm := NewWatchManager(watcher.newSub, timerMaker.maker(), WatchSettingsFlag{})
+	pfc := NewPortForwardController(clients)
+	ic := NewImageController(reaper, ImageGCConfig{})
 	an := analytics.NewMemoryAnalytics()
 	ar := ProvideAnalyticsReporter(an, st)
 
@@ -2282,15 +3054,16 @@ func newTestFixture(t *testing.T) *testFixture {
 	realDcc := dockercompose.NewDockerComposeClient(docker.Env{})
 
 	tfl := tiltfile.ProvideTiltfileLoader(an, realDcc, model.WebURL{})
-	cc := NewConfigsController(tfl)
+	sp := NewStatePersister(dirs.NewWindmillDirAt(f.JoinPath(".windmill")), dockerClient, clients)
+	cc := NewConfigsController(tfl, sp)
 	dcw := NewDockerComposeEventWatcher(fakeDcc)
 	dclm := NewDockerComposeLogManager(fakeDcc)
 	pm := NewProfilerManager()
 	sCli := synclet.NewFakeSyncletClient()
-	sm := NewSyncletManagerForTests(k8s, sCli)
-	hudsc := server.ProvideHeadsUpServerController(0, server.HeadsUpServer{}, assets.NewFakeServer())
+	sm := NewSyncletManagerForTests(kCli, sCli)
+	hudsc := server.ProvideHeadsUpServerController("localhost", 0, 0, server.HeadsUpServer{}, assets.NewFakeServer())
 	subs := []store.Subscriber{
-		fakeHud, pw, sw, plm, pfc, fwm, bc, ic, cc, dcw, dclm, pm, sm, ar, hudsc,
+		fakeHud, pw, sw, ew, rsw, plm, pfc, NewReadinessChecker(), fwm, bc, ic, cc, dcw, dclm, pm, sm, ar, hudsc,
 	}
 	upper := NewUpper(ctx, st, subs)
 
@@ -2395,13 +3168,22 @@ func (f *testFixture) SetNextBuildFailure(err error) {
 	// Don't set the nextBuildFailure flag when a completed build needs to be processed
 	// by the state machine.
 	f.WaitUntil("build complete processed", func(state store.EngineState) bool {
-		return state.CurrentlyBuilding == ""
+		return len(state.CurrentlyBuilding) == 0
 	})
 	_ = f.store.RLockState()
 	f.b.nextBuildFailure = err
 	f.store.RUnlockState()
 }
 
+func (f *testFixture) SetNextBuildBlocks() {
+	f.WaitUntil("build complete processed", func(state store.EngineState) bool {
+		return len(state.CurrentlyBuilding) == 0
+	})
+	_ = f.store.RLockState()
+	f.b.nextBuildBlocks = true
+	f.store.RUnlockState()
+}
+
 func (f *testFixture) setDeployIDForManifest(manifest model.Manifest, dID model.DeployID) {
 	action := NewDeployIDAction(manifest.K8sTarget().ID(), dID)
 	f.store.Dispatch(action)
@@ -0,0 +1,259 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/ospath"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/watch"
+)
+
+// How many directories to list in each part of the stats.
+const watchStatsTopDirs = 10
+const watchStatsMaxIgnoredDirs = 20
+
+var _ store.WatchStatsReporter = &WatchManager{}
+
+// What we've seen from the watch on one target.
+type targetWatchStats struct {
+	manifests []model.ManifestName
+	mode      model.WatchMode
+	paths     []string
+	filter    model.PathMatcher
+	watcher   watch.Notify
+
+	events    int
+	recent    []time.Time
+	dirEvents map[string]int
+}
+
+// Keeps track of the events each target's watch sees, so that users can
+// find out what the watcher is up to.
+type watchStatsTracker struct {
+	mu      sync.Mutex
+	stats   map[model.TargetID]*targetWatchStats
+	timeNow func() time.Time
+}
+
+func newWatchStatsTracker() *watchStatsTracker {
+	return &watchStatsTracker{
+		stats:   make(map[model.TargetID]*targetWatchStats),
+		timeNow: time.Now,
+	}
+}
+
+// Called when we (re-)start watching a target. Keeps the event counts
+// from any earlier watch on the same target.
+func (t *watchStatsTracker) watching(target WatchableTarget, mode model.WatchMode, filter model.PathMatcher, watcher watch.Notify) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.stats[target.ID()]
+	if !ok {
+		s = &targetWatchStats{dirEvents: make(map[string]int)}
+		t.stats[target.ID()] = s
+	}
+	if mode == "" {
+		mode = model.WatchModeNative
+	}
+	s.mode = mode
+	s.paths = nil
+	for _, p := range target.Dependencies() {
+		abs, err := filepath.Abs(p)
+		if err == nil {
+			p = abs
+		}
+		s.paths = append(s.paths, p)
+	}
+	s.filter = filter
+	s.watcher = watcher
+}
+
+// Called when a watch falls back to another mode (e.g., when Watchman goes away).
+func (t *watchStatsTracker) setMode(id model.TargetID, mode model.WatchMode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.stats[id]; ok {
+		s.mode = mode
+	}
+}
+
+func (t *watchStatsTracker) stopped(id model.TargetID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.stats, id)
+}
+
+func (t *watchStatsTracker) setManifests(names map[model.TargetID][]model.ManifestName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, s := range t.stats {
+		s.manifests = names[id]
+	}
+}
+
+func (t *watchStatsTracker) recordEvents(id model.TargetID, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.stats[id]
+	if !ok {
+		return
+	}
+
+	now := t.timeNow()
+	for _, p := range paths {
+		s.events++
+		s.recent = append(s.recent, now)
+		s.dirEvents[filepath.Dir(p)]++
+	}
+	s.trimRecent(now)
+}
+
+func (s *targetWatchStats) trimRecent(now time.Time) {
+	cutoff := now.Add(-time.Minute)
+	i := sort.Search(len(s.recent), func(i int) bool {
+		return s.recent[i].After(cutoff)
+	})
+	s.recent = s.recent[i:]
+}
+
+func (t *watchStatsTracker) WatchStats() []store.WatchStats {
+	t.mu.Lock()
+	now := t.timeNow()
+	result := make([]store.WatchStats, 0, len(t.stats))
+	filters := make([]model.PathMatcher, 0, len(t.stats))
+	watchers := make([]watch.Notify, 0, len(t.stats))
+	for id, s := range t.stats {
+		s.trimRecent(now)
+		result = append(result, store.WatchStats{
+			TargetID:         id,
+			Manifests:        append([]model.ManifestName(nil), s.manifests...),
+			Mode:             s.mode,
+			Paths:            append([]string(nil), s.paths...),
+			Events:           s.events,
+			EventsLastMinute: len(s.recent),
+			NoisiestDirs:     topDirs(s.dirEvents),
+		})
+		filters = append(filters, s.filter)
+		watchers = append(watchers, s.watcher)
+	}
+	t.mu.Unlock()
+
+	// Walking the files can take a while, so do it without holding the lock.
+	for i := range result {
+		if r, ok := watchers[i].(watch.WatchedPathsReporter); ok {
+			result[i].WatchedPaths = r.WatchedPaths()
+			result[i].Watches = len(result[i].WatchedPaths)
+		}
+		countWatchedFiles(&result[i], filters[i])
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TargetID.String() < result[j].TargetID.String()
+	})
+	return result
+}
+
+// Walks the watched paths, skipping the directories that are entirely
+// ignored, to count the files that can trigger builds.
+func countWatchedFiles(stats *store.WatchStats, filter model.PathMatcher) {
+	filesPerDir := make(map[string]int)
+	for _, root := range uncoveredPaths(stats.Paths) {
+		_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info == nil {
+				return nil
+			}
+
+			if info.IsDir() {
+				if path != root && filter != nil {
+					ignored, err := model.MatchesEntireDir(filter, path)
+					if err == nil && ignored {
+						if len(stats.IgnoredDirs) < watchStatsMaxIgnoredDirs {
+							stats.IgnoredDirs = append(stats.IgnoredDirs, path)
+						}
+						return filepath.SkipDir
+					}
+				}
+				return nil
+			}
+
+			if filter != nil {
+				ignored, err := filter.Matches(path, false)
+				if err == nil && ignored {
+					return nil
+				}
+			}
+			stats.Files++
+			filesPerDir[filepath.Dir(path)]++
+			return nil
+		})
+	}
+	stats.LargestDirs = topDirs(filesPerDir)
+}
+
+// Drops the paths inside other paths, so that we don't count anything twice.
+func uncoveredPaths(paths []string) []string {
+	var result []string
+	for i, p := range paths {
+		covered := false
+		for j, other := range paths {
+			if (p == other && j < i) || (p != other && ospath.IsChild(other, p)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func topDirs(counts map[string]int) []store.DirCount {
+	result := make([]store.DirCount, 0, len(counts))
+	for dir, count := range counts {
+		result = append(result, store.DirCount{Dir: dir, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Dir < result[j].Dir
+	})
+	if len(result) > watchStatsTopDirs {
+		result = result[:watchStatsTopDirs]
+	}
+	return result
+}
+
+// The manifests that each target is watched on behalf of.
+func manifestNamesForTargets(manifests []model.Manifest) map[model.TargetID][]model.ManifestName {
+	result := make(map[model.TargetID][]model.ManifestName)
+	for _, m := range manifests {
+		var ids []model.TargetID
+		if m.IsDC() {
+			ids = append(ids, m.DockerComposeTarget().ID())
+		}
+		if m.IsLocal() {
+			ids = append(ids, m.LocalTarget().ID())
+		}
+		for _, iTarget := range m.ImageTargets {
+			ids = append(ids, iTarget.ID())
+		}
+
+		for _, id := range ids {
+			result[id] = append(result[id], m.Name)
+		}
+	}
+	return result
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/windmilleng/tilt/internal/dockerignore"
 	"github.com/windmilleng/tilt/internal/ignore"
 	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/metrics"
 	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/internal/watch"
@@ -39,6 +40,14 @@ func watchableTargetsForManifests(manifests []model.Manifest) []WatchableTarget
 			}
 		}
 
+		if m.IsLocal() {
+			lTarget := m.LocalTarget()
+			if !seen[lTarget.ID()] {
+				watchable = append(watchable, lTarget)
+				seen[lTarget.ID()] = true
+			}
+		}
+
 		for _, iTarget := range m.ImageTargets {
 			if !seen[iTarget.ID()] {
 				watchable = append(watchable, iTarget)
@@ -49,6 +58,31 @@ func watchableTargetsForManifests(manifests []model.Manifest) []WatchableTarget
 	return watchable
 }
 
+// How long to wait for file changes to stop before we tell the engine about them,
+// for each target. If manifests that share a target disagree, we wait the longest.
+func quietPeriodsForManifests(manifests []model.Manifest) map[model.TargetID]time.Duration {
+	result := make(map[model.TargetID]time.Duration)
+	for _, m := range manifests {
+		var ids []model.TargetID
+		if m.IsDC() {
+			ids = append(ids, m.DockerComposeTarget().ID())
+		}
+		if m.IsLocal() {
+			ids = append(ids, m.LocalTarget().ID())
+		}
+		for _, iTarget := range m.ImageTargets {
+			ids = append(ids, iTarget.ID())
+		}
+
+		for _, id := range ids {
+			if m.FileQuietPeriod > result[id] {
+				result[id] = m.FileQuietPeriod
+			}
+		}
+	}
+	return result
+}
+
 // configTarget makes a WatchableTarget that works just for the config files (Tiltfile, yaml, Dockerfiles, etc.)
 type configsTarget struct {
 	dependencies []string
@@ -93,9 +127,10 @@ func newTargetFilesChangedAction(targetID model.TargetID, files ...string) targe
 }
 
 type targetNotifyCancel struct {
-	target WatchableTarget
-	notify watch.Notify
-	cancel func()
+	target      WatchableTarget
+	quietPeriod time.Duration
+	notify      watch.Notify
+	cancel      func()
 }
 
 type WatchManager struct {
@@ -103,14 +138,22 @@ type WatchManager struct {
 	fsWatcherMaker     FsWatcherMaker
 	timerMaker         timerMaker
 	tiltIgnoreContents string
+	watchSettings      model.WatchSettings
+	watchSettingsFlag  WatchSettingsFlag
 	disabledForTesting bool
+
+	warnedWatchmanUnavailable bool
+
+	stats *watchStatsTracker
 }
 
-func NewWatchManager(watcherMaker FsWatcherMaker, timerMaker timerMaker) *WatchManager {
+func NewWatchManager(watcherMaker FsWatcherMaker, timerMaker timerMaker, flag WatchSettingsFlag) *WatchManager {
 	return &WatchManager{
-		targetWatches:  make(map[model.TargetID]targetNotifyCancel),
-		fsWatcherMaker: watcherMaker,
-		timerMaker:     timerMaker,
+		targetWatches:     make(map[model.TargetID]targetNotifyCancel),
+		fsWatcherMaker:    watcherMaker,
+		timerMaker:        timerMaker,
+		watchSettingsFlag: flag,
+		stats:             newWatchStatsTracker(),
 	}
 }
 
@@ -118,14 +161,20 @@ func (w *WatchManager) DisableForTesting() {
 	w.disabledForTesting = true
 }
 
-func (w *WatchManager) diff(ctx context.Context, st store.RStore) (setup []WatchableTarget, teardown []model.TargetID) {
+// The watch settings from the Tiltfile, with the ones from the command line on top.
+func (w *WatchManager) settings() model.WatchSettings {
+	return w.watchSettings.Merge(model.WatchSettings(w.watchSettingsFlag))
+}
+
+func (w *WatchManager) diff(ctx context.Context, st store.RStore) (setup []WatchableTarget, teardown []model.TargetID, quietPeriods map[model.TargetID]time.Duration) {
 	state := st.RLockState()
 	defer st.RUnlockState()
 
 	setup = []WatchableTarget{}
 	teardown = []model.TargetID{}
 
-	watchable := watchableTargetsForManifests(state.Manifests())
+	watchable := watchableTargetsForManifests(state.EnabledManifests())
+	quietPeriods = quietPeriodsForManifests(state.EnabledManifests())
 	targetsToProcess := make(map[model.TargetID]WatchableTarget)
 	for _, w := range watchable {
 		targetsToProcess[w.ID()] = w
@@ -136,6 +185,7 @@ func (w *WatchManager) diff(ctx context.Context, st store.RStore) (setup []Watch
 	}
 
 	tiltIgnoreChanged := w.tiltIgnoreContents != state.TiltIgnoreContents
+	watchSettingsChanged := w.watchSettings != state.WatchSettings
 
 	for name, mnc := range w.targetWatches {
 		m, ok := targetsToProcess[name]
@@ -144,7 +194,7 @@ func (w *WatchManager) diff(ctx context.Context, st store.RStore) (setup []Watch
 			continue
 		}
 
-		if tiltIgnoreChanged || !watchRulesMatch(m, mnc.target) {
+		if tiltIgnoreChanged || watchSettingsChanged || !watchRulesMatch(m, mnc.target) || quietPeriods[name] != mnc.quietPeriod {
 			teardown = append(teardown, name)
 			setup = append(setup, m)
 		}
@@ -157,7 +207,7 @@ func (w *WatchManager) diff(ctx context.Context, st store.RStore) (setup []Watch
 		delete(targetsToProcess, name)
 	}
 
-	return setup, teardown
+	return setup, teardown, quietPeriods
 }
 
 func watchRulesMatch(w1, w2 WatchableTarget) bool {
@@ -168,34 +218,46 @@ func watchRulesMatch(w1, w2 WatchableTarget) bool {
 }
 
 func (w *WatchManager) OnChange(ctx context.Context, st store.RStore) {
-	setup, teardown := w.diff(ctx, st)
+	setup, teardown, quietPeriods := w.diff(ctx, st)
 
 	state := st.RLockState()
 	tiltRoot := filepath.Dir(state.TiltfilePath)
 	w.tiltIgnoreContents = state.TiltIgnoreContents
+	w.watchSettings = state.WatchSettings
+	manifestNames := manifestNamesForTargets(state.EnabledManifests())
 	st.RUnlockState()
 
 	// setup the watch first, to avoid a gap in coverage between setup and
 	// teardown. it's ok if we get a file event twice.
 	newWatches := make(map[model.TargetID]targetNotifyCancel)
 	for _, target := range setup {
-		watcher, err := w.fsWatcherMaker()
+		filter, err := w.fileChangeFilter(target, tiltRoot)
 		if err != nil {
 			st.Dispatch(NewErrorAction(err))
 			continue
 		}
 
-		for _, d := range target.Dependencies() {
-			err = watcher.Add(d)
-			if err != nil {
-				st.Dispatch(NewErrorAction(err))
-			}
+		dedup := w.checksumCache()
+
+		watcher, mode, err := w.makeWatcher(ctx, target, filter)
+		if err != nil {
+			w.handleWatchError(ctx, st, target, err)
+			continue
 		}
+		w.stats.watching(target, mode, filter, watcher)
 
 		ctx, cancel := context.WithCancel(ctx)
 
-		go w.dispatchFileChangesLoop(ctx, target, watcher, st, tiltRoot)
-		newWatches[target.ID()] = targetNotifyCancel{target, watcher, cancel}
+		// Read from the watcher before starting it, so that it doesn't block
+		// on changes that come in while it's still setting up.
+		quietPeriod := quietPeriods[target.ID()]
+		go w.dispatchFileChangesLoop(ctx, target, quietPeriod, watcher, st, filter, dedup)
+		newWatches[target.ID()] = targetNotifyCancel{target, quietPeriod, watcher, cancel}
+
+		err = watcher.Start()
+		if err != nil {
+			w.handleWatchError(ctx, st, target, err)
+		}
 	}
 
 	for _, name := range teardown {
@@ -209,32 +271,105 @@ func (w *WatchManager) OnChange(ctx context.Context, st store.RStore) {
 		}
 		p.cancel()
 		delete(w.targetWatches, name)
+		if _, ok := newWatches[name]; !ok {
+			w.stats.stopped(name)
+		}
 	}
 
 	for k, v := range newWatches {
 		w.targetWatches[k] = v
 	}
+	w.stats.setManifests(manifestNames)
 }
 
-func (w *WatchManager) dispatchFileChangesLoop(
-	ctx context.Context,
-	target WatchableTarget,
-	watcher watch.Notify,
-	st store.RStore,
-	tiltRoot string) {
+// What each target's watch is doing, for `tilt dump watches`.
+func (w *WatchManager) WatchStats() []store.WatchStats {
+	return w.stats.WatchStats()
+}
 
-	filter, err := ignore.CreateFileChangeFilter(target)
-	if err != nil {
+// Also returns the watch mode we ended up using.
+func (w *WatchManager) makeWatcher(ctx context.Context, target WatchableTarget, filter model.PathMatcher) (watch.Notify, model.WatchMode, error) {
+	settings := w.settings()
+	native := settings
+	native.Mode = model.WatchModeNative
+	makeNative := func() (watch.Notify, error) {
+		return w.fsWatcherMaker(target.Dependencies(), filter, native)
+	}
+
+	watcher, err := w.fsWatcherMaker(target.Dependencies(), filter, settings)
+	if err == nil {
+		if settings.IsWatchman() {
+			// If Watchman fails later on, keep watching without it.
+			watcher = watch.NewFallbackWatcher(watcher, makeNative, func(err error) {
+				logger.Get(ctx).Infof("WARNING: Watchman failed: %v. Falling back to the built-in file watcher for %s.", err, target.ID())
+				w.stats.setMode(target.ID(), native.Mode)
+			})
+		}
+		return watcher, settings.Mode, nil
+	}
+	if !watch.IsWatchmanUnavailable(err) {
+		return nil, settings.Mode, err
+	}
+
+	if !w.warnedWatchmanUnavailable {
+		logger.Get(ctx).Infof("WARNING: %v. Falling back to the built-in file watcher.", err)
+		w.warnedWatchmanUnavailable = true
+	}
+
+	watcher, err = makeNative()
+	return watcher, native.Mode, err
+}
+
+// With watch_settings(dedup_by_checksum=True), remembers the contents of the
+// target's files, so that we can drop the changes that don't change them.
+// Returns nil if dedup is off.
+func (w *WatchManager) checksumCache() *watch.ChecksumCache {
+	settings := w.settings()
+	if !settings.DedupByChecksum || settings.IsPoll() {
+		// The polling watcher compares checksums itself.
+		return nil
+	}
+	return watch.NewChecksumCache()
+}
+
+// Running out of OS watches shouldn't take down the whole session.
+// We keep whatever we managed to watch, and tell the user how to raise the limit.
+func (w *WatchManager) handleWatchError(ctx context.Context, st store.RStore, target WatchableTarget, err error) {
+	if !watch.IsWatchLimitError(err) {
 		st.Dispatch(NewErrorAction(err))
 		return
 	}
+	logger.Get(ctx).Infof("WARNING: Not all files for %s are being watched.\n%v", target.ID(), err)
+}
+
+// The files that shouldn't trigger updates for target: its own ignores, the .tiltignore,
+// and (unless the Tiltfile turns them off) editor temp files and VCS internals.
+func (w *WatchManager) fileChangeFilter(target WatchableTarget, tiltRoot string) (model.PathMatcher, error) {
+	filter, err := ignore.CreateFileChangeFilter(target)
+	if err != nil {
+		return nil, err
+	}
 	tiltIgnoreFilter, err := dockerignore.DockerIgnoreTesterFromContents(tiltRoot, w.tiltIgnoreContents)
 	if err != nil {
-		st.Dispatch(NewErrorAction(err))
+		return nil, err
 	}
-	filter = model.NewCompositeMatcher([]model.PathMatcher{filter, tiltIgnoreFilter})
+	matchers := []model.PathMatcher{filter, tiltIgnoreFilter}
+	if !w.settings().NoDefaultIgnores {
+		matchers = append(matchers, watch.NewDefaultIgnores(target.Dependencies()))
+	}
+	return model.NewCompositeMatcher(matchers), nil
+}
 
-	eventsCh := coalesceEvents(w.timerMaker, watcher.Events())
+func (w *WatchManager) dispatchFileChangesLoop(
+	ctx context.Context,
+	target WatchableTarget,
+	quietPeriod time.Duration,
+	watcher watch.Notify,
+	st store.RStore,
+	filter model.PathMatcher,
+	dedup *watch.ChecksumCache) {
+
+	eventsCh := coalesceEvents(w.timerMaker, quietPeriod, watcher.Events())
 
 	for {
 		select {
@@ -262,11 +397,19 @@ func (w *WatchManager) dispatchFileChangesLoop(
 					st.Dispatch(NewErrorAction(err))
 					continue
 				}
-				if !isIgnored {
-					watchEvent.files = append(watchEvent.files, path)
+				if isIgnored {
+					continue
+				}
+
+				// By now the writes have settled, so the file's contents are final.
+				if dedup != nil && !dedup.Changed(path) {
+					continue
 				}
+				watchEvent.files = append(watchEvent.files, path)
 			}
 
+			w.stats.recordEvents(target.ID(), watchEvent.files)
+			metrics.FileEvents.WithLabelValues(target.ID().String()).Add(float64(len(watchEvent.files)))
 			if len(watchEvent.files) > 0 {
 				st.Dispatch(watchEvent)
 			}
@@ -276,7 +419,14 @@ func (w *WatchManager) dispatchFileChangesLoop(
 
 //makes an attempt to read some events from `eventChan` so that multiple file changes that happen at the same time
 //from the user's perspective are grouped together.
-func coalesceEvents(timerMaker timerMaker, eventChan <-chan watch.FileEvent) <-chan []watch.FileEvent {
+//
+//Waits until there haven't been any changes for `quietPeriod` (or `watchBufferMinRestDuration`, if it's 0),
+//so that e.g. a `git checkout` that touches thousands of files only kicks off one build.
+func coalesceEvents(timerMaker timerMaker, quietPeriod time.Duration, eventChan <-chan watch.FileEvent) <-chan []watch.FileEvent {
+	if quietPeriod == 0 {
+		quietPeriod = watchBufferMinRestDuration
+	}
+
 	ret := make(chan []watch.FileEvent)
 	go func() {
 		defer close(ret)
@@ -288,8 +438,8 @@ func coalesceEvents(timerMaker timerMaker, eventChan <-chan watch.FileEvent) <-c
 			}
 			events := []watch.FileEvent{event}
 
-			// keep grabbing changes until we've gone `watchBufferMinRestDuration` without seeing a change
-			minRestTimer := timerMaker(watchBufferMinRestDuration)
+			// keep grabbing changes until we've gone `quietPeriod` without seeing a change
+			minRestTimer := timerMaker(quietPeriod)
 
 			// but if we go too long before seeing a break (e.g., a process is constantly writing logs to that dir)
 			// then just send what we've got
@@ -303,7 +453,7 @@ func coalesceEvents(timerMaker timerMaker, eventChan <-chan watch.FileEvent) <-c
 					if !ok {
 						channelClosed = true
 					} else {
-						minRestTimer = timerMaker(watchBufferMinRestDuration)
+						minRestTimer = timerMaker(quietPeriod)
 						events = append(events, event)
 					}
 				case <-minRestTimer:
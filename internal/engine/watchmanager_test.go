@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/ospath"
 	"github.com/windmilleng/tilt/internal/store"
@@ -121,6 +123,216 @@ func TestWatchManager_PickUpTiltIgnoreChanges(t *testing.T) {
 	assert.Contains(t, observedPaths, "bar/baz/foo")
 }
 
+func TestWatchManager_IgnoresEditorFilesByDefault(t *testing.T) {
+	f := newWMFixture(t)
+	defer f.TearDown()
+
+	target := model.DockerComposeTarget{Name: "foo"}.
+		WithBuildPath(".")
+	f.SetManifestTarget(target)
+
+	f.ChangeFile(t, ".main.go.swp")
+	f.ChangeFile(t, "4913")
+	f.ChangeFile(t, ".git/index")
+	f.ChangeFile(t, "main.go")
+
+	actions := f.Stop(t)
+	assert.Equal(t, []string{"main.go", "stop"}, targetFilesChangedActionsToPaths(actions))
+}
+
+func TestWatchManager_DefaultIgnoresCanBeTurnedOff(t *testing.T) {
+	f := newWMFixture(t)
+	defer f.TearDown()
+
+	f.SetWatchSettings(model.WatchSettings{NoDefaultIgnores: true})
+	target := model.DockerComposeTarget{Name: "foo"}.
+		WithBuildPath(".")
+	f.SetManifestTarget(target)
+
+	f.ChangeFile(t, ".git/index")
+
+	actions := f.Stop(t)
+	assert.Contains(t, targetFilesChangedActionsToPaths(actions), ".git/index")
+}
+
+func TestWatchManager_DedupByChecksum(t *testing.T) {
+	f := newWMFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("same.txt", "hello")
+	f.WriteFile("changed.txt", "hello")
+
+	f.SetWatchSettings(model.WatchSettings{DedupByChecksum: true})
+	target := model.DockerComposeTarget{Name: "foo"}.
+		WithBuildPath(".")
+	f.SetManifestTarget(target)
+
+	// We don't hash files until they change, so the first change always counts.
+	f.ChangeFile(t, "same.txt")
+	f.ChangeFile(t, "changed.txt")
+	first, err := f.ReadActionsUntil("changed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"same.txt", "changed.txt"}, targetFilesChangedActionsToPaths(first))
+
+	f.WriteFile("same.txt", "hello")
+	f.ChangeFile(t, "same.txt")
+	f.WriteFile("changed.txt", "goodbye")
+	f.ChangeFile(t, "changed.txt")
+
+	actions := f.Stop(t)
+	paths := targetFilesChangedActionsToPaths(actions[len(first):])
+	assert.Contains(t, paths, "changed.txt")
+	assert.NotContains(t, paths, "same.txt")
+}
+
+func TestWatchManager_FlagOverridesTiltfileSettings(t *testing.T) {
+	f := newWMFixture(t)
+	defer f.TearDown()
+
+	f.wm.watchSettingsFlag = WatchSettingsFlag{Mode: model.WatchModePoll}
+	f.SetWatchSettings(model.WatchSettings{Mode: model.WatchModeWatchman, DedupByChecksum: true})
+	target := model.DockerComposeTarget{Name: "foo"}.
+		WithBuildPath(".")
+	f.SetManifestTarget(target)
+
+	expected := model.WatchSettings{Mode: model.WatchModePoll, DedupByChecksum: true}
+	assert.Equal(t, []model.WatchSettings{expected}, f.fakeMultiWatcher.getSettings())
+}
+
+func TestWatchManager_WatchesReappliedOnWatchSettingsChange(t *testing.T) {
+	f := newWMFixture(t)
+	defer f.TearDown()
+
+	target := model.DockerComposeTarget{Name: "foo"}.
+		WithBuildPath(".")
+	f.SetManifestTarget(target)
+
+	poll := model.WatchSettings{Mode: model.WatchModePoll, PollInterval: 5 * time.Second}
+	f.SetWatchSettings(poll)
+
+	f.ChangeFile(t, "bar")
+
+	actions := f.Stop(t)
+	assert.Contains(t, targetFilesChangedActionsToPaths(actions), "bar")
+
+	settings := f.fakeMultiWatcher.getSettings()
+	if assert.True(t, len(settings) >= 2) {
+		assert.Equal(t, model.WatchSettings{}, settings[0])
+		assert.Equal(t, poll, settings[len(settings)-1])
+	}
+}
+
+func TestWatchManager_FallsBackWhenWatchmanUnavailable(t *testing.T) {
+	f := newWMFixture(t)
+	defer f.TearDown()
+
+	f.SetWatchSettings(model.WatchSettings{Mode: model.WatchModeWatchman})
+	target := model.DockerComposeTarget{Name: "foo"}.
+		WithBuildPath(".")
+	f.SetManifestTarget(target)
+
+	f.ChangeFile(t, "bar")
+
+	actions := f.Stop(t)
+	assert.Contains(t, targetFilesChangedActionsToPaths(actions), "bar")
+
+	settings := f.fakeMultiWatcher.getSettings()
+	if assert.Len(t, settings, 2) {
+		assert.Equal(t, model.WatchModeWatchman, settings[0].Mode)
+		assert.Equal(t, model.WatchModeNative, settings[1].Mode)
+	}
+}
+
+func TestWatchManager_WatchStats(t *testing.T) {
+	f := newWMFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("main.go", "package main")
+	f.WriteFile("src/a.go", "package src")
+	f.WriteFile("src/b.go", "package src")
+	f.WriteFile("node_modules/left-pad/index.js", "")
+
+	target := model.DockerComposeTarget{Name: "foo"}.
+		WithDockerignores([]model.Dockerignore{{LocalPath: f.Path(), Contents: "node_modules"}}).
+		WithBuildPath(".")
+	f.SetManifestTarget(target)
+
+	f.ChangeFile(t, "src/a.go")
+	f.ChangeFile(t, "src/a.go")
+	f.ChangeFile(t, "node_modules/left-pad/index.js")
+	f.Stop(t)
+
+	stats := f.wm.WatchStats()
+	if !assert.Len(t, stats, 1) {
+		return
+	}
+	s := stats[0]
+	assert.Equal(t, target.ID(), s.TargetID)
+	assert.Equal(t, []model.ManifestName{"foo"}, s.Manifests)
+	assert.Equal(t, model.WatchModeNative, s.Mode)
+	assert.Equal(t, []string{"."}, s.WatchedPaths)
+	assert.Equal(t, 1, s.Watches)
+	assert.Equal(t, 3, s.Files)
+	assert.Equal(t, []string{f.JoinPath("node_modules")}, s.IgnoredDirs)
+	assert.Equal(t, store.DirCount{Dir: f.JoinPath("src"), Count: 2}, s.LargestDirs[0])
+
+	// Changes to ignored files don't count.
+	assert.Equal(t, 3, s.Events)
+	assert.Equal(t, 3, s.EventsLastMinute)
+	assert.Equal(t, []store.DirCount{
+		{Dir: f.JoinPath("src"), Count: 2},
+		{Dir: f.Path(), Count: 1},
+	}, s.NoisiestDirs)
+}
+
+func TestQuietPeriodsForManifests(t *testing.T) {
+	iTarget := model.ImageTarget{ConfigurationRef: container.MustParseSelector("gcr.io/some-project/sancho")}
+	m1 := model.Manifest{Name: "a", FileQuietPeriod: 2 * time.Second}.WithImageTarget(iTarget)
+	m2 := model.Manifest{Name: "b", FileQuietPeriod: 5 * time.Second}.WithImageTarget(iTarget)
+	dcTarget := model.DockerComposeTarget{Name: "c"}
+	m3 := model.Manifest{Name: "c"}.WithDeployTarget(dcTarget)
+
+	periods := quietPeriodsForManifests([]model.Manifest{m1, m2, m3})
+
+	// Manifests that share a target wait for the longest quiet period.
+	assert.Equal(t, 5*time.Second, periods[iTarget.ID()])
+	assert.Equal(t, time.Duration(0), periods[dcTarget.ID()])
+}
+
+func TestCoalesceEventsUsesQuietPeriod(t *testing.T) {
+	for _, tc := range []struct {
+		quietPeriod time.Duration
+		expected    time.Duration
+	}{
+		{0, watchBufferMinRestDuration},
+		{3 * time.Second, 3 * time.Second},
+	} {
+		var durations []time.Duration
+		var mu sync.Mutex
+		timerMaker := func(d time.Duration) <-chan time.Time {
+			mu.Lock()
+			durations = append(durations, d)
+			mu.Unlock()
+			ret := make(chan time.Time, 1)
+			ret <- time.Unix(0, 0)
+			return ret
+		}
+
+		events := make(chan watch.FileEvent, 1)
+		events <- watch.FileEvent{Path: "foo"}
+		batches := coalesceEvents(timerMaker, tc.quietPeriod, events)
+		batch := <-batches
+		close(events)
+
+		assert.Equal(t, []watch.FileEvent{{Path: "foo"}}, batch)
+		mu.Lock()
+		assert.Contains(t, durations, tc.expected)
+		mu.Unlock()
+	}
+}
+
 type wmFixture struct {
 	ctx              context.Context
 	cancel           func()
@@ -136,7 +348,7 @@ func newWMFixture(t *testing.T) *wmFixture {
 	st, getActions := store.NewStoreForTesting()
 	timerMaker := makeFakeTimerMaker(t)
 	fakeMultiWatcher := newFakeMultiWatcher()
-	wm := NewWatchManager(fakeMultiWatcher.newSub, timerMaker.maker())
+	wm := NewWatchManager(fakeMultiWatcher.newSub, timerMaker.maker(), WatchSettingsFlag{})
 
 	ctx, cancel := context.WithCancel(output.CtxForTest())
 	go func() {
@@ -227,9 +439,9 @@ func (f *wmFixture) Stop(t *testing.T) []targetFilesChangedAction {
 
 func (f *wmFixture) SetManifestTarget(target model.DockerComposeTarget) {
 	m := model.Manifest{Name: "foo"}.WithDeployTarget(target)
-	mt := store.ManifestTarget{Manifest: m}
+	mt := store.NewManifestTarget(m)
 	state := f.store.LockMutableStateForTesting()
-	state.UpsertManifestTarget(&mt)
+	state.UpsertManifestTarget(mt)
 	state.WatchFiles = true
 	f.store.UnlockMutableState()
 	f.wm.OnChange(f.ctx, f.store)
@@ -242,6 +454,13 @@ func (f *wmFixture) SetTiltIgnoreContents(s string) {
 	f.wm.OnChange(f.ctx, f.store)
 }
 
+func (f *wmFixture) SetWatchSettings(settings model.WatchSettings) {
+	state := f.store.LockMutableStateForTesting()
+	state.WatchSettings = settings
+	f.store.UnlockMutableState()
+	f.wm.OnChange(f.ctx, f.store)
+}
+
 func targetFilesChangedActionsToPaths(actions []targetFilesChangedAction) []string {
 	var paths []string
 	for _, a := range actions {
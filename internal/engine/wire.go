@@ -38,6 +38,7 @@ var DeployerBaseWireSet = wire.NewSet(
 	NewSyncletBuildAndDeployer,
 	NewLocalContainerBuildAndDeployer,
 	NewDockerComposeBuildAndDeployer,
+	NewLocalTargetBuildAndDeployer,
 	NewImageAndCacheBuilder,
 	DefaultBuildOrder,
 
@@ -48,7 +49,10 @@ var DeployerBaseWireSet = wire.NewSet(
 
 var DeployerWireSetTest = wire.NewSet(
 	DeployerBaseWireSet,
+	wire.Value(build.DefaultRegistryRetries),
+	wire.Value(build.ReuseImages(false)),
 	NewSyncletManagerForTests,
+	k8s.NewClientRegistryForTests,
 )
 
 var DeployerWireSet = wire.NewSet(
@@ -112,6 +116,7 @@ func provideDockerComposeBuildAndDeployer(
 		k8s.ProvideConfigNamespace,
 		k8s.ProvideKubeContext,
 		k8s.ProvideKubectlRunner,
+		k8s.ProvideHelmRunner,
 		k8s.ProvideK8sClient,
 		k8s.ProvidePortForwarder,
 		k8s.ProvideContainerRuntime,
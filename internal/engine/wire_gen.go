@@ -28,12 +28,15 @@ func provideBuildAndDeployer(ctx context.Context, docker2 docker.Client, kClient
 	if err != nil {
 		return nil, err
 	}
-	syncletBuildAndDeployer := NewSyncletBuildAndDeployer(syncletManager, kClient, engineUpdateMode)
+	clientRegistry := k8s.NewClientRegistryForTests(kClient)
+	syncletBuildAndDeployer := NewSyncletBuildAndDeployer(syncletManager, clientRegistry, engineUpdateMode)
 	containerUpdater := build.NewContainerUpdater(docker2)
 	memoryAnalytics := analytics.NewMemoryAnalytics()
-	localContainerBuildAndDeployer := NewLocalContainerBuildAndDeployer(containerUpdater, memoryAnalytics, env)
+	localContainerBuildAndDeployer := NewLocalContainerBuildAndDeployer(containerUpdater, memoryAnalytics, env, clientRegistry)
 	labels := _wireLabelsValue
-	dockerImageBuilder := build.NewDockerImageBuilder(docker2, labels)
+	registryRetries := _wireRegistryRetriesValue
+	reuseImages := _wireReuseImagesValue
+	dockerImageBuilder := build.NewDockerImageBuilder(docker2, labels, registryRetries, reuseImages)
 	imageBuilder := build.DefaultImageBuilder(dockerImageBuilder)
 	cacheBuilder := build.NewCacheBuilder(docker2)
 	client := minikube.ProvideMinikubeClient()
@@ -42,21 +45,26 @@ func provideBuildAndDeployer(ctx context.Context, docker2 docker.Client, kClient
 		return nil, err
 	}
 	execCustomBuilder := build.NewExecCustomBuilder(docker2, dockerEnv, clock)
-	imageBuildAndDeployer := NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, kClient, env, memoryAnalytics, engineUpdateMode, clock, runtime, kp)
+	imageBuildAndDeployer := NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, clientRegistry, env, memoryAnalytics, engineUpdateMode, clock, runtime, kp, docker2, dockerEnv, reuseImages)
 	engineImageAndCacheBuilder := NewImageAndCacheBuilder(imageBuilder, cacheBuilder, execCustomBuilder, engineUpdateMode)
 	dockerComposeBuildAndDeployer := NewDockerComposeBuildAndDeployer(dcc, docker2, engineImageAndCacheBuilder, clock)
-	buildOrder := DefaultBuildOrder(syncletBuildAndDeployer, localContainerBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, env, engineUpdateMode, runtime)
+	localTargetBuildAndDeployer := NewLocalTargetBuildAndDeployer(clock)
+	buildOrder := DefaultBuildOrder(syncletBuildAndDeployer, localContainerBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, localTargetBuildAndDeployer, env, engineUpdateMode, runtime)
 	compositeBuildAndDeployer := NewCompositeBuildAndDeployer(buildOrder)
 	return compositeBuildAndDeployer, nil
 }
 
 var (
-	_wireLabelsValue = dockerfile.Labels{}
+	_wireLabelsValue          = dockerfile.Labels{}
+	_wireRegistryRetriesValue = build.DefaultRegistryRetries
+	_wireReuseImagesValue     = build.ReuseImages(false)
 )
 
 func provideImageBuildAndDeployer(ctx context.Context, docker2 docker.Client, kClient k8s.Client, env k8s.Env, dir *dirs.WindmillDir, clock build.Clock, kp KINDPusher) (*ImageBuildAndDeployer, error) {
 	labels := _wireLabelsValue
-	dockerImageBuilder := build.NewDockerImageBuilder(docker2, labels)
+	registryRetries := _wireRegistryRetriesValue
+	reuseImages := _wireReuseImagesValue
+	dockerImageBuilder := build.NewDockerImageBuilder(docker2, labels, registryRetries, reuseImages)
 	imageBuilder := build.DefaultImageBuilder(dockerImageBuilder)
 	cacheBuilder := build.NewCacheBuilder(docker2)
 	runtime := k8s.ProvideContainerRuntime(ctx, kClient)
@@ -72,7 +80,8 @@ func provideImageBuildAndDeployer(ctx context.Context, docker2 docker.Client, kC
 	if err != nil {
 		return nil, err
 	}
-	imageBuildAndDeployer := NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, kClient, env, memoryAnalytics, updateMode, clock, runtime, kp)
+	clientRegistry := k8s.NewClientRegistryForTests(kClient)
+	imageBuildAndDeployer := NewImageBuildAndDeployer(imageBuilder, cacheBuilder, execCustomBuilder, clientRegistry, env, memoryAnalytics, updateMode, clock, runtime, kp, docker2, dockerEnv, reuseImages)
 	return imageBuildAndDeployer, nil
 }
 
@@ -82,7 +91,9 @@ var (
 
 func provideDockerComposeBuildAndDeployer(ctx context.Context, dcCli dockercompose.DockerComposeClient, dCli docker.Client, dir *dirs.WindmillDir) (*DockerComposeBuildAndDeployer, error) {
 	labels := _wireLabelsValue
-	dockerImageBuilder := build.NewDockerImageBuilder(dCli, labels)
+	registryRetries := _wireRegistryRetriesValue
+	reuseImages := _wireReuseImagesValue
+	dockerImageBuilder := build.NewDockerImageBuilder(dCli, labels, registryRetries, reuseImages)
 	imageBuilder := build.DefaultImageBuilder(dockerImageBuilder)
 	cacheBuilder := build.NewCacheBuilder(dCli)
 	env := _wireEnvValue
@@ -98,7 +109,8 @@ func provideDockerComposeBuildAndDeployer(ctx context.Context, dcCli dockercompo
 		return nil, err
 	}
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, clientConfig)
+	helmRunner := k8s.ProvideHelmRunner(kubeContext)
+	client := k8s.ProvideK8sClient(ctx, env, portForwarder, namespace, kubectlRunner, helmRunner, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	minikubeClient := minikube.ProvideMinikubeClient()
 	dockerEnv, err := docker.ProvideEnv(ctx, env, runtime, minikubeClient)
@@ -127,13 +139,14 @@ var (
 var DeployerBaseWireSet = wire.NewSet(wire.Value(dockerfile.Labels{}), wire.Value(UpperReducer), minikube.ProvideMinikubeClient, docker.ProvideEnv, build.DefaultImageBuilder, build.NewCacheBuilder, build.NewDockerImageBuilder, build.NewExecCustomBuilder, wire.Bind(new(build.CustomBuilder), new(build.ExecCustomBuilder)), NewImageBuildAndDeployer, build.NewContainerUpdater, NewSyncletBuildAndDeployer,
 	NewLocalContainerBuildAndDeployer,
 	NewDockerComposeBuildAndDeployer,
+	NewLocalTargetBuildAndDeployer,
 	NewImageAndCacheBuilder,
 	DefaultBuildOrder, wire.Bind(new(BuildAndDeployer), new(CompositeBuildAndDeployer)), NewCompositeBuildAndDeployer,
 	ProvideUpdateMode,
 )
 
 var DeployerWireSetTest = wire.NewSet(
-	DeployerBaseWireSet,
+	DeployerBaseWireSet, wire.Value(build.DefaultRegistryRetries), wire.Value(build.ReuseImages(false)),
 	NewSyncletManagerForTests,
 )
 
@@ -0,0 +1,50 @@
+// Package events sends structured build and deploy events to places outside
+// Tilt (e.g., a Slack webhook, a CI dashboard, or a script), so that users
+// don't have to scrape the HUD to find out what Tilt is doing.
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+type Type string
+
+const (
+	TypeBuildStarted    Type = "build_started"
+	TypeBuildSucceeded  Type = "build_succeeded"
+	TypeBuildFailed     Type = "build_failed"
+	TypeResourceReady   Type = "resource_ready"
+	TypeResourceUnready Type = "resource_unready"
+)
+
+type Event struct {
+	Type     Type               `json:"type"`
+	Resource model.ManifestName `json:"resource"`
+	Time     time.Time          `json:"time"`
+
+	// Set for build events.
+	Edits []string `json:"edits,omitempty"`
+
+	// Set when a build finishes.
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// A Sink receives every event, in the order that they happen.
+//
+// Send is called from a single goroutine, so a slow sink delays the events after it
+// (but never the engine).
+type Sink interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// Makes a sink from its description in the Tiltfile.
+func NewSink(spec model.EventSink) Sink {
+	if spec.URL != "" {
+		return NewWebhookSink(spec.URL)
+	}
+	return NewExecSink(spec.Cmd, spec.Workdir)
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+var testEvent = Event{
+	Type:       TypeBuildFailed,
+	Resource:   "frontend",
+	Time:       time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC),
+	Edits:      []string{"main.go"},
+	DurationMs: 1500,
+	Error:      "compile error",
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		err := json.NewDecoder(r.Body).Decode(&received)
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	err := NewSink(model.EventSink{URL: server.URL}).Send(output.CtxForTest(), testEvent)
+	assert.NoError(t, err)
+	assert.Equal(t, testEvent, received)
+}
+
+func TestWebhookSinkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewWebhookSink(server.URL).Send(output.CtxForTest(), testEvent)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "502 Bad Gateway")
+	}
+}
+
+func TestExecSink(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	sink := NewExecSink(model.ToShellCmd("echo $TILT_EVENT_TYPE $TILT_RESOURCE > env.txt && cat > event.json"), f.Path())
+	err := sink.Send(output.CtxForTest(), testEvent)
+	assert.NoError(t, err)
+
+	env, err := ioutil.ReadFile(f.JoinPath("env.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "build_failed frontend\n", string(env))
+
+	var received Event
+	contents, err := ioutil.ReadFile(f.JoinPath("event.json"))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(contents, &received))
+	assert.Equal(t, testEvent, received)
+}
+
+func TestExecSinkError(t *testing.T) {
+	err := NewExecSink(model.ToShellCmd("echo oops && exit 1"), "").Send(output.CtxForTest(), testEvent)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "oops")
+	}
+}
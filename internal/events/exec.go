@@ -0,0 +1,59 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+const execTimeout = 30 * time.Second
+
+// Runs a command for each event. The command gets the event as JSON on stdin,
+// and its type and resource in the TILT_EVENT_TYPE and TILT_RESOURCE environment
+// variables, for scripts that don't want to parse JSON.
+type ExecSink struct {
+	cmd     model.Cmd
+	workdir string
+}
+
+func NewExecSink(cmd model.Cmd, workdir string) *ExecSink {
+	return &ExecSink{cmd: cmd, workdir: workdir}
+}
+
+func (s *ExecSink) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "Send")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, s.cmd.Argv[0], s.cmd.Argv[1:]...)
+	c.Dir = s.workdir
+	c.Stdin = bytes.NewReader(body)
+	c.Env = append(os.Environ(),
+		fmt.Sprintf("TILT_EVENT_TYPE=%s", e.Type),
+		fmt.Sprintf("TILT_RESOURCE=%s", e.Resource))
+
+	out, err := c.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg != "" {
+			return fmt.Errorf("%s: %v\n%s", s.cmd, err, msg)
+		}
+		return fmt.Errorf("%s: %v", s.cmd, err)
+	}
+	return nil
+}
+
+var _ Sink = &ExecSink{}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+type FakeSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewFakeSink() *FakeSink {
+	return &FakeSink{}
+}
+
+func (s *FakeSink) Send(ctx context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *FakeSink) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event{}, s.events...)
+}
+
+var _ Sink = &FakeSink{}
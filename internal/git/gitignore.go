@@ -4,6 +4,8 @@ import (
 	"context"
 	"path/filepath"
 	"strings"
+
+	"github.com/windmilleng/tilt/internal/ospath"
 )
 
 // ignores files specified in $ROOT/.git/
@@ -25,6 +27,15 @@ func (r repoIgnoreTester) Matches(f string, isDir bool) (bool, error) {
 	return false, nil
 }
 
+// Everything under .git is ignored.
+func (r repoIgnoreTester) MatchesEntireDir(f string) (bool, error) {
+	absPath, err := filepath.Abs(f)
+	if err != nil {
+		return false, err
+	}
+	return ospath.IsChild(filepath.Join(r.repoRoot, ".git"), absPath), nil
+}
+
 func NewRepoIgnoreTester(ctx context.Context, repoRoot string) (*repoIgnoreTester, error) {
 	return &repoIgnoreTester{repoRoot}, nil
 }
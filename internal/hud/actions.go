@@ -30,3 +30,10 @@ type DumpEngineStateAction struct {
 }
 
 func (DumpEngineStateAction) Action() {}
+
+// In `tilt replay` of an action recording, steps backwards or forwards through the actions.
+type StepReplayAction struct {
+	Delta int
+}
+
+func (StepReplayAction) Action() {}
@@ -0,0 +1,145 @@
+package hud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/rty"
+)
+
+const alertPaneScrollerName = "alerts"
+
+// Something that's wrong (or about to go wrong), for the alert pane.
+type alert struct {
+	// Empty for alerts about the whole session.
+	resource model.ManifestName
+
+	isError bool
+	message string
+}
+
+// Every error and warning, across all resources, in resource order.
+func alerts(v view.View) []alert {
+	var result []alert
+	if v.KubeContextAlert != "" {
+		result = append(result, alert{isError: true, message: v.KubeContextAlert})
+	}
+	for _, res := range v.Resources {
+		result = append(result, resourceAlerts(v, res)...)
+	}
+	return result
+}
+
+func resourceAlerts(v view.View, res view.Resource) []alert {
+	var result []alert
+	add := func(isError bool, message string) {
+		result = append(result, alert{resource: res.Name, isError: isError, message: firstLine(message)})
+	}
+
+	if err := res.LastBuild().Error; err != nil && !res.LastBuild().Canceled {
+		if res.IsTiltfile {
+			add(true, "Tiltfile error: "+err.Error())
+		} else {
+			add(true, "Build failed: "+err.Error())
+		}
+	}
+
+	if res.IsK8S() {
+		for _, a := range res.K8SInfo().PodAlerts {
+			add(true, a)
+		}
+		if restarts := res.K8SInfo().PodRestarts; restarts > 0 {
+			add(true, fmt.Sprintf("Pod restarted %s", pluralize(restarts, "time")))
+		}
+	}
+	if res.IsDC() && res.DockerComposeTarget().Status() == string(dockercompose.StatusCrash) {
+		add(true, "Container crashed")
+	}
+
+	// Anything that shows up red in the resource list should show up here,
+	// even if we don't know anything more about it.
+	if len(result) == 0 && isInError(res, triggerMode(v, res)) && res.ResourceInfo != nil {
+		add(true, "Status: "+res.ResourceInfo.Status())
+	}
+
+	if res.LastBuild().Reason.Has(model.BuildReasonFlagCrash) ||
+		res.CurrentBuild.Reason.Has(model.BuildReasonFlagCrash) {
+		add(false, "Container crashed after a live update, so Tilt rebuilt it")
+	}
+	if reason := res.LastBuild().FallbackReason; reason != "" {
+		add(false, "Couldn't live update, so Tilt built an image instead: "+reason)
+	}
+	for _, w := range warnings(res) {
+		add(false, w)
+	}
+	return result
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return strings.TrimSpace(s[:i]) + " …"
+	}
+	return s
+}
+
+// The title of the alert pane, e.g., "2 errors, 1 warning".
+func alertCounts(as []alert) string {
+	errors := 0
+	for _, a := range as {
+		if a.isError {
+			errors++
+		}
+	}
+	return fmt.Sprintf("%s, %s", pluralize(errors, "error"), pluralize(len(as)-errors, "warning"))
+}
+
+func (r *Renderer) maybeAddAlertPane(v view.View, vs view.ViewState, layout rty.Component) rty.Component {
+	if !vs.ShowAlertPane {
+		return layout
+	}
+
+	as := alerts(v)
+	names := make([]string, len(as))
+	for i, a := range as {
+		names[i] = fmt.Sprintf("%d:%s", i, a.resource)
+	}
+	l, selectedName := r.rty.RegisterElementScroll(alertPaneScrollerName, names)
+
+	if len(as) == 0 {
+		l.Add(rty.NewStringBuilder().Text("  ").Fg(r.theme.Good).Text("✓").Fg(tcell.ColorDefault).Text(" No errors or warnings").Build())
+	}
+
+	nameWidth := 0
+	for _, a := range as {
+		if len(a.resource) > nameWidth {
+			nameWidth = len(a.resource)
+		}
+	}
+
+	for i, a := range as {
+		sb := rty.NewStringBuilder()
+		if names[i] == selectedName {
+			sb.Text("▶ ")
+		} else {
+			sb.Text("  ")
+		}
+		if a.isError {
+			sb.Fg(r.theme.Bad).Text("✖").Fg(tcell.ColorDefault)
+		} else {
+			sb.Fg(r.theme.Pending).Text("!").Fg(tcell.ColorDefault)
+		}
+		sb.Fg(r.theme.LightText).Textf(" %-*s ", nameWidth, a.resource).Fg(tcell.ColorDefault)
+		sb.Text(a.message)
+		l.Add(sb.BuildTruncated(0))
+	}
+
+	w := rty.NewWindow(l)
+	w.SetTitle(fmt.Sprintf("Alerts: %s", alertCounts(as)))
+	return rty.NewModalLayout(layout, w, .9, true)
+}
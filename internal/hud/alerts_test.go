@@ -0,0 +1,79 @@
+package hud
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func alertsTestView() view.View {
+	return view.View{
+		KubeContextAlert: "Deploys paused: kube context changed",
+		Resources: []view.Resource{
+			{
+				Name:         view.TiltfileResourceName,
+				IsTiltfile:   true,
+				BuildHistory: []model.BuildRecord{{Warnings: []string{"docker_build('frontend') is unused"}}},
+			},
+			{
+				Name: "frontend",
+				ResourceInfo: view.K8SResourceInfo{
+					PodStatus:   "CrashLoopBackOff",
+					PodRestarts: 3,
+					PodAlerts:   []string{"Back-off restarting failed container"},
+				},
+			},
+			{
+				Name:         "backend",
+				ResourceInfo: view.K8SResourceInfo{},
+				BuildHistory: []model.BuildRecord{{Error: errors.New("compile error\nmain.go:12: undefined: foo")}},
+			},
+			{Name: "db", ResourceInfo: view.K8SResourceInfo{PodStatus: "Running"}},
+		},
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	as := alerts(alertsTestView())
+	assert.Equal(t, []alert{
+		{isError: true, message: "Deploys paused: kube context changed"},
+		{resource: view.TiltfileResourceName, message: "docker_build('frontend') is unused"},
+		{resource: "frontend", isError: true, message: "Back-off restarting failed container"},
+		{resource: "frontend", isError: true, message: "Pod restarted 3 times"},
+		{resource: "backend", isError: true, message: "Build failed: compile error …"},
+	}, as)
+	assert.Equal(t, "4 errors, 1 warning", alertCounts(as))
+}
+
+func TestAlertsLiveUpdateFallback(t *testing.T) {
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:         "web",
+				ResourceInfo: view.K8SResourceInfo{PodStatus: "Running"},
+				BuildHistory: []model.BuildRecord{{
+					UpdateType:     model.UpdateTypeImageBuild,
+					FallbackReason: "don't have info for deployed container",
+				}},
+			},
+		},
+	}
+	assert.Equal(t, []alert{
+		{resource: "web", message: "Couldn't live update, so Tilt built an image instead: don't have info for deployed container"},
+	}, alerts(v))
+}
+
+func TestAlertsFallBackToStatus(t *testing.T) {
+	v := view.View{
+		Resources: []view.Resource{
+			{Name: "web", ResourceInfo: view.K8SResourceInfo{PodStatus: "ErrImagePull"}},
+		},
+	}
+	assert.Equal(t, []alert{
+		{resource: "web", isError: true, message: "Status: ErrImagePull"},
+	}, alerts(v))
+}
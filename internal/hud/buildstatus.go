@@ -1,6 +1,7 @@
 package hud
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gdamore/tcell"
@@ -15,12 +16,13 @@ type buildStatus struct {
 	status     string
 	deployTime time.Time
 	reason     model.BuildReason
+	updateType model.UpdateType
 	muted      bool
 }
 
-func (bs buildStatus) defaultTextColor() tcell.Color {
+func (bs buildStatus) defaultTextColor(theme Theme) tcell.Color {
 	if bs.muted {
-		return cLightText
+		return theme.LightText
 	}
 	return tcell.ColorDefault
 }
@@ -31,6 +33,8 @@ func makeBuildStatus(res view.Resource, triggerMode model.TriggerMode) buildStat
 	edits := []string{}
 	deployTime := time.Time{}
 	reason := model.BuildReason(0)
+	updateType := model.UpdateTypeNone
+	muted := false
 
 	if res.IsTiltfile {
 		return buildStatus{
@@ -38,6 +42,22 @@ func makeBuildStatus(res view.Resource, triggerMode model.TriggerMode) buildStat
 		}
 	}
 
+	if res.Disabled {
+		return buildStatus{
+			status: "Disabled",
+			muted:  true,
+		}
+	}
+
+	if res.UpdatesPaused && res.CurrentBuild.Empty() {
+		// Show the changes that are waiting for the user to resume.
+		return buildStatus{
+			status: "Paused",
+			edits:  res.PendingBuildEdits,
+			muted:  true,
+		}
+	}
+
 	if !res.CurrentBuild.Empty() && !res.CurrentBuild.Reason.IsCrashOnly() {
 		status = "In prog."
 		duration = time.Since(res.CurrentBuild.StartTime)
@@ -50,10 +70,18 @@ func makeBuildStatus(res view.Resource, triggerMode model.TriggerMode) buildStat
 		}
 		edits = res.PendingBuildEdits
 		reason = res.PendingBuildReason
+	} else if !res.CrashBackoffUntil.IsZero() {
+		status = fmt.Sprintf("crash backoff: retrying in %s", crashBackoffRemaining(res.CrashBackoffUntil))
+		muted = true
 	} else if !res.LastBuild().FinishTime.IsZero() {
 		lastBuild := res.LastBuild()
-		if lastBuild.Error != nil {
+		if lastBuild.Canceled {
+			status = "Canceled"
+			muted = true
+		} else if lastBuild.Error != nil {
 			status = "Error"
+		} else if res.WaitingOnReadinessCheck {
+			status = "Not ready"
 		} else {
 			status = "OK"
 		}
@@ -61,6 +89,7 @@ func makeBuildStatus(res view.Resource, triggerMode model.TriggerMode) buildStat
 		edits = lastBuild.Edits
 		deployTime = res.LastDeployTime
 		reason = lastBuild.Reason
+		updateType = lastBuild.UpdateType
 	}
 
 	return buildStatus{
@@ -69,11 +98,35 @@ func makeBuildStatus(res view.Resource, triggerMode model.TriggerMode) buildStat
 		edits:      edits,
 		deployTime: deployTime,
 		reason:     reason,
+		updateType: updateType,
+		muted:      muted,
+	}
+}
+
+// How a finished build updated the resource, e.g., "live update".
+func updateTypeText(b model.BuildRecord) string {
+	switch b.UpdateType {
+	case model.UpdateTypeLiveUpdate:
+		return "live update"
+	case model.UpdateTypeImageBuild:
+		if b.Reason.Has(model.BuildReasonFlagCrash) {
+			return "rebuilt after crash"
+		}
+		return "image build"
+	}
+	return ""
+}
+
+func crashBackoffRemaining(until time.Time) time.Duration {
+	remaining := time.Until(until).Round(time.Second)
+	if remaining < time.Second {
+		return time.Second
 	}
+	return remaining
 }
 
-func buildStatusCell(bs buildStatus) rty.Component {
-	textColor := bs.defaultTextColor()
+func buildStatusCell(theme Theme, bs buildStatus) rty.Component {
+	textColor := bs.defaultTextColor(theme)
 	showingDuration := bs.duration != 0
 	lhsWidth := BuildStatusCellMinWidth
 	if !showingDuration {
@@ -86,9 +139,9 @@ func buildStatusCell(bs buildStatus) rty.Component {
 	}
 
 	sb := rty.NewStringBuilder()
-	sb.Fg(cLightText).Text(" (")
+	sb.Fg(theme.LightText).Text(" (")
 	sb.Fg(textColor).Text(formatBuildDuration(bs.duration))
-	sb.Fg(cLightText).Text(")")
+	sb.Fg(theme.LightText).Text(")")
 	rhs := rty.NewMinLengthLayout(BuildDurCellMinWidth, rty.DirHor).
 		SetAlign(rty.AlignEnd).
 		Add(sb.Build())
@@ -20,6 +20,10 @@ func (h *Hud) activeScroller() scroller {
 func (h *Hud) activeModal() modal {
 	if h.currentViewState.AlertMessage != "" {
 		return makeAlertModal(h.r.rty)
+	} else if h.currentViewState.ShowAlertPane {
+		return alertPaneModal{h.r.rty.ElementScroller(alertPaneScrollerName)}
+	} else if h.currentViewState.ResourceDetail != "" {
+		return resourceDetailModal{h.r.rty.TextScroller(resourceDetailScrollerName)}
 	} else {
 		return nil
 	}
@@ -53,3 +57,23 @@ func makeAlertModal(r rty.RTY) modal {
 func (am alertModal) Close(vs *view.ViewState) {
 	vs.AlertMessage = ""
 }
+
+type alertPaneModal struct {
+	rty.ElementScroller
+}
+
+var _ modal = alertPaneModal{}
+
+func (am alertPaneModal) Close(vs *view.ViewState) {
+	vs.ShowAlertPane = false
+}
+
+type resourceDetailModal struct {
+	rty.TextScroller
+}
+
+var _ modal = resourceDetailModal{}
+
+func (dm resourceDetailModal) Close(vs *view.ViewState) {
+	vs.ResourceDetail = ""
+}
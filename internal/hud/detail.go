@@ -0,0 +1,246 @@
+package hud
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/rty"
+)
+
+const resourceDetailScrollerName = "detail"
+
+// How many edited files to name in a build's reason, before "(+N more)".
+const detailEditsShown = 3
+
+// Everything we know about one resource, in a scrollable window over the HUD:
+// its status, endpoints, and pod, its recent builds, and recent k8s events.
+func (r *Renderer) maybeAddResourceDetail(v view.View, vs view.ViewState, layout rty.Component) rty.Component {
+	if vs.ResourceDetail == "" {
+		return layout
+	}
+
+	l := rty.NewTextScrollLayout(resourceDetailScrollerName)
+	res, ok := findResource(v, vs.ResourceDetail)
+	if !ok {
+		l.Add(rty.TextString(fmt.Sprintf("  %s isn't in the Tiltfile anymore", vs.ResourceDetail)))
+	} else {
+		d := resourceDetail{res: res, triggerMode: triggerMode(v, res), allEdits: vs.ResourceDetailAllEdits, now: r.clock(), theme: r.theme}
+		d.build(l)
+	}
+
+	w := rty.NewWindow(l)
+	w.SetTitle(fmt.Sprintf("Details: %s", vs.ResourceDetail))
+	return rty.NewModalLayout(layout, w, .9, true)
+}
+
+func findResource(v view.View, name model.ManifestName) (view.Resource, bool) {
+	for _, res := range v.Resources {
+		if res.Name == name {
+			return res, true
+		}
+	}
+	return view.Resource{}, false
+}
+
+type resourceDetail struct {
+	res         view.Resource
+	triggerMode model.TriggerMode
+	allEdits    bool
+	now         time.Time
+	theme       Theme
+}
+
+func (d resourceDetail) build(l *rty.TextScrollLayout) {
+	d.addField(l, "Status", d.statusText())
+	if len(d.res.Endpoints) > 0 {
+		sb := rty.NewStringBuilder()
+		for i, endpoint := range d.res.Endpoints {
+			if i != 0 {
+				sb.Text(" · ")
+			}
+			sb.Link(endpoint).Text(endpoint).Link("")
+		}
+		d.addField(l, "Endpoints", sb.Build())
+	}
+	if len(d.res.Labels) > 0 {
+		d.addField(l, "Labels", rty.TextString(strings.Join(d.res.Labels, ", ")))
+	}
+
+	switch info := d.res.ResourceInfo.(type) {
+	case view.K8SResourceInfo:
+		if info.PodName != "" {
+			pod := info.PodName
+			if info.PodNamespace != "" {
+				pod += " in " + info.PodNamespace
+			}
+			if !info.PodCreationTime.IsZero() {
+				pod += fmt.Sprintf(" · started %s ago", formatDeployAge(d.now.Sub(info.PodCreationTime)))
+			}
+			if info.PodRestarts > 0 {
+				pod += " · restarted " + pluralize(info.PodRestarts, "time")
+			}
+			d.addField(l, "Pod", rty.TextString(pod))
+		}
+		if info.PodCPU != "" || info.PodMemory != "" {
+			d.addField(l, "Usage", rty.TextString(fmt.Sprintf("CPU %s · MEM %s", info.PodCPU, info.PodMemory)))
+		}
+	case view.DCResourceInfo:
+		if info.ContainerID != "" {
+			d.addField(l, "Container", rty.TextString(info.ContainerID.ShortStr()))
+		}
+	case view.LocalResourceInfo:
+		if info.PID != 0 {
+			d.addField(l, "PID", rty.TextString(fmt.Sprintf("%d", info.PID)))
+		}
+	}
+
+	l.Add(rty.TextString(" "))
+	d.addHeading(l, "Builds")
+	builds := d.res.BuildHistory
+	if !d.res.CurrentBuild.Empty() {
+		builds = append([]model.BuildRecord{d.res.CurrentBuild}, builds...)
+	}
+	empty := true
+	for _, b := range builds {
+		if b.Empty() {
+			continue
+		}
+		empty = false
+		l.Add(d.buildText(b))
+		if b.FallbackReason != "" {
+			l.Add(rty.NewStringBuilder().Fg(d.theme.Pending).Textf("      ↳ no live update: %s", firstLine(b.FallbackReason)).Build())
+		}
+		if d.allEdits {
+			for _, e := range b.Edits {
+				l.Add(rty.NewStringBuilder().Fg(d.theme.LightText).Textf("      %s", e).Build())
+			}
+		}
+	}
+	if empty {
+		l.Add(rty.Fg(rty.TextString("  No builds yet"), d.theme.LightText))
+	}
+
+	if info, ok := d.res.ResourceInfo.(view.K8SResourceInfo); ok {
+		l.Add(rty.TextString(" "))
+		d.addHeading(l, "Kubernetes events")
+		for _, e := range info.Events {
+			l.Add(d.eventText(e))
+		}
+		if len(info.Events) == 0 {
+			l.Add(rty.Fg(rty.TextString("  No events yet"), d.theme.LightText))
+		}
+	}
+}
+
+func (d resourceDetail) addField(l *rty.TextScrollLayout, name string, value rty.Component) {
+	row := rty.NewConcatLayout(rty.DirHor)
+	row.Add(rty.NewStringBuilder().Fg(d.theme.LightText).Textf("  %-10s ", name).Build())
+	row.AddDynamic(value)
+	l.Add(row)
+}
+
+func (d resourceDetail) addHeading(l *rty.TextScrollLayout, heading string) {
+	l.Add(rty.NewStringBuilder().Text("  ").Fg(d.theme.LightText).Text(strings.ToUpper(heading)).Build())
+}
+
+func (d resourceDetail) statusText() rty.Component {
+	sb := rty.NewStringBuilder()
+	sb.Fg(d.theme.statusColor(statusOf(d.res, d.triggerMode))).Text("●").Fg(tcell.ColorDefault)
+	status := "Unknown"
+	if d.res.ResourceInfo != nil && d.res.ResourceInfo.Status() != "" {
+		status = d.res.ResourceInfo.Status()
+	}
+	sb.Textf(" %s", status)
+	if info, ok := d.res.ResourceInfo.(view.K8SResourceInfo); ok && info.PodName != "" && !info.PodReady {
+		sb.Fg(d.theme.Pending).Text(" (not ready)")
+	}
+	return sb.Build()
+}
+
+// e.g., "✔ 5m ago  1.2s  2 files changed: main.go util.go · live update"
+func (d resourceDetail) buildText(b model.BuildRecord) rty.Component {
+	sb := rty.NewStringBuilder().Text("  ")
+	when := formatDeployAge(d.now.Sub(b.StartTime)) + " ago"
+	duration := formatBuildDuration(b.FinishTime.Sub(b.StartTime))
+	switch {
+	case b.FinishTime.IsZero():
+		sb.Fg(d.theme.Pending).Text("…").Fg(tcell.ColorDefault)
+		when = "now"
+		duration = formatBuildDuration(d.now.Sub(b.StartTime))
+	case b.Canceled:
+		sb.Fg(d.theme.LightText).Text("–").Fg(tcell.ColorDefault)
+	case b.Error != nil:
+		sb.Fg(d.theme.Bad).Text("✖").Fg(tcell.ColorDefault)
+	default:
+		sb.Fg(d.theme.Good).Text("✔").Fg(tcell.ColorDefault)
+	}
+	sb.Fg(d.theme.LightText).Textf(" %-8s %6s ", when, duration).Fg(tcell.ColorDefault)
+	sb.Text(buildReasonText(b.Reason, b.Edits))
+	if t := updateTypeText(b); t != "" {
+		sb.Fg(d.theme.LightText).Textf(" · %s", t).Fg(tcell.ColorDefault)
+	}
+	if b.Canceled {
+		sb.Fg(d.theme.LightText).Textf(" · %s", firstLine(b.Error.Error())).Fg(tcell.ColorDefault)
+	} else if b.Error != nil {
+		sb.Fg(d.theme.Bad).Textf(" · %s", firstLine(b.Error.Error())).Fg(tcell.ColorDefault)
+	}
+	return sb.Build()
+}
+
+// Why a build happened, e.g., "first build" or "2 files changed: main.go util.go".
+func buildReasonText(reason model.BuildReason, edits []string) string {
+	var reasons []string
+	if reason.Has(model.BuildReasonFlagInit) {
+		reasons = append(reasons, "first build")
+	}
+	if reason.Has(model.BuildReasonFlagCrash) {
+		reasons = append(reasons, "container crashed")
+	}
+	if reason.Has(model.BuildReasonFlagConfig) {
+		reasons = append(reasons, "config changed")
+	}
+	if len(edits) > 0 {
+		names := make([]string, 0, detailEditsShown)
+		for i, e := range edits {
+			if i == detailEditsShown {
+				break
+			}
+			names = append(names, path.Base(e))
+		}
+		s := fmt.Sprintf("%s changed: %s", pluralize(len(edits), "file"), strings.Join(names, " "))
+		if len(edits) > detailEditsShown {
+			s += fmt.Sprintf(" (+%d more)", len(edits)-detailEditsShown)
+		}
+		reasons = append(reasons, s)
+	}
+	if len(reasons) == 0 {
+		return "triggered"
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// e.g., "2m ago  BackOff  pod/frontend-1234: Back-off restarting failed container (x5)"
+func (d resourceDetail) eventText(e view.K8sEvent) rty.Component {
+	sb := rty.NewStringBuilder().Text("  ")
+	when := "?"
+	if !e.Time.IsZero() {
+		when = formatDeployAge(d.now.Sub(e.Time)) + " ago"
+	}
+	sb.Fg(d.theme.LightText).Textf("%-8s ", when).Fg(tcell.ColorDefault)
+	if e.Type == "Warning" {
+		sb.Fg(d.theme.Pending).Text(e.Reason).Fg(tcell.ColorDefault)
+	} else {
+		sb.Text(e.Reason)
+	}
+	sb.Textf("  %s: %s", e.Object, firstLine(e.Message))
+	if e.Count > 1 {
+		sb.Fg(d.theme.LightText).Textf(" (x%d)", e.Count).Fg(tcell.ColorDefault)
+	}
+	return sb.Build()
+}
@@ -0,0 +1,57 @@
+package hud
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/rty"
+)
+
+func TestBuildReasonText(t *testing.T) {
+	assert.Equal(t, "triggered", buildReasonText(model.BuildReasonNone, nil))
+	assert.Equal(t, "first build", buildReasonText(model.BuildReasonFlagInit, nil))
+	assert.Equal(t, "container crashed, config changed",
+		buildReasonText(model.BuildReasonFlagCrash.With(model.BuildReasonFlagConfig), nil))
+	assert.Equal(t, "1 file changed: main.go",
+		buildReasonText(model.BuildReasonFlagChangedFiles, []string{"src/main.go"}))
+	assert.Equal(t, "4 files changed: a.go b.go c.go (+1 more)",
+		buildReasonText(model.BuildReasonFlagChangedFiles, []string{"a.go", "b.go", "c.go", "d.go"}))
+}
+
+func TestUpdateTypeText(t *testing.T) {
+	assert.Equal(t, "", updateTypeText(model.BuildRecord{}))
+	assert.Equal(t, "live update", updateTypeText(model.BuildRecord{UpdateType: model.UpdateTypeLiveUpdate}))
+	assert.Equal(t, "image build", updateTypeText(model.BuildRecord{UpdateType: model.UpdateTypeImageBuild}))
+	assert.Equal(t, "rebuilt after crash", updateTypeText(model.BuildRecord{
+		UpdateType: model.UpdateTypeImageBuild,
+		Reason:     model.BuildReasonFlagCrash,
+	}))
+}
+
+func TestBuildTextInProgress(t *testing.T) {
+	now := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	d := resourceDetail{now: now}
+	c := d.buildText(model.BuildRecord{StartTime: now.Add(-2 * time.Second), Edits: []string{"main.go"}})
+
+	sc := tcell.NewSimulationScreen("")
+	if err := sc.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer sc.Fini()
+	sc.SetSize(60, 1)
+	if err := rty.NewRTY(sc).Render(c); err != nil {
+		t.Fatal(err)
+	}
+
+	cells, width, _ := sc.GetContents()
+	var line []rune
+	for _, cell := range cells[:width] {
+		line = append(line, cell.Runes...)
+	}
+	assert.Equal(t, "  … now        2.0s 1 file changed: main.go", strings.TrimRight(string(line), " "))
+}
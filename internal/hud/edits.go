@@ -9,14 +9,16 @@ import (
 )
 
 type EditStatusLineComponent struct {
-	bs buildStatus
+	bs    buildStatus
+	theme Theme
 }
 
 var _ rty.Component = &EditStatusLineComponent{}
 
-func NewEditStatusLine(buildStatus buildStatus) rty.Component {
+func NewEditStatusLine(buildStatus buildStatus, theme Theme) rty.Component {
 	return &EditStatusLineComponent{
-		bs: buildStatus,
+		bs:    buildStatus,
+		theme: theme,
 	}
 }
 
@@ -63,17 +65,17 @@ func (esl *EditStatusLineComponent) makeEditsComponents(width int) (rty.Componen
 }
 
 func (esl *EditStatusLineComponent) buildStatusText() rty.Component {
-	return buildStatusCell(esl.bs)
+	return buildStatusCell(esl.theme, esl.bs)
 }
 
 func (esl *EditStatusLineComponent) buildAgeText() rty.Component {
-	return deployTimeCell(esl.bs.deployTime, esl.bs.defaultTextColor())
+	return deployTimeCell(esl.bs.deployTime, esl.bs.defaultTextColor(esl.theme))
 }
 
 func (esl *EditStatusLineComponent) rightPane() rty.Component {
 	l := rty.NewConcatLayout(rty.DirHor)
 	l.Add(esl.buildStatusText())
-	l.Add(middotText())
+	l.Add(middotText(esl.theme))
 	l.Add(esl.buildAgeText())
 	return l
 }
@@ -86,12 +88,16 @@ func (esl *EditStatusLineComponent) Render(w rty.Writer, width, height int) erro
 
 	if len(bs.edits) == 0 {
 		if bs.reason.Has(model.BuildReasonFlagInit) {
-			sb.Fg(cLightText).Text("FIRST BUILD ")
+			sb.Fg(esl.theme.LightText).Text("FIRST BUILD ")
 		} else if bs.reason.Has(model.BuildReasonFlagCrash) {
-			sb.Fg(cLightText).Text("CRASH BUILD ")
+			sb.Fg(esl.theme.LightText).Text("CRASH BUILD ")
 		}
+	} else if bs.updateType == model.UpdateTypeLiveUpdate {
+		sb.Fg(esl.theme.LightText).Text("LIVE UPDATE ")
+	} else if bs.updateType == model.UpdateTypeImageBuild {
+		sb.Fg(esl.theme.LightText).Text("IMAGE BUILD ")
 	} else {
-		sb.Fg(cLightText).Text("EDITED FILES ")
+		sb.Fg(esl.theme.LightText).Text("EDITED FILES ")
 	}
 
 	lhs := sb.Build()
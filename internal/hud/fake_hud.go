@@ -2,6 +2,7 @@ package hud
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -45,6 +46,10 @@ func (h *FakeHud) SetNarrationMessage(ctx context.Context, msg string) error {
 
 func (h *FakeHud) Refresh(ctx context.Context) {}
 
+func (h *FakeHud) StreamTo(out io.Writer) {}
+
+func (h *FakeHud) EnableReplaySteps() {}
+
 func (h *FakeHud) OnChange(ctx context.Context, st store.RStore) {
 	state := st.RLockState()
 	view := store.StateToView(state)
@@ -3,6 +3,7 @@ package hud
 import (
 	"context"
 	"fmt"
+	"io"
 	"strconv"
 	"sync"
 	"time"
@@ -25,6 +26,12 @@ const DefaultRefreshInterval = 100 * time.Millisecond
 // (we don't currently worry about trying to know how big a page is, and instead just support pgup/dn as "faster arrows"
 const pgUpDownCount = 20
 
+// How many columns < and > scroll the log, when long lines are cut off.
+const logHScrollStep = 10
+
+// How many lines the log scrolls for each notch of the mouse wheel.
+const mouseWheelLines = 3
+
 type HeadsUpDisplay interface {
 	store.Subscriber
 
@@ -32,6 +39,12 @@ type HeadsUpDisplay interface {
 	Update(v view.View, vs view.ViewState) error
 	Close()
 	SetNarrationMessage(ctx context.Context, msg string) error
+
+	// Instead of running the HUD, print logs and status changes to out as they happen.
+	StreamTo(out io.Writer)
+
+	// Lets the user step through an action recording with [ and ], in `tilt replay`.
+	EnableReplaySteps()
 }
 
 type Hud struct {
@@ -43,6 +56,19 @@ type Hud struct {
 	mu               sync.RWMutex
 	isRunning        bool
 	a                analytics.Analytics
+
+	// The mouse buttons held down as of the last mouse event,
+	// so that holding a button down only counts as one click.
+	mouseButtons tcell.ButtonMask
+
+	// Set when we're streaming logs instead of running the HUD.
+	stream *streamPrinter
+
+	// What to dispatch if the user answers yes to the ConfirmPrompt.
+	confirmAction store.Action
+
+	// Whether [ and ] step through an action recording.
+	replaySteps bool
 }
 
 var _ HeadsUpDisplay = (*Hud)(nil)
@@ -55,6 +81,18 @@ func NewDefaultHeadsUpDisplay(renderer *Renderer, webURL model.WebURL, analytics
 	}, nil
 }
 
+func (h *Hud) StreamTo(out io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stream = newStreamPrinter(out)
+}
+
+func (h *Hud) EnableReplaySteps() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replaySteps = true
+}
+
 func (h *Hud) SetNarrationMessage(ctx context.Context, msg string) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -129,13 +167,71 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 		}
 	}
 
+	if ev, ok := ev.(*tcell.EventKey); ok && h.currentViewState.ConfirmPrompt != "" && ev.Key() != tcell.KeyCtrlC {
+		h.handleConfirmKey(ev, dispatch)
+		err := h.refresh(ctx)
+		if err != nil {
+			dispatch(NewExitAction(err))
+		}
+		return false
+	}
+
+	if ev, ok := ev.(*tcell.EventKey); ok && h.currentViewState.ResourceFilter.Typing && ev.Key() != tcell.KeyCtrlC {
+		h.handleResourceFilterKey(ctx, ev)
+		err := h.refresh(ctx)
+		if err != nil {
+			dispatch(NewExitAction(err))
+		}
+		return false
+	}
+
+	if ev, ok := ev.(*tcell.EventKey); ok && h.currentViewState.LogSearch.Typing && ev.Key() != tcell.KeyCtrlC {
+		h.handleLogSearchKey(ctx, ev)
+		err := h.refresh(ctx)
+		if err != nil {
+			dispatch(NewExitAction(err))
+		}
+		return false
+	}
+
 	switch ev := ev.(type) {
 	case *tcell.EventKey:
 		switch ev.Key() {
 		case tcell.KeyEscape:
+			if h.activeModal() == nil && h.currentViewState.LogSearch.Active() {
+				h.clearLogSearch()
+				break
+			}
+			if h.activeModal() == nil && h.currentViewState.ResourceFilter.Active() {
+				h.currentViewState.ResourceFilter = view.ResourceFilterState{}
+				h.resourceListChanged(ctx)
+				break
+			}
 			escape()
 		case tcell.KeyRune:
 			switch r := ev.Rune(); {
+			case r == '/': // search the logs
+				h.recordInteraction("log_search")
+				h.currentViewState.LogSearch.Typing = true
+				h.currentViewState.LogSearch.Query = ""
+				h.currentViewState.LogSearch.Match = 0
+			case r == 'n' && h.currentViewState.LogSearch.Query != "": // [N]ext search match
+				h.currentViewState.LogSearch.Match++
+				h.jumpToLogSearchMatch(ctx)
+			case r == 'N' && h.currentViewState.LogSearch.Query != "": // previous search match
+				h.currentViewState.LogSearch.Match--
+				h.jumpToLogSearchMatch(ctx)
+			case r == 'f': // [F]ilter resources by name
+				h.recordInteraction("filter_resources")
+				h.currentViewState.ResourceFilter.Typing = true
+			case r == 's': // cycle through [S]tatus filters: all, errors, building
+				h.recordInteraction("filter_resources_by_status")
+				h.currentViewState.ResourceFilter.Status = h.currentViewState.ResourceFilter.Status.Next()
+				h.resourceListChanged(ctx)
+			case r == 'g': // [G]roup resources by kind
+				h.recordInteraction("toggle_group_resources")
+				h.currentViewState.GroupResources = !h.currentViewState.GroupResources
+				h.resourceListChanged(ctx)
 			case r == 'b': // [B]rowser
 				// If we have an endpoint(s), open the first one
 				// TODO(nick): We might need some hints on what load balancer to
@@ -168,14 +264,130 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 				escape()
 			case r == 'R': // hidden key for recovering from printf junk during demos
 				h.r.screen.Sync()
+			case r == 'F': // pause the log pane, or go back to [F]ollowing the log
+				if h.r.rty == nil {
+					break
+				}
+				h.recordInteraction("toggle_log_follow")
+				log := h.r.rty.TextScroller("log")
+				if log.Following() {
+					log.SetFollow(false)
+				} else {
+					log.Bottom()
+				}
+			case r == ',' || r == '.': // page through older (,) and newer (.) builds of the selected resource
+				h.recordInteraction("page_build_log")
+				h.pageBuildLog(r == ',')
+			case r == 'w': // toggle [W]rapping long log lines
+				h.recordInteraction("toggle_log_wrap")
+				h.currentViewState.LogNoWrap = !h.currentViewState.LogNoWrap
+				h.currentViewState.LogHScroll = 0
+			case r == '<' && h.currentViewState.LogNoWrap: // scroll the log left, when not wrapping
+				h.currentViewState.LogHScroll -= logHScrollStep
+				if h.currentViewState.LogHScroll < 0 {
+					h.currentViewState.LogHScroll = 0
+				}
+			case r == '>' && h.currentViewState.LogNoWrap: // scroll the log right, when not wrapping
+				h.currentViewState.LogHScroll += logHScrollStep
+			case r == 'a': // show every [A]lert, across all resources
+				h.recordInteraction("toggle_alert_pane")
+				h.currentViewState.ShowAlertPane = !h.currentViewState.ShowAlertPane
+			case r == 'i': // show everything we know about the selected resource ([I]nfo)
+				if h.currentViewState.ResourceDetail != "" {
+					h.currentViewState.ResourceDetail = ""
+					break
+				}
+				_, selected := h.selectedResource()
+				if selected.Name == "" {
+					break
+				}
+				h.recordInteraction("resource_detail")
+				h.currentViewState.ResourceDetail = selected.Name
 			case r == 'x':
 				h.recordInteraction("cycle_view_log_state")
 				h.currentViewState.CycleViewLogState()
 			case r == ' ': // [space] - trigger build for selected resource
 				_, selected := h.selectedResource()
+				if selected.Name == "" {
+					break
+				}
 				dispatch(view.AppendToTriggerQueueAction{
 					Name: selected.Name,
 				})
+			case r == 'A': // rebuild [A]ll resources from scratch
+				h.recordInteraction("trigger_all")
+				dispatch(view.TriggerAllAction{})
+			case r == 'c': // [C]ancel the current build of the selected resource
+				_, selected := h.selectedResource()
+				if selected.Name == "" {
+					break
+				}
+				dispatch(view.CancelBuildAction{
+					Name: selected.Name,
+				})
+			case r == 'K': // [K]ill the selected resource's pods, so that they restart
+				_, selected := h.selectedResource()
+				if selected.Name == "" || selected.IsTiltfile {
+					break
+				}
+				h.recordInteraction("restart_pod")
+				dispatch(view.RestartPodAction{
+					Name: selected.Name,
+				})
+			case r == 'r': // [R]e-apply the selected resource, if someone changed it outside of Tilt
+				_, selected := h.selectedResource()
+				if selected.Name == "" || selected.IsTiltfile {
+					break
+				}
+				dispatch(view.ReapplyAction{
+					Name: selected.Name,
+				})
+			case r == 'd': // [D]elete the objects from resources that aren't in the Tiltfile anymore
+				h.confirm("Delete the objects from resources that aren't in your Tiltfile anymore?", view.DeleteOrphansAction{})
+			case r == 'e' && h.currentViewState.ResourceDetail != "": // show [E]very file that triggered each build
+				h.recordInteraction("toggle_resource_detail_edits")
+				h.currentViewState.ResourceDetailAllEdits = !h.currentViewState.ResourceDetailAllEdits
+			case r == 'e': // [E]nable or disable the selected resource
+				_, selected := h.selectedResource()
+				if selected.Name == "" || selected.IsTiltfile {
+					break
+				}
+				h.recordInteraction("toggle_enabled")
+				if selected.Disabled {
+					dispatch(view.EnableResourceAction{Name: selected.Name})
+				} else {
+					dispatch(view.DisableResourceAction{Name: selected.Name})
+				}
+			case r == 'p': // [P]ause or resume updates to the selected resource
+				_, selected := h.selectedResource()
+				if selected.Name == "" || selected.IsTiltfile {
+					break
+				}
+				h.recordInteraction("toggle_paused")
+				if selected.UpdatesPaused && !h.currentView.UpdatesPaused {
+					dispatch(view.ResumeUpdatesAction{Name: selected.Name})
+				} else {
+					dispatch(view.PauseUpdatesAction{Name: selected.Name})
+				}
+			case r == 'P': // [P]ause or resume updates to every resource
+				h.recordInteraction("toggle_paused_all")
+				if h.currentView.UpdatesPaused {
+					dispatch(view.ResumeUpdatesAction{})
+				} else {
+					dispatch(view.PauseUpdatesAction{})
+				}
+			case r == 'D': // [D]own: tear down the selected resource
+				_, selected := h.selectedResource()
+				if selected.Name == "" || selected.IsTiltfile {
+					break
+				}
+				h.confirm(fmt.Sprintf("Delete everything that %s deployed?", selected.Name), view.TearDownAction{
+					Name: selected.Name,
+				})
+			case r == '[' && h.replaySteps: // step back through an action recording in `tilt replay`
+				dispatch(StepReplayAction{Delta: -1})
+			case r == ']' && h.replaySteps: // step forward through an action recording in `tilt replay`
+				dispatch(StepReplayAction{Delta: 1})
 			case r == '1':
 				h.recordInteraction("tab_all_log")
 				h.currentViewState.TabState = view.TabAllLog
@@ -203,10 +415,25 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 			}
 			h.refreshSelectedIndex()
 		case tcell.KeyEnter:
+			if h.currentViewState.ShowAlertPane && h.currentViewState.AlertMessage == "" {
+				h.jumpToAlert(ctx)
+				break
+			}
+			if h.currentViewState.ResourceDetail != "" {
+				break
+			}
 			if len(h.currentView.Resources) == 0 {
 				break
 			}
+			// A group header has no log, so enter collapses or expands the group.
+			if row, ok := selectedRow(h.currentView, h.currentViewState); ok && row.isGroupHeader() {
+				h.toggleSelectedCollapsed(ctx)
+				break
+			}
 			_, r := h.selectedResource()
+			if r.Name == "" {
+				break
+			}
 			if r.IsYAML() {
 				h.currentViewState.AlertMessage = fmt.Sprintf("YAML Resources don't have logs")
 				break
@@ -220,11 +447,9 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 			h.a.Incr("ui.interactions.open_log", map[string]string{"is_tiltfile": strconv.FormatBool(r.Name == view.TiltfileResourceName)})
 			_ = browser.OpenURL(url.String())
 		case tcell.KeyRight:
-			i, _ := h.selectedResource()
-			h.currentViewState.Resources[i].CollapseState = view.CollapseNo
+			h.setSelectedCollapsed(ctx, false)
 		case tcell.KeyLeft:
-			i, _ := h.selectedResource()
-			h.currentViewState.Resources[i].CollapseState = view.CollapseYes
+			h.setSelectedCollapsed(ctx, true)
 		case tcell.KeyHome:
 			h.activeScroller().Top()
 		case tcell.KeyEnd:
@@ -245,6 +470,9 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 			dispatch(SetLogTimestampsAction{!h.currentView.LogTimestamps})
 		}
 
+	case *tcell.EventMouse:
+		h.handleMouse(ctx, ev)
+
 	case *tcell.EventResize:
 		// since we already refresh after the switch, don't need to do anything here
 		// just marking this as where sigwinch gets handled
@@ -258,6 +486,327 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 	return false
 }
 
+// While the user types a search query, keys go to the query.
+// Must hold the lock.
+func (h *Hud) handleLogSearchKey(ctx context.Context, ev *tcell.EventKey) {
+	search := &h.currentViewState.LogSearch
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		h.clearLogSearch()
+	case tcell.KeyEnter:
+		search.Typing = false
+		if search.Query == "" {
+			h.clearLogSearch()
+			return
+		}
+		// Start at the most recent match, since that's usually the interesting one.
+		s := newLogSearch(h.currentView, h.currentViewState)
+		search.Match = len(s.matches) - 1
+		h.jumpToLogSearchMatch(ctx)
+	case tcell.KeyTab:
+		search.AllLogs = !search.AllLogs
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(search.Query) > 0 {
+			q := []rune(search.Query)
+			search.Query = string(q[:len(q)-1])
+		}
+	case tcell.KeyRune:
+		search.Query += string(ev.Rune())
+	}
+}
+
+// Asks the user before we do something that's hard to undo.
+// Must hold the lock.
+func (h *Hud) confirm(prompt string, action store.Action) {
+	h.currentViewState.ConfirmPrompt = prompt
+	h.confirmAction = action
+}
+
+// While we're asking the user to confirm, y goes ahead, and any other key cancels.
+// Must hold the lock.
+func (h *Hud) handleConfirmKey(ev *tcell.EventKey, dispatch func(action store.Action)) {
+	if ev.Key() == tcell.KeyRune && (ev.Rune() == 'y' || ev.Rune() == 'Y') {
+		dispatch(h.confirmAction)
+	}
+	h.currentViewState.ConfirmPrompt = ""
+	h.confirmAction = nil
+}
+
+// While the user types a resource filter, keys go to the filter.
+// Must hold the lock.
+func (h *Hud) handleResourceFilterKey(ctx context.Context, ev *tcell.EventKey) {
+	filter := &h.currentViewState.ResourceFilter
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		*filter = view.ResourceFilterState{}
+	case tcell.KeyEnter:
+		filter.Typing = false
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(filter.Name) > 0 {
+			name := []rune(filter.Name)
+			filter.Name = string(name[:len(name)-1])
+		}
+	case tcell.KeyRune:
+		filter.Name += string(ev.Rune())
+	default:
+		return
+	}
+	h.resourceListChanged(ctx)
+}
+
+// Collapses or expands the selected resource, or the selected group.
+// Must hold the lock.
+func (h *Hud) setSelectedCollapsed(ctx context.Context, collapsed bool) {
+	row, ok := selectedRow(h.currentView, h.currentViewState)
+	if !ok {
+		return
+	}
+
+	if row.isGroupHeader() {
+		if h.currentViewState.CollapsedGroups == nil {
+			h.currentViewState.CollapsedGroups = make(map[string]bool)
+		}
+		h.currentViewState.CollapsedGroups[row.group] = collapsed
+		h.resourceListChanged(ctx)
+		return
+	}
+
+	var state view.CollapseState = view.CollapseNo
+	if collapsed {
+		state = view.CollapseYes
+	}
+	h.currentViewState.Resources[row.index].CollapseState = state
+}
+
+// Expands the selected resource if it's collapsed, or collapses it if it's expanded.
+// Must hold the lock.
+func (h *Hud) toggleSelectedCollapsed(ctx context.Context) {
+	row, ok := selectedRow(h.currentView, h.currentViewState)
+	if !ok {
+		return
+	}
+
+	if row.isGroupHeader() {
+		h.setSelectedCollapsed(ctx, !h.currentViewState.CollapsedGroups[row.group])
+		return
+	}
+	res := h.currentView.Resources[row.index]
+	h.setSelectedCollapsed(ctx, !res.IsCollapsed(h.currentViewState.Resources[row.index]))
+}
+
+// Must hold the lock.
+func (h *Hud) handleMouse(ctx context.Context, ev *tcell.EventMouse) {
+	if h.r.rty == nil {
+		return
+	}
+
+	buttons := ev.Buttons()
+	clicked := buttons&tcell.Button1 != 0 && h.mouseButtons&tcell.Button1 == 0
+	h.mouseButtons = buttons
+
+	x, y := ev.Position()
+	switch {
+	case buttons&tcell.WheelUp != 0:
+		h.scrollAt(x, y, true)
+	case buttons&tcell.WheelDown != 0:
+		h.scrollAt(x, y, false)
+	case clicked:
+		h.clickAt(ctx, x, y)
+	}
+}
+
+// Scrolls whatever is under the mouse: the log, or the resource list.
+// Must hold the lock.
+func (h *Hud) scrollAt(x, y int, up bool) {
+	var s scroller
+	lines := 1
+	if am := h.activeModal(); am != nil {
+		s = am
+	} else if log := h.r.rty.TextScroller("log"); log.Contains(x, y) {
+		s = log
+		lines = mouseWheelLines
+	} else if resources := h.r.rty.ElementScroller(resourcesScollerName); resources.Contains(x, y) {
+		s = resources
+	} else {
+		return
+	}
+
+	for i := 0; i < lines; i++ {
+		if up {
+			s.Up()
+		} else {
+			s.Down()
+		}
+	}
+	h.refreshSelectedIndex()
+}
+
+// Clicking a resource selects it. Clicking it again (or clicking its arrow)
+// expands or collapses it. In the alert pane, clicking an alert selects it,
+// and clicking it again goes to its resource.
+// Must hold the lock.
+func (h *Hud) clickAt(ctx context.Context, x, y int) {
+	vs := &h.currentViewState
+	if vs.AlertMessage != "" {
+		vs.AlertMessage = ""
+		return
+	}
+
+	if vs.ShowAlertPane {
+		alertScroller := h.r.rty.ElementScroller(alertPaneScrollerName)
+		i, _, _ := alertScroller.ChildAt(x, y)
+		if i < 0 {
+			return
+		}
+		if i == alertScroller.GetSelectedIndex() {
+			h.jumpToAlert(ctx)
+			return
+		}
+		alertScroller.Select(i)
+		return
+	}
+
+	if vs.TiltLogState == view.TiltLogFullScreen || vs.ResourceDetail != "" {
+		return
+	}
+
+	resources := h.r.rty.ElementScroller(resourcesScollerName)
+	i, childX, childY := resources.ChildAt(x, y)
+	if i < 0 {
+		return
+	}
+	h.recordInteraction("click_resource")
+	wasSelected := i == resources.GetSelectedIndex()
+	resources.Select(i)
+	h.refreshSelectedIndex()
+
+	onArrow := childX <= 1 && childY == 0
+	if wasSelected || onArrow {
+		h.toggleSelectedCollapsed(ctx)
+	}
+}
+
+// Re-renders the resource list after the rows in it changed, so that
+// the selection points at the right row.
+// Must hold the lock.
+func (h *Hud) resourceListChanged(ctx context.Context) {
+	if h.r.rty == nil {
+		return
+	}
+	err := h.refresh(ctx)
+	if err != nil {
+		return
+	}
+	h.refreshSelectedIndex()
+}
+
+// Closes the alert pane, and selects and expands the resource
+// that the selected alert is about.
+// Must hold the lock.
+func (h *Hud) jumpToAlert(ctx context.Context) {
+	vs := &h.currentViewState
+	vs.ShowAlertPane = false
+	if h.r.rty == nil {
+		return
+	}
+
+	as := alerts(h.currentView)
+	i := h.r.rty.ElementScroller(alertPaneScrollerName).GetSelectedIndex()
+	if i < 0 || i >= len(as) || as[i].resource == "" {
+		return
+	}
+	h.recordInteraction("jump_to_alert")
+
+	name := as[i].resource
+	resIdx := -1
+	for j, res := range h.currentView.Resources {
+		if res.Name == name {
+			resIdx = j
+		}
+	}
+	if resIdx < 0 || resIdx >= len(vs.Resources) {
+		return
+	}
+
+	// Make sure the resource is in the list.
+	res := h.currentView.Resources[resIdx]
+	if !resourceMatchesFilter(h.currentView, res, vs.ResourceFilter) {
+		vs.ResourceFilter = view.ResourceFilterState{}
+	}
+	delete(vs.CollapsedGroups, resourceGroup(res))
+	vs.Resources[resIdx].CollapseState = view.CollapseNo
+
+	err := h.refresh(ctx)
+	if err != nil {
+		return
+	}
+	for j, row := range resourceRows(h.currentView, *vs) {
+		if row.index == resIdx {
+			h.r.rty.ElementScroller(resourcesScollerName).Select(j)
+			break
+		}
+	}
+	h.refreshSelectedIndex()
+}
+
+// Shows the build log tab, with the next older or newer build.
+// Must hold the lock.
+func (h *Hud) pageBuildLog(older bool) {
+	vs := &h.currentViewState
+	_, selected := h.selectedResource()
+	builds := resourceBuilds(selected)
+	if len(builds) == 0 {
+		return
+	}
+
+	i := buildLogIndex(builds, vs.BuildLogStartTime)
+	if vs.TabState == view.TabBuildLog {
+		if older && i+1 < len(builds) {
+			i++
+		} else if !older && i > 0 {
+			i--
+		}
+	}
+	vs.TabState = view.TabBuildLog
+	if i == 0 {
+		vs.BuildLogStartTime = time.Time{}
+	} else {
+		vs.BuildLogStartTime = builds[i].StartTime
+	}
+
+	if h.r.rty != nil {
+		// Start at the end of the build, where the errors are.
+		h.r.rty.TextScroller("log").Bottom()
+	}
+}
+
+// Must hold the lock.
+func (h *Hud) clearLogSearch() {
+	h.currentViewState.LogSearch = view.LogSearchState{}
+	if h.r.rty != nil {
+		// Go back to following the log.
+		h.r.rty.TextScroller("log").Bottom()
+	}
+}
+
+// Scrolls the log pane to the current search match.
+// Must hold the lock.
+func (h *Hud) jumpToLogSearchMatch(ctx context.Context) {
+	if h.r.rty == nil {
+		return
+	}
+
+	// Render first, so that the log pane has a line for each line of the log.
+	err := h.refresh(ctx)
+	if err != nil {
+		return
+	}
+	line := newLogSearch(h.currentView, h.currentViewState).scrollLine()
+	if line >= 0 {
+		h.r.rty.TextScroller("log").ScrollTo(line)
+	}
+}
+
 func (h *Hud) OnChange(ctx context.Context, st store.RStore) {
 	state := st.RLockState()
 	view := store.StateToView(state)
@@ -286,10 +835,13 @@ func (h *Hud) setView(ctx context.Context, view view.View) error {
 	}
 	h.currentView = view
 	h.refreshSelectedIndex()
+	h.r.updateFileLinks(view)
 
 	// if the hud isn't running, make sure new logs are visible on stdout
 	logLen := view.Log.Len()
-	if !h.isRunning && h.currentViewState.ProcessedLogByteCount < logLen {
+	if h.stream != nil {
+		h.stream.print(view)
+	} else if !h.isRunning && h.currentViewState.ProcessedLogByteCount < logLen {
 		fmt.Print(view.Log.String()[h.currentViewState.ProcessedLogByteCount:])
 	}
 
@@ -314,9 +866,28 @@ func (h *Hud) refresh(ctx context.Context) error {
 	vs := h.currentViewState
 	vs.Resources = append(vs.Resources, h.currentViewState.Resources...)
 
+	h.updateLogPause()
+
 	return h.Update(h.currentView, h.currentViewState)
 }
 
+// Freezes the log pane when the user scrolls it up, and unfreezes it when
+// they go back to the bottom.
+// Must hold the lock.
+func (h *Hud) updateLogPause() {
+	vs := &h.currentViewState
+	if h.r.rty == nil || vs.LogSearch.Active() || h.r.rty.TextScroller("log").Following() {
+		vs.LogPause = view.LogPauseState{}
+		return
+	}
+
+	tv := NewTabView(h.currentView, *vs, h.r.theme)
+	if vs.LogPause.Paused && vs.LogPause.Tab == vs.TabState && vs.LogPause.Resource == tv.sourceResource() {
+		return
+	}
+	vs.LogPause = tv.pause()
+}
+
 func (h *Hud) Update(v view.View, vs view.ViewState) error {
 	err := h.r.Render(v, vs)
 	return errors.Wrap(err, "error rendering hud")
@@ -349,13 +920,14 @@ func (h *Hud) selectedResource() (i int, resource view.Resource) {
 	return selectedResource(h.currentView, h.currentViewState)
 }
 
+// Returns the index of the selected resource in view.Resources, or -1
+// if a group header (or nothing) is selected.
 func selectedResource(view view.View, state view.ViewState) (i int, resource view.Resource) {
-	i = state.SelectedIndex
-	if i >= 0 && i < len(view.Resources) {
-		resource = view.Resources[i]
+	row, ok := selectedRow(view, state)
+	if !ok || row.isGroupHeader() {
+		return -1, resource
 	}
-	return i, resource
-
+	return row.index, view.Resources[row.index]
 }
 
 var _ store.Subscriber = &Hud{}
@@ -0,0 +1,273 @@
+package hud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+	"github.com/windmilleng/wmclient/pkg/analytics"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/rty"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestMouseClickSelectsAndExpands(t *testing.T) {
+	h := newMouseTestHud(t)
+
+	// Each collapsed resource takes up one line, under the header.
+	h.click(2, 2)
+	assert.Equal(t, 1, h.currentViewState.SelectedIndex)
+	assert.Equal(t, view.CollapseState(view.CollapseAuto), h.currentViewState.Resources[1].CollapseState)
+
+	// Clicking it again expands it.
+	h.click(10, 2)
+	assert.Equal(t, view.CollapseState(view.CollapseNo), h.currentViewState.Resources[1].CollapseState)
+
+	// Clicking the arrow of another resource selects it and expands it.
+	h.click(0, 1)
+	assert.Equal(t, 0, h.currentViewState.SelectedIndex)
+	assert.Equal(t, view.CollapseState(view.CollapseNo), h.currentViewState.Resources[0].CollapseState)
+}
+
+func TestMouseHoldCountsAsOneClick(t *testing.T) {
+	h := newMouseTestHud(t)
+
+	h.mouse(2, 2, tcell.Button1)
+	h.mouse(2, 2, tcell.Button1)
+	assert.Equal(t, view.CollapseState(view.CollapseAuto), h.currentViewState.Resources[1].CollapseState)
+}
+
+func TestMouseWheelScrollsResources(t *testing.T) {
+	h := newMouseTestHud(t)
+
+	h.mouse(2, 2, tcell.WheelDown)
+	assert.Equal(t, 1, h.currentViewState.SelectedIndex)
+	h.mouse(2, 2, tcell.WheelUp)
+	assert.Equal(t, 0, h.currentViewState.SelectedIndex)
+}
+
+func TestLogPauseFreezesLog(t *testing.T) {
+	h := newMouseTestHud(t)
+
+	h.key('F')
+	assert.True(t, h.currentViewState.LogPause.Paused)
+	assert.Equal(t, "hello\n", h.currentViewState.LogPause.Text)
+
+	h.currentView.Log = model.NewLog("hello\nworld\nagain\n")
+	if err := h.refresh(output.CtxForTest()); err != nil {
+		t.Fatal(err)
+	}
+	tv := NewTabView(h.currentView, h.currentViewState, ThemeDefault)
+	assert.Equal(t, "hello\n", tv.log())
+	assert.Equal(t, 2, tv.newLineCount())
+
+	// Going back to following the log shows the new lines.
+	h.key('F')
+	assert.False(t, h.currentViewState.LogPause.Paused)
+	tv = NewTabView(h.currentView, h.currentViewState, ThemeDefault)
+	assert.Equal(t, "hello\nworld\nagain\n", tv.log())
+}
+
+func TestLogPauseFollowsNewTab(t *testing.T) {
+	h := newMouseTestHud(t)
+	h.currentView.Resources[0].CombinedLog = model.NewLog("vigoda log\n")
+	h.currentView.Resources[0].ResourceInfo = view.K8SResourceInfo{PodLog: model.NewLog("vigoda pod log\n")}
+
+	h.key('F')
+	h.key('3')
+	assert.Equal(t, view.TabPodLog, h.currentViewState.LogPause.Tab)
+	assert.Equal(t, model.ManifestName("vigoda"), h.currentViewState.LogPause.Resource)
+	assert.Equal(t, "vigoda pod log\n", h.currentViewState.LogPause.Text)
+}
+
+func TestPageBuildLog(t *testing.T) {
+	h := newMouseTestHud(t)
+	start := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.currentView.Resources[0].BuildHistory = []model.BuildRecord{
+		{StartTime: start.Add(2 * time.Minute), FinishTime: start.Add(3 * time.Minute), Log: model.NewLog("third\n")},
+		{StartTime: start.Add(time.Minute), FinishTime: start.Add(2 * time.Minute), Log: model.NewLog("second\n")},
+		{StartTime: start, FinishTime: start.Add(time.Minute), Log: model.NewLog("first\n")},
+	}
+	buildLog := func() string {
+		return NewTabView(h.currentView, h.currentViewState, ThemeDefault).log()
+	}
+
+	// The first press goes to the build log tab, and the next ones page.
+	h.key(',')
+	assert.Equal(t, view.TabBuildLog, h.currentViewState.TabState)
+	assert.Equal(t, "third\n", buildLog())
+	h.key(',')
+	assert.Equal(t, "second\n", buildLog())
+	h.key(',')
+	h.key(',')
+	assert.Equal(t, "first\n", buildLog())
+	assert.Equal(t, "✔ 3/3 (, .) ", NewTabView(h.currentView, h.currentViewState, ThemeDefault).buildLogStatus())
+
+	// A new build doesn't move us off of the one we're looking at.
+	h.currentView.Resources[0].CurrentBuild = model.BuildRecord{StartTime: start.Add(4 * time.Minute), Log: model.NewLog("fourth\n")}
+	assert.Equal(t, "first\n", buildLog())
+
+	h.key('.')
+	h.key('.')
+	h.key('.')
+	h.key('.')
+	assert.Equal(t, "fourth\n", buildLog())
+	assert.True(t, h.currentViewState.BuildLogStartTime.IsZero())
+}
+
+func TestResourceDetailShowsEveryEdit(t *testing.T) {
+	h := newMouseTestHud(t)
+
+	h.key('i')
+	assert.Equal(t, model.ManifestName("vigoda"), h.currentViewState.ResourceDetail)
+	h.key('e')
+	assert.True(t, h.currentViewState.ResourceDetailAllEdits)
+	h.key('e')
+	assert.False(t, h.currentViewState.ResourceDetailAllEdits)
+}
+
+func TestDeleteOrphansAsksFirst(t *testing.T) {
+	h := newMouseTestHud(t)
+
+	var actions []store.Action
+	dispatch := func(action store.Action) { actions = append(actions, action) }
+	press := func(r rune) {
+		h.handleScreenEvent(output.CtxForTest(), dispatch, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+
+	press('d')
+	assert.NotEqual(t, "", h.currentViewState.ConfirmPrompt)
+	assert.Empty(t, actions)
+
+	press('n')
+	assert.Equal(t, "", h.currentViewState.ConfirmPrompt)
+	assert.Empty(t, actions)
+
+	press('d')
+	press('y')
+	assert.Equal(t, "", h.currentViewState.ConfirmPrompt)
+	assert.Equal(t, []store.Action{view.DeleteOrphansAction{}}, actions)
+}
+
+func TestTearDownAsksFirst(t *testing.T) {
+	h := newMouseTestHud(t)
+
+	var actions []store.Action
+	dispatch := func(action store.Action) { actions = append(actions, action) }
+	press := func(r rune) {
+		h.handleScreenEvent(output.CtxForTest(), dispatch, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+
+	press('D')
+	assert.Contains(t, h.currentViewState.ConfirmPrompt, "vigoda")
+	assert.Empty(t, actions)
+
+	press('y')
+	assert.Equal(t, []store.Action{view.TearDownAction{Name: "vigoda"}}, actions)
+}
+
+func TestCtrlCQuitsWhileTyping(t *testing.T) {
+	for _, r := range []rune{'/', 'f'} {
+		h := newMouseTestHud(t)
+
+		var actions []store.Action
+		dispatch := func(action store.Action) { actions = append(actions, action) }
+		h.handleScreenEvent(output.CtxForTest(), dispatch, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		assert.True(t, h.currentViewState.LogSearch.Typing || h.currentViewState.ResourceFilter.Typing)
+
+		h.handleScreenEvent(output.CtxForTest(), dispatch, tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl))
+		assert.Equal(t, []store.Action{NewExitAction(nil)}, actions, string(r))
+	}
+}
+
+func TestReplayStepKeysOnlyInReplay(t *testing.T) {
+	h := newMouseTestHud(t)
+
+	var actions []store.Action
+	dispatch := func(action store.Action) { actions = append(actions, action) }
+	press := func(r rune) {
+		h.handleScreenEvent(output.CtxForTest(), dispatch, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+
+	press(']')
+	assert.Empty(t, actions)
+
+	h.EnableReplaySteps()
+	press(']')
+	press('[')
+	assert.Equal(t, []store.Action{StepReplayAction{Delta: 1}, StepReplayAction{Delta: -1}}, actions)
+}
+
+func TestGroupHeaderKeys(t *testing.T) {
+	h := newMouseTestHud(t)
+	h.currentViewState.GroupResources = true
+	if err := h.refresh(output.CtxForTest()); err != nil {
+		t.Fatal(err)
+	}
+
+	var actions []store.Action
+	dispatch := func(action store.Action) { actions = append(actions, action) }
+	press := func(key tcell.Key, r rune) {
+		h.handleScreenEvent(output.CtxForTest(), dispatch, tcell.NewEventKey(key, r, tcell.ModNone))
+	}
+
+	// The first row is the "Kubernetes" group header.
+	press(tcell.KeyUp, 0)
+	assert.Equal(t, 0, h.currentViewState.SelectedIndex)
+	press(tcell.KeyRune, 'r')
+	assert.Empty(t, actions)
+
+	press(tcell.KeyEnter, 0)
+	assert.True(t, h.currentViewState.CollapsedGroups["Kubernetes"])
+	press(tcell.KeyEnter, 0)
+	assert.False(t, h.currentViewState.CollapsedGroups["Kubernetes"])
+}
+
+type mouseTestHud struct {
+	*Hud
+	t *testing.T
+}
+
+func newMouseTestHud(t *testing.T) mouseTestHud {
+	r := NewRenderer(clockForTest, ThemeDefault)
+	sim := tcell.NewSimulationScreen("")
+	if err := sim.Init(); err != nil {
+		t.Fatal(err)
+	}
+	sim.SetSize(80, 30)
+	r.rty = rty.NewRTY(sim)
+
+	h := &Hud{r: r, a: analytics.NewMemoryAnalytics()}
+	h.currentView = view.View{
+		Resources: []view.Resource{
+			{Name: "vigoda", ResourceInfo: view.K8SResourceInfo{}},
+			{Name: "snack", ResourceInfo: view.K8SResourceInfo{}},
+			{Name: "beep", ResourceInfo: view.K8SResourceInfo{}},
+		},
+		Log: model.NewLog("hello\n"),
+	}
+	if err := h.refresh(output.CtxForTest()); err != nil {
+		t.Fatal(err)
+	}
+	return mouseTestHud{Hud: h, t: t}
+}
+
+func (h mouseTestHud) mouse(x, y int, buttons tcell.ButtonMask) {
+	h.handleMouse(output.CtxForTest(), tcell.NewEventMouse(x, y, buttons, tcell.ModNone))
+	if err := h.refresh(output.CtxForTest()); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+func (h mouseTestHud) key(r rune) {
+	h.handleScreenEvent(output.CtxForTest(), func(action store.Action) {}, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+}
+
+func (h mouseTestHud) click(x, y int) {
+	h.mouse(x, y, tcell.Button1)
+	h.mouse(x, y, tcell.ButtonNone)
+}
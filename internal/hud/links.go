@@ -0,0 +1,87 @@
+package hud
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/rty"
+)
+
+// An endpoint, as a link if it's a URL.
+func endpointText(endpoint string) rty.Component {
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return rty.TextString(endpoint)
+	}
+	return rty.NewStringBuilder().Link(endpoint).Text(endpoint).Link("").Build()
+}
+
+// Matches file references like "main.go:12", "./cmd/main.go:12:3", and
+// "/home/me/Tiltfile:4" in compiler and Tiltfile errors.
+var fileRefRe = regexp.MustCompile(`(?:^|[\s("'])((?:[\w./-]*/)?[\w.-]+):(\d+)(?::\d+)?`)
+
+// The file:// URLs of the files that build errors refer to, by the path as
+// it appears in the error, or "" if there's no such file.
+//
+// Going to the filesystem is too slow to do on every render, so the renderer
+// looks these up once each time the view changes.
+type fileLinks map[string]string
+
+func newFileLinks(v view.View, host string) fileLinks {
+	links := fileLinks{}
+	for _, res := range v.Resources {
+		for _, line := range buildErrorLines(res) {
+			for _, m := range fileRefRe.FindAllStringSubmatch(line, -1) {
+				path := m[1]
+				if _, ok := links[path]; !ok {
+					links[path] = fileURL(host, path)
+				}
+			}
+		}
+	}
+	return links
+}
+
+// A line of an error log, with any file references that exist on disk linked
+// to the file.
+func (links fileLinks) text(line string) rty.Component {
+	if len(links) == 0 {
+		return rty.TextString(line)
+	}
+
+	sb := rty.NewStringBuilder()
+	start := 0
+	for _, m := range fileRefRe.FindAllStringSubmatchIndex(line, -1) {
+		pathStart, pathEnd, lineEnd := m[2], m[3], m[5]
+		u := links[line[pathStart:pathEnd]]
+		if u == "" {
+			continue
+		}
+		sb.Text(line[start:pathStart]).Link(u).Text(line[pathStart:lineEnd]).Link("")
+		start = lineEnd
+	}
+	sb.Text(line[start:])
+	return sb.Build()
+}
+
+// A file:// URL for the path, or "" if there's no such file.
+// Relative paths are relative to the directory Tilt is running in.
+//
+// Terminals use the hostname to tell whether the file is on this machine
+// (e.g., when you're ssh'd somewhere else).
+func fileURL(host string, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(abs)
+	if err != nil || info.IsDir() {
+		return ""
+	}
+
+	u := url.URL{Scheme: "file", Host: host, Path: filepath.ToSlash(abs)}
+	return u.String()
+}
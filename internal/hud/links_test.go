@@ -0,0 +1,81 @@
+package hud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestFileURL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tilt-links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(path, []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "file://myhost"+path, fileURL("myhost", path))
+	assert.Equal(t, "", fileURL("myhost", filepath.Join(dir, "nope.go")))
+	assert.Equal(t, "", fileURL("myhost", dir))
+}
+
+func TestFileLinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tilt-links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(path, []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := view.View{Resources: []view.Resource{
+		{
+			Name: "broken",
+			BuildHistory: []model.BuildRecord{{
+				Error: fmt.Errorf("build failed"),
+				Log:   model.NewLog(fmt.Sprintf("%s:3:1: undefined: foo\nnope.go:4: bad\n", path)),
+			}},
+		},
+		{
+			Name:         "fine",
+			BuildHistory: []model.BuildRecord{{Log: model.NewLog("other.go:5: not an error\n")}},
+		},
+	}}
+
+	links := newFileLinks(v, "myhost")
+	assert.Equal(t, fileLinks{path: "file://myhost" + path, "nope.go": ""}, links)
+}
+
+func TestFileRefRe(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected []string
+	}{
+		{"./cmd/main.go:12:3: undefined: foo", []string{"./cmd/main.go"}},
+		{"Error in /home/me/Tiltfile:4: bad", []string{"/home/me/Tiltfile"}},
+		{"  File \"main.py\", line 3", nil},
+		{"(main.go:3) and other.go:4", []string{"main.go", "other.go"}},
+		{"listening on http://localhost:8080", nil},
+	}
+	for _, c := range cases {
+		var actual []string
+		for _, m := range fileRefRe.FindAllStringSubmatch(c.line, -1) {
+			actual = append(actual, m[1])
+		}
+		assert.Equal(t, c.expected, actual, c.line)
+	}
+}
@@ -0,0 +1,181 @@
+package hud
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/rty"
+)
+
+// How far back in the log we search. Every line is its own component
+// (so that we can scroll to it), so this needs to stay reasonably small.
+const logSearchLineCount = 5000
+
+// The log pane scrolls this many lines above the match, for context.
+const logSearchContextLines = 2
+
+var ansiEscapeRE = regexp.MustCompile("\x1b\\[[0-9;]*[a-zA-Z]")
+
+// The lines of the log being searched, and which of them match.
+type logSearch struct {
+	state   view.LogSearchState
+	lines   []string
+	matches []int
+
+	// Whether to cut off long lines instead of wrapping them, and from which column.
+	noWrap  bool
+	hscroll int
+}
+
+func newLogSearch(v view.View, vs view.ViewState) logSearch {
+	var log model.Log
+	if vs.LogSearch.AllLogs {
+		log = v.Log
+	} else {
+		_, res := selectedResource(v, vs)
+		log = res.CombinedLog
+	}
+
+	s := logSearch{state: vs.LogSearch, noWrap: vs.LogNoWrap, hscroll: vs.LogHScroll}
+	text := strings.TrimSuffix(log.Tail(logSearchLineCount).String(), "\n")
+	if text != "" {
+		s.lines = strings.Split(text, "\n")
+	}
+
+	if s.state.Query == "" {
+		return s
+	}
+	for i, line := range s.lines {
+		if len(matchRanges(line, s.state.Query)) > 0 {
+			s.matches = append(s.matches, i)
+		}
+	}
+	return s
+}
+
+// The index of the current match, wrapped around so that `n` on the last
+// match goes back to the first.
+func (s logSearch) current() int {
+	if len(s.matches) == 0 {
+		return -1
+	}
+	i := s.state.Match % len(s.matches)
+	if i < 0 {
+		i += len(s.matches)
+	}
+	return i
+}
+
+// The line to scroll to, to show the current match.
+func (s logSearch) scrollLine() int {
+	i := s.current()
+	if i < 0 {
+		return -1
+	}
+	line := s.matches[i] - logSearchContextLines
+	if line < 0 {
+		line = 0
+	}
+	return line
+}
+
+func (s logSearch) build(theme Theme) rty.Component {
+	l := rty.NewTextScrollLayout("log")
+	if len(s.lines) == 0 {
+		l.Add(rty.TextString("(no logs received)"))
+		return l
+	}
+
+	currentLine := -1
+	if i := s.current(); i >= 0 {
+		currentLine = s.matches[i]
+	}
+
+	for i, line := range s.lines {
+		ranges := matchRanges(line, s.state.Query)
+		if len(ranges) == 0 {
+			l.Add(s.buildLine(rty.NewStringBuilder().Text(line)))
+			continue
+		}
+
+		bg := theme.Match
+		if i == currentLine {
+			bg = theme.CurrentMatch
+		}
+
+		// Drop the line's own colors, so that the highlights show up.
+		plain := ansiEscapeRE.ReplaceAllString(line, "")
+		sb := rty.NewStringBuilder()
+		start := 0
+		for _, r := range ranges {
+			sb.Text(plain[start:r[0]])
+			sb.Fg(theme.Text).Bg(bg).Text(plain[r[0]:r[1]]).Fg(tcell.ColorDefault).Bg(tcell.ColorDefault)
+			start = r[1]
+		}
+		sb.Text(plain[start:])
+		l.Add(s.buildLine(sb))
+	}
+	return l
+}
+
+func (s logSearch) buildLine(sb rty.StringBuilder) rty.Component {
+	if s.noWrap {
+		return sb.BuildTruncated(s.hscroll)
+	}
+	return sb.Build()
+}
+
+// Describes the search, for the log pane's header.
+func (s logSearch) status() string {
+	scope := ""
+	if s.state.AllLogs {
+		scope = " (all logs)"
+	}
+	if s.state.Query == "" {
+		return fmt.Sprintf("search%s ", scope)
+	}
+	if len(s.matches) == 0 {
+		return fmt.Sprintf("%q: no matches%s ", s.state.Query, scope)
+	}
+	return fmt.Sprintf("%q: %d/%d%s ", s.state.Query, s.current()+1, len(s.matches), scope)
+}
+
+// Where query appears in the line (with ANSI codes stripped), as [start, end) pairs.
+// Case-insensitive, unless the query has an upper-case letter.
+func matchRanges(line, query string) [][2]int {
+	if query == "" {
+		return nil
+	}
+	plain := ansiEscapeRE.ReplaceAllString(line, "")
+	haystack := plain
+	if lower := strings.ToLower(plain); !hasUpper(query) && len(lower) == len(plain) {
+		haystack = lower
+	}
+
+	var result [][2]int
+	offset := 0
+	for {
+		i := strings.Index(haystack[offset:], query)
+		if i < 0 {
+			return result
+		}
+		start := offset + i
+		result = append(result, [2]int{start, start + len(query)})
+		offset = start + len(query)
+	}
+}
+
+func hasUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
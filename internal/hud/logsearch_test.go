@@ -0,0 +1,62 @@
+package hud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestMatchRanges(t *testing.T) {
+	assert.Equal(t, [][2]int{{0, 5}, {11, 16}}, matchRanges("panic: the PANIC", "panic"))
+	assert.Equal(t, [][2]int{{11, 16}}, matchRanges("panic: the PANIC", "PANIC"))
+	assert.Equal(t, [][2]int{{3, 8}}, matchRanges("oh \x1b[31mpanic\x1b[0m!", "panic"))
+	assert.Empty(t, matchRanges("all good", "panic"))
+	assert.Empty(t, matchRanges("all good", ""))
+}
+
+func TestLogSearchScopes(t *testing.T) {
+	v := view.View{
+		Log: model.NewLog("foo: error\nbar: error\nbar: ok\n"),
+		Resources: []view.Resource{
+			{Name: "foo", CombinedLog: model.NewLog("error\n")},
+			{Name: "bar", CombinedLog: model.NewLog("error\nok\n")},
+		},
+	}
+	vs := view.ViewState{SelectedIndex: 1, LogSearch: view.LogSearchState{Query: "ok"}}
+
+	s := newLogSearch(v, vs)
+	assert.Equal(t, []string{"error", "ok"}, s.lines)
+	assert.Equal(t, []int{1}, s.matches)
+	assert.Equal(t, `"ok": 1/1 `, s.status())
+
+	vs.LogSearch = view.LogSearchState{Query: "error", AllLogs: true}
+	s = newLogSearch(v, vs)
+	assert.Equal(t, []int{0, 1}, s.matches)
+	assert.Equal(t, `"error": 1/2 (all logs) `, s.status())
+}
+
+func TestLogSearchMatchesWrapAround(t *testing.T) {
+	v := view.View{
+		Resources: []view.Resource{
+			{Name: "foo", CombinedLog: model.NewLog("a\nb\na\nb\nb\na\n")},
+		},
+	}
+	vs := view.ViewState{LogSearch: view.LogSearchState{Query: "a", Match: 3}}
+
+	s := newLogSearch(v, vs)
+	assert.Equal(t, 0, s.current())
+	assert.Equal(t, 0, s.scrollLine())
+
+	vs.LogSearch.Match = -1
+	s = newLogSearch(v, vs)
+	assert.Equal(t, 2, s.current())
+	assert.Equal(t, 3, s.scrollLine())
+
+	vs.LogSearch.Query = "c"
+	s = newLogSearch(v, vs)
+	assert.Equal(t, -1, s.current())
+	assert.Equal(t, `"c": no matches `, s.status())
+}
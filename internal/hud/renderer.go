@@ -18,17 +18,31 @@ import (
 type Renderer struct {
 	rty    rty.RTY
 	screen tcell.Screen
+	tty    *os.File
 	mu     *sync.Mutex
 	clock  func() time.Time
+	theme  Theme
+
+	host      string
+	fileLinks fileLinks
 }
 
-func NewRenderer(clock func() time.Time) *Renderer {
+func NewRenderer(clock func() time.Time, theme Theme) *Renderer {
 	return &Renderer{
 		mu:    new(sync.Mutex),
 		clock: clock,
+		theme: theme,
 	}
 }
 
+var mouseEnabled = true
+
+// Sets whether the HUD handles mouse clicks and scrolling, for the rest of the process.
+// While it does, most terminals only select text when you hold down shift.
+func SetMouseEnabled(enabled bool) {
+	mouseEnabled = enabled
+}
+
 func (r *Renderer) Render(v view.View, vs view.ViewState) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -42,58 +56,79 @@ func (r *Renderer) Render(v view.View, vs view.ViewState) error {
 	return nil
 }
 
-var cText = tcell.Color232
-var cLightText = tcell.Color243
-var cGood = tcell.ColorGreen
-var cBad = tcell.ColorRed
-var cPending = tcell.ColorYellow
-
-var statusColors = map[string]tcell.Color{
-	"Running":                          cGood,
-	"ContainerCreating":                cPending,
-	"Pending":                          cPending,
-	"PodInitializing":                  cPending,
-	"Error":                            cBad,
-	"CrashLoopBackOff":                 cBad,
-	"ErrImagePull":                     cBad,
-	"ImagePullBackOff":                 cBad,
-	string(dockercompose.StatusInProg): cPending,
-	string(dockercompose.StatusUp):     cGood,
-	string(dockercompose.StatusDown):   cBad,
-	"Completed":                        cGood,
+var runtimeStatuses = map[string]resourceStatus{
+	"Running":                          statusGood,
+	"ContainerCreating":                statusPending,
+	"Pending":                          statusPending,
+	"PodInitializing":                  statusPending,
+	"Error":                            statusBad,
+	"CrashLoopBackOff":                 statusBad,
+	"ErrImagePull":                     statusBad,
+	"ImagePullBackOff":                 statusBad,
+	"InvalidImageName":                 statusBad,
+	"OOMKilled":                        statusBad,
+	"CreateContainerConfigError":       statusBad,
+	string(dockercompose.StatusInProg): statusPending,
+	string(dockercompose.StatusUp):     statusGood,
+	string(dockercompose.StatusDown):   statusBad,
+	"Completed":                        statusGood,
+}
+
+// Looks up the files that the view's build errors refer to, so that we can
+// link to them without going to the filesystem on every render.
+func (r *Renderer) updateFileLinks(v view.View) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !rty.HyperlinksEnabled() {
+		r.fileLinks = nil
+		return
+	}
+	if r.host == "" {
+		r.host, _ = os.Hostname()
+	}
+	r.fileLinks = newFileLinks(v, r.host)
 }
 
 func (r *Renderer) layout(v view.View, vs view.ViewState) rty.Component {
 	l := rty.NewFlexLayout(rty.DirVert)
 	if vs.ShowNarration {
-		l.Add(renderNarration(vs.NarrationMessage))
+		l.Add(r.renderNarration(vs.NarrationMessage))
+		l.Add(rty.NewLine())
+	}
+	if v.KubeContextAlert != "" {
+		l.Add(r.renderKubeContextAlert(v.KubeContextAlert))
 		l.Add(rty.NewLine())
 	}
 
 	l.Add(r.renderResourceHeader(v))
 	l.Add(r.renderResources(v, vs))
 	l.Add(r.renderLogPane(v, vs))
-	l.Add(r.renderFooter(v, keyLegend(v, vs)))
+	l.Add(r.renderFooter(v, vs, keyLegend(v, vs)))
 
 	var ret rty.Component = l
 
 	ret = r.maybeAddFullScreenLog(v, vs, ret)
 
+	ret = r.maybeAddResourceDetail(v, vs, ret)
+
+	ret = r.maybeAddAlertPane(v, vs, ret)
+
 	ret = r.maybeAddAlertModal(vs, ret)
 
+	ret = r.maybeAddConfirmModal(vs, ret)
+
 	return ret
 }
 
 func (r *Renderer) maybeAddFullScreenLog(v view.View, vs view.ViewState, layout rty.Component) rty.Component {
 	if vs.TiltLogState == view.TiltLogFullScreen {
-		tabView := NewTabView(v, vs)
+		tabView := NewTabView(v, vs, r.theme)
 
 		l := rty.NewConcatLayout(rty.DirVert)
-		sl := rty.NewTextScrollLayout("log")
 		l.Add(tabView.buildTabs(true))
-		sl.Add(rty.TextString(tabView.log()))
-		l.AddDynamic(sl)
-		l.Add(r.renderFooter(v, keyLegend(v, vs)))
+		l.AddDynamic(tabView.buildLog())
+		l.Add(r.renderFooter(v, vs, keyLegend(v, vs)))
 
 		layout = rty.NewModalLayout(layout, l, 1, true)
 	}
@@ -111,13 +146,27 @@ func (r *Renderer) maybeAddAlertModal(vs view.ViewState, layout rty.Component) r
 
 		w := rty.NewWindow(l)
 		w.SetTitle("! Alert !")
-		layout = r.renderModal(rty.Fg(w, tcell.ColorRed), layout, false)
+		layout = r.renderModal(rty.Fg(w, r.theme.Bad), layout, false)
+	}
+	return layout
+}
+
+func (r *Renderer) maybeAddConfirmModal(vs view.ViewState, layout rty.Component) rty.Component {
+	if vs.ConfirmPrompt != "" {
+		l := rty.NewLines()
+		l.Add(rty.TextString(""))
+		l.Add(rty.Fg(rty.TextString("   "+vs.ConfirmPrompt+"   "), tcell.ColorDefault))
+		l.Add(rty.TextString(""))
+
+		w := rty.NewWindow(l)
+		w.SetTitle("Are you sure?")
+		layout = r.renderModal(rty.Fg(w, r.theme.Pending), layout, false)
 	}
 	return layout
 }
 
 func (r *Renderer) renderLogPane(v view.View, vs view.ViewState) rty.Component {
-	tabView := NewTabView(v, vs)
+	tabView := NewTabView(v, vs, r.theme)
 	var height int
 	switch vs.TiltLogState {
 	case view.TiltLogShort:
@@ -146,17 +195,17 @@ func renderPaneHeader(isMax bool) rty.Component {
 	return l
 }
 
-func (r *Renderer) renderStatusBar(v view.View) rty.Component {
+func (r *Renderer) renderStatusBar(v view.View, vs view.ViewState) rty.Component {
 	sb := rty.NewStringBuilder()
 	sb.Text(" ") // Indent
 	errorCount := 0
 	for _, res := range v.Resources {
-		if isInError(res, v.TriggerMode) {
+		if isInError(res, triggerMode(v, res)) {
 			errorCount++
 		}
 	}
 	if errorCount == 0 && v.TiltfileErrorMessage() == "" {
-		sb.Fg(cGood).Text("✓").Fg(tcell.ColorDefault).Fg(cText).Text(" OK").Fg(tcell.ColorDefault)
+		sb.Fg(r.theme.Good).Text("✓").Fg(tcell.ColorDefault).Fg(r.theme.Text).Text(" OK").Fg(tcell.ColorDefault)
 	} else {
 		var errorCountMessage string
 		var tiltfileError strings.Builder
@@ -172,14 +221,38 @@ func (r *Renderer) renderStatusBar(v view.View) rty.Component {
 		if v.TiltfileErrorMessage() != "" {
 			_, _ = tiltfileError.WriteString(" • Tiltfile error")
 		}
-		sb.Fg(cBad).Text("✖").Fg(tcell.ColorDefault).Fg(cText).Textf("%s%s", errorCountMessage, tiltfileError.String()).Fg(tcell.ColorDefault)
+		sb.Fg(r.theme.Bad).Text("✖").Fg(tcell.ColorDefault).Fg(r.theme.Text).Textf("%s%s", errorCountMessage, tiltfileError.String()).Fg(tcell.ColorDefault)
+	}
+	if v.UpdatesPaused {
+		sb.Fg(r.theme.Text).Text(" • Updates paused (P to resume)").Fg(tcell.ColorDefault)
+	}
+	if bs := buildingStatus(v); bs != "" {
+		sb.Fg(r.theme.Text).Text(" • " + bs).Fg(tcell.ColorDefault)
 	}
-	return rty.Bg(rty.OneLine(sb.Build()), tcell.ColorWhiteSmoke)
+	if fs := filterStatus(v, vs); fs != "" {
+		sb.Fg(r.theme.Text).Text(" • " + fs).Fg(tcell.ColorDefault)
+	}
+	return rty.Bg(rty.OneLine(sb.Build()), r.theme.Bar)
 }
 
-func (r *Renderer) renderFooter(v view.View, keys string) rty.Component {
+// With parallel builds, the resource list can scroll the ones in progress out
+// of sight, so list them all. Empty unless more than one is building.
+func buildingStatus(v view.View) string {
+	var names []string
+	for _, res := range v.Resources {
+		if !res.CurrentBuild.Empty() {
+			names = append(names, res.Name.String())
+		}
+	}
+	if len(names) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("Building %d: %s", len(names), strings.Join(names, ", "))
+}
+
+func (r *Renderer) renderFooter(v view.View, vs view.ViewState, keys string) rty.Component {
 	footer := rty.NewConcatLayout(rty.DirVert)
-	footer.Add(r.renderStatusBar(v))
+	footer.Add(r.renderStatusBar(v, vs))
 	l := rty.NewConcatLayout(rty.DirHor)
 	sbRight := rty.NewStringBuilder()
 	sbRight.Text(keys)
@@ -191,20 +264,58 @@ func (r *Renderer) renderFooter(v view.View, keys string) rty.Component {
 
 func keyLegend(v view.View, vs view.ViewState) string {
 	defaultKeys := "Browse (↓ ↑), Expand (→) ┊ (enter) log, (b)rowser ┊ (ctrl-C) quit  "
-	if vs.AlertMessage != "" {
+	if vs.ConfirmPrompt != "" {
+		return "(y) yes ┊ any other key: no  "
+	} else if vs.AlertMessage != "" {
 		return "Tilt (l)og ┊ (esc) close alert "
+	} else if vs.ShowAlertPane {
+		return "Browse (↓ ↑) ┊ (enter) go to resource ┊ (esc) close  "
+	} else if vs.ResourceDetail != "" && vs.ResourceDetailAllEdits {
+		return "Scroll (↓ ↑) ┊ (e) hide changed files ┊ (esc) close  "
+	} else if vs.ResourceDetail != "" {
+		return "Scroll (↓ ↑) ┊ (e) show changed files ┊ (esc) close  "
+	} else if vs.ResourceFilter.Typing {
+		return fmt.Sprintf("Filter: %s█ ┊ (enter) done, (esc) clear  ", vs.ResourceFilter.Name)
+	} else if vs.LogSearch.Typing {
+		return fmt.Sprintf("/%s█ ┊ (tab) all logs / this resource ┊ (enter) search, (esc) cancel  ", vs.LogSearch.Query)
+	} else if vs.LogSearch.Query != "" {
+		return "Next (n), previous (N) match ┊ (/) new search, (esc) clear ┊ (ctrl-C) quit  "
 	} else if v.TriggerMode == model.TriggerManual {
 		return "Build (space) ┊ " + defaultKeys
 	}
+	for _, res := range v.Resources {
+		if res.TriggerMode == model.TriggerManual {
+			return "Build (space) ┊ " + defaultKeys
+		}
+	}
 	return defaultKeys
 }
 
+// Resources in manual mode wait for the user to build them,
+// either because the whole session is manual or just the resource.
+func triggerMode(v view.View, res view.Resource) model.TriggerMode {
+	if v.TriggerMode == model.TriggerManual {
+		return model.TriggerManual
+	}
+	return res.TriggerMode
+}
+
 func isInError(res view.Resource, triggerMode model.TriggerMode) bool {
-	return statusColor(res, triggerMode) == cBad
+	return statusOf(res, triggerMode) == statusBad
 }
 
 func warnings(res view.Resource) []string {
-	return res.LastBuild().Warnings
+	result := res.LastBuild().Warnings
+	if res.IsK8S() {
+		result = append(append([]string{}, result...), res.K8SInfo().PodUsageWarnings...)
+		if drift := res.K8SInfo().Drift; drift != "" {
+			result = append(result, "Modified outside of Tilt. Press r to re-apply:\n"+drift)
+		}
+	}
+	if res.WaitingOnReadinessCheck && res.ReadinessCheckError != "" {
+		result = append(result, "Not ready: "+res.ReadinessCheckError)
+	}
+	return result
 }
 
 func isCrashing(res view.Resource) bool {
@@ -219,7 +330,7 @@ func (r *Renderer) renderModal(fg rty.Component, bg rty.Component, fixed bool) r
 	return rty.NewModalLayout(bg, fg, .9, fixed)
 }
 
-func renderNarration(msg string) rty.Component {
+func (r *Renderer) renderNarration(msg string) rty.Component {
 	lines := rty.NewLines()
 	l := rty.NewLine()
 	l.Add(rty.TextString(msg))
@@ -227,47 +338,61 @@ func renderNarration(msg string) rty.Component {
 	lines.Add(l)
 	lines.Add(rty.NewLine())
 
-	box := rty.Fg(rty.Bg(lines, tcell.ColorLightGrey), cText)
+	box := rty.Fg(rty.Bg(lines, r.theme.Narration), r.theme.Text)
 	return rty.NewFixedSize(box, rty.GROW, 3)
 }
 
+func (r *Renderer) renderKubeContextAlert(msg string) rty.Component {
+	lines := rty.NewLines()
+	l := rty.NewLine()
+	l.Add(rty.TextString(" ! " + msg))
+	lines.Add(l)
+
+	box := rty.Fg(rty.Bg(lines, r.theme.Bad), tcell.ColorWhite)
+	return rty.NewFixedSize(box, rty.GROW, 1)
+}
+
 func (r *Renderer) renderResourceHeader(v view.View) rty.Component {
 	l := rty.NewConcatLayout(rty.DirHor)
-	l.Add(rty.ColoredString("  RESOURCE NAME ", cLightText))
+	l.Add(rty.ColoredString("  RESOURCE NAME ", r.theme.LightText))
 	l.AddDynamic(rty.NewFillerString(' '))
 
-	k8sCell := rty.ColoredString(" CONTAINER", cLightText)
+	k8sCell := rty.ColoredString(" CONTAINER", r.theme.LightText)
 	l.Add(k8sCell)
-	l.Add(middotText())
+	l.Add(middotText(r.theme))
 
 	buildCell := rty.NewMinLengthLayout(BuildDurCellMinWidth+BuildStatusCellMinWidth, rty.DirHor).
 		SetAlign(rty.AlignEnd).
-		Add(rty.ColoredString("UPDATE STATUS ", cLightText))
+		Add(rty.ColoredString("UPDATE STATUS ", r.theme.LightText))
 	l.Add(buildCell)
-	l.Add(middotText())
+	l.Add(middotText(r.theme))
 	deployCell := rty.NewMinLengthLayout(DeployCellMinWidth+1, rty.DirHor).
 		SetAlign(rty.AlignEnd).
-		Add(rty.ColoredString("AS OF ", cLightText))
+		Add(rty.ColoredString("AS OF ", r.theme.LightText))
 	l.Add(deployCell)
 	return rty.OneLine(l)
 }
 
 func (r *Renderer) renderResources(v view.View, vs view.ViewState) rty.Component {
-	rs := v.Resources
+	rows := resourceRows(v, vs)
 
 	cl := rty.NewConcatLayout(rty.DirVert)
 
-	childNames := make([]string, len(rs))
-	for i, r := range rs {
-		childNames[i] = r.Name.String()
+	childNames := make([]string, len(rows))
+	for i, row := range rows {
+		childNames[i] = row.name(v)
 	}
 	// the items added to `l` below must be kept in sync with `childNames` above
-	l, selectedResource := r.rty.RegisterElementScroll(resourcesScollerName, childNames)
+	l, selectedName := r.rty.RegisterElementScroll(resourcesScollerName, childNames)
 
-	if len(rs) > 0 {
-		for i, res := range rs {
-			l.Add(r.renderResource(res, vs.Resources[i], v.TriggerMode, selectedResource == res.Name.String()))
+	for i, row := range rows {
+		selected := selectedName == childNames[i]
+		if row.isGroupHeader() {
+			l.Add(r.renderGroupHeader(v, vs, row.group, selected))
+			continue
 		}
+		res := v.Resources[row.index]
+		l.Add(r.renderResource(res, vs.Resources[row.index], triggerMode(v, res), selected))
 	}
 
 	cl.Add(l)
@@ -275,7 +400,7 @@ func (r *Renderer) renderResources(v view.View, vs view.ViewState) rty.Component
 }
 
 func (r *Renderer) renderResource(res view.Resource, rv view.ResourceViewState, triggerMode model.TriggerMode, selected bool) rty.Component {
-	return NewResourceView(res, rv, triggerMode, selected, r.clock).Build()
+	return NewResourceView(res, rv, triggerMode, selected, r.clock, r.theme, r.fileLinks).Build()
 }
 
 func (r *Renderer) SetUp() (chan tcell.Event, error) {
@@ -299,6 +424,9 @@ func (r *Renderer) SetUp() (chan tcell.Event, error) {
 	if err = screen.Init(); err != nil {
 		return nil, err
 	}
+	if mouseEnabled {
+		screen.EnableMouse()
+	}
 	screenEvents := make(chan tcell.Event)
 	go func() {
 		for {
@@ -306,7 +434,25 @@ func (r *Renderer) SetUp() (chan tcell.Event, error) {
 		}
 	}()
 
-	r.rty = rty.NewRTY(screen)
+	var rtyScreen tcell.Screen = screen
+	if r.theme.NoColor {
+		rtyScreen = noColorScreen{screen}
+	}
+
+	// tcell can't draw hyperlinks, so rty draws them over the screen itself,
+	// on the same terminal that tcell opens.
+	r.tty = nil
+	if rty.HyperlinksEnabled() {
+		r.tty, err = os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			r.tty = nil
+		}
+	}
+	if r.tty != nil {
+		r.rty = rty.NewRTYWithHyperlinks(rtyScreen, r.tty)
+	} else {
+		r.rty = rty.NewRTY(rtyScreen)
+	}
 
 	r.screen = screen
 
@@ -320,6 +466,11 @@ func (r *Renderer) Reset() {
 	if r.screen != nil {
 		r.screen.Fini()
 	}
+	if r.tty != nil {
+		_ = r.tty.Close()
+	}
 
+	r.rty = nil
 	r.screen = nil
+	r.tty = nil
 }
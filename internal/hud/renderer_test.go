@@ -88,6 +88,7 @@ ERROR: ImageBuild: executor failed running [/bin/sh -c go install github.com/win
 				ResourceInfo: view.K8SResourceInfo{
 					PodName:     "vigoda-pod",
 					PodStatus:   "Running",
+					PodReady:    true,
 					PodRestarts: 1,
 					PodLog:      model.NewLog("1\n2\n3\n4\nabe vigoda is now dead\n5\n6\n7\n8\n"),
 				},
@@ -135,6 +136,7 @@ ERROR: ImageBuild: executor failed running [/bin/sh -c go install github.com/win
 					PodName:         "vigoda-pod",
 					PodCreationTime: ts,
 					PodStatus:       "Running",
+					PodReady:        true,
 					PodRestarts:     1,
 					PodLog:          model.NewLog("1\n2\n3\n4\nabe vigoda is now dead\n5\n6\n7\n8\n"),
 				},
@@ -163,6 +165,7 @@ ERROR: ImageBuild: executor failed running [/bin/sh -c go install github.com/win
 					PodName:         "vigoda-pod",
 					PodCreationTime: ts,
 					PodStatus:       "Running",
+					PodReady:        true,
 					PodRestarts:     0,
 				},
 				Endpoints: []string{"1.2.3.4:8080"},
@@ -187,6 +190,7 @@ ERROR: ImageBuild: executor failed running [/bin/sh -c go install github.com/win
 					PodName:         "vigoda-pod",
 					PodCreationTime: ts,
 					PodStatus:       "Running",
+					PodReady:        true,
 					PodRestarts:     1,
 					PodLog: model.NewLog(`abe vigoda is crashing
 oh noooooooooooooooooo nooooooooooo noooooooooooo nooooooooooo
@@ -265,6 +269,125 @@ oh noooooooooooooooooo nooooooooooo noooooooooooo nooooooooooo`),
 	rtf.run("edited files wide term", 120, 20, v, plainVs)
 }
 
+func TestRenderPortForwardReconnecting(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	ts := time.Now().Add(-30 * time.Second)
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:           "vigoda",
+				LastDeployTime: ts,
+				BuildHistory: []model.BuildRecord{{
+					FinishTime: ts,
+					StartTime:  ts.Add(-1400 * time.Millisecond),
+				}},
+				Endpoints: []string{"http://localhost:8080/"},
+				ResourceInfo: view.K8SResourceInfo{
+					PodName:           "vigoda-pod",
+					PodCreationTime:   ts,
+					PodStatus:         "Running",
+					PodReady:          true,
+					PortForwardStatus: "reconnecting",
+				},
+			},
+		},
+	}
+	vs := fakeViewState(1, view.CollapseNo)
+
+	rtf.run("port forward reconnecting", 70, 20, v, vs)
+}
+
+func TestRenderPodAlerts(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	ts := time.Now().Add(-30 * time.Second)
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:           "vigoda",
+				LastDeployTime: ts,
+				BuildHistory: []model.BuildRecord{{
+					FinishTime: ts,
+					StartTime:  ts.Add(-1400 * time.Millisecond),
+				}},
+				ResourceInfo: view.K8SResourceInfo{
+					PodName:         "vigoda-pod",
+					PodCreationTime: ts,
+					PodStatus:       "ImagePullBackOff",
+					PodAlerts: []string{
+						`Container "vigoda" can't pull image "gcr.io/vigoda:tilt-123": Back-off pulling image "gcr.io/vigoda:tilt-123"`,
+						"Check that the image exists, and that the cluster can authenticate to its registry.",
+					},
+				},
+			},
+		},
+	}
+	vs := fakeViewState(1, view.CollapseNo)
+
+	rtf.run("pod alerts", 70, 20, v, vs)
+}
+
+func TestRenderPodUsage(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	ts := time.Now().Add(-30 * time.Second)
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:           "vigoda",
+				LastDeployTime: ts,
+				BuildHistory: []model.BuildRecord{{
+					FinishTime: ts,
+					StartTime:  ts.Add(-1400 * time.Millisecond),
+				}},
+				ResourceInfo: view.K8SResourceInfo{
+					PodName:         "vigoda-pod",
+					PodCreationTime: ts,
+					PodStatus:       "Running",
+					PodReady:        true,
+					PodCPU:          "250m",
+					PodMemory:       "118Mi",
+					PodUsageWarnings: []string{
+						`Container "vigoda" is using 92% of its memory limit (118Mi of 128Mi), and will be OOMKilled if it goes over`,
+					},
+				},
+			},
+		},
+	}
+	vs := fakeViewState(1, view.CollapseNo)
+
+	rtf.run("pod usage", 70, 20, v, vs)
+}
+
+func TestRenderDrift(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	ts := time.Now().Add(-30 * time.Second)
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:           "vigoda",
+				LastDeployTime: ts,
+				BuildHistory: []model.BuildRecord{{
+					FinishTime: ts,
+					StartTime:  ts.Add(-1400 * time.Millisecond),
+				}},
+				ResourceInfo: view.K8SResourceInfo{
+					PodName:         "vigoda-pod",
+					PodCreationTime: ts,
+					PodStatus:       "Running",
+					PodReady:        true,
+					Drift:           "Deployment default/vigoda:\n-  replicas: 3\n+  replicas: 1",
+				},
+			},
+		},
+	}
+	vs := fakeViewState(1, view.CollapseNo)
+
+	rtf.run("drift", 70, 20, v, vs)
+}
+
 func TestRenderTiltLog(t *testing.T) {
 	rtf := newRendererTestFixture(t)
 
@@ -361,14 +484,53 @@ func TestPodPending(t *testing.T) {
 	vs := fakeViewState(1, view.CollapseAuto)
 
 	rtf.run("pending pod no status", 80, 20, v, vs)
-	assert.Equal(t, cPending, statusColor(v.Resources[0], model.TriggerAuto))
+	assert.Equal(t, statusPending, statusOf(v.Resources[0], model.TriggerAuto))
 
 	v.Resources[0].ResourceInfo = view.K8SResourceInfo{
 		PodCreationTime: ts,
 		PodStatus:       "Pending",
 	}
 	rtf.run("pending pod pending status", 80, 20, v, vs)
-	assert.Equal(t, cPending, statusColor(v.Resources[0], model.TriggerAuto))
+	assert.Equal(t, statusPending, statusOf(v.Resources[0], model.TriggerAuto))
+
+	// A running pod is pending until its readiness probes pass.
+	v.Resources[0].ResourceInfo = view.K8SResourceInfo{
+		PodCreationTime: ts,
+		PodStatus:       "Running",
+	}
+	assert.Equal(t, statusPending, statusOf(v.Resources[0], model.TriggerAuto))
+
+	v.Resources[0].ResourceInfo = view.K8SResourceInfo{
+		PodCreationTime: ts,
+		PodStatus:       "Running",
+		PodReady:        true,
+	}
+	assert.Equal(t, statusGood, statusOf(v.Resources[0], model.TriggerAuto))
+}
+
+func TestCanceledBuildIsNeutral(t *testing.T) {
+	ts := time.Now().Add(-30 * time.Second)
+	res := view.Resource{
+		Name: "vigoda",
+		BuildHistory: []model.BuildRecord{{
+			StartTime:  ts,
+			FinishTime: ts,
+			Error:      fmt.Errorf("Build canceled: canceled by user"),
+			Canceled:   true,
+		}},
+		ResourceInfo: view.K8SResourceInfo{
+			PodCreationTime: ts,
+			PodStatus:       "Running",
+			PodReady:        true,
+		},
+		LastDeployTime: ts,
+	}
+
+	assert.Equal(t, statusNone, statusOf(res, model.TriggerAuto))
+	bs := makeBuildStatus(res, model.TriggerAuto)
+	assert.Equal(t, "Canceled", bs.status)
+	assert.True(t, bs.muted)
+	assert.Empty(t, resourceAlerts(view.View{}, res))
 }
 
 func TestCrashingPodInlineCrashLog(t *testing.T) {
@@ -450,6 +612,7 @@ func TestNonCrashingPodNoInlineCrashLog(t *testing.T) {
 				ResourceInfo: view.K8SResourceInfo{
 					PodName:            "vigoda-pod",
 					PodStatus:          "Running",
+					PodReady:           true,
 					PodLog:             model.NewLog("Something's maybe wrong idk"),
 					PodUpdateStartTime: ts,
 					PodCreationTime:    ts.Add(-time.Minute),
@@ -509,6 +672,7 @@ func TestBrackets(t *testing.T) {
 				ResourceInfo: view.K8SResourceInfo{
 					PodName:         "vigoda-pod",
 					PodStatus:       "Running",
+					PodReady:        true,
 					PodCreationTime: ts,
 				},
 				LastDeployTime: ts,
@@ -561,6 +725,7 @@ func TestBuildHistory(t *testing.T) {
 				ResourceInfo: view.K8SResourceInfo{
 					PodName:            "vigoda-pod",
 					PodStatus:          "Running",
+					PodReady:           true,
 					PodUpdateStartTime: ts,
 					PodCreationTime:    ts.Add(-time.Minute),
 				},
@@ -594,6 +759,21 @@ func TestDockerComposeUpExpanded(t *testing.T) {
 	rtf.run("docker-compose up expanded", 80, 20, v, vs)
 }
 
+func TestBuildingStatus(t *testing.T) {
+	now := time.Now()
+	v := view.View{
+		Resources: []view.Resource{
+			{Name: "fe", CurrentBuild: model.BuildRecord{StartTime: now}},
+			{Name: "be"},
+			{Name: "db"},
+		},
+	}
+	assert.Equal(t, "", buildingStatus(v))
+
+	v.Resources[2].CurrentBuild = model.BuildRecord{StartTime: now}
+	assert.Equal(t, "Building 2: fe, db", buildingStatus(v))
+}
+
 func TestStatusBarDCRebuild(t *testing.T) {
 	rtf := newRendererTestFixture(t)
 
@@ -796,6 +976,7 @@ func TestRenderTabView(t *testing.T) {
 					PodCreationTime: now,
 					PodLog:          model.NewLog("serving on 8080"),
 					PodStatus:       "Running",
+					PodReady:        true,
 				},
 				LastDeployTime: now,
 			},
@@ -818,6 +999,258 @@ type rendererTestFixture struct {
 	i rty.InteractiveTester
 }
 
+func TestRenderLogSearch(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:         "vigoda",
+				ResourceInfo: view.K8SResourceInfo{},
+				CombinedLog: model.NewLog("starting up\n" +
+					"panic: runtime error\n" +
+					"goroutine 1 [running]\n" +
+					"restarting\n" +
+					"PANIC again\n"),
+			},
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseNo)
+	vs.TiltLogState = view.TiltLogHalfScreen
+	vs.LogSearch = view.LogSearchState{Typing: true, Query: "pani"}
+	rtf.run("log search typing", 70, 20, v, vs)
+
+	vs.LogSearch = view.LogSearchState{Query: "panic", Match: 1}
+	rtf.run("log search matches", 70, 20, v, vs)
+}
+
+func TestRenderLogNoWrap(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	v := view.View{
+		Log: model.NewLog("short line\n" +
+			"goroutine 1 [running]: main.main() /go/src/github.com/windmilleng/servantes/fortune/main.go:42 +0x1a2\n" +
+			"{\"level\":\"info\",\"msg\":\"listening\",\"port\":8080,\"host\":\"0.0.0.0\",\"pid\":1234}\n"),
+		Resources: []view.Resource{
+			{
+				Name:         "vigoda",
+				ResourceInfo: view.K8SResourceInfo{},
+			},
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseNo)
+	vs.TiltLogState = view.TiltLogHalfScreen
+	vs.LogNoWrap = true
+	rtf.run("log not wrapped", 70, 20, v, vs)
+
+	vs.LogHScroll = 30
+	rtf.run("log scrolled right", 70, 20, v, vs)
+}
+
+func TestRenderLogPaused(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	v := view.View{
+		Log: model.NewLog("first line\nsecond line\nthird line\n"),
+		Resources: []view.Resource{
+			{
+				Name:         "vigoda",
+				ResourceInfo: view.K8SResourceInfo{},
+			},
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseNo)
+	vs.TiltLogState = view.TiltLogHalfScreen
+	vs.LogPause = view.LogPauseState{Paused: true, Text: "first line\n", Offset: len("first line\n")}
+	rtf.run("log paused", 70, 20, v, vs)
+}
+
+func TestRenderBuildLogPaging(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	now := clockForTest()
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:         "vigoda",
+				ResourceInfo: view.K8SResourceInfo{},
+				BuildHistory: []model.BuildRecord{
+					{StartTime: now.Add(-time.Minute), FinishTime: now, Log: model.NewLog("Step 1/2\nok\n")},
+					{
+						StartTime:  now.Add(-10 * time.Minute),
+						FinishTime: now.Add(-9 * time.Minute),
+						Error:      fmt.Errorf("compile error"),
+						Log:        model.NewLog("Step 1/2\nmain.go:12: undefined: foo\n"),
+					},
+				},
+			},
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseYes)
+	vs.TiltLogState = view.TiltLogHalfScreen
+	vs.TabState = view.TabBuildLog
+	vs.BuildLogStartTime = now.Add(-10 * time.Minute)
+	rtf.run("previous build log", 80, 20, v, vs)
+}
+
+func TestRenderAlertPane(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	v := alertsTestView()
+	vs := fakeViewState(len(v.Resources), view.CollapseNo)
+	vs.ShowAlertPane = true
+	rtf.run("alert pane", 80, 20, v, vs)
+
+	rtf.run("alert pane with no alerts", 80, 20, view.View{}, vs)
+}
+
+func TestRenderResourceDetail(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	now := clockForTest()
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:      "frontend",
+				Endpoints: []string{"http://localhost:8000"},
+				BuildHistory: []model.BuildRecord{
+					{
+						StartTime:  now.Add(-5 * time.Minute),
+						FinishTime: now.Add(-5*time.Minute + 3*time.Second),
+						Edits:      []string{"/src/main.go", "/src/a.go", "/src/b.go", "/src/c.go"},
+						Error:      fmt.Errorf("compile error\nmain.go:12: undefined: foo"),
+					},
+					{
+						StartTime:  now.Add(-10 * time.Minute),
+						FinishTime: now.Add(-10*time.Minute + 1200*time.Millisecond),
+						Reason:     model.BuildReasonFlagInit,
+					},
+				},
+				ResourceInfo: view.K8SResourceInfo{
+					PodName:         "frontend-1234",
+					PodNamespace:    "default",
+					PodCreationTime: now.Add(-10 * time.Minute),
+					PodStatus:       "Running",
+					PodReady:        true,
+					PodRestarts:     2,
+					PodCPU:          "250m",
+					PodMemory:       "64Mi",
+					Events: []view.K8sEvent{
+						{
+							Time:    now.Add(-2 * time.Minute),
+							Object:  "pod/frontend-1234",
+							Type:    "Warning",
+							Reason:  "BackOff",
+							Message: "Back-off restarting failed container",
+							Count:   5,
+						},
+						{
+							Time:    now.Add(-10 * time.Minute),
+							Object:  "pod/frontend-1234",
+							Type:    "Normal",
+							Reason:  "Scheduled",
+							Message: "Successfully assigned default/frontend-1234 to node-1",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseYes)
+	vs.ResourceDetail = "frontend"
+	rtf.run("resource detail", 80, 24, v, vs)
+
+	vs.ResourceDetailAllEdits = true
+	rtf.run("resource detail with every changed file", 80, 24, v, vs)
+	vs.ResourceDetailAllEdits = false
+
+	vs.ResourceDetail = "backend"
+	rtf.run("resource detail for a missing resource", 80, 24, v, vs)
+}
+
+func TestRenderUpdateTypes(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	detailVS := fakeViewState(1, view.CollapseYes)
+	detailVS.ResourceDetail = "frontend"
+
+	// The resource list measures ages with the real clock, and the
+	// resource detail with the test clock.
+	for _, tc := range []struct {
+		name string
+		now  time.Time
+		vs   view.ViewState
+	}{
+		{"update types", time.Now(), fakeViewState(1, view.CollapseNo)},
+		{"update types in resource detail", clockForTest(), detailVS},
+	} {
+		v := view.View{
+			Resources: []view.Resource{
+				{
+					Name: "frontend",
+					BuildHistory: []model.BuildRecord{
+						{
+							StartTime:  tc.now.Add(-1 * time.Minute),
+							FinishTime: tc.now.Add(-1*time.Minute + 800*time.Millisecond),
+							Edits:      []string{"/src/main.go"},
+							UpdateType: model.UpdateTypeLiveUpdate,
+						},
+						{
+							StartTime:      tc.now.Add(-5 * time.Minute),
+							FinishTime:     tc.now.Add(-5*time.Minute + 20*time.Second),
+							Edits:          []string{"/src/package.json"},
+							UpdateType:     model.UpdateTypeImageBuild,
+							FallbackReason: "detected change to fall_back_on file 'package.json'",
+						},
+					},
+					LastDeployTime: tc.now.Add(-1 * time.Minute),
+					ResourceInfo:   view.K8SResourceInfo{PodStatus: "Running", PodReady: true},
+				},
+			},
+		}
+		rtf.run(tc.name, 80, 20, v, tc.vs)
+	}
+}
+
+func TestRenderResourceFilterAndGroups(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	ts := time.Now().Add(-5 * time.Minute)
+	v := view.View{
+		Resources: []view.Resource{
+			{
+				Name:         "vigoda",
+				ResourceInfo: view.K8SResourceInfo{},
+				BuildHistory: []model.BuildRecord{{
+					StartTime:  ts.Add(-time.Second),
+					FinishTime: ts,
+					Error:      fmt.Errorf("oh no"),
+				}},
+			},
+			{Name: "snack", ResourceInfo: view.K8SResourceInfo{}},
+			{Name: "db", ResourceInfo: view.NewDCResourceInfo("", dockercompose.StatusUp, "", model.Log{}, ts)},
+		},
+	}
+
+	vs := fakeViewState(3, view.CollapseYes)
+	vs.ResourceFilter = view.ResourceFilterState{Status: view.StatusFilterErrors}
+	rtf.run("resources filtered to errors", 70, 20, v, vs)
+
+	vs = fakeViewState(3, view.CollapseYes)
+	vs.ResourceFilter = view.ResourceFilterState{Typing: true, Name: "sn"}
+	rtf.run("resources filtered by name", 70, 20, v, vs)
+
+	vs = fakeViewState(3, view.CollapseYes)
+	vs.GroupResources = true
+	vs.CollapsedGroups = map[string]bool{"Docker Compose": true}
+	rtf.run("resources grouped", 70, 20, v, vs)
+}
+
 func newRendererTestFixture(t rty.ErrorReporter) rendererTestFixture {
 	return rendererTestFixture{
 		i: rty.NewInteractiveTester(t, screen),
@@ -837,7 +1270,7 @@ func (rtf rendererTestFixture) run(name string, w int, h int, v view.View, vs vi
 
 	}
 
-	r := NewRenderer(clockForTest)
+	r := NewRenderer(clockForTest, ThemeDefault)
 	r.rty = rty.NewRTY(tcell.NewSimulationScreen(""))
 	c := r.layout(v, vs)
 	rtf.i.Run(name, w, h, c)
@@ -0,0 +1,193 @@
+package hud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/rty"
+)
+
+// One row of the resource list: a resource, or (when resources are grouped)
+// the header of a group.
+type resourceRow struct {
+	// An index into view.Resources, or -1 for a group header.
+	index int
+
+	group string
+}
+
+func (r resourceRow) isGroupHeader() bool {
+	return r.index < 0
+}
+
+// The element name for the row, for the resource list's ElementScroller.
+func (r resourceRow) name(v view.View) string {
+	if r.isGroupHeader() {
+		return "group:" + r.group
+	}
+	return v.Resources[r.index].Name.String()
+}
+
+// The rows of the resource list, after filtering and grouping.
+func resourceRows(v view.View, vs view.ViewState) []resourceRow {
+	var rows []resourceRow
+	if !vs.GroupResources {
+		for i, res := range v.Resources {
+			if resourceMatchesFilter(v, res, vs.ResourceFilter) {
+				rows = append(rows, resourceRow{index: i})
+			}
+		}
+		return rows
+	}
+
+	var groups []string
+	byGroup := make(map[string][]int)
+	for i, res := range v.Resources {
+		if !resourceMatchesFilter(v, res, vs.ResourceFilter) {
+			continue
+		}
+		g := resourceGroup(res)
+		if _, ok := byGroup[g]; !ok {
+			groups = append(groups, g)
+		}
+		byGroup[g] = append(byGroup[g], i)
+	}
+
+	for _, g := range groups {
+		rows = append(rows, resourceRow{index: -1, group: g})
+		if vs.CollapsedGroups[g] {
+			continue
+		}
+		for _, i := range byGroup[g] {
+			rows = append(rows, resourceRow{index: i, group: g})
+		}
+	}
+	return rows
+}
+
+// Resources are grouped by their first label, or if they have none,
+// by what kind of thing they deploy.
+func resourceGroup(res view.Resource) string {
+	switch {
+	case res.IsTiltfile:
+		return "Tiltfile"
+	case len(res.Labels) > 0:
+		return res.Labels[0]
+	case res.IsK8S(), res.IsYAML():
+		return "Kubernetes"
+	case res.IsDC():
+		return "Docker Compose"
+	case res.IsLocal():
+		return "Local"
+	default:
+		return "Other"
+	}
+}
+
+func resourceMatchesFilter(v view.View, res view.Resource, filter view.ResourceFilterState) bool {
+	if label, ok := filter.Label(); ok {
+		// While the user is still typing "label:", show everything.
+		if label != "" && !resourceHasLabel(res, label) {
+			return false
+		}
+	} else if filter.Name != "" && !strings.Contains(strings.ToLower(res.Name.String()), strings.ToLower(filter.Name)) {
+		return false
+	}
+
+	switch filter.Status {
+	case view.StatusFilterErrors:
+		return isInError(res, triggerMode(v, res))
+	case view.StatusFilterBuilding:
+		return !res.CurrentBuild.Empty()
+	}
+	return true
+}
+
+func resourceHasLabel(res view.Resource, label string) bool {
+	for _, l := range res.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// The selected row, if there is one.
+func selectedRow(v view.View, vs view.ViewState) (resourceRow, bool) {
+	rows := resourceRows(v, vs)
+	i := vs.SelectedIndex
+	if i < 0 || i >= len(rows) {
+		return resourceRow{}, false
+	}
+	return rows[i], true
+}
+
+// A one-line summary of a group, e.g. "▶ Kubernetes (12 resources, 2 errors)".
+func (r *Renderer) renderGroupHeader(v view.View, vs view.ViewState, group string, selected bool) rty.Component {
+	count, errors := 0, 0
+	for _, res := range v.Resources {
+		if resourceGroup(res) != group || !resourceMatchesFilter(v, res, vs.ResourceFilter) {
+			continue
+		}
+		count++
+		if isInError(res, triggerMode(v, res)) {
+			errors++
+		}
+	}
+
+	p := "▽"
+	if vs.CollapsedGroups[group] {
+		p = "▷"
+	}
+	if selected {
+		p = "▼"
+		if vs.CollapsedGroups[group] {
+			p = "▶"
+		}
+	}
+
+	sb := rty.NewStringBuilder()
+	sb.Text(p).Text(" ").Fg(r.theme.LightText).Text(strings.ToUpper(group)).Fg(tcell.ColorDefault)
+	sb.Fg(r.theme.LightText).Textf(" (%s", pluralize(count, "resource"))
+	if errors > 0 {
+		sb.Text(", ").Fg(r.theme.Bad).Text(pluralize(errors, "error")).Fg(r.theme.LightText)
+	}
+	sb.Text(")").Fg(tcell.ColorDefault)
+	return rty.OneLine(sb.Build())
+}
+
+// Describes the filter, for the status bar.
+func filterStatus(v view.View, vs view.ViewState) string {
+	filter := vs.ResourceFilter
+	if !filter.Active() && !filter.Typing {
+		return ""
+	}
+
+	var parts []string
+	if label, ok := filter.Label(); ok {
+		parts = append(parts, fmt.Sprintf("label = %q", label))
+	} else if filter.Name != "" || filter.Typing {
+		parts = append(parts, fmt.Sprintf("name ~ %q", filter.Name))
+	}
+	if filter.Status != view.StatusFilterAll {
+		parts = append(parts, fmt.Sprintf("only %s", filter.Status))
+	}
+
+	shown := 0
+	for _, res := range v.Resources {
+		if resourceMatchesFilter(v, res, filter) {
+			shown++
+		}
+	}
+	return fmt.Sprintf("Filter: %s (%d of %d shown)", strings.Join(parts, ", "), shown, len(v.Resources))
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
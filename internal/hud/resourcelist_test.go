@@ -0,0 +1,113 @@
+package hud
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func resourceListTestView() view.View {
+	return view.View{
+		Resources: []view.Resource{
+			{Name: view.TiltfileResourceName, IsTiltfile: true},
+			{Name: "frontend", ResourceInfo: view.K8SResourceInfo{}},
+			{
+				Name:         "backend",
+				ResourceInfo: view.K8SResourceInfo{},
+				BuildHistory: []model.BuildRecord{{Error: errors.New("oh no")}},
+			},
+			{Name: "db", ResourceInfo: view.NewDCResourceInfo("", "", "", model.Log{}, time.Time{})},
+			{
+				Name:         "frontend-tests",
+				ResourceInfo: view.LocalResourceInfo{},
+				CurrentBuild: model.BuildRecord{StartTime: time.Now()},
+			},
+		},
+	}
+}
+
+func rowNames(v view.View, rows []resourceRow) []string {
+	var names []string
+	for _, row := range rows {
+		names = append(names, row.name(v))
+	}
+	return names
+}
+
+func TestResourceRowsFilter(t *testing.T) {
+	v := resourceListTestView()
+	vs := fakeViewState(len(v.Resources), view.CollapseNo)
+
+	assert.Equal(t, []string{"(Tiltfile)", "frontend", "backend", "db", "frontend-tests"}, rowNames(v, resourceRows(v, vs)))
+
+	vs.ResourceFilter = view.ResourceFilterState{Name: "FRONT"}
+	assert.Equal(t, []string{"frontend", "frontend-tests"}, rowNames(v, resourceRows(v, vs)))
+
+	vs.ResourceFilter = view.ResourceFilterState{Status: view.StatusFilterErrors}
+	assert.Equal(t, []string{"backend"}, rowNames(v, resourceRows(v, vs)))
+
+	vs.ResourceFilter = view.ResourceFilterState{Name: "front", Status: view.StatusFilterBuilding}
+	assert.Equal(t, []string{"frontend-tests"}, rowNames(v, resourceRows(v, vs)))
+	assert.Equal(t, `Filter: name ~ "front", only building (1 of 5 shown)`, filterStatus(v, vs))
+}
+
+func TestResourceRowsGroups(t *testing.T) {
+	v := resourceListTestView()
+	vs := fakeViewState(len(v.Resources), view.CollapseNo)
+	vs.GroupResources = true
+
+	assert.Equal(t, []string{
+		"group:Tiltfile", "(Tiltfile)",
+		"group:Kubernetes", "frontend", "backend",
+		"group:Docker Compose", "db",
+		"group:Local", "frontend-tests",
+	}, rowNames(v, resourceRows(v, vs)))
+
+	vs.CollapsedGroups = map[string]bool{"Kubernetes": true}
+	assert.Equal(t, []string{
+		"group:Tiltfile", "(Tiltfile)",
+		"group:Kubernetes",
+		"group:Docker Compose", "db",
+		"group:Local", "frontend-tests",
+	}, rowNames(v, resourceRows(v, vs)))
+
+	// Selecting a row maps back to the resource.
+	vs.SelectedIndex = 4
+	i, res := selectedResource(v, vs)
+	assert.Equal(t, 3, i)
+	assert.Equal(t, model.ManifestName("db"), res.Name)
+
+	vs.SelectedIndex = 2
+	i, res = selectedResource(v, vs)
+	assert.Equal(t, -1, i)
+	assert.Equal(t, model.ManifestName(""), res.Name)
+}
+
+func TestResourceRowsLabels(t *testing.T) {
+	v := resourceListTestView()
+	v.Resources[1].Labels = []string{"web"}
+	v.Resources[2].Labels = []string{"api", "web"}
+	v.Resources[4].Labels = []string{"web"}
+	vs := fakeViewState(len(v.Resources), view.CollapseNo)
+
+	vs.ResourceFilter = view.ResourceFilterState{Name: "label:web"}
+	assert.Equal(t, []string{"frontend", "backend", "frontend-tests"}, rowNames(v, resourceRows(v, vs)))
+	assert.Equal(t, `Filter: label = "web" (3 of 5 shown)`, filterStatus(v, vs))
+
+	vs.ResourceFilter = view.ResourceFilterState{Name: "label:api"}
+	assert.Equal(t, []string{"backend"}, rowNames(v, resourceRows(v, vs)))
+
+	vs.ResourceFilter = view.ResourceFilterState{}
+	vs.GroupResources = true
+	assert.Equal(t, []string{
+		"group:Tiltfile", "(Tiltfile)",
+		"group:web", "frontend", "frontend-tests",
+		"group:api", "backend",
+		"group:Docker Compose", "db",
+	}, rowNames(v, resourceRows(v, vs)))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/windmilleng/tilt/internal/hud/view"
 	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/rty"
+	"github.com/windmilleng/tilt/internal/store"
 )
 
 // These widths are determined experimentally, to see what shows up in a typical UX.
@@ -24,17 +25,21 @@ type ResourceView struct {
 	triggerMode model.TriggerMode
 	selected    bool
 
-	clock func() time.Time
+	clock     func() time.Time
+	theme     Theme
+	fileLinks fileLinks
 }
 
 func NewResourceView(res view.Resource, rv view.ResourceViewState, triggerMode model.TriggerMode,
-	selected bool, clock func() time.Time) *ResourceView {
+	selected bool, clock func() time.Time, theme Theme, fileLinks fileLinks) *ResourceView {
 	return &ResourceView{
 		res:         res,
 		rv:          rv,
 		triggerMode: triggerMode,
 		selected:    selected,
 		clock:       clock,
+		theme:       theme,
+		fileLinks:   fileLinks,
 	}
 }
 
@@ -53,54 +58,70 @@ func (v *ResourceView) resourceTitle() rty.Component {
 	l := rty.NewConcatLayout(rty.DirHor)
 	l.Add(v.titleTextName())
 	l.Add(rty.TextString(" "))
-	l.AddDynamic(rty.Fg(rty.NewFillerString('╌'), cLightText))
+	l.AddDynamic(rty.Fg(rty.NewFillerString('╌'), v.theme.LightText))
 	l.Add(rty.TextString(" "))
 
 	if tt := v.titleText(); tt != nil {
 		l.Add(tt)
-		l.Add(middotText())
+		l.Add(middotText(v.theme))
 	}
 
 	l.Add(v.titleTextBuild())
-	l.Add(middotText())
+	l.Add(middotText(v.theme))
 	l.Add(v.titleTextDeploy())
 	return rty.OneLine(l)
 }
 
-func statusColor(res view.Resource, triggerMode model.TriggerMode) tcell.Color {
+// How a resource is doing, which the theme picks a color for.
+type resourceStatus int
+
+const (
+	statusNone resourceStatus = iota
+	statusPending
+	statusGood
+	statusBad
+)
+
+func statusOf(res view.Resource, triggerMode model.TriggerMode) resourceStatus {
 	if res.IsTiltfile {
 		if !res.CurrentBuild.Empty() {
-			return cPending
+			return statusPending
 		} else if res.CrashLog.Empty() {
-			return cGood
+			return statusGood
 		} else {
-			return cBad
+			return statusBad
 		}
 	}
 
 	if !res.CurrentBuild.Empty() && !res.CurrentBuild.Reason.IsCrashOnly() {
-		return cPending
+		return statusPending
 	} else if !res.PendingBuildSince.IsZero() && !res.PendingBuildReason.IsCrashOnly() {
 		if triggerMode == model.TriggerAuto {
-			return cPending
+			return statusPending
 		} else {
-			return cLightText
+			return statusNone
 		}
 	} else if isCrashing(res) {
-		return cBad
+		return statusBad
+	} else if res.LastBuild().Canceled {
+		return statusNone
 	} else if res.LastBuild().Error != nil {
-		return cBad
+		return statusBad
+	} else if res.IsK8S() && len(res.K8SInfo().PodAlerts) > 0 {
+		return statusBad
 	} else if res.IsYAML() && !res.LastDeployTime.IsZero() {
-		return cGood
+		return statusGood
 	} else if !res.LastBuild().FinishTime.IsZero() && res.ResourceInfo.Status() == "" {
-		return cPending // pod status hasn't shown up yet
+		return statusPending // pod status hasn't shown up yet
+	} else if res.IsK8S() && res.K8SInfo().PodStatus == "Running" && !res.K8SInfo().PodReady {
+		return statusPending // pod is running, but not ready to serve yet
 	} else {
 		if res.ResourceInfo != nil {
-			if statusColor, ok := statusColors[res.ResourceInfo.Status()]; ok {
-				return statusColor
+			if status, ok := runtimeStatuses[res.ResourceInfo.Status()]; ok {
+				return status
 			}
 		}
-		return cLightText
+		return statusNone
 	}
 }
 
@@ -116,12 +137,12 @@ func (v *ResourceView) titleTextName() rty.Component {
 		p = "▶"
 	}
 
-	color := statusColor(v.res, v.triggerMode)
+	status := statusOf(v.res, v.triggerMode)
 	sb.Text(p)
-	sb.Fg(color).Textf(" ● ")
+	sb.Fg(v.theme.statusColor(status)).Textf(" ● ")
 
 	name := v.res.Name.String()
-	if color == cPending {
+	if status == statusPending {
 		name = fmt.Sprintf("%s %s", v.res.Name, v.spinner())
 	}
 	if len(warnings(v.res)) > 0 {
@@ -137,6 +158,8 @@ func (v *ResourceView) titleText() rty.Component {
 		return titleTextDC(i)
 	case view.K8SResourceInfo:
 		return titleTextK8s(i)
+	case view.LocalResourceInfo:
+		return titleTextLocal(i)
 	default:
 		return nil
 	}
@@ -160,8 +183,19 @@ func titleTextDC(dcInfo view.DCResourceInfo) rty.Component {
 	return sb.Build()
 }
 
+func titleTextLocal(localInfo view.LocalResourceInfo) rty.Component {
+	if !localInfo.HasServeCmd {
+		return nil
+	}
+	status := localInfo.Status()
+	if status == "" {
+		status = "Pending"
+	}
+	return rty.TextString(status)
+}
+
 func (v *ResourceView) titleTextBuild() rty.Component {
-	return buildStatusCell(makeBuildStatus(v.res, v.triggerMode))
+	return buildStatusCell(v.theme, makeBuildStatus(v.res, v.triggerMode))
 }
 
 func (v *ResourceView) titleTextDeploy() rty.Component {
@@ -189,6 +223,8 @@ func (v *ResourceView) resourceExpanded() rty.Component {
 		return v.resourceExpandedK8s()
 	case view.YAMLResourceInfo:
 		return v.resourceExpandedYAML()
+	case view.LocalResourceInfo:
+		return v.resourceExpandedLocal()
 	default:
 		return rty.EmptyLayout
 	}
@@ -204,7 +240,7 @@ func (v *ResourceView) resourceExpandedYAML() rty.Component {
 	l := rty.NewConcatLayout(rty.DirHor)
 	l.Add(rty.TextString(strings.Repeat(" ", 2)))
 	rhs := rty.NewConcatLayout(rty.DirVert)
-	rhs.Add(rty.NewStringBuilder().Fg(cLightText).Text("K8s objects not included in the resources above:").Build())
+	rhs.Add(rty.NewStringBuilder().Fg(v.theme.LightText).Text("K8s objects not included in the resources above:").Build())
 	rhs.Add(rty.TextString(strings.Join(yi.K8sResources, "\n")))
 	l.AddDynamic(rhs)
 	return l
@@ -222,21 +258,44 @@ func (v *ResourceView) resourceExpandedDC() rty.Component {
 	if !st.IsZero() {
 		if len(v.res.Endpoints) > 0 {
 			v.appendEndpoints(l)
-			l.Add(middotText())
+			l.Add(middotText(v.theme))
 		}
-		l.Add(resourceTextAge(st))
+		l.Add(v.resourceTextAge(st))
 	}
 
 	return rty.OneLine(l)
 }
 
+func (v *ResourceView) resourceExpandedLocal() rty.Component {
+	localInfo := v.res.LocalInfo()
+	if localInfo.PID == 0 {
+		return rty.EmptyLayout
+	}
+
+	l := rty.NewConcatLayout(rty.DirHor)
+	sb := rty.NewStringBuilder()
+	sb.Fg(v.theme.LightText).Text("PID: ")
+	sb.Fg(tcell.ColorDefault).Textf("%d", localInfo.PID)
+	l.Add(sb.Build())
+	l.Add(rty.TextString(" "))
+	l.AddDynamic(rty.NewFillerString(' '))
+
+	if len(v.res.Endpoints) > 0 {
+		v.appendEndpoints(l)
+		l.Add(middotText(v.theme))
+	}
+	l.Add(v.resourceTextAge(localInfo.StartTime))
+
+	return rty.OneLine(l)
+}
+
 func (v *ResourceView) resourceTextDCContainer(dcInfo view.DCResourceInfo) rty.Component {
 	if dcInfo.ContainerID.String() == "" {
 		return rty.EmptyLayout
 	}
 
 	sb := rty.NewStringBuilder()
-	sb.Fg(cLightText).Text("Container ID: ")
+	sb.Fg(v.theme.LightText).Text("Container ID: ")
 	sb.Fg(tcell.ColorDefault).Text(dcInfo.ContainerID.ShortStr())
 	return sb.Build()
 }
@@ -258,46 +317,60 @@ func (v *ResourceView) resourceExpandedK8s() rty.Component {
 	}
 
 	l := rty.NewConcatLayout(rty.DirHor)
-	l.Add(resourceTextPodName(k8sInfo))
+	l.Add(v.resourceTextPodName(k8sInfo))
 	l.Add(rty.TextString(" "))
 	l.AddDynamic(rty.NewFillerString(' '))
 	l.Add(rty.TextString(" "))
 
 	if k8sInfo.PodRestarts > 0 {
-		l.Add(resourceTextPodRestarts(k8sInfo))
-		l.Add(middotText())
+		l.Add(v.resourceTextPodRestarts(k8sInfo))
+		l.Add(middotText(v.theme))
+	}
+
+	if k8sInfo.PodCPU != "" {
+		l.Add(v.resourceTextPodUsage(k8sInfo))
+		l.Add(middotText(v.theme))
 	}
 
 	if len(v.res.Endpoints) > 0 && !v.endpointsNeedSecondLine() {
 		v.appendEndpoints(l)
-		l.Add(middotText())
+		l.Add(middotText(v.theme))
 	}
 
-	l.Add(resourceTextAge(k8sInfo.PodCreationTime))
+	l.Add(v.resourceTextAge(k8sInfo.PodCreationTime))
 	return rty.OneLine(l)
 }
 
-func resourceTextPodName(k8sInfo view.K8SResourceInfo) rty.Component {
+func (v *ResourceView) resourceTextPodName(k8sInfo view.K8SResourceInfo) rty.Component {
 	sb := rty.NewStringBuilder()
-	sb.Fg(cLightText).Text("K8S POD: ")
+	sb.Fg(v.theme.LightText).Text("K8S POD: ")
 	sb.Fg(tcell.ColorDefault).Text(k8sInfo.PodName)
 	return sb.Build()
 }
 
-func resourceTextPodRestarts(k8sInfo view.K8SResourceInfo) rty.Component {
+func (v *ResourceView) resourceTextPodRestarts(k8sInfo view.K8SResourceInfo) rty.Component {
 	s := "restarts"
 	if k8sInfo.PodRestarts == 1 {
 		s = "restart"
 	}
 	return rty.NewStringBuilder().
-		Fg(cPending).
+		Fg(v.theme.Pending).
 		Textf("%d %s", k8sInfo.PodRestarts, s).
 		Build()
 }
 
-func resourceTextAge(t time.Time) rty.Component {
+func (v *ResourceView) resourceTextPodUsage(k8sInfo view.K8SResourceInfo) rty.Component {
 	sb := rty.NewStringBuilder()
-	sb.Fg(cLightText).Text("AGE ")
+	sb.Fg(v.theme.LightText).Text("CPU ")
+	sb.Fg(tcell.ColorDefault).Text(k8sInfo.PodCPU)
+	sb.Fg(v.theme.LightText).Text(" MEM ")
+	sb.Fg(tcell.ColorDefault).Text(k8sInfo.PodMemory)
+	return sb.Build()
+}
+
+func (v *ResourceView) resourceTextAge(t time.Time) rty.Component {
+	sb := rty.NewStringBuilder()
+	sb.Fg(v.theme.LightText).Text("AGE ")
 	sb.Fg(tcell.ColorDefault).Text(formatDeployAge(time.Since(t)))
 	return rty.NewMinLengthLayout(DeployCellMinWidth, rty.DirHor).
 		SetAlign(rty.AlignEnd).
@@ -307,9 +380,15 @@ func resourceTextAge(t time.Time) rty.Component {
 func (v *ResourceView) appendEndpoints(l *rty.ConcatLayout) {
 	for i, endpoint := range v.res.Endpoints {
 		if i != 0 {
-			l.Add(middotText())
+			l.Add(middotText(v.theme))
 		}
-		l.Add(rty.TextString(endpoint))
+		l.Add(endpointText(endpoint))
+	}
+
+	if v.res.IsK8S() && v.res.K8SInfo().PortForwardStatus == string(store.PortForwardReconnecting) {
+		sb := rty.NewStringBuilder()
+		sb.Fg(v.theme.Pending).Text(" (reconnecting…)")
+		l.Add(sb.Build())
 	}
 }
 
@@ -319,15 +398,15 @@ func (v *ResourceView) resourceExpandedEndpoints() rty.Component {
 	}
 
 	l := rty.NewConcatLayout(rty.DirHor)
-	l.Add(resourceTextURLPrefix())
+	l.Add(v.resourceTextURLPrefix())
 	v.appendEndpoints(l)
 
 	return l
 }
 
-func resourceTextURLPrefix() rty.Component {
+func (v *ResourceView) resourceTextURLPrefix() rty.Component {
 	sb := rty.NewStringBuilder()
-	sb.Fg(cLightText).Text("URL: ")
+	sb.Fg(v.theme.LightText).Text("URL: ")
 	return sb.Build()
 }
 
@@ -341,7 +420,7 @@ func (v *ResourceView) resourceExpandedHistory() rty.Component {
 	}
 
 	l := rty.NewConcatLayout(rty.DirHor)
-	l.Add(rty.NewStringBuilder().Fg(cLightText).Text("HISTORY: ").Build())
+	l.Add(rty.NewStringBuilder().Fg(v.theme.LightText).Text("HISTORY: ").Build())
 
 	rows := rty.NewConcatLayout(rty.DirVert)
 	rowCount := 0
@@ -352,7 +431,7 @@ func (v *ResourceView) resourceExpandedHistory() rty.Component {
 			duration: v.res.CurrentBuild.Duration(),
 			status:   "Building",
 			muted:    true,
-		}))
+		}, v.theme))
 		rowCount++
 	}
 	for _, bStatus := range v.res.BuildHistory {
@@ -362,17 +441,20 @@ func (v *ResourceView) resourceExpandedHistory() rty.Component {
 		}
 
 		status := "OK"
-		if bStatus.Error != nil {
+		if bStatus.Canceled {
+			status = "Canceled"
+		} else if bStatus.Error != nil {
 			status = "Error"
 		}
 
 		rows.Add(NewEditStatusLine(buildStatus{
 			edits:      bStatus.Edits,
 			reason:     bStatus.Reason,
+			updateType: bStatus.UpdateType,
 			duration:   bStatus.Duration(),
 			status:     status,
 			deployTime: bStatus.FinishTime,
-		}))
+		}, v.theme))
 		rowCount++
 	}
 	l.AddDynamic(rows)
@@ -382,6 +464,9 @@ func (v *ResourceView) resourceExpandedHistory() rty.Component {
 func (v *ResourceView) resourceExpandedError() rty.Component {
 	errPane, ok := v.resourceExpandedBuildError()
 	isWarnings := false
+	if !ok {
+		errPane, ok = v.resourceExpandedPodAlerts()
+	}
 	if !ok {
 		errPane, ok = v.resourceExpandedRuntimeError()
 	}
@@ -398,9 +483,9 @@ func (v *ResourceView) resourceExpandedError() rty.Component {
 
 	l := rty.NewConcatLayout(rty.DirVert)
 	if isWarnings {
-		l.Add(rty.NewStringBuilder().Fg(cLightText).Text("WARNINGS:").Build())
+		l.Add(rty.NewStringBuilder().Fg(v.theme.LightText).Text("WARNINGS:").Build())
 	} else {
-		l.Add(rty.NewStringBuilder().Fg(cLightText).Text("ERROR:").Build())
+		l.Add(rty.NewStringBuilder().Fg(v.theme.LightText).Text("ERROR:").Build())
 	}
 
 	indentPane := rty.NewConcatLayout(rty.DirHor)
@@ -414,6 +499,18 @@ func (v *ResourceView) resourceExpandedError() rty.Component {
 	return l
 }
 
+func (v *ResourceView) resourceExpandedPodAlerts() (rty.Component, bool) {
+	pane := rty.NewConcatLayout(rty.DirVert)
+	ok := false
+	if v.res.IsK8S() {
+		for _, line := range abbreviateLog(strings.Join(v.res.K8SInfo().PodAlerts, "\n")) {
+			pane.Add(rty.TextString(line))
+			ok = true
+		}
+	}
+	return pane, ok
+}
+
 func (v *ResourceView) resourceExpandedRuntimeError() (rty.Component, bool) {
 	pane := rty.NewConcatLayout(rty.DirVert)
 	ok := false
@@ -448,23 +545,30 @@ func (v *ResourceView) resourceExpandedBuildError() (rty.Component, bool) {
 	pane := rty.NewConcatLayout(rty.DirVert)
 	ok := false
 
-	if v.res.LastBuild().Error != nil {
-		abbrevLog := abbreviateLog(v.res.LastBuild().Log.Tail(abbreviatedLogLineCount).String())
-		for _, logLine := range abbrevLog {
-			pane.Add(rty.TextString(logLine))
-			ok = true
-		}
-
-		// if the build log is non-empty, it will contain the error, so we don't need to show this separately
-		if len(abbrevLog) == 0 {
-			pane.Add(rty.TextString(fmt.Sprintf("Error: %s", v.res.LastBuild().Error)))
-			ok = true
-		}
+	for _, line := range buildErrorLines(v.res) {
+		pane.Add(v.fileLinks.text(line))
+		ok = true
 	}
 
 	return pane, ok
 }
 
+// The lines of the last build's log that we show under a failed build,
+// or just the error if the log is empty.
+func buildErrorLines(res view.Resource) []string {
+	if res.LastBuild().Error == nil {
+		return nil
+	}
+
+	abbrevLog := abbreviateLog(res.LastBuild().Log.Tail(abbreviatedLogLineCount).String())
+
+	// if the build log is non-empty, it will contain the error, so we don't need to show this separately
+	if len(abbrevLog) == 0 {
+		return []string{fmt.Sprintf("Error: %s", res.LastBuild().Error)}
+	}
+	return abbrevLog
+}
+
 var spinnerChars = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
 func (v *ResourceView) spinner() string {
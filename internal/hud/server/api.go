@@ -0,0 +1,311 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/windmilleng/tilt/internal/hud/webview"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// The JSON behind /api/resources, for scripts and editor plugins.
+//
+// /api/view and /ws/view change whenever the web UI does. These types are
+// the stable alternative: we only ever add fields to them.
+type apiResourceList struct {
+	Resources []apiResource `json:"resources"`
+}
+
+type apiResource struct {
+	Name string `json:"name"`
+
+	// "tiltfile", "k8s", "docker-compose", "local", or "yaml".
+	Type string `json:"type"`
+
+	// "none" (never built), "pending", "in_progress", "ok", "error", or "canceled".
+	UpdateStatus string `json:"updateStatus"`
+
+	// "ok", "pending", or "error".
+	RuntimeStatus string `json:"runtimeStatus"`
+
+	// The status of the pod, container, or process, e.g., "Running".
+	Status string `json:"status"`
+
+	// "auto" or "manual".
+	TriggerMode   string `json:"triggerMode"`
+	Disabled      bool   `json:"disabled"`
+	UpdatesPaused bool   `json:"updatesPaused"`
+
+	Endpoints      []string   `json:"endpoints"`
+	PodName        string     `json:"podName,omitempty"`
+	PodRestarts    int        `json:"podRestarts,omitempty"`
+	LastDeployTime *time.Time `json:"lastDeployTime,omitempty"`
+
+	// Files that changed since the last build, waiting for the next one.
+	PendingChanges []string `json:"pendingChanges"`
+
+	CurrentBuild *apiBuild `json:"currentBuild,omitempty"`
+
+	// Newest first.
+	BuildHistory []apiBuild `json:"buildHistory"`
+
+	// The groups the Tiltfile put the resource in, e.g., "frontend".
+	Labels []string `json:"labels"`
+}
+
+type apiBuild struct {
+	StartTime time.Time `json:"startTime"`
+
+	// Empty while the build is running.
+	FinishTime *time.Time `json:"finishTime,omitempty"`
+
+	// Any of "init", "config", "crash", and "changed_files".
+	Reasons []string `json:"reasons"`
+
+	Edits    []string `json:"edits"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings"`
+
+	// "live update" or "image build", for builds that updated a container.
+	UpdateType string `json:"updateType,omitempty"`
+
+	// Why the build couldn't live update, if it fell back to an image build.
+	FallbackReason string `json:"fallbackReason,omitempty"`
+}
+
+type apiLog struct {
+	Log string `json:"log"`
+
+	// Pass this as ?since= on the next request to get only what's new.
+	Offset int `json:"offset"`
+}
+
+func newAPIResource(res webview.Resource) apiResource {
+	r := apiResource{
+		Name:           res.Name.String(),
+		Type:           apiResourceType(res),
+		UpdateStatus:   apiUpdateStatus(res),
+		RuntimeStatus:  string(res.RuntimeStatus),
+		TriggerMode:    "auto",
+		Disabled:       res.Disabled,
+		UpdatesPaused:  res.UpdatesPaused,
+		Endpoints:      append([]string{}, res.Endpoints...),
+		PendingChanges: append([]string{}, res.PendingBuildEdits...),
+		BuildHistory:   []apiBuild{},
+		Labels:         append([]string{}, res.Labels...),
+	}
+	if res.TriggerMode == model.TriggerManual {
+		r.TriggerMode = "manual"
+	}
+	if res.ResourceInfo != nil {
+		r.Status = res.ResourceInfo.Status()
+	}
+	if info, ok := res.ResourceInfo.(webview.K8SResourceInfo); ok {
+		r.PodName = info.PodName
+		r.PodRestarts = info.PodRestarts
+	}
+	if !res.LastDeployTime.IsZero() {
+		t := res.LastDeployTime
+		r.LastDeployTime = &t
+	}
+	if !res.CurrentBuild.Empty() {
+		b := newAPIBuild(res.CurrentBuild)
+		r.CurrentBuild = &b
+	}
+	for _, b := range res.BuildHistory {
+		if b.Empty() {
+			continue
+		}
+		r.BuildHistory = append(r.BuildHistory, newAPIBuild(b))
+	}
+	return r
+}
+
+func newAPIBuild(b model.BuildRecord) apiBuild {
+	result := apiBuild{
+		StartTime:      b.StartTime,
+		Reasons:        []string{},
+		Edits:          append([]string{}, b.Edits...),
+		Warnings:       append([]string{}, b.Warnings...),
+		UpdateType:     string(b.UpdateType),
+		FallbackReason: b.FallbackReason,
+	}
+	if !b.FinishTime.IsZero() {
+		t := b.FinishTime
+		result.FinishTime = &t
+	}
+	if b.Error != nil {
+		result.Error = b.Error.Error()
+	}
+	for _, flag := range []struct {
+		flag model.BuildReason
+		name string
+	}{
+		{model.BuildReasonFlagInit, "init"},
+		{model.BuildReasonFlagConfig, "config"},
+		{model.BuildReasonFlagCrash, "crash"},
+		{model.BuildReasonFlagChangedFiles, "changed_files"},
+	} {
+		if b.Reason.Has(flag.flag) {
+			result.Reasons = append(result.Reasons, flag.name)
+		}
+	}
+	return result
+}
+
+func apiResourceType(res webview.Resource) string {
+	if res.IsTiltfile {
+		return "tiltfile"
+	}
+	switch res.ResourceInfo.(type) {
+	case webview.DCResourceInfo:
+		return "docker-compose"
+	case webview.LocalResourceInfo:
+		return "local"
+	case webview.YAMLResourceInfo:
+		return "yaml"
+	default:
+		return "k8s"
+	}
+}
+
+func apiUpdateStatus(res webview.Resource) string {
+	switch {
+	case !res.CurrentBuild.Empty():
+		return "in_progress"
+	case !res.PendingBuildSince.IsZero():
+		return "pending"
+	case res.LastBuild().Empty():
+		return "none"
+	case res.LastBuild().Canceled:
+		return "canceled"
+	case res.LastBuild().Error != nil:
+		return "error"
+	default:
+		return "ok"
+	}
+}
+
+// Looks up the resource named in the request path, or writes a 404.
+func (s HeadsUpServer) apiResourceFromRequest(w http.ResponseWriter, req *http.Request) (webview.Resource, bool) {
+	name, err := url.PathUnescape(mux.Vars(req)["name"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing resource name: %v", err), http.StatusBadRequest)
+		return webview.Resource{}, false
+	}
+
+	state := s.store.RLockState()
+	view := webview.StateToWebView(state)
+	s.store.RUnlockState()
+
+	res, ok := view.Resource(model.ManifestName(name))
+	if !ok {
+		http.Error(w, fmt.Sprintf("no resource named %q", name), http.StatusNotFound)
+		return webview.Resource{}, false
+	}
+	return res, true
+}
+
+// Every resource in the session, in Tiltfile order, with the Tiltfile first.
+// With ?label=, only the resources with that label.
+func (s HeadsUpServer) ResourcesJSON(w http.ResponseWriter, req *http.Request) {
+	label := req.URL.Query().Get("label")
+
+	state := s.store.RLockState()
+	view := webview.StateToWebView(state)
+	s.store.RUnlockState()
+
+	list := apiResourceList{Resources: []apiResource{}}
+	for _, res := range view.Resources {
+		if label != "" && !res.HasLabel(label) {
+			continue
+		}
+		list.Resources = append(list.Resources, newAPIResource(res))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(list)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering resources: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (s HeadsUpServer) ResourceJSON(w http.ResponseWriter, req *http.Request) {
+	res, ok := s.apiResourceFromRequest(w, req)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(newAPIResource(res))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering resource: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Which log lines to send, from the ?source= (tilt, build, or runtime) and
+// ?level= (info, warn, or error, for that level and above) parameters.
+func logFilterFromRequest(req *http.Request) (model.LogFilter, error) {
+	var result model.LogFilter
+	query := req.URL.Query()
+	if v := query.Get("source"); v != "" {
+		source, err := model.ParseLogSource(v)
+		if err != nil {
+			return model.LogFilter{}, err
+		}
+		result.Source = source
+	}
+	if v := query.Get("level"); v != "" {
+		level, err := model.ParseLogLevel(v)
+		if err != nil {
+			return model.LogFilter{}, err
+		}
+		result.MinLevel = level
+	}
+	return result, nil
+}
+
+// The resource's log. With ?since=<offset>, only what it logged after the
+// offset from a previous response. If the log started over since then
+// (e.g., because the resource was recreated), returns the whole log.
+//
+// With ?source= or ?level=, only the lines that match (see logFilterFromRequest).
+// Offsets only make sense with the same filter as the response they came from.
+func (s HeadsUpServer) ResourceLogJSON(w http.ResponseWriter, req *http.Request) {
+	logFilter, err := logFilterFromRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	since := 0
+	if v := req.URL.Query().Get("since"); v != "" {
+		since, err = strconv.Atoi(v)
+		if err != nil || since < 0 {
+			http.Error(w, fmt.Sprintf("since must be a non-negative offset, got %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, ok := s.apiResourceFromRequest(w, req)
+	if !ok {
+		return
+	}
+
+	log := res.CombinedLog.Filter(logFilter)
+	if log.TotalLen() < since {
+		since = 0
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(apiLog{Log: log.Since(since), Offset: log.TotalLen()})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering log: %v", err), http.StatusInternalServerError)
+	}
+}
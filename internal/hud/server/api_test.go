@@ -0,0 +1,211 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+func TestResourcesJSON(t *testing.T) {
+	f := newTestFixture(t)
+	start := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	f.addManifest("fe", func(ms *store.ManifestState) {
+		ms.BuildHistory = []model.BuildRecord{{
+			StartTime:  start,
+			FinishTime: start.Add(time.Second),
+			Reason:     model.BuildReasonFlagInit,
+			Error:      fmt.Errorf("exit status 1"),
+		}}
+	})
+	f.addManifest("be", func(ms *store.ManifestState) {
+		ms.CurrentBuild = model.BuildRecord{
+			StartTime: start,
+			Reason:    model.BuildReasonFlagChangedFiles,
+			Edits:     []string{"main.go"},
+		}
+	})
+
+	var list struct {
+		Resources []struct {
+			Name         string
+			Type         string
+			UpdateStatus string
+			CurrentBuild *struct {
+				Reasons []string
+				Edits   []string
+			}
+			BuildHistory []struct {
+				Error string
+			}
+		}
+	}
+	f.getJSON("/api/resources", http.StatusOK, &list)
+
+	if !assert.Equal(t, 3, len(list.Resources)) {
+		return
+	}
+	assert.Equal(t, "tiltfile", list.Resources[0].Type)
+
+	fe := list.Resources[1]
+	assert.Equal(t, "fe", fe.Name)
+	assert.Equal(t, "k8s", fe.Type)
+	assert.Equal(t, "error", fe.UpdateStatus)
+	assert.Nil(t, fe.CurrentBuild)
+	if assert.Equal(t, 1, len(fe.BuildHistory)) {
+		assert.Equal(t, "exit status 1", fe.BuildHistory[0].Error)
+	}
+
+	be := list.Resources[2]
+	assert.Equal(t, "in_progress", be.UpdateStatus)
+	if assert.NotNil(t, be.CurrentBuild) {
+		assert.Equal(t, []string{"changed_files"}, be.CurrentBuild.Reasons)
+		assert.Equal(t, []string{"main.go"}, be.CurrentBuild.Edits)
+	}
+}
+
+func TestResourcesJSONLabel(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {})
+	f.addManifest("be", func(ms *store.ManifestState) {})
+
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].Manifest.Labels = []string{"web"}
+	state.ManifestTargets["be"].Manifest.Labels = []string{"api", "web"}
+	f.st.UnlockMutableState()
+
+	var list struct {
+		Resources []struct {
+			Name   string
+			Labels []string
+		}
+	}
+	f.getJSON("/api/resources?label=api", http.StatusOK, &list)
+	if assert.Equal(t, 1, len(list.Resources)) {
+		assert.Equal(t, "be", list.Resources[0].Name)
+		assert.Equal(t, []string{"api", "web"}, list.Resources[0].Labels)
+	}
+
+	f.getJSON("/api/resources?label=web", http.StatusOK, &list)
+	assert.Equal(t, 2, len(list.Resources))
+}
+
+func TestResourceJSON(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {})
+
+	var res struct {
+		Name         string
+		UpdateStatus string
+		TriggerMode  string
+	}
+	f.getJSON("/api/resources/fe", http.StatusOK, &res)
+	assert.Equal(t, "fe", res.Name)
+	assert.Equal(t, "none", res.UpdateStatus)
+	assert.Equal(t, "auto", res.TriggerMode)
+
+	f.getJSON("/api/resources/missing", http.StatusNotFound, nil)
+	f.getJSON("/api/resources/missing/logs", http.StatusNotFound, nil)
+}
+
+func TestResourceLogJSON(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {
+		ms.CombinedLog = model.NewLog("hello\nworld\n")
+	})
+
+	var log struct {
+		Log    string
+		Offset int
+	}
+	f.getJSON("/api/resources/fe/logs", http.StatusOK, &log)
+	assert.Equal(t, "hello\nworld\n", log.Log)
+	assert.Equal(t, 12, log.Offset)
+
+	f.getJSON("/api/resources/fe/logs?since=6", http.StatusOK, &log)
+	assert.Equal(t, "world\n", log.Log)
+	assert.Equal(t, 12, log.Offset)
+
+	f.getJSON("/api/resources/fe/logs?since=12", http.StatusOK, &log)
+	assert.Equal(t, "", log.Log)
+
+	// The log started over, so we get all of it.
+	f.getJSON("/api/resources/fe/logs?since=100", http.StatusOK, &log)
+	assert.Equal(t, "hello\nworld\n", log.Log)
+
+	f.getJSON("/api/resources/fe/logs?since=-1", http.StatusBadRequest, nil)
+	f.getJSON("/api/resources/fe/logs?since=abc", http.StatusBadRequest, nil)
+}
+
+func TestResourceLogJSONFilter(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {
+		ms.CombinedLog = model.AppendLog(model.NewLog("Building\nERROR: build failed\n"),
+			fakeRuntimeLogEvent("listening\nWARNING: slow\n"), false)
+	})
+
+	var log struct {
+		Log    string
+		Offset int
+	}
+	f.getJSON("/api/resources/fe/logs?level=error", http.StatusOK, &log)
+	assert.Equal(t, "ERROR: build failed\n", log.Log)
+
+	f.getJSON("/api/resources/fe/logs?level=warn", http.StatusOK, &log)
+	assert.Equal(t, "ERROR: build failed\nWARNING: slow\n", log.Log)
+
+	f.getJSON("/api/resources/fe/logs?source=runtime", http.StatusOK, &log)
+	assert.Equal(t, "listening\nWARNING: slow\n", log.Log)
+
+	f.getJSON("/api/resources/fe/logs?source=runtime&since="+strconv.Itoa(log.Offset), http.StatusOK, &log)
+	assert.Equal(t, "", log.Log)
+
+	f.getJSON("/api/resources/fe/logs?level=debug", http.StatusBadRequest, nil)
+	f.getJSON("/api/resources/fe/logs?source=pod", http.StatusBadRequest, nil)
+}
+
+type fakeRuntimeLogEvent string
+
+func (e fakeRuntimeLogEvent) Message() []byte         { return []byte(e) }
+func (e fakeRuntimeLogEvent) Time() time.Time         { return time.Time{} }
+func (e fakeRuntimeLogEvent) Source() model.LogSource { return model.LogSourceRuntime }
+
+func (f *serverFixture) addManifest(name model.ManifestName, update func(ms *store.ManifestState)) {
+	state := f.st.LockMutableStateForTesting()
+	mt := store.NewManifestTarget(model.Manifest{Name: name})
+	update(mt.State)
+	state.UpsertManifestTarget(mt)
+	f.st.UnlockMutableState()
+}
+
+// Requests the path through the router, and decodes the response into v,
+// unless v is nil.
+func (f *serverFixture) getJSON(path string, code int, v interface{}) {
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	f.s.Router().ServeHTTP(rr, req)
+
+	if status := rr.Code; status != code {
+		f.t.Fatalf("%s: handler returned wrong status code: got %v want %v (%s)",
+			path, status, code, rr.Body.String())
+	}
+	if v == nil {
+		return
+	}
+	err = json.Unmarshal(rr.Body.Bytes(), v)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+}
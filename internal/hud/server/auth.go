@@ -0,0 +1,211 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/windmilleng/tilt/internal/network"
+)
+
+const tokenCookieName = "tilt_token"
+
+// The session token is a random token that the server makes up when it
+// starts, and requires on every request that changes anything. The web UI
+// reads it from a cookie that the server sets on page loads; other clients
+// ask /api/session for it. Web pages on other origins can't do either.
+const sessionCookieName = "tilt_session"
+const sessionHeaderName = "X-Tilt-Session"
+
+func newSessionToken() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		// crypto/rand only fails if the OS has no source of randomness,
+		// and then nothing else is going to work either.
+		panic(fmt.Sprintf("generating session token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// Rejects requests addressed to a host other than the one we listen on.
+//
+// Otherwise, a web page can set its domain name to resolve to 127.0.0.1
+// (DNS rebinding), and then the browser treats the Tilt server as the page's
+// own origin, so the page can do anything the web UI can.
+func (s HeadsUpServer) checkHost(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !s.hostAllowed(req.Host) {
+			http.Error(w, fmt.Sprintf("requests to host %q aren't allowed", req.Host), http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func (s HeadsUpServer) hostAllowed(hostport string) bool {
+	// Browsers always send a Host, so a request without one isn't from a web page.
+	if hostport == "" {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.Trim(host, "[]")
+	if network.IsLoopbackHost(host) || strings.EqualFold(host, string(s.auth.Host)) {
+		return true
+	}
+
+	// If we listen on every interface, we can't know every name that the
+	// machine goes by, so we rely on the --web-token instead.
+	ip := net.ParseIP(string(s.auth.Host))
+	return ip != nil && ip.IsUnspecified()
+}
+
+// Gives the web UI the session token when it loads a page.
+func (s HeadsUpServer) setSessionCookie(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && !isAPIPath(req.URL.Path) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     sessionCookieName,
+				Value:    s.sessionToken,
+				Path:     "/",
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func (s HeadsUpServer) SessionJSON(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(map[string]string{"token": s.sessionToken})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering session: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (s HeadsUpServer) sessionTokenMatches(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.sessionToken)) == 1
+}
+
+// Checks the token on every request, if there is one (see model.WebAuth).
+//
+// Browsers can't put headers on websocket requests or page loads, so the
+// web UI link carries the token as ?token=. We trade it for a cookie and
+// redirect, so that the token doesn't stay in the address bar.
+func (s HeadsUpServer) authenticate(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if s.auth.Token == "" || isCORSPreflight(req) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		if s.tokenMatches(bearerToken(req)) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		if cookie, err := req.Cookie(tokenCookieName); err == nil && s.tokenMatches(cookie.Value) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		query := req.URL.Query()
+		if s.tokenMatches(query.Get("token")) {
+			if isAPIPath(req.URL.Path) {
+				handler.ServeHTTP(w, req)
+				return
+			}
+
+			http.SetCookie(w, &http.Cookie{
+				Name:     tokenCookieName,
+				Value:    s.auth.Token,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			query.Del("token")
+			u := *req.URL
+			u.RawQuery = query.Encode()
+			http.Redirect(w, req, u.RequestURI(), http.StatusFound)
+			return
+		}
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="tilt"`)
+		http.Error(w, "missing or wrong token. Open the link that tilt up printed, or pass the token in an Authorization: Bearer header", http.StatusUnauthorized)
+	})
+}
+
+func (s HeadsUpServer) tokenMatches(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.auth.Token)) == 1
+}
+
+func bearerToken(req *http.Request) string {
+	h := req.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(h[len(prefix):])
+}
+
+func isAPIPath(path string) bool {
+	return strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/ws/")
+}
+
+// Lets web pages on the allowed origins call the API.
+func (s HeadsUpServer) allowCORS(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		if origin == "" || !s.originAllowed(origin) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		if isCORSPreflight(req) {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+sessionHeaderName)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func isCORSPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+func (s HeadsUpServer) originAllowed(origin string) bool {
+	for _, allowed := range s.auth.AllowedOrigins {
+		if allowed == "*" || strings.TrimSuffix(allowed, "/") == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether a request comes from the web UI itself, one of the allowed
+// origins, or something that isn't a browser (which sends no Origin).
+func (s HeadsUpServer) requestOriginAllowed(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err == nil && u.Host == req.Host {
+		return true
+	}
+	return s.originAllowed(origin)
+}
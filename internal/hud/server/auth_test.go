@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestNoTokenAllowsEveryone(t *testing.T) {
+	f := newTestFixture(t)
+	rr := f.serve(f.newRequest(http.MethodGet, "/api/resources"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestTokenRequired(t *testing.T) {
+	f := newTestFixtureWithAuth(t, model.WebAuth{Token: "s3cret"})
+
+	rr := f.serve(f.newRequest(http.MethodGet, "/api/resources"))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	req := f.newRequest(http.MethodGet, "/api/resources")
+	req.Header.Set("Authorization", "Bearer wrong")
+	rr = f.serve(req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	req = f.newRequest(http.MethodGet, "/api/resources")
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rr = f.serve(req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = f.serve(f.newRequest(http.MethodGet, "/api/resources?token=s3cret"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestTokenInLinkSetsCookie(t *testing.T) {
+	f := newTestFixtureWithAuth(t, model.WebAuth{Token: "s3cret"})
+
+	rr := f.serve(f.newRequest(http.MethodGet, "/r/fe/?token=s3cret"))
+	assert.Equal(t, http.StatusFound, rr.Code)
+	assert.Equal(t, "/r/fe/", rr.Header().Get("Location"))
+
+	cookies := (&http.Response{Header: rr.Header()}).Cookies()
+	if assert.Len(t, cookies, 1) {
+		req := f.newRequest(http.MethodGet, "/api/resources")
+		req.AddCookie(cookies[0])
+		rr = f.serve(req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	f := newTestFixtureWithAuth(t, model.WebAuth{
+		Token:          "s3cret",
+		AllowedOrigins: []string{"https://dash.example.com"},
+	})
+
+	// Browsers don't send credentials with preflight requests.
+	req := f.newRequest(http.MethodOptions, "/api/trigger")
+	req.Header.Set("Origin", "https://dash.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rr := f.serve(req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "https://dash.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rr.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	trigger := func(origin string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:10350/api/trigger", bytes.NewBufferString(`{"names": []}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		return f.serve(req)
+	}
+
+	rr = trigger("https://dash.example.com")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "https://dash.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+
+	rr = trigger("https://evil.example.com")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, "", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// Stops web pages that point their own domain names at 127.0.0.1.
+func TestHostCheck(t *testing.T) {
+	for _, tc := range []struct {
+		listen string
+		url    string
+		code   int
+	}{
+		{"localhost", "http://localhost:10350/api/resources", http.StatusOK},
+		{"localhost", "http://127.0.0.1:10350/api/resources", http.StatusOK},
+		{"localhost", "http://[::1]:10350/api/resources", http.StatusOK},
+		{"localhost", "http://evil.example.com:10350/api/resources", http.StatusForbidden},
+		{"devbox.internal", "http://devbox.internal:10350/api/resources", http.StatusOK},
+		{"devbox.internal", "http://evil.example.com:10350/api/resources", http.StatusForbidden},
+		{"0.0.0.0", "http://devbox.internal:10350/api/resources", http.StatusOK},
+	} {
+		f := newTestFixtureWithAuth(t, model.WebAuth{Host: model.WebHost(tc.listen)})
+		rr := f.serve(f.newRequest(http.MethodGet, tc.url))
+		assert.Equal(t, tc.code, rr.Code, "listening on %s: %s", tc.listen, tc.url)
+	}
+}
+
+func (f *serverFixture) newRequest(method, path string) *http.Request {
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return req
+}
+
+func (f *serverFixture) serve(req *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	f.s.Router().ServeHTTP(rr, req)
+	return rr
+}
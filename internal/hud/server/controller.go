@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"net"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -12,15 +13,19 @@ import (
 )
 
 type HeadsUpServerController struct {
+	host        model.WebHost
 	port        model.WebPort
+	grpcPort    model.GRPCPort
 	hudServer   HeadsUpServer
 	assetServer assets.Server
 	initDone    bool
 }
 
-func ProvideHeadsUpServerController(port model.WebPort, hudServer HeadsUpServer, assetServer assets.Server) *HeadsUpServerController {
+func ProvideHeadsUpServerController(host model.WebHost, port model.WebPort, grpcPort model.GRPCPort, hudServer HeadsUpServer, assetServer assets.Server) *HeadsUpServerController {
 	return &HeadsUpServerController{
+		host:        host,
 		port:        port,
+		grpcPort:    grpcPort,
 		hudServer:   hudServer,
 		assetServer: assetServer,
 	}
@@ -35,11 +40,21 @@ func (s *HeadsUpServerController) OnChange(ctx context.Context, st store.RStore)
 		s.initDone = true
 	}()
 
-	if s.initDone || s.port == 0 {
+	if s.initDone {
 		return
 	}
 
-	err := network.IsBindAddrFree(network.LocalhostBindAddr(int(s.port)))
+	// The gRPC API doesn't need the HTTP server, so it runs even with --port=0.
+	if s.grpcPort != 0 {
+		s.serveGRPC(ctx, st)
+	}
+
+	if s.port == 0 {
+		return
+	}
+
+	addr := network.BindAddr(string(s.host), int(s.port))
+	err := network.IsBindAddrFree(addr)
 	if err != nil {
 		st.Dispatch(
 			store.NewErrorAction(
@@ -48,7 +63,7 @@ func (s *HeadsUpServerController) OnChange(ctx context.Context, st store.RStore)
 	}
 
 	httpServer := &http.Server{
-		Addr:    network.LocalhostBindAddr(int(s.port)),
+		Addr:    addr,
 		Handler: http.DefaultServeMux,
 	}
 	http.Handle("/", s.hudServer.Router())
@@ -73,4 +88,27 @@ func (s *HeadsUpServerController) OnChange(ctx context.Context, st store.RStore)
 	}()
 }
 
+func (s *HeadsUpServerController) serveGRPC(ctx context.Context, st store.RStore) {
+	l, err := net.Listen("tcp", network.BindAddr(string(s.host), int(s.grpcPort)))
+	if err != nil {
+		st.Dispatch(
+			store.NewErrorAction(
+				errors.Wrapf(err, "Cannot start Tilt. Maybe another process is already running on port %d? Use --grpc-port to set a custom port", s.grpcPort)))
+		return
+	}
+
+	grpcServer := s.hudServer.GRPCServer()
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+
+	go func() {
+		err := grpcServer.Serve(l)
+		if err != nil && ctx.Err() == nil {
+			st.Dispatch(store.NewErrorAction(err))
+		}
+	}()
+}
+
 var _ store.TearDowner = &HeadsUpServerController{}
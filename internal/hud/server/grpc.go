@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/hud/webview"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/tiltapi"
+)
+
+// The gRPC server for pkg/tiltapi. Like the HTTP server, it needs the token,
+// if there is one, in an "authorization: Bearer <token>" header.
+func (s HeadsUpServer) GRPCServer() *grpc.Server {
+	g := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			err := s.authenticateGRPC(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			err := s.authenticateGRPC(ss.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	tiltapi.RegisterTiltServer(g, tiltAPIServer{s})
+	return g
+}
+
+func (s HeadsUpServer) authenticateGRPC(ctx context.Context) error {
+	if s.auth.Token == "" {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if strings.HasPrefix(v, "Bearer ") && s.tokenMatches(strings.TrimPrefix(v, "Bearer ")) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or wrong token")
+}
+
+type tiltAPIServer struct {
+	s HeadsUpServer
+}
+
+var _ tiltapi.TiltServer = tiltAPIServer{}
+
+func (t tiltAPIServer) view() webview.View {
+	state := t.s.store.RLockState()
+	defer t.s.store.RUnlockState()
+	return webview.StateToWebView(state)
+}
+
+func (t tiltAPIServer) listResources() *tiltapi.ListResourcesReply {
+	reply := &tiltapi.ListResourcesReply{}
+	for _, res := range t.view().Resources {
+		reply.Resources = append(reply.Resources, newProtoResource(newAPIResource(res)))
+	}
+	return reply
+}
+
+func (t tiltAPIServer) ListResources(ctx context.Context, req *tiltapi.ListResourcesRequest) (*tiltapi.ListResourcesReply, error) {
+	return t.listResources(), nil
+}
+
+// The state changes with every log line, so WatchResources waits this long
+// after a change for more of them, instead of re-listing the resources each time.
+const watchResourcesInterval = 200 * time.Millisecond
+
+func (t tiltAPIServer) WatchResources(req *tiltapi.WatchResourcesRequest, stream tiltapi.Tilt_WatchResourcesServer) error {
+	ctx := stream.Context()
+	n := newChangeNotifier()
+	t.s.store.AddSubscriber(ctx, n)
+	defer func() {
+		_ = t.s.store.RemoveSubscriber(context.Background(), n)
+	}()
+
+	var last *tiltapi.ListResourcesReply
+	for {
+		reply := t.listResources()
+		if !proto.Equal(reply, last) {
+			err := stream.Send(reply)
+			if err != nil {
+				return err
+			}
+			last = reply
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-n.ch:
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchResourcesInterval):
+		}
+	}
+}
+
+func (t tiltAPIServer) Trigger(ctx context.Context, req *tiltapi.TriggerRequest) (*tiltapi.TriggerReply, error) {
+	if req.All {
+		t.s.store.Dispatch(view.TriggerAllAction{})
+		return &tiltapi.TriggerReply{}, nil
+	}
+
+	if name, ok := t.s.missingResource(req.Names); !ok {
+		return nil, status.Errorf(codes.NotFound, "no resource named %q", name)
+	}
+	for _, name := range req.Names {
+		t.s.store.Dispatch(view.AppendToTriggerQueueAction{Name: model.ManifestName(name)})
+	}
+	return &tiltapi.TriggerReply{}, nil
+}
+
+func (t tiltAPIServer) SetEnabled(ctx context.Context, req *tiltapi.SetEnabledRequest) (*tiltapi.SetEnabledReply, error) {
+	if name, ok := t.s.missingResource([]string{req.Name}); !ok {
+		return nil, status.Errorf(codes.NotFound, "no resource named %q", name)
+	}
+
+	name := model.ManifestName(req.Name)
+	if req.Enabled {
+		t.s.store.Dispatch(view.EnableResourceAction{Name: name})
+	} else {
+		t.s.store.Dispatch(view.DisableResourceAction{Name: name, DeleteObjects: req.Delete})
+	}
+	return &tiltapi.SetEnabledReply{}, nil
+}
+
+func (t tiltAPIServer) StreamLogs(req *tiltapi.StreamLogsRequest, stream tiltapi.Tilt_StreamLogsServer) error {
+	var filter model.LogFilter
+	if req.Source != "" {
+		source, err := model.ParseLogSource(req.Source)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter.Source = source
+	}
+	if req.Level != "" {
+		level, err := model.ParseLogLevel(req.Level)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter.MinLevel = level
+	}
+
+	// Subscribe before we read the log, so that we don't miss anything
+	// logged in between.
+	ctx := stream.Context()
+	n := newChangeNotifier()
+	if req.Follow {
+		t.s.store.AddSubscriber(ctx, n)
+		defer func() {
+			_ = t.s.store.RemoveSubscriber(context.Background(), n)
+		}()
+	}
+
+	name := model.ManifestName(req.Name)
+	res, ok := t.view().Resource(name)
+	if !ok {
+		return status.Errorf(codes.NotFound, "no resource named %q", req.Name)
+	}
+	log := res.CombinedLog.Filter(filter)
+	err := stream.Send(&tiltapi.LogChunk{Text: log.String(), Replace: true})
+	if err != nil || !req.Follow {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-n.ch:
+		}
+
+		res, ok := t.view().Resource(name)
+		if !ok {
+			// The resource went away, so there's nothing more to follow.
+			return nil
+		}
+
+		newLog := res.CombinedLog.Filter(filter)
+		patch := webview.DiffLogs(log, newLog, false)
+		log = newLog
+		if patch == nil {
+			continue
+		}
+		err := stream.Send(&tiltapi.LogChunk{Text: patch.Text, Replace: patch.Reset, Drop: int64(patch.Drop)})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Wakes up a gRPC stream when the state changes. If the stream is still busy
+// with the last change, the next one waits for it instead of piling up.
+type changeNotifier struct {
+	ch chan struct{}
+}
+
+func newChangeNotifier() *changeNotifier {
+	return &changeNotifier{ch: make(chan struct{}, 1)}
+}
+
+func (n *changeNotifier) OnChange(ctx context.Context, st store.RStore) {
+	select {
+	case n.ch <- struct{}{}:
+	default:
+	}
+}
+
+var _ store.Subscriber = &changeNotifier{}
+
+func newProtoResource(r apiResource) *tiltapi.Resource {
+	result := &tiltapi.Resource{
+		Name:           r.Name,
+		Type:           r.Type,
+		UpdateStatus:   r.UpdateStatus,
+		RuntimeStatus:  r.RuntimeStatus,
+		Status:         r.Status,
+		TriggerMode:    r.TriggerMode,
+		Disabled:       r.Disabled,
+		UpdatesPaused:  r.UpdatesPaused,
+		Endpoints:      r.Endpoints,
+		PodName:        r.PodName,
+		PodRestarts:    int32(r.PodRestarts),
+		PendingChanges: r.PendingChanges,
+		Labels:         r.Labels,
+	}
+	if r.LastDeployTime != nil {
+		result.LastDeployTime = newProtoTimestamp(*r.LastDeployTime)
+	}
+	if r.CurrentBuild != nil {
+		result.CurrentBuild = newProtoBuild(*r.CurrentBuild)
+	}
+	for _, b := range r.BuildHistory {
+		result.BuildHistory = append(result.BuildHistory, newProtoBuild(b))
+	}
+	return result
+}
+
+func newProtoBuild(b apiBuild) *tiltapi.Build {
+	result := &tiltapi.Build{
+		StartTime:      newProtoTimestamp(b.StartTime),
+		Reasons:        b.Reasons,
+		Edits:          b.Edits,
+		Error:          b.Error,
+		Warnings:       b.Warnings,
+		UpdateType:     b.UpdateType,
+		FallbackReason: b.FallbackReason,
+	}
+	if b.FinishTime != nil {
+		result.FinishTime = newProtoTimestamp(*b.FinishTime)
+	}
+	return result
+}
+
+func newProtoTimestamp(t time.Time) *timestamp.Timestamp {
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
@@ -0,0 +1,154 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+	"github.com/windmilleng/tilt/pkg/tiltapi"
+)
+
+func TestGRPCListResources(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {})
+	client, closer := f.dialGRPC("")
+	defer closer()
+
+	reply, err := client.ListResources(context.Background(), &tiltapi.ListResourcesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, reply.Resources, 2) {
+		assert.Equal(t, "(Tiltfile)", reply.Resources[0].Name)
+		assert.Equal(t, "fe", reply.Resources[1].Name)
+		assert.Equal(t, "none", reply.Resources[1].UpdateStatus)
+	}
+}
+
+func TestGRPCTrigger(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {})
+	client, closer := f.dialGRPC("")
+	defer closer()
+
+	_, err := client.Trigger(context.Background(), &tiltapi.TriggerRequest{Names: []string{"missing"}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Trigger(context.Background(), &tiltapi.TriggerRequest{Names: []string{"fe"}})
+	assert.NoError(t, err)
+}
+
+func TestGRPCStreamLogs(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {
+		ms.CombinedLog = model.NewLog("hello\nERROR: oops\n")
+	})
+	client, closer := f.dialGRPC("")
+	defer closer()
+
+	stream, err := client.StreamLogs(context.Background(), &tiltapi.StreamLogsRequest{Name: "fe", Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "ERROR: oops\n", chunk.Text)
+	assert.True(t, chunk.Replace)
+
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	stream, err = client.StreamLogs(context.Background(), &tiltapi.StreamLogsRequest{Name: "fe", Level: "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCStreamLogsDropsTruncatedBytes(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {
+		ms.CombinedLog = model.NewLog(strings.Repeat("old line\n", 10000))
+	})
+	client, closer := f.dialGRPC("")
+	defer closer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f.st.SetUpSubscribersForTesting(ctx)
+	stream, err := client.StreamLogs(ctx, &tiltapi.StreamLogsRequest{Name: "fe", Follow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, chunk.Replace)
+	log := chunk.Text
+
+	// Fill up the log, so that Tilt truncates the start of it.
+	state := f.st.LockMutableStateForTesting()
+	ms, _ := state.ManifestState("fe")
+	ms.CombinedLog = model.AppendLog(ms.CombinedLog, store.NewLogEvent([]byte(strings.Repeat("new line\n", 10000))), false)
+	expected := ms.CombinedLog.String()
+	f.st.UnlockMutableState()
+	f.st.NotifySubscribers(ctx)
+
+	chunk, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, chunk.Replace)
+	assert.True(t, chunk.Drop > 0)
+	log = log[chunk.Drop:] + chunk.Text
+	assert.Equal(t, expected, log)
+}
+
+func TestGRPCToken(t *testing.T) {
+	f := newTestFixtureWithAuth(t, model.WebAuth{Token: "s3cret"})
+
+	for token, code := range map[string]codes.Code{
+		"":       codes.Unauthenticated,
+		"wrong":  codes.Unauthenticated,
+		"s3cret": codes.OK,
+	} {
+		client, closer := f.dialGRPC(token)
+		_, err := client.ListResources(context.Background(), &tiltapi.ListResourcesRequest{})
+		assert.Equal(t, code, status.Code(err), "token %q", token)
+		closer()
+	}
+}
+
+// Serves the gRPC API on a free port, until the returned func is called.
+func (f *serverFixture) dialGRPC(token string) (tiltapi.TiltClient, func()) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	g := f.s.GRPCServer()
+	go func() {
+		_ = g.Serve(l)
+	}()
+
+	client, conn, err := tiltapi.Dial(context.Background(), l.Addr().(*net.TCPAddr).Port, token)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return client, func() {
+		_ = conn.Close()
+		g.Stop()
+	}
+}
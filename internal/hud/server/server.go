@@ -9,9 +9,13 @@ import (
 
 	"github.com/gorilla/mux"
 	_ "github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/windmilleng/tilt/internal/assets"
+	"github.com/windmilleng/tilt/internal/hud/view"
 	"github.com/windmilleng/tilt/internal/hud/webview"
 	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/metrics"
+	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/sail/client"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/wmclient/pkg/analytics"
@@ -23,33 +27,122 @@ type analyticsPayload struct {
 	Tags map[string]string `json:"tags"`
 }
 
+type downPayload struct {
+	Name string `json:"name"`
+}
+
+type cancelPayload struct {
+	Name string `json:"name"`
+}
+
+type clearErrorPayload struct {
+	Name string `json:"name"`
+}
+
+type enablePayload struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+
+	// When disabling, also delete the resource's objects.
+	Delete bool `json:"delete"`
+}
+
+// With no names, pauses (or resumes) updates for the whole session.
+type pausePayload struct {
+	Names  []string `json:"names"`
+	Paused bool     `json:"paused"`
+}
+
+type triggerPayload struct {
+	Names []string `json:"names"`
+	All   bool     `json:"all"`
+}
+
 type HeadsUpServer struct {
-	store   *store.Store
-	router  *mux.Router
-	a       analytics.Analytics
-	sailCli client.SailClient
+	store      *store.Store
+	router     *mux.Router
+	a          analytics.Analytics
+	sailCli    client.SailClient
+	watchStats store.WatchStatsReporter
+	auth       model.WebAuth
+
+	// See sessionCookieName.
+	sessionToken string
 }
 
-func ProvideHeadsUpServer(store *store.Store, assetServer assets.Server, analytics analytics.Analytics, sailCli client.SailClient) HeadsUpServer {
+func ProvideHeadsUpServer(store *store.Store, assetServer assets.Server, analytics analytics.Analytics, sailCli client.SailClient, watchStats store.WatchStatsReporter, auth model.WebAuth) HeadsUpServer {
 	r := mux.NewRouter().UseEncodedPath()
 	s := HeadsUpServer{
-		store:   store,
-		router:  r,
-		a:       analytics,
-		sailCli: sailCli,
+		store:        store,
+		router:       r,
+		a:            analytics,
+		sailCli:      sailCli,
+		watchStats:   watchStats,
+		auth:         auth,
+		sessionToken: newSessionToken(),
 	}
 
 	r.HandleFunc("/api/view", s.ViewJSON)
+	r.HandleFunc("/api/snapshot", s.SnapshotJSON)
 	r.HandleFunc("/api/analytics", s.HandleAnalytics)
-	r.HandleFunc("/api/sail", s.HandleSail)
+	r.HandleFunc("/api/session", s.SessionJSON).Methods(http.MethodGet)
+	r.HandleFunc("/api/sail", s.requireSession(s.HandleSail))
+	r.HandleFunc("/api/down", s.requireSession(s.HandleDown))
+	r.HandleFunc("/api/trigger", s.requireSession(s.HandleTrigger))
+	r.HandleFunc("/api/cancel", s.requireSession(s.HandleCancel))
+	r.HandleFunc("/api/clear-error", s.requireSession(s.HandleClearError))
+	r.HandleFunc("/api/enable", s.requireSession(s.HandleEnable))
+	r.HandleFunc("/api/pause", s.requireSession(s.HandlePause))
+	r.HandleFunc("/api/dump/watches", s.DumpWatchesJSON)
+	r.HandleFunc("/api/dump/engine", s.DumpEngineJSON).Methods(http.MethodGet)
+	r.HandleFunc("/api/dump/images", s.DumpImagesJSON).Methods(http.MethodGet)
+	r.HandleFunc("/api/dump/logstore", s.DumpLogStoreJSON).Methods(http.MethodGet)
+	r.HandleFunc("/api/resources", s.ResourcesJSON).Methods(http.MethodGet)
+	r.HandleFunc("/api/resources/{name}", s.ResourceJSON).Methods(http.MethodGet)
+	r.HandleFunc("/api/resources/{name}/logs", s.ResourceLogJSON).Methods(http.MethodGet)
 	r.HandleFunc("/ws/view", s.ViewWebsocket)
+	r.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})).Methods(http.MethodGet)
 	r.PathPrefix("/").Handler(assetServer)
 
 	return s
 }
 
 func (s HeadsUpServer) Router() http.Handler {
-	return s.router
+	return s.checkHost(s.allowCORS(s.authenticate(s.setSessionCookie(s.router))))
+}
+
+// Browsers let any web page POST to localhost, as long as the request looks
+// like a form submission, so without this check any page the user visits
+// could build and tear down their resources.
+//
+// Requests need the session token (see sessionCookieName), or the --web-token
+// in an Authorization header, which web pages can't set on requests to other
+// origins unless we allow it.
+func (s HeadsUpServer) requireSession(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !s.requestOriginAllowed(req) {
+			http.Error(w, fmt.Sprintf("requests from %s aren't allowed", req.Header.Get("Origin")), http.StatusForbidden)
+			return
+		}
+		if !s.sessionTokenMatches(req.Header.Get(sessionHeaderName)) &&
+			!(s.auth.Token != "" && s.tokenMatches(bearerToken(req))) {
+			http.Error(w, fmt.Sprintf("missing or wrong %s header. Get the token from /api/session", sessionHeaderName), http.StatusForbidden)
+			return
+		}
+		handler(w, req)
+	}
+}
+
+// Returns the first name that isn't a resource, and false, if there is one.
+func (s HeadsUpServer) missingResource(names []string) (string, bool) {
+	state := s.store.RLockState()
+	defer s.store.RUnlockState()
+	for _, name := range names {
+		if _, ok := state.ManifestTargets[model.ManifestName(name)]; !ok {
+			return name, false
+		}
+	}
+	return "", true
 }
 
 func (s HeadsUpServer) ViewJSON(w http.ResponseWriter, req *http.Request) {
@@ -64,6 +157,73 @@ func (s HeadsUpServer) ViewJSON(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// A snapshot of the whole engine state, to attach to bug reports
+// and look at with `tilt replay`.
+func (s HeadsUpServer) SnapshotJSON(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	snapshot := store.NewSnapshot(state)
+	s.store.RUnlockState()
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(snapshot)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering snapshot: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// What the file watcher is doing, for `tilt dump watches`.
+func (s HeadsUpServer) DumpWatchesJSON(w http.ResponseWriter, req *http.Request) {
+	stats := []store.WatchStats{}
+	if s.watchStats != nil {
+		stats = s.watchStats.WatchStats()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering watch stats: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// The whole engine state, for `tilt dump engine`.
+func (s HeadsUpServer) DumpEngineJSON(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	dump := store.NewEngineStateDump(state)
+	s.store.RUnlockState()
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(dump)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering engine state: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// The image refs we inject into each resource's YAML, for `tilt dump images`.
+func (s HeadsUpServer) DumpImagesJSON(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	images := store.ImageMap(state)
+	s.store.RUnlockState()
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(images)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering image map: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// How much log we're holding, for `tilt dump logstore`.
+func (s HeadsUpServer) DumpLogStoreJSON(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	stats := store.NewLogStoreStats(state)
+	s.store.RUnlockState()
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering log store stats: %v", err), http.StatusInternalServerError)
+	}
+}
+
 func (s HeadsUpServer) HandleAnalytics(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		http.Error(w, "must be POST request", http.StatusBadRequest)
@@ -89,6 +249,189 @@ func (s HeadsUpServer) HandleAnalytics(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+// Tears down a resource, and takes it out of the session.
+func (s HeadsUpServer) HandleDown(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "must be POST request", http.StatusBadRequest)
+		return
+	}
+
+	var payload downPayload
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	state := s.store.RLockState()
+	_, ok := state.ManifestTargets[model.ManifestName(payload.Name)]
+	s.store.RUnlockState()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no resource named %q", payload.Name), http.StatusNotFound)
+		return
+	}
+
+	s.store.Dispatch(view.TearDownAction{Name: model.ManifestName(payload.Name)})
+}
+
+// Builds the named resources, or rebuilds every resource from scratch.
+func (s HeadsUpServer) HandleTrigger(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "must be POST request", http.StatusBadRequest)
+		return
+	}
+
+	var payload triggerPayload
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if payload.All {
+		s.store.Dispatch(view.TriggerAllAction{})
+		return
+	}
+
+	if name, ok := s.missingResource(payload.Names); !ok {
+		http.Error(w, fmt.Sprintf("no resource named %q", name), http.StatusNotFound)
+		return
+	}
+
+	for _, name := range payload.Names {
+		s.store.Dispatch(view.AppendToTriggerQueueAction{Name: model.ManifestName(name)})
+	}
+}
+
+// Cancels the current build of the named resource.
+func (s HeadsUpServer) HandleCancel(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "must be POST request", http.StatusBadRequest)
+		return
+	}
+
+	var payload cancelPayload
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	name := model.ManifestName(payload.Name)
+	state := s.store.RLockState()
+	_, ok := state.ManifestTargets[name]
+	building := state.CurrentlyBuilding[name]
+	s.store.RUnlockState()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no resource named %q", payload.Name), http.StatusNotFound)
+		return
+	}
+	if !building {
+		http.Error(w, fmt.Sprintf("resource %q isn't building", payload.Name), http.StatusConflict)
+		return
+	}
+
+	s.store.Dispatch(view.CancelBuildAction{Name: name})
+}
+
+// Hides the named resource's last build error and pod restarts.
+func (s HeadsUpServer) HandleClearError(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "must be POST request", http.StatusBadRequest)
+		return
+	}
+
+	var payload clearErrorPayload
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	name := model.ManifestName(payload.Name)
+	state := s.store.RLockState()
+	_, ok := state.ManifestTargets[name]
+	s.store.RUnlockState()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no resource named %q", payload.Name), http.StatusNotFound)
+		return
+	}
+
+	s.store.Dispatch(view.ClearErrorAction{Name: name})
+}
+
+// Turns the named resource on or off.
+func (s HeadsUpServer) HandleEnable(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "must be POST request", http.StatusBadRequest)
+		return
+	}
+
+	var payload enablePayload
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	name := model.ManifestName(payload.Name)
+	state := s.store.RLockState()
+	_, ok := state.ManifestTargets[name]
+	s.store.RUnlockState()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no resource named %q", payload.Name), http.StatusNotFound)
+		return
+	}
+
+	if payload.Enabled {
+		s.store.Dispatch(view.EnableResourceAction{Name: name})
+	} else {
+		s.store.Dispatch(view.DisableResourceAction{Name: name, DeleteObjects: payload.Delete})
+	}
+}
+
+// Pauses or resumes updates, for the named resources or the whole session.
+func (s HeadsUpServer) HandlePause(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "must be POST request", http.StatusBadRequest)
+		return
+	}
+
+	var payload pausePayload
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if name, ok := s.missingResource(payload.Names); !ok {
+		http.Error(w, fmt.Sprintf("no resource named %q", name), http.StatusNotFound)
+		return
+	}
+
+	names := []model.ManifestName{""}
+	if len(payload.Names) > 0 {
+		names = nil
+		for _, name := range payload.Names {
+			names = append(names, model.ManifestName(name))
+		}
+	}
+
+	for _, name := range names {
+		if payload.Paused {
+			s.store.Dispatch(view.PauseUpdatesAction{Name: name})
+		} else {
+			s.store.Dispatch(view.ResumeUpdatesAction{Name: name})
+		}
+	}
+}
+
 func (s HeadsUpServer) HandleSail(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		http.Error(w, "must be POST request", http.StatusBadRequest)
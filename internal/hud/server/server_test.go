@@ -2,6 +2,8 @@ package server_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +12,7 @@ import (
 	"github.com/windmilleng/tilt/internal/assets"
 	"github.com/windmilleng/tilt/internal/engine"
 	"github.com/windmilleng/tilt/internal/hud/server"
+	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/sail/client"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/wmclient/pkg/analytics"
@@ -142,25 +145,330 @@ func TestHandleSail(t *testing.T) {
 	assert.Equal(f.t, 1, f.sailCli.ConnectCalls)
 }
 
+func TestHandleDownUnknownResource(t *testing.T) {
+	f := newTestFixture(t)
+
+	var jsonStr = []byte(`{"name": "foo"}`)
+	req, err := http.NewRequest(http.MethodPost, "/api/down", bytes.NewBuffer(jsonStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(f.s.HandleDown)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestHandleTriggerUnknownResource(t *testing.T) {
+	f := newTestFixture(t)
+
+	var jsonStr = []byte(`{"names": ["foo"]}`)
+	req, err := http.NewRequest(http.MethodPost, "/api/trigger", bytes.NewBuffer(jsonStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(f.s.HandleTrigger)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestHandlePause(t *testing.T) {
+	f := newTestFixture(t)
+
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "foo"}))
+	f.st.UnlockMutableState()
+
+	for body, code := range map[string]int{
+		`{"names": ["foo"], "paused": true}`: http.StatusOK,
+		`{"paused": true}`:                   http.StatusOK,
+		`{"names": ["bar"], "paused": true}`: http.StatusNotFound,
+	} {
+		req, err := http.NewRequest(http.MethodPost, "/api/pause", bytes.NewBuffer([]byte(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(f.s.HandlePause)
+
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != code {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v",
+				body, status, code)
+		}
+	}
+}
+
+func TestHandleCancelNotBuilding(t *testing.T) {
+	f := newTestFixture(t)
+
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "foo"}))
+	f.st.UnlockMutableState()
+
+	for name, code := range map[string]int{"foo": http.StatusConflict, "bar": http.StatusNotFound} {
+		var jsonStr = []byte(fmt.Sprintf(`{"name": %q}`, name))
+		req, err := http.NewRequest(http.MethodPost, "/api/cancel", bytes.NewBuffer(jsonStr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(f.s.HandleCancel)
+
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != code {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v",
+				name, status, code)
+		}
+	}
+}
+
+func TestHandleClearError(t *testing.T) {
+	f := newTestFixture(t)
+
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "foo"}))
+	f.st.UnlockMutableState()
+
+	for name, code := range map[string]int{"foo": http.StatusOK, "bar": http.StatusNotFound} {
+		var jsonStr = []byte(fmt.Sprintf(`{"name": %q}`, name))
+		req, err := http.NewRequest(http.MethodPost, "/api/clear-error", bytes.NewBuffer(jsonStr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(f.s.HandleClearError)
+
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != code {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v",
+				name, status, code)
+		}
+	}
+}
+
+// Other web pages can't trigger builds from the user's browser.
+func TestRequireSession(t *testing.T) {
+	f := newTestFixture(t)
+	session := f.sessionToken()
+
+	for _, tc := range []struct {
+		origin  string
+		session string
+		code    int
+	}{
+		{"", session, http.StatusOK},
+		{"http://localhost:10350", session, http.StatusOK},
+		{"", "", http.StatusForbidden},
+		{"http://localhost:10350", "", http.StatusForbidden},
+		{"http://localhost:10350", "wrong", http.StatusForbidden},
+		{"http://evil.example.com", session, http.StatusForbidden},
+	} {
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:10350/api/trigger", bytes.NewBuffer([]byte(`{"names": []}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		if tc.session != "" {
+			req.Header.Set("X-Tilt-Session", tc.session)
+		}
+
+		rr := httptest.NewRecorder()
+		f.s.Router().ServeHTTP(rr, req)
+
+		if status := rr.Code; status != tc.code {
+			t.Errorf("%q/%q: handler returned wrong status code: got %v want %v",
+				tc.origin, tc.session, status, tc.code)
+		}
+	}
+}
+
+func TestPageLoadSetsSessionCookie(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:10350/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	f.s.Router().ServeHTTP(rr, req)
+
+	cookies := (&http.Response{Header: rr.Header()}).Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "tilt_session", cookies[0].Name)
+		assert.Equal(t, f.sessionToken(), cookies[0].Value)
+		assert.False(t, cookies[0].HttpOnly, "the web UI needs to read it")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	f.s.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "tilt_build_queue_depth")
+}
+
+func TestDumpWatches(t *testing.T) {
+	f := newTestFixture(t)
+	f.watchStats.stats = []store.WatchStats{{
+		TargetID: model.TargetID{Type: model.TargetTypeImage, Name: "foo"},
+		Mode:     model.WatchModeNative,
+		Files:    3,
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/api/dump/watches", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(f.s.DumpWatchesJSON)
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var stats []store.WatchStats
+	err = json.Unmarshal(rr.Body.Bytes(), &stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, f.watchStats.stats, stats)
+}
+
+func TestDumpEngine(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {
+		ms.BuildHistory = []model.BuildRecord{{Error: fmt.Errorf("exit status 1")}}
+	})
+
+	var state struct {
+		Manifests []struct {
+			Name         string
+			BuildHistory []struct {
+				Error string
+			}
+		}
+	}
+	f.getJSON("/api/dump/engine", http.StatusOK, &state)
+	if assert.Equal(t, 1, len(state.Manifests)) {
+		fe := state.Manifests[0]
+		assert.Equal(t, "fe", fe.Name)
+		if assert.Equal(t, 1, len(fe.BuildHistory)) {
+			assert.Equal(t, "exit status 1", fe.BuildHistory[0].Error)
+		}
+	}
+}
+
+func TestDumpLogStore(t *testing.T) {
+	f := newTestFixture(t)
+	f.addManifest("fe", func(ms *store.ManifestState) {
+		ms.CombinedLog = model.NewLog("hello\n")
+	})
+
+	var stats store.LogStoreStats
+	f.getJSON("/api/dump/logstore", http.StatusOK, &stats)
+	assert.Equal(t, 6, stats.TotalBytes)
+
+	var images []store.ImageMapEntry
+	f.getJSON("/api/dump/images", http.StatusOK, &images)
+	assert.Empty(t, images)
+}
+
+type fakeWatchStats struct {
+	stats []store.WatchStats
+}
+
+func (f *fakeWatchStats) WatchStats() []store.WatchStats {
+	return f.stats
+}
+
 type serverFixture struct {
-	t       *testing.T
-	s       server.HeadsUpServer
-	a       *analytics.MemoryAnalytics
-	sailCli *client.FakeSailClient
+	t          *testing.T
+	s          server.HeadsUpServer
+	st         *store.Store
+	a          *analytics.MemoryAnalytics
+	sailCli    *client.FakeSailClient
+	watchStats *fakeWatchStats
 }
 
 func newTestFixture(t *testing.T) *serverFixture {
+	return newTestFixtureWithAuth(t, model.WebAuth{})
+}
+
+func newTestFixtureWithAuth(t *testing.T, auth model.WebAuth) *serverFixture {
 	st := store.NewStore(engine.UpperReducer, store.LogActionsFlag(false))
 	a := analytics.NewMemoryAnalytics()
 	sailCli := client.NewFakeSailClient()
-	s := server.ProvideHeadsUpServer(st, assets.NewFakeServer(), a, sailCli)
+	watchStats := &fakeWatchStats{}
+	s := server.ProvideHeadsUpServer(st, assets.NewFakeServer(), a, sailCli, watchStats, auth)
 
 	return &serverFixture{
-		t:       t,
-		s:       s,
-		a:       a,
-		sailCli: sailCli,
+		t:          t,
+		s:          s,
+		st:         st,
+		a:          a,
+		sailCli:    sailCli,
+		watchStats: watchStats,
+	}
+}
+
+func (f *serverFixture) sessionToken() string {
+	req, err := http.NewRequest(http.MethodGet, "/api/session", nil)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	f.s.Router().ServeHTTP(rr, req)
+
+	var session struct {
+		Token string `json:"token"`
+	}
+	err = json.Unmarshal(rr.Body.Bytes(), &session)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	if session.Token == "" {
+		f.t.Fatal("no session token")
 	}
+	return session.Token
 }
 
 func (f *serverFixture) assertIncrement(name string, count int) {
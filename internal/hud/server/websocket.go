@@ -5,22 +5,46 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
 	"github.com/windmilleng/tilt/internal/hud/webview"
 	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/store"
 
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
+// Like the default origin check, but also allows the model.WebAuth origins.
+func (s HeadsUpServer) upgrader() websocket.Upgrader {
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     s.requestOriginAllowed,
+	}
 }
 
 type WebsocketSubscriber struct {
 	conn       WebsocketConn
 	streamDone chan bool
+
+	// The view that the browser has, so that we only send it what changed.
+	sent *sentView
+
+	// Which log lines the browser wants.
+	logFilter model.LogFilter
+}
+
+type sentView struct {
+	mu   sync.Mutex
+	view *webview.View
+}
+
+// What we send over the websocket: the whole view when the browser
+// connects, then a patch for each change after that.
+type viewMessage struct {
+	View  *webview.View      `json:",omitempty"`
+	Patch *webview.ViewPatch `json:",omitempty"`
 }
 
 type WebsocketConn interface {
@@ -35,6 +59,7 @@ func NewWebsocketSubscriber(conn WebsocketConn) WebsocketSubscriber {
 	return WebsocketSubscriber{
 		conn:       conn,
 		streamDone: make(chan bool, 0),
+		sent:       &sentView{},
 	}
 }
 
@@ -63,18 +88,51 @@ func (ws WebsocketSubscriber) Stream(ctx context.Context, store *store.Store) {
 	_ = store.RemoveSubscriber(context.Background(), ws)
 }
 
+// A browser that stops reading (e.g., a laptop that went to sleep) can block
+// WriteJSON for a long time. Closing the connection makes it return, and the
+// browser reconnects when it wakes up.
+func (ws WebsocketSubscriber) Unstick(ctx context.Context) {
+	_ = ws.conn.Close()
+}
+
+var _ store.Unsticker = WebsocketSubscriber{}
+
 func (ws WebsocketSubscriber) OnChange(ctx context.Context, s store.RStore) {
 	state := s.RLockState()
 	view := webview.StateToWebView(state)
 	s.RUnlockState()
+	view = view.FilterLogs(ws.logFilter)
+
+	ws.sent.mu.Lock()
+	defer ws.sent.mu.Unlock()
 
-	err := ws.conn.WriteJSON(view)
+	msg := viewMessage{View: &view}
+	if ws.sent.view != nil {
+		patch := webview.DiffViews(*ws.sent.view, view)
+		msg = viewMessage{Patch: &patch}
+	}
+
+	err := ws.conn.WriteJSON(msg)
 	if err != nil {
 		logger.Get(ctx).Verbosef("sending webview data: %v", err)
+
+		// We don't know what the browser got, so start over.
+		ws.sent.view = nil
+		return
 	}
+	ws.sent.view = &view
 }
 
+// With ?source= and ?level=, only sends the resource log lines that match.
+// See logFilterFromRequest.
 func (s HeadsUpServer) ViewWebsocket(w http.ResponseWriter, req *http.Request) {
+	logFilter, err := logFilterFromRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	upgrader := s.upgrader()
 	conn, err := upgrader.Upgrade(w, req, nil)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error upgrading websocket: %v", err), http.StatusInternalServerError)
@@ -82,6 +140,7 @@ func (s HeadsUpServer) ViewWebsocket(w http.ResponseWriter, req *http.Request) {
 	}
 
 	ws := NewWebsocketSubscriber(conn)
+	ws.logFilter = logFilter
 
 	// TODO(nick): Handle clean shutdown when the server shuts down
 	ctx := context.TODO()
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/windmilleng/tilt/internal/hud/webview"
+	"github.com/windmilleng/tilt/internal/model"
 	"github.com/windmilleng/tilt/internal/store"
 	"github.com/windmilleng/tilt/internal/testutils/output"
 )
@@ -37,6 +39,45 @@ func TestWebsocketCloseOnReadErr(t *testing.T) {
 	conn.AssertClose(t, done)
 }
 
+func TestWebsocketSendsPatches(t *testing.T) {
+	ctx := output.CtxForTest()
+	st, _ := store.NewStoreForTesting()
+	st.SetUpSubscribersForTesting(ctx)
+
+	conn := newFakeConn()
+	ws := NewWebsocketSubscriber(conn)
+	st.AddSubscriber(ctx, ws)
+
+	done := make(chan bool)
+	go func() {
+		ws.Stream(ctx, st)
+		close(done)
+	}()
+
+	st.NotifySubscribers(ctx)
+	m := conn.AssertNextWriteMsg(t)
+	m.Ack()
+	first := m.v.(viewMessage)
+	assert.NotNil(t, first.View)
+	assert.Nil(t, first.Patch)
+
+	state := st.LockMutableStateForTesting()
+	state.Log = model.NewLog("hello\n")
+	st.UnlockMutableState()
+
+	st.NotifySubscribers(ctx)
+	m = conn.AssertNextWriteMsg(t)
+	m.Ack()
+	second := m.v.(viewMessage)
+	assert.Nil(t, second.View)
+	if assert.NotNil(t, second.Patch) && assert.NotNil(t, second.Patch.Log) {
+		assert.Equal(t, webview.LogPatch{Text: "hello\n"}, *second.Patch.Log)
+	}
+
+	conn.readCh <- fmt.Errorf("read error")
+	conn.AssertClose(t, done)
+}
+
 func TestWebsocketReadErrDuringMsg(t *testing.T) {
 	ctx := output.CtxForTest()
 	st, _ := store.NewStoreForTesting()
@@ -95,7 +136,7 @@ func (c *fakeConn) Close() error {
 }
 
 func (c *fakeConn) WriteJSON(v interface{}) error {
-	msg := msg{callback: make(chan error)}
+	msg := msg{v: v, callback: make(chan error)}
 	c.writeCh <- msg
 	return <-msg.callback
 }
@@ -120,6 +161,7 @@ func (c *fakeConn) AssertClose(t *testing.T, done chan bool) {
 }
 
 type msg struct {
+	v        interface{}
 	callback chan error
 }
 
@@ -0,0 +1,277 @@
+package hud
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// The widest that we pad resource names to, so that one long name doesn't
+// push everyone's logs off the side of the screen.
+const streamMaxPrefixWidth = 20
+
+var streamColors = []color.Attribute{color.FgCyan, color.FgMagenta, color.FgBlue, color.FgYellow, color.FgGreen}
+
+// Instead of the HUD, prints every resource's logs to a stream (e.g., stdout),
+// one line at a time, with the name of the resource in front of each line,
+// along with a line for each build and status change.
+// For CI logs, tmux panes, and anywhere else that a full-screen HUD doesn't work.
+type streamPrinter struct {
+	out io.Writer
+
+	// How much of each log we've printed. See model.Log.TotalLen.
+	globalOffset int
+	logOffsets   map[model.ManifestName]int
+
+	resources map[model.ManifestName]*streamResourceState
+
+	// Resource log lines that we printed recently. Most of them show up in
+	// the global log too, and we don't want to print them twice.
+	recentLines     map[streamLineKey]int
+	lastRecentLines map[streamLineKey]int
+}
+
+// A log line, and where in the global log its copy comes from: the resource
+// named in the line's prefix, or "" for lines without a prefix (i.e., the
+// ones Tilt writes itself, including the build logs of serial builds).
+type streamLineKey struct {
+	source string
+	line   string
+}
+
+// What we've told the user about a resource, so that we only tell them
+// when something changes.
+type streamResourceState struct {
+	colorIndex int
+
+	buildStart    time.Time
+	buildFinish   time.Time
+	runtimeStatus string
+	restarts      int
+}
+
+func newStreamPrinter(out io.Writer) *streamPrinter {
+	return &streamPrinter{
+		out:         out,
+		logOffsets:  make(map[model.ManifestName]int),
+		resources:   make(map[model.ManifestName]*streamResourceState),
+		recentLines: make(map[streamLineKey]int),
+	}
+}
+
+func (p *streamPrinter) print(v view.View) {
+	p.lastRecentLines = p.recentLines
+	p.recentLines = make(map[streamLineKey]int)
+
+	width := 0
+	for _, res := range v.Resources {
+		if w := len(streamName(res)); w > width {
+			width = w
+		}
+	}
+	if width > streamMaxPrefixWidth {
+		width = streamMaxPrefixWidth
+	}
+
+	for _, res := range v.Resources {
+		rs, ok := p.resources[res.Name]
+		if !ok {
+			rs = &streamResourceState{colorIndex: len(p.resources) % len(streamColors)}
+			p.resources[res.Name] = rs
+		}
+		prefix := color.New(streamColors[rs.colorIndex]).Sprintf("%-*s │ ", width, truncateName(streamName(res), width))
+
+		lines := p.newLines(res.Name, res.CombinedLog)
+		buildLogs := streamBuildLogs(res, lines)
+		for _, line := range lines {
+			key := normalizeStreamLine(line)
+			p.recentLines[streamLineKey{source: streamSource(res), line: key}]++
+			if buildLogs.contains(line) {
+				p.recentLines[streamLineKey{line: key}]++
+			}
+			fmt.Fprint(p.out, prefix+line)
+		}
+
+		for _, status := range rs.statusChanges(res) {
+			fmt.Fprintln(p.out, prefix+status)
+		}
+	}
+
+	// Everything else in the global log is from Tilt itself.
+	for _, line := range completeLines(v.Log.Since(p.globalOffset)) {
+		p.globalOffset += len(line)
+		if p.isRecentLine(line) {
+			continue
+		}
+		fmt.Fprint(p.out, line)
+	}
+}
+
+// The complete lines of the resource's log that we haven't printed yet.
+func (p *streamPrinter) newLines(name model.ManifestName, log model.Log) []string {
+	offset := p.logOffsets[name]
+	if log.TotalLen() < offset {
+		// The resource was recreated, and its log started over.
+		offset = 0
+	}
+	lines := completeLines(log.Since(offset))
+	for _, line := range lines {
+		offset += len(line)
+	}
+	p.logOffsets[name] = offset
+	return lines
+}
+
+// Whether the global log line is a copy of a resource log line that we
+// printed already. Lines only count as copies of lines from the same source,
+// so that we don't drop Tilt's own lines when a resource happens to log the same thing.
+func (p *streamPrinter) isRecentLine(line string) bool {
+	source, rest := splitStreamSource(line)
+	key := streamLineKey{source: source, line: rest}
+	for _, lines := range []map[streamLineKey]int{p.recentLines, p.lastRecentLines} {
+		if lines[key] > 0 {
+			lines[key]--
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *streamResourceState) statusChanges(res view.Resource) []string {
+	var result []string
+
+	if cb := res.CurrentBuild; !cb.Empty() && !cb.StartTime.Equal(rs.buildStart) {
+		rs.buildStart = cb.StartTime
+		result = append(result, fmt.Sprintf("Building: %s", buildReasonText(cb.Reason, cb.Edits)))
+	}
+
+	if lb := res.LastBuild(); !lb.Empty() && !lb.FinishTime.IsZero() && !lb.FinishTime.Equal(rs.buildFinish) {
+		rs.buildFinish = lb.FinishTime
+		duration := formatBuildDuration(lb.Duration())
+		if lb.Error != nil {
+			result = append(result, color.RedString("✖ Build failed in %s: %s", duration, firstLine(lb.Error.Error())))
+		} else if t := updateTypeText(lb); t != "" {
+			result = append(result, color.GreenString("✔ Build succeeded in %s (%s)", duration, t))
+		} else {
+			result = append(result, color.GreenString("✔ Build succeeded in %s", duration))
+		}
+		if lb.FallbackReason != "" {
+			result = append(result, color.YellowString("Couldn't live update: %s", firstLine(lb.FallbackReason)))
+		}
+	}
+
+	if res.ResourceInfo != nil {
+		if status := res.ResourceInfo.Status(); status != "" && status != rs.runtimeStatus {
+			rs.runtimeStatus = status
+			result = append(result, fmt.Sprintf("Status: %s", status))
+		}
+	}
+
+	if res.IsK8S() {
+		restarts := res.K8SInfo().PodRestarts
+		if restarts > rs.restarts {
+			result = append(result, color.RedString("Pod restarted (%s)", pluralize(restarts, "time")))
+		}
+		rs.restarts = restarts
+	}
+
+	return result
+}
+
+func streamName(res view.Resource) string {
+	if res.IsTiltfile {
+		return "Tiltfile"
+	}
+	return res.Name.String()
+}
+
+func truncateName(name string, width int) string {
+	if len(name) <= width {
+		return name
+	}
+	return name[:width-1] + "…"
+}
+
+// Splits s into lines, dropping the last line if it hasn't ended yet.
+func completeLines(s string) []string {
+	var result []string
+	for {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			return result
+		}
+		result = append(result, s[:i+1])
+		s = s[i+1:]
+	}
+}
+
+var streamTimestampRe = regexp.MustCompile(`^\d{4}/\d\d/\d\d \d\d:\d\d:\d\d `)
+
+// Matches the prefixes that Tilt puts on resource logs in the global log
+// (see logPrefix in the engine), and on Tiltfile logs.
+var streamGlobalPrefixRe = regexp.MustCompile(`^(?:([^┊\n]{12})┊ |(\[Tiltfile\]) )`)
+
+// The longest resource name that fits in a global log prefix (see logPrefix in the engine).
+const streamSourceMaxLen = 12
+
+// Resource log lines and their copies in the global log differ by a
+// timestamp and a prefix.
+func normalizeStreamLine(line string) string {
+	_, rest := splitStreamSource(line)
+	return rest
+}
+
+// Splits a global log line into the name in its prefix (see streamSource),
+// and the rest of the line, without the timestamp.
+func splitStreamSource(line string) (string, string) {
+	line = streamTimestampRe.ReplaceAllString(line, "")
+	m := streamGlobalPrefixRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", line
+	}
+	return strings.TrimRight(m[1]+m[2], " "), line[len(m[0]):]
+}
+
+// The name in the prefix of the resource's lines in the global log.
+func streamSource(res view.Resource) string {
+	if res.IsTiltfile {
+		return "[Tiltfile]"
+	}
+	name := res.Name.String()
+	if len(name) > streamSourceMaxLen {
+		name = name[:streamSourceMaxLen-1] + "…"
+	}
+	return name
+}
+
+// The logs of the resource's builds that the new lines could be from.
+type streamBuildLogSet []string
+
+func streamBuildLogs(res view.Resource, newLines []string) streamBuildLogSet {
+	if len(newLines) == 0 || res.IsTiltfile {
+		return nil
+	}
+	var result streamBuildLogSet
+	for _, b := range []model.BuildRecord{res.CurrentBuild, res.LastBuild()} {
+		if !b.Log.Empty() {
+			result = append(result, b.Log.String())
+		}
+	}
+	return result
+}
+
+func (s streamBuildLogSet) contains(line string) bool {
+	for _, log := range s {
+		if strings.Contains(log, line) {
+			return true
+		}
+	}
+	return false
+}
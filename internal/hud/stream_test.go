@@ -0,0 +1,157 @@
+package hud
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestStreamPrefixesResourceLogs(t *testing.T) {
+	defer setNoColor(true)()
+	out := &bytes.Buffer{}
+	p := newStreamPrinter(out)
+
+	p.print(view.View{
+		Resources: []view.Resource{
+			{Name: "fe", CombinedLog: model.NewLog("hello\nunfinished")},
+			{Name: "backend", CombinedLog: model.NewLog("world\n")},
+		},
+	})
+
+	assert.Equal(t, "fe      │ hello\nbackend │ world\n", out.String())
+}
+
+func TestStreamPrintsEachLineOnce(t *testing.T) {
+	defer setNoColor(true)()
+	out := &bytes.Buffer{}
+	p := newStreamPrinter(out)
+
+	log := model.NewLog("hello\nunfinished")
+	p.print(view.View{Resources: []view.Resource{{Name: "fe", CombinedLog: log}}})
+	log = model.AppendLog(log, testLogEvent(" line\nbye\n"), false)
+	p.print(view.View{Resources: []view.Resource{{Name: "fe", CombinedLog: log}}})
+
+	assert.Equal(t, "fe │ hello\nfe │ unfinished line\nfe │ bye\n", out.String())
+}
+
+// Resource logs show up in the global log too, but we only want to print
+// the lines that came from Tilt itself.
+func TestStreamSkipsResourceLinesInGlobalLog(t *testing.T) {
+	defer setNoColor(true)()
+	out := &bytes.Buffer{}
+	p := newStreamPrinter(out)
+
+	p.print(view.View{
+		Log: model.NewLog("Starting Tilt\nfrontend    ┊ hello\n2019/01/01 12:00:00 frontend    ┊ again\nwaiting\n"),
+		Resources: []view.Resource{
+			{Name: "frontend", CombinedLog: model.NewLog("hello\nagain\n")},
+		},
+	})
+
+	assert.Equal(t, "frontend │ hello\nfrontend │ again\nStarting Tilt\nwaiting\n", out.String())
+}
+
+// Only lines with the same source count as copies: Tilt's own lines aren't
+// dropped just because a resource logged the same thing.
+func TestStreamSkipsOnlyLinesFromTheSameSource(t *testing.T) {
+	defer setNoColor(true)()
+	out := &bytes.Buffer{}
+	p := newStreamPrinter(out)
+
+	p.print(view.View{
+		Log: model.NewLog("done\nbackend     ┊ done\nSTEP 1/1 — Deploying\n"),
+		Resources: []view.Resource{
+			{
+				Name:         "frontend",
+				CombinedLog:  model.NewLog("STEP 1/1 — Deploying\ndone\n"),
+				CurrentBuild: model.BuildRecord{StartTime: time.Now(), Log: model.NewLog("STEP 1/1 — Deploying\n")},
+			},
+		},
+	})
+
+	assert.Equal(t, "frontend │ STEP 1/1 — Deploying\nfrontend │ done\nfrontend │ Building: triggered\ndone\nbackend     ┊ done\n", out.String())
+}
+
+func TestStreamStatusChanges(t *testing.T) {
+	defer setNoColor(true)()
+	out := &bytes.Buffer{}
+	p := newStreamPrinter(out)
+
+	start := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	res := view.Resource{
+		Name:         "fe",
+		CurrentBuild: model.BuildRecord{StartTime: start, Reason: model.BuildReasonFlagInit},
+	}
+	p.print(view.View{Resources: []view.Resource{res}})
+	p.print(view.View{Resources: []view.Resource{res}})
+
+	res.CurrentBuild = model.BuildRecord{}
+	res.BuildHistory = []model.BuildRecord{{
+		StartTime:  start,
+		FinishTime: start.Add(1500 * time.Millisecond),
+		Error:      fmt.Errorf("exit status 1\nmore details"),
+	}}
+	p.print(view.View{Resources: []view.Resource{res}})
+	p.print(view.View{Resources: []view.Resource{res}})
+
+	assert.Equal(t, "fe │ Building: first build\nfe │ ✖ Build failed in 1.5s: exit status 1 …\n", out.String())
+}
+
+func TestStreamUpdateType(t *testing.T) {
+	defer setNoColor(true)()
+	out := &bytes.Buffer{}
+	p := newStreamPrinter(out)
+
+	start := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	p.print(view.View{Resources: []view.Resource{{
+		Name: "fe",
+		BuildHistory: []model.BuildRecord{{
+			StartTime:      start,
+			FinishTime:     start.Add(3 * time.Second),
+			UpdateType:     model.UpdateTypeImageBuild,
+			FallbackReason: "don't have info for deployed container",
+		}},
+	}}})
+
+	assert.Equal(t, "fe │ ✔ Build succeeded in 3.0s (image build)\n"+
+		"fe │ Couldn't live update: don't have info for deployed container\n", out.String())
+}
+
+func TestStreamTruncatesLongNames(t *testing.T) {
+	defer setNoColor(true)()
+	out := &bytes.Buffer{}
+	p := newStreamPrinter(out)
+
+	p.print(view.View{
+		Resources: []view.Resource{
+			{Name: "a-very-long-resource-name", CombinedLog: model.NewLog("hi\n")},
+		},
+	})
+
+	assert.Equal(t, "a-very-long-resourc… │ hi\n", out.String())
+}
+
+func testLogEvent(s string) model.LogEvent {
+	return streamTestLogEvent{ts: time.Now(), msg: []byte(s)}
+}
+
+type streamTestLogEvent struct {
+	ts  time.Time
+	msg []byte
+}
+
+func (e streamTestLogEvent) Time() time.Time { return e.ts }
+func (e streamTestLogEvent) Message() []byte { return e.msg }
+
+func setNoColor(noColor bool) func() {
+	old := color.NoColor
+	color.NoColor = noColor
+	return func() { color.NoColor = old }
+}
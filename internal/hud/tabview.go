@@ -2,8 +2,8 @@ package hud
 
 import (
 	"fmt"
-
-	"github.com/gdamore/tcell"
+	"strings"
+	"time"
 
 	"github.com/windmilleng/tilt/internal/hud/view"
 	"github.com/windmilleng/tilt/internal/model"
@@ -14,38 +14,91 @@ type TabView struct {
 	view      view.View
 	viewState view.ViewState
 	tabState  view.TabState
+	theme     Theme
 }
 
-func NewTabView(v view.View, vState view.ViewState) *TabView {
+func NewTabView(v view.View, vState view.ViewState, theme Theme) *TabView {
 	return &TabView{
 		view:      v,
 		viewState: vState,
 		tabState:  vState.TabState,
+		theme:     theme,
 	}
 }
 
 func (v *TabView) Build() rty.Component {
 	l := rty.NewConcatLayout(rty.DirVert)
 	l.Add(v.buildTabs(false))
+	l.Add(v.buildLog())
+
+	return l
+}
+
+func (v *TabView) buildLog() rty.Component {
+	if v.viewState.LogSearch.Active() {
+		return newLogSearch(v.view, v.viewState).build(v.theme)
+	}
 
 	log := rty.NewTextScrollLayout("log")
-	log.Add(rty.TextString(v.log()))
-	l.Add(log)
+	if !v.viewState.LogNoWrap {
+		log.Add(rty.TextString(v.log()))
+		return log
+	}
 
-	return l
+	for _, line := range strings.Split(strings.TrimSuffix(v.log(), "\n"), "\n") {
+		log.Add(rty.NewStringBuilder().Text(line).BuildTruncated(v.viewState.LogHScroll))
+	}
+	return log
 }
 
 func (v *TabView) log() string {
+	if v.viewState.LogPause.Paused {
+		return v.viewState.LogPause.Text
+	}
+	if v.tabState == view.TabBuildLog && v.buildLogDropped() {
+		return fmt.Sprintf("(Tilt only keeps the logs of the last %d builds)", model.BuildHistoryLogLimit)
+	}
+	return logText(v.source())
+}
+
+// Whether the build log tab shows a build that's too old for us to have kept its log.
+func (v *TabView) buildLogDropped() bool {
+	_, resource := selectedResource(v.view, v.viewState)
+	builds := resourceBuilds(resource)
+	if len(builds) == 0 {
+		return false
+	}
+	build := builds[buildLogIndex(builds, v.viewState.BuildLogStartTime)]
+	if !build.Log.Empty() {
+		return false
+	}
+	for i, b := range resource.BuildHistory {
+		if b.StartTime.Equal(build.StartTime) {
+			return i >= model.BuildHistoryLogLimit
+		}
+	}
+	return false
+}
+
+func logText(log model.Log) string {
+	if !log.Empty() {
+		return log.Tail(logLineCount).String()
+	} else {
+		return "(no logs received)"
+	}
+}
+
+// The log that the current tab shows.
+func (v *TabView) source() model.Log {
 	var ret model.Log
 	switch v.tabState {
 	case view.TabAllLog:
 		ret = v.view.Log
 	case view.TabBuildLog:
 		_, resource := selectedResource(v.view, v.viewState)
-		if !resource.CurrentBuild.Empty() {
-			ret = resource.CurrentBuild.Log
-		} else {
-			ret = resource.LastBuild().Log
+		builds := resourceBuilds(resource)
+		if len(builds) > 0 {
+			ret = builds[buildLogIndex(builds, v.viewState.BuildLogStartTime)].Log
 		}
 	case view.TabPodLog:
 		_, resource := selectedResource(v.view, v.viewState)
@@ -53,12 +106,40 @@ func (v *TabView) log() string {
 			ret = resource.ResourceInfo.RuntimeLog()
 		}
 	}
+	return ret
+}
 
-	if !ret.Empty() {
-		return ret.Tail(logLineCount).String()
-	} else {
-		return "(no logs received)"
+// The resource whose log the current tab shows, or empty for the whole log.
+func (v *TabView) sourceResource() model.ManifestName {
+	if v.tabState == view.TabAllLog {
+		return ""
 	}
+	_, resource := selectedResource(v.view, v.viewState)
+	return resource.Name
+}
+
+// A snapshot of the current tab's log, to show until the user goes back
+// to following it.
+func (v *TabView) pause() view.LogPauseState {
+	source := v.source()
+	return view.LogPauseState{
+		Paused:   true,
+		Tab:      v.tabState,
+		Resource: v.sourceResource(),
+		Text:     logText(source),
+		Offset:   source.TotalLen(),
+	}
+}
+
+// How many lines came into the log since the user paused it.
+func (v *TabView) newLineCount() int {
+	source := v.source()
+	offset := v.viewState.LogPause.Offset
+	if source.TotalLen() < offset {
+		// A new build started, with a new log.
+		offset = 0
+	}
+	return strings.Count(source.Since(offset), "\n")
 }
 
 func (v *TabView) buildTab(text string) rty.Component {
@@ -75,6 +156,7 @@ func (v *TabView) buildTabs(isMax bool) rty.Component {
 	l.Add(rty.TextString("│"))
 	if v.tabState == view.TabBuildLog {
 		l.Add(v.buildTab("2: BUILD LOG"))
+		l.Add(rty.TextString(v.buildLogStatus()))
 	} else {
 		l.Add(v.buildTab("2: build log"))
 	}
@@ -85,8 +167,83 @@ func (v *TabView) buildTabs(isMax bool) rty.Component {
 		l.Add(v.buildTab("3: pod log"))
 	}
 	l.Add(rty.TextString("│ "))
+	if v.viewState.LogSearch.Active() {
+		l.Add(rty.TextString(newLogSearch(v.view, v.viewState).status()))
+	}
+	if v.viewState.LogNoWrap {
+		l.Add(rty.TextString(noWrapStatus(v.viewState)))
+	}
+	if v.viewState.LogPause.Paused {
+		l.Add(rty.TextString(v.pauseStatus()))
+	}
 	l.Add(renderPaneHeader(isMax))
-	result := rty.Bg(l, tcell.ColorWhiteSmoke)
-	result = rty.Fg(result, cText)
+	result := rty.Bg(l, v.theme.Bar)
+	result = rty.Fg(result, v.theme.Text)
 	return result
 }
+
+// The selected resource's builds, newest first, starting with the one in progress.
+func resourceBuilds(res view.Resource) []model.BuildRecord {
+	var builds []model.BuildRecord
+	if !res.CurrentBuild.Empty() {
+		builds = append(builds, res.CurrentBuild)
+	}
+	for _, b := range res.BuildHistory {
+		if !b.Empty() {
+			builds = append(builds, b)
+		}
+	}
+	return builds
+}
+
+// Finds the build that started at startTime, or the latest build if it's not
+// there anymore (or if startTime is zero).
+func buildLogIndex(builds []model.BuildRecord, startTime time.Time) int {
+	if startTime.IsZero() {
+		return 0
+	}
+	for i, b := range builds {
+		if b.StartTime.Equal(startTime) {
+			return i
+		}
+	}
+	return 0
+}
+
+// Which build the build log tab shows, e.g., "✖ 2/5 (, .)" for the second
+// newest of five builds, which failed. Empty when there's only one build.
+func (v *TabView) buildLogStatus() string {
+	_, resource := selectedResource(v.view, v.viewState)
+	builds := resourceBuilds(resource)
+	if len(builds) < 2 {
+		return ""
+	}
+	i := buildLogIndex(builds, v.viewState.BuildLogStartTime)
+	icon := "✔"
+	if builds[i].FinishTime.IsZero() {
+		icon = "…"
+	} else if builds[i].Canceled {
+		icon = "–"
+	} else if builds[i].Error != nil {
+		icon = "✖"
+	}
+	return fmt.Sprintf("%s %d/%d (, .) ", icon, i+1, len(builds))
+}
+
+// Tells the user that the log is paused, how much they're missing, and how
+// to get back to following it.
+func (v *TabView) pauseStatus() string {
+	n := v.newLineCount()
+	if n == 0 {
+		return "paused (F) "
+	}
+	return fmt.Sprintf("%s (F) ", pluralize(n, "new line"))
+}
+
+// Tells the user that long lines are cut off, and how far they've scrolled.
+func noWrapStatus(vs view.ViewState) string {
+	if vs.LogHScroll > 0 {
+		return fmt.Sprintf("no wrap, from col %d ", vs.LogHScroll+1)
+	}
+	return "no wrap "
+}
@@ -0,0 +1,31 @@
+package hud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/hud/view"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestBuildLogOfOldBuild(t *testing.T) {
+	now := clockForTest()
+	var history []model.BuildRecord
+	for i := 0; i < model.BuildHistoryLogLimit+1; i++ {
+		br := model.BuildRecord{StartTime: now.Add(-time.Duration(i) * time.Minute)}
+		if i < model.BuildHistoryLogLimit {
+			br.Log = model.NewLog("Step 1/2\n")
+		}
+		history = append(history, br)
+	}
+	v := view.View{Resources: []view.Resource{{Name: "vigoda", BuildHistory: history}}}
+
+	vs := fakeViewState(1, view.CollapseYes)
+	vs.TabState = view.TabBuildLog
+	assert.Equal(t, "Step 1/2\n", NewTabView(v, vs, ThemeDefault).log())
+
+	vs.BuildLogStartTime = history[model.BuildHistoryLogLimit].StartTime
+	assert.Equal(t, "(Tilt only keeps the logs of the last 2 builds)", NewTabView(v, vs, ThemeDefault).log())
+}
@@ -30,8 +30,8 @@ func deployTimeCell(t time.Time, color tcell.Color) rty.Component {
 		Add(rty.Fg(deployTimeText(t), color))
 }
 
-func middotText() rty.Component {
-	return rty.ColoredString(" • ", cLightText)
+func middotText(theme Theme) rty.Component {
+	return rty.ColoredString(" • ", theme.LightText)
 }
 
 const abbreviatedLogLineCount = 6
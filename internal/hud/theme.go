@@ -0,0 +1,146 @@
+package hud
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell"
+)
+
+// The colors that the HUD draws with.
+type Theme struct {
+	Name string
+
+	Text      tcell.Color
+	LightText tcell.Color
+	Good      tcell.Color
+	Bad       tcell.Color
+	Pending   tcell.Color
+
+	// The background of the status bar and the log pane's header.
+	Bar tcell.Color
+
+	// The background of the narration box, in demos.
+	Narration tcell.Color
+
+	// The backgrounds of log search matches.
+	Match        tcell.Color
+	CurrentMatch tcell.Color
+
+	// Draw everything in the terminal's default colors, and drop the colors
+	// from logs too. See https://no-color.org.
+	NoColor bool
+}
+
+// The theme we've always had: dark text on a light status bar, with the
+// terminal's standard green, red, and yellow for status.
+var ThemeDefault = Theme{
+	Name:         "default",
+	Text:         tcell.Color232,
+	LightText:    tcell.Color243,
+	Good:         tcell.ColorGreen,
+	Bad:          tcell.ColorRed,
+	Pending:      tcell.ColorYellow,
+	Bar:          tcell.ColorWhiteSmoke,
+	Narration:    tcell.ColorLightGrey,
+	Match:        tcell.ColorYellow,
+	CurrentMatch: tcell.ColorOrange,
+}
+
+// Darker status colors, so that pending (yellow) resources don't disappear
+// on a white background.
+var ThemeLight = Theme{
+	Name:         "light",
+	Text:         tcell.Color232,
+	LightText:    tcell.Color240,
+	Good:         tcell.Color28,
+	Bad:          tcell.Color160,
+	Pending:      tcell.Color130,
+	Bar:          tcell.Color252,
+	Narration:    tcell.Color252,
+	Match:        tcell.Color229,
+	CurrentMatch: tcell.Color215,
+}
+
+// Tells good from bad with blue and orange instead of green and red, which
+// look alike to people with red-green colorblindness.
+var ThemeColorblind = Theme{
+	Name:         "colorblind",
+	Text:         tcell.Color232,
+	LightText:    tcell.Color243,
+	Good:         tcell.Color33,
+	Bad:          tcell.Color208,
+	Pending:      tcell.Color226,
+	Bar:          tcell.ColorWhiteSmoke,
+	Narration:    tcell.ColorLightGrey,
+	Match:        tcell.Color226,
+	CurrentMatch: tcell.Color208,
+}
+
+// The default theme, without any colors. Rather than set every color to
+// the terminal default, we strip them out at the screen, so that colors
+// in logs go too.
+var ThemeNone = func() Theme {
+	t := ThemeDefault
+	t.Name = "none"
+	t.NoColor = true
+	return t
+}()
+
+var themes = map[string]Theme{
+	ThemeDefault.Name:    ThemeDefault,
+	ThemeLight.Name:      ThemeLight,
+	ThemeColorblind.Name: ThemeColorblind,
+	ThemeNone.Name:       ThemeNone,
+}
+
+func ThemeNames() []string {
+	var result []string
+	for name := range themes {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Picks a theme by name. With no name, uses the default theme, unless the
+// user has set NO_COLOR.
+func ThemeByName(name string) (Theme, error) {
+	if name == "" {
+		if os.Getenv("NO_COLOR") != "" {
+			return ThemeNone, nil
+		}
+		return ThemeDefault, nil
+	}
+
+	t, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("Unknown HUD theme %q. Valid themes: %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	return t, nil
+}
+
+func (t Theme) statusColor(s resourceStatus) tcell.Color {
+	switch s {
+	case statusGood:
+		return t.Good
+	case statusBad:
+		return t.Bad
+	case statusPending:
+		return t.Pending
+	default:
+		return t.LightText
+	}
+}
+
+// A screen that draws everything in the terminal's default colors.
+// Text attributes, like bold and reverse, still come through.
+type noColorScreen struct {
+	tcell.Screen
+}
+
+func (s noColorScreen) SetContent(x int, y int, mainc rune, combc []rune, style tcell.Style) {
+	s.Screen.SetContent(x, y, mainc, combc, style.Foreground(tcell.ColorDefault).Background(tcell.ColorDefault))
+}
@@ -0,0 +1,86 @@
+package hud
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThemeByName(t *testing.T) {
+	defer os.Unsetenv("NO_COLOR")
+	os.Unsetenv("NO_COLOR")
+
+	theme, err := ThemeByName("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ThemeDefault, theme)
+
+	theme, err = ThemeByName("light")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ThemeLight, theme)
+
+	_, err = ThemeByName("sparkly")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Valid themes: colorblind, default, light, none")
+	}
+
+	os.Setenv("NO_COLOR", "1")
+	theme, err = ThemeByName("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, theme.NoColor)
+
+	// An explicit theme wins over NO_COLOR.
+	theme, err = ThemeByName("colorblind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, theme.NoColor)
+}
+
+func TestThemeStatusColor(t *testing.T) {
+	assert.Equal(t, ThemeColorblind.Bad, ThemeColorblind.statusColor(runtimeStatuses["CrashLoopBackOff"]))
+	assert.Equal(t, ThemeColorblind.Good, ThemeColorblind.statusColor(runtimeStatuses["Running"]))
+	assert.Equal(t, ThemeColorblind.LightText, ThemeColorblind.statusColor(statusNone))
+}
+
+// Each theme needs distinct status colors, so that you can tell at a glance
+// which resources are in error.
+func TestThemeStatusColorsAreDistinct(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, err := ThemeByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		colors := map[tcell.Color]bool{
+			theme.Good:      true,
+			theme.Bad:       true,
+			theme.Pending:   true,
+			theme.LightText: true,
+		}
+		assert.Equal(t, 4, len(colors), "theme %s", name)
+	}
+}
+
+func TestNoColorScreen(t *testing.T) {
+	sim := tcell.NewSimulationScreen("")
+	if err := sim.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Fini()
+
+	s := noColorScreen{sim}
+	s.SetContent(0, 0, 'x', nil, tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorWhite).Bold(true))
+
+	_, _, style, _ := sim.GetContent(0, 0)
+	fg, bg, attrs := style.Decompose()
+	assert.Equal(t, tcell.ColorDefault, fg)
+	assert.Equal(t, tcell.ColorDefault, bg)
+	assert.Equal(t, tcell.AttrBold, attrs&tcell.AttrBold)
+}
@@ -7,3 +7,80 @@ type AppendToTriggerQueueAction struct {
 }
 
 func (AppendToTriggerQueueAction) Action() {}
+
+type CancelBuildAction struct {
+	Name model.ManifestName
+}
+
+func (CancelBuildAction) Action() {}
+
+// Re-apply a resource whose objects someone changed outside of Tilt.
+type ReapplyAction struct {
+	Name model.ManifestName
+}
+
+func (ReapplyAction) Action() {}
+
+// Delete the objects that Tilt deployed for resources that aren't in the Tiltfile anymore.
+type DeleteOrphansAction struct{}
+
+func (DeleteOrphansAction) Action() {}
+
+// Delete a resource's objects, and take it out of the session.
+type TearDownAction struct {
+	Name model.ManifestName
+}
+
+func (TearDownAction) Action() {}
+
+// Rebuild every resource from scratch, dependencies first.
+type TriggerAllAction struct{}
+
+func (TriggerAllAction) Action() {}
+
+// Turn a resource off for now: stop watching, building, and port-forwarding
+// it, and optionally delete its objects.
+type DisableResourceAction struct {
+	Name          model.ManifestName
+	DeleteObjects bool
+}
+
+func (DisableResourceAction) Action() {}
+
+// Turn a resource back on, and rebuild it.
+type EnableResourceAction struct {
+	Name model.ManifestName
+}
+
+func (EnableResourceAction) Action() {}
+
+// Stop building file changes for a resource (or, with no Name, the whole
+// session) until the user resumes. The changes keep piling up in the meantime.
+type PauseUpdatesAction struct {
+	Name model.ManifestName
+}
+
+func (PauseUpdatesAction) Action() {}
+
+// Build the changes that piled up while updates were paused.
+type ResumeUpdatesAction struct {
+	Name model.ManifestName
+}
+
+func (ResumeUpdatesAction) Action() {}
+
+// Restart a resource's pods (or its Docker Compose container) without
+// rebuilding or redeploying it.
+type RestartPodAction struct {
+	Name model.ManifestName
+}
+
+func (RestartPodAction) Action() {}
+
+// Stop showing a resource's last build error and pod restarts, until
+// something new goes wrong.
+type ClearErrorAction struct {
+	Name model.ManifestName
+}
+
+func (ClearErrorAction) Action() {}
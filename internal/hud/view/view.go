@@ -1,6 +1,7 @@
 package view
 
 import (
+	"strings"
 	"time"
 
 	"github.com/windmilleng/tilt/internal/container"
@@ -42,12 +43,47 @@ func (dcInfo DCResourceInfo) Status() string        { return string(dcInfo.Conta
 
 type K8SResourceInfo struct {
 	PodName            string
+	PodNamespace       string
 	PodCreationTime    time.Time
 	PodUpdateStartTime time.Time
 	PodStatus          string
 	PodRestarts        int
 	PodLog             model.Log
 	YAML               string
+
+	// "connected" or "reconnecting", if the resource has port forwards.
+	PortForwardStatus string
+
+	// Human-readable explanations of why the pod is failing.
+	PodAlerts []string
+
+	// Whether the pods passed their readiness probes and the rollout finished.
+	// A running pod isn't serving until it's ready.
+	PodReady bool
+
+	// How much CPU and memory the pod is using (e.g., "250m" and "64Mi"),
+	// or empty if the cluster doesn't have metrics-server.
+	PodCPU    string
+	PodMemory string
+
+	// Warnings about containers that are close to their CPU or memory limits.
+	PodUsageWarnings []string
+
+	// How the objects in the cluster differ from what Tilt applied, if someone
+	// changed them outside of Tilt (e.g., with `kubectl edit`).
+	Drift string
+
+	// Recent k8s events about the resource's objects, most recent first.
+	Events []K8sEvent
+}
+
+type K8sEvent struct {
+	Time    time.Time
+	Object  string
+	Type    string
+	Reason  string
+	Message string
+	Count   int32
 }
 
 var _ ResourceInfoView = K8SResourceInfo{}
@@ -56,6 +92,30 @@ func (K8SResourceInfo) resourceInfoView()             {}
 func (k8sInfo K8SResourceInfo) RuntimeLog() model.Log { return k8sInfo.PodLog }
 func (k8sInfo K8SResourceInfo) Status() string        { return k8sInfo.PodStatus }
 
+// A resource that runs on the user's machine.
+type LocalResourceInfo struct {
+	// Whether the resource has a serve_cmd for Tilt to keep running.
+	HasServeCmd bool
+
+	ServeStatus string
+	PID         int
+	StartTime   time.Time
+	Log         model.Log
+}
+
+var _ ResourceInfoView = LocalResourceInfo{}
+
+func (LocalResourceInfo) resourceInfoView()               {}
+func (localInfo LocalResourceInfo) RuntimeLog() model.Log { return localInfo.Log }
+
+// A resource without a serve_cmd has nothing left running after it updates.
+func (localInfo LocalResourceInfo) Status() string {
+	if !localInfo.HasServeCmd {
+		return "Completed"
+	}
+	return localInfo.ServeStatus
+}
+
 type YAMLResourceInfo struct {
 	K8sResources []string
 }
@@ -87,7 +147,32 @@ type Resource struct {
 	// for a little while.
 	CrashLog model.Log
 
+	// Everything the resource has logged: builds, pods, and events.
+	CombinedLog model.Log
+
 	IsTiltfile bool
+
+	// Whether the resource builds as soon as its files change,
+	// or waits for the user to trigger it.
+	TriggerMode model.TriggerMode
+
+	// The user turned this resource off for now.
+	Disabled bool
+
+	// File changes wait until the user resumes updates, for this resource
+	// or the whole session.
+	UpdatesPaused bool
+
+	// Whether the resource has a readiness check that hasn't passed since
+	// its last deploy, and why the check last failed, if it did.
+	WaitingOnReadinessCheck bool
+	ReadinessCheckError     string
+
+	// If the resource keeps crashing, when we'll rebuild it next.
+	CrashBackoffUntil time.Time
+
+	// The groups the Tiltfile put the resource in, e.g., "frontend".
+	Labels []string
 }
 
 func (r Resource) DockerComposeTarget() DCResourceInfo {
@@ -119,6 +204,16 @@ func (r Resource) IsK8S() bool {
 	return ok
 }
 
+func (r Resource) LocalInfo() LocalResourceInfo {
+	ret, _ := r.ResourceInfo.(LocalResourceInfo)
+	return ret
+}
+
+func (r Resource) IsLocal() bool {
+	_, ok := r.ResourceInfo.(LocalResourceInfo)
+	return ok
+}
+
 func (r Resource) YAMLInfo() YAMLResourceInfo {
 	ret, _ := r.ResourceInfo.(YAMLResourceInfo)
 	return ret
@@ -151,7 +246,7 @@ func (r Resource) DefaultCollapse() bool {
 	}
 
 	autoExpand = autoExpand ||
-		r.LastBuild().Error != nil ||
+		(r.LastBuild().Error != nil && !r.LastBuild().Canceled) ||
 		!r.CrashLog.Empty() ||
 		len(r.LastBuild().Warnings) > 0 ||
 		r.LastBuild().Reason.Has(model.BuildReasonFlagCrash) ||
@@ -177,6 +272,12 @@ type View struct {
 	TriggerMode   model.TriggerMode
 	IsProfiling   bool
 	LogTimestamps bool
+
+	// Why deploys are paused, if the user switched kubeconfig contexts while Tilt was running.
+	KubeContextAlert string
+
+	// The user paused updates for the whole session.
+	UpdatesPaused bool
 }
 
 func (v View) TiltfileErrorMessage() string {
@@ -210,6 +311,123 @@ type ViewState struct {
 	TabState              TabState
 	SelectedIndex         int
 	TiltLogState          TiltLogState
+	LogSearch             LogSearchState
+	ResourceFilter        ResourceFilterState
+
+	// A yes-or-no question, before we do something that's hard to undo
+	// (like deleting objects). Shown until the user answers.
+	ConfirmPrompt string
+
+	// Show the resources in groups, by kind (Kubernetes, Docker Compose, ...).
+	GroupResources bool
+
+	// The groups whose resources are hidden, by group name.
+	CollapsedGroups map[string]bool
+
+	// Cut long log lines off at the edge of the pane, instead of wrapping them.
+	// LogHScroll is how many columns the user has scrolled right.
+	LogNoWrap  bool
+	LogHScroll int
+
+	// Show every error and warning, across all resources, in one list.
+	ShowAlertPane bool
+
+	// The resource to show everything we know about, or empty for none.
+	ResourceDetail model.ManifestName
+
+	// In the resource detail, list every file that triggered each build,
+	// instead of the first few.
+	ResourceDetailAllEdits bool
+
+	// The log pane stops following the log while the user has it scrolled up.
+	LogPause LogPauseState
+
+	// Which of the selected resource's builds the build log tab shows,
+	// by start time. Zero for the latest.
+	BuildLogStartTime time.Time
+}
+
+// While the log pane is paused, it shows the log as it was when the user
+// paused it, so that new lines don't move what they're reading.
+type LogPauseState struct {
+	Paused bool
+
+	// Which log we paused: the tab, and the resource, for tabs that show
+	// one resource's log.
+	Tab      TabState
+	Resource model.ManifestName
+
+	// The log's text when we paused, and how long the log was then
+	// (see model.Log.TotalLen), to count the lines that came in since.
+	Text   string
+	Offset int
+}
+
+// Which resources to show in the resource list.
+type ResourceFilterState struct {
+	// The user is still typing the name filter.
+	Typing bool
+
+	// Only show resources whose names contain this, or with
+	// "label:<label>", only the resources with that label.
+	Name string
+
+	Status StatusFilter
+}
+
+func (s ResourceFilterState) Active() bool {
+	return s.Name != "" || s.Status != StatusFilterAll
+}
+
+const labelFilterPrefix = "label:"
+
+// The label to filter on, if the name filter is "label:<label>".
+func (s ResourceFilterState) Label() (string, bool) {
+	if !strings.HasPrefix(s.Name, labelFilterPrefix) {
+		return "", false
+	}
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(s.Name, labelFilterPrefix))), true
+}
+
+type StatusFilter int
+
+const (
+	StatusFilterAll StatusFilter = iota
+	StatusFilterErrors
+	StatusFilterBuilding
+)
+
+func (f StatusFilter) Next() StatusFilter {
+	return (f + 1) % 3
+}
+
+func (f StatusFilter) String() string {
+	switch f {
+	case StatusFilterErrors:
+		return "errors"
+	case StatusFilterBuilding:
+		return "building"
+	default:
+		return "all"
+	}
+}
+
+// Searching the logs with `/`.
+type LogSearchState struct {
+	// The user is still typing the query.
+	Typing bool
+
+	Query string
+
+	// Search every resource's log, instead of just the selected resource's.
+	AllLogs bool
+
+	// Which match we jumped to (an index into the matching lines).
+	Match int
+}
+
+func (s LogSearchState) Active() bool {
+	return s.Typing || s.Query != ""
 }
 
 type TabState int
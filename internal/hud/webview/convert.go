@@ -48,7 +48,7 @@ func StateToWebView(s store.EngineState) View {
 
 		pendingBuildEdits = ospath.FileListDisplayNames(absWatchDirs, pendingBuildEdits)
 
-		buildHistory := append([]model.BuildRecord{}, ms.BuildHistory...)
+		buildHistory := ms.DisplayedBuildHistory()
 		for i, build := range buildHistory {
 			build.Edits = ospath.FileListDisplayNames(absWatchDirs, build.Edits)
 			buildHistory[i] = build
@@ -82,16 +82,28 @@ func StateToWebView(s store.EngineState) View {
 			Endpoints:          endpoints,
 			PodID:              podID,
 			ResourceInfo:       resourceInfoView(mt),
-			ShowBuildStatus:    len(mt.Manifest.ImageTargets) > 0 || mt.Manifest.IsDC(),
+			ShowBuildStatus:    len(mt.Manifest.ImageTargets) > 0 || mt.Manifest.IsDC() || mt.Manifest.IsLocal(),
 			CombinedLog:        ms.CombinedLog,
+			TriggerMode:        s.TriggerModeForManifest(mt.Manifest),
+			Disabled:           ms.Disabled,
+			UpdatesPaused:      s.UpdatesPausedForManifest(name),
+
+			WaitingOnReadinessCheck: !mt.Manifest.ReadinessCheck.Empty() && !ms.ReadinessCheckPassed,
+			ReadinessCheckError:     ms.ReadinessCheckError,
+			CrashBackoffUntil:       ms.CrashBackoffUntil,
+			Labels:                  mt.Manifest.Labels,
 		}
 
 		r.RuntimeStatus = runtimeStatus(r.ResourceInfo)
+		if r.WaitingOnReadinessCheck && r.RuntimeStatus == RuntimeStatusOK {
+			r.RuntimeStatus = RuntimeStatusPending
+		}
 
 		ret.Resources = append(ret.Resources, r)
 	}
 
 	ret.Log = s.Log
+	ret.UpdatesPaused = s.UpdatesPaused
 	ret.SailEnabled = s.SailEnabled
 	ret.SailURL = s.SailURL
 
@@ -130,6 +142,16 @@ func resourceInfoView(mt *store.ManifestTarget) ResourceInfoView {
 			K8sResources: mt.Manifest.K8sTarget().ResourceNames,
 		}
 	}
+	if mt.Manifest.IsLocal() {
+		lState := mt.State.LocalResourceState()
+		return LocalResourceInfo{
+			HasServeCmd: !mt.Manifest.LocalTarget().ServeCmd.Empty(),
+			ServeStatus: string(lState.Status),
+			PID:         lState.PID,
+			StartTime:   lState.StartTime,
+			Log:         lState.CurrentLog,
+		}
+	}
 	if dcState, ok := mt.State.ResourceState.(dockercompose.State); ok {
 		return NewDCResourceInfo(mt.Manifest.DockerComposeTarget().ConfigPath, dcState.Status, dcState.ContainerID, dcState.Log(), dcState.StartTime)
 	} else {
@@ -139,9 +161,16 @@ func resourceInfoView(mt *store.ManifestTarget) ResourceInfoView {
 			PodCreationTime:    pod.StartedAt,
 			PodUpdateStartTime: pod.UpdateStartTime,
 			PodStatus:          pod.Status,
+			PodReady:           mt.State.K8sReady(mt.Manifest.K8sTarget()),
 			PodRestarts:        pod.ContainerRestarts - pod.OldRestarts,
 			PodLog:             pod.Log(),
 			YAML:               mt.Manifest.K8sTarget().YAML,
+			PortForwardStatus:  string(pod.PortForwardStatus()),
+			PodAlerts:          pod.Alerts(),
+			PodCPU:             pod.CPUUsage(),
+			PodMemory:          pod.MemoryUsage(),
+			PodUsageWarnings:   pod.UsageWarnings(),
+			Drift:              mt.State.Drift,
 		}
 	}
 }
@@ -157,6 +186,12 @@ func runtimeStatus(res ResourceInfoView) RuntimeStatus {
 	if !ok {
 		return RuntimeStatusError
 	}
+
+	// A running pod isn't up until it's ready to serve.
+	k8sInfo, isK8s := res.(K8SResourceInfo)
+	if isK8s && k8sInfo.PodStatus == "Running" && !k8sInfo.PodReady {
+		return RuntimeStatusPending
+	}
 	return result
 }
 
@@ -77,6 +77,14 @@ func TestStateToViewUnresourcedYAMLManifest(t *testing.T) {
 	assert.Equal(t, expectedInfo, r.ResourceInfo)
 }
 
+func TestRuntimeStatusWaitsForReadiness(t *testing.T) {
+	assert.Equal(t, RuntimeStatus(RuntimeStatusPending), runtimeStatus(K8SResourceInfo{PodStatus: "Running"}))
+	assert.Equal(t, RuntimeStatusOK, runtimeStatus(K8SResourceInfo{PodStatus: "Running", PodReady: true}))
+
+	// Completed pods never become ready.
+	assert.Equal(t, RuntimeStatusOK, runtimeStatus(K8SResourceInfo{PodStatus: "Completed"}))
+}
+
 func TestRelativeTiltfilePath(t *testing.T) {
 	es := newState([]model.Manifest{})
 	wd, err := os.Getwd()
@@ -0,0 +1,191 @@
+package webview
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// What changed between two Views, so that we can send the web UI only what
+// changed, instead of re-sending every log on every change.
+type ViewPatch struct {
+	Log *LogPatch `json:",omitempty"`
+
+	// Every resource, in order. The web UI drops resources that aren't here.
+	ResourceNames []model.ManifestName
+
+	// Only the resources that changed.
+	Resources []ResourcePatch `json:",omitempty"`
+
+	// The rest of the View. These are cheap, so we always send them.
+	LogTimestamps bool
+	UpdatesPaused bool
+	SailEnabled   bool
+	SailURL       string
+}
+
+type ResourcePatch struct {
+	Name model.ManifestName
+
+	// The whole resource, without the logs below, if anything besides
+	// those logs changed.
+	Resource *Resource `json:",omitempty"`
+
+	CombinedLog     *LogPatch `json:",omitempty"`
+	RuntimeLog      *LogPatch `json:",omitempty"`
+	CurrentBuildLog *LogPatch `json:",omitempty"`
+
+	// The logs of the builds at the start of Resource.BuildHistory that the
+	// web UI hasn't seen. The other builds keep the logs the web UI already has
+	// for the build with the same StartTime.
+	NewBuildLogs []model.Log `json:",omitempty"`
+}
+
+// Text to add to the end of a log the web UI already has, after dropping
+// Drop bytes off the start of it (because we truncated the log).
+// If Reset is set, Text is the whole log (e.g., because it's a new log).
+type LogPatch struct {
+	Text  string
+	Drop  int  `json:",omitempty"`
+	Reset bool `json:",omitempty"`
+}
+
+func DiffViews(old, new View) ViewPatch {
+	result := ViewPatch{
+		Log:           DiffLogs(old.Log, new.Log, false),
+		LogTimestamps: new.LogTimestamps,
+		UpdatesPaused: new.UpdatesPaused,
+		SailEnabled:   new.SailEnabled,
+		SailURL:       new.SailURL,
+	}
+
+	for _, res := range new.Resources {
+		result.ResourceNames = append(result.ResourceNames, res.Name)
+
+		oldRes, ok := old.Resource(res.Name)
+		if !ok {
+			result.Resources = append(result.Resources, newResourcePatch(res))
+			continue
+		}
+		if p, changed := diffResources(oldRes, res); changed {
+			result.Resources = append(result.Resources, p)
+		}
+	}
+	return result
+}
+
+// Sends the whole resource, and all of its logs.
+func newResourcePatch(res Resource) ResourcePatch {
+	r := withoutStreamingLogs(res)
+	return ResourcePatch{
+		Name:            res.Name,
+		Resource:        &r,
+		CombinedLog:     &LogPatch{Text: res.CombinedLog.String(), Reset: true},
+		RuntimeLog:      &LogPatch{Text: runtimeLog(res).String(), Reset: true},
+		CurrentBuildLog: &LogPatch{Text: res.CurrentBuild.Log.String(), Reset: true},
+		NewBuildLogs:    buildLogs(res.BuildHistory),
+	}
+}
+
+func diffResources(old, new Resource) (ResourcePatch, bool) {
+	p := ResourcePatch{
+		Name:            new.Name,
+		CombinedLog:     DiffLogs(old.CombinedLog, new.CombinedLog, false),
+		RuntimeLog:      DiffLogs(runtimeLog(old), runtimeLog(new), runtimePodName(old) != runtimePodName(new)),
+		CurrentBuildLog: DiffLogs(old.CurrentBuild.Log, new.CurrentBuild.Log, !old.CurrentBuild.StartTime.Equal(new.CurrentBuild.StartTime)),
+	}
+
+	oldJSON, oldErr := json.Marshal(withoutStreamingLogs(old))
+	r := withoutStreamingLogs(new)
+	newJSON, newErr := json.Marshal(r)
+	if oldErr != nil || newErr != nil || !bytes.Equal(oldJSON, newJSON) {
+		p.Resource = &r
+		p.NewBuildLogs = newBuildLogs(old.BuildHistory, new.BuildHistory)
+	}
+
+	changed := p.Resource != nil || p.CombinedLog != nil || p.RuntimeLog != nil || p.CurrentBuildLog != nil
+	return p, changed
+}
+
+// Returns nil if the log didn't change. If isNewLog is set, or the new log
+// isn't just the old log with more on the end, resets the whole log.
+func DiffLogs(old, new model.Log, isNewLog bool) *LogPatch {
+	oldTruncated := old.TotalLen() - old.Len()
+	newTruncated := new.TotalLen() - new.Len()
+	drop := newTruncated - oldTruncated
+	if isNewLog || new.TotalLen() < old.TotalLen() || drop < 0 || drop > old.Len() {
+		return &LogPatch{Text: new.String(), Reset: true}
+	}
+	if new.TotalLen() == old.TotalLen() {
+		return nil
+	}
+	return &LogPatch{Text: new.Since(old.TotalLen()), Drop: drop}
+}
+
+// The logs that grow with every line that a resource prints, and the logs
+// of finished builds, which never change. The patch sends these separately,
+// so that a new log line doesn't re-send (or re-compare) them.
+func withoutStreamingLogs(res Resource) Resource {
+	res.CombinedLog = model.Log{}
+	res.CurrentBuild.Log = model.Log{}
+	if len(res.BuildHistory) > 0 {
+		history := make([]model.BuildRecord, len(res.BuildHistory))
+		for i, b := range res.BuildHistory {
+			b.Log = model.Log{}
+			history[i] = b
+		}
+		res.BuildHistory = history
+	}
+	switch info := res.ResourceInfo.(type) {
+	case K8SResourceInfo:
+		info.PodLog = model.Log{}
+		res.ResourceInfo = info
+	case DCResourceInfo:
+		info.Log = model.Log{}
+		res.ResourceInfo = info
+	case LocalResourceInfo:
+		info.Log = model.Log{}
+		res.ResourceInfo = info
+	}
+	return res
+}
+
+func buildLogs(history []model.BuildRecord) []model.Log {
+	var result []model.Log
+	for _, b := range history {
+		result = append(result, b.Log)
+	}
+	return result
+}
+
+// The logs of the builds that are new since the old history. New builds go
+// on the front, so that's everything up to the last build that we haven't sent.
+func newBuildLogs(old, new []model.BuildRecord) []model.Log {
+	sent := make(map[int64]bool, len(old))
+	for _, b := range old {
+		sent[b.StartTime.UnixNano()] = true
+	}
+	n := 0
+	for i, b := range new {
+		if !sent[b.StartTime.UnixNano()] {
+			n = i + 1
+		}
+	}
+	return buildLogs(new[:n])
+}
+
+func runtimeLog(res Resource) model.Log {
+	if res.ResourceInfo == nil {
+		return model.Log{}
+	}
+	return res.ResourceInfo.RuntimeLog()
+}
+
+// A new pod means a new pod log.
+func runtimePodName(res Resource) string {
+	if info, ok := res.ResourceInfo.(K8SResourceInfo); ok {
+		return info.PodName
+	}
+	return ""
+}
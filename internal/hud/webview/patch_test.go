@@ -0,0 +1,142 @@
+package webview
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/store"
+)
+
+func TestMarshalViewPatch(t *testing.T) {
+	assertCanMarshal(t, reflect.TypeOf(ViewPatch{}), reflect.TypeOf(ViewPatch{}))
+}
+
+func TestDiffLogs(t *testing.T) {
+	log := model.NewLog("hello\n")
+	assert.Nil(t, DiffLogs(log, log, false))
+
+	appended := appendLog(log, "world\n")
+	assert.Equal(t, &LogPatch{Text: "world\n"}, DiffLogs(log, appended, false))
+	assert.Equal(t, &LogPatch{Text: "hello\nworld\n", Reset: true}, DiffLogs(log, appended, true))
+
+	// The log started over.
+	assert.Equal(t, &LogPatch{Text: "bye\n", Reset: true}, DiffLogs(appended, model.NewLog("bye\n"), false))
+}
+
+func TestDiffLogsDropsTruncatedBytes(t *testing.T) {
+	line := strings.Repeat("x", 999) + "\n"
+	log := model.Log{}
+	for log.Len()+len(line) <= model.MaxLogLength() {
+		log = appendLog(log, line)
+	}
+
+	// The log is full, so each new line pushes an old one off the front.
+	full := appendLog(log, "a\n")
+	assert.Equal(t, &LogPatch{Text: "a\n", Drop: len(line)}, DiffLogs(log, full, false))
+
+	next := appendLog(full, "b\n")
+	assert.Equal(t, &LogPatch{Text: "b\n"}, DiffLogs(full, next, false))
+}
+
+func TestDiffViewsSendsOnlyNewBuildLogs(t *testing.T) {
+	first := model.BuildRecord{StartTime: time.Unix(1, 0), Log: model.NewLog("build 1\n")}
+	second := model.BuildRecord{StartTime: time.Unix(2, 0), Log: model.NewLog("build 2\n")}
+	old := View{Resources: []Resource{{Name: "fe", BuildHistory: []model.BuildRecord{first}}}}
+	new := View{Resources: []Resource{{Name: "fe", BuildHistory: []model.BuildRecord{second, first}}}}
+
+	p := DiffViews(old, new)
+	if !assert.Equal(t, 1, len(p.Resources)) {
+		return
+	}
+	fe := p.Resources[0]
+	if assert.NotNil(t, fe.Resource) {
+		assert.Equal(t, 2, len(fe.Resource.BuildHistory))
+		assert.Equal(t, "", fe.Resource.BuildHistory[0].Log.String())
+		assert.Equal(t, "", fe.Resource.BuildHistory[1].Log.String())
+	}
+	assert.Equal(t, []model.Log{second.Log}, fe.NewBuildLogs)
+
+	// The build logs didn't change, so there's nothing to send.
+	assert.Equal(t, 0, len(DiffViews(new, new).Resources))
+
+	// We don't touch the view's own copy of the history.
+	assert.Equal(t, "build 2\n", new.Resources[0].BuildHistory[0].Log.String())
+}
+
+func TestDiffViewsOnlySendsChangedResources(t *testing.T) {
+	old := View{
+		Log: model.NewLog("hello\n"),
+		Resources: []Resource{
+			{Name: "fe", CombinedLog: model.NewLog("fe\n")},
+			{Name: "be", CombinedLog: model.NewLog("be\n")},
+		},
+	}
+	new := View{
+		Log: appendLog(old.Log, "world\n"),
+		Resources: []Resource{
+			{Name: "fe", CombinedLog: appendLog(old.Resources[0].CombinedLog, "more\n")},
+			{Name: "be", CombinedLog: old.Resources[1].CombinedLog},
+			{Name: "db", CombinedLog: model.NewLog("db\n")},
+		},
+	}
+
+	p := DiffViews(old, new)
+	assert.Equal(t, &LogPatch{Text: "world\n"}, p.Log)
+	assert.Equal(t, []model.ManifestName{"fe", "be", "db"}, p.ResourceNames)
+	if !assert.Equal(t, 2, len(p.Resources)) {
+		return
+	}
+
+	// Only fe's log changed, so we don't send the rest of fe.
+	fe := p.Resources[0]
+	assert.Equal(t, model.ManifestName("fe"), fe.Name)
+	assert.Nil(t, fe.Resource)
+	assert.Equal(t, &LogPatch{Text: "more\n"}, fe.CombinedLog)
+
+	// db is new, so we send all of it.
+	db := p.Resources[1]
+	assert.Equal(t, model.ManifestName("db"), db.Name)
+	if assert.NotNil(t, db.Resource) {
+		assert.Equal(t, "", db.Resource.CombinedLog.String())
+	}
+	assert.Equal(t, &LogPatch{Text: "db\n", Reset: true}, db.CombinedLog)
+}
+
+func TestDiffViewsSendsChangedFields(t *testing.T) {
+	start := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := View{Resources: []Resource{{
+		Name:         "fe",
+		ResourceInfo: K8SResourceInfo{PodName: "fe-1", PodLog: model.NewLog("pod 1\n")},
+	}}}
+	new := View{Resources: []Resource{{
+		Name:         "fe",
+		CurrentBuild: model.BuildRecord{StartTime: start, Log: model.NewLog("building\n")},
+		ResourceInfo: K8SResourceInfo{PodName: "fe-2", PodLog: model.NewLog("pod 2\n")},
+	}}}
+
+	p := DiffViews(old, new)
+	if !assert.Equal(t, 1, len(p.Resources)) {
+		return
+	}
+	fe := p.Resources[0]
+	if assert.NotNil(t, fe.Resource) {
+		assert.Equal(t, start, fe.Resource.CurrentBuild.StartTime)
+		assert.Equal(t, "", fe.Resource.CurrentBuild.Log.String())
+		assert.Equal(t, "fe-2", fe.Resource.ResourceInfo.(K8SResourceInfo).PodName)
+		assert.Equal(t, "", fe.Resource.ResourceInfo.RuntimeLog().String())
+	}
+	assert.Nil(t, fe.CombinedLog)
+	assert.Equal(t, &LogPatch{Text: "building\n", Reset: true}, fe.CurrentBuildLog)
+
+	// A new pod has a new log, even if it's longer than the old one.
+	assert.Equal(t, &LogPatch{Text: "pod 2\n", Reset: true}, fe.RuntimeLog)
+}
+
+func appendLog(log model.Log, s string) model.Log {
+	return model.AppendLog(log, store.LogEvent{Timestamp: time.Now(), Msg: []byte(s)}, false)
+}
@@ -47,6 +47,28 @@ type K8SResourceInfo struct {
 	PodRestarts        int
 	PodLog             model.Log
 	YAML               string
+
+	// "connected" or "reconnecting", if the resource has port forwards.
+	PortForwardStatus string
+
+	// Human-readable explanations of why the pod is failing.
+	PodAlerts []string
+
+	// Whether the pods passed their readiness probes and the rollout finished.
+	// A running pod isn't serving until it's ready.
+	PodReady bool
+
+	// How much CPU and memory the pod is using (e.g., "250m" and "64Mi"),
+	// or empty if the cluster doesn't have metrics-server.
+	PodCPU    string
+	PodMemory string
+
+	// Warnings about containers that are close to their CPU or memory limits.
+	PodUsageWarnings []string
+
+	// How the objects in the cluster differ from what Tilt applied, if someone
+	// changed them outside of Tilt (e.g., with `kubectl edit`).
+	Drift string
 }
 
 var _ ResourceInfoView = K8SResourceInfo{}
@@ -55,6 +77,30 @@ func (K8SResourceInfo) resourceInfoView()             {}
 func (k8sInfo K8SResourceInfo) RuntimeLog() model.Log { return k8sInfo.PodLog }
 func (k8sInfo K8SResourceInfo) Status() string        { return k8sInfo.PodStatus }
 
+// A resource that runs on the user's machine.
+type LocalResourceInfo struct {
+	// Whether the resource has a serve_cmd for Tilt to keep running.
+	HasServeCmd bool
+
+	ServeStatus string
+	PID         int
+	StartTime   time.Time
+	Log         model.Log
+}
+
+var _ ResourceInfoView = LocalResourceInfo{}
+
+func (LocalResourceInfo) resourceInfoView()               {}
+func (localInfo LocalResourceInfo) RuntimeLog() model.Log { return localInfo.Log }
+
+// A resource without a serve_cmd has nothing left running after it updates.
+func (localInfo LocalResourceInfo) Status() string {
+	if !localInfo.HasServeCmd {
+		return "Completed"
+	}
+	return localInfo.ServeStatus
+}
+
 type YAMLResourceInfo struct {
 	K8sResources []string
 }
@@ -90,6 +136,37 @@ type Resource struct {
 	IsTiltfile      bool
 	ShowBuildStatus bool // if true, we show status & time in 'Build Status'; else, "N/A"
 	CombinedLog     model.Log
+
+	// Whether the resource builds as soon as its files change,
+	// or waits for the user to trigger it.
+	TriggerMode model.TriggerMode
+
+	// The user turned this resource off for now.
+	Disabled bool
+
+	// File changes wait until the user resumes updates, for this resource
+	// or the whole session.
+	UpdatesPaused bool
+
+	// Whether the resource has a readiness check that hasn't passed since
+	// its last deploy, and why the check last failed, if it did.
+	WaitingOnReadinessCheck bool
+	ReadinessCheckError     string
+
+	// If the resource keeps crashing, when we'll rebuild it next.
+	CrashBackoffUntil time.Time
+
+	// The groups the Tiltfile put the resource in, e.g., "frontend".
+	Labels []string
+}
+
+func (r Resource) HasLabel(label string) bool {
+	for _, l := range r.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
 }
 
 func (r Resource) LastBuild() model.BuildRecord {
@@ -112,10 +189,30 @@ type View struct {
 	Resources     []Resource
 	LogTimestamps bool
 
+	// The user paused updates for the whole session.
+	UpdatesPaused bool
+
 	SailEnabled bool
 	SailURL     string
 }
 
+// Keeps only the log lines that match the filter. The global log mixes
+// every resource's logs with Tilt's, so only the level applies to it.
+func (v View) FilterLogs(f model.LogFilter) View {
+	if f.Empty() {
+		return v
+	}
+
+	v.Log = v.Log.Filter(model.LogFilter{MinLevel: f.MinLevel})
+	resources := make([]Resource, len(v.Resources))
+	for i, res := range v.Resources {
+		res.CombinedLog = res.CombinedLog.Filter(f)
+		resources[i] = res
+	}
+	v.Resources = resources
+	return v
+}
+
 func (v View) Resource(n model.ManifestName) (Resource, bool) {
 	for _, res := range v.Resources {
 		if res.Name == n {
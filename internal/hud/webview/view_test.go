@@ -6,6 +6,10 @@ import (
 	"testing"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
 )
 
 // The view data model is not allowed to have any private properties,
@@ -14,6 +18,27 @@ func TestMarshalView(t *testing.T) {
 	assertCanMarshal(t, reflect.TypeOf(View{}), reflect.TypeOf(View{}))
 }
 
+func TestFilterLogs(t *testing.T) {
+	v := View{
+		Log: model.NewLog("fe\nERROR: fe failed\n"),
+		Resources: []Resource{
+			{Name: "fe", CombinedLog: model.NewLog("Building\nERROR: fe failed\n")},
+		},
+	}
+
+	filtered := v.FilterLogs(model.LogFilter{MinLevel: model.LogLevelError})
+	assert.Equal(t, "ERROR: fe failed\n", filtered.Log.String())
+	assert.Equal(t, "ERROR: fe failed\n", filtered.Resources[0].CombinedLog.String())
+
+	// Resource logs written by Tilt itself count as "tilt".
+	filtered = v.FilterLogs(model.LogFilter{Source: model.LogSourceRuntime})
+	assert.Equal(t, v.Log.String(), filtered.Log.String())
+	assert.Equal(t, "", filtered.Resources[0].CombinedLog.String())
+
+	// The original view is untouched.
+	assert.Equal(t, "Building\nERROR: fe failed\n", v.Resources[0].CombinedLog.String())
+}
+
 // v: the type to check.
 // owner: the owner of this field, for display purposes.
 func assertCanMarshal(t *testing.T, v reflect.Type, owner reflect.Type) {
@@ -21,6 +21,10 @@ func (fcf fileChangeFilter) Matches(f string, isDir bool) (bool, error) {
 	return fcf.ignoreMatchers.Matches(f, isDir)
 }
 
+func (fcf fileChangeFilter) MatchesEntireDir(f string) (bool, error) {
+	return model.MatchesEntireDir(fcf.ignoreMatchers, f)
+}
+
 type repoTarget interface {
 	LocalRepos() []model.LocalGitRepo
 	Dockerignores() []model.Dockerignore
@@ -60,6 +64,11 @@ type IgnorableTarget interface {
 	IgnoredLocalDirectories() []string
 }
 
+// Targets that know which of their files can't affect the build.
+type unreadContextTarget interface {
+	UnreadContextMatcher() model.PathMatcher
+}
+
 // Filter out files that should not trigger new builds.
 func CreateFileChangeFilter(m IgnorableTarget) (model.PathMatcher, error) {
 	matchers := []model.PathMatcher{}
@@ -82,6 +91,9 @@ func CreateFileChangeFilter(m IgnorableTarget) (model.PathMatcher, error) {
 		}
 		matchers = append(matchers, dm)
 	}
+	if ut, ok := m.(unreadContextTarget); ok {
+		matchers = append(matchers, ut.UnreadContextMatcher())
+	}
 
 	// Filter out spurious changes that we don't want to rebuild on, like IDE
 	// temp/lock files.
@@ -149,3 +161,7 @@ func newDirectoryMatcher(dir string) (directoryMatcher, error) {
 func (d directoryMatcher) Matches(p string, isDir bool) (bool, error) {
 	return ospath.IsChild(d.dir, p), nil
 }
+
+func (d directoryMatcher) MatchesEntireDir(p string) (bool, error) {
+	return d.Matches(p, true)
+}
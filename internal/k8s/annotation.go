@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// Adds the given annotations to the entity and to the pod templates inside it,
+// replacing any existing annotations with the same keys.
+func InjectAnnotations(entity K8sEntity, annotations []model.LabelPair) (K8sEntity, error) {
+	if len(annotations) == 0 {
+		return entity, nil
+	}
+
+	entity = entity.DeepCopy()
+
+	// Don't modify persistent volume claims
+	// because they're supposed to be immutable.
+	pvc := reflect.TypeOf(v1.PersistentVolumeClaim{})
+	metas, err := extractObjectMetas(&entity, func(v reflect.Value) bool {
+		return v.Type() != pvc
+	})
+	if err != nil {
+		return K8sEntity{}, err
+	}
+
+	for _, meta := range metas {
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string, len(annotations))
+		}
+		for _, a := range annotations {
+			meta.Annotations[a.Key] = a.Value
+		}
+	}
+
+	if u, ok := entity.Obj.(*unstructured.Unstructured); ok {
+		injectUnstructuredAnnotations(u, annotations)
+	}
+	return entity, nil
+}
+
+// see notes on injectUnstructuredLabels
+func injectUnstructuredAnnotations(u *unstructured.Unstructured, annotations []model.LabelPair) {
+	objAnnotations := u.GetAnnotations()
+	if objAnnotations == nil {
+		objAnnotations = make(map[string]string, len(annotations))
+	}
+	for _, a := range annotations {
+		objAnnotations[a.Key] = a.Value
+	}
+	u.SetAnnotations(objAnnotations)
+
+	for _, s := range findUnstructuredPodSpecs(u.UnstructuredContent()) {
+		if s.template == nil {
+			continue
+		}
+
+		meta := s.templateMetadata()
+		templateAnnotations, ok := meta["annotations"].(map[string]interface{})
+		if !ok {
+			templateAnnotations = make(map[string]interface{}, len(annotations))
+		}
+		for _, a := range annotations {
+			templateAnnotations[a.Key] = a.Value
+		}
+		meta["annotations"] = templateAnnotations
+	}
+}
@@ -0,0 +1,62 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestInjectAnnotationsDeployment(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SanchoYAML)
+	newEntity, err := InjectAnnotations(entity, []model.LabelPair{
+		{Key: "example.com/cost-center", Value: "1234"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := SerializeYAML([]K8sEntity{newEntity})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// We expect both the Deployment and the PodTemplate to get the annotation.
+	assert.Equal(t, 2, strings.Count(result, "example.com/cost-center: \"1234\""))
+}
+
+func TestInjectAnnotationsCustomResource(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.OperatorAppYAML)
+	newEntity, err := InjectAnnotations(entity, []model.LabelPair{
+		{Key: "team", Value: "frontend"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := ExtractPodTemplateSpec(newEntity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 1, len(templates)) {
+		assert.Equal(t, map[string]string{"team": "frontend"}, templates[0].Annotations)
+	}
+
+	result, err := SerializeYAML([]K8sEntity{newEntity})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Inject in the top-level metadata and the pod template.
+	assert.Equal(t, 2, strings.Count(result, "team: frontend"))
+
+	// The original entity isn't modified.
+	templates, err = ExtractPodTemplateSpec(entity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, templates[0].Annotations)
+}
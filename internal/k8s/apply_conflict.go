@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Returned by server-side apply when other field managers
+// (e.g., an operator or a mutating admission webhook) own fields that Tilt is trying to set.
+type ApplyConflictError struct {
+	// One line per conflict, as reported by kubectl, e.g.,
+	// conflict with "kube-controller-manager" using apps/v1: .spec.replicas
+	Conflicts []string
+}
+
+func (e ApplyConflictError) Error() string {
+	return fmt.Sprintf("kubectl apply --server-side: fields are owned by other field managers:\n  %s\n"+
+		"To take ownership of these fields, set force_conflicts=True in k8s_server_side_apply()",
+		strings.Join(e.Conflicts, "\n  "))
+}
+
+func IsApplyConflictError(err error) bool {
+	_, ok := err.(ApplyConflictError)
+	return ok
+}
+
+// kubectl reports conflicts like:
+//
+// error: Apply failed with 2 conflicts: conflicts with "webhook":
+// - .metadata.labels.team
+// - .spec.replicas
+//
+// or, with only one field:
+//
+// error: Apply failed with 1 conflict: conflict with "webhook" using apps/v1: .spec.replicas
+func applyConflicts(stderr string) []string {
+	var result []string
+	inConflicts := false
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		i := strings.Index(line, "Apply failed with ")
+		if i != -1 {
+			inConflicts = true
+			header := line[i:]
+			colon := strings.Index(header, ": ")
+			if colon != -1 {
+				result = append(result, header[colon+2:])
+			}
+			continue
+		}
+
+		if inConflicts && strings.HasPrefix(line, "- ") {
+			result = append(result, line)
+			continue
+		}
+		inConflicts = false
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// How long to wait for the cluster to start serving the kinds that new
+// CustomResourceDefinitions define.
+const crdEstablishedTimeout = time.Minute
+
+// Kinds that call out to a workload (e.g., to validate other objects), so we don't
+// create them until the workload exists, or they'd block everything else.
+var workloadDependentKinds = map[string]bool{
+	"APIService":                     true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+const (
+	applyTierNamespace = iota
+	applyTierCRD
+	applyTierDependency
+	applyTierWorkload
+	applyTierLast
+)
+
+// Sorts entities into the order we should apply them in, so that nothing
+// is created before the things that it refers to:
+//
+// 1) Namespaces.
+// 2) CustomResourceDefinitions.
+// 3) Other cluster-scoped objects, and the config, storage, and permissions that workloads use.
+// 4) Workloads, and everything else built in to Kubernetes.
+// 5) Custom resources, and webhooks and API services that call out to workloads.
+//
+// The sort is stable, so entities in the same tier keep the order they were declared in.
+func SortedEntities(entities []K8sEntity) []K8sEntity {
+	result := append([]K8sEntity{}, entities...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return applyTier(result[i]) < applyTier(result[j])
+	})
+	return result
+}
+
+func applyTier(e K8sEntity) int {
+	kind := ""
+	if e.Kind != nil {
+		kind = e.Kind.Kind
+	}
+
+	switch {
+	case kind == "Namespace":
+		return applyTierNamespace
+	case kind == "CustomResourceDefinition":
+		return applyTierCRD
+	case workloadDependentKinds[kind]:
+		return applyTierLast
+	case clusterScopedKinds[kind], workloadDependencyKinds[kind]:
+		return applyTierDependency
+	}
+
+	// Kinds that client-go doesn't know about are parsed as unstructured.
+	if _, ok := e.Obj.(*unstructured.Unstructured); ok {
+		return applyTierLast
+	}
+	return applyTierWorkload
+}
+
+// kubectl looks up every kind it's applying before it applies anything, so
+// custom resources fail with "no matches for kind" if their definitions are
+// in the same apply. Splits off the entities that need to wait until
+// the definitions are established.
+//
+// Returns the entities to apply first, the names of the CRDs to wait for,
+// and the entities to apply after they're established.
+func splitOnCRDs(sorted []K8sEntity) ([]K8sEntity, []string, []K8sEntity) {
+	var crds []string
+	for i, e := range sorted {
+		if applyTier(e) == applyTierCRD {
+			crds = append(crds, e.Name())
+		}
+		if applyTier(e) == applyTierLast {
+			if len(crds) == 0 {
+				return sorted, nil, nil
+			}
+			return sorted[:i], crds, sorted[i:]
+		}
+	}
+	return sorted, nil, nil
+}
+
+func (k K8sClient) waitForCRDsEstablished(ctx context.Context, names []string) error {
+	args := []string{"wait", "--for", "condition=established", fmt.Sprintf("--timeout=%ds", int(crdEstablishedTimeout.Seconds()))}
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("crd/%s", name))
+	}
+
+	_, stderr, err := k.kubectlRunner.exec(ctx, args)
+	if err != nil {
+		return errors.Wrapf(err, "waiting for CustomResourceDefinitions to be established:\nstderr: %s", stderr)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestSortedEntities(t *testing.T) {
+	entities, err := ParseYAMLFromString(yamlJoin(
+		testyaml.SanchoYAML,
+		testyaml.CRDYAML,
+		testyaml.SecretYaml,
+		testyaml.MyNamespaceYAML,
+		testyaml.OperatorAppYAML,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var kinds []string
+	for _, e := range SortedEntities(entities) {
+		kinds = append(kinds, e.Kind.Kind)
+	}
+	assert.Equal(t, []string{"Namespace", "CustomResourceDefinition", "Secret", "Deployment", "Project", "App"}, kinds)
+}
+
+func TestSplitOnCRDs(t *testing.T) {
+	entities, err := ParseYAMLFromString(yamlJoin(testyaml.SanchoYAML, testyaml.CRDYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, crds, rest := splitOnCRDs(SortedEntities(entities))
+	assert.Equal(t, 2, len(first))
+	assert.Equal(t, []string{"projects.example.martin-helmich.de"}, crds)
+	if assert.Equal(t, 1, len(rest)) {
+		assert.Equal(t, "Project", rest[0].Kind.Kind)
+	}
+
+	// Without a CRD in the same apply, the custom resources' kinds are already served.
+	entities, err = ParseYAMLFromString(yamlJoin(testyaml.SanchoYAML, testyaml.OperatorAppYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, crds, rest = splitOnCRDs(SortedEntities(entities))
+	assert.Equal(t, 2, len(first))
+	assert.Empty(t, crds)
+	assert.Empty(t, rest)
+}
+
+func yamlJoin(yamls ...string) string {
+	result := ""
+	for _, y := range yamls {
+		result += "\n---\n" + y
+	}
+	return result
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/browser"
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/labels"
@@ -26,6 +27,7 @@ import (
 	apiv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
 
 	// Client auth plugins! They will auto-init if we import them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -60,14 +62,48 @@ type Client interface {
 	// we might need to fallback to deleting and re-creating them.
 	Upsert(ctx context.Context, entities []K8sEntity) error
 
-	// Deletes all given entities.
+	// Like Upsert, but uses server-side apply, with Tilt as the field manager.
+	//
+	// If another field manager owns a field that we're changing, returns an ApplyConflictError,
+	// unless forceConflicts is set, in which case Tilt takes ownership of the field.
+	ServerSideApply(ctx context.Context, entities []K8sEntity, forceConflicts bool) error
+
+	// Deletes all given entities, and waits for their finalizers to finish.
 	//
 	// Currently ignores any "not found" errors, because that seems like the correct
 	// behavior for our use cases.
 	Delete(ctx context.Context, entities []K8sEntity) error
 
+	ListVolumeClaims(ctx context.Context, n Namespace) ([]v1.PersistentVolumeClaim, error)
+
+	// Lists the objects in the namespace that match the selector.
+	ListObjects(ctx context.Context, n Namespace, ls labels.Selector) ([]K8sEntity, error)
+
+	// The namespace of the kube context, where objects that don't say
+	// what namespace they're in go.
+	ConfigNamespace() Namespace
+
+	// Creates the namespace if it doesn't exist, marking it as created by Tilt.
+	CreateNamespaceIfMissing(ctx context.Context, n Namespace) error
+
+	// Deletes the namespace, but only if Tilt created it.
+	DeleteNamespaceIfCreatedByTilt(ctx context.Context, n Namespace) error
+
+	// Installs or upgrades a Helm release, setting the given values to the images we built.
+	HelmUpgrade(ctx context.Context, release model.HelmRelease, imageValues []string) error
+
+	// Uninstalls a Helm release. Ignores releases that aren't installed.
+	HelmUninstall(ctx context.Context, release model.HelmRelease) error
+
+	// Gets a Helm release out of a pending state that an unfinished install
+	// or upgrade left it in, so that we can upgrade it again.
+	HelmClearPending(ctx context.Context, release model.HelmRelease) error
+
 	PodByID(ctx context.Context, podID PodID, n Namespace) (*v1.Pod, error)
 
+	// Lists the pods in the namespace that match the selector.
+	ListPods(ctx context.Context, n Namespace, ls labels.Selector) ([]v1.Pod, error)
+
 	// Creates a channel where all changes to the pod are brodcast.
 	// Takes a pod as input, to indicate the version of the pod where we start watching.
 	WatchPod(ctx context.Context, pod *v1.Pod) (watch.Interface, error)
@@ -76,17 +112,44 @@ type Client interface {
 	ContainerLogs(ctx context.Context, podID PodID, cName container.Name, n Namespace, startTime time.Time) (io.ReadCloser, error)
 
 	// Opens a tunnel to the specified pod+port. Returns the tunnel's local port and a function that closes the tunnel
-	ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (localPort int, closer func(), err error)
+	ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (PortForward, error)
+
+	// Returns the ready pods behind a Service, with the pod ports that the given Service port maps to.
+	ServiceEndpoints(ctx context.Context, n Namespace, name string, port int) ([]ServiceEndpoint, error)
 
 	WatchPods(ctx context.Context, lps labels.Selector) (<-chan *v1.Pod, error)
 
 	WatchServices(ctx context.Context, lps []model.LabelPair) (<-chan *v1.Service, error)
 
+	// Watches the events in the namespace.
+	WatchEvents(ctx context.Context, n Namespace) (<-chan *v1.Event, error)
+
+	WatchReplicaSets(ctx context.Context, ls labels.Selector) (<-chan *appsv1.ReplicaSet, error)
+
+	WatchIngresses(ctx context.Context, ls labels.Selector) (<-chan IngressEvent, error)
+
 	ConnectedToCluster(ctx context.Context) error
 
+	// Asks the cluster whether we (i.e., the user we connect as) have each of the permissions.
+	CheckPermissions(ctx context.Context, perms []Permission) ([]PermissionCheck, error)
+
+	// Returns how the objects in the cluster differ from the given entities,
+	// or the empty string if they don't.
+	Diff(ctx context.Context, entities []K8sEntity, serverSide bool) (string, error)
+
+	// Returns the CPU and memory use of the pods in the namespace, from metrics-server.
+	// Returns ErrMetricsUnavailable if the cluster doesn't have metrics-server.
+	PodMetrics(ctx context.Context, ns Namespace) ([]PodMetrics, error)
+
 	ContainerRuntime(ctx context.Context) container.Runtime
 
 	Exec(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error
+
+	// Like Exec, but runs the command in a TTY, so that it can be used interactively
+	// (e.g., to open a shell). With a TTY, the command's stderr goes to stdout.
+	//
+	// sizes reports changes to the size of the local terminal. May be nil.
+	ExecTTY(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, sizes remotecommand.TerminalSizeQueue) error
 }
 
 type K8sClient struct {
@@ -98,11 +161,13 @@ type K8sClient struct {
 	configNamespace Namespace
 	clientSet       kubernetes.Interface
 	runtimeAsync    *runtimeAsync
+	helmRunner      helmRunner
+	informers       *informerFactories
 }
 
 var _ Client = K8sClient{}
 
-type PortForwarder func(ctx context.Context, restConfig *rest.Config, core apiv1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), err error)
+type PortForwarder func(ctx context.Context, restConfig *rest.Config, core apiv1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), done <-chan error, err error)
 
 func ProvideK8sClient(
 	ctx context.Context,
@@ -110,6 +175,7 @@ func ProvideK8sClient(
 	pf PortForwarder,
 	configNamespace Namespace,
 	runner kubectlRunner,
+	helm helmRunner,
 	clientLoader clientcmd.ClientConfig) Client {
 	if env == EnvNone {
 		// No k8s, so no need to get any further configs
@@ -143,9 +209,18 @@ func ProvideK8sClient(
 		configNamespace: configNamespace,
 		clientSet:       clientset,
 		runtimeAsync:    runtimeAsync,
+		helmRunner:      helm,
+		informers:       newInformerFactories(),
 	}
 }
 
+func (k K8sClient) ConfigNamespace() Namespace {
+	if k.configNamespace == "" {
+		return DefaultNamespace
+	}
+	return k.configNamespace
+}
+
 func ServiceURL(service *v1.Service, ip NodeIP) (*url.URL, error) {
 	status := service.Status
 
@@ -201,22 +276,64 @@ func (k K8sClient) Upsert(ctx context.Context, entities []K8sEntity) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "daemon-k8sUpsert")
 	defer span.Finish()
 
+	return k.upsert(ctx, entities, []string{"apply"})
+}
+
+// The field manager that owns the fields Tilt sets with server-side apply.
+const TiltFieldManager = "tilt"
+
+func (k K8sClient) ServerSideApply(ctx context.Context, entities []K8sEntity, forceConflicts bool) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "daemon-k8sServerSideApply")
+	defer span.Finish()
+
+	applyArgs := []string{"apply", "--server-side", "--field-manager=" + TiltFieldManager}
+	if forceConflicts {
+		applyArgs = append(applyArgs, "--force-conflicts")
+	}
+	return k.upsert(ctx, entities, applyArgs)
+}
+
+func (k K8sClient) upsert(ctx context.Context, entities []K8sEntity, applyArgs []string) error {
 	l := logger.Get(ctx)
 	prefix := logger.Blue(l).Sprint("  │ ")
 	l.Infof("%sApplying via kubectl", prefix)
 
-	immutable := ImmutableEntities(entities)
-	if len(immutable) > 0 {
-		_, stderr, err := k.actOnEntities(ctx, []string{"replace", "--force"}, immutable)
-		if err != nil {
-			return errors.Wrapf(err, "kubectl replace:\nstderr: %s", stderr)
-		}
+	first, crds, rest := splitOnCRDs(SortedEntities(entities))
+	err := k.applyEntities(ctx, first, applyArgs)
+	if err != nil {
+		return err
+	}
+
+	if len(crds) == 0 {
+		return nil
+	}
+
+	l.Infof("%sWaiting for CustomResourceDefinitions to be established", prefix)
+	err = k.waitForCRDsEstablished(ctx, crds)
+	if err != nil {
+		return err
 	}
+	return k.applyEntities(ctx, rest, applyArgs)
+}
+
+func (k K8sClient) applyEntities(ctx context.Context, entities []K8sEntity, applyArgs []string) error {
+	l := logger.Get(ctx)
+	prefix := logger.Blue(l).Sprint("  │ ")
 
+	// Apply the mutable entities first, because they include the namespaces
+	// and config that the immutable ones (like Jobs) need.
 	mutable := MutableEntities(entities)
 	if len(mutable) > 0 {
-		_, stderr, err := k.actOnEntities(ctx, []string{"apply"}, mutable)
+		_, stderr, err := k.actOnEntities(ctx, applyArgs, mutable)
 		if err != nil {
+			if conflicts := applyConflicts(stderr); len(conflicts) > 0 {
+				return ApplyConflictError{Conflicts: conflicts}
+			}
+
+			if pErr := permissionDeniedError(stderr); pErr != nil {
+				return pErr
+			}
+
 			shouldTryReplace := maybeImmutableFieldStderr(stderr)
 
 			if !shouldTryReplace {
@@ -227,23 +344,41 @@ func (k K8sClient) Upsert(ctx context.Context, entities []K8sEntity) error {
 			// NOTE(maia): this is equivalent to `kubecutl replace --force`, but will ensure that all
 			// dependant pods get deleted rather than orphaned. We WANT these pods to be deleted
 			// and recreated so they have all the new labels, etc. of their controlling k8s entity.
-			l.Infof("%sFalling back to 'kubectl delete && apply' on immutable field error", prefix)
-			_, stderr, err = k.actOnEntities(ctx, []string{"delete"}, mutable)
+			recreate, rErr := entitiesToRecreate(mutable, stderr)
+			if rErr != nil {
+				return errors.Wrapf(rErr, "kubectl apply:\nstderr: %s", stderr)
+			}
+
+			l.Infof("%sImmutable field changed; deleting and recreating: %s", prefix, entityNames(recreate))
+			_, stderr, err = k.actOnEntities(ctx, []string{"delete"}, recreate)
 			if err != nil {
 				return errors.Wrapf(err, "kubectl delete (as part of delete && apply):\nstderr: %s", stderr)
 			}
-			_, stderr, err = k.actOnEntities(ctx, []string{"apply"}, mutable)
+			_, stderr, err = k.actOnEntities(ctx, applyArgs, mutable)
 			if err != nil {
 				return errors.Wrapf(err, "kubectl apply (as part of delete && apply):\nstderr: %s", stderr)
 			}
 
 		}
 	}
+
+	immutable := ImmutableEntities(entities)
+	if len(immutable) > 0 {
+		_, stderr, err := k.actOnEntities(ctx, []string{"replace", "--force"}, immutable)
+		if err != nil {
+			return errors.Wrapf(err, "kubectl replace:\nstderr: %s", stderr)
+		}
+	}
 	return nil
 }
 
 func (k K8sClient) ConnectedToCluster(ctx context.Context) error {
 	stdout, stderr, err := k.kubectlRunner.exec(ctx, []string{"cluster-info"})
+	if err != nil && isForbiddenStderr(stderr) {
+		// Credentials with limited permissions might not be allowed to see cluster-info,
+		// but if the cluster says so, we're connected.
+		return nil
+	}
 	if err != nil {
 		return errors.Wrapf(err, "Unable to connect to cluster via `kubectl cluster-info`:\nstdout: %s\nstderr: %s", stdout, stderr)
 	}
@@ -258,7 +393,9 @@ func (k K8sClient) ConnectedToCluster(ctx context.Context) error {
 // This should bias towards false positives (i.e., we think something is an
 // immutable field error when it's not).
 func maybeImmutableFieldStderr(stderr string) bool {
-	return strings.Contains(stderr, validation.FieldImmutableErrorMsg) || ForbiddenFieldsRe.Match([]byte(stderr))
+	return strings.Contains(stderr, validation.FieldImmutableErrorMsg) ||
+		strings.Contains(stderr, "immutable after creation") ||
+		ForbiddenFieldsRe.Match([]byte(stderr))
 }
 
 // Deletes all given entities.
@@ -271,7 +408,7 @@ func (k K8sClient) Delete(ctx context.Context, entities []K8sEntity) error {
 		l.Infof("Deleting via kubectl: %s/%s\n", e.Kind.Kind, e.Name())
 	}
 
-	_, stderr, err := k.actOnEntities(ctx, []string{"delete", "--ignore-not-found"}, entities)
+	_, stderr, err := k.actOnEntities(ctx, []string{"delete", "--ignore-not-found", "--wait"}, entities)
 	if err != nil {
 		return errors.Wrapf(err, "kubectl delete:\nstderr: %s", stderr)
 	}
@@ -0,0 +1,229 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// How to reach a cluster, and who to act as when we do.
+//
+// The zero Connection is the kube context that Tilt started with, with the
+// credentials in that context.
+type Connection struct {
+	Context KubeContext
+
+	// If set, load the kube context from this kubeconfig file,
+	// instead of the default kubeconfig.
+	KubeConfigPath string
+
+	// If set, authenticate as this kubeconfig user, instead of the context's user.
+	User string
+
+	// If set, impersonate this user (and these groups) on every request.
+	ImpersonateUser string
+
+	// Comma-separated, so that Connections can be map keys.
+	ImpersonateGroups string
+}
+
+func ConnectionForTarget(t model.K8sTarget) Connection {
+	return Connection{
+		Context:           KubeContext(t.KubeContext),
+		KubeConfigPath:    t.KubeConfigPath,
+		User:              t.KubeUser,
+		ImpersonateUser:   t.ImpersonateUser,
+		ImpersonateGroups: strings.Join(t.ImpersonateGroups, ","),
+	}
+}
+
+func (c Connection) impersonateGroups() []string {
+	if c.ImpersonateGroups == "" {
+		return nil
+	}
+	return strings.Split(c.ImpersonateGroups, ",")
+}
+
+// Whether this connection uses something other than the credentials in its kube context.
+func (c Connection) HasCredentials() bool {
+	return c.User != "" || c.ImpersonateUser != "" || c.ImpersonateGroups != ""
+}
+
+func (c Connection) String() string {
+	var parts []string
+	if c.Context != "" {
+		parts = append(parts, fmt.Sprintf("kube context %q", c.Context))
+	}
+	if c.KubeConfigPath != "" {
+		parts = append(parts, fmt.Sprintf("kubeconfig %s", c.KubeConfigPath))
+	}
+	if c.User != "" {
+		parts = append(parts, fmt.Sprintf("user %q", c.User))
+	}
+	if c.ImpersonateUser != "" {
+		parts = append(parts, fmt.Sprintf("impersonating %q", c.ImpersonateUser))
+	}
+	if c.ImpersonateGroups != "" {
+		parts = append(parts, fmt.Sprintf("impersonating groups %q", c.ImpersonateGroups))
+	}
+	if len(parts) == 0 {
+		return "the default kube context"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Hands out a client for each connection that resources deploy with,
+// so that one session can run resources on more than one cluster,
+// or with more than one set of credentials.
+//
+// Resources that don't set a kube context or credentials use the client for the
+// context that Tilt started with.
+type ClientRegistry struct {
+	defaultContext KubeContext
+	defaultClient  Client
+	newClient      func(ctx context.Context, conn Connection) Client
+
+	mu      sync.Mutex
+	clients map[Connection]Client
+}
+
+func ProvideClientRegistry(kubeContext KubeContext, kCli Client) *ClientRegistry {
+	return &ClientRegistry{
+		defaultContext: kubeContext,
+		defaultClient:  kCli,
+		newClient:      NewClientForConnection,
+		clients:        make(map[Connection]Client),
+	}
+}
+
+// A registry where every resource deploys with the given client.
+// Add clients for other contexts with SetClientForTests.
+func NewClientRegistryForTests(kCli Client) *ClientRegistry {
+	return &ClientRegistry{
+		defaultClient: kCli,
+		newClient: func(ctx context.Context, conn Connection) Client {
+			return &explodingClient{err: fmt.Errorf("unknown connection: %s", conn)}
+		},
+		clients: make(map[Connection]Client),
+	}
+}
+
+func (r *ClientRegistry) SetClientForTests(kubeContext KubeContext, kCli Client) {
+	r.SetClientForConnectionForTests(Connection{Context: kubeContext}, kCli)
+}
+
+func (r *ClientRegistry) SetClientForConnectionForTests(conn Connection, kCli Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[conn] = kCli
+}
+
+// Whether this is the context that Tilt started with, with its own credentials.
+func (r *ClientRegistry) IsDefault(conn Connection) bool {
+	return r.IsDefaultCluster(conn) && !conn.HasCredentials()
+}
+
+// Whether this connection goes to the cluster that Tilt started with
+// (though maybe with different credentials).
+func (r *ClientRegistry) IsDefaultCluster(conn Connection) bool {
+	return conn.KubeConfigPath == "" && (conn.Context == "" || conn.Context == r.defaultContext)
+}
+
+// Normalizes the connection so that equivalent connections
+// have the same key. The default connection is the zero Connection.
+func (r *ClientRegistry) Normalize(conn Connection) Connection {
+	if r.IsDefault(conn) {
+		return Connection{}
+	}
+	if conn.KubeConfigPath == "" && conn.Context == "" {
+		conn.Context = r.defaultContext
+	}
+	return conn
+}
+
+// The kube context that the connection goes to. Empty if it's the current
+// context of some other kubeconfig.
+func (r *ClientRegistry) ContextFor(conn Connection) KubeContext {
+	if conn.Context == "" && conn.KubeConfigPath == "" {
+		return r.defaultContext
+	}
+	return conn.Context
+}
+
+func (r *ClientRegistry) Default() Client {
+	return r.defaultClient
+}
+
+// Returns the client for the given connection.
+//
+// The first time we see a connection other than the default one,
+// we check that we can reach its cluster, so that a bad context or bad credentials
+// fail with an error that names them (rather than with whatever the first API call says).
+func (r *ClientRegistry) ClientFor(ctx context.Context, conn Connection) (Client, error) {
+	conn = r.Normalize(conn)
+	if conn == (Connection{}) {
+		return r.defaultClient, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if kCli, ok := r.clients[conn]; ok {
+		return kCli, nil
+	}
+
+	kCli := r.newClient(ctx, conn)
+	err := kCli.ConnectedToCluster(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Connecting with %s", conn)
+	}
+
+	r.clients[conn] = kCli
+	return kCli, nil
+}
+
+// Creates a client that talks to the cluster of the given connection,
+// rather than the current context in the kubeconfig.
+func NewClientForConnection(ctx context.Context, conn Connection) Client {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.DefaultClientConfig = &clientcmd.DefaultClientConfig
+	if conn.KubeConfigPath != "" {
+		rules.ExplicitPath = conn.KubeConfigPath
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: string(conn.Context)}
+	overrides.Context.AuthInfo = conn.User
+	overrides.AuthInfo.Impersonate = conn.ImpersonateUser
+	overrides.AuthInfo.ImpersonateGroups = conn.impersonateGroups()
+	clientLoader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	rawConfig, err := clientLoader.RawConfig()
+	if err != nil {
+		return &explodingClient{err: errors.Wrapf(err, "Loading kubeconfig for %s", conn)}
+	}
+	if conn.Context == "" {
+		conn.Context = KubeContext(rawConfig.CurrentContext)
+	}
+	if _, ok := rawConfig.Contexts[string(conn.Context)]; !ok {
+		return &explodingClient{err: fmt.Errorf("kube context %q not found in kubeconfig", conn.Context)}
+	}
+	if _, ok := rawConfig.AuthInfos[conn.User]; conn.User != "" && !ok {
+		return &explodingClient{err: fmt.Errorf("user %q not found in kubeconfig", conn.User)}
+	}
+	rawConfig.CurrentContext = string(conn.Context)
+
+	return ProvideK8sClient(
+		ctx,
+		EnvFromConfig(&rawConfig),
+		ProvidePortForwarder(),
+		ProvideConfigNamespace(clientLoader),
+		newKubectlRunnerForConnection(conn),
+		newHelmRunnerForConnection(conn),
+		clientLoader)
+}
@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/testutils/output"
+)
+
+func TestClientRegistryNormalize(t *testing.T) {
+	r := ProvideClientRegistry("docker-for-desktop", NewFakeK8sClient())
+
+	assert.Equal(t, Connection{}, r.Normalize(Connection{}))
+	assert.Equal(t, Connection{}, r.Normalize(Connection{Context: "docker-for-desktop"}))
+	assert.Equal(t, Connection{Context: "gke"}, r.Normalize(Connection{Context: "gke"}))
+
+	// Credentials on the default cluster go through the default context.
+	robot := Connection{Context: "docker-for-desktop", ImpersonateUser: "ci-bot"}
+	assert.Equal(t, robot, r.Normalize(Connection{ImpersonateUser: "ci-bot"}))
+	assert.False(t, r.IsDefault(robot))
+	assert.True(t, r.IsDefaultCluster(robot))
+
+	assert.False(t, r.IsDefaultCluster(Connection{KubeConfigPath: "/etc/robot.kubeconfig"}))
+}
+
+func TestClientRegistryClientForCredentials(t *testing.T) {
+	kCli := NewFakeK8sClient()
+	robotCli := NewFakeK8sClient()
+	r := NewClientRegistryForTests(kCli)
+	r.SetClientForConnectionForTests(Connection{ImpersonateUser: "ci-bot"}, robotCli)
+
+	c, err := r.ClientFor(output.CtxForTest(), Connection{ImpersonateUser: "ci-bot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, c == Client(robotCli))
+
+	_, err = r.ClientFor(output.CtxForTest(), Connection{User: "admin"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `user "admin"`)
+	}
+}
+
+func TestKubectlRunnerForConnection(t *testing.T) {
+	runner := newKubectlRunnerForConnection(Connection{
+		Context:           "gke",
+		KubeConfigPath:    "/etc/robot.kubeconfig",
+		User:              "robot",
+		ImpersonateUser:   "ci-bot",
+		ImpersonateGroups: "deployers,auditors",
+	}).(realKubectlRunner)
+
+	assert.Equal(t, []string{
+		"--context", "gke",
+		"--kubeconfig", "/etc/robot.kubeconfig",
+		"--user", "robot",
+		"--as", "ci-bot",
+		"--as-group", "deployers",
+		"--as-group", "auditors",
+		"apply", "-f", "-",
+	}, runner.prependGlobalArgs([]string{"apply", "-f", "-"}))
+}
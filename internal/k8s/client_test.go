@@ -34,6 +34,26 @@ func TestUpsert(t *testing.T) {
 	assert.Equal(t, []string{"apply", "-f", "-"}, f.runner.calls[0].argv)
 }
 
+func TestUpsertWaitsForCRDs(t *testing.T) {
+	f := newClientTestFixture(t)
+	entities, err := ParseYAMLFromString(testyaml.CRDYAML)
+	assert.Nil(t, err)
+
+	err = f.client.Upsert(f.ctx, entities)
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(f.runner.calls)) {
+		assert.Equal(t, []string{"apply", "-f", "-"}, f.runner.calls[0].argv)
+		assert.Contains(t, f.runner.calls[0].stdin, "kind: CustomResourceDefinition")
+		assert.NotContains(t, f.runner.calls[0].stdin, "name: example-project")
+
+		assert.Equal(t, []string{"wait", "--for", "condition=established", "--timeout=60s", "crd/projects.example.martin-helmich.de"},
+			f.runner.calls[1].argv)
+
+		assert.Equal(t, []string{"apply", "-f", "-"}, f.runner.calls[2].argv)
+		assert.Contains(t, f.runner.calls[2].stdin, "name: example-project")
+	}
+}
+
 func TestUpsertStatefulsetForbidden(t *testing.T) {
 	f := newClientTestFixture(t)
 	postgres, err := ParseYAMLFromString(testyaml.PostgresYAML)
@@ -45,7 +65,59 @@ func TestUpsertStatefulsetForbidden(t *testing.T) {
 		assert.Equal(t, []string{"apply", "-f", "-"}, f.runner.calls[0].argv)
 		assert.Equal(t, []string{"delete", "-f", "-"}, f.runner.calls[1].argv)
 		assert.Equal(t, []string{"apply", "-f", "-"}, f.runner.calls[2].argv)
+
+		// Only the StatefulSet is recreated. The volumes (and the Service with the same name) are left alone.
+		deleted := f.runner.calls[1].stdin
+		assert.Contains(t, deleted, "kind: StatefulSet")
+		assert.NotContains(t, deleted, "kind: PersistentVolume")
+		assert.NotContains(t, deleted, "kind: Service")
+		assert.NotContains(t, deleted, "kind: ConfigMap")
+	}
+}
+
+func TestUpsertImmutablePersistentVolumeClaim(t *testing.T) {
+	f := newClientTestFixture(t)
+	postgres, err := ParseYAMLFromString(testyaml.PostgresYAML)
+	assert.Nil(t, err)
+
+	f.setStderr(`The PersistentVolumeClaim "postgres-pv-claim" is invalid: spec: Forbidden: spec is immutable after creation except resources.requests for bound claims`)
+	err = f.client.Upsert(f.ctx, postgres)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `PersistentVolumeClaim "postgres-pv-claim" can't be updated in place`)
+		assert.Contains(t, err.Error(), "kubectl delete persistentvolumeclaim postgres-pv-claim")
+	}
+	assert.Equal(t, 1, len(f.runner.calls))
+}
+
+func TestUpsertImmutableUnknownObject(t *testing.T) {
+	f := newClientTestFixture(t)
+	postgres, err := ParseYAMLFromString(testyaml.PostgresYAML)
+	assert.Nil(t, err)
+
+	f.setStderr(`error: field is immutable`)
+	err = f.client.Upsert(f.ctx, postgres)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "couldn't tell which object")
 	}
+	assert.Equal(t, 1, len(f.runner.calls))
+}
+
+func TestUpsertPermissionDenied(t *testing.T) {
+	f := newClientTestFixture(t)
+	sancho, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	assert.Nil(t, err)
+
+	f.setStderr(`Error from server (Forbidden): error when retrieving current configuration of:
+Resource: "apps/v1, Resource=deployments", GroupVersionKind: "apps/v1, Kind=Deployment"
+Name: "sancho", Namespace: "default"
+Object: &{map[...]}
+from server for: "STDIN": deployments.apps "sancho" is forbidden: User "system:serviceaccount:ci:deployer" cannot get resource "deployments" in API group "apps" in the namespace "default"`)
+	err = f.client.Upsert(f.ctx, sancho)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `Permission denied: user "system:serviceaccount:ci:deployer" can't get deployments in namespace "default"`)
+		assert.NotContains(t, err.Error(), "Object: &{map")
+	}
+	assert.Equal(t, 1, len(f.runner.calls))
 }
 
 func TestUpsertToTerminatingNamespaceForbidden(t *testing.T) {
@@ -69,6 +141,49 @@ func TestUpsertToTerminatingNamespaceForbidden(t *testing.T) {
 
 }
 
+func TestServerSideApply(t *testing.T) {
+	f := newClientTestFixture(t)
+	postgres, err := ParseYAMLFromString(testyaml.PostgresYAML)
+	assert.Nil(t, err)
+	err = f.client.ServerSideApply(f.ctx, postgres, false)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(f.runner.calls)) {
+		assert.Equal(t, []string{"apply", "--server-side", "--field-manager=tilt", "-f", "-"}, f.runner.calls[0].argv)
+	}
+
+	err = f.client.ServerSideApply(f.ctx, postgres, true)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(f.runner.calls)) {
+		assert.Equal(t, []string{"apply", "--server-side", "--field-manager=tilt", "--force-conflicts", "-f", "-"}, f.runner.calls[1].argv)
+	}
+}
+
+func TestServerSideApplyConflict(t *testing.T) {
+	f := newClientTestFixture(t)
+	postgres, err := ParseYAMLFromString(testyaml.PostgresYAML)
+	assert.Nil(t, err)
+
+	f.setStderr(`error: Apply failed with 1 conflict: conflict with "istio-webhook" using apps/v1: .spec.template.metadata.annotations
+Please review the fields above--they currently have other managers.`)
+	err = f.client.ServerSideApply(f.ctx, postgres, false)
+	if assert.True(t, IsApplyConflictError(err), "expected conflict error, got %v", err) {
+		assert.Contains(t, err.Error(), `conflict with "istio-webhook" using apps/v1: .spec.template.metadata.annotations`)
+		assert.Contains(t, err.Error(), "force_conflicts=True")
+	}
+
+	// Conflicts aren't immutable field errors, so we don't delete and re-apply.
+	assert.Equal(t, 1, len(f.runner.calls))
+}
+
+func TestApplyConflictsMultipleFields(t *testing.T) {
+	stderr := `error: Apply failed with 2 conflicts: conflicts with "operator":
+- .spec.replicas
+- .metadata.labels.team
+Please review the fields above--they currently have other managers.`
+	assert.Equal(t, []string{`conflicts with "operator":`, "- .spec.replicas", "- .metadata.labels.team"}, applyConflicts(stderr))
+	assert.Empty(t, applyConflicts(`The StatefulSet "postgres" is invalid`))
+}
+
 type call struct {
 	argv  []string
 	stdin string
@@ -114,6 +229,7 @@ type clientTestFixture struct {
 	ctx         context.Context
 	client      K8sClient
 	runner      *fakeKubectlRunner
+	helm        *fakeKubectlRunner
 	tracker     ktesting.ObjectTracker
 	watchNotify chan watch.Interface
 }
@@ -123,6 +239,7 @@ func newClientTestFixture(t *testing.T) *clientTestFixture {
 	ret.t = t
 	ret.ctx = output.CtxForTest()
 	ret.runner = &fakeKubectlRunner{}
+	ret.helm = &fakeKubectlRunner{}
 
 	tracker := ktesting.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
 	watchNotify := make(chan watch.Interface, 100)
@@ -146,7 +263,7 @@ func newClientTestFixture(t *testing.T) *clientTestFixture {
 
 	core := cs.CoreV1()
 	runtimeAsync := newRuntimeAsync(core)
-	ret.client = K8sClient{EnvUnknown, ret.runner, core, nil, fakePortForwarder, "", nil, runtimeAsync}
+	ret.client = K8sClient{EnvUnknown, ret.runner, core, nil, fakePortForwarder, "", nil, runtimeAsync, ret.helm, newInformerFactories()}
 	return ret
 }
 
@@ -186,8 +303,8 @@ func (c clientTestFixture) setError(err error) {
 	c.runner.err = err
 }
 
-func fakePortForwarder(ctx context.Context, restConfig *rest.Config, core apiv1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), err error) {
-	return nil, nil
+func fakePortForwarder(ctx context.Context, restConfig *rest.Config, core apiv1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), done <-chan error, err error) {
+	return nil, nil, nil
 }
 
 var _ PortForwarder = fakePortForwarder
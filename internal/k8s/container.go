@@ -117,35 +117,60 @@ func IsUnschedulable(pod v1.PodStatus) (bool, string) {
 // purposes). To work around this bug, we change the image reference in
 // ContainerStatus to match the ContainerSpec.
 func FixContainerStatusImages(pod *v1.Pod) {
+	fixContainerStatusImages(pod.Spec.InitContainers, pod.Status.InitContainerStatuses)
+	fixContainerStatusImages(pod.Spec.Containers, pod.Status.ContainerStatuses)
+}
+
+func fixContainerStatusImages(specs []v1.Container, statuses []v1.ContainerStatus) {
 	refsByContainerName := make(map[string]string)
-	for _, c := range pod.Spec.Containers {
+	for _, c := range specs {
 		if c.Name != "" {
 			refsByContainerName[c.Name] = c.Image
 		}
 	}
-	for i, cs := range pod.Status.ContainerStatuses {
+	for i, cs := range statuses {
 		image, ok := refsByContainerName[cs.Name]
 		if !ok {
 			continue
 		}
 
 		cs.Image = image
-		pod.Status.ContainerStatuses[i] = cs
+		statuses[i] = cs
 	}
 }
 
+// Returns the status of the first container running an image that matches ref.
+//
+// App containers take precedence. If only an init container matches, returns its status
+// while it's running, marked Ready (k8s never marks init containers as Ready). An init
+// container that hasn't started or has already exited is skipped, because there's nothing
+// left to update or exec into.
 func ContainerMatching(pod *v1.Pod, ref container.RefSelector) (v1.ContainerStatus, error) {
-	for _, c := range pod.Status.ContainerStatuses {
+	c, ok, err := containerStatusMatching(pod.Status.ContainerStatuses, ref)
+	if err != nil || ok {
+		return c, err
+	}
+
+	c, ok, err = containerStatusMatching(pod.Status.InitContainerStatuses, ref)
+	if err != nil || !ok || c.State.Running == nil {
+		return v1.ContainerStatus{}, err
+	}
+	c.Ready = true
+	return c, nil
+}
+
+func containerStatusMatching(statuses []v1.ContainerStatus, ref container.RefSelector) (v1.ContainerStatus, bool, error) {
+	for _, c := range statuses {
 		cRef, err := container.ParseNamed(c.Image)
 		if err != nil {
-			return v1.ContainerStatus{}, errors.Wrap(err, "ContainerMatching")
+			return v1.ContainerStatus{}, false, errors.Wrap(err, "ContainerMatching")
 		}
 
 		if ref.Matches(cRef) {
-			return c, nil
+			return c, true, nil
 		}
 	}
-	return v1.ContainerStatus{}, nil
+	return v1.ContainerStatus{}, false, nil
 }
 
 func ContainerIDFromContainerStatus(status v1.ContainerStatus) (container.ID, error) {
@@ -166,7 +191,9 @@ func ContainerNameFromContainerStatus(status v1.ContainerStatus) container.Name
 }
 
 func ContainerSpecOf(pod *v1.Pod, status v1.ContainerStatus) v1.Container {
-	for _, spec := range pod.Spec.Containers {
+	specs := append([]v1.Container{}, pod.Spec.InitContainers...)
+	specs = append(specs, pod.Spec.Containers...)
+	for _, spec := range specs {
 		if spec.Name == status.Name {
 			return spec
 		}
@@ -34,6 +34,61 @@ func TestFixContainerStatusImages(t *testing.T) {
 		pod.Status.ContainerStatuses[0].Image)
 }
 
+func TestFixInitContainerStatusImages(t *testing.T) {
+	pod := fakePod(expectedPod, blorgDevImgStr)
+	pod.Spec.InitContainers = []v1.Container{
+		{Name: "migrate", Image: blorgDevImgStr + "-migrate"},
+	}
+	pod.Status = v1.PodStatus{
+		InitContainerStatuses: []v1.ContainerStatus{
+			{Name: "migrate", Image: blorgDevImgStr + "-migrate:v2"},
+		},
+	}
+
+	FixContainerStatusImages(&pod)
+	assert.Equal(t,
+		pod.Spec.InitContainers[0].Image,
+		pod.Status.InitContainerStatuses[0].Image)
+}
+
+func TestContainerMatchingInitContainer(t *testing.T) {
+	pod := fakePod(expectedPod, blorgDevImgStr)
+	pod.Status = v1.PodStatus{
+		InitContainerStatuses: []v1.ContainerStatus{
+			{
+				Name:  "migrate",
+				Image: blorgDevImgStr + "-migrate",
+				State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			},
+		},
+		ContainerStatuses: []v1.ContainerStatus{
+			{Name: "default", Image: blorgDevImgStr},
+		},
+	}
+
+	c, err := ContainerMatching(&pod, container.MustParseSelector(blorgDevImgStr+"-migrate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "migrate", c.Name)
+	assert.True(t, c.Ready)
+
+	// Once the init container exits, there's nothing to pick.
+	pod.Status.InitContainerStatuses[0].State = v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 0}}
+	c, err = ContainerMatching(&pod, container.MustParseSelector(blorgDevImgStr+"-migrate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", c.Name)
+
+	c, err = ContainerMatching(&pod, container.MustParseSelector(blorgDevImgStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "default", c.Name)
+	assert.False(t, c.Ready)
+}
+
 func TestWaitForContainerAlreadyAlive(t *testing.T) {
 	f := newClientTestFixture(t)
 
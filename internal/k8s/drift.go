@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Compares the objects in the cluster against the given entities (i.e., what
+// we last applied), with `kubectl diff`, to see if someone changed them out
+// from under us (e.g., with `kubectl edit`).
+//
+// Returns a human-readable diff, where - lines are what's in the cluster and
+// + lines are what we applied, or the empty string if nothing changed.
+func (k K8sClient) Diff(ctx context.Context, entities []K8sEntity, serverSide bool) (string, error) {
+	args := []string{"diff"}
+	if serverSide {
+		args = append(args, "--server-side", "--field-manager="+TiltFieldManager)
+	}
+
+	stdout, stderr, err := k.actOnEntities(ctx, args, entities)
+	if err == nil {
+		return "", nil
+	}
+
+	// kubectl diff exits 1 when it finds differences, and >1 when it fails.
+	if exitErr, ok := err.(interface{ ExitCode() int }); !ok || exitErr.ExitCode() != 1 {
+		return "", errors.Wrapf(err, "kubectl diff:\nstderr: %s", stderr)
+	}
+	return cleanKubectlDiff(stdout), nil
+}
+
+// kubectl diff writes each object to a temp file and runs diff on them,
+// so its output is full of temp file names and timestamps that change
+// on every run. Replaces those with the names of the objects.
+func cleanKubectlDiff(diff string) string {
+	var result []string
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			fields := strings.Fields(line)
+			result = append(result, fmt.Sprintf("%s:", diffObjectName(fields[len(fields)-1])))
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			continue
+		default:
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// kubectl diff names its files [group.]version.Kind.namespace.name
+// (e.g., apps.v1.Deployment.default.sancho).
+// Returns the object as Kind namespace/name.
+func diffObjectName(path string) string {
+	parts := strings.Split(filepath.Base(path), ".")
+	for i, part := range parts {
+		if part == "" || part[0] < 'A' || part[0] > 'Z' || i+2 >= len(parts) {
+			continue
+		}
+		kind, ns, name := part, parts[i+1], strings.Join(parts[i+2:], ".")
+		if ns == "" {
+			return fmt.Sprintf("%s %s", kind, name)
+		}
+		return fmt.Sprintf("%s %s/%s", kind, ns, name)
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+const kubectlDiffOutput = `diff -u -N /tmp/LIVE-123/apps.v1.Deployment.default.sancho /tmp/MERGED-456/apps.v1.Deployment.default.sancho
+--- /tmp/LIVE-123/apps.v1.Deployment.default.sancho	2019-06-04 17:19:19.000000000 -0400
++++ /tmp/MERGED-456/apps.v1.Deployment.default.sancho	2019-06-04 17:19:19.000000000 -0400
+@@ -6,7 +6,7 @@
+ spec:
+-  replicas: 3
++  replicas: 1
+`
+
+type exitCodeError int
+
+func (e exitCodeError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e exitCodeError) ExitCode() int { return int(e) }
+
+func TestDiffNoChanges(t *testing.T) {
+	f := newClientTestFixture(t)
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	assert.Nil(t, err)
+
+	diff, err := f.client.Diff(f.ctx, entities, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", diff)
+	assert.Equal(t, []string{"diff", "-f", "-"}, f.runner.calls[0].argv)
+}
+
+func TestDiffChanges(t *testing.T) {
+	f := newClientTestFixture(t)
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	assert.Nil(t, err)
+
+	f.runner.stdout = kubectlDiffOutput
+	f.runner.err = exitCodeError(1)
+	diff, err := f.client.Diff(f.ctx, entities, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Deployment default/sancho:\n@@ -6,7 +6,7 @@\n spec:\n-  replicas: 3\n+  replicas: 1", diff)
+	assert.Equal(t, []string{"diff", "--server-side", "--field-manager=tilt", "-f", "-"}, f.runner.calls[0].argv)
+}
+
+func TestDiffError(t *testing.T) {
+	f := newClientTestFixture(t)
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	assert.Nil(t, err)
+
+	f.runner.stderr = "error: unable to connect"
+	f.runner.err = exitCodeError(2)
+	_, err = f.client.Diff(f.ctx, entities, false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unable to connect")
+	}
+}
+
+func TestDiffObjectName(t *testing.T) {
+	assert.Equal(t, "Deployment default/sancho", diffObjectName("/tmp/LIVE-1/apps.v1.Deployment.default.sancho"))
+	assert.Equal(t, "Service default/sancho.v2", diffObjectName("v1.Service.default.sancho.v2"))
+	assert.Equal(t, "Namespace team", diffObjectName("v1.Namespace..team"))
+}
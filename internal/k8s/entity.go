@@ -86,6 +86,12 @@ func (e K8sEntity) Namespace() Namespace {
 	return Namespace(n)
 }
 
+// The namespace set in the entity's YAML, or empty if it doesn't set one
+// (and so goes in whatever namespace the client is configured with).
+func (e K8sEntity) ExplicitNamespace() Namespace {
+	return Namespace(e.meta().GetNamespace())
+}
+
 // Most entities can be updated once running, but a few cannot.
 func (e K8sEntity) ImmutableOnceCreated() bool {
 	if e.Kind != nil {
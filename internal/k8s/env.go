@@ -17,6 +17,7 @@ const (
 	EnvDockerDesktop Env = "docker-for-desktop"
 	EnvMicroK8s      Env = "microk8s"
 	EnvKIND          Env = "kind"
+	EnvK3D           Env = "k3d"
 	EnvNone          Env = "none" // k8s not running (not neces. a problem, e.g. if using Tilt x Docker Compose)
 )
 
@@ -32,6 +33,24 @@ func ProvideKubeContext(config *api.Config) (KubeContext, error) {
 	return KubeContext(config.CurrentContext), nil
 }
 
+// Reads the current context, and the namespace of that context, fresh from the kubeconfig.
+//
+// Unlike ProvideKubeConfig (which loads the kubeconfig once, at startup),
+// this sees when the user switches contexts while Tilt is running.
+func LoadCurrentContext() (KubeContext, Namespace, error) {
+	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		return "", "", errors.Wrap(err, "Loading Kubernetes current-context")
+	}
+
+	ns := DefaultNamespace
+	kCtx, ok := config.Contexts[config.CurrentContext]
+	if ok && kCtx != nil && kCtx.Namespace != "" {
+		ns = Namespace(kCtx.Namespace)
+	}
+	return KubeContext(config.CurrentContext), ns, nil
+}
+
 func ProvideKubeConfig(clientLoader clientcmd.ClientConfig) (*api.Config, error) {
 	access := clientLoader.ConfigAccess()
 	config, err := access.GetStartingConfig()
@@ -49,8 +68,10 @@ func EnvFromString(s string) Env {
 		return EnvDockerDesktop
 	} else if Env(s) == EnvMicroK8s {
 		return EnvMicroK8s
-	} else if strings.HasPrefix(s, "kubernetes-admin@kind") {
+	} else if strings.HasPrefix(s, "kubernetes-admin@kind") || strings.HasPrefix(s, "kind-") {
 		return EnvKIND
+	} else if strings.HasPrefix(s, "k3d-") {
+		return EnvK3D
 	} else if Env(s) == EnvNone {
 		return EnvNone
 	} else if strings.HasPrefix(s, string(EnvGKE)) {
@@ -78,11 +99,28 @@ func EnvFromConfig(config *api.Config) Env {
 		// GKE cluster strings look like:
 		// gke_blorg-dev_us-central1-b_blorg
 		return EnvGKE
-	} else if Env(cn) == EnvKIND {
+	} else if Env(cn) == EnvKIND || strings.HasPrefix(cn, "kind-") {
 		return EnvKIND
 	} else if cn == "microk8s-cluster" {
 		return EnvMicroK8s
+	} else if strings.HasPrefix(cn, "k3d-") {
+		return EnvK3D
 	}
 
 	return EnvUnknown
 }
+
+const defaultKINDClusterName = "kind"
+
+// The name of the KIND cluster behind this kube context, as `kind load --name` expects it.
+//
+// KIND names contexts "kind-<cluster>" (v0.6 and up) or "kubernetes-admin@<cluster>" (before v0.6).
+func KINDClusterName(kubeContext KubeContext) string {
+	s := string(kubeContext)
+	for _, prefix := range []string{"kind-", "kubernetes-admin@"} {
+		if strings.HasPrefix(s, prefix) && len(s) > len(prefix) {
+			return strings.TrimPrefix(s, prefix)
+		}
+	}
+	return defaultKINDClusterName
+}
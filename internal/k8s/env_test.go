@@ -21,6 +21,9 @@ func TestEnvFromString(t *testing.T) {
 		{EnvUnknown, "aws"},
 		{EnvKIND, "kubernetes-admin@kind"},
 		{EnvKIND, "kubernetes-admin@kind-1"},
+		{EnvKIND, "kind-kind"},
+		{EnvKIND, "kind-dev"},
+		{EnvK3D, "k3d-dev"},
 	}
 
 	for _, tt := range table {
@@ -69,6 +72,16 @@ func TestEnvFromConfig(t *testing.T) {
 			Cluster: "microk8s-cluster",
 		},
 	}
+	newKINDContexts := map[string]*api.Context{
+		"kind-dev": &api.Context{
+			Cluster: "kind-dev",
+		},
+	}
+	k3dContexts := map[string]*api.Context{
+		"k3d-dev": &api.Context{
+			Cluster: "k3d-dev",
+		},
+	}
 	table := []expectedConfig{
 		{EnvUnknown, &api.Config{CurrentContext: "aws"}},
 		{EnvMinikube, &api.Config{CurrentContext: "minikube", Contexts: minikubeContexts}},
@@ -77,6 +90,8 @@ func TestEnvFromConfig(t *testing.T) {
 		{EnvGKE, &api.Config{CurrentContext: "gke_blorg-dev_us-central1-b_blorg", Contexts: gkeContexts}},
 		{EnvKIND, &api.Config{CurrentContext: "kubernetes-admin@kind-1", Contexts: kindContexts}},
 		{EnvMicroK8s, &api.Config{CurrentContext: "microk8s", Contexts: microK8sContexts}},
+		{EnvK3D, &api.Config{CurrentContext: "k3d-dev", Contexts: k3dContexts}},
+		{EnvKIND, &api.Config{CurrentContext: "kind-dev", Contexts: newKINDContexts}},
 	}
 
 	for _, tt := range table {
@@ -88,3 +103,22 @@ func TestEnvFromConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestKINDClusterName(t *testing.T) {
+	table := map[KubeContext]string{
+		"kind-kind":               "kind",
+		"kind-dev":                "dev",
+		"kubernetes-admin@kind":   "kind",
+		"kubernetes-admin@kind-1": "kind-1",
+		"something-else":          "kind",
+	}
+
+	for kubeContext, expected := range table {
+		t.Run(string(kubeContext), func(t *testing.T) {
+			actual := KINDClusterName(kubeContext)
+			if actual != expected {
+				t.Errorf("Expected %s, actual %s", expected, actual)
+			}
+		})
+	}
+}
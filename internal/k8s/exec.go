@@ -18,6 +18,27 @@ func (k K8sClient) Exec(ctx context.Context, podID PodID, cName container.Name,
 	span.SetTag("cmd", fmt.Sprintf("%v", cmd))
 	defer span.Finish()
 
+	return k.exec(podID, cName, n, cmd, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+func (k K8sClient) ExecTTY(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, sizes remotecommand.TerminalSizeQueue) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "k8s-ExecTTY")
+	span.SetTag("cmd", fmt.Sprintf("%v", cmd))
+	defer span.Finish()
+
+	return k.exec(podID, cName, n, cmd, remotecommand.StreamOptions{
+		Stdin:             stdin,
+		Stdout:            stdout,
+		Tty:               true,
+		TerminalSizeQueue: sizes,
+	})
+}
+
+func (k K8sClient) exec(podID PodID, cName container.Name, n Namespace, cmd []string, opts remotecommand.StreamOptions) error {
 	req := k.core.RESTClient().Post().
 		Resource("pods").
 		Namespace(n.String()).
@@ -27,9 +48,10 @@ func (k K8sClient) Exec(ctx context.Context, podID PodID, cName container.Name,
 	req.VersionedParams(&corev1.PodExecOptions{
 		Container: cName.String(),
 		Command:   cmd,
-		Stdin:     stdin != nil,
-		Stdout:    stdout != nil,
-		Stderr:    stderr != nil,
+		Stdin:     opts.Stdin != nil,
+		Stdout:    opts.Stdout != nil,
+		Stderr:    opts.Stderr != nil,
+		TTY:       opts.Tty,
 	}, scheme.ParameterCodec)
 
 	exec, err := remotecommand.NewSPDYExecutor(k.restConfig, "POST", req.URL())
@@ -37,9 +59,5 @@ func (k K8sClient) Exec(ctx context.Context, podID PodID, cName container.Name,
 		return err
 	}
 
-	return exec.Stream(remotecommand.StreamOptions{
-		Stdin:  stdin,
-		Stdout: stdout,
-		Stderr: stderr,
-	})
+	return exec.Stream(opts)
 }
@@ -9,9 +9,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/model"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/remotecommand"
 )
 
 var _ Client = &explodingClient{}
@@ -36,6 +38,10 @@ func (ec *explodingClient) PollForPodsWithImage(ctx context.Context, image refer
 	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
 
+func (ec *explodingClient) ListPods(ctx context.Context, n Namespace, ls labels.Selector) ([]v1.Pod, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
 func (ec *explodingClient) PodByID(ctx context.Context, podID PodID, n Namespace) (*v1.Pod, error) {
 	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
@@ -48,8 +54,48 @@ func (ec *explodingClient) ContainerLogs(ctx context.Context, podID PodID, cName
 	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
 
-func (ec *explodingClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (localPort int, closer func(), err error) {
-	return 0, nil, errors.Wrap(ec.err, "could not set up k8s client")
+func (ec *explodingClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (PortForward, error) {
+	return PortForward{}, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) ServerSideApply(ctx context.Context, entities []K8sEntity, forceConflicts bool) error {
+	return errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) ListVolumeClaims(ctx context.Context, n Namespace) ([]v1.PersistentVolumeClaim, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) ListObjects(ctx context.Context, n Namespace, ls labels.Selector) ([]K8sEntity, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) ConfigNamespace() Namespace {
+	return DefaultNamespace
+}
+
+func (ec *explodingClient) CreateNamespaceIfMissing(ctx context.Context, n Namespace) error {
+	return errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) DeleteNamespaceIfCreatedByTilt(ctx context.Context, n Namespace) error {
+	return errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) HelmUpgrade(ctx context.Context, release model.HelmRelease, imageValues []string) error {
+	return errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) HelmUninstall(ctx context.Context, release model.HelmRelease) error {
+	return errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) HelmClearPending(ctx context.Context, release model.HelmRelease) error {
+	return errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) ServiceEndpoints(ctx context.Context, n Namespace, name string, port int) ([]ServiceEndpoint, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
 
 func (ec *explodingClient) WatchPods(ctx context.Context, lps labels.Selector) (<-chan *v1.Pod, error) {
@@ -60,10 +106,34 @@ func (ec *explodingClient) WatchServices(ctx context.Context, lps []model.LabelP
 	return nil, errors.Wrap(ec.err, "could not set up k8s client")
 }
 
+func (ec *explodingClient) WatchEvents(ctx context.Context, n Namespace) (<-chan *v1.Event, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) WatchReplicaSets(ctx context.Context, ls labels.Selector) (<-chan *appsv1.ReplicaSet, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) WatchIngresses(ctx context.Context, ls labels.Selector) (<-chan IngressEvent, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
 func (ec *explodingClient) ConnectedToCluster(ctx context.Context) error {
 	return errors.Wrap(ec.err, "could not set up k8s client")
 }
 
+func (ec *explodingClient) CheckPermissions(ctx context.Context, perms []Permission) ([]PermissionCheck, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) Diff(ctx context.Context, entities []K8sEntity, serverSide bool) (string, error) {
+	return "", errors.Wrap(ec.err, "could not set up k8s client")
+}
+
+func (ec *explodingClient) PodMetrics(ctx context.Context, ns Namespace) ([]PodMetrics, error) {
+	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+}
+
 func (ec *explodingClient) ContainerRuntime(ctx context.Context) container.Runtime {
 	return container.RuntimeUnknown
 }
@@ -71,3 +141,7 @@ func (ec *explodingClient) ContainerRuntime(ctx context.Context) container.Runti
 func (ec *explodingClient) Exec(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
 	return errors.Wrap(ec.err, "could not set up k8s client")
 }
+
+func (ec *explodingClient) ExecTTY(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, sizes remotecommand.TerminalSizeQueue) error {
+	return errors.Wrap(ec.err, "could not set up k8s client")
+}
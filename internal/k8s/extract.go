@@ -25,6 +25,10 @@ func ExtractPods(obj interface{}) ([]*v1.PodSpec, error) {
 	return result, nil
 }
 
+// Extracts pointers to all the pod templates in the given object.
+//
+// Pod templates in unstructured entities (e.g., custom resources) are found
+// by their shape, and are returned as copies.
 func ExtractPodTemplateSpec(obj interface{}) ([]*v1.PodTemplateSpec, error) {
 	extracted, err := newExtractor(reflect.TypeOf(v1.PodTemplateSpec{})).extractPointersFrom(obj)
 	if err != nil {
@@ -39,6 +43,13 @@ func ExtractPodTemplateSpec(obj interface{}) ([]*v1.PodTemplateSpec, error) {
 		}
 		result[i] = c
 	}
+
+	switch e := obj.(type) {
+	case K8sEntity:
+		result = append(result, extractUnstructuredPodTemplateSpecs(e)...)
+	case *K8sEntity:
+		result = append(result, extractUnstructuredPodTemplateSpecs(*e)...)
+	}
 	return result, nil
 }
 
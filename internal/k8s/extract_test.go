@@ -70,3 +70,16 @@ func TestExtractSanchoPodTemplateSpecs(t *testing.T) {
 		assert.Equal(t, tempSpecs[0].ObjectMeta.Labels, expectedLabels)
 	}
 }
+
+func TestExtractCustomResourcePodTemplateSpecs(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.OperatorAppYAML)
+	tempSpecs, err := ExtractPodTemplateSpec(&entity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Equal(t, 1, len(tempSpecs)) {
+		assert.Equal(t, map[string]string{"app": "frontend"}, tempSpecs[0].ObjectMeta.Labels)
+		assert.Equal(t, "gcr.io/foo/frontend", tempSpecs[0].Spec.Containers[0].Image)
+	}
+}
@@ -3,18 +3,23 @@ package k8s
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/remotecommand"
 
 	"github.com/windmilleng/tilt/internal/model"
 
 	"github.com/docker/distribution/reference"
 	"github.com/pkg/errors"
 	"github.com/windmilleng/tilt/internal/container"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
@@ -41,14 +46,71 @@ type FakeK8sClient struct {
 	PodLogsByPodAndContainer map[PodAndCName]BufferCloser
 	ContainerLogsError       error
 
+	mu sync.Mutex
+
 	LastForwardPortPodID      PodID
 	LastForwardPortRemotePort int
+	LastForwardPortDone       chan error
+	ForwardPortCount          int
+
+	// Errors that the next calls to ForwardPort will return, in order.
+	ForwardPortErrors []error
+
+	// Keyed by service name.
+	ServiceEndpointsByName map[string][]ServiceEndpoint
+
+	CreatedNamespaces []Namespace
+	DeletedNamespaces []Namespace
+
+	VolumeClaims []v1.PersistentVolumeClaim
+
+	// The objects in the cluster, for ListObjects,
+	// and the namespaces that ListObjects looked in, in order.
+	Objects               []K8sEntity
+	ListObjectsNamespaces []Namespace
+
+	// The namespace of the kube context. Defaults to "default".
+	ConfigNs Namespace
+
+	// The entities passed to each call to Delete, in order.
+	DeleteCalls [][]K8sEntity
 
-	watcherMu sync.Mutex
-	watches   []fakePodWatch
+	// Permissions that CheckPermissions says we don't have.
+	DeniedPermissions map[Permission]bool
+
+	// The pods that ListPods and PodByID return, and the calls to ExecTTY, in order.
+	Pods         []v1.Pod
+	ExecTTYCalls []ExecCall
+
+	// The diff that Diff returns, and the entities passed to each call to Diff, in order.
+	DiffResult string
+	DiffCalls  [][]K8sEntity
+
+	// The metrics that PodMetrics returns, or the error it returns instead.
+	Metrics    []PodMetrics
+	MetricsErr error
+
+	watcherMu         sync.Mutex
+	watches           []fakePodWatch
+	eventWatches      []fakeEventWatch
+	replicaSetWatches []chan *appsv1.ReplicaSet
+	ingressWatches    []chan IngressEvent
 
 	UpsertError error
 	Runtime     container.Runtime
+
+	// Set when the last deploy used server-side apply.
+	ServerSideApplied bool
+	ForcedConflicts   bool
+
+	// The Helm releases passed to HelmUpgrade, and the image values for the last one.
+	UpgradedHelmReleases    []model.HelmRelease
+	HelmImageValues         []string
+	UninstalledHelmReleases []model.HelmRelease
+	ClearedHelmReleases     []model.HelmRelease
+
+	// Returned by the next call to HelmUpgrade.
+	HelmUpgradeError error
 }
 
 type fakePodWatch struct {
@@ -56,10 +118,84 @@ type fakePodWatch struct {
 	ch chan *v1.Pod
 }
 
+type fakeEventWatch struct {
+	ns Namespace
+	ch chan *v1.Event
+}
+
 func (c *FakeK8sClient) WatchServices(ctx context.Context, lps []model.LabelPair) (<-chan *v1.Service, error) {
 	return nil, nil
 }
 
+func (c *FakeK8sClient) WatchEvents(ctx context.Context, n Namespace) (<-chan *v1.Event, error) {
+	c.watcherMu.Lock()
+	defer c.watcherMu.Unlock()
+	ch := make(chan *v1.Event, 20)
+	c.eventWatches = append(c.eventWatches, fakeEventWatch{ns: Namespace(n.String()), ch: ch})
+	return ch, nil
+}
+
+// The namespaces that we're watching events in, in the order we started watching them.
+func (c *FakeK8sClient) EventWatchNamespaces() []Namespace {
+	c.watcherMu.Lock()
+	defer c.watcherMu.Unlock()
+	var result []Namespace
+	for _, w := range c.eventWatches {
+		result = append(result, w.ns)
+	}
+	return result
+}
+
+func (c *FakeK8sClient) WatchReplicaSets(ctx context.Context, ls labels.Selector) (<-chan *appsv1.ReplicaSet, error) {
+	c.watcherMu.Lock()
+	defer c.watcherMu.Unlock()
+	ch := make(chan *appsv1.ReplicaSet, 20)
+	c.replicaSetWatches = append(c.replicaSetWatches, ch)
+	return ch, nil
+}
+
+func (c *FakeK8sClient) EmitReplicaSet(rs *appsv1.ReplicaSet) {
+	c.watcherMu.Lock()
+	defer c.watcherMu.Unlock()
+	for _, ch := range c.replicaSetWatches {
+		ch <- rs
+	}
+}
+
+func (c *FakeK8sClient) WatchIngresses(ctx context.Context, ls labels.Selector) (<-chan IngressEvent, error) {
+	c.watcherMu.Lock()
+	defer c.watcherMu.Unlock()
+	ch := make(chan IngressEvent, 20)
+	c.ingressWatches = append(c.ingressWatches, ch)
+	return ch, nil
+}
+
+func (c *FakeK8sClient) EmitIngress(ing *extv1beta1.Ingress) {
+	c.emitIngressEvent(IngressEvent{Ingress: ing})
+}
+
+func (c *FakeK8sClient) EmitIngressDelete(ing *extv1beta1.Ingress) {
+	c.emitIngressEvent(IngressEvent{Ingress: ing, Deleted: true})
+}
+
+func (c *FakeK8sClient) emitIngressEvent(e IngressEvent) {
+	c.watcherMu.Lock()
+	defer c.watcherMu.Unlock()
+	for _, ch := range c.ingressWatches {
+		ch <- e
+	}
+}
+
+func (c *FakeK8sClient) EmitEvent(e *v1.Event) {
+	c.watcherMu.Lock()
+	defer c.watcherMu.Unlock()
+	for _, w := range c.eventWatches {
+		if w.ns == Namespace(Namespace(e.Namespace).String()) {
+			w.ch <- e
+		}
+	}
+}
+
 func (c *FakeK8sClient) WatchedSelectors() []labels.Selector {
 	c.watcherMu.Lock()
 	defer c.watcherMu.Unlock()
@@ -121,6 +257,18 @@ func (c *FakeK8sClient) Upsert(ctx context.Context, entities []K8sEntity) error
 		return errors.Wrap(err, "kubectl apply")
 	}
 	c.Yaml = yaml
+	c.ServerSideApplied = false
+	c.ForcedConflicts = false
+	return nil
+}
+
+func (c *FakeK8sClient) ServerSideApply(ctx context.Context, entities []K8sEntity, forceConflicts bool) error {
+	err := c.Upsert(ctx, entities)
+	if err != nil {
+		return err
+	}
+	c.ServerSideApplied = true
+	c.ForcedConflicts = forceConflicts
 	return nil
 }
 
@@ -130,9 +278,42 @@ func (c *FakeK8sClient) Delete(ctx context.Context, entities []K8sEntity) error
 		return errors.Wrap(err, "kubectl delete")
 	}
 	c.DeletedYaml = yaml
+	c.DeleteCalls = append(c.DeleteCalls, entities)
 	return nil
 }
 
+func (c *FakeK8sClient) ListObjects(ctx context.Context, n Namespace, ls labels.Selector) ([]K8sEntity, error) {
+	c.ListObjectsNamespaces = append(c.ListObjectsNamespaces, n)
+	var result []K8sEntity
+	for _, e := range c.Objects {
+		m, err := meta.Accessor(e.Obj)
+		if err != nil {
+			return nil, err
+		}
+		if e.Namespace() == n && ls.Matches(labels.Set(m.GetLabels())) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (c *FakeK8sClient) ConfigNamespace() Namespace {
+	if c.ConfigNs == "" {
+		return DefaultNamespace
+	}
+	return c.ConfigNs
+}
+
+func (c *FakeK8sClient) ListVolumeClaims(ctx context.Context, n Namespace) ([]v1.PersistentVolumeClaim, error) {
+	var result []v1.PersistentVolumeClaim
+	for _, claim := range c.VolumeClaims {
+		if namespaceFromPVC(claim) == n {
+			result = append(result, claim)
+		}
+	}
+	return result, nil
+}
+
 func (c *FakeK8sClient) WatchPod(ctx context.Context, pod *v1.Pod) (watch.Interface, error) {
 	return watch.NewEmptyWatch(), nil
 }
@@ -154,7 +335,23 @@ func (c *FakeK8sClient) ContainerLogs(ctx context.Context, pID PodID, cName cont
 	return BufferCloser{Buffer: bytes.NewBuffer(nil)}, nil
 }
 
+func (c *FakeK8sClient) ListPods(ctx context.Context, n Namespace, ls labels.Selector) ([]v1.Pod, error) {
+	var result []v1.Pod
+	for _, pod := range c.Pods {
+		if pod.Namespace == n.String() && ls.Matches(labels.Set(pod.Labels)) {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
 func (c *FakeK8sClient) PodByID(ctx context.Context, pID PodID, n Namespace) (*v1.Pod, error) {
+	for _, pod := range c.Pods {
+		if pod.Name == pID.String() && pod.Namespace == n.String() {
+			pod := pod
+			return &pod, nil
+		}
+	}
 	return nil, nil
 }
 
@@ -203,10 +400,130 @@ func (c *FakeK8sClient) applyWasCalled() bool {
 	return c.Yaml != ""
 }
 
-func (c *FakeK8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (int, func(), error) {
+func (c *FakeK8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (PortForward, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.LastForwardPortPodID = podID
 	c.LastForwardPortRemotePort = remotePort
-	return optionalLocalPort, func() {}, nil
+	c.ForwardPortCount++
+	if len(c.ForwardPortErrors) > 0 {
+		err := c.ForwardPortErrors[0]
+		c.ForwardPortErrors = c.ForwardPortErrors[1:]
+		return PortForward{}, err
+	}
+
+	done := make(chan error, 1)
+	c.LastForwardPortDone = done
+	return PortForward{LocalPort: optionalLocalPort, Close: func() {}, Done: done}, nil
+}
+
+func (c *FakeK8sClient) CreateNamespaceIfMissing(ctx context.Context, n Namespace) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.CreatedNamespaces = append(c.CreatedNamespaces, n)
+	return nil
+}
+
+func (c *FakeK8sClient) DeleteNamespaceIfCreatedByTilt(ctx context.Context, n Namespace) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.DeletedNamespaces = append(c.DeletedNamespaces, n)
+	return nil
+}
+
+func (c *FakeK8sClient) HelmUpgrade(ctx context.Context, release model.HelmRelease, imageValues []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.UpgradedHelmReleases = append(c.UpgradedHelmReleases, release)
+	c.HelmImageValues = imageValues
+
+	err := c.HelmUpgradeError
+	c.HelmUpgradeError = nil
+	return err
+}
+
+func (c *FakeK8sClient) HelmUninstall(ctx context.Context, release model.HelmRelease) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.UninstalledHelmReleases = append(c.UninstalledHelmReleases, release)
+	return nil
+}
+
+func (c *FakeK8sClient) HelmClearPending(ctx context.Context, release model.HelmRelease) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ClearedHelmReleases = append(c.ClearedHelmReleases, release)
+	return nil
+}
+
+func (c *FakeK8sClient) ServiceEndpoints(ctx context.Context, n Namespace, name string, port int) ([]ServiceEndpoint, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	eps, ok := c.ServiceEndpointsByName[name]
+	if !ok || len(eps) == 0 {
+		return nil, fmt.Errorf("service %s has no ready pods", name)
+	}
+	return append([]ServiceEndpoint{}, eps...), nil
+}
+
+func (c *FakeK8sClient) SetServiceEndpoints(name string, eps []ServiceEndpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ServiceEndpointsByName == nil {
+		c.ServiceEndpointsByName = make(map[string][]ServiceEndpoint)
+	}
+	c.ServiceEndpointsByName[name] = eps
+}
+
+func (c *FakeK8sClient) LastForwardPodID() PodID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.LastForwardPortPodID
+}
+
+func (c *FakeK8sClient) ForwardPortCallCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ForwardPortCount
+}
+
+// Simulates the connection to the pod dropping on the most recent port forward.
+func (c *FakeK8sClient) DropLastPortForward(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.LastForwardPortDone <- err
+}
+
+func (c *FakeK8sClient) CheckPermissions(ctx context.Context, perms []Permission) ([]PermissionCheck, error) {
+	result := make([]PermissionCheck, 0, len(perms))
+	for _, p := range perms {
+		result = append(result, PermissionCheck{Permission: p, Allowed: !c.DeniedPermissions[p]})
+	}
+	return result, nil
+}
+
+func (c *FakeK8sClient) Diff(ctx context.Context, entities []K8sEntity, serverSide bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.DiffCalls = append(c.DiffCalls, entities)
+	return c.DiffResult, nil
+}
+
+func (c *FakeK8sClient) PodMetrics(ctx context.Context, ns Namespace) ([]PodMetrics, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.MetricsErr != nil {
+		return nil, c.MetricsErr
+	}
+	var result []PodMetrics
+	for _, pm := range c.Metrics {
+		if pm.Namespace == ns {
+			result = append(result, pm)
+		}
+	}
+	return result, nil
 }
 
 func (c *FakeK8sClient) ContainerRuntime(ctx context.Context) container.Runtime {
@@ -220,6 +537,18 @@ func (c *FakeK8sClient) Exec(ctx context.Context, podID PodID, cName container.N
 	return nil
 }
 
+func (c *FakeK8sClient) ExecTTY(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, sizes remotecommand.TerminalSizeQueue) error {
+	c.ExecTTYCalls = append(c.ExecTTYCalls, ExecCall{PodID: podID, ContainerName: cName, Namespace: n, Cmd: cmd})
+	return nil
+}
+
+type ExecCall struct {
+	PodID         PodID
+	ContainerName container.Name
+	Namespace     Namespace
+	Cmd           []string
+}
+
 type BufferCloser struct {
 	*bytes.Buffer
 }
@@ -0,0 +1,184 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/logger"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+type helmRunner interface {
+	exec(ctx context.Context, argv []string) (stdout string, stderr string, err error)
+}
+
+type realHelmRunner struct {
+	kubeContext KubeContext
+
+	// Any flags that pick the kubeconfig and credentials, besides the context.
+	connectionArgs []string
+
+	// Helm can't pick a user from the kubeconfig, so if the connection
+	// needs one, we fail rather than deploy as the wrong user.
+	user string
+}
+
+var _ helmRunner = realHelmRunner{}
+
+func (h realHelmRunner) exec(ctx context.Context, args []string) (stdout string, stderr string, err error) {
+	if h.user != "" {
+		return "", "", fmt.Errorf("helm can't deploy as kubeconfig user %q. Use a kube context with that user instead", h.user)
+	}
+
+	globalArgs := append([]string{"--kube-context", string(h.kubeContext)}, h.connectionArgs...)
+	args = append(globalArgs, args...)
+	c := exec.CommandContext(ctx, "helm", args...)
+
+	stdoutBuf := &bytes.Buffer{}
+	stderrBuf := &bytes.Buffer{}
+	c.Stdout = stdoutBuf
+	c.Stderr = stderrBuf
+
+	err = c.Run()
+	if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+		return "", "", fmt.Errorf("helm CLI not found. Install it (https://helm.sh/docs/intro/install/) to deploy Helm releases")
+	}
+	return stdoutBuf.String(), stderrBuf.String(), err
+}
+
+func ProvideHelmRunner(kubeContext KubeContext) helmRunner {
+	return realHelmRunner{
+		kubeContext: kubeContext,
+	}
+}
+
+func newHelmRunnerForConnection(conn Connection) helmRunner {
+	var args []string
+	if conn.KubeConfigPath != "" {
+		args = append(args, "--kubeconfig", conn.KubeConfigPath)
+	}
+	if conn.ImpersonateUser != "" {
+		args = append(args, "--kube-as-user", conn.ImpersonateUser)
+	}
+	for _, g := range conn.impersonateGroups() {
+		args = append(args, "--kube-as-group", g)
+	}
+	return realHelmRunner{
+		kubeContext:    conn.Context,
+		connectionArgs: args,
+		user:           conn.User,
+	}
+}
+
+// Installs the release, or upgrades it if it's already installed.
+//
+// imageValues are key=value pairs that point the chart at the images we built.
+// We pass them with --set-string, so that Helm doesn't try to parse tags as numbers.
+func (k K8sClient) HelmUpgrade(ctx context.Context, release model.HelmRelease, imageValues []string) error {
+	logger.Get(ctx).Infof("Upgrading Helm release %s (chart: %s)", release.Name, release.Chart)
+
+	stdout, stderr, err := k.helmRunner.exec(ctx, helmUpgradeArgs(release, imageValues))
+	if err != nil {
+		return errors.Wrapf(err, "helm upgrade %s:\nstderr: %s", release.Name, stderr)
+	}
+	logger.Get(ctx).Debugf("%s", stdout)
+	return nil
+}
+
+// Uninstalls the release, running the chart's delete hooks.
+func (k K8sClient) HelmUninstall(ctx context.Context, release model.HelmRelease) error {
+	logger.Get(ctx).Infof("Uninstalling Helm release %s", release.Name)
+
+	args := []string{"uninstall", release.Name}
+	if release.Namespace != "" {
+		args = append(args, "--namespace", release.Namespace)
+	}
+
+	_, stderr, err := k.helmRunner.exec(ctx, args)
+	if err != nil {
+		if strings.Contains(stderr, "not found") {
+			return nil
+		}
+		return errors.Wrapf(err, "helm uninstall %s:\nstderr: %s", release.Name, stderr)
+	}
+	return nil
+}
+
+// If an install or upgrade didn't finish (e.g., it timed out, or Tilt was
+// killed), Helm leaves the release in a pending-* state and refuses to touch it
+// again. Rolls it back to the last revision that deployed, or uninstalls it if
+// it never deployed.
+func (k K8sClient) HelmClearPending(ctx context.Context, release model.HelmRelease) error {
+	status, err := k.helmStatus(ctx, release)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case "pending-install":
+		logger.Get(ctx).Infof("Helm release %s didn't finish installing. Uninstalling it", release.Name)
+		return k.HelmUninstall(ctx, release)
+	case "pending-upgrade", "pending-rollback":
+		logger.Get(ctx).Infof("Helm release %s is stuck in %s. Rolling it back", release.Name, status)
+		args := []string{"rollback", release.Name}
+		if release.Namespace != "" {
+			args = append(args, "--namespace", release.Namespace)
+		}
+		_, stderr, err := k.helmRunner.exec(ctx, args)
+		if err != nil {
+			return errors.Wrapf(err, "helm rollback %s:\nstderr: %s", release.Name, stderr)
+		}
+	}
+	return nil
+}
+
+// The release's status (e.g., "deployed" or "pending-upgrade"), or "" if
+// it isn't installed.
+func (k K8sClient) helmStatus(ctx context.Context, release model.HelmRelease) (string, error) {
+	args := []string{"status", release.Name, "--output", "json"}
+	if release.Namespace != "" {
+		args = append(args, "--namespace", release.Namespace)
+	}
+
+	stdout, stderr, err := k.helmRunner.exec(ctx, args)
+	if err != nil {
+		if strings.Contains(stderr, "not found") {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "helm status %s:\nstderr: %s", release.Name, stderr)
+	}
+
+	var status struct {
+		Info struct {
+			Status string `json:"status"`
+		} `json:"info"`
+	}
+	err = json.Unmarshal([]byte(stdout), &status)
+	if err != nil {
+		return "", errors.Wrapf(err, "helm status %s", release.Name)
+	}
+	return status.Info.Status, nil
+}
+
+func helmUpgradeArgs(release model.HelmRelease, imageValues []string) []string {
+	args := []string{"upgrade", "--install", release.Name, release.Chart}
+	if release.Namespace != "" {
+		args = append(args, "--namespace", release.Namespace)
+	}
+	for _, f := range release.ValuesFiles {
+		args = append(args, "--values", f)
+	}
+	for _, v := range release.Set {
+		args = append(args, "--set", v)
+	}
+	for _, v := range imageValues {
+		args = append(args, "--set-string", v)
+	}
+	return args
+}
@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestHelmUpgrade(t *testing.T) {
+	f := newClientTestFixture(t)
+	release := model.HelmRelease{
+		Name:        "frontend",
+		Chart:       "/charts/frontend",
+		Namespace:   "web",
+		ValuesFiles: []string{"/charts/frontend/dev.yaml"},
+		Set:         []string{"replicas=2"},
+	}
+
+	err := f.client.HelmUpgrade(f.ctx, release, []string{"image.tag=tilt-1234"})
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(f.helm.calls)) {
+		assert.Equal(t, []string{
+			"upgrade", "--install", "frontend", "/charts/frontend",
+			"--namespace", "web",
+			"--values", "/charts/frontend/dev.yaml",
+			"--set", "replicas=2",
+			"--set-string", "image.tag=tilt-1234",
+		}, f.helm.calls[0].argv)
+	}
+	assert.Empty(t, f.runner.calls)
+}
+
+func TestHelmClearPendingUpgrade(t *testing.T) {
+	f := newClientTestFixture(t)
+	release := model.HelmRelease{Name: "frontend", Chart: "stable/frontend", Namespace: "web"}
+
+	f.helm.stdout = `{"name": "frontend", "info": {"status": "pending-upgrade"}}`
+	err := f.client.HelmClearPending(f.ctx, release)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(f.helm.calls)) {
+		assert.Equal(t, []string{"status", "frontend", "--output", "json", "--namespace", "web"}, f.helm.calls[0].argv)
+		assert.Equal(t, []string{"rollback", "frontend", "--namespace", "web"}, f.helm.calls[1].argv)
+	}
+}
+
+func TestHelmClearPendingInstall(t *testing.T) {
+	f := newClientTestFixture(t)
+	release := model.HelmRelease{Name: "frontend", Chart: "stable/frontend"}
+
+	// There's nothing to roll back to, so we uninstall it.
+	f.helm.stdout = `{"name": "frontend", "info": {"status": "pending-install"}}`
+	err := f.client.HelmClearPending(f.ctx, release)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(f.helm.calls)) {
+		assert.Equal(t, []string{"uninstall", "frontend"}, f.helm.calls[1].argv)
+	}
+}
+
+func TestHelmClearPendingDeployed(t *testing.T) {
+	f := newClientTestFixture(t)
+	release := model.HelmRelease{Name: "frontend", Chart: "stable/frontend"}
+
+	f.helm.stdout = `{"name": "frontend", "info": {"status": "deployed"}}`
+	err := f.client.HelmClearPending(f.ctx, release)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(f.helm.calls))
+
+	// Nor is there anything to do if it isn't installed at all.
+	f.helm.stderr = "Error: release: not found"
+	f.helm.err = fmt.Errorf("exit status 1")
+	err = f.client.HelmClearPending(f.ctx, release)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(f.helm.calls))
+}
+
+func TestHelmUninstall(t *testing.T) {
+	f := newClientTestFixture(t)
+	release := model.HelmRelease{Name: "frontend", Chart: "stable/frontend"}
+
+	err := f.client.HelmUninstall(f.ctx, release)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(f.helm.calls)) {
+		assert.Equal(t, []string{"uninstall", "frontend"}, f.helm.calls[0].argv)
+	}
+
+	// Releases that are already gone are fine.
+	f.helm.stderr = "Error: uninstall: Release not loaded: frontend: release: not found"
+	f.helm.err = fmt.Errorf("exit status 1")
+	err = f.client.HelmUninstall(f.ctx, release)
+	assert.Nil(t, err)
+}
@@ -23,6 +23,14 @@ func InjectImagePullPolicy(entity K8sEntity, policy v1.PullPolicy) (K8sEntity, e
 	for _, container := range containers {
 		container.ImagePullPolicy = policy
 	}
+
+	if content, ok := unstructuredContent(entity); ok {
+		for _, s := range findUnstructuredPodSpecs(content) {
+			for _, c := range s.containers() {
+				c["imagePullPolicy"] = string(policy)
+			}
+		}
+	}
 	return entity, nil
 }
 
@@ -101,6 +109,27 @@ func injectImageDigestInContainers(entity K8sEntity, selector container.RefSelec
 		}
 	}
 
+	if content, ok := unstructuredContent(entity); ok {
+		for _, s := range findUnstructuredPodSpecs(content) {
+			for _, c := range s.containers() {
+				// We found this pod spec by its shape, so it might not be a pod
+				// spec at all, or its image might be a template that the
+				// operator fills in. Leave those alone.
+				image, _ := c["image"].(string)
+				existingRef, err := container.ParseNamed(image)
+				if err != nil {
+					continue
+				}
+
+				if selector.Matches(existingRef) {
+					c["image"] = injectRef.String()
+					c["imagePullPolicy"] = string(policy)
+					replaced = true
+				}
+			}
+		}
+	}
+
 	return entity, replaced, nil
 }
 
@@ -207,6 +236,21 @@ func (e K8sEntity) FindImages(imageJSONPaths []JSONPath) ([]reference.Named, err
 		result = append(result, ref)
 	}
 
+	// Look for images in the pod specs of custom resources
+	if content, ok := unstructuredContent(e); ok {
+		for _, s := range findUnstructuredPodSpecs(content) {
+			for _, c := range s.containers() {
+				// Skip images that aren't refs, like in injectImageDigestInContainers.
+				image, _ := c["image"].(string)
+				ref, err := container.ParseNamed(image)
+				if err != nil {
+					continue
+				}
+				result = append(result, ref)
+			}
+		}
+	}
+
 	var obj interface{}
 	if u, ok := e.Obj.(runtime.Unstructured); ok {
 		obj = u.UnstructuredContent()
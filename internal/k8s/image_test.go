@@ -47,6 +47,132 @@ func TestInjectDigestSanchoYAML(t *testing.T) {
 	}
 }
 
+func TestInjectDigestCronJob(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.CronJobYAML)
+	name := "gcr.io/foo/hello"
+	digest := "sha256:2baf1f40105d9501fe319a8ec463fdf4325a2a5df445adf3f572f626253678c9"
+	newEntity, replaced, err := InjectImageDigestWithStrings(entity, name, digest, v1.PullIfNotPresent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, replaced)
+
+	result, err := SerializeYAML([]K8sEntity{newEntity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, result, fmt.Sprintf("image: %s@%s", name, digest))
+}
+
+func TestInjectDigestCustomResourcePodTemplate(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.OperatorAppYAML)
+	name := "gcr.io/foo/frontend"
+	digest := "sha256:2baf1f40105d9501fe319a8ec463fdf4325a2a5df445adf3f572f626253678c9"
+	newEntity, replaced, err := InjectImageDigestWithStrings(entity, name, digest, v1.PullIfNotPresent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, replaced)
+
+	result, err := SerializeYAML([]K8sEntity{newEntity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, result, fmt.Sprintf("image: %s@%s", name, digest))
+	assert.Contains(t, result, "imagePullPolicy: IfNotPresent")
+}
+
+func TestInjectDigestInitContainer(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SanchoMigrateInitContainerYAML)
+	ref := container.MustParseNamedTagged("gcr.io/some-project-162817/sancho-migrate:tilt-deadbeef")
+	newEntity, replaced, err := InjectImageDigest(entity, container.NameSelector(ref), ref, v1.PullNever)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, replaced)
+
+	pods, err := ExtractPods(&newEntity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ref.String(), pods[0].InitContainers[0].Image)
+	assert.Equal(t, v1.PullNever, pods[0].InitContainers[0].ImagePullPolicy)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho", pods[0].Containers[0].Image)
+}
+
+func TestInjectDigestCustomResourceEphemeralContainer(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.OperatorAppEphemeralContainerYAML)
+	images, err := entity.FindImages(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(images))
+
+	name := "gcr.io/foo/debugger"
+	digest := "sha256:2baf1f40105d9501fe319a8ec463fdf4325a2a5df445adf3f572f626253678c9"
+	newEntity, replaced, err := InjectImageDigestWithStrings(entity, name, digest, v1.PullIfNotPresent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, replaced)
+
+	result, err := SerializeYAML([]K8sEntity{newEntity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, result, fmt.Sprintf("image: %s@%s", name, digest))
+	assert.Contains(t, result, "image: gcr.io/foo/frontend\n")
+}
+
+func TestFindImagesCustomResourcePodTemplate(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.OperatorAppYAML)
+	images, err := entity.FindImages(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 1, len(images)) {
+		assert.Equal(t, "gcr.io/foo/frontend", images[0].String())
+	}
+
+	// The CRD itself mentions an image, but not in a pod spec.
+	entities, err := ParseYAMLFromString(testyaml.CRDYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	images, err = entities[0].FindImages(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, images)
+}
+
+func TestCustomResourceTemplatedImage(t *testing.T) {
+	yaml := strings.Replace(testyaml.OperatorAppYAML, "image: gcr.io/foo/frontend",
+		"image: gcr.io/foo/frontend\n      - name: sidecar\n        image: \"{{ .Values.sidecarImage }}\"", 1)
+	entity := parseOneEntity(t, yaml)
+
+	images, err := entity.FindImages(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 1, len(images)) {
+		assert.Equal(t, "gcr.io/foo/frontend", images[0].String())
+	}
+
+	digest := "sha256:2baf1f40105d9501fe319a8ec463fdf4325a2a5df445adf3f572f626253678c9"
+	newEntity, replaced, err := InjectImageDigestWithStrings(entity, "gcr.io/foo/frontend", digest, v1.PullIfNotPresent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, replaced)
+
+	result, err := SerializeYAML([]K8sEntity{newEntity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, result, "image: '{{ .Values.sidecarImage }}'")
+}
+
 func TestInjectDigestDoesNotMutateOriginal(t *testing.T) {
 	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
 	if err != nil {
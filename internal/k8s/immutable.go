@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// kubectl names the object that failed validation, with a kind that may be
+// qualified by its API group (e.g., `The Deployment "sancho" is invalid` or
+// `deployments.apps "sancho" is invalid`).
+var invalidObjectRe = regexp.MustCompile(`([A-Za-z]+)(?:\.[a-z0-9.\-]+)? "([^"]+)" is invalid`)
+
+// Kinds that we never delete to get around an immutable field error,
+// because deleting them also deletes the data (or the objects) inside them.
+var unrecreatableKinds = map[string]bool{
+	"Namespace":                true,
+	"PersistentVolume":         true,
+	"PersistentVolumeClaim":    true,
+	"CustomResourceDefinition": true,
+}
+
+// Picks out the entities that kubectl apply couldn't update because of an
+// immutable field error, so that we can delete and recreate just those
+// (rather than everything in the apply).
+//
+// Returns an error if we can't tell which objects failed from stderr, or if one
+// of the objects that failed is a kind we won't recreate. Guessing wrong would
+// mean deleting objects that didn't need it (and everything that depends on them).
+func entitiesToRecreate(entities []K8sEntity, stderr string) ([]K8sEntity, error) {
+	var result []K8sEntity
+	for _, line := range strings.Split(stderr, "\n") {
+		if !maybeImmutableFieldStderr(line) {
+			continue
+		}
+
+		for _, match := range invalidObjectRe.FindAllStringSubmatch(line, -1) {
+			kind, name := match[1], match[2]
+			for _, e := range entities {
+				if e.Name() != name || !kindMatches(e, kind) || containsEntity(result, e) {
+					continue
+				}
+				if unrecreatableKinds[e.Kind.Kind] {
+					return nil, fmt.Errorf("%s %q can't be updated in place because an immutable field changed, "+
+						"and Tilt won't delete it for you because that would delete everything in it. "+
+						"Delete it manually (e.g., `kubectl delete %s %s`) to recreate it",
+						e.Kind.Kind, e.Name(), strings.ToLower(e.Kind.Kind), e.Name())
+				}
+				result = append(result, e)
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("an immutable field changed, but Tilt couldn't tell which object it belongs to, " +
+			"so it won't delete anything. Delete the object manually (e.g., `kubectl delete KIND NAME`) to recreate it")
+	}
+	return result, nil
+}
+
+// Matches the kind of an entity against a kind as kubectl prints it in an error,
+// which is either the Kind (e.g., "StatefulSet") or its resource name (e.g., "statefulsets").
+func kindMatches(e K8sEntity, kind string) bool {
+	if e.Kind == nil {
+		return false
+	}
+	k := strings.ToLower(e.Kind.Kind)
+	kind = strings.ToLower(kind)
+	return kind == k || kind == k+"s" || kind == k+"es"
+}
+
+func containsEntity(entities []K8sEntity, e K8sEntity) bool {
+	for _, existing := range entities {
+		if existing.Name() == e.Name() && existing.Kind.Kind == e.Kind.Kind && existing.Namespace() == e.Namespace() {
+			return true
+		}
+	}
+	return false
+}
+
+func entityNames(entities []K8sEntity) string {
+	names := make([]string, 0, len(entities))
+	for _, e := range entities {
+		names = append(names, fmt.Sprintf("%s/%s", e.Kind.Kind, e.Name()))
+	}
+	return strings.Join(names, ", ")
+}
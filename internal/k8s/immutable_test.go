@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestEntitiesToRecreateNamedInError(t *testing.T) {
+	entities, err := ParseYAMLFromString(yamlJoin(testyaml.MyNamespaceYAML, testyaml.SanchoYAML, testyaml.PostgresYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stderr := `The Deployment "sancho" is invalid: spec.selector: Invalid value: v1.LabelSelector{MatchLabels:map[string]string{"app":"sancho"}}: field is immutable
+Error from server (Invalid): error when applying patch: for: "STDIN": services "postgres" is invalid: spec.clusterIP: Invalid value: "": field is immutable`
+	result, err := entitiesToRecreate(entities, stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Deployment/sancho, Service/postgres", entityNames(result))
+}
+
+func TestEntitiesToRecreateUnknownObjects(t *testing.T) {
+	entities, err := ParseYAMLFromString(yamlJoin(testyaml.MyNamespaceYAML, testyaml.PostgresYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// If we can't tell what failed, don't delete anything.
+	result, err := entitiesToRecreate(entities, "error: field is immutable")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "couldn't tell which object")
+	}
+	assert.Empty(t, result)
+}
@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+)
+
+// A URL that an Ingress routes to a Service.
+type IngressEndpoint struct {
+	ServiceName ServiceName
+	URL         *url.URL
+}
+
+// Returns the URLs that the Ingress serves, one for each rule path (or one for the
+// default backend, if there are no rules), with the Service that each one routes to.
+//
+// Rules without a host are served at the address of the Ingress's load balancer,
+// so we skip them until the Ingress controller has assigned one.
+func IngressEndpoints(ing *extv1beta1.Ingress) []IngressEndpoint {
+	lbHost := ""
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		if lb.Hostname != "" {
+			lbHost = lb.Hostname
+			break
+		}
+		if lb.IP != "" {
+			lbHost = lb.IP
+			break
+		}
+	}
+
+	var result []IngressEndpoint
+	seen := map[string]bool{}
+	add := func(ruleHost string, path string, backend extv1beta1.IngressBackend) {
+		host := ruleHost
+		if host == "" {
+			host = lbHost
+		}
+		// We can't guess a URL for a wildcard host.
+		if host == "" || strings.HasPrefix(host, "*") {
+			return
+		}
+
+		u := &url.URL{
+			Scheme: ingressScheme(ing, ruleHost),
+			Host:   host,
+			Path:   ingressURLPath(path),
+		}
+		if seen[u.String()] {
+			return
+		}
+		seen[u.String()] = true
+		result = append(result, IngressEndpoint{
+			ServiceName: ServiceName(backend.ServiceName),
+			URL:         u,
+		})
+	}
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			add(rule.Host, p.Path, p.Backend)
+		}
+	}
+
+	if len(ing.Spec.Rules) == 0 && ing.Spec.Backend != nil {
+		add("", "", *ing.Spec.Backend)
+	}
+	return result
+}
+
+// Hosts that the Ingress has a TLS certificate for are served over https.
+// A TLS entry without hosts covers every host.
+func ingressScheme(ing *extv1beta1.Ingress, host string) string {
+	for _, tls := range ing.Spec.TLS {
+		if len(tls.Hosts) == 0 {
+			return "https"
+		}
+		for _, h := range tls.Hosts {
+			if h == host {
+				return "https"
+			}
+		}
+	}
+	return "http"
+}
+
+// Ingress controllers accept path patterns (e.g., "/api/*" on GCE, or regular expressions
+// on nginx). Browsers don't, so we link to the literal prefix of the pattern.
+func ingressURLPath(path string) string {
+	if i := strings.IndexAny(path, "*(?[$^|"); i != -1 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = fmt.Sprintf("/%s", path)
+	}
+	return path
+}
@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+)
+
+func TestIngressEndpointsRules(t *testing.T) {
+	ing := &extv1beta1.Ingress{
+		Spec: extv1beta1.IngressSpec{
+			TLS: []extv1beta1.IngressTLS{{Hosts: []string{"secure.example.com"}}},
+			Rules: []extv1beta1.IngressRule{
+				ingressRule("secure.example.com", "/", "frontend"),
+				ingressRule("api.example.com", "/v1/*", "api"),
+				ingressRule("api.example.com", "/v2(/|$)(.*)", "api-v2"),
+				ingressRule("*.example.com", "/", "catchall"),
+			},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"https://secure.example.com/ -> frontend",
+		"http://api.example.com/v1/ -> api",
+		"http://api.example.com/v2 -> api-v2",
+	}, ingressEndpointStrings(IngressEndpoints(ing)))
+}
+
+func TestIngressEndpointsNoHost(t *testing.T) {
+	ing := &extv1beta1.Ingress{
+		Spec: extv1beta1.IngressSpec{
+			Rules: []extv1beta1.IngressRule{ingressRule("", "/app", "app")},
+		},
+	}
+
+	// No load balancer address yet, so we don't know where the rule is served.
+	assert.Empty(t, IngressEndpoints(ing))
+
+	ing.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "35.1.2.3"}}
+	assert.Equal(t, []string{"http://35.1.2.3/app -> app"}, ingressEndpointStrings(IngressEndpoints(ing)))
+}
+
+func TestIngressEndpointsDefaultBackend(t *testing.T) {
+	ing := &extv1beta1.Ingress{
+		Spec: extv1beta1.IngressSpec{
+			Backend: &extv1beta1.IngressBackend{ServiceName: "web"},
+		},
+		Status: extv1beta1.IngressStatus{
+			LoadBalancer: v1.LoadBalancerStatus{
+				Ingress: []v1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"http://lb.example.com/ -> web"}, ingressEndpointStrings(IngressEndpoints(ing)))
+}
+
+func ingressRule(host string, path string, service string) extv1beta1.IngressRule {
+	return extv1beta1.IngressRule{
+		Host: host,
+		IngressRuleValue: extv1beta1.IngressRuleValue{
+			HTTP: &extv1beta1.HTTPIngressRuleValue{
+				Paths: []extv1beta1.HTTPIngressPath{
+					{Path: path, Backend: extv1beta1.IngressBackend{ServiceName: service}},
+				},
+			},
+		},
+	}
+}
+
+func ingressEndpointStrings(eps []IngressEndpoint) []string {
+	var result []string
+	for _, ep := range eps {
+		result = append(result, ep.URL.String()+" -> "+string(ep.ServiceName))
+	}
+	return result
+}
@@ -14,12 +14,16 @@ type kubectlRunner interface {
 
 type realKubectlRunner struct {
 	kubeContext KubeContext
+
+	// Any flags that pick the kubeconfig and credentials, besides the context.
+	connectionArgs []string
 }
 
 var _ kubectlRunner = realKubectlRunner{}
 
 func (k realKubectlRunner) prependGlobalArgs(args []string) []string {
-	return append([]string{"--context", string(k.kubeContext)}, args...)
+	result := append([]string{"--context", string(k.kubeContext)}, k.connectionArgs...)
+	return append(result, args...)
 }
 
 func (k realKubectlRunner) exec(ctx context.Context, args []string) (stdout string, stderr string, err error) {
@@ -54,3 +58,23 @@ func ProvideKubectlRunner(kubeContext KubeContext) kubectlRunner {
 		kubeContext: kubeContext,
 	}
 }
+
+func newKubectlRunnerForConnection(conn Connection) kubectlRunner {
+	var args []string
+	if conn.KubeConfigPath != "" {
+		args = append(args, "--kubeconfig", conn.KubeConfigPath)
+	}
+	if conn.User != "" {
+		args = append(args, "--user", conn.User)
+	}
+	if conn.ImpersonateUser != "" {
+		args = append(args, "--as", conn.ImpersonateUser)
+	}
+	for _, g := range conn.impersonateGroups() {
+		args = append(args, "--as-group", g)
+	}
+	return realKubectlRunner{
+		kubeContext:    conn.Context,
+		connectionArgs: args,
+	}
+}
@@ -9,6 +9,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -67,9 +68,43 @@ func injectLabels(entity K8sEntity, labels []model.LabelPair, overwrite bool) (K
 			meta.Labels[label.Key] = label.Value
 		}
 	}
+
+	if u, ok := entity.Obj.(*unstructured.Unstructured); ok {
+		injectUnstructuredLabels(u, labels, overwrite)
+	}
 	return entity, nil
 }
 
+// Custom resources don't have an ObjectMeta for us to find, so we add the labels
+// to the object's own metadata and to any pod templates we can find, so that
+// pods created from those templates are associated with the resource.
+func injectUnstructuredLabels(u *unstructured.Unstructured, labels []model.LabelPair, overwrite bool) {
+	objLabels := u.GetLabels()
+	if overwrite || objLabels == nil {
+		objLabels = make(map[string]string, len(labels))
+	}
+	for _, label := range labels {
+		objLabels[label.Key] = label.Value
+	}
+	u.SetLabels(objLabels)
+
+	for _, s := range findUnstructuredPodSpecs(u.UnstructuredContent()) {
+		if s.template == nil {
+			continue
+		}
+
+		meta := s.templateMetadata()
+		templateLabels, ok := meta["labels"].(map[string]interface{})
+		if overwrite || !ok {
+			templateLabels = make(map[string]interface{}, len(labels))
+		}
+		for _, label := range labels {
+			templateLabels[label.Key] = label.Value
+		}
+		meta["labels"] = templateLabels
+	}
+}
+
 // In the v1beta1 API, if a Deployment didn't have a selector,
 // Kubernetes would automatically infer a selector based on the labels
 // in the pod.
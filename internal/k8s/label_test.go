@@ -7,6 +7,7 @@ import (
 
 	"github.com/windmilleng/tilt/internal/model"
 	"k8s.io/api/apps/v1beta2"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/windmilleng/tilt/internal/k8s/testyaml"
@@ -163,6 +164,66 @@ func TestInjectStatefulSet(t *testing.T) {
 	assert.Equal(t, 2, strings.Count(result, "tilt-runid: deadbeef"))
 }
 
+func TestInjectLabelCronJob(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.CronJobYAML)
+	newEntity, err := InjectLabels(entity, []model.LabelPair{
+		{
+			Key:   "tilt-runid",
+			Value: "deadbeef",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cronJob := newEntity.Obj.(*batchv1beta1.CronJob)
+	assert.Equal(t, "deadbeef", cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta.Labels["tilt-runid"])
+
+	result, err := SerializeYAML([]K8sEntity{newEntity})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Inject in the top-level metadata, the job template, and the pod template.
+	assert.Equal(t, 3, strings.Count(result, "tilt-runid: deadbeef"))
+}
+
+func TestInjectLabelCustomResource(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.OperatorAppYAML)
+	newEntity, err := InjectLabels(entity, []model.LabelPair{
+		{
+			Key:   "tilt-runid",
+			Value: "deadbeef",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := ExtractPodTemplateSpec(newEntity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 1, len(templates)) {
+		assert.Equal(t, map[string]string{"app": "frontend", "tilt-runid": "deadbeef"}, templates[0].Labels)
+	}
+
+	result, err := SerializeYAML([]K8sEntity{newEntity})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Inject in the top-level metadata and the pod template.
+	assert.Equal(t, 2, strings.Count(result, "tilt-runid: deadbeef"))
+
+	// The original entity isn't modified.
+	templates, err = ExtractPodTemplateSpec(entity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"app": "frontend"}, templates[0].Labels)
+}
+
 func TestSelectorMatchesLabels(t *testing.T) {
 	entities, err := ParseYAMLFromString(testyaml.BlorgBackendYAML)
 	if err != nil {
@@ -1,6 +1,8 @@
 package k8s
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"strconv"
 
 	"github.com/google/uuid"
@@ -16,6 +18,18 @@ const ManifestNameLabel = "tilt-manifest"
 
 const TiltDeployIDLabel = "tilt-deployid"
 
+// Ties objects to the Tiltfile that deployed them, across runs of Tilt,
+// so that we can find the ones that the Tiltfile doesn't deploy anymore.
+const TiltProjectIDLabel = "tilt-project"
+
+// The label that ties the target's objects to its manifest.
+func ManifestLabelKey(t model.K8sTarget) string {
+	if t.ManifestLabel != "" {
+		return t.ManifestLabel
+	}
+	return ManifestNameLabel
+}
+
 func TiltRunLabel() model.LabelPair {
 	return model.LabelPair{
 		Key:   TiltRunIDLabel,
@@ -33,3 +47,15 @@ func TiltDeployLabel(dID model.DeployID) model.LabelPair {
 func TiltRunSelector() labels.Selector {
 	return labels.Set{TiltRunIDLabel: TiltRunID}.AsSelector()
 }
+
+// Identifies the Tiltfile at the given (absolute) path, as a label value.
+func TiltProjectID(tiltfilePath string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(tiltfilePath)))[:16]
+}
+
+func TiltProjectLabel(project string) model.LabelPair {
+	return model.LabelPair{
+		Key:   TiltProjectIDLabel,
+		Value: project,
+	}
+}
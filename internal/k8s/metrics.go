@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/windmilleng/tilt/internal/container"
+)
+
+// Returned by PodMetrics when the cluster doesn't serve the metrics API
+// (i.e., metrics-server isn't installed).
+var ErrMetricsUnavailable = fmt.Errorf("metrics API unavailable (is metrics-server installed?)")
+
+// How much CPU and memory a container is using, from metrics-server.
+type ContainerMetrics struct {
+	Name   container.Name
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+type PodMetrics struct {
+	PodID      PodID
+	Namespace  Namespace
+	Containers []ContainerMetrics
+}
+
+// The parts of a metrics.k8s.io/v1beta1 PodMetricsList that we use.
+// We read them as JSON, because the metrics client isn't vendored.
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Containers []struct {
+			Name  string                       `json:"name"`
+			Usage map[string]resource.Quantity `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+func (k K8sClient) PodMetrics(ctx context.Context, ns Namespace) ([]PodMetrics, error) {
+	body, err := k.clientSet.CoreV1().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", ns.String(), "pods").
+		Context(ctx).
+		DoRaw()
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+			return nil, ErrMetricsUnavailable
+		}
+		return nil, errors.Wrapf(err, "fetching pod metrics in namespace %q", ns)
+	}
+	return parsePodMetrics(body)
+}
+
+func parsePodMetrics(body []byte) ([]PodMetrics, error) {
+	var list podMetricsList
+	err := json.Unmarshal(body, &list)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing pod metrics")
+	}
+
+	result := make([]PodMetrics, 0, len(list.Items))
+	for _, item := range list.Items {
+		pm := PodMetrics{
+			PodID:     PodID(item.Metadata.Name),
+			Namespace: Namespace(item.Metadata.Namespace),
+		}
+		for _, c := range item.Containers {
+			pm.Containers = append(pm.Containers, ContainerMetrics{
+				Name:   container.Name(c.Name),
+				CPU:    c.Usage["cpu"],
+				Memory: c.Usage["memory"],
+			})
+		}
+		result = append(result, pm)
+	}
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParsePodMetrics(t *testing.T) {
+	body := `{
+  "kind": "PodMetricsList",
+  "apiVersion": "metrics.k8s.io/v1beta1",
+  "items": [
+    {
+      "metadata": {"name": "sancho-pod", "namespace": "default"},
+      "timestamp": "2019-06-05T15:04:05Z",
+      "window": "30s",
+      "containers": [
+        {"name": "sancho", "usage": {"cpu": "250m", "memory": "65536Ki"}}
+      ]
+    }
+  ]
+}`
+	metrics, err := parsePodMetrics([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []PodMetrics{{
+		PodID:     "sancho-pod",
+		Namespace: "default",
+		Containers: []ContainerMetrics{
+			{Name: "sancho", CPU: resource.MustParse("250m"), Memory: resource.MustParse("65536Ki")},
+		},
+	}}
+	assert.Equal(t, expected, metrics)
+}
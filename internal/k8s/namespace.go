@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/logger"
+)
+
+// Marks the namespaces that Tilt created, so that we only ever
+// delete namespaces that we own.
+const ManagedByLabel = "app.kubernetes.io/managed-by"
+const ManagedByTilt = "tilt"
+
+// Kinds that don't live in a namespace.
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+func IsClusterScoped(e K8sEntity) bool {
+	return e.Kind != nil && clusterScopedKinds[e.Kind.Kind]
+}
+
+// Returns a copy of the entity that lives in the given namespace.
+// Cluster-scoped entities are returned unchanged.
+func WithNamespace(e K8sEntity, n Namespace) (K8sEntity, error) {
+	if IsClusterScoped(e) {
+		return e, nil
+	}
+
+	e = e.DeepCopy()
+	m, err := meta.Accessor(e.Obj)
+	if err != nil {
+		return K8sEntity{}, errors.Wrapf(err, "setting namespace of %s", e.Name())
+	}
+	m.SetNamespace(n.String())
+	return e, nil
+}
+
+func NewNamespace(n Namespace) *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   n.String(),
+			Labels: map[string]string{ManagedByLabel: ManagedByTilt},
+		},
+	}
+}
+
+func (k K8sClient) CreateNamespaceIfMissing(ctx context.Context, n Namespace) error {
+	_, err := k.core.Namespaces().Get(n.String(), metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "getting namespace %s", n)
+	}
+
+	logger.Get(ctx).Infof("Creating namespace %s", n)
+	_, err = k.core.Namespaces().Create(NewNamespace(n))
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "creating namespace %s", n)
+	}
+	return nil
+}
+
+func (k K8sClient) DeleteNamespaceIfCreatedByTilt(ctx context.Context, n Namespace) error {
+	ns, err := k.core.Namespaces().Get(n.String(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "getting namespace %s", n)
+	}
+
+	if ns.Labels[ManagedByLabel] != ManagedByTilt {
+		return nil
+	}
+
+	logger.Get(ctx).Infof("Deleting namespace %s", n)
+	err = k.core.Namespaces().Delete(n.String(), &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting namespace %s", n)
+	}
+	return nil
+}
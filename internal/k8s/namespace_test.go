@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestWithNamespace(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SanchoYAML)
+	newEntity, err := WithNamespace(entity, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Namespace("alice"), newEntity.Namespace())
+	assert.Equal(t, Namespace("sancho-ns"), entity.Namespace())
+}
+
+func TestWithNamespaceCustomResource(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.OperatorAppYAML)
+	newEntity, err := WithNamespace(entity, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Namespace("alice"), newEntity.Namespace())
+}
+
+func TestWithNamespaceClusterScoped(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.MyNamespaceYAML)
+	newEntity, err := WithNamespace(entity, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "mynamespace", newEntity.Name())
+	assert.Equal(t, DefaultNamespace, newEntity.Namespace())
+}
@@ -0,0 +1,131 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// The kinds of objects that we look for when we clean up after a Tiltfile,
+// and the names that kubectl knows them by.
+//
+// We don't list every kind the cluster knows about, because a user who can't
+// list one of them (e.g., because of RBAC) would make the whole query fail.
+var orphanKinds = []struct {
+	kind     string
+	resource string
+}{
+	{"Deployment", "deployments"},
+	{"StatefulSet", "statefulsets"},
+	{"DaemonSet", "daemonsets"},
+	{"Job", "jobs"},
+	{"CronJob", "cronjobs"},
+	{"Pod", "pods"},
+	{"Service", "services"},
+	{"Ingress", "ingresses"},
+	{"ConfigMap", "configmaps"},
+	{"Secret", "secrets"},
+	{"PersistentVolumeClaim", "persistentvolumeclaims"},
+	{"ServiceAccount", "serviceaccounts"},
+	{"Role", "roles.rbac.authorization.k8s.io"},
+	{"RoleBinding", "rolebindings.rbac.authorization.k8s.io"},
+}
+
+// Whether ListObjects looks for objects of this kind.
+func ListsKind(kind string) bool {
+	for _, k := range orphanKinds {
+		if k.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Lists the objects in the namespace that match the selector.
+//
+// Only looks for the kinds of objects that Tiltfiles usually deploy (see orphanKinds).
+func (k K8sClient) ListObjects(ctx context.Context, n Namespace, ls labels.Selector) ([]K8sEntity, error) {
+	resources := make([]string, 0, len(orphanKinds))
+	for _, kind := range orphanKinds {
+		resources = append(resources, kind.resource)
+	}
+	args := []string{"get", strings.Join(resources, ","), "-n", n.String(), "-l", ls.String(), "-o", "yaml"}
+	stdout, stderr, err := k.kubectlRunner.exec(ctx, args)
+	if err != nil {
+		return nil, errors.Wrapf(err, "kubectl get:\nstderr: %s", stderr)
+	}
+	return parseObjectList(stdout)
+}
+
+// kubectl get returns its objects as a List, so we unpack them.
+func parseObjectList(yaml string) ([]K8sEntity, error) {
+	parsed, err := ParseYAMLFromString(yaml)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing kubectl get output")
+	}
+
+	var result []K8sEntity
+	for _, e := range parsed {
+		list, ok := e.Obj.(*v1.List)
+		if !ok {
+			result = append(result, e)
+			continue
+		}
+
+		for _, item := range list.Items {
+			items, err := ParseYAML(bytes.NewReader(item.Raw))
+			if err != nil {
+				return nil, errors.Wrap(err, "parsing kubectl get output")
+			}
+			result = append(result, items...)
+		}
+	}
+	return result, nil
+}
+
+// Returns the objects that a manifest deployed, but that no current manifest
+// deploys anymore (e.g., because the user renamed or removed the resource).
+//
+// labelKeys are the labels that tie objects to their manifests, and manifests
+// are the names of the current manifests. Skips objects that some other object
+// owns (e.g., the ReplicaSets of a Deployment), because deleting the owner
+// deletes them too, and objects that aren't tied to any manifest.
+func Orphans(entities []K8sEntity, labelKeys []string, manifests map[string]bool) []K8sEntity {
+	var result []K8sEntity
+	for _, e := range entities {
+		m, err := meta.Accessor(e.Obj)
+		if err != nil || len(m.GetOwnerReferences()) > 0 {
+			continue
+		}
+
+		name := ""
+		for _, key := range labelKeys {
+			if v, ok := m.GetLabels()[key]; ok {
+				name = v
+				break
+			}
+		}
+		if name == "" || manifests[name] {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// A human-readable name for the object, e.g., "Deployment default/sancho".
+func DisplayName(e K8sEntity) string {
+	if e.Kind == nil {
+		return e.Name()
+	}
+	if clusterScopedKinds[e.Kind.Kind] {
+		return fmt.Sprintf("%s %s", e.Kind.Kind, e.Name())
+	}
+	return fmt.Sprintf("%s %s/%s", e.Kind.Kind, e.Namespace(), e.Name())
+}
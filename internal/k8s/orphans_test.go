@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const kubectlGetListOutput = `apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: old-name
+    namespace: default
+    labels:
+      tilt-manifest: old-name
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: sancho
+    namespace: default
+    labels:
+      tilt-manifest: sancho
+`
+
+func TestParseObjectList(t *testing.T) {
+	entities, err := parseObjectList(kubectlGetListOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 2, len(entities)) {
+		assert.Equal(t, "Deployment default/old-name", DisplayName(entities[0]))
+		assert.Equal(t, "Service default/sancho", DisplayName(entities[1]))
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	entities := []K8sEntity{
+		orphanTestDeployment("sancho", map[string]string{ManifestNameLabel: "sancho"}, nil),
+		orphanTestDeployment("old-name", map[string]string{ManifestNameLabel: "old-name"}, nil),
+		orphanTestDeployment("custom", map[string]string{"app": "old-custom"}, nil),
+		orphanTestDeployment("unlabeled", nil, nil),
+		orphanTestDeployment("owned", map[string]string{ManifestNameLabel: "old-name"},
+			[]metav1.OwnerReference{{Kind: "Deployment", Name: "old-name"}}),
+	}
+
+	orphans := Orphans(entities, []string{ManifestNameLabel, "app"}, map[string]bool{"sancho": true})
+	var names []string
+	for _, e := range orphans {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"old-name", "custom"}, names)
+}
+
+func orphanTestDeployment(name string, labels map[string]string, owners []metav1.OwnerReference) K8sEntity {
+	return K8sEntity{
+		Obj: &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				Labels:          labels,
+				OwnerReferences: owners,
+			},
+		},
+		Kind: &schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+}
@@ -0,0 +1,44 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// kubectl's message when RBAC denies a request, e.g.,
+// `deployments.apps "sancho" is forbidden: User "system:serviceaccount:ci:deployer" cannot patch resource "deployments" in API group "apps" in the namespace "default"`
+var permissionDeniedRe = regexp.MustCompile(`is forbidden: User "([^"]+)" cannot ([a-z]+) resource "([^"]+)"(?: in API group "[^"]*")?(?: in the namespace "([^"]+)")?`)
+
+// If kubectl failed because the user isn't allowed to do something,
+// returns an error that says who can't do what, without the rest of kubectl's output.
+func permissionDeniedError(stderr string) error {
+	var denials []string
+	seen := map[string]bool{}
+	for _, match := range permissionDeniedRe.FindAllStringSubmatch(stderr, -1) {
+		user, verb, resource, namespace := match[1], match[2], match[3], match[4]
+		denial := fmt.Sprintf("%s %s", verb, resource)
+		if namespace != "" {
+			denial = fmt.Sprintf("%s in namespace %q", denial, namespace)
+		}
+		denial = fmt.Sprintf("user %q can't %s", user, denial)
+		if seen[denial] {
+			continue
+		}
+		seen[denial] = true
+		denials = append(denials, denial)
+	}
+
+	if len(denials) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Permission denied: %s.\n"+
+		"Ask a cluster admin to grant access, or deploy with other credentials "+
+		"(see k8s_credentials, and the kubeconfig, kube_user, and impersonate_user arguments of k8s_resource)",
+		strings.Join(denials, "; "))
+}
+
+// Whether kubectl got an answer from the cluster that said we're not allowed to do something.
+func isForbiddenStderr(stderr string) bool {
+	return strings.Contains(stderr, "Error from server (Forbidden)")
+}
@@ -6,8 +6,10 @@ import (
 	"io"
 	"time"
 
+	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/windmilleng/tilt/internal/container"
@@ -45,6 +47,17 @@ func (k K8sClient) PodByID(ctx context.Context, pID PodID, n Namespace) (*v1.Pod
 	return pod, err
 }
 
+func (k K8sClient) ListPods(ctx context.Context, n Namespace, ls labels.Selector) ([]v1.Pod, error) {
+	list, err := k.core.Pods(n.String()).List(metav1.ListOptions{LabelSelector: ls.String()})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing pods in %s matching %s", n, ls)
+	}
+	for i := range list.Items {
+		FixContainerStatusImages(&list.Items[i])
+	}
+	return list.Items, nil
+}
+
 func PodIDFromPod(pod *v1.Pod) PodID {
 	return PodID(pod.ObjectMeta.Name)
 }
@@ -56,3 +69,13 @@ func NamespaceFromPod(pod *v1.Pod) Namespace {
 func NodeIDFromPod(pod *v1.Pod) NodeID {
 	return NodeID(pod.Spec.NodeName)
 }
+
+// Whether the pod's containers have all passed their readiness probes.
+func IsPodReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
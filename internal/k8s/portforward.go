@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
 
 	"k8s.io/client-go/kubernetes/typed/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp" // registers gcp auth provider
@@ -18,8 +19,20 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (k K8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (localPort int, closer func(), err error) {
-	localPort = optionalLocalPort
+// An open port forward to a pod.
+type PortForward struct {
+	LocalPort int
+
+	// Stops forwarding.
+	Close func()
+
+	// Receives once when forwarding stops: nil if we closed it,
+	// or the reason it died (e.g., the pod went away).
+	Done <-chan error
+}
+
+func (k K8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int) (PortForward, error) {
+	localPort := optionalLocalPort
 	if localPort == 0 {
 		// preferably, we'd set the localport to 0, and let the underlying function pick a port for us,
 		// to avoid the race condition potential of something else grabbing this port between
@@ -27,24 +40,25 @@ func (k K8sClient) ForwardPort(ctx context.Context, namespace Namespace, podID P
 		// the k8s client supports a local port of 0, and stores the actual local port assigned in a field,
 		// but unfortunately does not export that field, so there is no way for the caller to know which
 		// local port to talk to.
+		var err error
 		localPort, err = getAvailablePort()
 		if err != nil {
-			return 0, nil, errors.Wrap(err, "failed to find an available local port")
+			return PortForward{}, errors.Wrap(err, "failed to find an available local port")
 		}
 	}
 
-	closer, err = k.portForwarder(ctx, k.restConfig, k.core, namespace.String(), podID, localPort, remotePort)
+	closer, done, err := k.portForwarder(ctx, k.restConfig, k.core, namespace.String(), podID, localPort, remotePort)
 	if err != nil {
-		return 0, nil, err
+		return PortForward{}, err
 	}
 
-	return localPort, closer, nil
+	return PortForward{LocalPort: localPort, Close: closer, Done: done}, nil
 }
 
-func portForwarder(ctx context.Context, restConfig *rest.Config, core v1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), err error) {
+func portForwarder(ctx context.Context, restConfig *rest.Config, core v1.CoreV1Interface, namespace string, podID PodID, localPort int, remotePort int) (closer func(), done <-chan error, err error) {
 	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, "error getting roundtripper")
+		return nil, nil, errors.Wrap(err, "error getting roundtripper")
 	}
 
 	req := core.RESTClient().Post().
@@ -55,7 +69,7 @@ func portForwarder(ctx context.Context, restConfig *rest.Config, core v1.CoreV1I
 
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating dialer")
+		return nil, nil, errors.Wrap(err, "error creating dialer")
 	}
 
 	stopChan := make(chan struct{}, 1)
@@ -71,29 +85,25 @@ func portForwarder(ctx context.Context, restConfig *rest.Config, core v1.CoreV1I
 		logger.Get(ctx).Writer(logger.DebugLvl))
 
 	if err != nil {
-		return nil, errors.Wrap(err, "error forwarding port")
+		return nil, nil, errors.Wrap(err, "error forwarding port")
 	}
 
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
 	go func() {
-		errChan <- pf.ForwardPorts()
-		err := <-errChan
+		err := pf.ForwardPorts()
 		pf.Close()
-		// logging isn't really sufficient, since we're in a goroutine and who knows where the caller
-		// has moved on to by this point, but other options are much more expensive (e.g., monitoring the state
-		// of the port forward from the caller and/or automatically reconnecting port forwards)
-		logger.Get(ctx).Infof("error from port forward: %v", err)
+		errChan <- err
 	}()
 
 	select {
 	case err = <-errChan:
-		pf.Close()
-		return nil, errors.Wrap(err, "error forwarding port")
+		return nil, nil, errors.Wrap(err, "error forwarding port")
 	case <-pf.Ready:
+		var once sync.Once
 		closer = func() {
-			close(stopChan)
+			once.Do(func() { close(stopChan) })
 		}
-		return closer, nil
+		return closer, errChan, nil
 	}
 }
 
@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Create a Secret that lets Kubernetes pull images from private registries,
+// from credentials serialized in the .dockerconfigjson format.
+func NewImagePullSecret(name string, namespace Namespace, dockerConfigJSON []byte) K8sEntity {
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace.String(),
+		},
+		Type: v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: dockerConfigJSON,
+		},
+	}
+	kind := secret.GroupVersionKind()
+	return K8sEntity{
+		Obj:  secret,
+		Kind: &kind,
+	}
+}
+
+// Iterate through the pod specs of a k8s entity and add a reference
+// to the named image pull secret, if it's not already there.
+//
+// Returns: the new entity, whether the entity has any pod specs, and an error.
+func InjectImagePullSecret(entity K8sEntity, name string) (K8sEntity, bool, error) {
+	entity = entity.DeepCopy()
+	pods, err := ExtractPods(&entity)
+	if err != nil {
+		return K8sEntity{}, false, err
+	}
+
+	for _, pod := range pods {
+		if hasImagePullSecret(pod, name) {
+			continue
+		}
+		pod.ImagePullSecrets = append(pod.ImagePullSecrets, v1.LocalObjectReference{Name: name})
+	}
+	return entity, len(pods) > 0, nil
+}
+
+func hasImagePullSecret(pod *v1.PodSpec, name string) bool {
+	for _, ref := range pod.ImagePullSecrets {
+		if ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
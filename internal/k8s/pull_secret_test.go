@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestInjectImagePullSecret(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newEntity, hasPods, err := InjectImagePullSecret(entities[0], "tilt-registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, hasPods)
+
+	// Injecting twice shouldn't add a duplicate reference.
+	newEntity, _, err = InjectImagePullSecret(newEntity, "tilt-registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pods, err := ExtractPods(&newEntity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []v1.LocalObjectReference{{Name: "tilt-registry"}}, pods[0].ImagePullSecrets)
+
+	// The original should be untouched.
+	pods, err = ExtractPods(&entities[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, pods[0].ImagePullSecrets)
+}
+
+func TestInjectImagePullSecretNoPods(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.DoggosServiceYaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, hasPods, err := InjectImagePullSecret(entities[0], "tilt-registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, hasPods)
+}
+
+func TestNewImagePullSecret(t *testing.T) {
+	entity := NewImagePullSecret("tilt-registry", Namespace("sancho-ns"), []byte(`{"auths":{}}`))
+	assert.Equal(t, "tilt-registry", entity.Name())
+	assert.Equal(t, Namespace("sancho-ns"), entity.Namespace())
+
+	result, err := SerializeYAML([]K8sEntity{entity})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"kind: Secret",
+		"type: kubernetes.io/dockerconfigjson",
+		".dockerconfigjson: eyJhdXRocyI6e319",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in serialized yaml:\n%s", expected, result)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	authv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// Something that Tilt needs to be allowed to do in the cluster.
+type Permission struct {
+	// Empty for cluster-scoped resources.
+	Namespace   Namespace
+	Group       string
+	Resource    string
+	Subresource string
+	Verb        string
+}
+
+func (p Permission) resourceName() string {
+	r := p.Resource
+	if p.Group != "" {
+		r = fmt.Sprintf("%s.%s", r, p.Group)
+	}
+	if p.Subresource != "" {
+		r = fmt.Sprintf("%s/%s", r, p.Subresource)
+	}
+	return r
+}
+
+func (p Permission) String() string {
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, p.resourceName())
+	}
+	return fmt.Sprintf("%s %s in namespace %q", p.Verb, p.resourceName(), p.Namespace)
+}
+
+// Whether the cluster said we have a permission.
+type PermissionCheck struct {
+	Permission Permission
+	Allowed    bool
+
+	// Why the permission was denied, if the cluster said.
+	Reason string
+}
+
+// Verbs that kubectl apply, kubectl delete, and kubectl replace --force use.
+var deployVerbs = []string{"get", "create", "patch", "delete"}
+
+// Verbs that informers use.
+var watchVerbs = []string{"list", "watch"}
+
+// Derives the permissions that Tilt uses to deploy and watch the given manifests:
+// applying and deleting their objects, watching their pods and the objects that tell
+// us about them, streaming logs, and port-forwarding and exec-ing into pods.
+//
+// We can't tell which objects a Helm chart creates without rendering it,
+// so for Helm releases, we only include the permissions that Helm uses to track the release.
+//
+// Returns the permissions sorted and without duplicates.
+func RequiredPermissions(manifests []model.Manifest, defaultNS Namespace) ([]Permission, error) {
+	seen := map[Permission]bool{}
+	var result []Permission
+	add := func(ns Namespace, group, resource, subresource string, verbs ...string) {
+		for _, verb := range verbs {
+			p := Permission{Namespace: ns, Group: group, Resource: resource, Subresource: subresource, Verb: verb}
+			if !seen[p] {
+				seen[p] = true
+				result = append(result, p)
+			}
+		}
+	}
+
+	for _, m := range manifests {
+		if !m.IsK8s() {
+			continue
+		}
+		t := m.K8sTarget()
+
+		ns := defaultNS
+		if t.Namespace != "" {
+			ns = Namespace(t.Namespace)
+			add("", "", "namespaces", "", "get", "create")
+		}
+
+		if t.HelmRelease != nil {
+			releaseNS := ns
+			if t.HelmRelease.Namespace != "" {
+				releaseNS = Namespace(t.HelmRelease.Namespace)
+			}
+			add(releaseNS, "", "secrets", "", "get", "list", "create", "update", "delete")
+		}
+
+		// The namespaces that the target's objects (and so its pods) live in.
+		namespaces := []Namespace{ns}
+		entities, err := ParseYAMLFromString(t.YAML)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing YAML of %s", m.Name)
+		}
+		for _, e := range entities {
+			if e.Kind == nil {
+				continue
+			}
+			gvr, _ := meta.UnsafeGuessKindToResource(*e.Kind)
+			entityNS := Namespace("")
+			if !clusterScopedKinds[e.Kind.Kind] {
+				entityNS = ns
+				if n := e.meta().GetNamespace(); n != "" {
+					entityNS = Namespace(n)
+					namespaces = appendNamespaceIfMissing(namespaces, entityNS)
+				}
+			}
+			add(entityNS, gvr.Group, gvr.Resource, "", deployVerbs...)
+
+			if e.Kind.Kind == "CustomResourceDefinition" {
+				// kubectl wait, until the definitions are established
+				add("", gvr.Group, gvr.Resource, "", watchVerbs...)
+			}
+		}
+
+		if t.ImagePullSecret != "" {
+			for _, n := range namespaces {
+				add(n, "", "secrets", "", "get", "create", "patch")
+			}
+		}
+
+		liveUpdate := false
+		for _, iTarget := range m.ImageTargets {
+			if !iTarget.AnyLiveUpdateInfo().Empty() {
+				liveUpdate = true
+			}
+		}
+
+		for _, n := range namespaces {
+			add(n, "", "pods", "", "get", "list", "watch")
+			add(n, "", "pods", "log", "get")
+			add(n, "", "services", "", watchVerbs...)
+			add(n, "", "events", "", watchVerbs...)
+			add(n, "apps", "replicasets", "", watchVerbs...)
+			add(n, "extensions", "ingresses", "", watchVerbs...)
+
+			if len(t.PortForwards) > 0 {
+				add(n, "", "pods", "portforward", "create")
+			}
+			if len(t.PortForwards) > len(t.PodPortForwards()) {
+				add(n, "", "services", "", "get")
+				add(n, "", "endpoints", "", "get")
+			}
+			if liveUpdate {
+				add(n, "", "pods", "exec", "create")
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.resourceName() != b.resourceName() {
+			return a.resourceName() < b.resourceName()
+		}
+		return a.Verb < b.Verb
+	})
+	return result, nil
+}
+
+func appendNamespaceIfMissing(namespaces []Namespace, n Namespace) []Namespace {
+	for _, existing := range namespaces {
+		if existing == n {
+			return namespaces
+		}
+	}
+	return append(namespaces, n)
+}
+
+func (k K8sClient) CheckPermissions(ctx context.Context, perms []Permission) ([]PermissionCheck, error) {
+	result := make([]PermissionCheck, 0, len(perms))
+	for _, p := range perms {
+		review, err := k.clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(&authv1.SelfSubjectAccessReview{
+			Spec: authv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authv1.ResourceAttributes{
+					Namespace:   p.Namespace.String(),
+					Verb:        p.Verb,
+					Group:       p.Group,
+					Resource:    p.Resource,
+					Subresource: p.Subresource,
+				},
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking whether we can %s", p)
+		}
+		result = append(result, PermissionCheck{
+			Permission: p,
+			Allowed:    review.Status.Allowed,
+			Reason:     review.Status.Reason,
+		})
+	}
+	return result, nil
+}
+
+// Returns YAML for a ClusterRole with the cluster-scoped permissions,
+// and a Role in each namespace with the permissions in that namespace,
+// all with the given name.
+func RolesYAML(name string, perms []Permission) (string, error) {
+	var namespaces []Namespace
+	rulesByNS := map[Namespace]map[string]*rbacv1.PolicyRule{}
+	ruleKeysByNS := map[Namespace][]string{}
+	for _, p := range perms {
+		rules, ok := rulesByNS[p.Namespace]
+		if !ok {
+			rules = map[string]*rbacv1.PolicyRule{}
+			rulesByNS[p.Namespace] = rules
+			namespaces = append(namespaces, p.Namespace)
+		}
+
+		resource := p.Resource
+		if p.Subresource != "" {
+			resource = fmt.Sprintf("%s/%s", p.Resource, p.Subresource)
+		}
+		key := fmt.Sprintf("%s/%s", p.Group, resource)
+		rule, ok := rules[key]
+		if !ok {
+			rule = &rbacv1.PolicyRule{APIGroups: []string{p.Group}, Resources: []string{resource}}
+			rules[key] = rule
+			ruleKeysByNS[p.Namespace] = append(ruleKeysByNS[p.Namespace], key)
+		}
+		rule.Verbs = append(rule.Verbs, p.Verb)
+	}
+
+	var entities []K8sEntity
+	for _, ns := range namespaces {
+		var rules []rbacv1.PolicyRule
+		for _, key := range ruleKeysByNS[ns] {
+			rules = append(rules, *rulesByNS[ns][key])
+		}
+
+		if ns == "" {
+			entities = append(entities, K8sEntity{Obj: &rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Rules:      rules,
+			}})
+			continue
+		}
+
+		entities = append(entities, K8sEntity{Obj: &rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns.String()},
+			Rules:      rules,
+		}})
+	}
+
+	result, err := SerializeYAML(entities)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result), nil
+}
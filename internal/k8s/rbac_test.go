@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestRequiredPermissions(t *testing.T) {
+	entities, err := ParseYAMLFromString(yamlJoin(testyaml.MyNamespaceYAML, testyaml.SanchoYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewK8sOnlyManifest("sancho", entities)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kTarget := m.K8sTarget()
+	kTarget.PortForwards = []model.PortForward{{LocalPort: 8080}}
+	m = m.WithDeployTarget(kTarget)
+
+	liveUpdate := model.LiveUpdate{Steps: []model.LiveUpdateStep{model.LiveUpdateRunStep{Command: model.ToShellCmd("make")}}}
+	iTarget := model.NewImageTarget(container.MustParseSelector("gcr.io/some-project-162817/sancho")).
+		WithBuildDetails(model.DockerBuild{LiveUpdate: liveUpdate})
+	m = m.WithImageTarget(iTarget)
+
+	perms, err := RequiredPermissions([]model.Manifest{m}, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var strs []string
+	for _, p := range perms {
+		strs = append(strs, p.String())
+	}
+	assert.Equal(t, []string{
+		"create namespaces",
+		"delete namespaces",
+		"get namespaces",
+		"patch namespaces",
+		`list events in namespace "default"`,
+		`watch events in namespace "default"`,
+		`list ingresses.extensions in namespace "default"`,
+		`watch ingresses.extensions in namespace "default"`,
+		`get pods in namespace "default"`,
+		`list pods in namespace "default"`,
+		`watch pods in namespace "default"`,
+		`create pods/exec in namespace "default"`,
+		`get pods/log in namespace "default"`,
+		`create pods/portforward in namespace "default"`,
+		`list replicasets.apps in namespace "default"`,
+		`watch replicasets.apps in namespace "default"`,
+		`list services in namespace "default"`,
+		`watch services in namespace "default"`,
+		`create deployments.apps in namespace "sancho-ns"`,
+		`delete deployments.apps in namespace "sancho-ns"`,
+		`get deployments.apps in namespace "sancho-ns"`,
+		`patch deployments.apps in namespace "sancho-ns"`,
+		`list events in namespace "sancho-ns"`,
+		`watch events in namespace "sancho-ns"`,
+		`list ingresses.extensions in namespace "sancho-ns"`,
+		`watch ingresses.extensions in namespace "sancho-ns"`,
+		`get pods in namespace "sancho-ns"`,
+		`list pods in namespace "sancho-ns"`,
+		`watch pods in namespace "sancho-ns"`,
+		`create pods/exec in namespace "sancho-ns"`,
+		`get pods/log in namespace "sancho-ns"`,
+		`create pods/portforward in namespace "sancho-ns"`,
+		`list replicasets.apps in namespace "sancho-ns"`,
+		`watch replicasets.apps in namespace "sancho-ns"`,
+		`list services in namespace "sancho-ns"`,
+		`watch services in namespace "sancho-ns"`,
+	}, strs)
+}
+
+func TestRolesYAML(t *testing.T) {
+	perms := []Permission{
+		{Resource: "namespaces", Verb: "get"},
+		{Namespace: "default", Group: "apps", Resource: "deployments", Verb: "create"},
+		{Namespace: "default", Group: "apps", Resource: "deployments", Verb: "patch"},
+		{Namespace: "default", Resource: "pods", Subresource: "log", Verb: "get"},
+	}
+
+	result, err := RolesYAML("tilt-deployer", perms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entities, err := ParseYAMLFromString(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 2, len(entities)) {
+		assert.Equal(t, "ClusterRole", entities[0].Kind.Kind)
+		assert.Equal(t, "Role", entities[1].Kind.Kind)
+		assert.Equal(t, Namespace("default"), entities[1].Namespace())
+	}
+
+	assert.True(t, strings.Contains(result, `- apps
+  resources:
+  - deployments
+  verbs:
+  - create
+  - patch`), result)
+	assert.Contains(t, result, "- pods/log")
+}
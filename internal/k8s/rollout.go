@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The number of pods that the Deployments in this list want to run.
+//
+// Used to tell when a rollout has finished. Deployments without
+// a replica count run one pod, like they do in Kubernetes.
+func DeploymentReplicas(entities []K8sEntity) int {
+	result := 0
+	for _, e := range entities {
+		if !e.HasKind("Deployment") {
+			continue
+		}
+
+		content, ok := unstructuredContent(e)
+		if !ok {
+			converted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.Obj)
+			if err != nil {
+				continue
+			}
+			content = converted
+		}
+
+		replicas, found, err := unstructured.NestedInt64(content, "spec", "replicas")
+		if err != nil {
+			continue
+		}
+		if !found {
+			replicas = 1
+		}
+		result += int(replicas)
+	}
+	return result
+}
+
+// The Deployment controller numbers each ReplicaSet it creates with this annotation.
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// Which revision of its Deployment this ReplicaSet runs, or 0 if
+// the ReplicaSet doesn't belong to a Deployment.
+func ReplicaSetRevision(rs *appsv1.ReplicaSet) int {
+	revision, err := strconv.Atoi(rs.Annotations[deploymentRevisionAnnotation])
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// The name of the Deployment that owns this ReplicaSet, or the empty string.
+func ReplicaSetOwner(rs *appsv1.ReplicaSet) string {
+	owner := metav1.GetControllerOf(rs)
+	if owner == nil || owner.Kind != "Deployment" {
+		return ""
+	}
+	return owner.Name
+}
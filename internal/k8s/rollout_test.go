@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestDeploymentReplicas(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, DeploymentReplicas(entities))
+
+	threeReplicas := strings.Replace(testyaml.SanchoYAML, "replicas: 1", "replicas: 3", 1)
+	entities, err = ParseYAMLFromString(strings.Join([]string{threeReplicas, testyaml.SecretYaml}, "\n---\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, DeploymentReplicas(entities))
+
+	// Kubernetes defaults to one replica.
+	noReplicas := strings.Replace(testyaml.SanchoYAML, "  replicas: 1\n", "", 1)
+	entities, err = ParseYAMLFromString(noReplicas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, DeploymentReplicas(entities))
+}
+
+func TestReplicaSetRevisionAndOwner(t *testing.T) {
+	isController := true
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sancho-5d8f7b",
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "3"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "sancho", Controller: &isController},
+			},
+		},
+	}
+	assert.Equal(t, 3, ReplicaSetRevision(rs))
+	assert.Equal(t, "sancho", ReplicaSetOwner(rs))
+
+	bare := &appsv1.ReplicaSet{}
+	assert.Equal(t, 0, ReplicaSetRevision(bare))
+	assert.Equal(t, "", ReplicaSetOwner(bare))
+}
@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"encoding/base64"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const redactedSecretValue = "<redacted>"
+
+// Values shorter than this show up all over logs by coincidence ("true",
+// "8080"), so scrubbing them would mangle the logs without hiding much.
+const minScrubbableSecretLength = 5
+
+// The annotation that kubectl apply keeps the last applied YAML in, which
+// would give away a Secret's data all over again.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Replaces the values in Secrets with a placeholder, so that we can show
+// or write out YAML without giving away credentials. Keeps the keys, since
+// they're often what you need to know when debugging.
+func RedactSecrets(entities []K8sEntity) []K8sEntity {
+	result := make([]K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		secret, ok := e.Obj.(*v1.Secret)
+		if !ok {
+			result = append(result, e)
+			continue
+		}
+
+		secret = secret.DeepCopy()
+		for k := range secret.Data {
+			secret.Data[k] = []byte(redactedSecretValue)
+		}
+		for k := range secret.StringData {
+			secret.StringData[k] = redactedSecretValue
+		}
+		delete(secret.Annotations, lastAppliedConfigAnnotation)
+		result = append(result, K8sEntity{Obj: secret, Kind: e.Kind})
+	}
+	return result
+}
+
+// Like RedactSecrets, but for YAML. If we can't parse the YAML, we can't tell
+// what's in it, so we return nothing rather than risk leaking a Secret.
+func RedactSecretsInYAML(yaml string) string {
+	entities, err := ParseYAMLFromString(yaml)
+	if err != nil {
+		return ""
+	}
+	result, err := SerializeYAML(RedactSecrets(entities))
+	if err != nil {
+		return ""
+	}
+	return result
+}
+
+// The values in the given Secrets, both as-is and base64-encoded (since
+// that's how they appear in YAML), to scrub from logs.
+func SecretValues(entities []K8sEntity) []string {
+	var result []string
+	add := func(value string) {
+		if len(value) < minScrubbableSecretLength {
+			return
+		}
+		result = append(result, value, base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+
+	for _, e := range entities {
+		secret, ok := e.Obj.(*v1.Secret)
+		if !ok {
+			continue
+		}
+		for _, v := range secret.Data {
+			add(string(v))
+		}
+		for _, v := range secret.StringData {
+			add(v)
+		}
+	}
+	return result
+}
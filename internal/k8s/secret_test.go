@@ -0,0 +1,40 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestRedactSecretsInYAML(t *testing.T) {
+	yaml := strings.Join([]string{testyaml.SecretYaml, testyaml.SanchoYAML}, "\n---\n")
+	result := RedactSecretsInYAML(yaml)
+
+	assert.NotContains(t, result, "YWRtaW4=")
+	assert.NotContains(t, result, "MWYyZDFlMmU2N2Rm")
+	assert.Contains(t, result, "username:")
+	assert.Contains(t, result, "image: gcr.io/some-project-162817/sancho")
+
+	entities, err := ParseYAMLFromString(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(entities))
+}
+
+func TestRedactSecretsInUnparseableYAML(t *testing.T) {
+	assert.Equal(t, "", RedactSecretsInYAML("kind: Secret\ndata: [password"))
+}
+
+func TestSecretValues(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SecretYaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "admin" is just long enough to scrub.
+	assert.ElementsMatch(t, []string{"admin", "YWRtaW4=", "1f2d1e2e67df", "MWYyZDFlMmU2N2Rm"}, SecretValues(entities))
+}
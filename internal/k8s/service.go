@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A ready pod behind a Service, and the port on that pod
+// that a port on the Service maps to.
+type ServiceEndpoint struct {
+	PodID     PodID
+	Namespace Namespace
+	Port      int
+}
+
+// Returns the ready endpoints behind the given port of a Service.
+// If port is 0, uses the first port of the Service.
+func (k K8sClient) ServiceEndpoints(ctx context.Context, n Namespace, name string, port int) ([]ServiceEndpoint, error) {
+	if n == "" {
+		n = k.configNamespace
+	}
+
+	svc, err := k.core.Services(n.String()).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	eps, err := k.core.Endpoints(n.String()).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return readyServiceEndpoints(svc, eps, port)
+}
+
+func readyServiceEndpoints(svc *v1.Service, eps *v1.Endpoints, port int) ([]ServiceEndpoint, error) {
+	if len(svc.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("service %s has no ports", svc.Name)
+	}
+
+	var svcPort *v1.ServicePort
+	if port == 0 {
+		svcPort = &svc.Spec.Ports[0]
+	} else {
+		for i, p := range svc.Spec.Ports {
+			if int(p.Port) == port {
+				svcPort = &svc.Spec.Ports[i]
+				break
+			}
+		}
+	}
+	if svcPort == nil {
+		return nil, fmt.Errorf("service %s has no port %d", svc.Name, port)
+	}
+
+	var result []ServiceEndpoint
+	for _, subset := range eps.Subsets {
+		// The Endpoints controller names each endpoint port after the service port it serves.
+		targetPort := 0
+		for _, p := range subset.Ports {
+			if p.Name == svcPort.Name {
+				targetPort = int(p.Port)
+				break
+			}
+		}
+		if targetPort == 0 {
+			continue
+		}
+
+		// NotReadyAddresses are deliberately skipped.
+		for _, addr := range subset.Addresses {
+			if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+				continue
+			}
+			result = append(result, ServiceEndpoint{
+				PodID:     PodID(addr.TargetRef.Name),
+				Namespace: Namespace(addr.TargetRef.Namespace),
+				Port:      targetPort,
+			})
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("service %s has no ready pods", svc.Name)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PodID < result[j].PodID
+	})
+	return result, nil
+}
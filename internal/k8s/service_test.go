@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReadyServiceEndpoints(t *testing.T) {
+	svc := fakeService(v1.ServicePort{Name: "http", Port: 80}, v1.ServicePort{Name: "grpc", Port: 9000})
+	eps := &v1.Endpoints{
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{
+					podAddress("web-2"),
+					podAddress("web-1"),
+				},
+				NotReadyAddresses: []v1.EndpointAddress{
+					podAddress("web-3"),
+				},
+				Ports: []v1.EndpointPort{
+					{Name: "grpc", Port: 9090},
+					{Name: "http", Port: 8080},
+				},
+			},
+		},
+	}
+
+	result, err := readyServiceEndpoints(svc, eps, 9000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []ServiceEndpoint{{PodID: "web-1", Port: 9090}, {PodID: "web-2", Port: 9090}}, result)
+
+	// With no port, we use the first port of the service.
+	result, err = readyServiceEndpoints(svc, eps, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []ServiceEndpoint{{PodID: "web-1", Port: 8080}, {PodID: "web-2", Port: 8080}}, result)
+}
+
+func TestReadyServiceEndpointsMissingPort(t *testing.T) {
+	svc := fakeService(v1.ServicePort{Port: 80})
+	_, err := readyServiceEndpoints(svc, &v1.Endpoints{}, 81)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "service web has no port 81")
+	}
+}
+
+func TestReadyServiceEndpointsNoneReady(t *testing.T) {
+	svc := fakeService(v1.ServicePort{Port: 80})
+	eps := &v1.Endpoints{
+		Subsets: []v1.EndpointSubset{
+			{
+				NotReadyAddresses: []v1.EndpointAddress{podAddress("web-1")},
+				Ports:             []v1.EndpointPort{{Port: 8080}},
+			},
+		},
+	}
+	_, err := readyServiceEndpoints(svc, eps, 80)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "service web has no ready pods")
+	}
+}
+
+func fakeService(ports ...v1.ServicePort) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec:       v1.ServiceSpec{Ports: ports},
+	}
+}
+
+func podAddress(name string) v1.EndpointAddress {
+	return v1.EndpointAddress{TargetRef: &v1.ObjectReference{Kind: "Pod", Name: name}}
+}
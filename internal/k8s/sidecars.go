@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Containers that service meshes inject into pods, next to the app's own containers.
+var knownSidecarContainers = map[string]bool{
+	"istio-proxy":                  true,
+	"istio-init":                   true,
+	"istio-validation":             true,
+	"linkerd-proxy":                true,
+	"linkerd-init":                 true,
+	"consul-connect-envoy-sidecar": true,
+	"consul-connect-inject-init":   true,
+}
+
+// Istio records the containers it injected in this annotation, as JSON.
+const istioSidecarStatusAnnotation = "sidecar.istio.io/status"
+
+type istioSidecarStatus struct {
+	InitContainers []string `json:"initContainers"`
+	Containers     []string `json:"containers"`
+}
+
+// Returns the names of the pod's containers (and init containers) that
+// aren't part of the app, so that we can leave them out of the pod's readiness
+// and status.
+//
+// The result includes the given names, and if detect is set, the containers
+// that a service mesh injected into the pod.
+func SidecarContainers(pod *v1.Pod, names []string, detect bool) map[string]bool {
+	result := map[string]bool{}
+	for _, name := range names {
+		result[name] = true
+	}
+	if !detect {
+		return result
+	}
+
+	if status, ok := pod.Annotations[istioSidecarStatusAnnotation]; ok {
+		var s istioSidecarStatus
+		if err := json.Unmarshal([]byte(status), &s); err == nil {
+			for _, name := range append(s.InitContainers, s.Containers...) {
+				result[name] = true
+			}
+		}
+	}
+
+	containers := append([]v1.Container{}, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, c := range containers {
+		if knownSidecarContainers[c.Name] {
+			result[c.Name] = true
+		}
+	}
+	return result
+}
+
+// Like IsPodReady, but only looks at the containers that aren't sidecars,
+// so that a mesh proxy that's slow to start (or restarting) doesn't
+// make the app look unready.
+func IsPodReadyIgnoringSidecars(pod *v1.Pod, sidecars map[string]bool) bool {
+	if len(sidecars) == 0 {
+		return IsPodReady(pod)
+	}
+	if pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+
+	found := false
+	for _, cStatus := range pod.Status.ContainerStatuses {
+		if sidecars[cStatus.Name] {
+			continue
+		}
+		if !cStatus.Ready {
+			return false
+		}
+		found = true
+	}
+	if !found {
+		return IsPodReady(pod)
+	}
+	return true
+}
+
+// Returns the container statuses of the pod's app containers, without the sidecars.
+func ContainerStatusesWithoutSidecars(statuses []v1.ContainerStatus, sidecars map[string]bool) []v1.ContainerStatus {
+	if len(sidecars) == 0 {
+		return statuses
+	}
+	result := make([]v1.ContainerStatus, 0, len(statuses))
+	for _, cStatus := range statuses {
+		if !sidecars[cStatus.Name] {
+			result = append(result, cStatus)
+		}
+	}
+	return result
+}
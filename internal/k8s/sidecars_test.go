@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSidecarContainersIstioAnnotation(t *testing.T) {
+	pod := sidecarTestPod()
+	pod.Annotations = map[string]string{
+		istioSidecarStatusAnnotation: `{"version":"abc","initContainers":["istio-init"],"containers":["my-proxy"]}`,
+	}
+	assert.Equal(t, map[string]bool{"istio-init": true, "my-proxy": true, "istio-proxy": true}, SidecarContainers(pod, nil, true))
+}
+
+func TestSidecarContainersKnownNames(t *testing.T) {
+	assert.Equal(t, map[string]bool{"istio-proxy": true}, SidecarContainers(sidecarTestPod(), nil, true))
+}
+
+func TestSidecarContainersNoDetection(t *testing.T) {
+	assert.Equal(t, map[string]bool{"vault-agent": true}, SidecarContainers(sidecarTestPod(), []string{"vault-agent"}, false))
+}
+
+func TestIsPodReadyIgnoringSidecars(t *testing.T) {
+	pod := sidecarTestPod()
+	assert.False(t, IsPodReady(pod))
+	assert.False(t, IsPodReadyIgnoringSidecars(pod, nil))
+	assert.True(t, IsPodReadyIgnoringSidecars(pod, map[string]bool{"istio-proxy": true}))
+
+	pod.Status.ContainerStatuses[0].Ready = false
+	assert.False(t, IsPodReadyIgnoringSidecars(pod, map[string]bool{"istio-proxy": true}))
+}
+
+func sidecarTestPod() *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "app"},
+				{Name: "istio-proxy"},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodReady, Status: v1.ConditionFalse},
+			},
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "app", Ready: true},
+				{Name: "istio-proxy", Ready: false},
+			},
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package k8s
 
 import (
+	"sort"
+
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/windmilleng/tilt/internal/model"
@@ -23,14 +25,77 @@ func NewTarget(
 	}
 
 	return model.K8sTarget{
-		Name:              name,
-		YAML:              yaml,
-		ResourceNames:     resourceNames,
-		PortForwards:      portForwards,
-		ExtraPodSelectors: extraPodSelectors,
+		Name:               name,
+		YAML:               yaml,
+		ResourceNames:      resourceNames,
+		PortForwards:       portForwards,
+		ExtraPodSelectors:  extraPodSelectors,
+		DeploymentReplicas: DeploymentReplicas(entities),
+		HasPods:            HasPods(entities),
 	}.WithDependencyIDs(dependencyIDs), nil
 }
 
+// Whether any of the entities run pods (e.g., Deployments, Jobs, or bare Pods).
+// A Job's pods count as ready once they've run to completion.
+//
+// CronJobs only run pods on a schedule, so they don't count.
+func HasPods(entities []K8sEntity) bool {
+	for _, e := range entities {
+		if e.HasKind("Pod") {
+			return true
+		}
+		if e.HasKind("CronJob") {
+			continue
+		}
+		templates, err := ExtractPodTemplateSpec(&e)
+		if err == nil && len(templates) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// The namespaces that the target deploys to. Objects that don't say what
+// namespace they're in go to defaultNs (i.e., the kube context's namespace).
+func TargetNamespaces(kTarget model.K8sTarget, defaultNs Namespace) []Namespace {
+	if defaultNs == "" {
+		defaultNs = DefaultNamespace
+	}
+
+	seen := make(map[Namespace]bool)
+	var result []Namespace
+	add := func(n string) {
+		ns := defaultNs
+		if n != "" {
+			ns = Namespace(n)
+		}
+		if !seen[ns] {
+			seen[ns] = true
+			result = append(result, ns)
+		}
+	}
+
+	if kTarget.Namespace != "" {
+		add(kTarget.Namespace)
+	}
+	if kTarget.HelmRelease != nil {
+		add(kTarget.HelmRelease.Namespace)
+	}
+
+	entities, err := ParseYAMLFromString(kTarget.YAML)
+	if err == nil {
+		for _, e := range entities {
+			add(e.meta().GetNamespace())
+		}
+	}
+	if len(result) == 0 {
+		add("")
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
 func NewK8sOnlyManifest(name model.ManifestName, entities []K8sEntity) (model.Manifest, error) {
 	kTarget, err := NewTarget(name.TargetName(), entities, nil, nil, nil)
 	if err != nil {
@@ -0,0 +1,46 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestHasPods(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		yaml     string
+		expected bool
+	}{
+		{"deployment", testyaml.SanchoYAML, true},
+		{"job", testyaml.JobYAML, true},
+		{"pod", testyaml.LonelyPodYAML, true},
+		{"cronjob", testyaml.CronJobYAML, false},
+		{"service", testyaml.DoggosServiceYaml, false},
+		{"secret", testyaml.SecretYaml, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entities, err := ParseYAMLFromString(tc.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expected, HasPods(entities))
+		})
+	}
+}
+
+func TestTargetNamespaces(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML + "\n---\n" + testyaml.SecretYaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kTarget, err := NewTarget("sancho", entities, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []Namespace{"my-ns", "sancho-ns"}, TargetNamespaces(kTarget, "my-ns"))
+	assert.Equal(t, []Namespace{"default", "sancho-ns"}, TargetNamespaces(kTarget, ""))
+}
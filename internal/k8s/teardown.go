@@ -0,0 +1,177 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Helm never deletes objects with this annotation, so neither do we.
+const helmResourcePolicyAnnotation = "helm.sh/resource-policy"
+
+// Kinds that workloads depend on while they shut down,
+// so we delete them after the workloads are gone.
+var workloadDependencyKinds = map[string]bool{
+	"ConfigMap":             true,
+	"PersistentVolumeClaim": true,
+	"Role":                  true,
+	"RoleBinding":           true,
+	"Secret":                true,
+	"ServiceAccount":        true,
+}
+
+// Groups entities into the order we should delete them in, so that nothing
+// is deleted while something else still depends on it:
+//
+// 1) Custom resources, while their operators and definitions still exist to clean them up.
+// 2) Workloads, and everything else that lives in a namespace.
+// 3) The config, storage, and permissions that the workloads use.
+// 4) Cluster-scoped objects, like CustomResourceDefinitions and ClusterRoles.
+// 5) Namespaces.
+//
+// Empty groups are omitted.
+func DeletionGroups(entities []K8sEntity) [][]K8sEntity {
+	const numTiers = 5
+	tiers := make([][]K8sEntity, numTiers)
+	for _, e := range entities {
+		tier := deletionTier(e)
+		tiers[tier] = append(tiers[tier], e)
+	}
+
+	var result [][]K8sEntity
+	for _, tier := range tiers {
+		if len(tier) > 0 {
+			result = append(result, tier)
+		}
+	}
+	return result
+}
+
+func deletionTier(e K8sEntity) int {
+	kind := ""
+	if e.Kind != nil {
+		kind = e.Kind.Kind
+	}
+
+	switch {
+	case kind == "Namespace":
+		return 4
+	case clusterScopedKinds[kind]:
+		return 3
+	case workloadDependencyKinds[kind]:
+		return 2
+	}
+
+	// Kinds that client-go doesn't know about are parsed as unstructured.
+	if _, ok := e.Obj.(*unstructured.Unstructured); ok {
+		return 0
+	}
+	return 1
+}
+
+// Whether Helm would keep this object around when the release is deleted.
+func (e K8sEntity) KeptByHelm() bool {
+	m, err := meta.Accessor(e.Obj)
+	if err != nil {
+		return false
+	}
+	return m.GetAnnotations()[helmResourcePolicyAnnotation] == "keep"
+}
+
+// Returns the entity for a namespace, for deleting it.
+func NewNamespaceEntity(n Namespace) K8sEntity {
+	ns := NewNamespace(n)
+	ns.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"}
+	kind := ns.GroupVersionKind()
+	return K8sEntity{
+		Obj:  ns,
+		Kind: &kind,
+	}
+}
+
+// Namespaces that we should never delete, even when asked to delete
+// all the namespaces that Tilt deployed to.
+func IsSystemNamespace(n Namespace) bool {
+	return n == DefaultNamespace || strings.HasPrefix(n.String(), "kube-")
+}
+
+// StatefulSets create a PersistentVolumeClaim for each of their volumeClaimTemplates
+// on each replica, and never delete them. Finds those claims, so that we can delete them too.
+//
+// The claims are named <template name>-<statefulset name>-<ordinal>.
+func StatefulSetVolumeClaims(entities []K8sEntity, claims []v1.PersistentVolumeClaim) []K8sEntity {
+	var patterns []*regexp.Regexp
+	var namespaces []Namespace
+	for _, e := range entities {
+		if e.Kind == nil || e.Kind.Kind != "StatefulSet" {
+			continue
+		}
+
+		for _, tmpl := range statefulSetClaimTemplateNames(e) {
+			re := regexp.MustCompile(fmt.Sprintf("^%s-%s-[0-9]+$", regexp.QuoteMeta(tmpl), regexp.QuoteMeta(e.Name())))
+			patterns = append(patterns, re)
+			namespaces = append(namespaces, e.Namespace())
+		}
+	}
+
+	var result []K8sEntity
+	for _, claim := range claims {
+		claimNS := namespaceFromPVC(claim)
+		for i, re := range patterns {
+			if namespaces[i] != claimNS || !re.MatchString(claim.Name) {
+				continue
+			}
+
+			c := claim.DeepCopy()
+			c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"}
+			kind := c.GroupVersionKind()
+			result = append(result, K8sEntity{Obj: c, Kind: &kind})
+			break
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+	return result
+}
+
+func (k K8sClient) ListVolumeClaims(ctx context.Context, n Namespace) ([]v1.PersistentVolumeClaim, error) {
+	list, err := k.core.PersistentVolumeClaims(n.String()).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing volume claims in %s", n)
+	}
+	return list.Items, nil
+}
+
+func namespaceFromPVC(claim v1.PersistentVolumeClaim) Namespace {
+	if claim.Namespace == "" {
+		return DefaultNamespace
+	}
+	return Namespace(claim.Namespace)
+}
+
+func statefulSetClaimTemplateNames(e K8sEntity) []string {
+	// The only claims inside a StatefulSet are its volumeClaimTemplates.
+	extracted, err := newExtractor(reflect.TypeOf(v1.PersistentVolumeClaim{})).extractPointersFrom(&e)
+	if err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, t := range extracted {
+		if claim, ok := t.(*v1.PersistentVolumeClaim); ok {
+			result = append(result, claim.Name)
+		}
+	}
+	return result
+}
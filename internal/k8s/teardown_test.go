@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+)
+
+func TestDeletionGroups(t *testing.T) {
+	yaml := strings.Join([]string{testyaml.MyNamespaceYAML, testyaml.SecretYaml, testyaml.CRDYAML, testyaml.SanchoYAML}, "\n---\n")
+	entities, err := ParseYAMLFromString(yaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups := DeletionGroups(entities)
+	var kinds [][]string
+	for _, g := range groups {
+		var groupKinds []string
+		for _, e := range g {
+			groupKinds = append(groupKinds, e.Kind.Kind)
+		}
+		kinds = append(kinds, groupKinds)
+	}
+
+	assert.Equal(t, [][]string{
+		{"Project"},
+		{"Deployment"},
+		{"Secret"},
+		{"CustomResourceDefinition"},
+		{"Namespace"},
+	}, kinds)
+}
+
+func TestKeptByHelm(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SecretYaml)
+	assert.False(t, entity.KeptByHelm())
+
+	kept := strings.Replace(testyaml.SecretYaml, "name: mysecret", `name: mysecret
+  annotations:
+    helm.sh/resource-policy: keep`, 1)
+	entity = parseOneEntity(t, kept)
+	assert.True(t, entity.KeptByHelm())
+}
+
+func TestStatefulSetVolumeClaims(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.RedisStatefulSetYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := []v1.PersistentVolumeClaim{
+		newClaim("redis-data-test-redis-master-1", "default"),
+		newClaim("redis-data-test-redis-master-0", "default"),
+		newClaim("redis-data-test-redis-master-0", "other"),
+		newClaim("redis-data-test-redis-master-slave-0", "default"),
+		newClaim("unrelated", "default"),
+	}
+
+	result := StatefulSetVolumeClaims(entities, claims)
+	var names []string
+	for _, e := range result {
+		assert.Equal(t, "PersistentVolumeClaim", e.Kind.Kind)
+		assert.Equal(t, DefaultNamespace, e.Namespace())
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"redis-data-test-redis-master-0", "redis-data-test-redis-master-1"}, names)
+}
+
+func TestIsSystemNamespace(t *testing.T) {
+	assert.True(t, IsSystemNamespace("default"))
+	assert.True(t, IsSystemNamespace("kube-system"))
+	assert.False(t, IsSystemNamespace("mynamespace"))
+}
+
+func newClaim(name string, namespace string) v1.PersistentVolumeClaim {
+	return v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+}
@@ -101,6 +101,32 @@ spec:
                 key: token
 `
 
+const SanchoMigrateInitContainerYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: sancho
+  namespace: sancho-ns
+  labels:
+    app: sancho
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: sancho
+  template:
+    metadata:
+      labels:
+        app: sancho
+    spec:
+      initContainers:
+      - name: migrate
+        image: gcr.io/some-project-162817/sancho-migrate
+      containers:
+      - name: sancho
+        image: gcr.io/some-project-162817/sancho
+`
+
 const SanchoBeta1YAML = `
 apiVersion: apps/v1beta1
 kind: Deployment
@@ -360,6 +386,63 @@ spec:
   backoffLimit: 4
 `
 
+const CronJobYAML = `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: hello
+spec:
+  schedule: "*/1 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: hello
+            image: gcr.io/foo/hello
+            args: ["/bin/sh", "-c", "date; echo Hello"]
+          restartPolicy: OnFailure
+`
+
+// A custom resource managed by an operator, with an embedded pod template.
+const OperatorAppYAML = `
+apiVersion: apps.example.com/v1
+kind: App
+metadata:
+  name: frontend
+spec:
+  replicas: 2
+  template:
+    metadata:
+      labels:
+        app: frontend
+    spec:
+      containers:
+      - name: web
+        image: gcr.io/foo/frontend
+        ports:
+        - containerPort: 8080
+`
+
+const OperatorAppEphemeralContainerYAML = `
+apiVersion: apps.example.com/v1
+kind: App
+metadata:
+  name: frontend
+spec:
+  template:
+    metadata:
+      labels:
+        app: frontend
+    spec:
+      containers:
+      - name: web
+        image: gcr.io/foo/frontend
+      ephemeralContainers:
+      - name: debug
+        image: gcr.io/foo/debugger
+`
+
 const PodYAML = `apiVersion: v1
 kind: Pod
 metadata:
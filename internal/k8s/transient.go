@@ -0,0 +1,51 @@
+package k8s
+
+import "strings"
+
+// Parts of the errors that kubectl and the apiserver return when a request
+// failed for a reason that's probably temporary, so trying again might work.
+var transientErrorMessages = []string{
+	// The apiserver or etcd took too long.
+	"i/o timeout",
+	"TLS handshake timeout",
+	"the server was unable to return a response in the time allotted",
+	"etcdserver: request timed out",
+	"Client.Timeout exceeded",
+	"context deadline exceeded",
+
+	// The apiserver is restarting, or overloaded.
+	"the server is currently unable to handle the request",
+	"connection refused",
+	"connection reset by peer",
+	"(ServiceUnavailable)",
+	"(TooManyRequests)",
+	"an error on the server (\"\") has prevented the request from succeeding",
+
+	// An admission webhook is down or slow.
+	"failed calling webhook",
+	"failed calling admission webhook",
+
+	// Someone else updated the object between when we read it and when we wrote it.
+	"the object has been modified; please apply your changes to the latest version",
+
+	// A Helm release is stuck in a pending state from an upgrade that didn't
+	// finish. We clear it before we retry.
+	"another operation (install/upgrade/rollback) is in progress",
+}
+
+// Whether a deploy failed for a reason that's probably temporary
+// (e.g., an apiserver timeout or an admission webhook that's down),
+// rather than because something's wrong with what we deployed.
+func IsTransientDeployError(err error) bool {
+	if err == nil || IsApplyConflictError(err) {
+		return false
+	}
+
+	msg := err.Error()
+	for _, m := range transientErrorMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
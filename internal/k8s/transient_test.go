@@ -0,0 +1,31 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransientDeployError(t *testing.T) {
+	for _, tc := range []struct {
+		stderr    string
+		transient bool
+	}{
+		{`Error from server (InternalError): error when creating "STDIN": Internal error occurred: failed calling webhook "validate.example.com": Post https://webhook.default.svc:443/validate?timeout=30s: dial tcp 10.0.0.1:443: connect: connection refused`, true},
+		{`Error from server (Timeout): error when applying patch: the server was unable to return a response in the time allotted, but may still be processing the request`, true},
+		{`Unable to connect to the server: net/http: TLS handshake timeout`, true},
+		{`Error from server (Conflict): error when applying patch: Operation cannot be fulfilled on deployments.apps "sancho": the object has been modified; please apply your changes to the latest version and try again`, true},
+		{`The Deployment "sancho" is invalid: spec.template.spec.containers[0].image: Required value`, false},
+		{`error: unable to recognize "STDIN": no matches for kind "Foo" in version "example.com/v1"`, false},
+	} {
+		t.Run(tc.stderr, func(t *testing.T) {
+			err := errors.Wrapf(fmt.Errorf("exit status 1"), "kubectl apply:\nstderr: %s", tc.stderr)
+			assert.Equal(t, tc.transient, IsTransientDeployError(err))
+		})
+	}
+
+	assert.False(t, IsTransientDeployError(nil))
+	assert.False(t, IsTransientDeployError(ApplyConflictError{Conflicts: []string{`conflict with "webhook" using apps/v1: .spec.replicas`}}))
+}
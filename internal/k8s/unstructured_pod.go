@@ -0,0 +1,141 @@
+package k8s
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Operators often define their own workload kinds (e.g., an "App" resource)
+// that embed a pod template somewhere in their spec. Tilt doesn't know about
+// these kinds ahead of time, so we find their pod specs by shape: a map
+// with a list of "containers" that all have images.
+type unstructuredPodSpec struct {
+	spec map[string]interface{}
+
+	// The pod template that this pod spec is the "spec" of,
+	// or nil if the pod spec isn't in a template (e.g., it's the spec of the object itself).
+	template map[string]interface{}
+}
+
+func (s unstructuredPodSpec) containers() []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, key := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		list, _ := s.spec[key].([]interface{})
+		for _, c := range list {
+			if m, ok := c.(map[string]interface{}); ok {
+				result = append(result, m)
+			}
+		}
+	}
+	return result
+}
+
+func (s unstructuredPodSpec) templateMetadata() map[string]interface{} {
+	meta, ok := s.template["metadata"].(map[string]interface{})
+	if !ok {
+		meta = make(map[string]interface{})
+		s.template["metadata"] = meta
+	}
+	return meta
+}
+
+func unstructuredContent(e K8sEntity) (map[string]interface{}, bool) {
+	u, ok := e.Obj.(runtime.Unstructured)
+	if !ok {
+		return nil, false
+	}
+	return u.UnstructuredContent(), true
+}
+
+// Find all the pod specs embedded in an unstructured object.
+// The maps are returned by reference, so callers can modify the object through them.
+func findUnstructuredPodSpecs(content map[string]interface{}) []unstructuredPodSpec {
+	var result []unstructuredPodSpec
+
+	var visit func(v interface{}, parent map[string]interface{}, key string)
+	visit = func(v interface{}, parent map[string]interface{}, key string) {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			if isUnstructuredPodSpec(x) {
+				s := unstructuredPodSpec{spec: x}
+				if key == "spec" && parent != nil {
+					s.template = parent
+				}
+				result = append(result, s)
+				return
+			}
+
+			keys := make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				visit(x[k], x, k)
+			}
+
+		case []interface{}:
+			for _, el := range x {
+				visit(el, nil, "")
+			}
+		}
+	}
+
+	// The top-level object isn't a pod template, even if it has a pod spec,
+	// so it's not a parent for the purposes of finding templates.
+	keys := make([]string, 0, len(content))
+	for k := range content {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		visit(content[k], nil, k)
+	}
+	return result
+}
+
+func isUnstructuredPodSpec(m map[string]interface{}) bool {
+	containers, ok := m["containers"].([]interface{})
+	if !ok || len(containers) == 0 {
+		return false
+	}
+	for _, c := range containers {
+		cMap, ok := c.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		image, ok := cMap["image"].(string)
+		if !ok || image == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Converts the pod templates embedded in an unstructured object to PodTemplateSpecs.
+//
+// These are copies, so modifying them won't modify the object.
+// Templates that don't convert cleanly aren't really pod templates, so we skip them.
+func extractUnstructuredPodTemplateSpecs(e K8sEntity) []*v1.PodTemplateSpec {
+	content, ok := unstructuredContent(e)
+	if !ok {
+		return nil
+	}
+
+	var result []*v1.PodTemplateSpec
+	for _, s := range findUnstructuredPodSpecs(content) {
+		if s.template == nil {
+			continue
+		}
+
+		template := &v1.PodTemplateSpec{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(s.template, template)
+		if err != nil {
+			continue
+		}
+		result = append(result, template)
+	}
+	return result
+}
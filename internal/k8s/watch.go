@@ -4,17 +4,21 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"time"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/windmilleng/tilt/internal/model"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -23,137 +27,278 @@ type watcher interface {
 	Watch(options metav1.ListOptions) (watch.Interface, error)
 }
 
-func (kCli K8sClient) makeWatcher(f watcherFactory, ls labels.Selector) (watch.Interface, error) {
+// Returns a watcher, and the namespace it watches ("" for all namespaces).
+func (kCli K8sClient) makeWatcher(f watcherFactory, ls labels.Selector) (watch.Interface, string, error) {
 	// passing "" gets us all namespaces
 	watcher, err := f("").Watch(metav1.ListOptions{LabelSelector: ls.String()})
 	if err == nil {
-		return watcher, nil
+		return watcher, "", nil
 	}
 
 	// If the request failed, we might be able to recover.
 	statusErr, isStatusErr := err.(*apiErrors.StatusError)
 	if !isStatusErr {
-		return nil, err
+		return nil, "", err
 	}
 
 	status := statusErr.ErrStatus
 	if status.Code == http.StatusForbidden {
 		// If this is a forbidden error, maybe the user just isn't allowed to watch this namespace.
 		// Let's narrow our request to just the config namespace, and see if that helps.
-		watcher, err := f(kCli.configNamespace.String()).Watch(metav1.ListOptions{LabelSelector: ls.String()})
+		ns := kCli.configNamespace.String()
+		watcher, err := f(ns).Watch(metav1.ListOptions{LabelSelector: ls.String()})
 		if err == nil {
-			return watcher, nil
+			return watcher, ns, nil
 		}
 
 		// ugh, it still failed. return the original error.
 	}
-	return nil, fmt.Errorf("%s, Reason: %s, Code: %d", status.Message, status.Reason, status.Code)
+	return nil, "", fmt.Errorf("%s, Reason: %s, Code: %d", status.Message, status.Reason, status.Code)
 }
 
-func (kCli K8sClient) WatchPods(ctx context.Context, ls labels.Selector) (<-chan *v1.Pod, error) {
+// The informer factories of a client, one for each namespace and label selector
+// that we watch, so that watches of the same objects share an informer
+// (and a single watch on the API server).
+type informerFactories struct {
+	mu        sync.Mutex
+	factories map[informerKey]informers.SharedInformerFactory
+}
+
+type informerKey struct {
+	namespace string
+	selector  string
+}
+
+func newInformerFactories() *informerFactories {
+	return &informerFactories{factories: make(map[informerKey]informers.SharedInformerFactory)}
+}
+
+func (f *informerFactories) get(clientSet kubernetes.Interface, ns string, ls labels.Selector) informers.SharedInformerFactory {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := informerKey{namespace: ns, selector: ls.String()}
+	factory, ok := f.factories[key]
+	if ok {
+		return factory
+	}
+
+	options := []informers.SharedInformerOption{
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = ls.String()
+		}),
+	}
+	if ns != "" {
+		options = append(options, informers.WithNamespace(ns))
+	}
+
+	// The informer's watch tells us about every change, so there's no need to resync.
+	factory = informers.NewSharedInformerFactoryWithOptions(clientSet, 0, options...)
+	f.factories[key] = factory
+	return factory
+}
+
+// Runs an informer for the objects that match the label selector, and calls
+// handler with every object it adds, updates, or deletes, until ctx is done.
+// If ns is empty, watches every namespace we're allowed to.
+//
+// Informers list once and then keep a single watch open, so they're much cheaper
+// for the API server than polling, and we hear about changes as soon as they happen.
+// Watches of the same objects share an informer, which runs for as long as the client does.
+func (kCli K8sClient) runInformer(ctx context.Context, ns Namespace, ls labels.Selector, f watcherFactory,
+	informerFor func(factory informers.SharedInformerFactory) cache.SharedIndexInformer,
+	handler func(obj interface{})) error {
+	return kCli.runInformerWithDeletes(ctx, ns, ls, f, informerFor, handler, handler)
+}
+
+// Like runInformer, but calls onDelete instead of onChange with the objects
+// that are deleted, for watchers that can't tell a delete from the last update.
+func (kCli K8sClient) runInformerWithDeletes(ctx context.Context, ns Namespace, ls labels.Selector, f watcherFactory,
+	informerFor func(factory informers.SharedInformerFactory) cache.SharedIndexInformer,
+	onChange func(obj interface{}), onDelete func(obj interface{})) error {
 	// HACK(dmiller): There's no way to get errors out of an informer. See https://github.com/kubernetes/client-go/issues/155
 	// In the meantime, at least to get authorization and some other errors let's try to set up a watcher and then just
 	// throw it away.
-	watcher, err := kCli.makeWatcher(func(ns string) watcher {
-		return kCli.core.Pods(ns)
-	}, ls)
-	if err != nil {
-		return nil, errors.Wrap(err, "pods.WatchFiles")
+	watchNs := string(ns)
+	if ns == "" {
+		watcher, allowedNs, err := kCli.makeWatcher(f, ls)
+		if err != nil {
+			return err
+		}
+		watcher.Stop()
+		watchNs = allowedNs
+	} else {
+		watcher, err := f(watchNs).Watch(metav1.ListOptions{LabelSelector: ls.String()})
+		if err != nil {
+			return err
+		}
+		watcher.Stop()
 	}
-	watcher.Stop()
 
-	factory := informers.NewSharedInformerFactoryWithOptions(kCli.clientSet, 5*time.Second, informers.WithTweakListOptions(func(o *metav1.ListOptions) {
-		o.LabelSelector = ls.String()
-	}))
-	informer := factory.Core().V1().Pods().Informer()
-
-	stopper := make(chan struct{})
-	ch := make(chan *v1.Pod)
+	factory := kCli.informers.get(kCli.clientSet, watchNs, ls)
+	informer := informerFor(factory)
 
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			mObj, ok := obj.(*v1.Pod)
-			if ok {
-				FixContainerStatusImages(mObj)
-				ch <- mObj
+			if ctx.Err() == nil {
+				onChange(obj)
 			}
 		},
 		DeleteFunc: func(obj interface{}) {
-			mObj, ok := obj.(*v1.Pod)
-			if ok {
-				FixContainerStatusImages(mObj)
-				ch <- mObj
+			if ctx.Err() != nil {
+				return
 			}
+			// If we missed the delete, the informer hands us a tombstone
+			// with the last state of the object that it knew about.
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			onDelete(obj)
 		},
 		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
-			oldPod, ok := oldObj.(*v1.Pod)
-			if !ok {
+			if oldObj == newObj || ctx.Err() != nil {
 				return
 			}
-
-			newPod, ok := newObj.(*v1.Pod)
-			if !ok {
-				return
-			}
-
-			if oldPod != newPod {
-				FixContainerStatusImages(newPod)
-				ch <- newPod
-			}
+			onChange(newObj)
 		},
 	})
 
-	go informer.Run(stopper)
-	// TODO(dmiller): is this right?
-	go func() {
-		<-ctx.Done()
-		close(stopper)
-	}()
+	// Starts the informers that aren't running yet.
+	factory.Start(wait.NeverStop)
+	return nil
+}
+
+func (kCli K8sClient) WatchPods(ctx context.Context, ls labels.Selector) (<-chan *v1.Pod, error) {
+	ch := make(chan *v1.Pod)
+	err := kCli.runInformer(ctx, "", ls, func(ns string) watcher {
+		return kCli.core.Pods(ns)
+	}, func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return factory.Core().V1().Pods().Informer()
+	}, func(obj interface{}) {
+		pod, ok := obj.(*v1.Pod)
+		if !ok {
+			return
+		}
 
+		FixContainerStatusImages(pod)
+		select {
+		case ch <- pod:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "pods.WatchFiles")
+	}
 	return ch, nil
 }
 
 func (kCli K8sClient) WatchServices(ctx context.Context, lps []model.LabelPair) (<-chan *v1.Service, error) {
-	ch := make(chan *v1.Service)
-
 	ls := labels.Set{}
 	for _, lp := range lps {
 		ls[lp.Key] = lp.Value
 	}
 
-	watcher, err := kCli.makeWatcher(func(ns string) watcher {
+	ch := make(chan *v1.Service)
+	err := kCli.runInformer(ctx, "", ls.AsSelector(), func(ns string) watcher {
 		return kCli.core.Services(ns)
-	}, ls.AsSelector())
+	}, func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return factory.Core().V1().Services().Informer()
+	}, func(obj interface{}) {
+		service, ok := obj.(*v1.Service)
+		if !ok {
+			return
+		}
+
+		select {
+		case ch <- service:
+		case <-ctx.Done():
+		}
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "Services.WatchFiles")
 	}
+	return ch, nil
+}
 
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.ResultChan():
-				if !ok {
-					close(ch)
-					return
-				}
-
-				if event.Object == nil {
-					continue
-				}
-
-				service, ok := event.Object.(*v1.Service)
-				if !ok {
-					continue
-				}
-
-				ch <- service
-			case <-ctx.Done():
-				watcher.Stop()
-				close(ch)
-				return
-			}
+func (kCli K8sClient) WatchEvents(ctx context.Context, n Namespace) (<-chan *v1.Event, error) {
+	ch := make(chan *v1.Event)
+
+	// Events don't carry the labels of the object they're about,
+	// so we can't filter them by label, only by namespace.
+	err := kCli.runInformer(ctx, Namespace(n.String()), labels.Everything(), func(ns string) watcher {
+		return kCli.core.Events(ns)
+	}, func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return factory.Core().V1().Events().Informer()
+	}, func(obj interface{}) {
+		k8sEvent, ok := obj.(*v1.Event)
+		if !ok {
+			return
 		}
-	}()
 
+		select {
+		case ch <- k8sEvent:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Events.Watch")
+	}
+	return ch, nil
+}
+
+func (kCli K8sClient) WatchReplicaSets(ctx context.Context, ls labels.Selector) (<-chan *appsv1.ReplicaSet, error) {
+	ch := make(chan *appsv1.ReplicaSet)
+	err := kCli.runInformer(ctx, "", ls, func(ns string) watcher {
+		return kCli.clientSet.AppsV1().ReplicaSets(ns)
+	}, func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return factory.Apps().V1().ReplicaSets().Informer()
+	}, func(obj interface{}) {
+		rs, ok := obj.(*appsv1.ReplicaSet)
+		if !ok {
+			return
+		}
+
+		select {
+		case ch <- rs:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ReplicaSets.Watch")
+	}
+	return ch, nil
+}
+
+// A change to an Ingress. Deleted is set if the Ingress is gone.
+type IngressEvent struct {
+	Ingress *extv1beta1.Ingress
+	Deleted bool
+}
+
+func (kCli K8sClient) WatchIngresses(ctx context.Context, ls labels.Selector) (<-chan IngressEvent, error) {
+	ch := make(chan IngressEvent)
+	send := func(obj interface{}, deleted bool) {
+		ing, ok := obj.(*extv1beta1.Ingress)
+		if !ok {
+			return
+		}
+
+		select {
+		case ch <- IngressEvent{Ingress: ing, Deleted: deleted}:
+		case <-ctx.Done():
+		}
+	}
+	err := kCli.runInformerWithDeletes(ctx, "", ls, func(ns string) watcher {
+		return kCli.clientSet.ExtensionsV1beta1().Ingresses(ns)
+	}, func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return factory.Extensions().V1beta1().Ingresses().Informer()
+	}, func(obj interface{}) {
+		send(obj, false)
+	}, func(obj interface{}) {
+		send(obj, true)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Ingresses.Watch")
+	}
 	return ch, nil
 }
@@ -79,6 +79,16 @@ func TestK8sClient_WatchServicesLabelsPassed(t *testing.T) {
 	tf.testServiceLabels(lps, lps)
 }
 
+func TestK8sClient_WatchReplicaSetsLabelsPassed(t *testing.T) {
+	tf := newWatchTestFixture(t)
+	ls := labels.Set{"foo": "bar"}
+	_, err := tf.kCli.WatchReplicaSets(tf.ctx, ls.AsSelector())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ls.AsSelector(), tf.watchRestrictions.Labels)
+}
+
 func TestK8sClient_WatchPodsError(t *testing.T) {
 	tf := newWatchTestFixture(t)
 	tf.watchErr = &errors.StatusError{
@@ -129,6 +139,7 @@ func newWatchTestFixture(t *testing.T) *watchTestFixture {
 		restConfig:    nil,
 		portForwarder: nil,
 		clientSet:     c,
+		informers:     newInformerFactories(),
 	}
 
 	return ret
@@ -0,0 +1,81 @@
+// Package metrics keeps numbers about how fast Tilt is, for Prometheus to
+// scrape from the web server's /metrics, so that teams can tell when their
+// inner loop gets slower.
+package metrics
+
+import (
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+)
+
+// Holds only Tilt's metrics, and not those of the libraries we vendor that
+// register their own with the default registry.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// Every build, by resource, by whether it was a live update or an image
+	// build, and by whether it failed. The _count is the number of builds.
+	BuildDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "tilt",
+		Name:      "build_duration_seconds",
+		Help:      "How long each build took.",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300, 600},
+	}, []string{"resource", "update_type", "result"})
+
+	BuildQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "tilt",
+		Name:      "build_queue_depth",
+		Help:      "How many resources are waiting to build.",
+	})
+
+	FileEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tilt",
+		Name:      "file_events_total",
+		Help:      "How many changed files the file watcher saw, after ignores.",
+	}, []string{"target"})
+
+	K8sRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "tilt",
+		Name:      "k8s_request_duration_seconds",
+		Help:      "How long requests to the Kubernetes API took.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"verb"})
+
+	K8sRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tilt",
+		Name:      "k8s_requests_total",
+		Help:      "Requests to the Kubernetes API, by method and status code.",
+	}, []string{"method", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		BuildDuration,
+		BuildQueueDepth,
+		FileEvents,
+		K8sRequestDuration,
+		K8sRequests,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(os.Getpid(), "tilt"),
+	)
+
+	clientmetrics.Register(k8sLatency{}, k8sResult{})
+}
+
+// Leaves the URL out, since it has the names of objects in it, and
+// every name would be a new time series.
+type k8sLatency struct{}
+
+func (k8sLatency) Observe(verb string, u url.URL, latency time.Duration) {
+	K8sRequestDuration.WithLabelValues(verb).Observe(latency.Seconds())
+}
+
+type k8sResult struct{}
+
+func (k8sResult) Increment(code string, method string, host string) {
+	K8sRequests.WithLabelValues(method, code).Inc()
+}
@@ -4,7 +4,12 @@ import (
 	"time"
 )
 
-const BuildHistoryLimit = 2
+const BuildHistoryLimit = 10
+
+// Each build's log can be as long as MaxLogLength, so we only keep the logs
+// of the most recent builds in the history. For older ones, we keep
+// what changed and whether it worked.
+const BuildHistoryLogLimit = 2
 
 type BuildRecord struct {
 	Edits      []string
@@ -14,8 +19,31 @@ type BuildRecord struct {
 	FinishTime time.Time // IsZero() == true for in-progress builds
 	Reason     BuildReason
 	Log        Log `testdiff:"ignore"`
+
+	// How the build got the new code into the running resource, and, when it
+	// meant to live update but built an image instead, why.
+	UpdateType     UpdateType
+	FallbackReason string
+
+	// The build was canceled before it finished. Error is still set (so that
+	// nothing mistakes it for a successful build), but it's not a failure.
+	Canceled bool
 }
 
+// How a build got the new code into the running resource.
+type UpdateType string
+
+const (
+	// Builds that failed, or that don't update a container (like local resources).
+	UpdateTypeNone UpdateType = ""
+
+	// Copied the changed files into the running container.
+	UpdateTypeLiveUpdate UpdateType = "live update"
+
+	// Built a new image and redeployed it.
+	UpdateTypeImageBuild UpdateType = "image build"
+)
+
 func (bs BuildRecord) Empty() bool {
 	return bs.StartTime.IsZero()
 }
@@ -151,9 +151,133 @@ type K8sTarget struct {
 	ExtraPodSelectors []labels.Selector
 	ResourceNames     []string
 
+	// The number of pods that the target's Deployments want to run.
+	// The resource isn't ready until that many pods are ready.
+	DeploymentReplicas int
+
+	// Whether the target's objects run pods. If they don't (e.g., the target
+	// is all ConfigMaps and Services), the resource is ready once it deploys.
+	HasPods bool
+
+	// If set, Tilt creates an image pull secret with this name from the
+	// local docker credentials for any registry it pushes to, and attaches
+	// it to the pods it deploys.
+	ImagePullSecret string
+
+	// If set, the namespace that the resource's objects were rewritten into.
+	// Tilt creates it if it doesn't exist, and deletes it on `tilt down`
+	// if Tilt created it.
+	Namespace string
+
+	// If set, Tilt deploys with server-side apply, with itself as the field manager,
+	// so that it doesn't fight with other controllers that write to the same objects.
+	ServerSideApply bool
+
+	// If set, server-side apply takes ownership of fields that other field managers own,
+	// rather than failing.
+	ForceApplyConflicts bool
+
+	// If non-empty, only stream logs from the containers with these names.
+	LogContainers []string
+
+	// Never stream logs from the containers with these names.
+	IgnoredLogContainers []string
+
+	// Containers that aren't part of the app (e.g., service mesh proxies), which
+	// Tilt leaves out of the pod's readiness and status, and doesn't stream logs from
+	// unless they're in LogContainers.
+	SidecarContainers []string
+
+	// If set, Tilt only treats SidecarContainers as sidecars, and doesn't
+	// look for the ones that a service mesh injected.
+	DisableSidecarDetection bool
+
+	// If set, Tilt deploys this target as a Helm release, instead of applying its YAML.
+	HelmRelease *HelmRelease
+
+	// If set, the kubeconfig context to deploy this target to,
+	// instead of the context that Tilt started with.
+	KubeContext string
+
+	// If set, the kubeconfig file to load the kube context from,
+	// the kubeconfig user to deploy as, and the user and groups to impersonate,
+	// so that the target deploys with different credentials than the developer's own.
+	KubeConfigPath    string
+	KubeUser          string
+	ImpersonateUser   string
+	ImpersonateGroups []string
+
+	// Labels and annotations to add to every object Tilt deploys (and its pod templates),
+	// on top of the labels that Tilt adds itself.
+	ObjectLabels      []LabelPair
+	ObjectAnnotations []LabelPair
+
+	// If set, the label that ties deployed objects to this resource,
+	// instead of the default "tilt-manifest".
+	ManifestLabel string
+
+	// Identifies the Tiltfile that the target came from, so that Tilt can find
+	// the objects it deployed for resources that the Tiltfile no longer has.
+	Project string
+
+	// How to retry a deploy that fails for a reason that's probably temporary
+	// (e.g., an apiserver timeout), before marking the resource as failed.
+	DeployRetry DeployRetryPolicy
+
 	dependencyIDs []TargetID
 }
 
+// How many times to retry a deploy that failed with a temporary error,
+// and how long to wait before the first retry. The wait doubles after each retry.
+type DeployRetryPolicy struct {
+	Retries int
+	Backoff time.Duration
+}
+
+var DefaultDeployRetryPolicy = DeployRetryPolicy{Retries: 3, Backoff: time.Second}
+
+// A chart that Tilt installs and upgrades as a real Helm release
+// (with `helm upgrade --install`), so that Helm runs the chart's hooks
+// and lookup functions, and uninstalls it on `tilt down`.
+type HelmRelease struct {
+	Name string
+
+	// A path to a local chart, or a chart reference (e.g., "stable/redis").
+	Chart string
+
+	Namespace string
+
+	// Values files to pass with --values, and key=value pairs to pass with --set.
+	ValuesFiles []string
+	Set         []string
+
+	// The chart values to set to the refs of the images that Tilt builds.
+	ImageValues []HelmImageValue
+}
+
+// Tells Tilt where a chart expects the ref of an image it builds.
+type HelmImageValue struct {
+	ImageID TargetID
+
+	// The value to set to the whole image ref.
+	Key string
+
+	// For charts that take the repository and tag of the image as separate values.
+	RepositoryKey string
+	TagKey        string
+}
+
+// The port forwards that go to the resource's pod, rather than to a Service.
+func (k8s K8sTarget) PodPortForwards() []PortForward {
+	var result []PortForward
+	for _, pf := range k8s.PortForwards {
+		if pf.Service == "" {
+			result = append(result, pf)
+		}
+	}
+	return result
+}
+
 func (k8s K8sTarget) Empty() bool { return reflect.DeepEqual(k8s, K8sTarget{}) }
 
 func (k8s K8sTarget) DependencyIDs() []TargetID {
@@ -165,7 +289,7 @@ func (k8s K8sTarget) Validate() error {
 		return fmt.Errorf("[Validate] K8s resources missing name:\n%s", k8s.YAML)
 	}
 
-	if k8s.YAML == "" {
+	if k8s.YAML == "" && k8s.HelmRelease == nil {
 		return fmt.Errorf("[Validate] K8s resources %q missing YAML", k8s.Name)
 	}
 
@@ -184,6 +308,98 @@ func (k8s K8sTarget) WithDependencyIDs(ids []TargetID) K8sTarget {
 	return k8s
 }
 
+func (k8s K8sTarget) WithImagePullSecret(name string) K8sTarget {
+	k8s.ImagePullSecret = name
+	return k8s
+}
+
+func (k8s K8sTarget) WithNamespace(ns string) K8sTarget {
+	k8s.Namespace = ns
+	return k8s
+}
+
+func (k8s K8sTarget) WithKubeContext(kubeContext string) K8sTarget {
+	k8s.KubeContext = kubeContext
+	return k8s
+}
+
+func (k8s K8sTarget) WithCredentials(kubeConfigPath string, user string, impersonateUser string, impersonateGroups []string) K8sTarget {
+	k8s.KubeConfigPath = kubeConfigPath
+	k8s.KubeUser = user
+	k8s.ImpersonateUser = impersonateUser
+	k8s.ImpersonateGroups = impersonateGroups
+	return k8s
+}
+
+func (k8s K8sTarget) WithServerSideApply(serverSide bool, forceConflicts bool) K8sTarget {
+	k8s.ServerSideApply = serverSide
+	k8s.ForceApplyConflicts = forceConflicts
+	return k8s
+}
+
+func (k8s K8sTarget) WithObjectMetadata(labels []LabelPair, annotations []LabelPair, manifestLabel string) K8sTarget {
+	k8s.ObjectLabels = labels
+	k8s.ObjectAnnotations = annotations
+	k8s.ManifestLabel = manifestLabel
+	return k8s
+}
+
+func (k8s K8sTarget) WithProject(project string) K8sTarget {
+	k8s.Project = project
+	return k8s
+}
+
+func (k8s K8sTarget) WithDeployRetry(policy DeployRetryPolicy) K8sTarget {
+	k8s.DeployRetry = policy
+	return k8s
+}
+
+func (k8s K8sTarget) WithHelmRelease(release HelmRelease) K8sTarget {
+	k8s.HelmRelease = &release
+	return k8s
+}
+
+func (k8s K8sTarget) WithLogContainers(include, ignore []string) K8sTarget {
+	k8s.LogContainers = include
+	k8s.IgnoredLogContainers = ignore
+	return k8s
+}
+
+func (k8s K8sTarget) WithSidecars(names []string, detect bool) K8sTarget {
+	k8s.SidecarContainers = names
+	k8s.DisableSidecarDetection = !detect
+	return k8s
+}
+
+// Whether we should stream logs from the container with the given name.
+func (k8s K8sTarget) ShouldStreamLogs(containerName string) bool {
+	for _, name := range k8s.IgnoredLogContainers {
+		if name == containerName {
+			return false
+		}
+	}
+	if len(k8s.LogContainers) == 0 {
+		return true
+	}
+	for _, name := range k8s.LogContainers {
+		if name == containerName {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether we should stream logs from the sidecar container with the given name.
+// Sidecars are noisy, so we only stream them when asked for by name.
+func (k8s K8sTarget) ShouldStreamSidecarLogs(containerName string) bool {
+	for _, name := range k8s.LogContainers {
+		if name == containerName {
+			return k8s.ShouldStreamLogs(containerName)
+		}
+	}
+	return false
+}
+
 func (k8s K8sTarget) AppendYAML(y string) K8sTarget {
 	if k8s.YAML == "" {
 		k8s.YAML = y
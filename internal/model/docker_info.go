@@ -5,10 +5,12 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/docker/distribution/reference"
 
 	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/ospath"
 	"github.com/windmilleng/tilt/internal/sliceutils"
 )
 
@@ -216,9 +218,126 @@ func (i ImageTarget) WithTiltFilename(f string) ImageTarget {
 // TODO(nick): This method should be deleted. We should just de-dupe and sort LocalPaths once
 // when we create it, rather than have a duplicate method that does the "right" thing.
 func (i ImageTarget) Dependencies() []string {
+	if paths, ok := i.contextReadPaths(); ok {
+		deps := make([]string, len(paths))
+		for j, p := range paths {
+			deps[j] = globStaticPrefix(p)
+		}
+		return sliceutils.DedupedAndSorted(deps)
+	}
 	return sliceutils.DedupedAndSorted(i.LocalPaths())
 }
 
+// Matches files in the build context that can't affect the image,
+// because the Dockerfile never reads them and we never sync them.
+func (i ImageTarget) UnreadContextMatcher() PathMatcher {
+	paths, ok := i.contextReadPaths()
+	if !ok {
+		return EmptyMatcher
+	}
+	return unreadContextMatcher{
+		contextDir: i.DockerBuildInfo().BuildPath,
+		readPaths:  paths,
+	}
+}
+
+// If we know which files in the build context the Dockerfile reads, returns
+// those files, plus any files in the context that we sync or fall back on.
+func (i ImageTarget) contextReadPaths() ([]string, bool) {
+	db, ok := i.BuildDetails.(DockerBuild)
+	if !ok || len(db.ContextSources) == 0 {
+		return nil, false
+	}
+
+	paths := append([]string{}, db.ContextSources...)
+	for _, sync := range db.FastBuild.Syncs {
+		paths = append(paths, sync.LocalPath)
+	}
+	for _, sync := range db.LiveUpdate.SyncSteps() {
+		paths = append(paths, sync.LocalPath)
+	}
+	fallBack := db.LiveUpdate.FallBackOnFiles()
+	for _, p := range fallBack.Paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(fallBack.BaseDirectory, p)
+		}
+		paths = append(paths, p)
+	}
+
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if ospath.IsChild(db.BuildPath, p) {
+			result = append(result, p)
+		}
+	}
+	return result, true
+}
+
+func isGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// The longest leading part of the path without any glob characters,
+// e.g., /src/pkg/*.go -> /src/pkg
+func globStaticPrefix(p string) string {
+	for isGlob(p) {
+		p = filepath.Dir(p)
+	}
+	return p
+}
+
+type unreadContextMatcher struct {
+	contextDir string
+	readPaths  []string
+}
+
+func (m unreadContextMatcher) Matches(f string, isDir bool) (bool, error) {
+	if !ospath.IsChild(m.contextDir, f) {
+		return false, nil
+	}
+
+	for _, p := range m.readPaths {
+		if !isGlob(p) {
+			if ospath.IsChild(p, f) {
+				return false, nil
+			}
+			continue
+		}
+
+		// Globs match files or directories, and Docker copies the whole
+		// directory, so check every ancestor of the file in the context.
+		for current := f; ospath.IsChild(m.contextDir, current); current = filepath.Dir(current) {
+			match, err := filepath.Match(p, current)
+			if err != nil {
+				return false, err
+			}
+			if match {
+				return false, nil
+			}
+			if current == m.contextDir {
+				break
+			}
+		}
+	}
+	return true, nil
+}
+
+// A directory is entirely unread if none of the paths Docker reads
+// are in it (or it in them).
+func (m unreadContextMatcher) MatchesEntireDir(dir string) (bool, error) {
+	matches, err := m.Matches(dir, true)
+	if err != nil || !matches {
+		return false, err
+	}
+
+	for _, p := range m.readPaths {
+		if ospath.IsChild(dir, globStaticPrefix(p)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func ImageTargetsByID(iTargets []ImageTarget) map[TargetID]ImageTarget {
 	result := make(map[TargetID]ImageTarget, len(iTargets))
 	for _, target := range iTargets {
@@ -228,15 +347,47 @@ func ImageTargetsByID(iTargets []ImageTarget) map[TargetID]ImageTarget {
 }
 
 type DockerBuild struct {
-	Dockerfile string
-	BuildPath  string // the absolute path to the files
-	BuildArgs  DockerBuildArgs
-	FastBuild  FastBuild  // Optionally, can use FastBuild to update this build in place.
-	LiveUpdate LiveUpdate // Optionally, can use LiveUpdate to update this build in place.
+	Dockerfile  string
+	BuildPath   string // the absolute path to the files
+	BuildArgs   DockerBuildArgs
+	FastBuild   FastBuild         // Optionally, can use FastBuild to update this build in place.
+	LiveUpdate  LiveUpdate        // Optionally, can use LiveUpdate to update this build in place.
+	TargetStage DockerBuildTarget // Optionally, the stage of a multi-stage Dockerfile to build.
+
+	// If true, tag the image with a hash of its build inputs (context, Dockerfile,
+	// build args) rather than its image digest, so that identical inputs always
+	// produce the same ref, and we can skip builds we've already done.
+	ContentTag bool
+
+	// Equivalent to `docker build --network`, e.g. "host".
+	Network string
+
+	// Equivalent to `docker build --ssh`, e.g. "default" to forward
+	// the local SSH agent into RUN --mount=type=ssh steps.
+	SSHSpecs []string
+
+	// Equivalent to `docker build --add-host`, in host:ip form.
+	ExtraHosts []string
+
+	// Equivalent to `docker build --platform`, e.g. "linux/amd64".
+	// If empty, builds for the platform of the Docker daemon.
+	Platform string
+
+	// The only files in BuildPath that can affect the image, as absolute
+	// paths that may contain globs (derived from the Dockerfile's ADD/COPY
+	// instructions). If empty, any file in BuildPath might affect the image.
+	ContextSources []string
 }
 
 func (DockerBuild) buildDetails() {}
 
+// The name of a build stage in a multi-stage Dockerfile.
+// Equivalent to `docker build --target`.
+type DockerBuildTarget string
+
+func (t DockerBuildTarget) String() string { return string(t) }
+func (t DockerBuildTarget) Empty() bool    { return t == "" }
+
 type FastBuild struct {
 	BaseDockerfile string
 	Syncs          []Sync
@@ -265,6 +416,10 @@ type CustomBuild struct {
 	Fast        FastBuild
 	LiveUpdate  LiveUpdate // Optionally, can use LiveUpdate to update this build in place.
 	DisablePush bool
+
+	// The platform the command should build for, e.g. "linux/amd64".
+	// Exported to the command as $DOCKER_DEFAULT_PLATFORM.
+	Platform string
 }
 
 func (CustomBuild) buildDetails() {}
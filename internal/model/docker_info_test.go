@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+)
+
+func newContextSourcesTarget(t *testing.T) ImageTarget {
+	lu, err := NewLiveUpdate([]LiveUpdateStep{
+		LiveUpdateSyncStep{Source: "/ctx/static", Dest: "/app/static"},
+		LiveUpdateSyncStep{Source: "/elsewhere", Dest: "/app/elsewhere"},
+	}, "/ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewImageTarget(container.MustParseSelector("gcr.io/foo")).WithBuildDetails(DockerBuild{
+		BuildPath:      "/ctx",
+		ContextSources: []string{"/ctx/Dockerfile", "/ctx/src", "/ctx/config/*.json"},
+		LiveUpdate:     lu,
+	})
+}
+
+func TestDependenciesFromContextSources(t *testing.T) {
+	iTarget := newContextSourcesTarget(t)
+	assert.Equal(t, []string{"/ctx/Dockerfile", "/ctx/config", "/ctx/src", "/ctx/static"}, iTarget.Dependencies())
+}
+
+func TestDependenciesWithoutContextSources(t *testing.T) {
+	iTarget := NewImageTarget(container.MustParseSelector("gcr.io/foo")).WithBuildDetails(DockerBuild{
+		BuildPath: "/ctx",
+	})
+	assert.Equal(t, []string{"/ctx"}, iTarget.Dependencies())
+	assert.Equal(t, EmptyMatcher, iTarget.UnreadContextMatcher())
+}
+
+func TestUnreadContextMatcher(t *testing.T) {
+	m := newContextSourcesTarget(t).UnreadContextMatcher()
+
+	for _, f := range []string{"/ctx/Dockerfile", "/ctx/src/main.go", "/ctx/config/app.json", "/ctx/static/index.html", "/other/file.txt"} {
+		unread, err := m.Matches(f, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, unread, "expected %s to be read", f)
+	}
+
+	for _, f := range []string{"/ctx/README.md", "/ctx/config/app.yaml", "/ctx/srcfoo"} {
+		unread, err := m.Matches(f, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, unread, "expected %s to be unread", f)
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+// Somewhere that Tilt sends build and deploy events, as set up by the
+// event_sink Tiltfile function. Exactly one of URL and Cmd is set.
+type EventSink struct {
+	// POST each event as JSON to this URL.
+	URL string
+
+	// Run this command for each event, with the event as JSON on stdin.
+	Cmd Cmd
+
+	// The directory Cmd runs in (the Tiltfile's directory, like local_resource commands).
+	Workdir string
+}
+
+func (s EventSink) String() string {
+	if s.URL != "" {
+		return s.URL
+	}
+	return s.Cmd.String()
+}
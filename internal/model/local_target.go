@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/windmilleng/tilt/internal/sliceutils"
+)
+
+// A resource that runs on the user's machine instead of in a cluster or container.
+type LocalTarget struct {
+	Name TargetName
+
+	// Runs to completion on each update (e.g., to compile a binary).
+	Cmd Cmd
+
+	// If set, a long-running process (e.g., a dev server) that Tilt starts
+	// after each successful update, and stops before the next one starts.
+	ServeCmd Cmd
+
+	// The directory both commands run in.
+	Workdir string
+
+	// Changes to these paths update the resource.
+	deps []string
+
+	repos []LocalGitRepo
+}
+
+func NewLocalTarget(name TargetName, cmd Cmd, serveCmd Cmd, deps []string, workdir string) LocalTarget {
+	return LocalTarget{
+		Name:     name,
+		Cmd:      cmd,
+		ServeCmd: serveCmd,
+		Workdir:  workdir,
+		deps:     sliceutils.DedupedAndSorted(deps),
+	}
+}
+
+func (lt LocalTarget) Empty() bool { return lt.ID().Empty() }
+
+func (lt LocalTarget) ID() TargetID {
+	return TargetID{
+		Type: TargetTypeLocal,
+		Name: lt.Name,
+	}
+}
+
+func (lt LocalTarget) DependencyIDs() []TargetID {
+	return nil
+}
+
+func (lt LocalTarget) WithRepos(repos []LocalGitRepo) LocalTarget {
+	lt.repos = append(append([]LocalGitRepo{}, lt.repos...), repos...)
+	return lt
+}
+
+func (lt LocalTarget) Dependencies() []string {
+	return append([]string{}, lt.deps...)
+}
+
+func (lt LocalTarget) LocalRepos() []LocalGitRepo {
+	return lt.repos
+}
+
+func (lt LocalTarget) Dockerignores() []Dockerignore {
+	return nil
+}
+
+func (lt LocalTarget) IgnoredLocalDirectories() []string {
+	return nil
+}
+
+func (lt LocalTarget) Validate() error {
+	if lt.ID().Empty() {
+		return fmt.Errorf("[Validate] LocalTarget missing name")
+	}
+
+	if lt.Cmd.Empty() && lt.ServeCmd.Empty() {
+		return fmt.Errorf("[Validate] LocalTarget %s needs a cmd or a serve_cmd", lt.Name)
+	}
+
+	return nil
+}
+
+var _ TargetSpec = LocalTarget{}
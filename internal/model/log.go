@@ -15,43 +15,101 @@ const maxLogLengthInBytes = 120 * 1000
 const newlineByte = byte('\n')
 
 // All LogLines should end in a \n to be considered "complete".
-// We expect this will have more metadata over time about where the line came from.
-type logLine []byte
+type logLine struct {
+	text   []byte
+	source LogSource
+
+	// Parsed when the line is appended, so that filtering doesn't have to.
+	level LogLevel
+}
+
+func newLogLine(text []byte, source LogSource) logLine {
+	return logLine{text: text, source: source, level: parseLogLevel(text)}
+}
 
 func (l logLine) IsComplete() bool {
-	lineLen := len(l)
-	return lineLen > 0 && l[lineLen-1] == newlineByte
+	lineLen := len(l.text)
+	return lineLen > 0 && l.text[lineLen-1] == newlineByte
 }
 
 func (l logLine) Len() int {
-	return len(l)
+	return len(l.text)
 }
 
 func (l logLine) String() string {
-	return string(l)
+	return string(l.text)
+}
+
+func (l logLine) matches(f LogFilter) bool {
+	if f.Source != "" && f.Source != l.source {
+		return false
+	}
+	return l.level >= f.MinLevel
 }
 
 func linesFromString(s string) []logLine {
-	return linesFromBytes([]byte(s))
+	return linesFromBytes([]byte(s), LogSourceTilt)
 }
 
-func linesFromBytes(bs []byte) []logLine {
+func linesFromBytes(bs []byte, source LogSource) []logLine {
 	lines := []logLine{}
 	lastBreak := 0
 	for i, b := range bs {
 		if b == newlineByte {
-			lines = append(lines, bs[lastBreak:i+1])
+			lines = append(lines, newLogLine(bs[lastBreak:i+1], source))
 			lastBreak = i + 1
 		}
 	}
 	if lastBreak < len(bs) {
-		lines = append(lines, bs[lastBreak:])
+		lines = append(lines, newLogLine(bs[lastBreak:], source))
 	}
 	return lines
 }
 
 type Log struct {
 	lines []logLine
+
+	// How many bytes we've truncated off the start of the log, so that
+	// readers can find what's new since they last looked.
+	truncatedBytes int
+
+	// The same, by the source and level of the lines we truncated, so that
+	// a filtered log knows how much of it we truncated.
+	truncatedByKind truncatedBytesByKind
+}
+
+type truncatedBytesByKind [len(logSources)][LogLevelError + 1]int
+
+func (t *truncatedBytesByKind) add(line logLine) {
+	for i, source := range logSources {
+		if source == line.source {
+			t[i][line.level] += line.Len()
+			return
+		}
+	}
+}
+
+func (t truncatedBytesByKind) filter(f LogFilter) truncatedBytesByKind {
+	var result truncatedBytesByKind
+	for i, source := range logSources {
+		if f.Source != "" && f.Source != source {
+			continue
+		}
+		for level := f.MinLevel; level <= LogLevelError; level++ {
+			result[i][level] = t[i][level]
+		}
+	}
+	return result
+}
+
+func (t truncatedBytesByKind) total() int {
+	result := 0
+	for _, levels := range t {
+		for _, n := range levels {
+			result += n
+		}
+	}
+	return result
 }
 
 func NewLog(s string) Log {
@@ -70,14 +128,63 @@ func (l Log) MarshalJSON() ([]byte, error) {
 	return json.Marshal(l.String())
 }
 
+func (l *Log) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	*l = NewLog(s)
+	return nil
+}
+
 func (l Log) Len() int {
 	result := 0
 	for _, line := range l.lines {
-		result += len(line)
+		result += line.Len()
 	}
 	return result
 }
 
+// How many bytes have ever been appended to the log, including ones that
+// we've since truncated.
+func (l Log) TotalLen() int {
+	return l.truncatedBytes + l.Len()
+}
+
+// Everything appended to the log after the first `offset` bytes (see TotalLen).
+// If we've truncated some of that, returns what's left.
+func (l Log) Since(offset int) string {
+	s := l.String()
+	start := offset - l.truncatedBytes
+	if start <= 0 {
+		return s
+	}
+	if start >= len(s) {
+		return ""
+	}
+	return s[start:]
+}
+
+// Only the lines that match the filter.
+//
+// The filtered log counts only the truncated bytes that matched the filter,
+// so its offsets (see TotalLen and Since) work like any other log's, as long
+// as you always filter the same way.
+func (l Log) Filter(f LogFilter) Log {
+	if f.Empty() {
+		return l
+	}
+	var lines []logLine
+	for _, line := range l.lines {
+		if line.matches(f) {
+			lines = append(lines, line)
+		}
+	}
+	truncatedByKind := l.truncatedByKind.filter(f)
+	return Log{lines: lines, truncatedBytes: truncatedByKind.total(), truncatedByKind: truncatedByKind}
+}
+
 func (l Log) String() string {
 	lines := make([]string, len(l.lines))
 	for i, line := range l.lines {
@@ -100,7 +207,7 @@ func timestampPrefix(ts time.Time) []byte {
 // longer than `maxLogLengthInBytes`. (which maybe means a pedant would say this isn't strictly an `append`?)
 func AppendLog(l Log, le LogEvent, timestampsEnabled bool) Log {
 	isStartingNewLine := len(l.lines) == 0 || l.lines[len(l.lines)-1].IsComplete()
-	addedLines := linesFromBytes(le.Message())
+	addedLines := linesFromBytes(le.Message(), logEventSource(le))
 	if len(addedLines) == 0 {
 		return l
 	}
@@ -109,7 +216,7 @@ func AppendLog(l Log, le LogEvent, timestampsEnabled bool) Log {
 		ts := le.Time()
 		for i, line := range addedLines {
 			if i != 0 || isStartingNewLine {
-				addedLines[i] = append(timestampPrefix(ts), line...)
+				addedLines[i].text = append(timestampPrefix(ts), line.text...)
 			}
 		}
 	}
@@ -119,14 +226,22 @@ func AppendLog(l Log, le LogEvent, timestampsEnabled bool) Log {
 		newLines = append(l.lines, addedLines...)
 	} else {
 		lastIndex := len(l.lines) - 1
-		newLastLine := append(l.lines[lastIndex], addedLines[0]...)
+		lastLine := l.lines[lastIndex]
+		newLastLine := newLogLine(append(lastLine.text, addedLines[0].text...), lastLine.source)
 
 		// We have to be a bit careful here to avoid mutating the original Log struct.
 		newLines = append(l.lines[0:lastIndex], newLastLine)
 		newLines = append(newLines, addedLines[1:]...)
 	}
 
-	return Log{ensureMaxLength(newLines)}
+	kept := ensureMaxLength(newLines)
+	truncatedBytes := l.truncatedBytes
+	truncatedByKind := l.truncatedByKind
+	for _, line := range newLines[:len(newLines)-len(kept)] {
+		truncatedBytes += line.Len()
+		truncatedByKind.add(line)
+	}
+	return Log{lines: kept, truncatedBytes: truncatedBytes, truncatedByKind: truncatedByKind}
 }
 
 type LogEvent interface {
@@ -134,6 +249,19 @@ type LogEvent interface {
 	Time() time.Time
 }
 
+// A LogEvent that knows where it came from. Other LogEvents are from Tilt itself.
+type SourcedLogEvent interface {
+	LogEvent
+	Source() LogSource
+}
+
+func logEventSource(le LogEvent) LogSource {
+	if sle, ok := le.(SourcedLogEvent); ok && sle.Source() != "" {
+		return sle.Source()
+	}
+	return LogSourceTilt
+}
+
 func ensureMaxLength(lines []logLine) []logLine {
 	bytesLeft := maxLogLengthInBytes
 	for i := len(lines) - 1; i >= 0; i-- {
@@ -146,3 +274,32 @@ func ensureMaxLength(lines []logLine) []logLine {
 
 	return lines
 }
+
+// How big a log is, for `tilt dump logstore`.
+type LogStats struct {
+	// Bytes we're holding, and bytes we've dropped off the start to stay
+	// under the limit.
+	Bytes          int `json:"bytes"`
+	TruncatedBytes int `json:"truncatedBytes"`
+
+	Lines         int               `json:"lines"`
+	LinesBySource map[LogSource]int `json:"linesBySource"`
+}
+
+func (l Log) Stats() LogStats {
+	result := LogStats{
+		Bytes:          l.Len(),
+		TruncatedBytes: l.truncatedBytes,
+		Lines:          len(l.lines),
+		LinesBySource:  make(map[LogSource]int),
+	}
+	for _, line := range l.lines {
+		result.LinesBySource[line.source]++
+	}
+	return result
+}
+
+// The most a log holds before we start dropping its oldest lines.
+func MaxLogLength() int {
+	return maxLogLengthInBytes
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Where a log line came from.
+type LogSource string
+
+const (
+	// Tilt itself, including the Kubernetes events that it logs.
+	LogSourceTilt LogSource = "tilt"
+
+	// Building and deploying the resource, or running the Tiltfile.
+	LogSourceBuild LogSource = "build"
+
+	// The running resource: its pods, its Docker Compose container, or its serve_cmd.
+	LogSourceRuntime LogSource = "runtime"
+)
+
+var logSources = [...]LogSource{LogSourceTilt, LogSourceBuild, LogSourceRuntime}
+
+func ParseLogSource(s string) (LogSource, error) {
+	for _, source := range logSources {
+		if string(source) == s {
+			return source, nil
+		}
+	}
+	return "", fmt.Errorf("unknown log source %q (valid sources: tilt, build, runtime)", s)
+}
+
+// How serious a log line is, as best we can tell from its text. Most lines
+// don't say, and count as info.
+type LogLevel int
+
+const (
+	LogLevelInfo LogLevel = iota
+	LogLevelWarn
+	LogLevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+func ParseLogLevel(s string) (LogLevel, error) {
+	for _, level := range []LogLevel{LogLevelInfo, LogLevelWarn, LogLevelError} {
+		if level.String() == s {
+			return level, nil
+		}
+	}
+	return LogLevelInfo, fmt.Errorf("unknown log level %q (valid levels: info, warn, error)", s)
+}
+
+// Which lines of a log to keep. The zero value keeps everything.
+type LogFilter struct {
+	// Empty for every source.
+	Source LogSource
+
+	// Keeps lines at this level and above.
+	MinLevel LogLevel
+}
+
+func (f LogFilter) Empty() bool {
+	return f.Source == "" && f.MinLevel == LogLevelInfo
+}
+
+// Common ways that loggers mark a line's level: an upper-case word anywhere
+// (e.g., "ERROR" or "[WARN]"), a lower-case one at the start of the line,
+// a level=error field, a JSON "level" field, or a klog prefix (e.g., "E0102").
+var (
+	errorLevelRe = regexp.MustCompile(`\b(?:ERROR|FATAL|PANIC)\b|^\W*(?:[Ee]rror|[Ff]atal|panic)\b|level=(?:error|fatal|panic)\b|"level":\s*"(?:error|fatal|panic)"|^E\d{4} `)
+	warnLevelRe  = regexp.MustCompile(`\bWARN(?:ING)?\b|^\W*[Ww]arn(?:ing)?\b|level=warn(?:ing)?\b|"level":\s*"warn(?:ing)?"|^W\d{4} `)
+)
+
+// With timestamps on, lines start with one (see timestampPrefix).
+var timestampPrefixRe = regexp.MustCompile(`^\d{4}/\d\d/\d\d \d\d:\d\d:\d\d `)
+
+func parseLogLevel(line []byte) LogLevel {
+	if loc := timestampPrefixRe.FindIndex(line); loc != nil {
+		line = line[loc[1]:]
+	}
+	switch {
+	case errorLevelRe.Match(line):
+		return LogLevelError
+	case warnLevelRe.Match(line):
+		return LogLevelWarn
+	default:
+		return LogLevelInfo
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -44,6 +45,24 @@ func TestLog_AppendOverLimit(t *testing.T) {
 	assert.Equal(t, s, l.String())
 }
 
+func TestLogSince(t *testing.T) {
+	l := NewLog("hello\n")
+	offset := l.TotalLen()
+	l = AppendLog(l, logEvent{time.Time{}, "world\n"}, false)
+	assert.Equal(t, "world\n", l.Since(offset))
+	assert.Equal(t, "", l.Since(l.TotalLen()))
+
+	// Once the log is too long, we drop lines off the start, but still know what's new.
+	offset = l.TotalLen()
+	s := strings.Repeat("x\n", maxLogLengthInBytes/2)
+	l = AppendLog(l, logEvent{time.Time{}, s}, false)
+	assert.Equal(t, 12+len(s), l.TotalLen())
+	assert.Equal(t, s, l.Since(offset))
+
+	// If we've dropped some of what's new, return what's left.
+	assert.Equal(t, s, l.Since(0))
+}
+
 func TestLog_Timestamps(t *testing.T) {
 	// initial text ends with a newline - we want to ensure that we insert a timestamp when appending right after a newline
 	l := NewLog("hello\n")
@@ -71,3 +90,92 @@ func TestLogTail(t *testing.T) {
 	assert.Equal(t, "1\n2\n3\n4\n5\n", l.Tail(5).String())
 	assert.Equal(t, "1\n2\n3\n4\n5\n", l.Tail(6).String())
 }
+
+func TestLogJSONRoundTrip(t *testing.T) {
+	l := NewLog("hello\nworld\n")
+	b, err := json.Marshal(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Log
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, l.String(), decoded.String())
+}
+
+type sourcedLogEvent struct {
+	logEvent
+	source LogSource
+}
+
+func (l sourcedLogEvent) Source() LogSource {
+	return l.source
+}
+
+func TestLogFilter(t *testing.T) {
+	l := NewLog("Starting\n")
+	l = AppendLog(l, sourcedLogEvent{logEvent{time.Time{}, "Step 1\nERROR: no such file\n"}, LogSourceBuild}, false)
+	l = AppendLog(l, sourcedLogEvent{logEvent{time.Time{}, "listening\nWARNING: slow\n"}, LogSourceRuntime}, false)
+
+	assert.Equal(t, l.String(), l.Filter(LogFilter{}).String())
+	assert.Equal(t, "Step 1\nERROR: no such file\n", l.Filter(LogFilter{Source: LogSourceBuild}).String())
+	assert.Equal(t, "listening\nWARNING: slow\n", l.Filter(LogFilter{Source: LogSourceRuntime}).String())
+	assert.Equal(t, "ERROR: no such file\nWARNING: slow\n", l.Filter(LogFilter{MinLevel: LogLevelWarn}).String())
+	assert.Equal(t, "ERROR: no such file\n", l.Filter(LogFilter{MinLevel: LogLevelError}).String())
+	assert.Equal(t, "", l.Filter(LogFilter{Source: LogSourceRuntime, MinLevel: LogLevelError}).String())
+}
+
+func TestLogFilterContinuedLine(t *testing.T) {
+	// The rest of a line keeps the source of its start.
+	l := AppendLog(Log{}, sourcedLogEvent{logEvent{time.Time{}, "hello "}, LogSourceRuntime}, false)
+	l = AppendLog(l, logEvent{time.Time{}, "world\n"}, false)
+	assert.Equal(t, "hello world\n", l.Filter(LogFilter{Source: LogSourceRuntime}).String())
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for _, tc := range []struct {
+		line     string
+		expected LogLevel
+	}{
+		{"hello", LogLevelInfo},
+		{"no errors found", LogLevelInfo},
+		{"ERROR: no such file", LogLevelError},
+		{"[FATAL] out of memory", LogLevelError},
+		{"error: exit status 1", LogLevelError},
+		{"panic: runtime error", LogLevelError},
+		{`time="2019-01-02" level=error msg="oops"`, LogLevelError},
+		{`{"level": "error", "msg": "oops"}`, LogLevelError},
+		{"E0102 15:04:05.000000 1 main.go:10] oops", LogLevelError},
+		{"2019/03/06 12:34:56 Error: oops", LogLevelError},
+		{"WARNING: slow", LogLevelWarn},
+		{"warn: deprecated", LogLevelWarn},
+		{"level=warning msg=slow", LogLevelWarn},
+		{"W0102 15:04:05.000000 1 main.go:10] slow", LogLevelWarn},
+	} {
+		t.Run(tc.line, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseLogLevel([]byte(tc.line)))
+		})
+	}
+}
+
+func TestLogFilterTruncated(t *testing.T) {
+	l := AppendLog(Log{}, sourcedLogEvent{logEvent{time.Time{}, "ERROR: build failed\n"}, LogSourceBuild}, false)
+	l = AppendLog(l, sourcedLogEvent{logEvent{time.Time{}, "listening\n"}, LogSourceRuntime}, false)
+	filter := LogFilter{Source: LogSourceRuntime}
+	filtered := l.Filter(filter)
+	offset := filtered.TotalLen()
+	assert.Equal(t, len("listening\n"), offset)
+
+	// Push everything so far off the start of the log. Only the runtime
+	// line counts against the filtered log.
+	s := strings.Repeat("x\n", maxLogLengthInBytes/2)
+	l = AppendLog(l, sourcedLogEvent{logEvent{time.Time{}, s}, LogSourceRuntime}, false)
+	filtered = l.Filter(filter)
+	assert.Equal(t, len("listening\n")+len(s), filtered.TotalLen())
+	assert.Equal(t, s, filtered.Since(offset))
+	assert.Equal(t, len("ERROR: build failed\n"), l.Filter(LogFilter{Source: LogSourceBuild}).TotalLen())
+	assert.Equal(t, len("ERROR: build failed\n"), l.Filter(LogFilter{MinLevel: LogLevelError}).TotalLen())
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -33,6 +34,25 @@ type Manifest struct {
 	deployTarget TargetSpec
 
 	UpdateMode UpdateMode
+
+	// The resources that have to be ready before this one deploys.
+	ResourceDependencies []ManifestName
+
+	// If set, Tilt redeploys this resource whenever one of its
+	// ResourceDependencies redeploys.
+	RedeployOnDependencyUpdate bool
+
+	// If set, the resource isn't ready (for its dependents, or in the UX)
+	// until this check passes after each deploy.
+	ReadinessCheck ReadinessCheck
+
+	// How long Tilt waits for the resource's files to stop changing before it
+	// updates the resource, or 0 for the default.
+	FileQuietPeriod time.Duration
+
+	// Groups this resource belongs to in the UIs (see ValidateResourceLabel).
+	// Changing them doesn't rebuild anything.
+	Labels []string
 }
 
 func (m Manifest) ID() TargetID {
@@ -92,6 +112,16 @@ func (m Manifest) IsK8s() bool {
 	return ok
 }
 
+func (m Manifest) LocalTarget() LocalTarget {
+	ret, _ := m.deployTarget.(LocalTarget)
+	return ret
+}
+
+func (m Manifest) IsLocal() bool {
+	_, ok := m.deployTarget.(LocalTarget)
+	return ok
+}
+
 func (m Manifest) IsUnresourcedYAMLManifest() bool {
 	return m.Name == UnresourcedYAMLManifestName
 }
@@ -108,6 +138,9 @@ func (m Manifest) WithDeployTarget(t TargetSpec) Manifest {
 	case DockerComposeTarget:
 		typedTarget.Name = m.Name.TargetName()
 		t = typedTarget
+	case LocalTarget:
+		typedTarget.Name = m.Name.TargetName()
+		t = typedTarget
 	}
 	m.deployTarget = t
 	return m
@@ -137,6 +170,8 @@ func (m Manifest) LocalPaths() []string {
 	switch di := m.deployTarget.(type) {
 	case DockerComposeTarget:
 		return di.LocalPaths()
+	case LocalTarget:
+		return di.Dependencies()
 	default:
 		paths := []string{}
 		for _, iTarget := range m.ImageTargets {
@@ -165,9 +200,122 @@ func (m Manifest) Validate() error {
 		}
 	}
 
+	if err := m.ReadinessCheck.Validate(); err != nil {
+		return fmt.Errorf("[validate] %s: %v", m.Name, err)
+	}
+
+	for _, l := range m.Labels {
+		if err := ValidateResourceLabel(l); err != nil {
+			return fmt.Errorf("[validate] %s: %v", m.Name, err)
+		}
+	}
+
 	return nil
 }
 
+// Checks that every resource only depends on resources that exist, and that
+// no resources depend on each other in a cycle (so none of them would ever deploy).
+//
+// allNames is every resource in the Tiltfile, including the ones that aren't
+// in this session (e.g., `tilt up frontend`). Depending on one of those is fine:
+// it counts as satisfied.
+func ValidateResourceDependencies(manifests []Manifest, allNames []ManifestName) error {
+	byName := make(map[ManifestName]Manifest, len(manifests))
+	exists := make(map[ManifestName]bool, len(allNames)+len(manifests))
+	for _, m := range manifests {
+		byName[m.Name] = m
+		exists[m.Name] = true
+	}
+	for _, n := range allNames {
+		exists[n] = true
+	}
+
+	for _, m := range manifests {
+		for _, dep := range m.ResourceDependencies {
+			if !exists[dep] {
+				return fmt.Errorf("resource %q depends on %q, which doesn't exist", m.Name, dep)
+			}
+		}
+	}
+
+	// Depth-first search, keeping the path we took so that we can show the cycle.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[ManifestName]int, len(manifests))
+	var path []ManifestName
+	var visit func(name ManifestName) error
+	visit = func(name ManifestName) error {
+		switch marks[name] {
+		case visited:
+			return nil
+		case visiting:
+			var cycle []string
+			for i, n := range path {
+				if n == name {
+					for _, n := range path[i:] {
+						cycle = append(cycle, n.String())
+					}
+					break
+				}
+			}
+			cycle = append(cycle, name.String())
+			return fmt.Errorf("resource dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		marks[name] = visiting
+		path = append(path, name)
+		for _, dep := range byName[name].ResourceDependencies {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = visited
+		return nil
+	}
+
+	for _, m := range manifests {
+		if err := visit(m.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Orders the manifests so that every resource comes after the resources it
+// depends on, and otherwise keeps them in the order they came in.
+// Ignores dependencies that aren't in the list, and doesn't loop on cycles.
+func SortByResourceDependencies(manifests []Manifest) []Manifest {
+	byName := make(map[ManifestName]Manifest, len(manifests))
+	for _, m := range manifests {
+		byName[m.Name] = m
+	}
+
+	result := make([]Manifest, 0, len(manifests))
+	seen := make(map[ManifestName]bool, len(manifests))
+	var visit func(m Manifest)
+	visit = func(m Manifest) {
+		if seen[m.Name] {
+			return
+		}
+		seen[m.Name] = true
+		for _, dep := range m.ResourceDependencies {
+			if depManifest, ok := byName[dep]; ok {
+				visit(depManifest)
+			}
+		}
+		result = append(result, m)
+	}
+
+	for _, m := range manifests {
+		visit(m)
+	}
+	return result
+}
+
 func (m1 Manifest) Equal(m2 Manifest) bool {
 	primitivesMatch := m1.Name == m2.Name
 	dockerEqual := DeepEqual(m1.ImageTargets, m2.ImageTargets)
@@ -180,10 +328,13 @@ func (m1 Manifest) Equal(m2 Manifest) bool {
 	k8s2 := m2.K8sTarget()
 	k8sEqual := DeepEqual(k8s1, k8s2)
 
+	localEqual := DeepEqual(m1.LocalTarget(), m2.LocalTarget())
+
 	return primitivesMatch &&
 		dockerEqual &&
 		dockerComposeEqual &&
-		k8sEqual
+		k8sEqual &&
+		localEqual
 }
 
 func (m Manifest) ManifestName() ManifestName {
@@ -326,13 +477,22 @@ type PortForward struct {
 
 	// The port to connect to inside the deployed container.
 	// If 0, we will connect to the first containerPort.
+	//
+	// When forwarding to a Service, this is the port on the Service,
+	// and 0 means the Service's first port.
 	ContainerPort int
+
+	// If set, forward to a ready pod behind this Service, instead of
+	// the resource's most recent pod.
+	Service          string
+	ServiceNamespace string
 }
 
 var imageTargetAllowUnexported = cmp.AllowUnexported(ImageTarget{})
 var dcTargetAllowUnexported = cmp.AllowUnexported(DockerComposeTarget{})
 var labelRequirementAllowUnexported = cmp.AllowUnexported(labels.Requirement{})
 var k8sTargetAllowUnexported = cmp.AllowUnexported(K8sTarget{})
+var localTargetAllowUnexported = cmp.AllowUnexported(LocalTarget{})
 var selectorAllowUnexported = cmp.AllowUnexported(container.RefSelector{})
 
 var dockerRefEqual = cmp.Comparer(func(a, b reference.Named) bool {
@@ -356,6 +516,7 @@ func DeepEqual(x, y interface{}) bool {
 		dcTargetAllowUnexported,
 		labelRequirementAllowUnexported,
 		k8sTargetAllowUnexported,
+		localTargetAllowUnexported,
 		selectorAllowUnexported,
 		dockerRefEqual)
 }
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/labels"
@@ -369,3 +370,69 @@ func TestDCTargetValidate(t *testing.T) {
 		assert.Contains(t, err.Error(), "missing name")
 	}
 }
+
+func TestShouldStreamLogs(t *testing.T) {
+	all := K8sTarget{}
+	assert.True(t, all.ShouldStreamLogs("app"))
+
+	ignored := K8sTarget{}.WithLogContainers(nil, []string{"istio-proxy"})
+	assert.True(t, ignored.ShouldStreamLogs("app"))
+	assert.False(t, ignored.ShouldStreamLogs("istio-proxy"))
+
+	included := K8sTarget{}.WithLogContainers([]string{"app", "migrate"}, []string{"migrate"})
+	assert.True(t, included.ShouldStreamLogs("app"))
+	assert.False(t, included.ShouldStreamLogs("migrate"))
+	assert.False(t, included.ShouldStreamLogs("istio-proxy"))
+}
+
+func TestShouldStreamSidecarLogs(t *testing.T) {
+	assert.False(t, K8sTarget{}.ShouldStreamSidecarLogs("istio-proxy"))
+
+	included := K8sTarget{}.WithLogContainers([]string{"app", "istio-proxy"}, nil)
+	assert.True(t, included.ShouldStreamSidecarLogs("istio-proxy"))
+	assert.False(t, included.ShouldStreamSidecarLogs("linkerd-proxy"))
+}
+
+func TestValidateResourceDependencies(t *testing.T) {
+	a := Manifest{Name: "a"}
+	b := Manifest{Name: "b", ResourceDependencies: []ManifestName{"a"}}
+	c := Manifest{Name: "c", ResourceDependencies: []ManifestName{"a", "b"}}
+	assert.NoError(t, ValidateResourceDependencies([]Manifest{a, b, c}, nil))
+
+	missing := Manifest{Name: "d", ResourceDependencies: []ManifestName{"nope"}}
+	err := ValidateResourceDependencies([]Manifest{a, missing}, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `resource "d" depends on "nope", which doesn't exist`)
+	}
+
+	// A resource in the Tiltfile that isn't in this session still exists.
+	assert.NoError(t, ValidateResourceDependencies([]Manifest{a, missing}, []ManifestName{"a", "d", "nope"}))
+
+	a.ResourceDependencies = []ManifestName{"c"}
+	err = ValidateResourceDependencies([]Manifest{a, b, c}, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "resource dependency cycle: a -> c -> a")
+	}
+}
+
+func TestSortByResourceDependencies(t *testing.T) {
+	app := Manifest{Name: "app", ResourceDependencies: []ManifestName{"db", "cache"}}
+	db := Manifest{Name: "db"}
+	worker := Manifest{Name: "worker", ResourceDependencies: []ManifestName{"app", "gone"}}
+	cache := Manifest{Name: "cache"}
+
+	var names []ManifestName
+	for _, m := range SortByResourceDependencies([]Manifest{worker, app, db, cache}) {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []ManifestName{"db", "cache", "app", "worker"}, names)
+}
+
+func TestValidateResourceLabel(t *testing.T) {
+	for _, l := range []string{"frontend", "infra", "team-a", "v1.2", "a_b", "x"} {
+		assert.NoError(t, ValidateResourceLabel(l), l)
+	}
+	for _, l := range []string{"", "Frontend", "-a", "a-", "has space", "a/b", strings.Repeat("a", 64)} {
+		assert.Error(t, ValidateResourceLabel(l), l)
+	}
+}
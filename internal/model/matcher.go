@@ -12,6 +12,24 @@ type PathMatcher interface {
 	Matches(f string, isDir bool) (bool, error)
 }
 
+// Implemented by PathMatchers that can tell when they match a directory
+// and everything under it, so that directory walks can skip it.
+type EntireDirMatcher interface {
+	MatchesEntireDir(dir string) (bool, error)
+}
+
+// Returns true if m matches dir and everything under it.
+//
+// Matchers that can't tell are assumed not to, so callers
+// fall back to walking the directory.
+func MatchesEntireDir(m PathMatcher, dir string) (bool, error) {
+	em, ok := m.(EntireDirMatcher)
+	if !ok {
+		return false, nil
+	}
+	return em.MatchesEntireDir(dir)
+}
+
 // A Matcher that matches nothing.
 type emptyMatcher struct{}
 
@@ -73,6 +91,10 @@ func (m fileOrChildMatcher) Matches(f string, isDir bool) (bool, error) {
 
 }
 
+func (m fileOrChildMatcher) MatchesEntireDir(dir string) (bool, error) {
+	return m.Matches(dir, true)
+}
+
 // NewRelativeFileOrChildMatcher returns a matcher for the given paths (with any
 // relative paths converted to absolute, relative to the given baseDir).
 func NewRelativeFileOrChildMatcher(baseDir string, paths ...string) fileOrChildMatcher {
@@ -192,6 +214,19 @@ func (c CompositePathMatcher) Matches(f string, isDir bool) (bool, error) {
 	return false, nil
 }
 
+func (c CompositePathMatcher) MatchesEntireDir(dir string) (bool, error) {
+	for _, t := range c.Matchers {
+		ret, err := MatchesEntireDir(t, dir)
+		if err != nil {
+			return false, err
+		}
+		if ret {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 type CompositePatternMatcher struct {
 	CompositePathMatcher
 	Matchers []PatternMatcher
@@ -206,4 +241,5 @@ func (c CompositePatternMatcher) AsMatchPatterns() []string {
 }
 
 var _ PathMatcher = CompositePathMatcher{}
+var _ EntireDirMatcher = CompositePathMatcher{}
 var _ PatternMatcher = CompositePatternMatcher{}
@@ -54,3 +54,28 @@ func TestFileOrChildMatcher(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchesEntireDir(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	matcher := NewCompositeMatcher([]PathMatcher{
+		NewRelativeFileOrChildMatcher(f.Path(), "vendor"),
+		NewGlobMatcher("*.pyc"),
+	})
+
+	expected := map[string]bool{
+		f.JoinPath("vendor"):            true,
+		f.JoinPath("vendor", "foo"):     true,
+		f.JoinPath("src"):               false,
+		f.JoinPath("__pycache__.pyc"):   false,
+		f.JoinPath("src", "vendorless"): false,
+	}
+
+	for dir, e := range expected {
+		match, err := MatchesEntireDir(matcher, dir)
+		if assert.NoError(t, err) {
+			assert.Equal(t, e, match, "expected dir '%s' entire match --> %t", dir, e)
+		}
+	}
+}
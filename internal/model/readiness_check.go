@@ -0,0 +1,88 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const DefaultReadinessCheckPeriod = time.Second
+const DefaultReadinessCheckTimeout = time.Second
+
+// A check that Tilt runs after it deploys a resource, to tell whether the
+// resource can actually serve (which may be long after Kubernetes says its
+// pods are ready). Resources that depend on this one don't deploy until the
+// check passes.
+//
+// Exactly one of HTTPGet, Exec, and LogRegex is set.
+type ReadinessCheck struct {
+	// Passes when a GET of this URL returns a 2xx or 3xx status.
+	HTTPGet string
+
+	// Passes when this command exits 0.
+	Exec Cmd
+
+	// The directory Exec runs in (the Tiltfile's directory, like local_resource commands).
+	Workdir string
+
+	// Passes when the resource's logs since its last deploy match this regexp.
+	LogRegex string
+
+	// How often to run the check until it passes, and how long to wait
+	// for an HTTP or exec check to finish.
+	Period  time.Duration
+	Timeout time.Duration
+}
+
+func (c ReadinessCheck) Empty() bool {
+	return c.HTTPGet == "" && c.Exec.Empty() && c.LogRegex == ""
+}
+
+func (c ReadinessCheck) Validate() error {
+	set := 0
+	if c.HTTPGet != "" {
+		set++
+	}
+	if !c.Exec.Empty() {
+		set++
+	}
+	if c.LogRegex != "" {
+		set++
+		if _, err := regexp.Compile(c.LogRegex); err != nil {
+			return fmt.Errorf("readiness check has an invalid log regex %q: %v", c.LogRegex, err)
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("readiness check must have only one of an HTTP GET, an exec command, or a log regex")
+	}
+	if c.Period < 0 || c.Timeout < 0 {
+		return fmt.Errorf("readiness check period and timeout must not be negative")
+	}
+	return nil
+}
+
+func (c ReadinessCheck) PeriodOrDefault() time.Duration {
+	if c.Period == 0 {
+		return DefaultReadinessCheckPeriod
+	}
+	return c.Period
+}
+
+func (c ReadinessCheck) TimeoutOrDefault() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultReadinessCheckTimeout
+	}
+	return c.Timeout
+}
+
+func (c ReadinessCheck) String() string {
+	switch {
+	case c.HTTPGet != "":
+		return fmt.Sprintf("GET %s", c.HTTPGet)
+	case !c.Exec.Empty():
+		return fmt.Sprintf("exec %s", c.Exec)
+	case c.LogRegex != "":
+		return fmt.Sprintf("log /%s/", c.LogRegex)
+	}
+	return ""
+}
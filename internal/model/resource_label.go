@@ -0,0 +1,24 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Resource labels sort resources into groups in the UIs (e.g., "frontend",
+// "backend", "infra"), and let users filter the resource list by group.
+// They have nothing to do with Kubernetes labels.
+var resourceLabelRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9_.-]*[a-z0-9])?$`)
+
+const maxResourceLabelLen = 63
+
+func ValidateResourceLabel(label string) error {
+	if len(label) > maxResourceLabelLen {
+		return fmt.Errorf("label %q is longer than %d characters", label, maxResourceLabelLen)
+	}
+	if !resourceLabelRegexp.MatchString(label) {
+		return fmt.Errorf("label %q must be lowercase letters, digits, '-', '_', and '.', "+
+			"and start and end with a letter or digit", label)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// How Tilt finds out about file changes.
+type WatchMode string
+
+const (
+	// Ask the OS to tell us about changes (inotify, FSEvents, etc).
+	WatchModeNative WatchMode = "native"
+
+	// Scan the watched files every so often, for file systems where the OS
+	// can't tell us about changes (e.g., NFS mounts and some VM shared folders).
+	WatchModePoll WatchMode = "poll"
+
+	// Subscribe to changes from a running Watchman daemon.
+	// Falls back to WatchModeNative if Watchman isn't available.
+	WatchModeWatchman WatchMode = "watchman"
+)
+
+var AllWatchModes = []WatchMode{WatchModeNative, WatchModePoll, WatchModeWatchman}
+
+// How the polling watcher decides whether a file changed.
+type PollCompare string
+
+const (
+	// Compare each file's modification time and size.
+	PollCompareMtime PollCompare = "mtime"
+
+	// Compare each file's contents. Slower, but works on file systems
+	// where modification times aren't reliable.
+	PollCompareChecksum PollCompare = "checksum"
+)
+
+var AllPollCompares = []PollCompare{PollCompareMtime, PollCompareChecksum}
+
+const DefaultPollInterval = time.Second
+
+// How to watch files, as set by the watch_settings Tiltfile function
+// (or flags that override it).
+type WatchSettings struct {
+	// Empty means WatchModeNative.
+	Mode WatchMode
+
+	// Only used with WatchModePoll. Zero means the defaults.
+	PollInterval time.Duration
+	PollCompare  PollCompare
+
+	// Don't ignore editor temp files and version control internals
+	// (see watch.DefaultIgnores).
+	NoDefaultIgnores bool
+
+	// Hash changed files, and drop the changes that didn't change any bytes
+	// (e.g., from a tool that rewrites files it didn't touch).
+	DedupByChecksum bool
+}
+
+func (s WatchSettings) Empty() bool {
+	return s == WatchSettings{}
+}
+
+// Returns s, with any fields that are set in override replaced.
+func (s WatchSettings) Merge(override WatchSettings) WatchSettings {
+	if override.Mode != "" {
+		s.Mode = override.Mode
+	}
+	if override.PollInterval != 0 {
+		s.PollInterval = override.PollInterval
+	}
+	if override.PollCompare != "" {
+		s.PollCompare = override.PollCompare
+	}
+	if override.NoDefaultIgnores {
+		s.NoDefaultIgnores = true
+	}
+	if override.DedupByChecksum {
+		s.DedupByChecksum = true
+	}
+	return s
+}
+
+func (s WatchSettings) IsPoll() bool {
+	return s.Mode == WatchModePoll
+}
+
+func (s WatchSettings) IsWatchman() bool {
+	return s.Mode == WatchModeWatchman
+}
+
+func (s WatchSettings) PollIntervalOrDefault() time.Duration {
+	if s.PollInterval <= 0 {
+		return DefaultPollInterval
+	}
+	return s.PollInterval
+}
+
+func (s WatchSettings) Validate() error {
+	switch s.Mode {
+	case "", WatchModeNative, WatchModePoll, WatchModeWatchman:
+	default:
+		return fmt.Errorf("Unknown watch mode %q. Must be one of: %v", s.Mode, AllWatchModes)
+	}
+
+	switch s.PollCompare {
+	case "", PollCompareMtime, PollCompareChecksum:
+	default:
+		return fmt.Errorf("Unknown poll comparison %q. Must be one of: %v", s.PollCompare, AllPollCompares)
+	}
+
+	if s.PollInterval < 0 {
+		return fmt.Errorf("Poll interval must not be negative; got %s", s.PollInterval)
+	}
+	return nil
+}
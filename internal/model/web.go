@@ -70,6 +70,9 @@ var _ flag.Value = &emptyWebMode
 var _ pflag.Value = &emptyWebMode
 
 type WebPort int
+
+// The port for the gRPC API in pkg/tiltapi.
+type GRPCPort int
 type WebDevPort int
 type WebURL url.URL
 
@@ -81,3 +84,22 @@ func (u WebURL) String() string {
 func (u WebURL) Empty() bool {
 	return WebURL{} == u
 }
+
+// The interface that the Tilt HTTP server listens on, e.g., "localhost" or "0.0.0.0".
+type WebHost string
+
+// Who may use the Tilt HTTP server, for when it listens on more than localhost.
+type WebAuth struct {
+	// If set, every request needs this token, in an "Authorization: Bearer"
+	// header, a ?token= parameter, or the cookie that the parameter sets.
+	Token string
+
+	// Web pages on these origins (e.g., "https://dash.example.com") may call
+	// the API, as well as the web UI itself. "*" allows any origin.
+	AllowedOrigins []string
+
+	// The interface that the server listens on. Requests have to be addressed
+	// to it or to localhost, so that a web page can't point its own domain
+	// name at 127.0.0.1 and call the API as if it were the web UI.
+	Host WebHost
+}
@@ -3,6 +3,7 @@ package network
 import (
 	"fmt"
 	"net"
+	"strconv"
 )
 
 const Localhost = "localhost"
@@ -17,6 +18,20 @@ func AllHostsBindAddr(port int) string {
 	return fmt.Sprintf(":%d", port)
 }
 
+// An address spec for listening on a port on the given host.
+func BindAddr(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// Whether only this machine can reach a server that listens on the host.
+func IsLoopbackHost(host string) bool {
+	if host == Localhost {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // Checks if no one is listening on the current address.
 func IsBindAddrFree(addr string) error {
 	l, err := bindAddress(addr)
@@ -28,3 +28,12 @@ func TestIsLocalhostFree(t *testing.T) {
 		assert.Contains(t, err.Error(), "bind")
 	}
 }
+
+func TestIsLoopbackHost(t *testing.T) {
+	assert.True(t, IsLoopbackHost("localhost"))
+	assert.True(t, IsLoopbackHost("127.0.0.1"))
+	assert.True(t, IsLoopbackHost("::1"))
+	assert.False(t, IsLoopbackHost("0.0.0.0"))
+	assert.False(t, IsLoopbackHost(""))
+	assert.False(t, IsLoopbackHost("192.168.1.10"))
+}
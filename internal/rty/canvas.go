@@ -15,6 +15,32 @@ type Canvas interface {
 	GetContent(x, y int) (mainc rune, combc []rune, style tcell.Style, width int, err error)
 }
 
+// Where the canvas starts on the screen. Not ok if the canvas isn't
+// on the screen (e.g., it's a temp canvas that we'll copy over later).
+func screenOrigin(c Canvas) (x int, y int, ok bool) {
+	for {
+		switch cc := c.(type) {
+		case *SubCanvas:
+			x, y = x+cc.startX, y+cc.startY
+			c = cc.del
+		case *ScreenCanvas:
+			return x, y, true
+		default:
+			return 0, 0, false
+		}
+	}
+}
+
+// Where something was drawn on the screen, so that we can tell what
+// the user clicked on.
+type screenRect struct {
+	x, y, width, height int
+}
+
+func (r screenRect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.width && y >= r.y && y < r.y+r.height
+}
+
 func totalHeight(canvases []Canvas) int {
 	total := 0
 	for _, c := range canvases {
@@ -27,6 +53,7 @@ func totalHeight(canvases []Canvas) int {
 // Implementations below
 type cell struct {
 	ch    rune
+	combc []rune
 	style tcell.Style
 }
 
@@ -81,7 +108,7 @@ func (c *TempCanvas) SetContent(x int, y int, mainc rune, combc []rune, style tc
 		c.cells = append(c.cells, c.makeRow())
 	}
 
-	c.cells[y][x] = cell{ch: mainc, style: style}
+	c.cells[y][x] = cell{ch: mainc, combc: combc, style: style}
 	return nil
 }
 
@@ -95,7 +122,7 @@ func (c *TempCanvas) GetContent(x, y int) (mainc rune, combc []rune, style tcell
 	}
 
 	cell := c.cells[y][x]
-	return cell.ch, nil, cell.style, 1, nil
+	return cell.ch, cell.combc, cell.style, 1, nil
 }
 
 type SubCanvas struct {
@@ -199,12 +226,15 @@ func (c *SubCanvas) GetContent(x int, y int) (rune, []rune, tcell.Style, int, er
 
 type ScreenCanvas struct {
 	del tcell.Screen
+
+	// The cells that link somewhere. See writeHyperlinks.
+	links map[hyperlinkCoord]string
 }
 
 var _ Canvas = &ScreenCanvas{}
 
 func newScreenCanvas(del tcell.Screen) *ScreenCanvas {
-	return &ScreenCanvas{del: del}
+	return &ScreenCanvas{del: del, links: make(map[hyperlinkCoord]string)}
 }
 
 func (c *ScreenCanvas) Size() (int, int) {
@@ -215,6 +245,12 @@ func (c *ScreenCanvas) SetContent(x int, y int, mainc rune, combc []rune, style
 	if mainc == 0 {
 		mainc = ' '
 	}
+	if url, ok := cellHyperlink(combc); ok {
+		c.links[hyperlinkCoord{x, y}] = url
+		combc = nil
+	} else {
+		delete(c.links, hyperlinkCoord{x, y})
+	}
 	c.del.SetContent(x, y, mainc, combc, style)
 	return nil
 }
@@ -25,6 +25,16 @@ type ElementScroller interface {
 	Top()
 	Bottom()
 	GetSelectedIndex() int
+
+	// Selects the idx'th child of the layout.
+	Select(idx int)
+
+	// Whether the layout covers (x, y) on the screen, as of the last render.
+	Contains(x, y int) bool
+
+	// The index of the child at (x, y) on the screen, as of the last render,
+	// and where (x, y) is within the child. -1 if there's no child there.
+	ChildAt(x, y int) (idx int, childX int, childY int)
 }
 
 type TextScroller interface {
@@ -35,6 +45,15 @@ type TextScroller interface {
 
 	ToggleFollow()
 	SetFollow(following bool)
+
+	// Whether the layout sticks to the bottom as content comes in.
+	Following() bool
+
+	// Scrolls to the start of the idx'th child of the layout.
+	ScrollTo(idx int)
+
+	// Whether the layout covers (x, y) on the screen, as of the last render.
+	Contains(x, y int) bool
 }
 
 // Component renders onto a canvas
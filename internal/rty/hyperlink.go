@@ -0,0 +1,174 @@
+package rty
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell"
+)
+
+// Whether to draw links as OSC 8 hyperlinks, which a lot of terminals
+// let you click on. See https://gist.github.com/egmontkob/eb114294efbcd5adb1944c9f3cb5feda
+var hyperlinks = false
+
+// Turns OSC 8 hyperlinks on or off, for the rest of the process.
+// When they're off, links are plain text.
+func EnableHyperlinks(enabled bool) {
+	hyperlinks = enabled
+}
+
+func HyperlinksEnabled() bool {
+	return hyperlinks
+}
+
+// Guesses whether the terminal supports OSC 8 hyperlinks. Terminals that
+// don't are supposed to ignore them, but some print garbage, so we only
+// turn them on for terminals we know about.
+// FORCE_HYPERLINK=1 (or 0) overrides the guess.
+func TerminalSupportsHyperlinks() bool {
+	if force, ok := os.LookupEnv("FORCE_HYPERLINK"); ok {
+		return force != "" && force != "0"
+	}
+
+	if os.Getenv("TMUX") != "" || strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		// Multiplexers don't pass them through.
+		return false
+	}
+	if os.Getenv("WT_SESSION") != "" || os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("DOMTERM") != "" {
+		return true
+	}
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app":
+		return versionAtLeast(os.Getenv("TERM_PROGRAM_VERSION"), 3, 1)
+	case "WezTerm", "vscode", "Hyper":
+		return true
+	}
+	return false
+}
+
+func versionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	maj, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	if maj != major {
+		return maj > major
+	}
+	if len(parts) < 2 {
+		return false
+	}
+	min, err := strconv.Atoi(parts[1])
+	return err == nil && min >= minor
+}
+
+// tcell (at least the version we use) can't write hyperlinks, so we draw
+// the text of each link with tcell as usual, and then draw it again with
+// the hyperlink escape sequences ourselves.
+//
+// To get the URL to the screen, through all the temp canvases that the text
+// might get copied through, we carry it in the cell's combining runes, after
+// a marker that can't show up in real text.
+const hyperlinkMarker = '\uFDD0' // a noncharacter
+
+func hyperlinkCell(r rune, url string) (mainc rune, combc []rune) {
+	if !hyperlinks || url == "" || r > unicode.MaxASCII || !unicode.IsPrint(r) ||
+		strings.ContainsAny(url, "\x1b\x07") {
+		return r, nil
+	}
+	return r, append([]rune{hyperlinkMarker}, []rune(url)...)
+}
+
+// The URL that the cell links to, if any.
+func cellHyperlink(combc []rune) (url string, ok bool) {
+	if len(combc) == 0 || combc[0] != hyperlinkMarker {
+		return "", false
+	}
+	return string(combc[1:]), true
+}
+
+type hyperlinkCoord struct {
+	x, y int
+}
+
+// Redraws the linked cells of the screen as hyperlinks, after tcell has
+// drawn the screen.
+//
+// DECSC (ESC 7) saves the cursor position and the text attributes, and DECRC
+// (ESC 8) puts them back, so tcell never finds out that we were here.
+func writeHyperlinks(w io.Writer, screen tcell.Screen, links map[hyperlinkCoord]string) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	coords := make([]hyperlinkCoord, 0, len(links))
+	for c := range links {
+		coords = append(coords, c)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].y != coords[j].y {
+			return coords[i].y < coords[j].y
+		}
+		return coords[i].x < coords[j].x
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString("\x1b7")
+	for _, c := range coords {
+		mainc, _, style, _ := screen.GetContent(c.x, c.y)
+		fmt.Fprintf(&buf, "\x1b[%d;%dH%s\x1b]8;;%s\x1b\\%c\x1b]8;;\x1b\\", c.y+1, c.x+1, sgr(style), links[c], mainc)
+	}
+	buf.WriteString("\x1b8")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// The escape sequence that sets the terminal's text attributes to style.
+func sgr(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	params := []string{"0"}
+	if attrs&tcell.AttrBold != 0 {
+		params = append(params, "1")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		params = append(params, "2")
+	}
+	if attrs&tcell.AttrUnderline != 0 {
+		params = append(params, "4")
+	}
+	if attrs&tcell.AttrBlink != 0 {
+		params = append(params, "5")
+	}
+	if attrs&tcell.AttrReverse != 0 {
+		params = append(params, "7")
+	}
+	if p := sgrColor(fg); p != "" {
+		params = append(params, "38;"+p)
+	}
+	if p := sgrColor(bg); p != "" {
+		params = append(params, "48;"+p)
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+func sgrColor(c tcell.Color) string {
+	switch {
+	case c == tcell.ColorDefault:
+		return ""
+	case c&tcell.ColorIsRGB != 0:
+		r, g, b := c.RGB()
+		return fmt.Sprintf("2;%d;%d;%d", r, g, b)
+	default:
+		return fmt.Sprintf("5;%d", c)
+	}
+}
@@ -0,0 +1,103 @@
+package rty
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperlinks(t *testing.T) {
+	defer EnableHyperlinks(false)
+
+	link := NewStringBuilder().Text("a").Link("http://localhost:8000").Text("b").Link("").Text("c").Build()
+
+	// Nothing extra, when hyperlinks are off.
+	out := renderHyperlinks(t, link)
+	assert.Equal(t, "", out)
+
+	EnableHyperlinks(true)
+	out = renderHyperlinks(t, link)
+	assert.Equal(t, "\x1b7\x1b[1;2H\x1b[0m\x1b]8;;http://localhost:8000\x1b\\b\x1b]8;;\x1b\\\x1b8", out)
+}
+
+func TestHyperlinksThroughTempCanvas(t *testing.T) {
+	defer EnableHyperlinks(false)
+	EnableHyperlinks(true)
+
+	sb := NewStringBuilder().Link("file:///tmp/x.go").Fg(tcell.ColorRed).Text("x").Build()
+	l := NewConcatLayout(DirVert)
+	l.Add(NewLine())
+	l.Add(NewBox(sb))
+	out := renderHyperlinks(t, l)
+	assert.Equal(t, "\x1b7\x1b[3;2H\x1b[0;38;5;9m\x1b]8;;file:///tmp/x.go\x1b\\x\x1b]8;;\x1b\\\x1b8", out)
+}
+
+func TestHyperlinkRejectsEscapes(t *testing.T) {
+	defer EnableHyperlinks(false)
+	EnableHyperlinks(true)
+
+	_, combc := hyperlinkCell('x', "http://evil\x1b]0;pwned\x07")
+	assert.Nil(t, combc)
+
+	// Wide characters take up two cells, which we can't link one at a time.
+	_, combc = hyperlinkCell('世', "http://localhost")
+	assert.Nil(t, combc)
+
+	_, combc = hyperlinkCell('x', "http://localhost")
+	url, ok := cellHyperlink(combc)
+	assert.True(t, ok)
+	assert.Equal(t, "http://localhost", url)
+}
+
+func TestTerminalSupportsHyperlinks(t *testing.T) {
+	vars := []string{"FORCE_HYPERLINK", "TMUX", "TERM", "TERM_PROGRAM", "TERM_PROGRAM_VERSION", "VTE_VERSION", "WT_SESSION", "KITTY_WINDOW_ID", "DOMTERM"}
+	for _, v := range vars {
+		if old, ok := os.LookupEnv(v); ok {
+			defer os.Setenv(v, old)
+		} else {
+			defer os.Unsetenv(v)
+		}
+	}
+
+	cases := []struct {
+		env      map[string]string
+		expected bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM_PROGRAM_VERSION": "3.2.9"}, true},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM_PROGRAM_VERSION": "3.0.15"}, false},
+		{map[string]string{"TERM_PROGRAM": "Apple_Terminal"}, false},
+		{map[string]string{"VTE_VERSION": "5202"}, true},
+		{map[string]string{"VTE_VERSION": "4601"}, false},
+		{map[string]string{"VTE_VERSION": "5202", "TMUX": "/tmp/tmux-1000/default,1,0"}, false},
+		{map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0", "FORCE_HYPERLINK": "1"}, true},
+		{map[string]string{"KITTY_WINDOW_ID": "1", "FORCE_HYPERLINK": "0"}, false},
+	}
+	for _, c := range cases {
+		for _, v := range vars {
+			os.Unsetenv(v)
+		}
+		for k, v := range c.env {
+			os.Setenv(k, v)
+		}
+		assert.Equal(t, c.expected, TerminalSupportsHyperlinks(), "%v", c.env)
+	}
+}
+
+func renderHyperlinks(t *testing.T, c Component) string {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(10, 5)
+
+	var out bytes.Buffer
+	if err := NewRTYWithHyperlinks(screen, &out).Render(c); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
@@ -2,6 +2,7 @@ package rty
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/gdamore/tcell"
 )
@@ -13,9 +14,21 @@ func NewRTY(screen tcell.Screen) RTY {
 	}
 }
 
+// Like NewRTY, but draws links as hyperlinks by writing them straight to tty
+// (which should be the terminal that the screen draws on), when hyperlinks
+// are enabled.
+func NewRTYWithHyperlinks(screen tcell.Screen, tty io.Writer) RTY {
+	return &rty{
+		screen: screen,
+		state:  make(renderState),
+		tty:    tty,
+	}
+}
+
 type rty struct {
 	screen tcell.Screen
 	state  renderState
+	tty    io.Writer
 }
 
 type renderState map[string]interface{}
@@ -26,14 +39,18 @@ func (r *rty) Render(c Component) (err error) {
 		prev: r.state,
 		next: make(renderState),
 	}
+	canvas := newScreenCanvas(r.screen)
 	f := renderFrame{
-		canvas:  newScreenCanvas(r.screen),
+		canvas:  canvas,
 		globals: g,
 	}
 
 	f.RenderChild(c)
 	r.screen.Show()
 	r.state = g.next
+	if g.err == nil && r.tty != nil {
+		g.err = writeHyperlinks(r.tty, r.screen, canvas.links)
+	}
 	return g.err
 }
 
@@ -57,7 +74,7 @@ func (r *rty) ElementScroller(name string) ElementScroller {
 func (r *rty) TextScroller(name string) TextScroller {
 	st, ok := r.state[name]
 	if !ok {
-		st = &TextScrollState{}
+		st = defaultTextScrollState()
 		r.state[name] = st
 	}
 
@@ -183,6 +200,20 @@ func (f renderFrame) Embed(src Canvas, srcY int, srcHeight int) error {
 	return nil
 }
 
+// The part of the screen that the writer draws on, if it draws on the screen.
+func writerScreenRect(w Writer) screenRect {
+	f, ok := w.(renderFrame)
+	if !ok {
+		return screenRect{}
+	}
+	x, y, ok := screenOrigin(f.canvas)
+	if !ok {
+		return screenRect{}
+	}
+	width, height := f.canvas.Size()
+	return screenRect{x: x, y: y, width: width, height: height}
+}
+
 func (f renderFrame) RenderStateful(c StatefulComponent, name string) {
 	prev := f.globals.Get(name)
 
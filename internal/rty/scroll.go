@@ -36,6 +36,8 @@ type TextScrollState struct {
 	canvasLengths []int
 
 	following bool
+
+	rect screenRect
 }
 
 func defaultTextScrollState() *TextScrollState {
@@ -55,6 +57,7 @@ func (l *TextScrollLayout) RenderStateful(w Writer, prevState interface{}, width
 		width:     width,
 		height:    height,
 		following: prev.following,
+		rect:      writerScreenRect(w),
 	}
 
 	if len(l.cs) == 0 {
@@ -246,6 +249,20 @@ func (s *TextScrollController) Down() {
 	st.lineIdx = 0
 }
 
+func (s *TextScrollController) ScrollTo(idx int) {
+	st := s.state
+	if idx < 0 || idx >= len(st.canvasLengths) {
+		return
+	}
+	s.SetFollow(false)
+	st.canvasIdx = idx
+	st.lineIdx = 0
+}
+
+func (s *TextScrollController) Contains(x, y int) bool {
+	return s.state.rect.contains(x, y)
+}
+
 func (s *TextScrollController) ToggleFollow() {
 	s.state.following = !s.state.following
 }
@@ -254,6 +271,10 @@ func (s *TextScrollController) SetFollow(follow bool) {
 	s.state.following = follow
 }
 
+func (s *TextScrollController) Following() bool {
+	return s.state.following
+}
+
 func NewScrollingWrappingTextArea(name string, text string) Component {
 	l := NewTextScrollLayout(name)
 	lines := strings.Split(text, "\n")
@@ -291,6 +312,11 @@ type ElementScrollState struct {
 	children []string
 
 	elementIdx int
+
+	// Where the layout and each of its children are on the screen.
+	// Children that aren't visible have empty rects.
+	rect       screenRect
+	childRects []screenRect
 }
 
 func (l *ElementScrollLayout) Render(w Writer, width, height int) error {
@@ -307,6 +333,8 @@ func (l *ElementScrollLayout) RenderStateful(w Writer, prevState interface{}, wi
 	next := *prev
 	next.width = width
 	next.height = height
+	next.rect = writerScreenRect(w)
+	next.childRects = make([]screenRect, len(l.children))
 
 	if len(l.children) == 0 {
 		return &next, nil
@@ -349,6 +377,7 @@ func (l *ElementScrollLayout) RenderStateful(w Writer, prevState interface{}, wi
 			if err != nil {
 				return nil, err
 			}
+			next.childRects[i] = writerScreenRect(w)
 			y += h
 		}
 	}
@@ -469,3 +498,23 @@ func (s *ElementScrollController) Top() {
 func (s *ElementScrollController) Bottom() {
 	s.state.elementIdx = len(s.state.children) - 1
 }
+
+func (s *ElementScrollController) Contains(x, y int) bool {
+	return s.state.rect.contains(x, y)
+}
+
+func (s *ElementScrollController) ChildAt(x, y int) (idx int, childX int, childY int) {
+	for i, r := range s.state.childRects {
+		if r.contains(x, y) {
+			return i, x - r.x, y - r.y
+		}
+	}
+	return -1, 0, 0
+}
+
+func (s *ElementScrollController) Select(idx int) {
+	if idx < 0 || idx >= len(s.state.children) {
+		return
+	}
+	s.state.elementIdx = idx
+}
@@ -39,6 +39,27 @@ func TestElementScroll(t *testing.T) {
 	f.run("jumped to bottom")
 }
 
+func TestElementScrollChildAt(t *testing.T) {
+	f := newElementScrollTestFixture(t)
+
+	// The selected child has an extra line.
+	_, _ = f.i.render(20, 10, f.layout())
+	idx, x, y := f.scroller().ChildAt(2, 5)
+	if idx != 1 || x != 2 || y != 1 {
+		t.Errorf("Expected line 1 of child 1. Actual: child %d at (%d, %d)", idx, x, y)
+	}
+
+	// The scrollbar isn't part of any child.
+	idx, _, _ = f.scroller().ChildAt(19, 5)
+	if idx != -1 {
+		t.Errorf("Expected no child. Actual: %d", idx)
+	}
+
+	if !f.scroller().Contains(19, 5) || f.scroller().Contains(20, 5) {
+		t.Errorf("Expected the layout to cover the whole 20x10 screen")
+	}
+}
+
 func TestElementScrollWrap(t *testing.T) {
 	i := NewInteractiveTester(t, screen)
 
@@ -148,3 +169,35 @@ func (f *elementScrollTestFixture) bottom() {
 	f.i.render(20, 10, f.layout())
 	f.scroller().Bottom()
 }
+
+func TestTextScrollTo(t *testing.T) {
+	st := &TextScrollState{following: true, canvasLengths: []int{1, 3, 1}}
+	ts := &TextScrollController{state: st}
+
+	ts.ScrollTo(1)
+	if st.following || st.canvasIdx != 1 || st.lineIdx != 0 {
+		t.Errorf("Expected to stop following at canvas 1. Actual: %+v", st)
+	}
+
+	// Out of range
+	ts.ScrollTo(3)
+	if st.canvasIdx != 1 {
+		t.Errorf("Expected to stay at canvas 1. Actual: %+v", st)
+	}
+}
+
+func TestElementScrollSelect(t *testing.T) {
+	st := &ElementScrollState{children: []string{"a", "b", "c"}}
+	es := &ElementScrollController{state: st}
+
+	es.Select(2)
+	if es.GetSelectedIndex() != 2 {
+		t.Errorf("Expected to select child 2. Actual: %d", es.GetSelectedIndex())
+	}
+
+	// Out of range
+	es.Select(3)
+	if es.GetSelectedIndex() != 2 {
+		t.Errorf("Expected to stay at child 2. Actual: %d", es.GetSelectedIndex())
+	}
+}
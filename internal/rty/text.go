@@ -13,7 +13,16 @@ type StringBuilder interface {
 	Textf(string, ...interface{}) StringBuilder
 	Fg(tcell.Color) StringBuilder
 	Bg(tcell.Color) StringBuilder
+
+	// Makes the text that follows a link to url, until the next call to Link.
+	// An empty url ends the link.
+	Link(url string) StringBuilder
+
 	Build() Component
+
+	// Builds text that gets cut off at the edge of its container, instead of
+	// wrapping, with the first offset columns of each line scrolled out of view.
+	BuildTruncated(offset int) Component
 }
 
 func NewStringBuilder() StringBuilder {
@@ -27,10 +36,12 @@ type directive interface {
 type textDirective string
 type fgDirective tcell.Color
 type bgDirective tcell.Color
+type linkDirective string
 
 func (textDirective) directive() {}
 func (fgDirective) directive()   {}
 func (bgDirective) directive()   {}
+func (linkDirective) directive() {}
 
 type stringBuilder struct {
 	directives []directive
@@ -58,12 +69,24 @@ func (b *stringBuilder) Bg(c tcell.Color) StringBuilder {
 	return b
 }
 
+func (b *stringBuilder) Link(url string) StringBuilder {
+	b.directives = append(b.directives, linkDirective(url))
+	return b
+}
+
 func (b *stringBuilder) Build() Component {
 	return &StringLayout{directives: b.directives}
 }
 
+func (b *stringBuilder) BuildTruncated(offset int) Component {
+	return &StringLayout{directives: b.directives, truncate: true, offset: offset}
+}
+
 type StringLayout struct {
 	directives []directive
+
+	truncate bool
+	offset   int
 }
 
 var _ Component = &StringLayout{}
@@ -91,8 +114,13 @@ func (l *StringLayout) Render(w Writer, width int, height int) error {
 
 // returns width, height for laying out full string
 func (l *StringLayout) render(w Writer, width int, height int) (int, int, error) {
+	if l.truncate {
+		return l.renderTruncated(w, width, height)
+	}
+
 	nextX, nextY := 0, 0
 	maxWidth := 0
+	link := ""
 	for _, d := range l.directives {
 		var s string
 		switch d := d.(type) {
@@ -108,6 +136,9 @@ func (l *StringLayout) render(w Writer, width int, height int) (int, int, error)
 				w = w.Background(tcell.Color(d))
 			}
 			continue
+		case linkDirective:
+			link = string(d)
+			continue
 		default:
 			return 0, 0, fmt.Errorf("StringLayout.Render: unexpected directive %T %+v", d, d)
 		}
@@ -148,7 +179,8 @@ func (l *StringLayout) render(w Writer, width int, height int) (int, int, error)
 				}
 
 				if w != nil {
-					w.SetContent(nextX, nextY, r, nil)
+					mainc, combc := hyperlinkCell(r, link)
+					w.SetContent(nextX, nextY, mainc, combc)
 				}
 				nextX = nextX + 1
 			}
@@ -156,3 +188,58 @@ func (l *StringLayout) render(w Writer, width int, height int) (int, int, error)
 	}
 	return maxWidth, nextY + 1, nil
 }
+
+// Like render, but each line starts l.offset columns in, and stops at the
+// edge instead of wrapping.
+func (l *StringLayout) renderTruncated(w Writer, width int, height int) (int, int, error) {
+	col, nextY := 0, 0
+	maxWidth := 0
+	link := ""
+	for _, d := range l.directives {
+		var s string
+		switch d := d.(type) {
+		case textDirective:
+			s = string(d)
+		case fgDirective:
+			if w != nil {
+				w = w.Foreground(tcell.Color(d))
+			}
+			continue
+		case bgDirective:
+			if w != nil {
+				w = w.Background(tcell.Color(d))
+			}
+			continue
+		case linkDirective:
+			link = string(d)
+			continue
+		default:
+			return 0, 0, fmt.Errorf("StringLayout.Render: unexpected directive %T %+v", d, d)
+		}
+
+		for _, r := range s {
+			if nextY >= height {
+				return maxWidth, height, nil
+			}
+
+			if r == '\n' {
+				col, nextY = 0, nextY+1
+				continue
+			}
+
+			x := col - l.offset
+			col++
+			if x < 0 || x >= width {
+				continue
+			}
+			if x+1 > maxWidth {
+				maxWidth = x + 1
+			}
+			if w != nil {
+				mainc, combc := hyperlinkCell(r, link)
+				w.SetContent(x, nextY, mainc, combc)
+			}
+		}
+	}
+	return maxWidth, nextY + 1, nil
+}
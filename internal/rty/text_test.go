@@ -21,6 +21,15 @@ func TestTextString(t *testing.T) {
 	i.Run("vertically overflowed via wrap text string", 5, 5, TextString(strings.Repeat("xxxxxxxxxx\n", 200)))
 }
 
+func TestTruncatedText(t *testing.T) {
+	i := NewInteractiveTester(t, screen)
+
+	i.Run("truncated text string", 10, 2, NewStringBuilder().Text("hello world\nhi").BuildTruncated(0))
+	i.Run("truncated text string with offset", 10, 2, NewStringBuilder().Text("hello world\nhi").BuildTruncated(6))
+	c := NewStringBuilder().Text("hello ").Fg(tcell.ColorBlue).Text("wonderful world").BuildTruncated(3)
+	i.Run("truncated multi-color string", 10, 1, c)
+}
+
 func TestStyledText(t *testing.T) {
 	i := NewInteractiveTester(t, screen)
 
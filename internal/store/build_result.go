@@ -29,6 +29,10 @@ type BuildResult struct {
 	// than building a new image. This captures how much the code
 	// running on-pod has diverged from the original image.
 	FilesReplacedSet map[string]bool
+
+	// For k8s targets, the spec that we deployed, so that we can tell
+	// if deploying again would change anything.
+	K8sTarget *model.K8sTarget
 }
 
 // For docker-compose deploys that don't have any built images.
@@ -39,6 +43,13 @@ func NewContainerBuildResult(id model.TargetID, containerID container.ID) BuildR
 	}
 }
 
+// For local targets, which don't produce anything we need to keep track of.
+func NewLocalBuildResult(id model.TargetID) BuildResult {
+	return BuildResult{
+		TargetID: id,
+	}
+}
+
 // For image targets. The container id will be added later.
 func NewImageBuildResult(id model.TargetID, image reference.NamedTagged) BuildResult {
 	return BuildResult{
@@ -47,6 +58,14 @@ func NewImageBuildResult(id model.TargetID, image reference.NamedTagged) BuildRe
 	}
 }
 
+// For k8s targets, so we remember what we deployed.
+func NewK8sDeployResult(kTarget model.K8sTarget) BuildResult {
+	return BuildResult{
+		TargetID:  kTarget.ID(),
+		K8sTarget: &kTarget,
+	}
+}
+
 func (b BuildResult) IsEmpty() bool {
 	return b.TargetID.Empty()
 }
@@ -93,6 +112,21 @@ func (set BuildResultSet) OneAndOnlyContainerID() container.ID {
 	return id
 }
 
+// Whether the build live-updated a container or built a new image.
+func (set BuildResultSet) UpdateType() model.UpdateType {
+	for _, result := range set {
+		if len(result.FilesReplacedSet) > 0 {
+			return model.UpdateTypeLiveUpdate
+		}
+	}
+	for _, result := range set {
+		if result.HasImage() {
+			return model.UpdateTypeImageBuild
+		}
+	}
+	return model.UpdateTypeNone
+}
+
 // The state of the system since the last successful build.
 // This data structure should be considered immutable.
 // All methods that return a new BuildState should first clone the existing build state.
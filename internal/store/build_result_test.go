@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/model"
 )
 
@@ -28,3 +30,15 @@ func TestOneAndOnlyContainerID(t *testing.T) {
 	}
 	assert.Equal(t, "cA", string(set.OneAndOnlyContainerID()))
 }
+
+func TestUpdateType(t *testing.T) {
+	img := container.MustParseNamedTagged("gcr.io/some-project/image:tilt-123")
+
+	assert.Equal(t, model.UpdateTypeNone, BuildResultSet{}.UpdateType())
+
+	set := BuildResultSet{imageID("a"): NewImageBuildResult(imageID("a"), img)}
+	assert.Equal(t, model.UpdateTypeImageBuild, set.UpdateType())
+
+	set[imageID("a")] = set[imageID("a")].ShallowCloneForContainerUpdate(map[string]bool{"main.go": true})
+	assert.Equal(t, model.UpdateTypeLiveUpdate, set.UpdateType())
+}
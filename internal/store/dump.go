@@ -0,0 +1,378 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// The engine state, for `tilt dump engine`, so that we can see what the state
+// machine was thinking when a user hits a bug.
+//
+// Unlike the Snapshot, it's meant to show the engine's bookkeeping (build
+// counts, queues, pending changes, last results), not to be read back in.
+// Logs are left out (`tilt dump logstore` says how big they are), and the
+// values of Secrets are redacted, because users paste these into bug reports.
+//
+// NewEngineStateDump copies everything it needs out of the state, so that
+// callers can marshal it after they release the state lock.
+type EngineStateDump struct {
+	TiltBuild     model.TiltBuild `json:"tiltBuild"`
+	TiltStartTime time.Time       `json:"tiltStartTime"`
+	TiltfilePath  string          `json:"tiltfilePath"`
+	ConfigFiles   []string        `json:"configFiles"`
+
+	TriggerMode  model.TriggerMode    `json:"triggerMode"`
+	TriggerQueue []model.ManifestName `json:"triggerQueue"`
+	WatchFiles   bool                 `json:"watchFiles"`
+
+	// The manifests that are building right now, out of at most MaxParallelUpdates.
+	CurrentlyBuilding  []model.ManifestName `json:"currentlyBuilding"`
+	MaxParallelUpdates int                  `json:"maxParallelUpdates"`
+
+	InitialBuildsQueued int `json:"initialBuildsQueued"`
+	StartedBuildCount   int `json:"startedBuildCount"`
+	CompletedBuildCount int `json:"completedBuildCount"`
+
+	PermanentError   string `json:"permanentError,omitempty"`
+	KubeContextAlert string `json:"kubeContextAlert,omitempty"`
+	UpdatesPaused    bool   `json:"updatesPaused"`
+	UserExited       bool   `json:"userExited"`
+
+	PendingConfigFileChanges map[string]time.Time `json:"pendingConfigFileChanges"`
+	PendingRestarts          []model.ManifestName `json:"pendingRestarts"`
+	PendingTearDowns         []model.ManifestName `json:"pendingTearDowns"`
+	TornDownManifests        []model.ManifestName `json:"tornDownManifests"`
+
+	FirstTiltfileBuildCompleted bool            `json:"firstTiltfileBuildCompleted"`
+	CurrentTiltfileBuild        buildRecordDump `json:"currentTiltfileBuild"`
+	LastTiltfileBuild           buildRecordDump `json:"lastTiltfileBuild"`
+
+	// In the order they're defined in the Tiltfile.
+	Manifests []manifestDump `json:"manifests"`
+}
+
+type manifestDump struct {
+	Name                 model.ManifestName   `json:"name"`
+	UpdateMode           model.UpdateMode     `json:"updateMode"`
+	ResourceDependencies []model.ManifestName `json:"resourceDependencies"`
+
+	// Set for k8s resources. Secrets have their values redacted.
+	K8sYAML         string         `json:"k8sYAML,omitempty"`
+	LastAppliedYAML string         `json:"lastAppliedYAML,omitempty"`
+	Drift           string         `json:"drift,omitempty"`
+	DeployID        model.DeployID `json:"deployID"`
+
+	// Keyed by target ID.
+	BuildStatuses map[string]buildStatusDump `json:"buildStatuses"`
+
+	PendingManifestChange    time.Time `json:"pendingManifestChange"`
+	LastSuccessfulDeployTime time.Time `json:"lastSuccessfulDeployTime"`
+
+	CurrentBuild         buildRecordDump   `json:"currentBuild"`
+	BuildHistory         []buildRecordDump `json:"buildHistory"`
+	BuildErrorCleared    bool              `json:"buildErrorCleared"`
+	CancelBuildRequested bool              `json:"cancelBuildRequested"`
+
+	Disabled      bool `json:"disabled"`
+	UpdatesPaused bool `json:"updatesPaused"`
+
+	ReadinessCheckPassed bool   `json:"readinessCheckPassed"`
+	ReadinessCheckError  string `json:"readinessCheckError,omitempty"`
+
+	ExpectedContainerID   string    `json:"expectedContainerID,omitempty"`
+	NeedsRebuildFromCrash bool      `json:"needsRebuildFromCrash"`
+	CrashRebuildCount     int       `json:"crashRebuildCount"`
+	CrashBackoffUntil     time.Time `json:"crashBackoffUntil"`
+
+	Pods        []podDump `json:"pods"`
+	IngressURLs []string  `json:"ingressURLs"`
+
+	// Set for docker-compose resources.
+	DockerComposeStatus string `json:"dockerComposeStatus,omitempty"`
+}
+
+type buildStatusDump struct {
+	PendingFileChanges   map[string]time.Time `json:"pendingFileChanges"`
+	LastSuccessfulResult buildResultDump      `json:"lastSuccessfulResult"`
+}
+
+type buildResultDump struct {
+	Image         string   `json:"image,omitempty"`
+	ContainerID   string   `json:"containerID,omitempty"`
+	FilesReplaced []string `json:"filesReplaced,omitempty"`
+}
+
+type buildRecordDump struct {
+	StartTime      time.Time         `json:"startTime"`
+	FinishTime     time.Time         `json:"finishTime"`
+	Reason         model.BuildReason `json:"reason"`
+	Edits          []string          `json:"edits"`
+	Error          string            `json:"error,omitempty"`
+	Canceled       bool              `json:"canceled,omitempty"`
+	Warnings       []string          `json:"warnings"`
+	UpdateType     model.UpdateType  `json:"updateType,omitempty"`
+	FallbackReason string            `json:"fallbackReason,omitempty"`
+}
+
+type podDump struct {
+	PodID             k8s.PodID     `json:"podID"`
+	Namespace         k8s.Namespace `json:"namespace"`
+	StartedAt         time.Time     `json:"startedAt"`
+	Status            string        `json:"status"`
+	Phase             string        `json:"phase"`
+	Ready             bool          `json:"ready"`
+	Deleting          bool          `json:"deleting"`
+	UpdateStartTime   time.Time     `json:"updateStartTime"`
+	ContainerName     string        `json:"containerName,omitempty"`
+	ContainerID       string        `json:"containerID,omitempty"`
+	ContainerImageRef string        `json:"containerImageRef,omitempty"`
+	ContainerRestarts int           `json:"containerRestarts"`
+	OldRestarts       int           `json:"oldRestarts"`
+	Alerts            []string      `json:"alerts"`
+}
+
+func NewEngineStateDump(state EngineState) EngineStateDump {
+	r := newSecretRedactor(state)
+	result := EngineStateDump{
+		TiltBuild:                   state.TiltBuildInfo,
+		TiltStartTime:               state.TiltStartTime,
+		TiltfilePath:                state.TiltfilePath,
+		ConfigFiles:                 append([]string{}, state.ConfigFiles...),
+		TriggerMode:                 state.TriggerMode,
+		TriggerQueue:                append([]model.ManifestName{}, state.TriggerQueue...),
+		WatchFiles:                  state.WatchFiles,
+		CurrentlyBuilding:           sortedManifestNames(state.CurrentlyBuilding),
+		MaxParallelUpdates:          state.MaxParallelUpdates,
+		InitialBuildsQueued:         state.InitialBuildsQueued,
+		StartedBuildCount:           state.StartedBuildCount,
+		CompletedBuildCount:         state.CompletedBuildCount,
+		KubeContextAlert:            state.KubeContextAlert,
+		UpdatesPaused:               state.UpdatesPaused,
+		UserExited:                  state.UserExited,
+		PendingConfigFileChanges:    make(map[string]time.Time, len(state.PendingConfigFileChanges)),
+		PendingRestarts:             append([]model.ManifestName{}, state.PendingRestarts...),
+		PendingTearDowns:            []model.ManifestName{},
+		TornDownManifests:           sortedManifestNames(state.TornDownManifests),
+		FirstTiltfileBuildCompleted: state.FirstTiltfileBuildCompleted,
+		CurrentTiltfileBuild:        newBuildRecordDump(state.CurrentTiltfileBuild, r),
+		LastTiltfileBuild:           newBuildRecordDump(state.LastTiltfileBuild, r),
+		Manifests:                   []manifestDump{},
+	}
+	if state.PermanentError != nil {
+		result.PermanentError = r.redact(state.PermanentError.Error())
+	}
+	for f, t := range state.PendingConfigFileChanges {
+		result.PendingConfigFileChanges[f] = t
+	}
+	for _, m := range state.PendingTearDowns {
+		result.PendingTearDowns = append(result.PendingTearDowns, m.Name)
+	}
+	for _, mt := range state.Targets() {
+		result.Manifests = append(result.Manifests, newManifestDump(mt, r))
+	}
+	return result
+}
+
+func newManifestDump(mt *ManifestTarget, r secretRedactor) manifestDump {
+	m := mt.Manifest
+	ms := mt.State
+	result := manifestDump{
+		Name:                     m.Name,
+		UpdateMode:               m.UpdateMode,
+		ResourceDependencies:     append([]model.ManifestName{}, m.ResourceDependencies...),
+		Drift:                    r.redact(ms.Drift),
+		DeployID:                 ms.DeployID,
+		BuildStatuses:            make(map[string]buildStatusDump, len(ms.BuildStatuses)),
+		PendingManifestChange:    ms.PendingManifestChange,
+		LastSuccessfulDeployTime: ms.LastSuccessfulDeployTime,
+		CurrentBuild:             newBuildRecordDump(ms.CurrentBuild, r),
+		BuildHistory:             []buildRecordDump{},
+		BuildErrorCleared:        ms.BuildErrorCleared,
+		CancelBuildRequested:     ms.CancelBuildRequested,
+		Disabled:                 ms.Disabled,
+		UpdatesPaused:            ms.UpdatesPaused,
+		ReadinessCheckPassed:     ms.ReadinessCheckPassed,
+		ReadinessCheckError:      r.redact(ms.ReadinessCheckError),
+		ExpectedContainerID:      ms.ExpectedContainerID.String(),
+		NeedsRebuildFromCrash:    ms.NeedsRebuildFromCrash,
+		CrashRebuildCount:        ms.CrashRebuildCount,
+		CrashBackoffUntil:        ms.CrashBackoffUntil,
+		Pods:                     []podDump{},
+		IngressURLs:              []string{},
+	}
+
+	if m.IsK8s() {
+		result.K8sYAML = k8s.RedactSecretsInYAML(m.K8sTarget().YAML)
+		if ms.LastAppliedYAML != "" {
+			result.LastAppliedYAML = k8s.RedactSecretsInYAML(ms.LastAppliedYAML)
+		}
+	}
+	if m.IsDC() {
+		result.DockerComposeStatus = string(ms.DCResourceState().Status)
+	}
+
+	for id, bs := range ms.BuildStatuses {
+		result.BuildStatuses[id.String()] = newBuildStatusDump(bs)
+	}
+	for _, br := range ms.BuildHistory {
+		result.BuildHistory = append(result.BuildHistory, newBuildRecordDump(br, r))
+	}
+	for _, pod := range ms.PodSet.PodList() {
+		result.Pods = append(result.Pods, newPodDump(pod))
+	}
+	for _, u := range ms.IngressURLs {
+		result.IngressURLs = append(result.IngressURLs, u.String())
+	}
+	return result
+}
+
+func newBuildStatusDump(bs *BuildStatus) buildStatusDump {
+	result := buildStatusDump{
+		PendingFileChanges: make(map[string]time.Time, len(bs.PendingFileChanges)),
+	}
+	for f, t := range bs.PendingFileChanges {
+		result.PendingFileChanges[f] = t
+	}
+
+	res := bs.LastSuccessfulResult
+	if res.Image != nil {
+		result.LastSuccessfulResult.Image = res.Image.String()
+	}
+	result.LastSuccessfulResult.ContainerID = res.ContainerID.String()
+	for f := range res.FilesReplacedSet {
+		result.LastSuccessfulResult.FilesReplaced = append(result.LastSuccessfulResult.FilesReplaced, f)
+	}
+	sort.Strings(result.LastSuccessfulResult.FilesReplaced)
+	return result
+}
+
+func newBuildRecordDump(br model.BuildRecord, r secretRedactor) buildRecordDump {
+	result := buildRecordDump{
+		StartTime:      br.StartTime,
+		FinishTime:     br.FinishTime,
+		Reason:         br.Reason,
+		Edits:          append([]string{}, br.Edits...),
+		Warnings:       []string{},
+		UpdateType:     br.UpdateType,
+		FallbackReason: r.redact(br.FallbackReason),
+		Canceled:       br.Canceled,
+	}
+	if br.Error != nil {
+		result.Error = r.redact(br.Error.Error())
+	}
+	for _, w := range br.Warnings {
+		result.Warnings = append(result.Warnings, r.redact(w))
+	}
+	return result
+}
+
+func newPodDump(pod Pod) podDump {
+	result := podDump{
+		PodID:             pod.PodID,
+		Namespace:         pod.Namespace,
+		StartedAt:         pod.StartedAt,
+		Status:            pod.Status,
+		Phase:             string(pod.Phase),
+		Ready:             pod.Ready,
+		Deleting:          pod.Deleting,
+		UpdateStartTime:   pod.UpdateStartTime,
+		ContainerName:     pod.ContainerName.String(),
+		ContainerID:       pod.ContainerID.String(),
+		ContainerRestarts: pod.ContainerRestarts,
+		OldRestarts:       pod.OldRestarts,
+		Alerts:            append([]string{}, pod.Alerts()...),
+	}
+	if pod.ContainerImageRef != nil {
+		result.ContainerImageRef = pod.ContainerImageRef.String()
+	}
+	return result
+}
+
+func sortedManifestNames(set map[model.ManifestName]bool) []model.ManifestName {
+	result := []model.ManifestName{}
+	for mn, ok := range set {
+		if ok {
+			result = append(result, mn)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// Which image refs we inject into each resource's YAML, in place of the
+// image names in the Tiltfile, for `tilt dump images`.
+type ImageMapEntry struct {
+	Manifest model.ManifestName `json:"manifest"`
+	TargetID model.TargetID     `json:"targetID"`
+
+	// The image name in the Tiltfile, and the image refs it matches.
+	ConfigurationRef string `json:"configurationRef"`
+
+	// The image we last built for it, which replaces the matching refs.
+	// Empty if we haven't built it yet.
+	Image string `json:"image,omitempty"`
+}
+
+func ImageMap(state EngineState) []ImageMapEntry {
+	result := []ImageMapEntry{}
+	for _, mt := range state.Targets() {
+		for _, iTarget := range mt.Manifest.ImageTargets {
+			entry := ImageMapEntry{
+				Manifest:         mt.Manifest.Name,
+				TargetID:         iTarget.ID(),
+				ConfigurationRef: iTarget.ConfigurationRef.String(),
+			}
+			if bs, ok := mt.State.BuildStatuses[iTarget.ID()]; ok && bs.LastSuccessfulResult.Image != nil {
+				entry.Image = bs.LastSuccessfulResult.Image.String()
+			}
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// How much log Tilt is holding, for `tilt dump logstore`.
+type LogStoreStats struct {
+	// Each log holds at most this many bytes.
+	MaxBytesPerLog int `json:"maxBytesPerLog"`
+
+	// Across all the logs.
+	TotalBytes int `json:"totalBytes"`
+
+	Logs []LogStoreEntry `json:"logs"`
+}
+
+type LogStoreEntry struct {
+	// "global" (the log the HUD shows for all resources), "tiltfile",
+	// "combined" (one resource's log), or "crash" (one resource's log
+	// from before it last crashed).
+	Kind     string             `json:"kind"`
+	Manifest model.ManifestName `json:"manifest,omitempty"`
+
+	model.LogStats
+}
+
+func NewLogStoreStats(state EngineState) LogStoreStats {
+	result := LogStoreStats{
+		MaxBytesPerLog: model.MaxLogLength(),
+		Logs:           []LogStoreEntry{},
+	}
+	add := func(kind string, name model.ManifestName, log model.Log) {
+		stats := log.Stats()
+		result.TotalBytes += stats.Bytes
+		result.Logs = append(result.Logs, LogStoreEntry{Kind: kind, Manifest: name, LogStats: stats})
+	}
+
+	add("global", "", state.Log)
+	add("tiltfile", "", state.TiltfileCombinedLog)
+	for _, mt := range state.Targets() {
+		add("combined", mt.Manifest.Name, mt.State.CombinedLog)
+		if !mt.State.CrashLog.Empty() {
+			add("crash", mt.Manifest.Name, mt.State.CrashLog)
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func TestEngineStateDump(t *testing.T) {
+	iTarget := model.NewImageTarget(container.MustParseSelector("gcr.io/foo"))
+	m := model.Manifest{Name: "foo"}.WithImageTarget(iTarget)
+	state := newState([]model.Manifest{m})
+	state.PermanentError = fmt.Errorf("the cluster went away")
+	ms := state.ManifestTargets["foo"].State
+	ms.BuildHistory = []model.BuildRecord{{Error: fmt.Errorf("exit status 1")}}
+	ms.MutableBuildStatus(iTarget.ID()).LastSuccessfulResult =
+		NewImageBuildResult(iTarget.ID(), container.MustParseNamedTagged("gcr.io/foo:tilt-123"))
+
+	b, err := json.Marshal(NewEngineStateDump(*state))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		PermanentError string
+		Manifests      []struct {
+			Name         string
+			BuildHistory []struct {
+				Error string
+			}
+			BuildStatuses map[string]struct {
+				LastSuccessfulResult struct {
+					Image string
+				}
+			}
+		}
+	}
+	err = json.Unmarshal(b, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "the cluster went away", out.PermanentError)
+	if assert.Equal(t, 1, len(out.Manifests)) {
+		foo := out.Manifests[0]
+		assert.Equal(t, "foo", foo.Name)
+		assert.Equal(t, "exit status 1", foo.BuildHistory[0].Error)
+		assert.Equal(t, "gcr.io/foo:tilt-123",
+			foo.BuildStatuses[iTarget.ID().String()].LastSuccessfulResult.Image)
+	}
+}
+
+func TestEngineStateDumpRedactsSecrets(t *testing.T) {
+	m := model.Manifest{Name: "foo"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.SecretYaml})
+	state := newState([]model.Manifest{m})
+	state.ManifestTargets["foo"].State.BuildHistory = []model.BuildRecord{
+		{Error: fmt.Errorf("bad password: 1f2d1e2e67df")},
+	}
+
+	b, err := json.Marshal(NewEngineStateDump(*state))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, string(b), "MWYyZDFlMmU2N2Rm")
+	assert.NotContains(t, string(b), "1f2d1e2e67df")
+	assert.Contains(t, string(b), "bad password: [redacted secret]")
+}
+
+func TestImageMap(t *testing.T) {
+	built := model.NewImageTarget(container.MustParseSelector("gcr.io/foo"))
+	unbuilt := model.NewImageTarget(container.MustParseSelector("gcr.io/bar"))
+	m := model.Manifest{Name: "foo"}.WithImageTargets([]model.ImageTarget{built, unbuilt})
+	state := newState([]model.Manifest{m})
+	state.ManifestTargets["foo"].State.MutableBuildStatus(built.ID()).LastSuccessfulResult =
+		NewImageBuildResult(built.ID(), container.MustParseNamedTagged("gcr.io/foo:tilt-123"))
+
+	assert.Equal(t, []ImageMapEntry{
+		{Manifest: "foo", TargetID: built.ID(), ConfigurationRef: "gcr.io/foo", Image: "gcr.io/foo:tilt-123"},
+		{Manifest: "foo", TargetID: unbuilt.ID(), ConfigurationRef: "gcr.io/bar"},
+	}, ImageMap(*state))
+}
+
+func TestLogStoreStats(t *testing.T) {
+	state := newState([]model.Manifest{{Name: "foo"}})
+	state.Log = model.NewLog("hello\n")
+	state.ManifestTargets["foo"].State.CombinedLog = model.NewLog("a\nb\n")
+
+	stats := NewLogStoreStats(*state)
+	assert.Equal(t, 10, stats.TotalBytes)
+	if assert.Equal(t, 3, len(stats.Logs)) {
+		assert.Equal(t, "global", stats.Logs[0].Kind)
+		assert.Equal(t, 6, stats.Logs[0].Bytes)
+		assert.Equal(t, model.ManifestName("foo"), stats.Logs[2].Manifest)
+		assert.Equal(t, 2, stats.Logs[2].Lines)
+		assert.Equal(t, 2, stats.Logs[2].LinesBySource[model.LogSourceTilt])
+	}
+}
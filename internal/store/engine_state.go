@@ -30,8 +30,21 @@ type EngineState struct {
 	// TODO(nick): This will eventually be a general Target index.
 	ManifestTargets map[model.ManifestName]*ManifestTarget
 
-	CurrentlyBuilding model.ManifestName
-	WatchFiles        bool
+	// The manifests that are building right now. Up to MaxParallelUpdates
+	// of them can build at once (or DefaultMaxParallelUpdates, if it's unset).
+	CurrentlyBuilding  map[model.ManifestName]bool
+	MaxParallelUpdates int
+	WatchFiles         bool
+
+	// Where to send build and deploy events, from the Tiltfile's event_sink calls.
+	EventSinks []model.EventSink
+
+	// How to watch files, from the Tiltfile's watch_settings call.
+	WatchSettings model.WatchSettings
+
+	// Every resource in the Tiltfile, including the ones that
+	// `tilt up <names>` didn't ask for.
+	TiltfileManifestNames []model.ManifestName
 
 	// How many builds were queued on startup (i.e., how many manifests there were
 	// after initial Tiltfile load)
@@ -40,14 +53,20 @@ type EngineState struct {
 	// How many builds have been completed (pass or fail) since starting tilt
 	CompletedBuildCount int
 
-	// For synchronizing BuildController so that it's only
-	// doing one action at a time. In the future, we might
-	// want to allow it to parallelize builds better, but that
-	// would require better tools for triaging output to different streams.
-	BuildControllerActionCount int
+	// How many builds have started since starting tilt. The BuildController
+	// doesn't start another build until the last one it started shows up here,
+	// so that it doesn't accidentally start the same build twice.
+	StartedBuildCount int
 
 	PermanentError error
 
+	// Set when the kubeconfig points to a different context or namespace than
+	// the one Tilt started with. We don't deploy to k8s while it's set.
+	KubeContextAlert string
+
+	// The Ingresses that Tilt deployed, by namespace/name.
+	Ingresses map[string]Ingress
+
 	// The user has indicated they want to exit
 	UserExited bool
 
@@ -65,9 +84,28 @@ type EngineState struct {
 	TriggerMode  model.TriggerMode
 	TriggerQueue []model.ManifestName
 
+	// The user paused updates for the whole session (e.g., during a big rebase).
+	// We keep collecting file changes, but don't build them or reload the
+	// Tiltfile until they resume.
+	UpdatesPaused bool
+
 	LogTimestamps bool
 	IsProfiling   bool
 
+	// Whether the user asked us to delete the objects from resources
+	// that aren't in the Tiltfile anymore.
+	DeleteOrphansRequested bool
+
+	// Resources that the user tore down during this session, which we leave
+	// out of the session, even when the Tiltfile reloads.
+	TornDownManifests map[model.ManifestName]bool
+
+	// Resources that we've taken out of the session, but haven't deleted the objects of yet.
+	PendingTearDowns []model.Manifest
+
+	// Resources whose pods (or Docker Compose container) the user asked us to restart.
+	PendingRestarts []model.ManifestName
+
 	LastTiltfileBuild    model.BuildRecord
 	CurrentTiltfileBuild model.BuildRecord
 	TiltfileCombinedLog  model.Log
@@ -93,6 +131,9 @@ func (e *EngineState) ManifestNamesForTargetID(id model.TargetID) []model.Manife
 		if manifest.DockerComposeTarget().ID() == id {
 			result = append(result, mn)
 		}
+		if manifest.LocalTarget().ID() == id {
+			result = append(result, mn)
+		}
 	}
 	return result
 }
@@ -134,6 +175,30 @@ func (e EngineState) ManifestState(mn model.ManifestName) (*ManifestState, bool)
 	return m.State, ok
 }
 
+// Whether the manifest builds as soon as its files change, or waits for the
+// user to trigger it. Either the whole session or just the manifest can be manual.
+func (e EngineState) TriggerModeForManifest(m model.Manifest) model.TriggerMode {
+	if e.TriggerMode == model.TriggerManual || m.UpdateMode == model.UpdateModeManual {
+		return model.TriggerManual
+	}
+	return model.TriggerAuto
+}
+
+// The resources that the manifest depends on that aren't ready yet,
+// so it can't deploy. Ignores dependencies that aren't in the session
+// anymore (e.g., because the user tore them down), and ones that the user
+// turned off, since they won't become ready until the user turns them back on.
+func (e EngineState) UnreadyDependencies(m model.Manifest) []model.ManifestName {
+	var result []model.ManifestName
+	for _, dep := range m.ResourceDependencies {
+		mt, ok := e.ManifestTargets[dep]
+		if ok && !mt.State.Disabled && !mt.IsReady() {
+			result = append(result, dep)
+		}
+	}
+	return result
+}
+
 // Returns Manifests in a stable order
 func (e EngineState) Manifests() []model.Manifest {
 	result := make([]model.Manifest, 0, len(e.ManifestTargets))
@@ -160,6 +225,16 @@ func (e EngineState) ManifestStates() []*ManifestState {
 	return result
 }
 
+// Whether file changes to the named resource should wait, because the user
+// paused updates for it or for the whole session.
+func (e EngineState) UpdatesPausedForManifest(name model.ManifestName) bool {
+	if e.UpdatesPaused {
+		return true
+	}
+	mt, ok := e.ManifestTargets[name]
+	return ok && mt.State.UpdatesPaused
+}
+
 // Returns ManifestTargets in a stable order
 func (e EngineState) Targets() []*ManifestTarget {
 	result := make([]*ManifestTarget, 0, len(e.ManifestTargets))
@@ -173,6 +248,28 @@ func (e EngineState) Targets() []*ManifestTarget {
 	return result
 }
 
+// Like Targets, but without the resources that the user turned off.
+func (e EngineState) EnabledTargets() []*ManifestTarget {
+	result := make([]*ManifestTarget, 0, len(e.ManifestTargets))
+	for _, mt := range e.Targets() {
+		if mt.State.Disabled {
+			continue
+		}
+		result = append(result, mt)
+	}
+	return result
+}
+
+// Like Manifests, but without the resources that the user turned off.
+func (e EngineState) EnabledManifests() []model.Manifest {
+	targets := e.EnabledTargets()
+	result := make([]model.Manifest, 0, len(targets))
+	for _, mt := range targets {
+		result = append(result, mt.Manifest)
+	}
+	return result
+}
+
 func (e EngineState) RelativeTiltfilePath() (string, error) {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -217,9 +314,16 @@ type ManifestState struct {
 	Name model.ManifestName
 
 	// k8s-specific state
-	PodSet   PodSet
-	LBs      map[k8s.ServiceName]*url.URL
-	DeployID model.DeployID // ID we have assigned to the current deploy (helps find expected k8s objects)
+	PodSet      PodSet
+	LBs         map[k8s.ServiceName]*url.URL
+	ReplicaSets map[string]ReplicaSet
+
+	// The Services that the resource deployed, by namespace/name.
+	Services map[string]bool
+
+	// The URLs of the Ingress rules that route to the resource's Services.
+	IngressURLs []*url.URL
+	DeployID    model.DeployID // ID we have assigned to the current deploy (helps find expected k8s objects)
 
 	BuildStatuses map[model.TargetID]*BuildStatus
 
@@ -231,16 +335,42 @@ type ManifestState struct {
 	// The current build
 	CurrentBuild model.BuildRecord
 
+	// The user asked us to cancel the current build.
+	CancelBuildRequested bool
+
+	// The user turned this resource off for now. We don't watch its files,
+	// build it, port-forward to it, or stream its logs until they turn it back on.
+	Disabled bool
+
+	// The user paused updates for this resource. File changes pile up in
+	// BuildStatuses until they resume, and then we build them all at once.
+	UpdatesPaused bool
+
 	LastSuccessfulDeployTime time.Time
 
+	// Whether the manifest's readiness check has passed since its last deploy,
+	// and why it failed the last time we ran it, if it hasn't.
+	ReadinessCheckPassed bool
+	ReadinessCheckError  string
+
 	// The last `BuildHistoryLimit` builds. The most recent build is first in the slice.
 	BuildHistory []model.BuildRecord
 
+	// The user cleared the error from the last build, so we don't show it
+	// until the next build finishes.
+	BuildErrorCleared bool
+
 	// If the pod isn't running this container then it's possible we're running stale code
 	ExpectedContainerID container.ID
 	// We detected stale code and are currently doing an image build
 	NeedsRebuildFromCrash bool
 
+	// How many crash rebuilds in a row we've done without any new changes,
+	// and when we'll do the next one, if we're backing off because the
+	// resource keeps crashing.
+	CrashRebuildCount int
+	CrashBackoffUntil time.Time
+
 	// If a pod had to be killed because it was crashing, we keep the old log
 	// around for a little while so we can show it in the UX.
 	CrashLog model.Log
@@ -250,6 +380,15 @@ type ManifestState struct {
 
 	// If this manifest was changed, which config files led to the most recent change in manifest definition
 	ConfigFilesThatCausedChange []string
+
+	// The last `K8sEventHistoryLimit` k8s events about the manifest's objects.
+	// The most recent event is first in the slice.
+	K8sEvents []K8sEvent
+
+	// The YAML that we last applied (with images and labels injected), and how the
+	// objects in the cluster differ from it, if someone changed them outside of Tilt.
+	LastAppliedYAML string
+	Drift           string
 }
 
 func NewState() *EngineState {
@@ -257,14 +396,31 @@ func NewState() *EngineState {
 	ret.Log = model.Log{}
 	ret.ManifestTargets = make(map[model.ManifestName]*ManifestTarget)
 	ret.PendingConfigFileChanges = make(map[string]time.Time)
+	ret.Ingresses = make(map[string]Ingress)
+	ret.TornDownManifests = make(map[model.ManifestName]bool)
+	ret.CurrentlyBuilding = make(map[model.ManifestName]bool)
 	return ret
 }
 
+// Unless the Tiltfile says otherwise, we build one manifest at a time.
+const DefaultMaxParallelUpdates = 1
+
+// How many more manifests can start building right now.
+func (e EngineState) AvailableBuildSlots() int {
+	max := e.MaxParallelUpdates
+	if max < 1 {
+		max = DefaultMaxParallelUpdates
+	}
+	return max - len(e.CurrentlyBuilding)
+}
+
 func newManifestState(mn model.ManifestName) *ManifestState {
 	return &ManifestState{
 		Name:          mn,
 		BuildStatuses: make(map[model.TargetID]*BuildStatus),
 		LBs:           make(map[k8s.ServiceName]*url.URL),
+		Services:      make(map[string]bool),
+		ReplicaSets:   make(map[string]ReplicaSet),
 	}
 }
 
@@ -297,6 +453,11 @@ func (ms *ManifestState) DCResourceState() dockercompose.State {
 	return ret
 }
 
+func (ms *ManifestState) LocalResourceState() LocalResourceState {
+	ret, _ := ms.ResourceState.(LocalResourceState)
+	return ret
+}
+
 func (ms *ManifestState) IsDC() bool {
 	_, ok := ms.ResourceState.(dockercompose.State)
 	return ok
@@ -313,11 +474,41 @@ func (ms *ManifestState) LastBuild() model.BuildRecord {
 	return ms.BuildHistory[0]
 }
 
+func (ms *ManifestState) AddK8sEvent(e K8sEvent) {
+	// Kubernetes updates an event when it happens again, instead of making a
+	// new one, so keep only the latest version of each.
+	events := []K8sEvent{e}
+	for _, old := range ms.K8sEvents {
+		if old.Object == e.Object && old.Reason == e.Reason && old.Message == e.Message {
+			continue
+		}
+		events = append(events, old)
+	}
+	if len(events) > K8sEventHistoryLimit {
+		events = events[:K8sEventHistoryLimit]
+	}
+	ms.K8sEvents = events
+}
+
 func (ms *ManifestState) AddCompletedBuild(bs model.BuildRecord) {
+	ms.BuildErrorCleared = false
 	ms.BuildHistory = append([]model.BuildRecord{bs}, ms.BuildHistory...)
 	if len(ms.BuildHistory) > model.BuildHistoryLimit {
 		ms.BuildHistory = ms.BuildHistory[:model.BuildHistoryLimit]
 	}
+	for i := model.BuildHistoryLogLimit; i < len(ms.BuildHistory); i++ {
+		ms.BuildHistory[i].Log = model.Log{}
+	}
+}
+
+// A copy of the build history to show the user, without the last build's
+// error if they cleared it.
+func (ms *ManifestState) DisplayedBuildHistory() []model.BuildRecord {
+	result := append([]model.BuildRecord{}, ms.BuildHistory...)
+	if ms.BuildErrorCleared && len(result) > 0 {
+		result[0].Error = nil
+	}
+	return result
 }
 
 func (ms *ManifestState) StartedFirstBuild() bool {
@@ -395,6 +586,50 @@ func (ms *ManifestState) HasPendingChangesBefore(highWaterMark time.Time) (bool,
 	return ok, earliest
 }
 
+// The time of the most recent change to this Manifest's synced files
+// or config since the last build, i.e., when the user last touched it.
+func (ms *ManifestState) MostRecentPendingChange() (bool, time.Time) {
+	ok := false
+	latest := time.Time{}
+	t := ms.PendingManifestChange
+	if t.After(latest) && ms.IsPendingTime(t) {
+		ok = true
+		latest = t
+	}
+
+	for _, status := range ms.BuildStatuses {
+		for _, t := range status.PendingFileChanges {
+			if t.After(latest) && ms.IsPendingTime(t) {
+				ok = true
+				latest = t
+			}
+		}
+	}
+	return ok, latest
+}
+
+// Whether any changes have come in since the current build started,
+// which means the current build is already out of date.
+func (ms *ManifestState) HasChangesSinceCurrentBuildStarted() bool {
+	start := ms.CurrentBuild.StartTime
+	if start.IsZero() {
+		return false
+	}
+
+	if ms.PendingManifestChange.After(start) {
+		return true
+	}
+
+	for _, status := range ms.BuildStatuses {
+		for _, t := range status.PendingFileChanges {
+			if t.After(start) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 var _ model.TargetStatus = &ManifestState{}
 
 type YAMLManifestState struct {
@@ -467,6 +702,79 @@ func (s PodSet) PodList() []Pod {
 	return pods
 }
 
+// Whether the resource is up and serving: every live pod has passed its
+// readiness probes, and there are as many ready pods as the target's
+// Deployments want (i.e., the rollout has finished).
+//
+// Pods that ran to completion (like a Job's) are done, so they count as ready
+// too, even though they'll never pass a readiness probe.
+func (s PodSet) Ready(target model.K8sTarget) bool {
+	readyCount := 0
+	succeededCount := 0
+	for _, pod := range s.Pods {
+		if pod.Deleting {
+			continue
+		}
+		if pod.Phase == v1.PodSucceeded {
+			succeededCount++
+			continue
+		}
+		if !pod.Ready {
+			return false
+		}
+		readyCount++
+	}
+	if readyCount == 0 {
+		return succeededCount > 0 && target.DeploymentReplicas == 0
+	}
+	return readyCount >= target.DeploymentReplicas
+}
+
+// The URLs that an Ingress serves, and the resource that deployed it
+// (if it wasn't deployed with the resource's Services).
+type Ingress struct {
+	Namespace k8s.Namespace
+	Manifest  model.ManifestName
+	Endpoints []k8s.IngressEndpoint
+}
+
+// How we key Services, since their names are only unique within a namespace.
+func ServiceKey(ns k8s.Namespace, name k8s.ServiceName) string {
+	return fmt.Sprintf("%s/%s", ns, name)
+}
+
+// A ReplicaSet that one of the resource's Deployments created.
+type ReplicaSet struct {
+	Name       string
+	Deployment string
+	Revision   int
+	Replicas   int32
+}
+
+// Whether one of the resource's Deployments is still replacing the pods
+// of an old ReplicaSet with the pods of its newest one.
+func (ms *ManifestState) RolloutInProgress() bool {
+	newest := make(map[string]int)
+	for _, rs := range ms.ReplicaSets {
+		if rs.Revision > newest[rs.Deployment] {
+			newest[rs.Deployment] = rs.Revision
+		}
+	}
+
+	for _, rs := range ms.ReplicaSets {
+		if rs.Revision < newest[rs.Deployment] && rs.Replicas > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the resource's pods are ready (see PodSet.Ready),
+// and none of its Deployments are in the middle of a rollout.
+func (ms *ManifestState) K8sReady(target model.K8sTarget) bool {
+	return ms.PodSet.Ready(target) && !ms.RolloutInProgress()
+}
+
 // Get the "most recent pod" from the PodSet.
 // For most users, we believe there will be only one pod per manifest.
 // So most of this time, this will return the only pod.
@@ -485,6 +793,24 @@ func (s PodSet) MostRecentPod() Pod {
 	return bestPod
 }
 
+const K8sEventHistoryLimit = 10
+
+// A k8s event about one of a manifest's objects.
+type K8sEvent struct {
+	Time time.Time
+
+	// The object that the event is about, e.g., "pod/frontend-1234".
+	Object string
+
+	// "Normal" or "Warning".
+	Type    string
+	Reason  string
+	Message string
+
+	// How many times it's happened.
+	Count int32
+}
+
 type Pod struct {
 	PodID     k8s.PodID
 	Namespace k8s.Namespace
@@ -492,6 +818,9 @@ type Pod struct {
 	Status    string
 	Phase     v1.PodPhase
 
+	// Whether all the pod's containers have started and passed their readiness probes.
+	Ready bool
+
 	// Set when we get ready to replace a pod. We may do the update in-place.
 	UpdateStartTime time.Time
 
@@ -521,12 +850,68 @@ type Pod struct {
 	// we need to ship log visibility into multiple containers. Here's the minimum
 	// of info we need for that.
 	ContainerInfos []ContainerInfo
+
+	// The status of each port forward to this pod, by local port.
+	PortForwardStatuses map[int]PortForwardStatus
+
+	// Human-readable explanations of why the pod is failing,
+	// from the pod's status and from k8s events (keyed by event reason).
+	StatusAlerts []string
+	EventAlerts  map[string]string
+
+	// The resource limits of each container, from the pod spec.
+	ContainerLimits map[container.Name]v1.ResourceList
+
+	// How much CPU and memory each container is using,
+	// if the cluster has metrics-server.
+	ContainerMetrics []k8s.ContainerMetrics
+}
+
+func (p Pod) Alerts() []string {
+	var result []string
+	result = append(result, p.StatusAlerts...)
+
+	reasons := make([]string, 0, len(p.EventAlerts))
+	for reason := range p.EventAlerts {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		result = append(result, p.EventAlerts[reason])
+	}
+	return result
+}
+
+type PortForwardStatus string
+
+const (
+	PortForwardConnected    PortForwardStatus = "connected"
+	PortForwardReconnecting PortForwardStatus = "reconnecting"
+)
+
+// The status of all the port forwards to this pod, taken together:
+// reconnecting if any of them are down.
+func (p Pod) PortForwardStatus() PortForwardStatus {
+	var result PortForwardStatus
+	for _, status := range p.PortForwardStatuses {
+		if status == PortForwardReconnecting {
+			return PortForwardReconnecting
+		}
+		result = status
+	}
+	return result
 }
 
 // The minimum info we need to retrieve logs for a container.
 type ContainerInfo struct {
 	ID container.ID
 	container.Name
+
+	// Init containers run (and log) before the pod is running.
+	Init bool
+
+	// Whether the container isn't part of the app (e.g., a service mesh proxy).
+	Sidecar bool
 }
 
 func (p Pod) Empty() bool {
@@ -548,41 +933,50 @@ func (p Pod) Log() model.Log {
 }
 
 func ManifestTargetEndpoints(mt *ManifestTarget) (endpoints []string) {
-	defer func() {
-		sort.Strings(endpoints)
-	}()
-
-	// If the user specified port-forwards in the Tiltfile, we
-	// assume that's what they want to see in the UI
-	portForwards := mt.Manifest.K8sTarget().PortForwards
-	if len(portForwards) > 0 {
-		for _, pf := range portForwards {
-			endpoints = append(endpoints, fmt.Sprintf("http://localhost:%d/", pf.LocalPort))
-		}
-		return endpoints
-	}
-
 	publishedPorts := mt.Manifest.DockerComposeTarget().PublishedPorts()
 	if len(publishedPorts) > 0 {
 		for _, p := range publishedPorts {
 			endpoints = append(endpoints, fmt.Sprintf("http://localhost:%d/", p))
 		}
+		sort.Strings(endpoints)
 		return endpoints
 	}
 
-	for _, u := range mt.State.LBs {
-		if u != nil {
-			endpoints = append(endpoints, u.String())
+	// If the user specified port-forwards in the Tiltfile, we
+	// assume that's what they want to see first in the UI
+	for _, pf := range mt.Manifest.K8sTarget().PortForwards {
+		endpoints = append(endpoints, fmt.Sprintf("http://localhost:%d/", pf.LocalPort))
+	}
+	sort.Strings(endpoints)
+
+	// Then the URLs of the resource's load balancers and Ingresses,
+	// which are how you reach it on clusters where we don't port-forward.
+	var discovered []string
+	seen := make(map[string]bool)
+	addURL := func(u *url.URL) {
+		if u == nil || seen[u.String()] {
+			return
 		}
+		seen[u.String()] = true
+		discovered = append(discovered, u.String())
+	}
+	for _, u := range mt.State.LBs {
+		addURL(u)
 	}
-	return endpoints
+	for _, u := range mt.State.IngressURLs {
+		addURL(u)
+	}
+	sort.Strings(discovered)
+	return append(endpoints, discovered...)
 }
 
 func StateToView(s EngineState) view.View {
 	ret := view.View{
-		TriggerMode:   s.TriggerMode,
-		IsProfiling:   s.IsProfiling,
-		LogTimestamps: s.LogTimestamps,
+		TriggerMode:      s.TriggerMode,
+		IsProfiling:      s.IsProfiling,
+		LogTimestamps:    s.LogTimestamps,
+		KubeContextAlert: s.KubeContextAlert,
+		UpdatesPaused:    s.UpdatesPaused,
 	}
 
 	ret.Resources = append(ret.Resources, tiltfileResourceView(s))
@@ -618,7 +1012,7 @@ func StateToView(s EngineState) view.View {
 
 		pendingBuildEdits = ospath.FileListDisplayNames(absWatchDirs, pendingBuildEdits)
 
-		buildHistory := append([]model.BuildRecord{}, ms.BuildHistory...)
+		buildHistory := ms.DisplayedBuildHistory()
 		for i, build := range buildHistory {
 			build.Edits = ospath.FileListDisplayNames(absWatchDirs, build.Edits)
 			buildHistory[i] = build
@@ -648,8 +1042,17 @@ func StateToView(s EngineState) view.View {
 			PendingBuildReason: ms.NextBuildReason(),
 			CurrentBuild:       currentBuild,
 			CrashLog:           ms.CrashLog,
+			CombinedLog:        ms.CombinedLog,
 			Endpoints:          endpoints,
 			ResourceInfo:       resourceInfoView(mt),
+			TriggerMode:        s.TriggerModeForManifest(mt.Manifest),
+			Disabled:           ms.Disabled,
+			UpdatesPaused:      s.UpdatesPausedForManifest(name),
+
+			WaitingOnReadinessCheck: !mt.Manifest.ReadinessCheck.Empty() && !ms.ReadinessCheckPassed,
+			ReadinessCheckError:     ms.ReadinessCheckError,
+			CrashBackoffUntil:       ms.CrashBackoffUntil,
+			Labels:                  mt.Manifest.Labels,
 		}
 
 		ret.Resources = append(ret.Resources, r)
@@ -672,6 +1075,7 @@ func tiltfileResourceView(s EngineState) view.Resource {
 		BuildHistory: []model.BuildRecord{
 			ltfb,
 		},
+		CombinedLog: s.TiltfileCombinedLog,
 	}
 	if !s.CurrentTiltfileBuild.Empty() {
 		tr.PendingBuildSince = s.CurrentTiltfileBuild.StartTime
@@ -694,6 +1098,17 @@ func resourceInfoView(mt *ManifestTarget) view.ResourceInfoView {
 		}
 	}
 
+	if mt.Manifest.IsLocal() {
+		lState := mt.State.LocalResourceState()
+		return view.LocalResourceInfo{
+			HasServeCmd: !mt.Manifest.LocalTarget().ServeCmd.Empty(),
+			ServeStatus: string(lState.Status),
+			PID:         lState.PID,
+			StartTime:   lState.StartTime,
+			Log:         lState.CurrentLog,
+		}
+	}
+
 	if dcState, ok := mt.State.ResourceState.(dockercompose.State); ok {
 		return view.NewDCResourceInfo(mt.Manifest.DockerComposeTarget().ConfigPath, dcState.Status, dcState.ContainerID, dcState.Log(), dcState.StartTime)
 	} else {
@@ -703,11 +1118,38 @@ func resourceInfoView(mt *ManifestTarget) view.ResourceInfoView {
 			PodCreationTime:    pod.StartedAt,
 			PodUpdateStartTime: pod.UpdateStartTime,
 			PodStatus:          pod.Status,
+			PodReady:           mt.State.K8sReady(mt.Manifest.K8sTarget()),
 			PodRestarts:        pod.ContainerRestarts - pod.OldRestarts,
 			PodLog:             pod.CurrentLog,
 			YAML:               mt.Manifest.K8sTarget().YAML,
+			PortForwardStatus:  string(pod.PortForwardStatus()),
+			PodNamespace:       pod.Namespace.String(),
+			PodAlerts:          pod.Alerts(),
+			PodCPU:             pod.CPUUsage(),
+			PodMemory:          pod.MemoryUsage(),
+			PodUsageWarnings:   pod.UsageWarnings(),
+			Drift:              mt.State.Drift,
+			Events:             k8sEventsView(mt.State.K8sEvents),
+		}
+	}
+}
+
+func k8sEventsView(events []K8sEvent) []view.K8sEvent {
+	if len(events) == 0 {
+		return nil
+	}
+	result := make([]view.K8sEvent, len(events))
+	for i, e := range events {
+		result[i] = view.K8sEvent{
+			Time:    e.Time,
+			Object:  e.Object,
+			Type:    e.Type,
+			Reason:  e.Reason,
+			Message: e.Message,
+			Count:   e.Count,
 		}
 	}
+	return result
 }
 
 // DockerComposeConfigPath returns the path to the docker-compose yaml file of any
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/windmilleng/tilt/internal/hud/view"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/model"
@@ -64,6 +67,31 @@ func TestStateToViewPortForwards(t *testing.T) {
 		res.Endpoints)
 }
 
+func TestStateToViewDiscoveredEndpoints(t *testing.T) {
+	m := model.Manifest{
+		Name: "foo",
+	}.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{
+			{LocalPort: 8000, ContainerPort: 5000},
+		},
+	})
+	state := newState([]model.Manifest{m})
+	ms := state.ManifestTargets[m.Name].State
+	lb, _ := url.Parse("http://1.2.3.4:8080/")
+	ing, _ := url.Parse("https://foo.example.com/")
+	ms.LBs["foo"] = lb
+	ms.LBs["foo-internal"] = nil
+	ms.IngressURLs = []*url.URL{ing, lb}
+
+	v := StateToView(*state)
+	res, _ := v.Resource(m.Name)
+
+	// Port forwards first, then everything else we found.
+	assert.Equal(t,
+		[]string{"http://localhost:8000/", "http://1.2.3.4:8080/", "https://foo.example.com/"},
+		res.Endpoints)
+}
+
 func TestStateToViewUnresourcedYAMLManifest(t *testing.T) {
 	m := k8s.NewK8sOnlyManifestForTesting("yamlyaml", []string{"deployA", "serviceB"})
 	state := newState([]model.Manifest{m})
@@ -86,6 +114,66 @@ func TestMostRecentPod(t *testing.T) {
 	assert.Equal(t, "pod-b", podSet.MostRecentPod().PodID.String())
 }
 
+func TestPodSetReady(t *testing.T) {
+	target := model.K8sTarget{DeploymentReplicas: 2}
+	podA := Pod{PodID: "pod-a", Ready: true}
+	podB := Pod{PodID: "pod-b"}
+	podOld := Pod{PodID: "pod-old", Deleting: true}
+	podSet := NewPodSet(podA, podB, podOld)
+
+	// pod-b hasn't passed its readiness probes.
+	assert.False(t, podSet.Ready(target))
+
+	podSet.Pods["pod-b"].Ready = true
+	assert.True(t, podSet.Ready(target))
+
+	// The rollout hasn't brought up all the replicas yet.
+	assert.False(t, podSet.Ready(model.K8sTarget{DeploymentReplicas: 3}))
+
+	assert.False(t, NewPodSet().Ready(model.K8sTarget{}))
+}
+
+func TestPodSetReadyWhenPodsSucceeded(t *testing.T) {
+	job := model.K8sTarget{HasPods: true}
+	podSet := NewPodSet(Pod{PodID: "migrate", Phase: v1.PodRunning})
+	assert.False(t, podSet.Ready(job))
+
+	// A Job's pod never passes its readiness probes, but once it's done, it's done.
+	podSet.Pods["migrate"].Phase = v1.PodSucceeded
+	assert.True(t, podSet.Ready(job))
+
+	// Until the Deployment's pods come up, too.
+	assert.False(t, podSet.Ready(model.K8sTarget{HasPods: true, DeploymentReplicas: 1}))
+	podSet = NewPodSet(Pod{PodID: "migrate", Phase: v1.PodSucceeded}, Pod{PodID: "app", Phase: v1.PodRunning, Ready: true})
+	assert.True(t, podSet.Ready(model.K8sTarget{HasPods: true, DeploymentReplicas: 1}))
+}
+
+func TestUnreadyDependencies(t *testing.T) {
+	db := model.Manifest{Name: "db"}.WithDeployTarget(model.K8sTarget{YAML: "fake-yaml", HasPods: true})
+	config := model.Manifest{Name: "config"}.WithDeployTarget(model.K8sTarget{YAML: "fake-yaml"})
+	app := model.Manifest{Name: "app", ResourceDependencies: []model.ManifestName{"db", "config", "gone"}}
+	state := newState([]model.Manifest{db, config, app})
+
+	// Nothing has deployed yet. "gone" isn't in the session, so we don't wait for it.
+	assert.Equal(t, []model.ManifestName{"db", "config"}, state.UnreadyDependencies(app))
+
+	// config doesn't run any pods, so it's ready once it deploys.
+	state.ManifestTargets["config"].State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now()})
+	state.ManifestTargets["db"].State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now()})
+	assert.Equal(t, []model.ManifestName{"db"}, state.UnreadyDependencies(app))
+
+	state.ManifestTargets["db"].State.PodSet = NewPodSet(Pod{PodID: "db-pod", Ready: true})
+	assert.Empty(t, state.UnreadyDependencies(app))
+
+	// A failed deploy isn't ready.
+	state.ManifestTargets["db"].State.AddCompletedBuild(model.BuildRecord{Error: fmt.Errorf("oops")})
+	assert.Equal(t, []model.ManifestName{"db"}, state.UnreadyDependencies(app))
+
+	// Nor do we wait for a resource that the user turned off.
+	state.ManifestTargets["db"].State.Disabled = true
+	assert.Empty(t, state.UnreadyDependencies(app))
+}
+
 func TestRelativeTiltfilePath(t *testing.T) {
 	es := newState([]model.Manifest{})
 	wd, err := os.Getwd()
@@ -110,3 +198,40 @@ func newState(manifests []model.Manifest) *EngineState {
 
 	return ret
 }
+
+func TestAddK8sEvent(t *testing.T) {
+	ms := &ManifestState{}
+	backoff := K8sEvent{Object: "pod/foo", Reason: "BackOff", Message: "Back-off restarting failed container", Count: 1}
+	ms.AddK8sEvent(backoff)
+	ms.AddK8sEvent(K8sEvent{Object: "pod/foo", Reason: "Pulled", Message: "Pulled image"})
+
+	// Kubernetes bumps the count when the same thing happens again.
+	backoff.Count = 2
+	ms.AddK8sEvent(backoff)
+	assert.Equal(t, []K8sEvent{backoff, {Object: "pod/foo", Reason: "Pulled", Message: "Pulled image"}}, ms.K8sEvents)
+
+	for i := 0; i < K8sEventHistoryLimit+5; i++ {
+		ms.AddK8sEvent(K8sEvent{Object: "pod/foo", Reason: "Created", Message: fmt.Sprintf("Created %d", i)})
+	}
+	assert.Equal(t, K8sEventHistoryLimit, len(ms.K8sEvents))
+}
+
+func TestAddCompletedBuildDropsOldLogs(t *testing.T) {
+	ms := &ManifestState{}
+	start := time.Now()
+	for i := 0; i < model.BuildHistoryLimit+2; i++ {
+		ms.AddCompletedBuild(model.BuildRecord{
+			StartTime: start.Add(time.Duration(i) * time.Second),
+			Log:       model.NewLog(fmt.Sprintf("build %d\n", i)),
+		})
+	}
+
+	assert.Equal(t, model.BuildHistoryLimit, len(ms.BuildHistory))
+	for i, br := range ms.BuildHistory {
+		if i < model.BuildHistoryLogLimit {
+			assert.Equal(t, fmt.Sprintf("build %d\n", model.BuildHistoryLimit+1-i), br.Log.String())
+		} else {
+			assert.True(t, br.Log.Empty(), "build %d should have no log", i)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"time"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+type LocalServeStatus string
+
+const (
+	LocalServeStatusRunning LocalServeStatus = "Running"
+
+	// The serve_cmd exited on its own with status 0.
+	LocalServeStatusCompleted LocalServeStatus = "Completed"
+
+	// The serve_cmd couldn't start, or exited on its own with an error.
+	LocalServeStatusError LocalServeStatus = "Error"
+)
+
+// The state of a local resource's serve_cmd.
+type LocalResourceState struct {
+	Status    LocalServeStatus
+	PID       int
+	StartTime time.Time
+
+	// The serve_cmd's output since it last started.
+	CurrentLog model.Log
+}
+
+func (LocalResourceState) ResourceState() {}
+
+var _ ResourceState = LocalResourceState{}
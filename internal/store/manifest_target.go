@@ -1,6 +1,9 @@
 package store
 
-import "github.com/windmilleng/tilt/internal/model"
+import (
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/model"
+)
 
 type ManifestTarget struct {
 	Manifest model.Manifest
@@ -22,4 +25,29 @@ func (t ManifestTarget) Status() model.TargetStatus {
 	return t.State
 }
 
+// Whether the resource has deployed and is up, so that the resources
+// that depend on it can deploy.
+func (t ManifestTarget) IsReady() bool {
+	ms := t.State
+	if !ms.CurrentBuild.Empty() || len(ms.BuildHistory) == 0 || ms.LastBuild().Error != nil {
+		return false
+	}
+
+	if !t.Manifest.ReadinessCheck.Empty() && !ms.ReadinessCheckPassed {
+		return false
+	}
+
+	switch {
+	case t.Manifest.IsDC():
+		return ms.DCResourceState().Status == dockercompose.StatusUp
+	case t.Manifest.IsK8s():
+		kTarget := t.Manifest.K8sTarget()
+		return !kTarget.HasPods || ms.K8sReady(kTarget)
+	case t.Manifest.IsLocal():
+		lTarget := t.Manifest.LocalTarget()
+		return lTarget.ServeCmd.Empty() || ms.LocalResourceState().Status == LocalServeStatusRunning
+	}
+	return true
+}
+
 var _ model.Target = &ManifestTarget{}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// Bump this whenever the format changes in a way that old state files can't be read.
+const persistedStateVersion = 2
+
+// The build and deploy results that we save across restarts of tilt,
+// so that if nothing has changed, we don't need to rebuild and redeploy everything.
+type PersistedState struct {
+	Version   int
+	Manifests map[model.ManifestName]PersistedManifest
+}
+
+func NewPersistedState() PersistedState {
+	return PersistedState{
+		Version:   persistedStateVersion,
+		Manifests: make(map[model.ManifestName]PersistedManifest),
+	}
+}
+
+type PersistedManifest struct {
+	DeployID   model.DeployID
+	DeployTime time.Time
+
+	// Checksum of the Kubernetes YAML we applied, and the options we applied it with.
+	YAMLChecksum string
+
+	// The kube context we deployed to, and its namespace (where the objects
+	// that don't say what namespace they're in went).
+	KubeContext k8s.KubeContext
+	Namespace   k8s.Namespace
+
+	// The objects we deployed, so that we can check that they're still there.
+	Entities []PersistedEntity
+
+	Images []PersistedImage
+}
+
+type PersistedEntity struct {
+	Kind      string
+	Namespace k8s.Namespace
+	Name      string
+}
+
+type PersistedImage struct {
+	TargetID model.TargetID
+	Ref      string
+
+	// Hash of everything that went into the image: the build details,
+	// and the files in the build context.
+	ContextHash string
+
+	// Files that we copied into the container after the image was built.
+	FilesReplaced []string
+}
+
+// Reads the state file at path.
+//
+// If there's no state file, or it was written by an incompatible version of tilt,
+// returns an empty state.
+func LoadPersistedState(path string) (PersistedState, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewPersistedState(), nil
+		}
+		return PersistedState{}, errors.Wrap(err, "LoadPersistedState")
+	}
+
+	var result PersistedState
+	err = json.Unmarshal(contents, &result)
+	if err != nil {
+		return PersistedState{}, errors.Wrapf(err, "reading %s", path)
+	}
+
+	if result.Version != persistedStateVersion {
+		return NewPersistedState(), nil
+	}
+	if result.Manifests == nil {
+		result.Manifests = make(map[model.ManifestName]PersistedManifest)
+	}
+	return result, nil
+}
+
+// Writes the state file at path.
+//
+// Writes to a temp file first, so that if tilt is killed in the middle
+// of a write, we don't leave a half-written state file behind.
+func SavePersistedState(path string, state PersistedState) error {
+	state.Version = persistedStateVersion
+	contents, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "SavePersistedState")
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), os.FileMode(0700))
+	if err != nil {
+		return errors.Wrap(err, "SavePersistedState")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "SavePersistedState")
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(contents)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "SavePersistedState")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), path), "SavePersistedState")
+}
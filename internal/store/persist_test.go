@@ -0,0 +1,67 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestPersistedStateRoundTrip(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	deployTime := time.Unix(1551202573, 0).UTC()
+	state := NewPersistedState()
+	state.Manifests["fe"] = PersistedManifest{
+		DeployID:     model.DeployID(1234),
+		DeployTime:   deployTime,
+		YAMLChecksum: "yaml-sum",
+		Images: []PersistedImage{
+			{
+				TargetID:      imageID("gcr.io/fe"),
+				Ref:           "gcr.io/fe:tilt-1234",
+				ContextHash:   "context-sum",
+				FilesReplaced: []string{"/src/main.go"},
+			},
+		},
+	}
+
+	path := f.JoinPath("state", "tilt.json")
+	err := SavePersistedState(path, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPersistedState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, state, loaded)
+}
+
+func TestLoadPersistedStateMissingFile(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	loaded, err := LoadPersistedState(f.JoinPath("nope.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, NewPersistedState(), loaded)
+}
+
+func TestLoadPersistedStateOldVersion(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	path := f.WriteFile("state.json", `{"Version": 0, "Manifests": {"fe": {"DeployID": 1}}}`)
+	loaded, err := LoadPersistedState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, NewPersistedState(), loaded)
+}
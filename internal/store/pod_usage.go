@@ -0,0 +1,79 @@
+package store
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// How close to its limit a container can get before we warn the user.
+const usageWarningFraction = 0.9
+
+// Warns about containers that are close to their memory limit (and about to be OOMKilled),
+// or close to their CPU limit (and about to be throttled).
+func (p Pod) UsageWarnings() []string {
+	var result []string
+	for _, cm := range p.ContainerMetrics {
+		limits := p.ContainerLimits[cm.Name]
+
+		if limit, ok := limits[v1.ResourceMemory]; ok && nearLimit(cm.Memory, limit) {
+			result = append(result, fmt.Sprintf("Container %q is using %d%% of its memory limit (%s of %s), and will be OOMKilled if it goes over",
+				cm.Name, percentOf(cm.Memory, limit), formatMemory(cm.Memory.Value()), limit.String()))
+		}
+
+		if limit, ok := limits[v1.ResourceCPU]; ok && nearLimit(cm.CPU, limit) {
+			result = append(result, fmt.Sprintf("Container %q is using %d%% of its CPU limit (%s of %s), and is being throttled",
+				cm.Name, percentOf(cm.CPU, limit), formatCPU(cm.CPU.MilliValue()), limit.String()))
+		}
+	}
+	return result
+}
+
+// The CPU that the pod's containers are using, or the empty string if we don't know.
+func (p Pod) CPUUsage() string {
+	if len(p.ContainerMetrics) == 0 {
+		return ""
+	}
+	var total int64
+	for _, cm := range p.ContainerMetrics {
+		total += cm.CPU.MilliValue()
+	}
+	return formatCPU(total)
+}
+
+// The memory that the pod's containers are using, or the empty string if we don't know.
+func (p Pod) MemoryUsage() string {
+	if len(p.ContainerMetrics) == 0 {
+		return ""
+	}
+	var total int64
+	for _, cm := range p.ContainerMetrics {
+		total += cm.Memory.Value()
+	}
+	return formatMemory(total)
+}
+
+func nearLimit(usage, limit resource.Quantity) bool {
+	if limit.IsZero() {
+		return false
+	}
+	return float64(usage.MilliValue()) >= usageWarningFraction*float64(limit.MilliValue())
+}
+
+func percentOf(usage, limit resource.Quantity) int {
+	return int(100 * usage.MilliValue() / limit.MilliValue())
+}
+
+// Formats millicores the way kubectl top does (e.g., "250m").
+func formatCPU(millis int64) string {
+	return fmt.Sprintf("%dm", millis)
+}
+
+// Formats bytes the way kubectl top does (e.g., "64Mi").
+func formatMemory(bytes int64) string {
+	if bytes < 1024*1024 {
+		return fmt.Sprintf("%dKi", bytes/1024)
+	}
+	return fmt.Sprintf("%dMi", bytes/(1024*1024))
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/k8s"
+)
+
+func TestPodUsage(t *testing.T) {
+	pod := Pod{
+		ContainerMetrics: []k8s.ContainerMetrics{
+			{Name: "app", CPU: resource.MustParse("250m"), Memory: resource.MustParse("64Mi")},
+			{Name: "proxy", CPU: resource.MustParse("5m"), Memory: resource.MustParse("512Ki")},
+		},
+	}
+	assert.Equal(t, "255m", pod.CPUUsage())
+	assert.Equal(t, "64Mi", pod.MemoryUsage())
+	assert.Empty(t, pod.UsageWarnings())
+
+	assert.Equal(t, "", Pod{}.CPUUsage())
+	assert.Equal(t, "", Pod{}.MemoryUsage())
+}
+
+func TestPodUsageWarnings(t *testing.T) {
+	pod := Pod{
+		ContainerLimits: map[container.Name]v1.ResourceList{
+			"app": {
+				v1.ResourceMemory: resource.MustParse("128Mi"),
+				v1.ResourceCPU:    resource.MustParse("1"),
+			},
+		},
+		ContainerMetrics: []k8s.ContainerMetrics{
+			{Name: "app", CPU: resource.MustParse("500m"), Memory: resource.MustParse("120Mi")},
+		},
+	}
+	assert.Equal(t, []string{
+		`Container "app" is using 93% of its memory limit (120Mi of 128Mi), and will be OOMKilled if it goes over`,
+	}, pod.UsageWarnings())
+
+	pod.ContainerMetrics[0].CPU = resource.MustParse("950m")
+	pod.ContainerMetrics[0].Memory = resource.MustParse("64Mi")
+	assert.Equal(t, []string{
+		`Container "app" is using 95% of its CPU limit (950m of 1), and is being throttled`,
+	}, pod.UsageWarnings())
+}
@@ -0,0 +1,260 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// Bump this when the recording format changes in a way that old recordings can't be read.
+const recordingVersion = 1
+
+// The first line of a recording.
+type recordingHeader struct {
+	RecordingVersion int
+	CreatedAt        time.Time
+}
+
+// One line of a recording for each action the store processed.
+type RecordedAction struct {
+	Index int
+	Time  time.Time
+	Type  string
+
+	// The action itself, as JSON, with Secret values redacted (like in snapshots).
+	// Some actions have things that don't turn into JSON (like channels), and then
+	// PayloadError says why.
+	Payload      json.RawMessage `json:",omitempty"`
+	PayloadError string          `json:",omitempty"`
+
+	// A snapshot of the state right after the action, without logs.
+	// Nil if the action didn't change anything but logs.
+	State *Snapshot `json:",omitempty"`
+}
+
+// Writes every action that goes through the store, and the state it led to, to a file,
+// so that `tilt replay` can step through them to find where the state machine went wrong.
+//
+// Snapshots leave out logs. Otherwise every log line would add a copy of all the logs
+// to the recording.
+type ActionRecorder struct {
+	mu        sync.Mutex
+	f         *os.File
+	w         *bufio.Writer
+	index     int
+	lastState []byte
+	err       error
+}
+
+func NewActionRecorder(path string) (*ActionRecorder, error) {
+	// Like snapshots, recordings have logs in them, so only the user can read them.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewActionRecorder")
+	}
+
+	r := &ActionRecorder{f: f, w: bufio.NewWriter(f)}
+	r.writeLine(recordingHeader{RecordingVersion: recordingVersion, CreatedAt: time.Now()})
+	if r.err != nil {
+		_ = f.Close()
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// Records an action and the state right after it.
+//
+// Once a write fails, the recorder stops recording and returns the same error from then on.
+func (r *ActionRecorder) Record(action Action, state EngineState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+
+	entry := RecordedAction{
+		Index: r.index,
+		Time:  time.Now(),
+		Type:  fmt.Sprintf("%T", action),
+	}
+	r.index++
+
+	redactor := newSecretRedactor(state)
+	payload, err := actionPayload(action, redactor)
+	if err != nil {
+		entry.PayloadError = err.Error()
+	} else {
+		entry.Payload = payload
+	}
+
+	snapshot := newSnapshot(state, redactor).withoutLogs()
+	stateJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		r.err = errors.Wrap(err, "ActionRecorder")
+		return r.err
+	}
+	if !bytes.Equal(stateJSON, r.lastState) {
+		entry.State = &snapshot
+		r.lastState = stateJSON
+	}
+
+	r.writeLine(entry)
+	return r.err
+}
+
+func actionPayload(action Action, redactor secretRedactor) (json.RawMessage, error) {
+	payload, err := json.Marshal(action)
+	if err != nil {
+		return nil, err
+	}
+
+	// Redact the decoded strings, rather than the JSON, so that we can't
+	// break the JSON by redacting something that isn't in a string.
+	var decoded interface{}
+	err = json.Unmarshal(payload, &decoded)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactor.redactJSON(decoded))
+}
+
+func (r *ActionRecorder) writeLine(v interface{}) {
+	line, err := json.Marshal(v)
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	if err == nil {
+		// Flush every line, so that the recording is complete even if tilt crashes.
+		err = r.w.Flush()
+	}
+	if err != nil {
+		r.err = errors.Wrap(err, "ActionRecorder")
+	}
+}
+
+func (r *ActionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// Everything in a recording, read back in.
+type Recording struct {
+	Actions []RecordedAction
+}
+
+func LoadRecording(path string) (Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Recording{}, errors.Wrap(err, "LoadRecording")
+	}
+	defer func() { _ = f.Close() }()
+
+	dec := json.NewDecoder(f)
+	var header recordingHeader
+	err = dec.Decode(&header)
+	if err != nil {
+		return Recording{}, errors.Wrapf(err, "reading %s", path)
+	}
+	if header.RecordingVersion != recordingVersion {
+		return Recording{}, fmt.Errorf("%s is not a version %d action recording", path, recordingVersion)
+	}
+
+	var result Recording
+	for dec.More() {
+		var entry RecordedAction
+		err := dec.Decode(&entry)
+		if err != nil {
+			// The last line might be cut off, if tilt was killed in the middle of writing it.
+			break
+		}
+		result.Actions = append(result.Actions, entry)
+	}
+	return result, nil
+}
+
+// Whether the file at path is an action recording (rather than a snapshot).
+func IsRecording(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	var header recordingHeader
+	err = json.NewDecoder(f).Decode(&header)
+	return err == nil && header.RecordingVersion > 0
+}
+
+// Rebuilds the state right after the i-th action, from the last snapshot at or before it.
+//
+// The log says which action it was, since the snapshots don't have logs.
+func (r Recording) StateAt(i int) *EngineState {
+	if len(r.Actions) == 0 {
+		return NewState()
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(r.Actions) {
+		i = len(r.Actions) - 1
+	}
+
+	state := NewState()
+	for j := i; j >= 0; j-- {
+		if r.Actions[j].State != nil {
+			state = r.Actions[j].State.EngineState()
+			break
+		}
+	}
+
+	a := r.Actions[i]
+	desc := fmt.Sprintf("Action %d of %d: %s at %s\n",
+		a.Index+1, len(r.Actions), a.Type, a.Time.Format("15:04:05.000"))
+	if len(a.Payload) > 0 {
+		desc += string(a.Payload) + "\n"
+	} else if a.PayloadError != "" {
+		desc += fmt.Sprintf("(couldn't record the action: %s)\n", a.PayloadError)
+	}
+	state.Log = model.NewLog(desc)
+	return state
+}
+
+func (s Snapshot) withoutLogs() Snapshot {
+	s.CreatedAt = time.Time{}
+	s.Log = model.Log{}
+	s.CurrentTiltfileBuild.Log = model.Log{}
+	s.LastTiltfileBuild.Log = model.Log{}
+
+	manifests := make([]manifestSnapshot, len(s.Manifests))
+	for i, m := range s.Manifests {
+		m.CrashLog = model.Log{}
+		m.CombinedLog = model.Log{}
+		m.CurrentBuild.Log = model.Log{}
+
+		history := make([]buildRecordSnapshot, len(m.BuildHistory))
+		for j, br := range m.BuildHistory {
+			br.Log = model.Log{}
+			history[j] = br
+		}
+		m.BuildHistory = history
+
+		pods := make([]podSnapshot, len(m.Pods))
+		for j, pod := range m.Pods {
+			pod.CurrentLog = model.Log{}
+			pods[j] = pod
+		}
+		m.Pods = pods
+		manifests[i] = m
+	}
+	s.Manifests = manifests
+	return s
+}
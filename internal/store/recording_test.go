@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+type recordingTestAction struct {
+	manifest model.ManifestName
+	log      string
+}
+
+func (recordingTestAction) Action() {}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	path := f.JoinPath("actions.jsonl")
+	r, err := NewActionRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := NewState()
+	state.UpsertManifestTarget(NewManifestTarget(model.Manifest{Name: "fe"}))
+	assert.NoError(t, r.Record(recordingTestAction{manifest: "fe"}, *state))
+
+	// Only the logs change, so there's no new snapshot.
+	state.Log = model.NewLog("hello\n")
+	assert.NoError(t, r.Record(recordingTestAction{log: "hello\n"}, *state))
+
+	state.UpsertManifestTarget(NewManifestTarget(model.Manifest{Name: "be"}))
+	state.ManifestTargets["fe"].State.BuildHistory = []model.BuildRecord{{StartTime: time.Now(), Error: fmt.Errorf("oh no")}}
+	assert.NoError(t, r.Record(recordingTestAction{manifest: "be"}, *state))
+	assert.NoError(t, r.Close())
+
+	assert.True(t, IsRecording(path))
+	rec, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Equal(t, 3, len(rec.Actions)) {
+		assert.Equal(t, "store.recordingTestAction", rec.Actions[0].Type)
+		assert.NotNil(t, rec.Actions[0].State)
+		assert.Nil(t, rec.Actions[1].State)
+		assert.NotNil(t, rec.Actions[2].State)
+	}
+
+	assert.Equal(t, []model.ManifestName{"fe"}, rec.StateAt(1).ManifestDefinitionOrder)
+	assert.Contains(t, rec.StateAt(1).Log.String(), "Action 2 of 3: store.recordingTestAction")
+
+	last := rec.StateAt(100)
+	assert.Equal(t, []model.ManifestName{"fe", "be"}, last.ManifestDefinitionOrder)
+	assert.Equal(t, "oh no", last.ManifestTargets["fe"].State.LastBuild().Error.Error())
+}
+
+type recordingPayloadAction struct {
+	Message string
+}
+
+func (recordingPayloadAction) Action() {}
+
+type recordingChannelAction struct {
+	Ch chan struct{}
+}
+
+func (recordingChannelAction) Action() {}
+
+func TestRecordingPayloads(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	path := f.JoinPath("actions.jsonl")
+	r, err := NewActionRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := NewState()
+	m := model.Manifest{Name: "secrets"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.SecretYaml})
+	state.UpsertManifestTarget(NewManifestTarget(m))
+	assert.NoError(t, r.Record(recordingPayloadAction{Message: "password is 1f2d1e2e67df"}, *state))
+	assert.NoError(t, r.Record(recordingChannelAction{Ch: make(chan struct{})}, *state))
+	assert.NoError(t, r.Close())
+
+	rec, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 2, len(rec.Actions)) {
+		assert.JSONEq(t, `{"Message": "password is [redacted secret]"}`, string(rec.Actions[0].Payload))
+		assert.Contains(t, rec.StateAt(0).Log.String(), "[redacted secret]")
+		assert.Contains(t, rec.Actions[1].PayloadError, "chan")
+	}
+}
+
+func TestRecordingSurvivesTruncatedLastLine(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	path := f.JoinPath("actions.jsonl")
+	r, err := NewActionRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, r.Record(recordingTestAction{}, *NewState()))
+	assert.NoError(t, r.Record(recordingTestAction{}, *NewState()))
+	assert.NoError(t, r.Close())
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteFile("actions.jsonl", strings.TrimSuffix(string(contents), "}\n"))
+
+	rec, err := LoadRecording(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rec.Actions))
+}
+
+func TestSnapshotIsNotRecording(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("snapshot.json", `{"Version": 1}`)
+	assert.False(t, IsRecording(f.JoinPath("snapshot.json")))
+}
+
+func TestStoreRecordsActions(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	path := f.JoinPath("actions.jsonl")
+	r, err := NewActionRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, _ := NewStoreForTesting()
+	st.SetActionRecorder(r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	st.Dispatch(recordingTestAction{})
+	st.Dispatch(NewErrorAction(fmt.Errorf("done")))
+	_ = st.Loop(ctx)
+	assert.NoError(t, r.Close())
+
+	rec, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, a := range rec.Actions {
+		types = append(types, a.Type)
+	}
+	assert.Equal(t, []string{"store.recordingTestAction", "store.ErrorAction"}, types)
+}
@@ -0,0 +1,322 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/k8s"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// Bump this when the snapshot format changes in a way that old snapshots can't be read.
+const snapshotVersion = 1
+
+// A copy of the EngineState that we can write to a file and read back, so that
+// users can attach what Tilt saw to a bug report, and we can look at it with
+// `tilt replay`.
+//
+// It has what the HUD shows (manifests, build history, pods, and logs), but not
+// enough to build or deploy anything. Errors and image refs become strings,
+// because they don't survive a round trip through JSON.
+type Snapshot struct {
+	Version      int
+	CreatedAt    time.Time
+	TiltBuild    model.TiltBuild
+	TiltfilePath string
+	TriggerMode  model.TriggerMode
+	Log          model.Log
+
+	CurrentTiltfileBuild buildRecordSnapshot
+	LastTiltfileBuild    buildRecordSnapshot
+
+	Manifests []manifestSnapshot
+}
+
+type buildRecordSnapshot struct {
+	model.BuildRecord
+	Error string
+}
+
+func newBuildRecordSnapshot(br model.BuildRecord, r secretRedactor) buildRecordSnapshot {
+	result := buildRecordSnapshot{BuildRecord: br}
+	if br.Error != nil {
+		result.Error = r.redact(br.Error.Error())
+	}
+	result.BuildRecord.Error = nil
+	result.BuildRecord.Log = r.redactLog(br.Log)
+	return result
+}
+
+func (s buildRecordSnapshot) buildRecord() model.BuildRecord {
+	result := s.BuildRecord
+	if s.Error != "" {
+		result.Error = errors.New(s.Error)
+	}
+	return result
+}
+
+type podSnapshot struct {
+	Pod
+	ContainerImageRef string
+}
+
+type manifestSnapshot struct {
+	Name                 model.ManifestName
+	UpdateMode           model.UpdateMode
+	ResourceDependencies []model.ManifestName
+	Labels               []string
+
+	// Set for k8s resources.
+	K8sYAML          string
+	K8sResourceNames []string
+	K8sHasPods       bool
+	Pods             []podSnapshot
+	Drift            string
+
+	// Set for docker-compose resources.
+	DockerComposeConfigPath string
+	DockerComposeState      *dockercompose.State
+
+	CurrentBuild             buildRecordSnapshot
+	BuildHistory             []buildRecordSnapshot
+	PendingFileChanges       map[string]time.Time
+	PendingManifestChange    time.Time
+	LastSuccessfulDeployTime time.Time
+	CrashLog                 model.Log
+	CombinedLog              model.Log
+
+	// For the alerts and resource details that the teammate reading the
+	// snapshot sees.
+	K8sEvents           []K8sEvent
+	ReadinessCheckError string
+	Disabled            bool
+}
+
+// Snapshots get attached to bug reports, so NewSnapshot leaves out the
+// values of Secrets, both in their YAML and anywhere they show up in the logs.
+func NewSnapshot(state EngineState) Snapshot {
+	return newSnapshot(state, newSecretRedactor(state))
+}
+
+func newSnapshot(state EngineState, r secretRedactor) Snapshot {
+	s := Snapshot{
+		Version:              snapshotVersion,
+		CreatedAt:            time.Now(),
+		TiltBuild:            state.TiltBuildInfo,
+		TiltfilePath:         state.TiltfilePath,
+		TriggerMode:          state.TriggerMode,
+		Log:                  r.redactLog(state.Log),
+		CurrentTiltfileBuild: newBuildRecordSnapshot(state.CurrentTiltfileBuild, r),
+		LastTiltfileBuild:    newBuildRecordSnapshot(state.LastTiltfileBuild, r),
+	}
+
+	for _, mt := range state.Targets() {
+		m := mt.Manifest
+		ms := mt.State
+		snap := manifestSnapshot{
+			Name:                     m.Name,
+			UpdateMode:               m.UpdateMode,
+			ResourceDependencies:     m.ResourceDependencies,
+			Labels:                   m.Labels,
+			Drift:                    ms.Drift,
+			CurrentBuild:             newBuildRecordSnapshot(ms.CurrentBuild, r),
+			PendingManifestChange:    ms.PendingManifestChange,
+			LastSuccessfulDeployTime: ms.LastSuccessfulDeployTime,
+			CrashLog:                 r.redactLog(ms.CrashLog),
+			CombinedLog:              r.redactLog(ms.CombinedLog),
+			K8sEvents:                ms.K8sEvents,
+			ReadinessCheckError:      r.redact(ms.ReadinessCheckError),
+			Disabled:                 ms.Disabled,
+		}
+
+		for _, br := range ms.BuildHistory {
+			snap.BuildHistory = append(snap.BuildHistory, newBuildRecordSnapshot(br, r))
+		}
+
+		for _, status := range ms.BuildStatuses {
+			for f, t := range status.PendingFileChanges {
+				if snap.PendingFileChanges == nil {
+					snap.PendingFileChanges = make(map[string]time.Time)
+				}
+				snap.PendingFileChanges[f] = t
+			}
+		}
+
+		if m.IsK8s() {
+			kTarget := m.K8sTarget()
+			snap.K8sYAML = k8s.RedactSecretsInYAML(kTarget.YAML)
+			snap.K8sResourceNames = kTarget.ResourceNames
+			snap.K8sHasPods = kTarget.HasPods
+			for _, pod := range ms.PodSet.PodList() {
+				ps := podSnapshot{Pod: pod}
+				ps.Pod.CurrentLog = r.redactLog(pod.CurrentLog)
+				if pod.ContainerImageRef != nil {
+					ps.ContainerImageRef = pod.ContainerImageRef.String()
+				}
+				ps.Pod.ContainerImageRef = nil
+				snap.Pods = append(snap.Pods, ps)
+			}
+		}
+
+		if m.IsDC() {
+			snap.DockerComposeConfigPath = m.DockerComposeTarget().ConfigPath
+			dcState := ms.DCResourceState()
+			snap.DockerComposeState = &dcState
+		}
+
+		s.Manifests = append(s.Manifests, snap)
+	}
+	return s
+}
+
+// Replaces the values of the Secrets that the manifests deploy.
+type secretRedactor struct {
+	replacer *strings.Replacer
+}
+
+func newSecretRedactor(state EngineState) secretRedactor {
+	var oldnew []string
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsK8s() {
+			continue
+		}
+		entities, err := k8s.ParseYAMLFromString(mt.Manifest.K8sTarget().YAML)
+		if err != nil {
+			continue
+		}
+		for _, v := range k8s.SecretValues(entities) {
+			oldnew = append(oldnew, v, "[redacted secret]")
+		}
+	}
+	if len(oldnew) == 0 {
+		return secretRedactor{}
+	}
+	return secretRedactor{replacer: strings.NewReplacer(oldnew...)}
+}
+
+func (r secretRedactor) redact(s string) string {
+	if r.replacer == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+func (r secretRedactor) redactLog(l model.Log) model.Log {
+	if r.replacer == nil {
+		return l
+	}
+	return model.NewLog(r.replacer.Replace(l.String()))
+}
+
+// Redacts the strings in a value decoded from JSON.
+func (r secretRedactor) redactJSON(v interface{}) interface{} {
+	if r.replacer == nil {
+		return v
+	}
+	switch v := v.(type) {
+	case string:
+		return r.replacer.Replace(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactJSON(item)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = r.redactJSON(item)
+		}
+	}
+	return v
+}
+
+func LoadSnapshot(path string) (Snapshot, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, errors.Wrap(err, "LoadSnapshot")
+	}
+
+	var result Snapshot
+	err = json.Unmarshal(contents, &result)
+	if err != nil {
+		return Snapshot{}, errors.Wrapf(err, "reading %s", path)
+	}
+
+	if result.Version != snapshotVersion {
+		return Snapshot{}, fmt.Errorf("%s is a version %d snapshot, but this version of Tilt reads version %d",
+			path, result.Version, snapshotVersion)
+	}
+	return result, nil
+}
+
+// Rebuilds an EngineState from the snapshot, with enough in it to render the HUD.
+func (s Snapshot) EngineState() *EngineState {
+	state := NewState()
+	state.TiltBuildInfo = s.TiltBuild
+	state.TiltfilePath = s.TiltfilePath
+	state.TriggerMode = s.TriggerMode
+	state.Log = s.Log
+	state.CurrentTiltfileBuild = s.CurrentTiltfileBuild.buildRecord()
+	state.LastTiltfileBuild = s.LastTiltfileBuild.buildRecord()
+
+	for _, snap := range s.Manifests {
+		m := model.Manifest{
+			Name:                 snap.Name,
+			UpdateMode:           snap.UpdateMode,
+			ResourceDependencies: snap.ResourceDependencies,
+			Labels:               snap.Labels,
+		}
+		if snap.DockerComposeState != nil {
+			m = m.WithDeployTarget(model.DockerComposeTarget{ConfigPath: snap.DockerComposeConfigPath})
+		} else {
+			m = m.WithDeployTarget(model.K8sTarget{
+				YAML:          snap.K8sYAML,
+				ResourceNames: snap.K8sResourceNames,
+				HasPods:       snap.K8sHasPods,
+			})
+		}
+
+		mt := NewManifestTarget(m)
+		ms := mt.State
+		ms.Drift = snap.Drift
+		ms.CurrentBuild = snap.CurrentBuild.buildRecord()
+		ms.PendingManifestChange = snap.PendingManifestChange
+		ms.LastSuccessfulDeployTime = snap.LastSuccessfulDeployTime
+		ms.CrashLog = snap.CrashLog
+		ms.CombinedLog = snap.CombinedLog
+		ms.K8sEvents = snap.K8sEvents
+		ms.ReadinessCheckError = snap.ReadinessCheckError
+		ms.Disabled = snap.Disabled
+		for _, br := range snap.BuildHistory {
+			ms.BuildHistory = append(ms.BuildHistory, br.buildRecord())
+		}
+		if len(snap.PendingFileChanges) > 0 {
+			ms.BuildStatuses[m.ID()] = &BuildStatus{PendingFileChanges: snap.PendingFileChanges}
+		}
+
+		var pods []Pod
+		for _, ps := range snap.Pods {
+			pod := ps.Pod
+			if ps.ContainerImageRef != "" {
+				ref, err := container.ParseNamed(ps.ContainerImageRef)
+				if err == nil {
+					pod.ContainerImageRef = ref
+				}
+			}
+			pods = append(pods, pod)
+		}
+		ms.PodSet = NewPodSet(pods...)
+
+		if snap.DockerComposeState != nil {
+			ms.ResourceState = *snap.DockerComposeState
+		}
+
+		state.UpsertManifestTarget(mt)
+	}
+	return state
+}
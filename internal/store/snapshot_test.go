@@ -0,0 +1,99 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/k8s/testyaml"
+	"github.com/windmilleng/tilt/internal/model"
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	start := time.Unix(1551202573, 0).UTC()
+	state := NewState()
+	state.TiltfilePath = "/src/Tiltfile"
+	state.Log = model.NewLog("tilt says hi\n")
+	state.LastTiltfileBuild = model.BuildRecord{StartTime: start, FinishTime: start, Error: fmt.Errorf("oh no")}
+
+	fe := NewManifestTarget(model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.SanchoYAML, HasPods: true}))
+	fe.State.BuildHistory = []model.BuildRecord{{StartTime: start, FinishTime: start, Error: fmt.Errorf("build failed")}}
+	fe.State.CombinedLog = model.NewLog("building fe\n")
+	fe.State.K8sEvents = []K8sEvent{{Time: start, Object: "pod/fe-pod", Type: "Warning", Reason: "BackOff"}}
+	fe.State.PodSet = NewPodSet(Pod{
+		PodID:             "fe-pod",
+		Status:            "Running",
+		CurrentLog:        model.NewLog("listening\n"),
+		ContainerImageRef: container.MustParseNamed("gcr.io/fe:tilt-123"),
+	})
+	state.UpsertManifestTarget(fe)
+
+	db := NewManifestTarget(model.Manifest{Name: "db"}.WithDeployTarget(model.DockerComposeTarget{ConfigPath: "docker-compose.yml"}))
+	db.State.ResourceState = dockercompose.State{Status: dockercompose.StatusUp, ContainerID: "db-container"}
+	state.UpsertManifestTarget(db)
+
+	contents, err := json.Marshal(NewSnapshot(*state))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteFile("snapshot.json", string(contents))
+
+	snapshot, err := LoadSnapshot(f.JoinPath("snapshot.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := snapshot.EngineState()
+
+	assert.Equal(t, "tilt says hi\n", loaded.Log.String())
+	assert.Equal(t, "oh no", loaded.LastTiltfileBuild.Error.Error())
+	assert.Equal(t, []model.ManifestName{"fe", "db"}, loaded.ManifestDefinitionOrder)
+
+	loadedFe := loaded.ManifestTargets["fe"]
+	assert.Contains(t, loadedFe.Manifest.K8sTarget().YAML, "name: sancho")
+	assert.Equal(t, "build failed", loadedFe.State.LastBuild().Error.Error())
+	assert.Equal(t, "building fe\n", loadedFe.State.CombinedLog.String())
+	assert.Equal(t, fe.State.K8sEvents, loadedFe.State.K8sEvents)
+	pod := loadedFe.State.MostRecentPod()
+	assert.Equal(t, "Running", pod.Status)
+	assert.Equal(t, "listening\n", pod.CurrentLog.String())
+	assert.Equal(t, "gcr.io/fe:tilt-123", pod.ContainerImageRef.String())
+
+	loadedDb := loaded.ManifestTargets["db"]
+	assert.True(t, loadedDb.Manifest.IsDC())
+	assert.Equal(t, dockercompose.StatusUp, loadedDb.State.DCResourceState().Status)
+}
+
+func TestLoadSnapshotWrongVersion(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("snapshot.json", `{"Version": 99}`)
+	_, err := LoadSnapshot(f.JoinPath("snapshot.json"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "version 99 snapshot")
+	}
+}
+
+func TestSnapshotRedactsSecrets(t *testing.T) {
+	state := NewState()
+	state.Log = model.NewLog("logging in with password 1f2d1e2e67df\n")
+	m := model.Manifest{Name: "secrets"}.WithDeployTarget(model.K8sTarget{YAML: testyaml.SecretYaml})
+	mt := NewManifestTarget(m)
+	mt.State.CombinedLog = model.NewLog("password: MWYyZDFlMmU2N2Rm\n")
+	state.UpsertManifestTarget(mt)
+
+	snapshot := NewSnapshot(*state)
+	assert.Contains(t, snapshot.Manifests[0].K8sYAML, "name: mysecret")
+	assert.NotContains(t, snapshot.Manifests[0].K8sYAML, "MWYyZDFlMmU2N2Rm")
+	assert.Equal(t, "logging in with password [redacted secret]\n", snapshot.Log.String())
+	assert.Equal(t, "password: [redacted secret]\n", snapshot.Manifests[0].CombinedLog.String())
+}
@@ -2,6 +2,9 @@ package store
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"sync"
 	"time"
 
@@ -36,6 +39,13 @@ type Store struct {
 	reduce      Reducer
 	logActions  bool
 
+	// Times each action through the reducer, for the watchdog.
+	reducerTimer  *activityTimer
+	slowThreshold time.Duration
+	watchdogOut   io.Writer
+
+	recorder *ActionRecorder
+
 	// TODO(nick): Define Subscribers and Reducers.
 	// The actionChan is an intermediate representation to make the transition easier.
 }
@@ -48,6 +58,10 @@ func NewStore(reducer Reducer, logActions LogActionsFlag) *Store {
 		actionCh:    make(chan []Action),
 		subscribers: &subscriberList{},
 		logActions:  bool(logActions),
+
+		reducerTimer:  &activityTimer{},
+		slowThreshold: DefaultSlowThreshold,
+		watchdogOut:   os.Stderr,
 	}
 }
 
@@ -79,6 +93,12 @@ func (s *Store) AddSubscriber(ctx context.Context, sub Subscriber) {
 	s.subscribers.Add(ctx, sub)
 }
 
+// Records every action from now on, along with the state it led to.
+// Must be called before Loop.
+func (s *Store) SetActionRecorder(r *ActionRecorder) {
+	s.recorder = r
+}
+
 func (s *Store) RemoveSubscriber(ctx context.Context, sub Subscriber) error {
 	return s.subscribers.Remove(ctx, sub)
 }
@@ -126,6 +146,10 @@ func (s *Store) Loop(ctx context.Context) error {
 	s.subscribers.SetUp(ctx)
 	defer s.subscribers.TeardownAll(context.Background())
 
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer cancelWatch()
+	go s.watch(watchCtx)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -140,7 +164,20 @@ func (s *Store) Loop(ctx context.Context) error {
 					oldState = s.cheapCopyState()
 				}
 
+				s.reducerTimer.begin(fmt.Sprintf("%T", action))
 				s.reduce(ctx, s.state, action)
+				d, wasStuck := s.reducerTimer.end()
+				if wasStuck {
+					_, _ = fmt.Fprintf(s.watchdogOut, "Finished processing %T after %s\n", action, d.Truncate(time.Millisecond))
+				}
+
+				if s.recorder != nil {
+					err := s.recorder.Record(action, *s.state)
+					if err != nil {
+						logger.Get(ctx).Infof("Stopped recording actions: %v", err)
+						s.recorder = nil
+					}
+				}
 
 				if s.logActions {
 					newState := s.cheapCopyState()
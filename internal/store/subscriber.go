@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // A subscriber is notified whenever the state changes.
@@ -26,6 +28,13 @@ type TearDowner interface {
 	TearDown(ctx context.Context)
 }
 
+// A subscriber that can interrupt its own OnChange, e.g., by closing the
+// connection that it's stuck writing to. The store calls Unstick (while
+// OnChange is still running) when OnChange takes too long.
+type Unsticker interface {
+	Unstick(ctx context.Context)
+}
+
 // Convenience interface for subscriber fulfilling both SetUpper and TearDowner
 type SubscriberLifecycle interface {
 	SetUpper
@@ -44,7 +53,9 @@ func (l *subscriberList) Add(ctx context.Context, s Subscriber) {
 
 	e := &subscriberEntry{
 		subscriber: s,
+		name:       subscriberName(s),
 		dirtyBit:   NewDirtyBit(),
+		timer:      &activityTimer{},
 	}
 	l.subscribers = append(l.subscribers, e)
 	if l.setup {
@@ -92,34 +103,54 @@ func (l *subscriberList) TeardownAll(ctx context.Context) {
 	}
 }
 
-func (l *subscriberList) NotifyAll(ctx context.Context, store *Store) {
+func (l *subscriberList) entries() []*subscriberEntry {
 	l.mu.Lock()
-	subscribers := append([]*subscriberEntry{}, l.subscribers...)
-	l.mu.Unlock()
+	defer l.mu.Unlock()
+	return append([]*subscriberEntry{}, l.subscribers...)
+}
 
-	for _, s := range subscribers {
+func (l *subscriberList) NotifyAll(ctx context.Context, store *Store) {
+	for _, s := range l.entries() {
 		s.dirtyBit.MarkDirty()
 
+		// If a notify is already waiting on this subscriber, it will pick up
+		// the dirty bit. This keeps a stuck subscriber from piling up goroutines
+		// for every state change.
+		if !atomic.CompareAndSwapInt32(&s.pending, 0, 1) {
+			continue
+		}
 		go s.notify(ctx, store)
 	}
 }
 
 type subscriberEntry struct {
 	subscriber Subscriber
+	name       string
 	mu         sync.Mutex
 	dirtyBit   *DirtyBit
+	timer      *activityTimer
+
+	// 1 if there's a notify goroutine that hasn't started OnChange yet.
+	pending int32
 }
 
 func (e *subscriberEntry) notify(ctx context.Context, store *Store) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	atomic.StoreInt32(&e.pending, 0)
 
 	startToken, isDirty := e.dirtyBit.StartBuildIfDirty()
 	if !isDirty {
 		return
 	}
 
+	e.timer.begin(e.name)
 	e.subscriber.OnChange(ctx, store)
+	d, wasStuck := e.timer.end()
+	if wasStuck {
+		_, _ = fmt.Fprintf(store.watchdogOut, "%s finished handling a state change after %s\n", e.name, d.Truncate(time.Millisecond))
+	}
+
 	e.dirtyBit.FinishBuild(startToken)
 }
 
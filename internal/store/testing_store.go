@@ -14,7 +14,8 @@ type TestingStore struct {
 	state   *EngineState
 	stateMu sync.RWMutex
 
-	Actions []Action
+	Actions   []Action
+	actionsMu sync.Mutex
 }
 
 func NewTestingStore() *TestingStore {
@@ -37,9 +38,33 @@ func (s *TestingStore) RUnlockState() {
 }
 
 func (s *TestingStore) Dispatch(action Action) {
+	s.actionsMu.Lock()
+	defer s.actionsMu.Unlock()
 	s.Actions = append(s.Actions, action)
 }
 
+// Waits until at least n actions have been dispatched (e.g., by a subscriber
+// that works in the background), and returns them.
+func (s *TestingStore) WaitForActions(t testing.TB, n int) []Action {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		s.actionsMu.Lock()
+		actions := append([]Action{}, s.Actions...)
+		s.actionsMu.Unlock()
+		if len(actions) >= n {
+			return actions
+		}
+
+		select {
+		case <-timeout:
+			t.Fatalf("timed out waiting for %d actions. Saw: %+v", n, actions)
+			return nil
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
 // for use by tests (with a real channel-based store, NOT a TestingStore), to wait until
 // an action of the specified type comes out of the given chan at some point we might want
 // it to return the index it was found at, and then take an index, so that we can start
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// What the file watcher is doing for one target, so that users can
+// check whether their ignores are working.
+type WatchStats struct {
+	TargetID  model.TargetID       `json:"targetID"`
+	Manifests []model.ManifestName `json:"manifests"`
+	Mode      model.WatchMode      `json:"mode"`
+
+	// The paths we were asked to watch.
+	Paths []string `json:"paths"`
+
+	// What the watcher itself registered, e.g., one inotify watch per
+	// directory (see watch.WatchedPathsReporter), and how many.
+	WatchedPaths []string `json:"watchedPaths"`
+	Watches      int      `json:"watches"`
+
+	// How many files under Paths aren't ignored.
+	Files int `json:"files"`
+
+	// Directories we skip entirely because everything in them is ignored.
+	IgnoredDirs []string `json:"ignoredDirs"`
+
+	// The directories with the most files that aren't ignored.
+	LargestDirs []DirCount `json:"largestDirs"`
+
+	// File changes we've seen since we started watching. Changes to ignored
+	// files don't count.
+	Events int `json:"events"`
+
+	// Events seen in the last minute.
+	EventsLastMinute int `json:"eventsLastMinute"`
+
+	// The directories with the most file changes.
+	NoisiestDirs []DirCount `json:"noisiestDirs"`
+}
+
+type DirCount struct {
+	Dir   string `json:"dir"`
+	Count int    `json:"count"`
+}
+
+// Something that can report what the file watcher is doing.
+type WatchStatsReporter interface {
+	WatchStats() []WatchStats
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/windmilleng/tilt/internal/logger"
+)
+
+// How long a reducer or subscriber can run before the watchdog warns that it's stuck.
+const DefaultSlowThreshold = 5 * time.Second
+
+// How often the watchdog checks on the reducer and subscribers.
+const watchdogInterval = time.Second
+
+// Times something that runs one call at a time (like a subscriber's OnChange,
+// or the store's reducer), so that the watchdog can tell when it gets stuck.
+type activityTimer struct {
+	mu sync.Mutex
+
+	current  string
+	start    time.Time
+	reported bool
+}
+
+func (t *activityTimer) begin(current string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current = current
+	t.start = time.Now()
+	t.reported = false
+}
+
+// Returns how long the call took, and whether the watchdog
+// complained about it while it was running.
+func (t *activityTimer) end() (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	d := time.Since(t.start)
+	reported := t.reported
+	t.current = ""
+	t.start = time.Time{}
+	t.reported = false
+	return d, reported
+}
+
+// Reports the current call if it's been running longer than the threshold.
+// Each call is only reported once.
+func (t *activityTimer) checkStuck(threshold time.Duration) (string, time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.start.IsZero() || t.reported {
+		return "", 0, false
+	}
+
+	elapsed := time.Since(t.start)
+	if elapsed < threshold {
+		return "", 0, false
+	}
+	t.reported = true
+	return t.current, elapsed, true
+}
+
+// Watches the store's reducer and subscribers, and warns when one of them
+// gets stuck, so that "Tilt stopped updating" comes with an explanation.
+func (s *Store) watch(ctx context.Context) {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.checkStuck(ctx)
+	}
+}
+
+// Writes to the watchdog's own output rather than the logger, because log
+// lines go through the store, which might be what's stuck.
+//
+// Subscribers that know how to get unstuck (see Unsticker) get a chance to.
+// We can't do anything about a stuck reducer, since it holds the state lock,
+// and interrupting it could leave the state half-updated.
+func (s *Store) checkStuck(ctx context.Context) {
+	stuck := false
+
+	action, elapsed, ok := s.reducerTimer.checkStuck(s.slowThreshold)
+	if ok {
+		stuck = true
+		_, _ = fmt.Fprintf(s.watchdogOut, "WARNING: Tilt has been processing %s for %s. "+
+			"The UI won't update until it finishes.\n", action, elapsed.Truncate(time.Second))
+	}
+
+	for _, e := range s.subscribers.entries() {
+		_, elapsed, ok := e.timer.checkStuck(s.slowThreshold)
+		if !ok {
+			continue
+		}
+		stuck = true
+
+		u, ok := e.subscriber.(Unsticker)
+		if !ok {
+			_, _ = fmt.Fprintf(s.watchdogOut, "WARNING: %s has been handling a state change for %s. "+
+				"It won't see any more changes until it finishes.\n", e.name, elapsed.Truncate(time.Second))
+			continue
+		}
+		_, _ = fmt.Fprintf(s.watchdogOut, "WARNING: %s has been handling a state change for %s. Interrupting it.\n",
+			e.name, elapsed.Truncate(time.Second))
+		go u.Unstick(ctx)
+	}
+
+	if stuck && logger.Get(ctx).Level() >= logger.DebugLvl {
+		_, _ = fmt.Fprintf(s.watchdogOut, "Goroutines:\n%s\n", allStacks())
+	}
+}
+
+func subscriberName(s Subscriber) string {
+	return fmt.Sprintf("%T", s)
+}
+
+func allStacks() string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/logger"
+)
+
+func TestWatchdogReportsStuckSubscriber(t *testing.T) {
+	st, _ := NewStoreForTesting()
+	st.slowThreshold = 10 * time.Millisecond
+	out := &syncBuffer{}
+	st.watchdogOut = out
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, ioutil.Discard))
+	s := newFakeSubscriber()
+	st.AddSubscriber(ctx, s)
+
+	st.NotifySubscribers(ctx)
+	call := <-s.onChange
+	time.Sleep(20 * time.Millisecond)
+
+	st.checkStuck(ctx)
+	assert.Contains(t, out.String(), "WARNING: *store.fakeSubscriber has been handling a state change")
+
+	// Only warn once per call.
+	out.Reset()
+	st.checkStuck(ctx)
+	assert.Equal(t, "", out.String())
+
+	close(call.done)
+	waitFor(t, func() bool { return out.String() != "" })
+	assert.Contains(t, out.String(), "*store.fakeSubscriber finished handling a state change after")
+}
+
+func TestWatchdogUnsticksSubscriber(t *testing.T) {
+	st, _ := NewStoreForTesting()
+	st.slowThreshold = 10 * time.Millisecond
+	out := &syncBuffer{}
+	st.watchdogOut = out
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, ioutil.Discard))
+	s := &unstickableSubscriber{fakeSubscriber: newFakeSubscriber(), unstuck: make(chan bool)}
+	st.AddSubscriber(ctx, s)
+
+	st.NotifySubscribers(ctx)
+	call := <-s.onChange
+	time.Sleep(20 * time.Millisecond)
+
+	st.checkStuck(ctx)
+	assert.Contains(t, out.String(), "Interrupting it")
+	select {
+	case <-s.unstuck:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Unstick")
+	}
+	close(call.done)
+}
+
+type unstickableSubscriber struct {
+	*fakeSubscriber
+	unstuck chan bool
+}
+
+func (s *unstickableSubscriber) Unstick(ctx context.Context) {
+	close(s.unstuck)
+}
+
+func TestWatchdogIgnoresFastSubscriber(t *testing.T) {
+	st, _ := NewStoreForTesting()
+	out := &syncBuffer{}
+	st.watchdogOut = out
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, ioutil.Discard))
+	s := newFakeSubscriber()
+	st.AddSubscriber(ctx, s)
+
+	st.NotifySubscribers(ctx)
+	call := <-s.onChange
+	st.checkStuck(ctx)
+	close(call.done)
+
+	assert.Equal(t, "", out.String())
+}
+
+func TestStuckSubscriberDoesNotPileUpNotifies(t *testing.T) {
+	st, _ := NewStoreForTesting()
+	ctx := context.Background()
+	s := newFakeSubscriber()
+	st.AddSubscriber(ctx, s)
+
+	st.NotifySubscribers(ctx)
+	call := <-s.onChange
+
+	// One notify waits for the current OnChange to finish.
+	// The rest get folded into it.
+	st.NotifySubscribers(ctx)
+	st.NotifySubscribers(ctx)
+	st.NotifySubscribers(ctx)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&st.subscribers.entries()[0].pending))
+
+	close(call.done)
+	s.assertOnChangeCount(t, 1)
+}
+
+func waitFor(t *testing.T, f func() bool) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for !f() {
+		select {
+		case <-timeout:
+			t.Fatal("timed out waiting for condition")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
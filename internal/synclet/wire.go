@@ -19,8 +19,7 @@ func WireSynclet(ctx context.Context, env k8s.Env, runtime container.Runtime) (*
 		docker.ProvideEnv,
 		docker.ProvideDockerClient,
 		docker.ProvideDockerVersion,
-		docker.DefaultClient,
-		wire.Bind(new(docker.Client), new(docker.Cli)),
+		docker.ProvideClient,
 
 		NewSynclet,
 	)
@@ -29,10 +29,10 @@ func WireSynclet(ctx context.Context, env k8s.Env, runtime container.Runtime) (*
 	if err != nil {
 		return nil, err
 	}
-	cli, err := docker.DefaultClient(ctx, clientClient, version)
+	dockerClient, err := docker.ProvideClient(ctx, clientClient, version, dockerEnv)
 	if err != nil {
 		return nil, err
 	}
-	synclet := NewSynclet(cli)
+	synclet := NewSynclet(dockerClient)
 	return synclet, nil
 }
@@ -0,0 +1,161 @@
+package tiltfile
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+
+	"github.com/windmilleng/tilt/internal/container"
+)
+
+// Builds an image from a Bazel target (e.g., a rules_docker container_image).
+//
+// We ask Bazel for the target's source files, so that we rebuild exactly when
+// one of them changes. BUILD files are recorded as config files, so that
+// when the dependency graph changes, we re-run the query.
+func (s *tiltfileState) bazelBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var dockerRef, target string
+	var liveUpdateVal starlark.Value
+	var disablePush bool
+
+	err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"ref", &dockerRef,
+		"target", &target,
+		"live_update?", &liveUpdateVal,
+		"disable_push?", &disablePush,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := reference.ParseNormalizedNamed(dockerRef)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 1 (ref): can't parse %q: %v", dockerRef, err)
+	}
+
+	label, err := parseBazelLabel(target)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 2 (target): %v", err)
+	}
+
+	liveUpdate, err := s.liveUpdateFromSteps(liveUpdateVal)
+	if err != nil {
+		return nil, errors.Wrap(err, "live_update")
+	}
+
+	deps, buildFiles, err := s.bazelSourceFiles(label)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range buildFiles {
+		s.recordConfigFile(f)
+	}
+
+	img := &dockerImage{
+		configurationRef: container.NewRefSelector(ref),
+		customCommand:    label.buildCommand(),
+		customDeps:       deps,
+		disablePush:      disablePush,
+		liveUpdate:       liveUpdate,
+	}
+
+	err = s.buildIndex.addImage(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.None, nil
+}
+
+type bazelLabel struct {
+	pkg  string
+	name string
+}
+
+// Parses an absolute label in the main workspace, like //foo/bar:image or //foo/bar
+func parseBazelLabel(target string) (bazelLabel, error) {
+	if !strings.HasPrefix(target, "//") {
+		return bazelLabel{}, fmt.Errorf("expected an absolute label like //path/to:image, got %q", target)
+	}
+
+	pkg, name := strings.TrimPrefix(target, "//"), ""
+	if i := strings.Index(pkg, ":"); i != -1 {
+		pkg, name = pkg[:i], pkg[i+1:]
+	} else {
+		name = path.Base(pkg)
+	}
+
+	if pkg == "" {
+		return bazelLabel{}, fmt.Errorf("images in the root package aren't supported, got %q", target)
+	}
+	if name == "" || name == "." {
+		return bazelLabel{}, fmt.Errorf("expected an absolute label like //path/to:image, got %q", target)
+	}
+	return bazelLabel{pkg: pkg, name: name}, nil
+}
+
+func (l bazelLabel) String() string {
+	return fmt.Sprintf("//%s:%s", l.pkg, l.name)
+}
+
+// rules_docker loads the image into the local docker daemon as bazel/<package>:<name>.
+// We re-tag it under the ref that tilt expects, so that the custom build machinery
+// can verify it and push it.
+func (l bazelLabel) buildCommand() string {
+	return fmt.Sprintf(`bazel run %s -- --norun && docker tag %s "$EXPECTED_REF"`,
+		shellQuote(l.String()), shellQuote(fmt.Sprintf("bazel/%s:%s", l.pkg, l.name)))
+}
+
+// Matches `bazel query --output location` lines for source files, e.g.,
+// /src/foo/main.go:1:1: source file //foo:main.go
+var bazelLocationRe = regexp.MustCompile(`^(.*):\d+:\d+: source file (\S+)$`)
+
+// Returns the source files in the main workspace that the target depends on,
+// and the BUILD files that define those dependencies.
+func (s *tiltfileState) bazelSourceFiles(l bazelLabel) (sources []string, buildFiles []string, err error) {
+	sources, err = s.bazelQuery(fmt.Sprintf(`kind("source file", deps(%s))`, l))
+	if err != nil {
+		return nil, nil, err
+	}
+	buildFiles, err = s.bazelQuery(fmt.Sprintf(`buildfiles(deps(%s))`, l))
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(sources, buildFiles...), buildFiles, nil
+}
+
+func (s *tiltfileState) bazelQuery(expr string) ([]string, error) {
+	out, err := s.execLocalCmdArgv("bazel", "query", expr, "--output", "location")
+	if err != nil {
+		return nil, err
+	}
+	return parseBazelLocations(out)
+}
+
+func parseBazelLocations(out string) ([]string, error) {
+	var result []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		match := bazelLocationRe.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("unexpected output from bazel query: %q", line)
+		}
+
+		// Files in external repositories (@io_bazel_rules_go//...) live in bazel's
+		// output base, and don't change unless the WORKSPACE does.
+		if strings.HasPrefix(match[2], "@") {
+			continue
+		}
+		result = append(result, match[1])
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package tiltfile
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBazelBuild(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	defer f.fakeBazel()()
+
+	f.setupFoo()
+	f.file("Tiltfile", `k8s_yaml('foo.yaml')
+bazel_build('gcr.io/foo', '//foo:image')`)
+
+	f.load("foo")
+	f.assertNumManifests(1)
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "foo/BUILD")
+	f.assertNextManifest("foo",
+		cb(
+			image("gcr.io/foo"),
+			deps(f.JoinPath("foo/main.go"), f.JoinPath("lib/lib.go"), f.JoinPath("foo/BUILD")),
+			cmd(`bazel run '//foo:image' -- --norun && docker tag 'bazel/foo:image' "$EXPECTED_REF"`),
+		),
+		deployment("foo"))
+}
+
+func TestBazelBuildRelativeLabel(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `k8s_yaml('foo.yaml')
+bazel_build('gcr.io/foo', ':image')`)
+
+	f.loadErrString("expected an absolute label")
+}
+
+func TestParseBazelLabel(t *testing.T) {
+	l, err := parseBazelLabel("//foo/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, bazelLabel{pkg: "foo/bar", name: "bar"}, l)
+
+	_, err = parseBazelLabel("//:image")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "root package")
+	}
+}
+
+func TestParseBazelLocationsSkipsExternal(t *testing.T) {
+	paths, err := parseBazelLocations(`/src/foo/main.go:1:1: source file //foo:main.go
+/cache/external/go_sdk/BUILD.bazel:1:1: source file @go_sdk//:BUILD.bazel
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"/src/foo/main.go"}, paths)
+}
+
+// Puts a fake bazel on the PATH that answers queries about //foo:image.
+// Returns a function that restores the PATH.
+func (f *fixture) fakeBazel() func() {
+	script := fmt.Sprintf(`#!/bin/sh
+case "$2" in
+  kind*)
+    echo '%s:1:1: source file //foo:main.go'
+    echo '%s:1:1: source file //lib:lib.go'
+    echo '/cache/external/go_sdk/src/fmt/print.go:1:1: source file @go_sdk//:src/fmt/print.go'
+    ;;
+  buildfiles*)
+    echo '%s:1:1: source file //foo:BUILD'
+    ;;
+esac
+`, f.JoinPath("foo/main.go"), f.JoinPath("lib/lib.go"), f.JoinPath("foo/BUILD"))
+	bazel := f.JoinPath("bin", "bazel")
+	f.file("bin/bazel", script)
+	err := os.Chmod(bazel, 0755)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	oldPath := os.Getenv("PATH")
+	_ = os.Setenv("PATH", fmt.Sprintf("%s%c%s", f.JoinPath("bin"), os.PathListSeparator, oldPath))
+	return func() {
+		_ = os.Setenv("PATH", oldPath)
+	}
+}
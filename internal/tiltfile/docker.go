@@ -3,10 +3,13 @@ package tiltfile
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
+	"github.com/containerd/containerd/platforms"
 	"github.com/docker/distribution/reference"
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/dockerfile"
@@ -29,6 +32,12 @@ type dockerImage struct {
 	dbDockerfile     dockerfile.Dockerfile
 	dbBuildPath      localPath
 	dbBuildArgs      model.DockerBuildArgs
+	targetStage      model.DockerBuildTarget
+	contentTag       bool
+	network          string
+	sshSpecs         []string
+	extraHosts       []string
+	platform         string
 
 	customCommand string
 	customDeps    []string
@@ -73,8 +82,10 @@ func (d *dockerImage) Type() dockerImageBuildType {
 }
 
 func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var dockerRef string
+	var dockerRef, targetStage, network, platformVal string
+	var contentTag bool
 	var contextVal, dockerfilePathVal, buildArgs, dockerfileContentsVal, cacheVal, liveUpdateVal starlark.Value
+	var sshVal, extraHostsVal starlark.Value
 	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
 		"ref", &dockerRef,
 		"context", &contextVal,
@@ -83,6 +94,12 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		"dockerfile_contents?", &dockerfileContentsVal,
 		"cache?", &cacheVal,
 		"live_update?", &liveUpdateVal,
+		"target?", &targetStage,
+		"content_tag?", &contentTag,
+		"network?", &network,
+		"ssh?", &sshVal,
+		"extra_hosts?", &extraHostsVal,
+		"platform?", &platformVal,
 	); err != nil {
 		return nil, err
 	}
@@ -146,21 +163,57 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		dockerfileContents = string(bs)
 	}
 
+	df := dockerfile.Dockerfile(dockerfileContents)
+	if targetStage != "" {
+		// Make sure the stage exists now, rather than waiting for the build to fail.
+		_, err := df.TruncateToStage(targetStage)
+		if err != nil {
+			return nil, fmt.Errorf("Argument (target): %v", err)
+		}
+	}
+
 	cachePaths, err := s.cachePathsFromSkylarkValue(cacheVal)
 	if err != nil {
 		return nil, err
 	}
 
+	sshSpecs, err := stringsFromSkylarkValue("ssh", sshVal)
+	if err != nil {
+		return nil, err
+	}
+
+	extraHosts, err := stringsFromSkylarkValue("extra_hosts", extraHostsVal)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range extraHosts {
+		parts := strings.SplitN(host, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("Argument (extra_hosts): %q must be in the form host:ip", host)
+		}
+	}
+
+	platform, err := parsePlatform(platformVal)
+	if err != nil {
+		return nil, err
+	}
+
 	liveUpdate, err := s.liveUpdateFromSteps(liveUpdateVal)
 	if err != nil {
 		return nil, errors.Wrap(err, "live_update")
 	}
 	r := &dockerImage{
 		dbDockerfilePath: dockerfilePath,
-		dbDockerfile:     dockerfile.Dockerfile(dockerfileContents),
+		dbDockerfile:     df,
 		dbBuildPath:      context,
 		configurationRef: container.NewRefSelector(ref),
 		dbBuildArgs:      sba,
+		targetStage:      model.DockerBuildTarget(targetStage),
+		contentTag:       contentTag,
+		network:          network,
+		sshSpecs:         sshSpecs,
+		extraHosts:       extraHosts,
+		platform:         platform,
 		cachePaths:       cachePaths,
 		liveUpdate:       liveUpdate,
 	}
@@ -176,6 +229,15 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 	return fb, nil
 }
 
+// The Dockerfile up to the target stage (if any). The stages after it
+// never make it into the image, so they can't contribute image dependencies.
+func (d *dockerImage) targetDockerfile() (dockerfile.Dockerfile, error) {
+	if d.targetStage.Empty() {
+		return d.dbDockerfile, nil
+	}
+	return d.dbDockerfile.TruncateToStage(d.targetStage.String())
+}
+
 func (s *tiltfileState) fastBuildForImage(image *dockerImage) model.FastBuild {
 	return model.FastBuild{
 		BaseDockerfile: image.baseDockerfile.String(),
@@ -190,7 +252,7 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 	var dockerRef string
 	var command string
 	var deps *starlark.List
-	var tag string
+	var tag, platformVal string
 	var disablePush bool
 	var liveUpdateVal starlark.Value
 
@@ -201,6 +263,7 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		"tag?", &tag,
 		"disable_push?", &disablePush,
 		"live_update?", &liveUpdateVal,
+		"platform?", &platformVal,
 	)
 	if err != nil {
 		return nil, err
@@ -231,6 +294,11 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		localDeps = append(localDeps, p.path)
 	}
 
+	platform, err := parsePlatform(platformVal)
+	if err != nil {
+		return nil, err
+	}
+
 	liveUpdate, err := s.liveUpdateFromSteps(liveUpdateVal)
 	if err != nil {
 		return nil, errors.Wrap(err, "live_update")
@@ -242,6 +310,7 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		customDeps:       localDeps,
 		customTag:        tag,
 		disablePush:      disablePush,
+		platform:         platform,
 		liveUpdate:       liveUpdate,
 	}
 
@@ -372,6 +441,19 @@ func (s *tiltfileState) cachePathsFromSkylarkValue(val starlark.Value) ([]string
 	return ret, nil
 }
 
+// Accepts a string or a list of strings.
+func stringsFromSkylarkValue(argName string, val starlark.Value) ([]string, error) {
+	var ret []string
+	for _, v := range starlarkValueOrSequenceToSlice(val) {
+		str, ok := v.(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("Argument (%s): %v is a %T; must be a string", argName, v, v)
+		}
+		ret = append(ret, string(str))
+	}
+	return ret, nil
+}
+
 type fastBuild struct {
 	s   *tiltfileState
 	img *dockerImage
@@ -549,6 +631,26 @@ func (s *tiltfileState) defaultRegistry(thread *starlark.Thread, fn *starlark.Bu
 	return starlark.None, nil
 }
 
+func (s *tiltfileState) imagePullSecretFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if s.imagePullSecret != "" {
+		return starlark.None, errors.New("image pull secret already defined")
+	}
+
+	var name string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+
+	errs := validation.IsDNS1123Subdomain(name)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: invalid secret name %q: %s", fn.Name(), name, strings.Join(errs, "; "))
+	}
+
+	s.imagePullSecret = name
+
+	return starlark.None, nil
+}
+
 func (s *tiltfileState) dockerignoresForPaths(paths []string) []model.Dockerignore {
 	var result []model.Dockerignore
 	dupeSet := map[string]bool{}
@@ -597,3 +699,40 @@ func (s *tiltfileState) dockerignoresForImage(image *dockerImage) []model.Docker
 
 	return s.dockerignoresForPaths(paths)
 }
+
+// The files in the build context that the Dockerfile reads, plus the Dockerfile
+// and .dockerignore, so that we only rebuild when one of them changes.
+//
+// Returns nil if the Dockerfile might read any file in the context.
+func contextSourcesForImage(image *dockerImage) []string {
+	sources, ok, err := image.dbDockerfile.ContextSources()
+	if err != nil || !ok {
+		return nil
+	}
+
+	contextDir := image.dbBuildPath.path
+	result := []string{filepath.Join(contextDir, ".dockerignore")}
+	if image.dbDockerfilePath.path != "" {
+		result = append(result, image.dbDockerfilePath.path)
+	}
+	for _, source := range sources {
+		result = append(result, filepath.Join(contextDir, filepath.FromSlash(source)))
+	}
+	return result
+}
+
+// Validates a platform argument like "linux/amd64", and normalizes
+// aliases (e.g., "linux/x86_64" -> "linux/amd64").
+func parsePlatform(val string) (string, error) {
+	if val == "" {
+		return "", nil
+	}
+
+	// platforms.Parse fills in a missing OS with the OS that tilt is running on,
+	// which is almost never what you want when building for a cluster.
+	p, err := platforms.Parse(val)
+	if err != nil || !strings.Contains(val, "/") {
+		return "", fmt.Errorf("Argument (platform): expected a platform like linux/amd64, got %q", val)
+	}
+	return platforms.Format(platforms.Normalize(p)), nil
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/distribution/reference"
 	"github.com/pkg/errors"
@@ -61,11 +62,20 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 	var name string
 	var imageVal starlark.Value
 	var updateMode updateMode
+	var fileQuietPeriodVal starlark.Value
+	var resourceDepsVal, readinessCheckVal starlark.Value
+	var redeployOnDependencyUpdate bool
+	var labelsVal starlark.Value
 
 	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
 		"name", &name,
 		"image", &imageVal, // in future this will be optional
 		"update_mode?", &updateMode,
+		"file_quiet_period_ms?", &fileQuietPeriodVal,
+		"resource_deps?", &resourceDepsVal,
+		"redeploy_on_dependency_update?", &redeployOnDependencyUpdate,
+		"readiness_check?", &readinessCheckVal,
+		"labels?", &labelsVal,
 	); err != nil {
 		return nil, err
 	}
@@ -92,6 +102,23 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 	}
 
 	svc.UpdateMode = updateMode
+	svc.FileQuietPeriod, err = fileQuietPeriodFromStarlark(fn.Name(), fileQuietPeriodVal)
+	if err != nil {
+		return nil, err
+	}
+	svc.ResourceDeps, err = resourceDepsFromStarlark(resourceDepsVal)
+	if err != nil {
+		return nil, err
+	}
+	svc.RedeployOnDepUpdate = redeployOnDependencyUpdate
+	svc.ReadinessCheck, err = readinessCheckFromStarlark(fn.Name(), readinessCheckVal)
+	if err != nil {
+		return nil, err
+	}
+	svc.Labels, err = labelsFromStarlark(fn.Name(), labelsVal)
+	if err != nil {
+		return nil, err
+	}
 
 	normalized, err := container.ParseNamed(imageRefAsStr)
 	if err != nil {
@@ -268,7 +295,12 @@ type dcService struct {
 	DependencyIDs  []model.TargetID
 	PublishedPorts []int
 
-	UpdateMode updateMode
+	UpdateMode          updateMode
+	FileQuietPeriod     time.Duration
+	ResourceDeps        []model.ManifestName
+	RedeployOnDepUpdate bool
+	ReadinessCheck      model.ReadinessCheck
+	Labels              []string
 }
 
 func (c dcConfig) GetService(name string) (dcService, error) {
@@ -415,8 +447,13 @@ func (s *tiltfileState) dcServiceToManifest(service *dcService, dcConfigPath str
 		return model.Manifest{}, nil, err
 	}
 	m := model.Manifest{
-		Name:       model.ManifestName(service.Name),
-		UpdateMode: um,
+		Name:                       model.ManifestName(service.Name),
+		UpdateMode:                 um,
+		FileQuietPeriod:            s.fileQuietPeriodForResource(service.FileQuietPeriod),
+		ResourceDependencies:       service.ResourceDeps,
+		RedeployOnDependencyUpdate: service.RedeployOnDepUpdate,
+		ReadinessCheck:             service.ReadinessCheck,
+		Labels:                     service.Labels,
 	}.WithDeployTarget(dcInfo)
 
 	if service.DfPath == "" {
@@ -0,0 +1,32 @@
+package tiltfile
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"go.starlark.net/starlark"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func (s *tiltfileState) eventSink(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var url, cmd string
+
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"url?", &url,
+		"cmd?", &cmd,
+	); err != nil {
+		return nil, err
+	}
+
+	if (url == "") == (cmd == "") {
+		return nil, fmt.Errorf("%s: must specify exactly one of url or cmd", fn.Name())
+	}
+
+	sink := model.EventSink{URL: url, Cmd: model.ToShellCmd(cmd)}
+	if cmd != "" {
+		sink.Workdir = filepath.Dir(s.filename.path)
+	}
+	s.eventSinks = append(s.eventSinks, sink)
+	return starlark.None, nil
+}
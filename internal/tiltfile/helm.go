@@ -0,0 +1,224 @@
+package tiltfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"go.starlark.net/starlark"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/windmilleng/tilt/internal/container"
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+// The labels that Helm charts conventionally put on the pods of a release,
+// so that we can find the release's pods without injecting our own labels.
+const helmInstanceLabel = "app.kubernetes.io/instance"
+const helmLegacyReleaseLabel = "release"
+
+// A chart that we deploy as a Helm release, rather than applying its YAML.
+type helmRelease struct {
+	release model.HelmRelease
+
+	// The images to point the chart at, resolved to image targets at assembly.
+	imageValues []helmImageValue
+}
+
+type helmImageValue struct {
+	ref           reference.Named
+	key           string
+	repositoryKey string
+	tagKey        string
+}
+
+func (s *tiltfileState) helmReleaseFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, namespace string
+	var chart, valuesVal, setVal starlark.Value
+	var imageValuesVal *starlark.Dict
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"chart", &chart,
+		"namespace?", &namespace,
+		"values?", &valuesVal,
+		"set?", &setVal,
+		"image_values?", &imageValuesVal,
+	); err != nil {
+		return nil, err
+	}
+
+	errs := validation.IsDNS1123Label(name)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: invalid release name %q: %s", fn.Name(), name, strings.Join(errs, "; "))
+	}
+
+	err := validateNamespace(fn.Name(), namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	chartRef, err := s.helmChartRef(chart)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	var valuesFiles []string
+	for _, v := range starlarkValueOrSequenceToSlice(valuesVal) {
+		lp, err := s.localPathFromSkylarkValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: values: %v", fn.Name(), err)
+		}
+		s.recordConfigFile(lp.path)
+		valuesFiles = append(valuesFiles, lp.path)
+	}
+
+	set, err := stringsFromSkylarkValue("set", setVal)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range set {
+		if !strings.Contains(v, "=") {
+			return nil, fmt.Errorf("%s: set: %q must be in the form key=value", fn.Name(), v)
+		}
+	}
+
+	imageValues, err := helmImageValuesFromSkylark(fn.Name(), imageValuesVal)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.makeK8sResource(name)
+	if err != nil {
+		return nil, err
+	}
+	r.helmRelease = &helmRelease{
+		release: model.HelmRelease{
+			Name:        name,
+			Chart:       chartRef,
+			Namespace:   namespace,
+			ValuesFiles: valuesFiles,
+			Set:         set,
+		},
+		imageValues: imageValues,
+	}
+	for _, v := range imageValues {
+		if !r.imageRefMap[v.ref.String()] {
+			r.imageRefMap[v.ref.String()] = true
+			r.imageRefs = append(r.imageRefs, v.ref)
+		}
+	}
+
+	return starlark.None, nil
+}
+
+// A chart is either a path to a local chart (which we watch for changes),
+// or a reference to a chart in a repository (e.g., "stable/redis").
+//
+// A string that can only be a path (e.g., "./chart") has to exist, so that a typo
+// doesn't get sent to Helm as a repository reference.
+func (s *tiltfileState) helmChartRef(chart starlark.Value) (string, error) {
+	str, isString := chart.(starlark.String)
+	if isString {
+		lp := s.localPathFromString(string(str))
+		if _, err := os.Stat(lp.path); err != nil {
+			if os.IsNotExist(err) && looksLikeLocalPath(string(str)) {
+				return "", fmt.Errorf("chart: no chart found at %s", lp.path)
+			}
+			return string(str), nil
+		}
+	}
+
+	lp, err := s.localPathFromSkylarkValue(chart)
+	if err != nil {
+		return "", fmt.Errorf("chart: %v", err)
+	}
+	s.recordConfigFile(lp.path)
+	return lp.path, nil
+}
+
+// Chart repository references never start with a path prefix, so anything that
+// does must be a local chart.
+func looksLikeLocalPath(chart string) bool {
+	return chart == "." || chart == ".." || filepath.IsAbs(chart) ||
+		strings.HasPrefix(chart, "./") || strings.HasPrefix(chart, "../") || strings.HasPrefix(chart, "~")
+}
+
+// Image values map each image to the chart value to set to its ref (e.g., 'frontend.image'),
+// or, for charts that take the repository and tag separately, to a dict
+// of the two values (e.g., {'repository': 'frontend.image.repository', 'tag': 'frontend.image.tag'}).
+func helmImageValuesFromSkylark(fnName string, d *starlark.Dict) ([]helmImageValue, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	var result []helmImageValue
+	for _, item := range d.Items() {
+		image, ok := item[0].(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("%s: image_values: key %v is a %T; must be an image name", fnName, item[0], item[0])
+		}
+		ref, err := container.ParseNamed(string(image))
+		if err != nil {
+			return nil, fmt.Errorf("%s: image_values: invalid image name %q: %v", fnName, image, err)
+		}
+
+		v := helmImageValue{ref: ref}
+		switch val := item[1].(type) {
+		case starlark.String:
+			v.key = string(val)
+		case *starlark.Dict:
+			for _, kv := range val.Items() {
+				k, kOk := kv[0].(starlark.String)
+				key, keyOk := kv[1].(starlark.String)
+				if !kOk || !keyOk {
+					return nil, fmt.Errorf("%s: image_values[%q]: keys and values must be strings", fnName, image)
+				}
+				switch string(k) {
+				case "repository":
+					v.repositoryKey = string(key)
+				case "tag":
+					v.tagKey = string(key)
+				default:
+					return nil, fmt.Errorf("%s: image_values[%q]: unknown key %q (expected 'repository' or 'tag')", fnName, image, k)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%s: image_values[%q] is a %T; must be a string or a dict", fnName, image, item[1])
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// Resolves the images of the release to the image targets that build them.
+func (s *tiltfileState) helmReleaseForResource(r *k8sResource) (model.HelmRelease, error) {
+	release := r.helmRelease.release
+	if release.Namespace == "" {
+		release.Namespace = r.namespace
+	}
+
+	for _, v := range r.helmRelease.imageValues {
+		builder := s.buildIndex.findBuilderForConsumedImage(v.ref)
+		if builder == nil {
+			return model.HelmRelease{}, fmt.Errorf("helm_release %q: image_values: no image build for %q; "+
+				"did you forget a docker_build()?", release.Name, v.ref.String())
+		}
+		release.ImageValues = append(release.ImageValues, model.HelmImageValue{
+			ImageID:       builder.ID(),
+			Key:           v.key,
+			RepositoryKey: v.repositoryKey,
+			TagKey:        v.tagKey,
+		})
+	}
+	return release, nil
+}
+
+func helmReleasePodSelectors(name string) []labels.Selector {
+	return []labels.Selector{
+		labels.SelectorFromSet(labels.Set{helmInstanceLabel: name}),
+		labels.SelectorFromSet(labels.Set{helmLegacyReleaseLabel: name}),
+	}
+}
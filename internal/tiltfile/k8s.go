@@ -2,9 +2,11 @@ package tiltfile
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/windmilleng/tilt/internal/sliceutils"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/k8s"
@@ -49,6 +52,48 @@ type k8sResource struct {
 	dependencyIDs []model.TargetID
 
 	updateMode updateMode
+
+	// how long to wait for files to stop changing before updating, or 0 for the Tiltfile's default
+	fileQuietPeriod time.Duration
+
+	// resources that have to be ready before this one deploys (and whether
+	// to redeploy this one when they do), and how to tell when this one is
+	// ready, on top of its pods being ready
+	resourceDeps        []model.ManifestName
+	redeployOnDepUpdate bool
+	readinessCheck      model.ReadinessCheck
+
+	// groups for the UIs (see model.ValidateResourceLabel)
+	labels []string
+
+	// if non-empty, the namespace to deploy all the entities into
+	namespace string
+
+	// names of the containers to stream logs from (if empty, all of them),
+	// and containers to never stream logs from
+	logContainers        []string
+	ignoredLogContainers []string
+
+	// names of containers that aren't part of the app, on top of the ones
+	// a service mesh injected (unless we shouldn't look for those)
+	sidecarContainers       []string
+	disableSidecarDetection bool
+
+	// if set, we deploy this resource as a Helm release, and it has no entities
+	helmRelease *helmRelease
+
+	// if non-empty, the kube context to deploy this resource to
+	kubeContext string
+
+	// the kubeconfig and credentials to deploy this resource with,
+	// on top of the ones from k8s_credentials
+	credentials k8sCredentials
+
+	// labels and annotations to add to the resource's objects, on top of the ones
+	// from k8s_object_metadata, and the label that ties the objects to the resource
+	objectLabels      map[string]string
+	objectAnnotations map[string]string
+	manifestLabel     string
 }
 
 const deprecatedResourceAssemblyV1Warning = "This Tiltfile is using k8s resource assembly version 1, which has been " +
@@ -57,12 +102,27 @@ const deprecatedResourceAssemblyV1Warning = "This Tiltfile is using k8s resource
 // holds options passed to `k8s_resource` until assembly happens
 type k8sResourceOptions struct {
 	// if non-empty, how to rename this resource
-	newName           string
-	portForwards      []portForward
-	extraPodSelectors []labels.Selector
-	updateMode        updateMode
-	tiltfilePosition  syntax.Position
-	consumed          bool
+	newName                 string
+	portForwards            []portForward
+	extraPodSelectors       []labels.Selector
+	updateMode              updateMode
+	fileQuietPeriod         time.Duration
+	resourceDeps            []model.ManifestName
+	redeployOnDepUpdate     bool
+	readinessCheck          model.ReadinessCheck
+	labels                  []string
+	logContainers           []string
+	ignoredLogContainers    []string
+	sidecarContainers       []string
+	disableSidecarDetection bool
+	namespace               string
+	kubeContext             string
+	credentials             k8sCredentials
+	objectLabels            map[string]string
+	objectAnnotations       map[string]string
+	manifestLabel           string
+	tiltfilePosition        syntax.Position
+	consumed                bool
 }
 
 func (r *k8sResource) addRefSelector(selector container.RefSelector) {
@@ -276,9 +336,9 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 }
 
 // v1 syntax:
-// `k8s_resource(name, yaml='', image='', port_forwards=[], extra_pod_selectors=[])`
+// `k8s_resource(name, yaml=”, image=”, port_forwards=[], extra_pod_selectors=[])`
 // v2 syntax:
-// `k8s_resource(workload, new_name='', port_forwards=[], extra_pod_selectors=[])`
+// `k8s_resource(workload, new_name=”, port_forwards=[], extra_pod_selectors=[])`
 // this function tries to tell if they're still using a v1 tiltfile after we made v2 the default
 func (s *tiltfileState) isProbablyK8SResourceV1Call(args starlark.Tuple, kwargs []starlark.Tuple) (bool, string) {
 	var k8sResourceV1OnlyNames = map[string]bool{
@@ -329,6 +389,19 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 	var portForwardsVal starlark.Value
 	var extraPodSelectorsVal starlark.Value
 	var updateMode updateMode
+	var logContainersVal, ignoredLogContainersVal starlark.Value
+	var sidecarContainersVal starlark.Value
+	detectSidecars := true
+	var namespace string
+	var kubeContext string
+	var kubeConfig, kubeUser, impersonateUser string
+	var impersonateGroupsVal starlark.Value
+	var objectLabelsVal, objectAnnotationsVal starlark.Value
+	var manifestLabel string
+	var fileQuietPeriodVal starlark.Value
+	var resourceDepsVal, readinessCheckVal starlark.Value
+	var redeployOnDependencyUpdate bool
+	var labelsVal starlark.Value
 
 	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
 		"workload", &workload,
@@ -336,6 +409,24 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		"port_forwards?", &portForwardsVal,
 		"extra_pod_selectors?", &extraPodSelectorsVal,
 		"update_mode?", &updateMode,
+		"log_containers?", &logContainersVal,
+		"ignore_log_containers?", &ignoredLogContainersVal,
+		"sidecar_containers?", &sidecarContainersVal,
+		"detect_sidecars?", &detectSidecars,
+		"namespace?", &namespace,
+		"kube_context?", &kubeContext,
+		"kubeconfig?", &kubeConfig,
+		"kube_user?", &kubeUser,
+		"impersonate_user?", &impersonateUser,
+		"impersonate_groups?", &impersonateGroupsVal,
+		"object_labels?", &objectLabelsVal,
+		"object_annotations?", &objectAnnotationsVal,
+		"manifest_label?", &manifestLabel,
+		"file_quiet_period_ms?", &fileQuietPeriodVal,
+		"resource_deps?", &resourceDepsVal,
+		"redeploy_on_dependency_update?", &redeployOnDependencyUpdate,
+		"readiness_check?", &readinessCheckVal,
+		"labels?", &labelsVal,
 	); err != nil {
 		return nil, err
 	}
@@ -344,6 +435,10 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		return nil, fmt.Errorf("%s: workload must not be empty", fn.Name())
 	}
 
+	if err := validateNamespace(fn.Name(), namespace); err != nil {
+		return nil, err
+	}
+
 	portForwards, err := convertPortForwards(portForwardsVal)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s %q", fn.Name(), workload)
@@ -354,16 +449,76 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		return nil, err
 	}
 
+	logContainers, err := stringsFromSkylarkValue("log_containers", logContainersVal)
+	if err != nil {
+		return nil, err
+	}
+	ignoredLogContainers, err := stringsFromSkylarkValue("ignore_log_containers", ignoredLogContainersVal)
+	if err != nil {
+		return nil, err
+	}
+
+	sidecarContainers, err := stringsFromSkylarkValue("sidecar_containers", sidecarContainersVal)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.credentialsFromStarlark(fn.Name(), kubeConfig, kubeUser, impersonateUser, impersonateGroupsVal)
+	if err != nil {
+		return nil, err
+	}
+
+	objectLabels, objectAnnotations, err := objectMetadataFromStarlark(fn.Name(), objectLabelsVal, objectAnnotationsVal, manifestLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	fileQuietPeriod, err := fileQuietPeriodFromStarlark(fn.Name(), fileQuietPeriodVal)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceDeps, err := resourceDepsFromStarlark(resourceDepsVal)
+	if err != nil {
+		return nil, err
+	}
+
+	readinessCheck, err := readinessCheckFromStarlark(fn.Name(), readinessCheckVal)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceLabels, err := labelsFromStarlark(fn.Name(), labelsVal)
+	if err != nil {
+		return nil, err
+	}
+
 	if opts, ok := s.k8sResourceOptions[workload]; ok {
 		return nil, fmt.Errorf("%s already called for %s, at %s", fn.Name(), workload, opts.tiltfilePosition.String())
 	}
 
 	s.k8sResourceOptions[workload] = k8sResourceOptions{
-		newName:           newName,
-		portForwards:      portForwards,
-		extraPodSelectors: extraPodSelectors,
-		tiltfilePosition:  thread.Caller().Position(),
-		updateMode:        updateMode,
+		newName:             newName,
+		portForwards:        portForwards,
+		extraPodSelectors:   extraPodSelectors,
+		tiltfilePosition:    thread.Caller().Position(),
+		updateMode:          updateMode,
+		fileQuietPeriod:     fileQuietPeriod,
+		resourceDeps:        resourceDeps,
+		redeployOnDepUpdate: redeployOnDependencyUpdate,
+		readinessCheck:      readinessCheck,
+		labels:              resourceLabels,
+
+		logContainers:           logContainers,
+		ignoredLogContainers:    ignoredLogContainers,
+		sidecarContainers:       sidecarContainers,
+		disableSidecarDetection: !detectSidecars,
+		namespace:               namespace,
+		kubeContext:             kubeContext,
+		credentials:             credentials,
+		objectLabels:            objectLabels,
+		objectAnnotations:       objectAnnotations,
+		manifestLabel:           manifestLabel,
 	}
 
 	return starlark.None, nil
@@ -398,8 +553,8 @@ func podLabelsFromStarlarkValue(v starlark.Value) ([]labels.Selector, error) {
 	}
 
 	switch x := v.(type) {
-	case *starlark.Dict:
-		s, err := selectorFromSkylarkDict(x)
+	case *starlark.Dict, starlark.String:
+		s, err := podSelectorFromStarlarkValue(x)
 		if err != nil {
 			return nil, err
 		} else if s == nil {
@@ -414,11 +569,7 @@ func podLabelsFromStarlarkValue(v starlark.Value) ([]labels.Selector, error) {
 		defer it.Done()
 		var i starlark.Value
 		for it.Next(&i) {
-			d, ok := i.(*starlark.Dict)
-			if !ok {
-				return nil, fmt.Errorf("pod labels elements must be dicts; got %T", i)
-			}
-			s, err := selectorFromSkylarkDict(d)
+			s, err := podSelectorFromStarlarkValue(i)
 			if err != nil {
 				return nil, err
 			} else if s != nil {
@@ -428,7 +579,27 @@ func podLabelsFromStarlarkValue(v starlark.Value) ([]labels.Selector, error) {
 
 		return ret, nil
 	default:
-		return nil, fmt.Errorf("pod labels must be a dict or a list; got %T", v)
+		return nil, fmt.Errorf("pod labels must be a dict, a string, or a list; got %T", v)
+	}
+}
+
+// A pod selector is either a dict of labels that pods must have,
+// or a string in kubectl's label selector syntax (e.g., "app in (web, api),tier!=cache").
+func podSelectorFromStarlarkValue(v starlark.Value) (labels.Selector, error) {
+	switch x := v.(type) {
+	case *starlark.Dict:
+		return selectorFromSkylarkDict(x)
+	case starlark.String:
+		if x.GoString() == "" {
+			return nil, nil
+		}
+		s, err := labels.Parse(x.GoString())
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pod selector %q", x.GoString())
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("pod labels elements must be dicts or strings; got %T", v)
 	}
 }
 
@@ -495,28 +666,273 @@ func (s *tiltfileState) k8sKind(thread *starlark.Thread, fn *starlark.Builtin, a
 	var imageJSONPath starlark.Value
 	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
 		"kind", &kind,
-		"image_json_path", &imageJSONPath,
+		"image_json_path?", &imageJSONPath,
 		"api_version?", &apiVersion,
 	); err != nil {
 		return nil, err
 	}
 
-	values := starlarkValueOrSequenceToSlice(imageJSONPath)
-	paths, err := starlarkValuesToJSONPaths(values)
+	k, err := newK8SObjectSelector(apiVersion, kind, "", "")
 	if err != nil {
 		return nil, err
 	}
 
-	k, err := newK8SObjectSelector(apiVersion, kind, "", "")
+	// Without an image_json_path, we find images in any pod templates embedded in the object.
+	if imageJSONPath != nil {
+		values := starlarkValueOrSequenceToSlice(imageJSONPath)
+		paths, err := starlarkValuesToJSONPaths(values)
+		if err != nil {
+			return nil, err
+		}
+		s.k8sImageJSONPaths[k] = paths
+	}
+
+	s.k8sKinds = append(s.k8sKinds, k)
+
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) defaultNamespaceFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if s.defaultNamespace != "" {
+		return starlark.None, errors.New("default namespace already defined")
+	}
+
+	var name string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("%s: name must not be empty", fn.Name())
+	}
+	if err := validateNamespace(fn.Name(), name); err != nil {
+		return nil, err
+	}
+
+	s.defaultNamespace = name
+
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) k8sServerSideApplyFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var forceConflicts bool
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "force_conflicts?", &forceConflicts); err != nil {
+		return nil, err
+	}
+
+	s.serverSideApply = true
+	s.forceApplyConflicts = forceConflicts
+
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) k8sCredentialsFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var kubeConfig, user, impersonateUser string
+	var impersonateGroupsVal starlark.Value
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"kubeconfig?", &kubeConfig,
+		"user?", &user,
+		"impersonate_user?", &impersonateUser,
+		"impersonate_groups?", &impersonateGroupsVal,
+	); err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.credentialsFromStarlark(fn.Name(), kubeConfig, user, impersonateUser, impersonateGroupsVal)
 	if err != nil {
 		return nil, err
 	}
+	if len(credentials.impersonateGroups) > 0 && credentials.impersonateUser == "" {
+		return nil, fmt.Errorf("%s: impersonate_groups requires impersonate_user", fn.Name())
+	}
 
-	s.k8sImageJSONPaths[k] = paths
+	s.credentials = credentials
 
 	return starlark.None, nil
 }
 
+// The kubeconfig and credentials to deploy with, if not the developer's own.
+type k8sCredentials struct {
+	kubeConfigPath    string
+	user              string
+	impersonateUser   string
+	impersonateGroups []string
+}
+
+// Returns these credentials, with any fields that override sets replaced.
+func (c k8sCredentials) merge(override k8sCredentials) k8sCredentials {
+	if override.kubeConfigPath != "" {
+		c.kubeConfigPath = override.kubeConfigPath
+	}
+	if override.user != "" {
+		c.user = override.user
+	}
+	if override.impersonateUser != "" {
+		c.impersonateUser = override.impersonateUser
+	}
+	if len(override.impersonateGroups) > 0 {
+		c.impersonateGroups = override.impersonateGroups
+	}
+	return c
+}
+
+func (s *tiltfileState) credentialsFromStarlark(fnName string, kubeConfig, user, impersonateUser string, impersonateGroupsVal starlark.Value) (k8sCredentials, error) {
+	impersonateGroups, err := stringsFromSkylarkValue("impersonate_groups", impersonateGroupsVal)
+	if err != nil {
+		return k8sCredentials{}, errors.Wrap(err, fnName)
+	}
+	for _, g := range impersonateGroups {
+		if g == "" || strings.Contains(g, ",") {
+			return k8sCredentials{}, fmt.Errorf("%s: invalid impersonate_groups entry %q", fnName, g)
+		}
+	}
+
+	if kubeConfig != "" {
+		kubeConfig = s.absPath(kubeConfig)
+		if _, err := os.Stat(kubeConfig); err != nil {
+			return k8sCredentials{}, errors.Wrapf(err, "%s: kubeconfig", fnName)
+		}
+		s.recordConfigFile(kubeConfig)
+	}
+
+	return k8sCredentials{
+		kubeConfigPath:    kubeConfig,
+		user:              user,
+		impersonateUser:   impersonateUser,
+		impersonateGroups: impersonateGroups,
+	}, nil
+}
+
+func (s *tiltfileState) k8sObjectMetadataFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var labelsVal, annotationsVal starlark.Value
+	var manifestLabel string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"labels?", &labelsVal,
+		"annotations?", &annotationsVal,
+		"manifest_label?", &manifestLabel,
+	); err != nil {
+		return nil, err
+	}
+
+	objectLabels, objectAnnotations, err := objectMetadataFromStarlark(fn.Name(), labelsVal, annotationsVal, manifestLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	s.objectLabels = objectLabels
+	s.objectAnnotations = objectAnnotations
+	s.manifestLabel = manifestLabel
+
+	return starlark.None, nil
+}
+
+// Parses and validates the labels and annotations to add to deployed objects,
+// and the label to tie them to their resource with.
+func objectMetadataFromStarlark(fnName string, labelsVal, annotationsVal starlark.Value, manifestLabel string) (map[string]string, map[string]string, error) {
+	objectLabels, err := stringMapFromStarlarkValue(fnName, "labels", labelsVal)
+	if err != nil {
+		return nil, nil, err
+	}
+	objectAnnotations, err := stringMapFromStarlarkValue(fnName, "annotations", annotationsVal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for k, v := range objectLabels {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return nil, nil, fmt.Errorf("%s: invalid label key %q: %s", fnName, k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return nil, nil, fmt.Errorf("%s: invalid value for label %q: %s", fnName, k, strings.Join(errs, "; "))
+		}
+		if k == k8s.TiltRunIDLabel || k == k8s.TiltDeployIDLabel || k == k8s.TiltProjectIDLabel {
+			return nil, nil, fmt.Errorf("%s: can't set label %q, which Tilt uses to track deployed objects", fnName, k)
+		}
+	}
+	for k := range objectAnnotations {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return nil, nil, fmt.Errorf("%s: invalid annotation key %q: %s", fnName, k, strings.Join(errs, "; "))
+		}
+	}
+	if manifestLabel != "" {
+		if errs := validation.IsQualifiedName(manifestLabel); len(errs) > 0 {
+			return nil, nil, fmt.Errorf("%s: invalid manifest_label %q: %s", fnName, manifestLabel, strings.Join(errs, "; "))
+		}
+	}
+
+	return objectLabels, objectAnnotations, nil
+}
+
+func stringMapFromStarlarkValue(fnName string, argName string, v starlark.Value) (map[string]string, error) {
+	if v == nil || v == starlark.None {
+		return nil, nil
+	}
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s must be a dict; got %T", fnName, argName, v)
+	}
+	result, err := skylarkStringDictToGoMap(d)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: %s", fnName, argName)
+	}
+	return result, nil
+}
+
+// The labels and annotations for a resource's objects: the resource's own, on top of the global ones.
+func (s *tiltfileState) objectMetadataForResource(r *k8sResource) ([]model.LabelPair, []model.LabelPair, string, error) {
+	manifestLabel := r.manifestLabel
+	if manifestLabel == "" {
+		manifestLabel = s.manifestLabel
+	}
+
+	objectLabels := mergeStringMaps(s.objectLabels, r.objectLabels)
+	if _, ok := objectLabels[manifestLabel]; ok && manifestLabel != "" {
+		return nil, nil, "", fmt.Errorf("resource %q: label %q is its manifest_label, so Tilt sets it to the resource name", r.name, manifestLabel)
+	}
+	if _, ok := objectLabels[k8s.ManifestNameLabel]; ok && manifestLabel == "" {
+		return nil, nil, "", fmt.Errorf("resource %q: can't set label %q, which Tilt uses to track deployed objects. "+
+			"To use a different label, set manifest_label", r.name, k8s.ManifestNameLabel)
+	}
+
+	return sortedLabelPairs(objectLabels), sortedLabelPairs(mergeStringMaps(s.objectAnnotations, r.objectAnnotations)), manifestLabel, nil
+}
+
+func mergeStringMaps(base map[string]string, overrides map[string]string) map[string]string {
+	result := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range overrides {
+		result[k] = v
+	}
+	return result
+}
+
+func sortedLabelPairs(m map[string]string) []model.LabelPair {
+	if len(m) == 0 {
+		return nil
+	}
+	result := make([]model.LabelPair, 0, len(m))
+	for k, v := range m {
+		result = append(result, model.LabelPair{Key: k, Value: v})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+func validateNamespace(fnName string, ns string) error {
+	if ns == "" {
+		return nil
+	}
+	errs := validation.IsDNS1123Label(ns)
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: invalid namespace %q: %s", fnName, ns, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 func (s *tiltfileState) workloadToResourceFunctionFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var wtrf *starlark.Function
 	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
@@ -728,22 +1144,29 @@ func convertPortForwards(val starlark.Value) ([]portForward, error) {
 func (s *tiltfileState) portForward(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var local int
 	var container int
+	var service string
 
-	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "local", &local, "container?", &container); err != nil {
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "local", &local, "container?", &container, "service?", &service); err != nil {
 		return nil, err
 	}
 
-	return portForward{local: local, container: container}, nil
+	return portForward{local: local, container: container, service: service}, nil
 }
 
 type portForward struct {
 	local     int
 	container int
+
+	// If set, container is a port on this Service.
+	service string
 }
 
 var _ starlark.Value = portForward{}
 
 func (f portForward) String() string {
+	if f.service != "" {
+		return fmt.Sprintf("port_forward(%d, %d, service=%q)", f.local, f.container, f.service)
+	}
 	return fmt.Sprintf("port_forward(%d, %d)", f.local, f.container)
 }
 
@@ -792,11 +1215,39 @@ func stringToPortForward(s starlark.String) (portForward, error) {
 func (s *tiltfileState) portForwardsToDomain(r *k8sResource) []model.PortForward {
 	var result []model.PortForward
 	for _, pf := range r.portForwards {
-		result = append(result, model.PortForward{LocalPort: pf.local, ContainerPort: pf.container})
+		result = append(result, model.PortForward{
+			LocalPort:        pf.local,
+			ContainerPort:    pf.container,
+			Service:          pf.service,
+			ServiceNamespace: s.serviceNamespace(r, pf.service),
+		})
 	}
 	return result
 }
 
+// Looks up the namespace of a Service in the Tiltfile's YAML.
+// The Service might be deployed by something else (e.g., a Helm chart installed out-of-band),
+// in which case we fall back to the resource's namespace.
+//
+// Returns empty if the Service doesn't set a namespace, so that the k8s client
+// looks in the namespace of the kubectl context it's configured with.
+func (s *tiltfileState) serviceNamespace(r *k8sResource, name string) string {
+	if name == "" {
+		return ""
+	}
+
+	entities := append([]k8s.K8sEntity{}, s.k8sUnresourced...)
+	for _, r := range s.k8s {
+		entities = append(entities, r.entities...)
+	}
+	for _, e := range entities {
+		if e.HasKind("Service") && e.HasName(name) {
+			return string(e.ExplicitNamespace())
+		}
+	}
+	return r.namespace
+}
+
 // returns any defined image JSON paths that apply to the given entity
 func (s *tiltfileState) imageJSONPaths(e k8s.K8sEntity) []k8s.JSONPath {
 	var ret []k8s.JSONPath
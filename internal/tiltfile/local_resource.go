@@ -0,0 +1,145 @@
+package tiltfile
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"go.starlark.net/starlark"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+type localResource struct {
+	name                string
+	cmd                 model.Cmd
+	serveCmd            model.Cmd
+	deps                []localPath
+	updateMode          updateMode
+	resourceDeps        []model.ManifestName
+	redeployOnDepUpdate bool
+	readinessCheck      model.ReadinessCheck
+	labels              []string
+}
+
+func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, cmd, serveCmd string
+	var depsVal, resourceDepsVal, readinessCheckVal, labelsVal starlark.Value
+	var updateMode updateMode
+	var redeployOnDependencyUpdate bool
+
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"cmd?", &cmd,
+		"deps?", &depsVal,
+		"serve_cmd?", &serveCmd,
+		"update_mode?", &updateMode,
+		"resource_deps?", &resourceDepsVal,
+		"redeploy_on_dependency_update?", &redeployOnDependencyUpdate,
+		"readiness_check?", &readinessCheckVal,
+		"labels?", &labelsVal,
+	); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("%s: `name` must not be empty", fn.Name())
+	}
+	if cmd == "" && serveCmd == "" {
+		return nil, fmt.Errorf("%s: must specify a cmd, a serve_cmd, or both", fn.Name())
+	}
+	for _, r := range s.localResources {
+		if r.name == name {
+			return nil, fmt.Errorf("%s named %q already exists", fn.Name(), name)
+		}
+	}
+
+	deps, err := s.localPathsFromStarlark(depsVal)
+	if err != nil {
+		return nil, fmt.Errorf("%s: deps: %v", fn.Name(), err)
+	}
+
+	resourceDeps, err := resourceDepsFromStarlark(resourceDepsVal)
+	if err != nil {
+		return nil, err
+	}
+
+	readinessCheck, err := readinessCheckFromStarlark(fn.Name(), readinessCheckVal)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := labelsFromStarlark(fn.Name(), labelsVal)
+	if err != nil {
+		return nil, err
+	}
+
+	s.localResources = append(s.localResources, &localResource{
+		name:                name,
+		cmd:                 model.ToShellCmd(cmd),
+		serveCmd:            model.ToShellCmd(serveCmd),
+		deps:                deps,
+		updateMode:          updateMode,
+		resourceDeps:        resourceDeps,
+		redeployOnDepUpdate: redeployOnDependencyUpdate,
+		readinessCheck:      readinessCheck,
+		labels:              labels,
+	})
+
+	return starlark.None, nil
+}
+
+// Takes a single path or a list of them.
+func (s *tiltfileState) localPathsFromStarlark(v starlark.Value) ([]localPath, error) {
+	var values []starlark.Value
+	switch v := v.(type) {
+	case nil, starlark.NoneType:
+		return nil, nil
+	case *starlark.List:
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, v.Index(i))
+		}
+	default:
+		values = []starlark.Value{v}
+	}
+
+	var result []localPath
+	for _, value := range values {
+		lp, err := s.localPathFromSkylarkValue(value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, lp)
+	}
+	return result, nil
+}
+
+func (s *tiltfileState) translateLocal() ([]model.Manifest, error) {
+	var result []model.Manifest
+	for _, r := range s.localResources {
+		um, err := starlarkUpdateModeToModel(s.updateModeForResource(r.updateMode))
+		if err != nil {
+			return nil, err
+		}
+
+		var paths []string
+		for _, dep := range r.deps {
+			paths = append(paths, dep.path)
+		}
+
+		lt := model.NewLocalTarget(model.TargetName(r.name), r.cmd, r.serveCmd, paths, filepath.Dir(s.filename.path)).
+			WithRepos(reposForPaths(r.deps))
+
+		m := model.Manifest{
+			Name:                       model.ManifestName(r.name),
+			UpdateMode:                 um,
+			FileQuietPeriod:            s.fileQuietPeriodForResource(0),
+			ResourceDependencies:       r.resourceDeps,
+			RedeployOnDependencyUpdate: r.redeployOnDepUpdate,
+			ReadinessCheck:             r.readinessCheck,
+			Labels:                     r.labels,
+		}.WithDeployTarget(lt)
+
+		result = append(result, m)
+	}
+	return result, nil
+}
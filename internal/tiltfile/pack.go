@@ -0,0 +1,112 @@
+package tiltfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+
+	"github.com/windmilleng/tilt/internal/container"
+)
+
+// Builds an image with Cloud Native Buildpacks, for apps that don't have a Dockerfile.
+//
+// This is sugar over custom_build: we run `pack build` against the local docker daemon,
+// so its output streams into the build log like any other custom build.
+func (s *tiltfileState) packBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var dockerRef, builder string
+	var pathVal, buildpacksVal, envVal, liveUpdateVal starlark.Value
+	var disablePush bool
+
+	err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"ref", &dockerRef,
+		"path", &pathVal,
+		"builder", &builder,
+		"buildpacks?", &buildpacksVal,
+		"env?", &envVal,
+		"live_update?", &liveUpdateVal,
+		"disable_push?", &disablePush,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := reference.ParseNormalizedNamed(dockerRef)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 1 (ref): can't parse %q: %v", dockerRef, err)
+	}
+
+	path, err := s.localPathFromSkylarkValue(pathVal)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 2 (path): %v", err)
+	}
+
+	if builder == "" {
+		return nil, fmt.Errorf("Argument 3 (builder) can't be empty")
+	}
+
+	buildpacks, err := stringsFromSkylarkValue("buildpacks", buildpacksVal)
+	if err != nil {
+		return nil, err
+	}
+
+	var env map[string]string
+	if envVal != nil {
+		d, ok := envVal.(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("Argument (env): expected dict, got %T", envVal)
+		}
+		env, err = skylarkStringDictToGoMap(d)
+		if err != nil {
+			return nil, fmt.Errorf("Argument (env): %v", err)
+		}
+	}
+
+	liveUpdate, err := s.liveUpdateFromSteps(liveUpdateVal)
+	if err != nil {
+		return nil, errors.Wrap(err, "live_update")
+	}
+
+	img := &dockerImage{
+		configurationRef: container.NewRefSelector(ref),
+		customCommand:    packBuildCommand(path.path, builder, buildpacks, env),
+		customDeps:       []string{path.path},
+		disablePush:      disablePush,
+		liveUpdate:       liveUpdate,
+	}
+
+	err = s.buildIndex.addImage(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.None, nil
+}
+
+func packBuildCommand(path, builder string, buildpacks []string, env map[string]string) string {
+	args := []string{"pack", "build", `"$EXPECTED_REF"`,
+		"--path", shellQuote(path),
+		"--builder", shellQuote(builder),
+	}
+	for _, bp := range buildpacks {
+		args = append(args, "--buildpack", shellQuote(bp))
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--env", shellQuote(fmt.Sprintf("%s=%s", k, env[k])))
+	}
+	return strings.Join(args, " ")
+}
+
+// Quote a string so that `sh -c` passes it through as a single argument.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
@@ -0,0 +1,80 @@
+package tiltfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+type readinessCheck struct {
+	check model.ReadinessCheck
+}
+
+var _ starlark.Value = readinessCheck{}
+
+func (c readinessCheck) String() string {
+	return fmt.Sprintf("readiness_check(%s)", c.check)
+}
+
+func (c readinessCheck) Type() string {
+	return "readiness_check"
+}
+
+func (c readinessCheck) Freeze() {}
+
+func (c readinessCheck) Truth() starlark.Bool {
+	return starlark.Bool(!c.check.Empty())
+}
+
+func (c readinessCheck) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: readiness_check")
+}
+
+func (s *tiltfileState) readinessCheck(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var httpGet, execCmd, logRegex string
+	var periodMs, timeoutMs int
+
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"http_get?", &httpGet,
+		"exec?", &execCmd,
+		"log_regex?", &logRegex,
+		"period_ms?", &periodMs,
+		"timeout_ms?", &timeoutMs,
+	); err != nil {
+		return nil, err
+	}
+
+	check := model.ReadinessCheck{
+		HTTPGet:  httpGet,
+		Exec:     model.ToShellCmd(execCmd),
+		LogRegex: logRegex,
+		Period:   time.Duration(periodMs) * time.Millisecond,
+		Timeout:  time.Duration(timeoutMs) * time.Millisecond,
+	}
+	if !check.Exec.Empty() {
+		check.Workdir = filepath.Dir(s.filename.path)
+	}
+	if check.Empty() {
+		return nil, fmt.Errorf("%s: must specify one of http_get, exec, or log_regex", fn.Name())
+	}
+	if err := check.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	return readinessCheck{check: check}, nil
+}
+
+func readinessCheckFromStarlark(fnName string, v starlark.Value) (model.ReadinessCheck, error) {
+	switch x := v.(type) {
+	case nil, starlark.NoneType:
+		return model.ReadinessCheck{}, nil
+	case readinessCheck:
+		return x.check, nil
+	default:
+		return model.ReadinessCheck{}, fmt.Errorf("%s: readiness_check must be a readiness_check; got %s", fnName, v.Type())
+	}
+}
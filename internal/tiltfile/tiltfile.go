@@ -34,6 +34,20 @@ type TiltfileLoadResult struct {
 	ConfigFiles        []string
 	Warnings           []string
 	TiltIgnoreContents string
+
+	// How many resources Tilt can update at once (see update_settings),
+	// or 0 for the default.
+	MaxParallelUpdates int
+
+	// Where to send build and deploy events (see event_sink).
+	EventSinks []model.EventSink
+
+	// How to watch files (see watch_settings).
+	WatchSettings model.WatchSettings
+
+	// Every resource in the Tiltfile, including the ones that
+	// weren't in `matching`.
+	AllManifestNames []model.ManifestName
 }
 
 type TiltfileLoader interface {
@@ -127,11 +141,29 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching ma
 		}
 	}
 
+	localManifests, err := s.translateLocal()
+	if err != nil {
+		return TiltfileLoadResult{}, err
+	}
+	for _, lm := range localManifests {
+		for _, m := range manifests {
+			if m.Name == lm.Name {
+				return TiltfileLoadResult{}, fmt.Errorf("%s: there's already a resource named %q", localResourceN, lm.Name)
+			}
+		}
+	}
+	manifests = append(manifests, localManifests...)
+
 	err = s.checkForUnconsumedLiveUpdateSteps()
 	if err != nil {
 		return TiltfileLoadResult{}, err
 	}
 
+	allManifestNames := make([]model.ManifestName, 0, len(manifests))
+	for _, m := range manifests {
+		allManifestNames = append(allManifestNames, m.Name)
+	}
+
 	manifests, err = match(manifests, matching)
 	if err != nil {
 		return TiltfileLoadResult{}, err
@@ -143,6 +175,13 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching ma
 		if err != nil {
 			return TiltfileLoadResult{}, err
 		}
+		yamlManifest = yamlManifest.WithDeployTarget(yamlManifest.K8sTarget().
+			WithNamespace(s.defaultNamespace).
+			WithServerSideApply(s.serverSideApply, s.forceApplyConflicts).
+			WithDeployRetry(s.deployRetry).
+			WithCredentials(s.credentials.kubeConfigPath, s.credentials.user, s.credentials.impersonateUser, s.credentials.impersonateGroups).
+			WithObjectMetadata(sortedLabelPairs(s.objectLabels), sortedLabelPairs(s.objectAnnotations), s.manifestLabel).
+			WithProject(k8s.TiltProjectID(s.filename.path)))
 		manifests = append(manifests, yamlManifest)
 	}
 
@@ -165,7 +204,7 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, matching ma
 		return TiltfileLoadResult{}, errors.Wrapf(err, "error reading %s", tiltIgnorePath(filename))
 	}
 
-	return TiltfileLoadResult{manifests, s.configFiles, s.warnings, string(tiltIgnoreContents)}, err
+	return TiltfileLoadResult{manifests, s.configFiles, s.warnings, string(tiltIgnoreContents), s.maxParallelUpdates, s.eventSinks, s.watchSettings, allManifestNames}, err
 }
 
 // .tiltignore sits next to Tiltfile
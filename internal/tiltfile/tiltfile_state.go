@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"go.starlark.net/syntax"
 
@@ -15,6 +16,7 @@ import (
 
 	"github.com/windmilleng/tilt/internal/container"
 	"github.com/windmilleng/tilt/internal/dockercompose"
+	"github.com/windmilleng/tilt/internal/dockerfile"
 	"github.com/windmilleng/tilt/internal/k8s"
 	"github.com/windmilleng/tilt/internal/logger"
 	"github.com/windmilleng/tilt/internal/model"
@@ -45,9 +47,31 @@ type tiltfileState struct {
 	// ensure that any pushed images are pushed instead to this registry, rewriting names if needed
 	defaultRegistryHost string
 
+	// name of the image pull secret to generate from local docker credentials, if any
+	imagePullSecret string
+
+	// namespace to deploy any k8s resource into, unless k8s_resource says otherwise
+	defaultNamespace string
+
+	// deploy k8s resources with server-side apply
+	serverSideApply     bool
+	forceApplyConflicts bool
+
+	// labels and annotations to add to every deployed object, and the label that
+	// ties objects to their resource (if not the default)
+	objectLabels      map[string]string
+	objectAnnotations map[string]string
+	manifestLabel     string
+
+	// kubeconfig and credentials to deploy every k8s resource with, unless k8s_resource says otherwise
+	credentials k8sCredentials
+
 	// JSON paths to images in k8s YAML (other than Container specs)
 	k8sImageJSONPaths map[k8sObjectSelector][]k8s.JSONPath
 
+	// Kinds registered with k8s_kind, which are always workloads
+	k8sKinds []k8sObjectSelector
+
 	k8sResourceAssemblyVersion       int
 	k8sResourceAssemblyVersionReason k8sResourceAssemblyVersionReason
 	workloadToResourceFunction       workloadToResourceFunction
@@ -70,6 +94,25 @@ type tiltfileState struct {
 	// for error reporting in case it's called twice
 	updateModeCallPosition syntax.Position
 
+	// How many resources Tilt can update at once, or 0 for the default.
+	maxParallelUpdates int
+
+	// How long to wait for files to stop changing before updating a resource,
+	// unless the resource says otherwise, or 0 for the default.
+	fileQuietPeriod time.Duration
+
+	// How to retry k8s deploys that fail with temporary errors.
+	deployRetry model.DeployRetryPolicy
+
+	// Where to send build and deploy events.
+	eventSinks []model.EventSink
+
+	// How to watch files.
+	watchSettings model.WatchSettings
+
+	// Resources that run on the user's machine, in the order they were declared.
+	localResources []*localResource
+
 	logger   logger.Logger
 	warnings []string
 }
@@ -100,6 +143,7 @@ func newTiltfileState(ctx context.Context, dcCli dockercompose.DockerComposeClie
 		k8sResourceAssemblyVersion: 2,
 		k8sResourceOptions:         make(map[string]k8sResourceOptions),
 		updateMode:                 UpdateModeAuto,
+		deployRetry:                model.DefaultDeployRetryPolicy,
 	}
 	s.filename = s.maybeAttachGitRepo(lp, filepath.Dir(lp.path))
 	return s
@@ -125,7 +169,10 @@ const (
 	dockerBuildN     = "docker_build"
 	fastBuildN       = "fast_build"
 	customBuildN     = "custom_build"
+	packBuildN       = "pack_build"
+	bazelBuildN      = "bazel_build"
 	defaultRegistryN = "default_registry"
+	imagePullSecretN = "image_pull_secret"
 
 	// docker compose functions
 	dockerComposeN = "docker_compose"
@@ -139,6 +186,11 @@ const (
 	portForwardN                = "port_forward"
 	k8sKindN                    = "k8s_kind"
 	k8sImageJSONPathN           = "k8s_image_json_path"
+	defaultNamespaceN           = "default_namespace"
+	k8sServerSideApplyN         = "k8s_server_side_apply"
+	k8sObjectMetadataN          = "k8s_object_metadata"
+	k8sCredentialsN             = "k8s_credentials"
+	helmReleaseN                = "helm_release"
 	workloadToResourceFunctionN = "workload_to_resource_function"
 
 	// file functions
@@ -164,9 +216,16 @@ const (
 	updateModeAutoN   = "UPDATE_MODE_AUTO"
 	updateModeManualN = "UPDATE_MODE_MANUAL"
 
+	// local resource functions
+	localResourceN = "local_resource"
+
 	// other functions
-	failN = "fail"
-	blobN = "blob"
+	readinessCheckN = "readiness_check"
+	eventSinkN      = "event_sink"
+	failN           = "fail"
+	blobN           = "blob"
+	updateSettingsN = "update_settings"
+	watchSettingsN  = "watch_settings"
 )
 
 type updateMode int
@@ -214,6 +273,72 @@ func (s *tiltfileState) updateModeForResource(resourceUpdateMode updateMode) upd
 	}
 }
 
+// Tilt updates a resource at least this often while its files keep changing
+// (see watchBufferMaxDuration in the engine), so a longer quiet period would never end.
+const maxFileQuietPeriod = 10 * time.Second
+
+func fileQuietPeriodFromStarlark(fnName string, v starlark.Value) (time.Duration, error) {
+	if v == nil || v == starlark.None {
+		return 0, nil
+	}
+
+	ms, err := starlark.AsInt32(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: file_quiet_period_ms must be an int; got %s", fnName, v.Type())
+	}
+
+	d := time.Duration(ms) * time.Millisecond
+	if d <= 0 || d >= maxFileQuietPeriod {
+		return 0, fmt.Errorf("%s: file_quiet_period_ms must be greater than 0 and less than %d; got %d",
+			fnName, maxFileQuietPeriod/time.Millisecond, ms)
+	}
+	return d, nil
+}
+
+// The labels kwarg: a string or list of strings, for grouping resources
+// in the UIs. Drops duplicates, but keeps the order, since the first
+// label is the resource's group in the HUD.
+func labelsFromStarlark(fnName string, v starlark.Value) ([]string, error) {
+	labels, err := stringsFromSkylarkValue("labels", v)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	seen := make(map[string]bool)
+	for _, l := range labels {
+		if err := model.ValidateResourceLabel(l); err != nil {
+			return nil, fmt.Errorf("%s: %v", fnName, err)
+		}
+		if seen[l] {
+			continue
+		}
+		seen[l] = true
+		result = append(result, l)
+	}
+	return result, nil
+}
+
+func resourceDepsFromStarlark(v starlark.Value) ([]model.ManifestName, error) {
+	deps, err := stringsFromSkylarkValue("resource_deps", v)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.ManifestName
+	for _, dep := range deps {
+		result = append(result, model.ManifestName(dep))
+	}
+	return result, nil
+}
+
+func (s *tiltfileState) fileQuietPeriodForResource(d time.Duration) time.Duration {
+	if d != 0 {
+		return d
+	}
+	return s.fileQuietPeriod
+}
+
 func starlarkUpdateModeToModel(updateMode updateMode) (model.UpdateMode, error) {
 	switch updateMode {
 	case UpdateModeManual:
@@ -245,13 +370,17 @@ func (s *tiltfileState) predeclared() starlark.StringDict {
 	r := make(starlark.StringDict)
 
 	addBuiltin(r, localN, s.local)
+	addBuiltin(r, localResourceN, s.localResource)
 	addBuiltin(r, readFileN, s.skylarkReadFile)
 	addBuiltin(r, watchFileN, s.watchFile)
 
 	addBuiltin(r, dockerBuildN, s.dockerBuild)
 	addBuiltin(r, fastBuildN, s.fastBuild)
 	addBuiltin(r, customBuildN, s.customBuild)
+	addBuiltin(r, packBuildN, s.packBuild)
+	addBuiltin(r, bazelBuildN, s.bazelBuild)
 	addBuiltin(r, defaultRegistryN, s.defaultRegistry)
+	addBuiltin(r, imagePullSecretN, s.imagePullSecretFn)
 	addBuiltin(r, dockerComposeN, s.dockerCompose)
 	addBuiltin(r, dcResourceN, s.dcResource)
 	addBuiltin(r, k8sResourceAssemblyVersionN, s.k8sResourceAssemblyVersionFn)
@@ -259,12 +388,18 @@ func (s *tiltfileState) predeclared() starlark.StringDict {
 	addBuiltin(r, filterYamlN, s.filterYaml)
 	addBuiltin(r, k8sResourceN, s.k8sResource)
 	addBuiltin(r, portForwardN, s.portForward)
+	addBuiltin(r, readinessCheckN, s.readinessCheck)
 	addBuiltin(r, k8sKindN, s.k8sKind)
 	addBuiltin(r, k8sImageJSONPathN, s.k8sImageJsonPath)
+	addBuiltin(r, defaultNamespaceN, s.defaultNamespaceFn)
+	addBuiltin(r, k8sServerSideApplyN, s.k8sServerSideApplyFn)
+	addBuiltin(r, k8sObjectMetadataN, s.k8sObjectMetadataFn)
+	addBuiltin(r, k8sCredentialsN, s.k8sCredentialsFn)
 	addBuiltin(r, workloadToResourceFunctionN, s.workloadToResourceFunctionFn)
 	addBuiltin(r, localGitRepoN, s.localGitRepo)
 	addBuiltin(r, kustomizeN, s.kustomize)
 	addBuiltin(r, helmN, s.helm)
+	addBuiltin(r, helmReleaseN, s.helmReleaseFn)
 	addBuiltin(r, failN, s.fail)
 	addBuiltin(r, blobN, s.blob)
 	addBuiltin(r, listdirN, s.listdir)
@@ -273,6 +408,9 @@ func (s *tiltfileState) predeclared() starlark.StringDict {
 	addBuiltin(r, readYAMLN, s.readYaml)
 
 	addBuiltin(r, updateModeN, s.updateModeFn)
+	addBuiltin(r, updateSettingsN, s.updateSettingsFn)
+	addBuiltin(r, watchSettingsN, s.watchSettingsFn)
+	addBuiltin(r, eventSinkN, s.eventSink)
 	r[updateModeAutoN] = UpdateModeAuto
 	r[updateModeManualN] = UpdateModeManual
 
@@ -302,6 +440,11 @@ func (s *tiltfileState) assemble() (resourceSet, []k8s.K8sEntity, error) {
 		return resourceSet{}, nil, err
 	}
 
+	err = s.assembleNamespaces()
+	if err != nil {
+		return resourceSet{}, nil, err
+	}
+
 	err = s.assembleDC()
 	if err != nil {
 		return resourceSet{}, nil, err
@@ -323,6 +466,45 @@ func (s *tiltfileState) assemble() (resourceSet, []k8s.K8sEntity, error) {
 	}, s.k8sUnresourced, nil
 }
 
+// Moves each k8s resource into its namespace (or the default namespace), if it has one.
+func (s *tiltfileState) assembleNamespaces() error {
+	for _, r := range s.k8s {
+		if r.namespace == "" {
+			r.namespace = s.defaultNamespace
+		}
+		if r.namespace == "" {
+			continue
+		}
+
+		entities, err := entitiesWithNamespace(r.entities, k8s.Namespace(r.namespace))
+		if err != nil {
+			return errors.Wrapf(err, "resource %q", r.name)
+		}
+		r.entities = entities
+	}
+
+	if s.defaultNamespace != "" {
+		entities, err := entitiesWithNamespace(s.k8sUnresourced, k8s.Namespace(s.defaultNamespace))
+		if err != nil {
+			return err
+		}
+		s.k8sUnresourced = entities
+	}
+	return nil
+}
+
+func entitiesWithNamespace(entities []k8s.K8sEntity, ns k8s.Namespace) ([]k8s.K8sEntity, error) {
+	result := make([]k8s.K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		e, err := k8s.WithNamespace(e, ns)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
 func (s *tiltfileState) assembleImages() error {
 	for _, imageBuilder := range s.buildIndex.images {
 		var err error
@@ -333,7 +515,12 @@ func (s *tiltfileState) assembleImages() error {
 
 		var depImages []reference.Named
 		if imageBuilder.dbDockerfile != "" {
-			depImages, err = imageBuilder.dbDockerfile.FindImages()
+			var df dockerfile.Dockerfile
+			df, err = imageBuilder.targetDockerfile()
+			if err != nil {
+				return err
+			}
+			depImages, err = df.FindImages()
 		} else {
 			depImages, err = imageBuilder.baseDockerfile.FindImages()
 		}
@@ -404,6 +591,21 @@ func (s *tiltfileState) assembleK8sV2() error {
 			r.extraPodSelectors = opts.extraPodSelectors
 			r.portForwards = opts.portForwards
 			r.updateMode = opts.updateMode
+			r.fileQuietPeriod = opts.fileQuietPeriod
+			r.resourceDeps = opts.resourceDeps
+			r.redeployOnDepUpdate = opts.redeployOnDepUpdate
+			r.readinessCheck = opts.readinessCheck
+			r.labels = opts.labels
+			r.logContainers = opts.logContainers
+			r.ignoredLogContainers = opts.ignoredLogContainers
+			r.sidecarContainers = opts.sidecarContainers
+			r.disableSidecarDetection = opts.disableSidecarDetection
+			r.namespace = opts.namespace
+			r.kubeContext = opts.kubeContext
+			r.credentials = opts.credentials
+			r.objectLabels = opts.objectLabels
+			r.objectAnnotations = opts.objectAnnotations
+			r.manifestLabel = opts.manifestLabel
 			if opts.newName != "" && opts.newName != r.name {
 				if _, ok := s.k8sByName[opts.newName]; ok {
 					return fmt.Errorf("k8s_resource at %s specified to rename '%s' to '%s', but there is already a resource with that name", opts.tiltfilePosition.String(), r.name, opts.newName)
@@ -478,6 +680,12 @@ func (s *tiltfileState) assembleK8sByWorkload() error {
 }
 
 func (s *tiltfileState) isWorkload(e k8s.K8sEntity) (bool, error) {
+	for _, k := range s.k8sKinds {
+		if k.matches(e) {
+			return true, nil
+		}
+	}
+
 	images, err := e.FindImages(s.imageJSONPaths(e))
 	if err != nil {
 		return false, err
@@ -549,7 +757,7 @@ func (s *tiltfileState) assembleK8sUnresourced() error {
 }
 
 func (s *tiltfileState) validateK8s(r *k8sResource) error {
-	if len(r.entities) == 0 {
+	if len(r.entities) == 0 && r.helmRelease == nil {
 		if len(r.refSelectors) > 0 {
 			return fmt.Errorf("resource %q: could not find k8s entities matching "+
 				"image(s) %q; perhaps there's a typo?",
@@ -703,16 +911,54 @@ func (s *tiltfileState) translateK8s(resources []*k8sResource) ([]model.Manifest
 			return nil, err
 		}
 		m := model.Manifest{
-			Name:       mn,
-			UpdateMode: um,
+			Name:                       mn,
+			UpdateMode:                 um,
+			FileQuietPeriod:            s.fileQuietPeriodForResource(r.fileQuietPeriod),
+			ResourceDependencies:       r.resourceDeps,
+			RedeployOnDependencyUpdate: r.redeployOnDepUpdate,
+			ReadinessCheck:             r.readinessCheck,
+			Labels:                     r.labels,
+		}
+
+		extraPodSelectors := r.extraPodSelectors
+		if r.helmRelease != nil {
+			extraPodSelectors = append(helmReleasePodSelectors(r.helmRelease.release.Name), extraPodSelectors...)
+		}
+
+		k8sTarget, err := k8s.NewTarget(mn.TargetName(), r.entities, s.portForwardsToDomain(r), extraPodSelectors, r.dependencyIDs)
+		if err != nil {
+			return nil, err
 		}
 
-		k8sTarget, err := k8s.NewTarget(mn.TargetName(), r.entities, s.portForwardsToDomain(r), r.extraPodSelectors, r.dependencyIDs)
+		if r.helmRelease != nil {
+			release, err := s.helmReleaseForResource(r)
+			if err != nil {
+				return nil, err
+			}
+			k8sTarget = k8sTarget.WithHelmRelease(release)
+			r.namespace = release.Namespace
+		}
+
+		k8sTarget = k8sTarget.WithLogContainers(r.logContainers, r.ignoredLogContainers).
+			WithSidecars(r.sidecarContainers, !r.disableSidecarDetection)
+		objectLabels, objectAnnotations, manifestLabel, err := s.objectMetadataForResource(r)
 		if err != nil {
 			return nil, err
 		}
 
-		m = m.WithDeployTarget(k8sTarget)
+		credentials := s.credentials.merge(r.credentials)
+		if len(credentials.impersonateGroups) > 0 && credentials.impersonateUser == "" {
+			return nil, fmt.Errorf("%s: impersonate_groups requires impersonate_user", r.name)
+		}
+
+		k8sTarget = k8sTarget.WithNamespace(r.namespace).
+			WithKubeContext(r.kubeContext).
+			WithCredentials(credentials.kubeConfigPath, credentials.user, credentials.impersonateUser, credentials.impersonateGroups).
+			WithServerSideApply(s.serverSideApply, s.forceApplyConflicts).
+			WithDeployRetry(s.deployRetry).
+			WithObjectMetadata(objectLabels, objectAnnotations, manifestLabel).
+			WithProject(k8s.TiltProjectID(s.filename.path))
+		m = m.WithDeployTarget(k8sTarget.WithImagePullSecret(s.imagePullSecret))
 
 		iTargets, err := s.imgTargetsForDependencyIDs(r.dependencyIDs)
 		if err != nil {
@@ -862,11 +1108,19 @@ func (s *tiltfileState) imgTargetsForDependencyIDsHelper(ids []model.TargetID, c
 		switch image.Type() {
 		case DockerBuild:
 			iTarget = iTarget.WithBuildDetails(model.DockerBuild{
-				Dockerfile: image.dbDockerfile.String(),
-				BuildPath:  string(image.dbBuildPath.path),
-				BuildArgs:  image.dbBuildArgs,
-				FastBuild:  s.fastBuildForImage(image),
-				LiveUpdate: lu,
+				Dockerfile:  image.dbDockerfile.String(),
+				BuildPath:   string(image.dbBuildPath.path),
+				BuildArgs:   image.dbBuildArgs,
+				FastBuild:   s.fastBuildForImage(image),
+				LiveUpdate:  lu,
+				TargetStage: image.targetStage,
+				ContentTag:  image.contentTag,
+				Network:     image.network,
+				SSHSpecs:    image.sshSpecs,
+				ExtraHosts:  image.extraHosts,
+				Platform:    image.platform,
+
+				ContextSources: contextSourcesForImage(image),
 			})
 		case FastBuild:
 			iTarget = iTarget.WithBuildDetails(s.fastBuildForImage(image))
@@ -877,6 +1131,7 @@ func (s *tiltfileState) imgTargetsForDependencyIDsHelper(ids []model.TargetID, c
 				Tag:         image.customTag,
 				DisablePush: image.disablePush,
 				LiveUpdate:  lu,
+				Platform:    image.platform,
 			}
 			if len(image.syncs) > 0 || len(image.runs) > 0 {
 				r.Fast = model.FastBuild{
@@ -1022,3 +1277,58 @@ func (s *tiltfileState) updateModeFn(thread *starlark.Thread, fn *starlark.Built
 
 	return starlark.None, nil
 }
+
+func (s *tiltfileState) updateSettingsFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var maxParallelUpdatesVal, fileQuietPeriodVal, deployRetriesVal, deployRetryBackoffVal starlark.Value
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"max_parallel_updates?", &maxParallelUpdatesVal,
+		"file_quiet_period_ms?", &fileQuietPeriodVal,
+		"deploy_retries?", &deployRetriesVal,
+		"deploy_retry_backoff_ms?", &deployRetryBackoffVal,
+	); err != nil {
+		return nil, err
+	}
+
+	if maxParallelUpdatesVal != nil {
+		maxParallelUpdates, err := starlark.AsInt32(maxParallelUpdatesVal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: max_parallel_updates must be an int; got %s", fn.Name(), maxParallelUpdatesVal.Type())
+		}
+		if maxParallelUpdates < 1 {
+			return nil, fmt.Errorf("%s: max_parallel_updates must be at least 1; got %d", fn.Name(), maxParallelUpdates)
+		}
+		s.maxParallelUpdates = maxParallelUpdates
+	}
+
+	if fileQuietPeriodVal != nil {
+		fileQuietPeriod, err := fileQuietPeriodFromStarlark(fn.Name(), fileQuietPeriodVal)
+		if err != nil {
+			return nil, err
+		}
+		s.fileQuietPeriod = fileQuietPeriod
+	}
+
+	if deployRetriesVal != nil {
+		deployRetries, err := starlark.AsInt32(deployRetriesVal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: deploy_retries must be an int; got %s", fn.Name(), deployRetriesVal.Type())
+		}
+		if deployRetries < 0 {
+			return nil, fmt.Errorf("%s: deploy_retries must not be negative; got %d", fn.Name(), deployRetries)
+		}
+		s.deployRetry.Retries = deployRetries
+	}
+
+	if deployRetryBackoffVal != nil {
+		backoffMs, err := starlark.AsInt32(deployRetryBackoffVal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: deploy_retry_backoff_ms must be an int; got %s", fn.Name(), deployRetryBackoffVal.Type())
+		}
+		if backoffMs < 1 {
+			return nil, fmt.Errorf("%s: deploy_retry_backoff_ms must be greater than 0; got %d", fn.Name(), backoffMs)
+		}
+		s.deployRetry.Backoff = time.Duration(backoffMs) * time.Millisecond
+	}
+
+	return starlark.None, nil
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 
@@ -239,6 +240,373 @@ k8s_yaml('foo.yaml')
 	f.loadErrString("Cannot specify both dockerfile and dockerfile_contents")
 }
 
+func TestDockerBuildTarget(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("foo/Dockerfile", `
+FROM golang:1.10 AS dev
+RUN go install
+
+FROM alpine AS prod
+`)
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', target='dev')
+k8s_yaml('foo.yaml')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.Equal(t, model.DockerBuildTarget("dev"), m.ImageTargetAt(0).DockerBuildInfo().TargetStage)
+}
+
+func TestDockerBuildContentTag(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', content_tag=True)
+k8s_yaml('foo.yaml')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.True(t, m.ImageTargetAt(0).DockerBuildInfo().ContentTag)
+}
+
+func TestDockerBuildNetworkSSHAndExtraHosts(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', network='host', ssh='default', extra_hosts=['git.internal:10.0.0.1'])
+k8s_yaml('foo.yaml')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	info := m.ImageTargetAt(0).DockerBuildInfo()
+	assert.Equal(t, "host", info.Network)
+	assert.Equal(t, []string{"default"}, info.SSHSpecs)
+	assert.Equal(t, []string{"git.internal:10.0.0.1"}, info.ExtraHosts)
+}
+
+func TestDockerBuildBadExtraHost(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', extra_hosts='git.internal')
+k8s_yaml('foo.yaml')
+`)
+	f.loadErrString(`"git.internal" must be in the form host:ip`)
+}
+
+func TestDockerBuildPlatform(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', platform='linux/x86_64')
+k8s_yaml('foo.yaml')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.Equal(t, "linux/amd64", m.ImageTargetAt(0).DockerBuildInfo().Platform)
+}
+
+func TestDockerBuildPlatformWithoutOS(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', platform='arm64')
+k8s_yaml('foo.yaml')
+`)
+	f.loadErrString(`expected a platform like linux/amd64, got "arm64"`)
+}
+
+func TestCustomBuildPlatform(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+custom_build('gcr.io/foo', 'docker build -t $EXPECTED_REF foo', ['foo'], platform='linux/arm64')
+k8s_yaml('foo.yaml')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", cb(image("gcr.io/foo")))
+	assert.Equal(t, "linux/arm64", m.ImageTargetAt(0).CustomBuildInfo().Platform)
+}
+
+func TestK8sResourceLogContainers(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', log_containers=['app', 'istio-proxy'], ignore_log_containers='istio-init')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.Equal(t, []string{"app", "istio-proxy"}, m.K8sTarget().LogContainers)
+	assert.Equal(t, []string{"istio-init"}, m.K8sTarget().IgnoredLogContainers)
+}
+
+func TestK8sResourceSidecars(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', sidecar_containers=['vault-agent'], detect_sidecars=False)
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.Equal(t, []string{"vault-agent"}, m.K8sTarget().SidecarContainers)
+	assert.True(t, m.K8sTarget().DisableSidecarDetection)
+}
+
+func TestK8sProject(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.Equal(t, k8s.TiltProjectID(f.JoinPath("Tiltfile")), m.K8sTarget().Project)
+}
+
+func TestK8sResourceNamespace(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', namespace='alice')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")), deployment("foo", namespace("alice")))
+	assert.Equal(t, "alice", m.K8sTarget().Namespace)
+}
+
+func TestK8sResourceKubeContext(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', kube_context='remote')
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, "remote", foo.K8sTarget().KubeContext)
+	bar := f.assertNextManifest("bar")
+	assert.Equal(t, "", bar.K8sTarget().KubeContext)
+}
+
+func TestK8sCredentials(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFooAndBar()
+	f.file("robot.kubeconfig", "")
+	f.file("Tiltfile", `
+k8s_credentials(kubeconfig='robot.kubeconfig', impersonate_user='ci-bot', impersonate_groups=['deployers'])
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', kube_user='admin', impersonate_user='foo-deployer')
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, f.JoinPath("robot.kubeconfig"), foo.K8sTarget().KubeConfigPath)
+	assert.Equal(t, "admin", foo.K8sTarget().KubeUser)
+	assert.Equal(t, "foo-deployer", foo.K8sTarget().ImpersonateUser)
+	assert.Equal(t, []string{"deployers"}, foo.K8sTarget().ImpersonateGroups)
+
+	bar := f.assertNextManifest("bar")
+	assert.Equal(t, f.JoinPath("robot.kubeconfig"), bar.K8sTarget().KubeConfigPath)
+	assert.Equal(t, "", bar.K8sTarget().KubeUser)
+	assert.Equal(t, "ci-bot", bar.K8sTarget().ImpersonateUser)
+	assert.Equal(t, []string{"deployers"}, bar.K8sTarget().ImpersonateGroups)
+
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml", "bar/Dockerfile", "bar/.dockerignore", "bar.yaml", "robot.kubeconfig")
+}
+
+func TestK8sCredentialsGroupsWithoutUser(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_credentials(impersonate_groups=['deployers'])
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+	f.loadErrString("impersonate_groups requires impersonate_user")
+}
+
+func TestK8sCredentialsMissingKubeconfig(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', kubeconfig='nope.kubeconfig')
+`)
+	f.loadErrString("k8s_resource: kubeconfig")
+}
+
+func TestK8sObjectMetadata(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+k8s_object_metadata(labels={'team': 'web', 'cost-center': '1234'}, annotations={'example.com/owner': 'web-team'})
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', object_labels={'team': 'api'}, object_annotations={'example.com/oncall': 'alice'}, manifest_label='app.kubernetes.io/instance')
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, []model.LabelPair{{Key: "cost-center", Value: "1234"}, {Key: "team", Value: "api"}}, foo.K8sTarget().ObjectLabels)
+	assert.Equal(t, []model.LabelPair{{Key: "example.com/oncall", Value: "alice"}, {Key: "example.com/owner", Value: "web-team"}}, foo.K8sTarget().ObjectAnnotations)
+	assert.Equal(t, "app.kubernetes.io/instance", foo.K8sTarget().ManifestLabel)
+
+	bar := f.assertNextManifest("bar")
+	assert.Equal(t, []model.LabelPair{{Key: "cost-center", Value: "1234"}, {Key: "team", Value: "web"}}, bar.K8sTarget().ObjectLabels)
+	assert.Equal(t, []model.LabelPair{{Key: "example.com/owner", Value: "web-team"}}, bar.K8sTarget().ObjectAnnotations)
+	assert.Equal(t, "", bar.K8sTarget().ManifestLabel)
+}
+
+func TestK8sObjectMetadataTrackingLabel(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_object_metadata(labels={'tilt-runid': 'mine'})
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+	f.loadErrString(`k8s_object_metadata: can't set label "tilt-runid", which Tilt uses to track deployed objects`)
+}
+
+func TestK8sResourceObjectLabelIsManifestLabel(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', object_labels={'app': 'foo-app'}, manifest_label='app')
+`)
+	f.loadErrString(`resource "foo": label "app" is its manifest_label`)
+}
+
+func TestK8sResourceInvalidObjectLabel(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', object_labels={'team': 'not a valid value'})
+`)
+	f.loadErrString(`k8s_resource: invalid value for label "team"`)
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFooAndBar()
+	f.yaml("config.yaml", secret("creds"))
+	f.file("Tiltfile", `
+default_namespace('bob')
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml', 'config.yaml'])
+k8s_resource('bar', namespace='alice')
+`)
+	f.load()
+	foo := f.assertNextManifest("foo", deployment("foo", namespace("bob")))
+	assert.Equal(t, "bob", foo.K8sTarget().Namespace)
+	bar := f.assertNextManifest("bar", deployment("bar", namespace("alice")))
+	assert.Equal(t, "alice", bar.K8sTarget().Namespace)
+
+	yaml := f.loadResult.Manifests[0]
+	f.assertNextManifestUnresourced("creds")
+	assert.Equal(t, "bob", yaml.K8sTarget().Namespace)
+	assert.Contains(t, yaml.K8sTarget().YAML, "namespace: bob")
+}
+
+func TestK8sServerSideApply(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_server_side_apply(force_conflicts=True)
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+	f.load()
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.True(t, m.K8sTarget().ServerSideApply)
+	assert.True(t, m.K8sTarget().ForceApplyConflicts)
+}
+
+func TestK8sResourceInvalidNamespace(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', namespace='Alice_Dev')
+`)
+	f.loadErrString(`k8s_resource: invalid namespace "Alice_Dev"`)
+}
+
+func TestDockerBuildTargetNotFound(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', target='dev')
+k8s_yaml('foo.yaml')
+`)
+	f.loadErrString(`target stage "dev" not found in Dockerfile`)
+}
+
+func TestDockerBuildTargetIgnoresLaterStageDependencies(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.gitInit("")
+	f.file("imageA.dockerfile", "FROM golang:1.10")
+	f.file("imageB.dockerfile", `
+FROM golang:1.10 AS dev
+RUN go install
+
+FROM gcr.io/image-a AS prod
+`)
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/image-b")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/image-b', '.', dockerfile='imageB.dockerfile', target='dev')
+docker_build('gcr.io/image-a', '.', dockerfile='imageA.dockerfile')
+k8s_yaml('foo.yaml')
+`)
+
+	f.loadAllowWarnings()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, []string{"gcr.io/image-b"}, f.imageTargetNames(m))
+}
+
 func TestFastBuildSimple(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -602,6 +970,7 @@ func TestPortForward(t *testing.T) {
 		{"value_both", "port_forward(8001, 443)", []model.PortForward{{LocalPort: 8001, ContainerPort: 443}}, ""},
 		{"list", "[8000, port_forward(8001, 443)]", []model.PortForward{{LocalPort: 8000}, {LocalPort: 8001, ContainerPort: 443}}, ""},
 		{"list_string", "['8000', '8001:443']", []model.PortForward{{LocalPort: 8000}, {LocalPort: 8001, ContainerPort: 443}}, ""},
+		{"value_service", "port_forward(8001, 80, service='foo-svc')", []model.PortForward{{LocalPort: 8001, ContainerPort: 80, Service: "foo-svc"}}, ""},
 	}
 
 	for _, c := range portForwardCases {
@@ -631,16 +1000,97 @@ k8s_resource('foo', port_forwards=EXPR)
 	}
 }
 
-func TestExpand(t *testing.T) {
+func TestPortForwardToServiceInTiltfile(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
-	f.setupExpand()
+	f.file("k8s.yaml", yaml.ConcatYAML(testyaml.DoggosDeploymentYaml, testyaml.DoggosServiceYaml))
 	f.file("Tiltfile", `
-k8s_yaml('all.yaml')
-docker_build('gcr.io/a', 'a')
-docker_build('gcr.io/b', 'b')
-docker_build('gcr.io/c', 'c')
-docker_build('gcr.io/d', 'd')
+k8s_yaml('k8s.yaml')
+k8s_resource('doggos', port_forwards=port_forward(8000, 80, service='doggos'))
+`)
+
+	f.load()
+	f.assertNextManifest("doggos",
+		[]model.PortForward{{LocalPort: 8000, ContainerPort: 80, Service: "doggos"}},
+		deployment("doggos"),
+		service("doggos"))
+}
+
+func TestPortForwardToServiceInNamespace(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	svc := strings.Replace(testyaml.DoggosServiceYaml, "name: doggos\n", "name: doggos\n  namespace: pets\n", 1)
+	f.file("k8s.yaml", yaml.ConcatYAML(testyaml.DoggosDeploymentYaml, svc))
+	f.file("Tiltfile", `
+k8s_yaml('k8s.yaml')
+k8s_resource('doggos', port_forwards=[
+  port_forward(8000, 80, service='doggos'),
+  port_forward(8001, 80, service='helm-svc'),
+])
+`)
+
+	f.load()
+	f.assertNextManifest("doggos",
+		[]model.PortForward{
+			{LocalPort: 8000, ContainerPort: 80, Service: "doggos", ServiceNamespace: "pets"},
+			{LocalPort: 8001, ContainerPort: 80, Service: "helm-svc"},
+		},
+		deployment("doggos"),
+		service("doggos"))
+}
+
+func TestJobAndCronJobResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.dockerfile("foo/Dockerfile")
+	f.file("k8s.yaml", yaml.ConcatYAML(testyaml.JobYAML, testyaml.CronJobYAML))
+	f.file("Tiltfile", `
+k8s_yaml('k8s.yaml')
+docker_build('gcr.io/foo/hello', 'foo')
+`)
+
+	f.load()
+	f.assertNextManifest("pi", k8sObject("pi", "Job"))
+	f.assertNextManifest("hello", db(image("gcr.io/foo/hello")), k8sObject("hello", "CronJob"))
+}
+
+func TestCustomResourceWithPodTemplate(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.dockerfile("frontend/Dockerfile")
+	f.file("k8s.yaml", yaml.ConcatYAML(testyaml.OperatorAppYAML, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend-svc
+spec:
+  selector:
+    app: frontend
+  ports:
+  - port: 80
+`))
+	f.file("Tiltfile", `
+k8s_yaml('k8s.yaml')
+docker_build('gcr.io/foo/frontend', 'frontend')
+`)
+
+	f.load()
+	f.assertNextManifest("frontend",
+		db(image("gcr.io/foo/frontend")),
+		k8sObject("frontend", "App"),
+		k8sObject("frontend-svc", "Service"))
+}
+
+func TestExpand(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupExpand()
+	f.file("Tiltfile", `
+k8s_yaml('all.yaml')
+docker_build('gcr.io/a', 'a')
+docker_build('gcr.io/b', 'b')
+docker_build('gcr.io/c', 'c')
+docker_build('gcr.io/d', 'd')
 `)
 	f.load()
 	f.assertNextManifest("a", db(image("gcr.io/a")), deployment("a"))
@@ -921,7 +1371,7 @@ func TestBasicGitPathFilter(t *testing.T) {
 	defer f.TearDown()
 
 	f.gitInit("")
-	f.file("Dockerfile", "FROM golang:1.10")
+	f.file("Dockerfile", "FROM golang:1.10\nADD . .")
 	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
 	f.file("Tiltfile", `
 docker_build('gcr.io/foo', '.')
@@ -944,7 +1394,7 @@ func TestDockerignorePathFilter(t *testing.T) {
 	defer f.TearDown()
 
 	f.gitInit("")
-	f.file("Dockerfile", "FROM golang:1.10")
+	f.file("Dockerfile", "FROM golang:1.10\nADD . .")
 	f.file(".dockerignore", "*.txt")
 	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
 	f.file("Tiltfile", `
@@ -966,7 +1416,7 @@ func TestDockerignorePathFilterSubdir(t *testing.T) {
 	defer f.TearDown()
 
 	f.gitInit("")
-	f.file("foo/Dockerfile", "FROM golang:1.10")
+	f.file("foo/Dockerfile", "FROM golang:1.10\nADD . .")
 	f.file("foo/.dockerignore", "*.txt")
 	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
 	f.file("Tiltfile", `
@@ -983,6 +1433,34 @@ k8s_yaml('foo.yaml')
 	)
 }
 
+func TestDockerfileCopiesNarrowFileChangeFilter(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.gitInit("")
+	f.file("Dockerfile", `FROM golang:1.10
+COPY src /app/src
+COPY *.json /app/
+RUN go build ./...
+`)
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', '.')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load("foo")
+	f.assertNextManifest("foo",
+		buildMatches("docs/README.md"),
+		fileChangeFilters("docs/README.md"),
+		fileChangeFilters("foo.yaml"),
+		fileChangeMatches("src/main.go"),
+		fileChangeMatches("package.json"),
+		fileChangeMatches("Dockerfile"),
+		fileChangeMatches(".dockerignore"),
+	)
+}
+
 func TestFastBuildDockerignoreRoot(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -1226,6 +1704,97 @@ k8s_yaml(yml)
 	)
 }
 
+func TestHelmRelease(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.setupHelm()
+	f.file("dev-values.yaml", "replicas: 2")
+
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+helm_release('foo', 'helm', namespace='web', values='dev-values.yaml', set=['ingress.enabled=true'],
+             image_values={'gcr.io/foo': {'repository': 'image.repository', 'tag': 'image.tag'}})
+k8s_resource('foo', port_forwards=8000)
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	release := m.K8sTarget().HelmRelease
+	if assert.NotNil(t, release) {
+		assert.Equal(t, "foo", release.Name)
+		assert.Equal(t, f.JoinPath("helm"), release.Chart)
+		assert.Equal(t, "web", release.Namespace)
+		assert.Equal(t, []string{f.JoinPath("dev-values.yaml")}, release.ValuesFiles)
+		assert.Equal(t, []string{"ingress.enabled=true"}, release.Set)
+		assert.Equal(t, []model.HelmImageValue{{
+			ImageID:       m.ImageTargetAt(0).ID(),
+			RepositoryKey: "image.repository",
+			TagKey:        "image.tag",
+		}}, release.ImageValues)
+	}
+	assert.Equal(t, "web", m.K8sTarget().Namespace)
+	assert.Equal(t, []model.PortForward{{LocalPort: 8000}}, m.K8sTarget().PortForwards)
+	assert.Equal(t, 2, len(m.K8sTarget().ExtraPodSelectors))
+	f.assertConfigFiles(
+		"Tiltfile",
+		".tiltignore",
+		"foo/Dockerfile",
+		"foo/.dockerignore",
+		"helm",
+		"dev-values.yaml",
+	)
+}
+
+func TestHelmReleaseFromChartRepo(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+helm_release('redis', 'stable/redis')
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("redis")
+	assert.Equal(t, "stable/redis", m.K8sTarget().HelmRelease.Chart)
+}
+
+func TestHelmReleaseMissingLocalChart(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+helm_release('foo', './charts/foo')
+`)
+
+	f.loadErrString("helm_release: chart: no chart found at " + f.JoinPath("charts", "foo"))
+}
+
+func TestHelmReleaseMissingImageBuild(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+helm_release('foo', 'stable/foo', image_values={'gcr.io/foo': 'image'})
+`)
+
+	f.loadErrString(`helm_release "foo": image_values: no image build for "gcr.io/foo"`)
+}
+
+func TestHelmReleaseInvalidSet(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+helm_release('foo', 'stable/foo', set=['replicas'])
+`)
+
+	f.loadErrString(`helm_release: set: "replicas" must be in the form key=value`)
+}
+
 func TestHelmFromRepoPath(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -1335,8 +1904,33 @@ func TestExtraPodSelectorsNotList(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
 
-	f.setupExtraPodSelectors("'hello'")
-	f.loadErrString("got starlark.String", "dict or a list")
+	f.setupExtraPodSelectors("54321")
+	f.loadErrString("got starlark.Int", "dict, a string, or a list")
+}
+
+func TestExtraPodSelectorsString(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupExtraPodSelectors("['app in (web, api),tier!=cache', {'quux': 'corge'}]")
+	f.load()
+
+	m := f.assertNextManifest("foo")
+	selectors := m.K8sTarget().ExtraPodSelectors
+	if assert.Equal(t, 2, len(selectors)) {
+		assert.True(t, selectors[0].Matches(labels.Set{"app": "web", "tier": "frontend"}))
+		assert.False(t, selectors[0].Matches(labels.Set{"app": "web", "tier": "cache"}))
+		assert.False(t, selectors[0].Matches(labels.Set{"app": "db"}))
+		assert.True(t, selectors[1].Matches(labels.Set{"quux": "corge"}))
+	}
+}
+
+func TestExtraPodSelectorsInvalidString(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupExtraPodSelectors("'app in ('")
+	f.loadErrString("invalid pod selector", "app in (")
 }
 
 func TestExtraPodSelectorsDict(t *testing.T) {
@@ -1353,8 +1947,8 @@ func TestExtraPodSelectorsElementNotDict(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
 
-	f.setupExtraPodSelectors("['hello']")
-	f.loadErrString("must be dicts", "starlark.String")
+	f.setupExtraPodSelectors("[54321]")
+	f.loadErrString("must be dicts or strings", "starlark.Int")
 }
 
 func TestExtraPodSelectorsKeyNotString(t *testing.T) {
@@ -1940,6 +2534,52 @@ hfb = custom_build(
 		deployment("foo"))
 }
 
+func TestPackBuild(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	tiltfile := `k8s_yaml('foo.yaml')
+pack_build(
+  'gcr.io/foo',
+  'foo',
+  builder='heroku/buildpacks:18',
+  buildpacks=['heroku/java'],
+  env={'MAVEN_OPTS': '-Xmx1g', 'BP_JAVA_VERSION': '11'},
+  disable_push=True,
+)`
+
+	f.setupFoo()
+	f.file("Tiltfile", tiltfile)
+
+	f.load("foo")
+	f.assertNumManifests(1)
+	f.assertNextManifest("foo",
+		cb(
+			image("gcr.io/foo"),
+			deps(f.JoinPath("foo")),
+			cmd(fmt.Sprintf(`pack build "$EXPECTED_REF" --path '%s' --builder 'heroku/buildpacks:18' `+
+				`--buildpack 'heroku/java' --env 'BP_JAVA_VERSION=11' --env 'MAVEN_OPTS=-Xmx1g'`, f.JoinPath("foo"))),
+			disablePush(true),
+		),
+		deployment("foo"))
+}
+
+func TestPackBuildEmptyBuilder(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `k8s_yaml('foo.yaml')
+pack_build('gcr.io/foo', 'foo', builder='')`)
+
+	f.loadErrString("builder")
+}
+
+func TestPackBuildQuotesPath(t *testing.T) {
+	assert.Equal(t, `pack build "$EXPECTED_REF" --path '/src/it'"'"'s' --builder 'b'`,
+		packBuildCommand("/src/it's", "b", nil, nil))
+}
+
 func TestExtraImageLocationOneImage(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -2058,6 +2698,20 @@ docker_build('test/mycrd-env', 'env')
 	f.loadErrString("got 2 arguments, want at most 1")
 }
 
+func TestK8SKindWithoutImageJSONPath(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.setupCRD()
+	f.file("Tiltfile", `
+k8s_resource_assembly_version(2)
+k8s_yaml('crd.yaml')
+k8s_kind('Environment')
+`)
+
+	f.load("mycrd")
+	f.assertNextManifest("mycrd", k8sObject("mycrd", "Environment"))
+}
+
 func TestExtraImageLocationTwoImages(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -2544,6 +3198,50 @@ default_registry('example.com')
 	f.assertConfigFiles("Tiltfile", ".tiltignore", "bar/Dockerfile", "bar/.dockerignore", "bar.yaml", "baz/Dockerfile", "baz/.dockerignore", "baz.yaml")
 }
 
+func TestImagePullSecret(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+image_pull_secret('tilt-registry')
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("foo",
+		db(image("gcr.io/foo")),
+		deployment("foo"))
+	assert.Equal(t, "tilt-registry", m.K8sTarget().ImagePullSecret)
+}
+
+func TestImagePullSecretInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+image_pull_secret('Tilt_Registry')
+`)
+
+	f.loadErrString("image_pull_secret: invalid secret name \"Tilt_Registry\"")
+}
+
+func TestImagePullSecretTwice(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+image_pull_secret('tilt-registry')
+image_pull_secret('other-registry')
+`)
+
+	f.loadErrString("image pull secret already defined")
+}
+
 func TestDefaultRegistryWithDockerCompose(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -3003,6 +3701,379 @@ docker_build('gcr.io/some-project-162817/sancho-sidecar', './sidecar')
 	f.load()
 }
 
+func TestUpdateSettings(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+update_settings(max_parallel_updates=3)
+`)
+
+	f.load()
+	assert.Equal(t, 3, f.loadResult.MaxParallelUpdates)
+}
+
+func TestWatchSettingsPoll(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+watch_settings(mode='poll', poll_interval_ms=500, poll_compare='checksum')
+`)
+
+	f.load()
+	assert.Equal(t, model.WatchSettings{
+		Mode:         model.WatchModePoll,
+		PollInterval: 500 * time.Millisecond,
+		PollCompare:  model.PollCompareChecksum,
+	}, f.loadResult.WatchSettings)
+}
+
+func TestWatchSettingsNoDefaultIgnores(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+watch_settings(default_ignores=False)
+`)
+
+	f.load()
+	assert.Equal(t, model.WatchSettings{NoDefaultIgnores: true}, f.loadResult.WatchSettings)
+}
+
+func TestWatchSettingsDedupByChecksum(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+watch_settings(dedup_by_checksum=True)
+`)
+
+	f.load()
+	assert.Equal(t, model.WatchSettings{DedupByChecksum: true}, f.loadResult.WatchSettings)
+}
+
+func TestWatchSettingsInvalidMode(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+watch_settings(mode='psychic')
+`)
+
+	f.loadErrString("watch_settings", `Unknown watch mode "psychic"`)
+}
+
+func TestUpdateSettingsDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+	assert.Equal(t, 0, f.loadResult.MaxParallelUpdates)
+}
+
+func TestUpdateSettingsInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+update_settings(max_parallel_updates=0)
+`)
+
+	f.loadErrString("max_parallel_updates must be at least 1; got 0")
+}
+
+func TestDeployRetry(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+update_settings(deploy_retries=5, deploy_retry_backoff_ms=200)
+`)
+
+	f.load()
+	assert.Equal(t,
+		model.DeployRetryPolicy{Retries: 5, Backoff: 200 * time.Millisecond},
+		f.assertNextManifest("foo").K8sTarget().DeployRetry)
+}
+
+func TestDeployRetryDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+	assert.Equal(t, model.DefaultDeployRetryPolicy, f.assertNextManifest("foo").K8sTarget().DeployRetry)
+}
+
+func TestFileQuietPeriod(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+update_settings(file_quiet_period_ms=500)
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('bar', file_quiet_period_ms=2000)
+`)
+
+	f.load()
+	assert.Equal(t, 500*time.Millisecond, f.assertNextManifest("foo").FileQuietPeriod)
+	assert.Equal(t, 2*time.Second, f.assertNextManifest("bar").FileQuietPeriod)
+}
+
+func TestFileQuietPeriodTooLong(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', file_quiet_period_ms=60000)
+`)
+
+	f.loadErrString("file_quiet_period_ms must be greater than 0 and less than 10000; got 60000")
+}
+
+func TestReadinessCheckAndResourceDeps(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', readiness_check=readiness_check(http_get='http://localhost:8000/healthz', period_ms=500))
+k8s_resource('bar', resource_deps=['foo'], redeploy_on_dependency_update=True)
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, model.ReadinessCheck{
+		HTTPGet: "http://localhost:8000/healthz",
+		Period:  500 * time.Millisecond,
+	}, foo.ReadinessCheck)
+	assert.Empty(t, foo.ResourceDependencies)
+	assert.False(t, foo.RedeployOnDependencyUpdate)
+
+	bar := f.assertNextManifest("bar")
+	assert.True(t, bar.ReadinessCheck.Empty())
+	assert.Equal(t, []model.ManifestName{"foo"}, bar.ResourceDependencies)
+	assert.True(t, bar.RedeployOnDependencyUpdate)
+}
+
+func TestResourceDepsOutsideLoadedResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('bar', resource_deps=['foo'])
+`)
+
+	f.load("bar")
+	bar := f.assertNextManifest("bar")
+	f.assertNoMoreManifests()
+	assert.Equal(t, []model.ManifestName{"foo"}, bar.ResourceDependencies)
+
+	// foo isn't in this session, but it's in the Tiltfile, so bar's dependency is fine.
+	assert.NoError(t, model.ValidateResourceDependencies(f.loadResult.Manifests, f.loadResult.AllManifestNames))
+}
+
+func TestReadinessCheckExecAndLogRegex(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', readiness_check=readiness_check(exec='./check-foo.sh'))
+k8s_resource('bar', readiness_check=readiness_check(log_regex='listening on :\\d+'))
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, model.ToShellCmd("./check-foo.sh"), foo.ReadinessCheck.Exec)
+	assert.Equal(t, f.Path(), foo.ReadinessCheck.Workdir)
+	assert.Equal(t, `listening on :\d+`, f.assertNextManifest("bar").ReadinessCheck.LogRegex)
+}
+
+func TestLabels(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', labels=['frontend', 'web', 'frontend'])
+k8s_resource('bar', labels='backend')
+local_resource('codegen', cmd='make generate', labels=['infra'])
+`)
+
+	f.load()
+	assert.Equal(t, []string{"frontend", "web"}, f.assertNextManifest("foo").Labels)
+	assert.Equal(t, []string{"backend"}, f.assertNextManifest("bar").Labels)
+	assert.Equal(t, []string{"infra"}, f.assertNextManifest("codegen").Labels)
+}
+
+func TestLabelsInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', labels=['Front End'])
+`)
+
+	f.loadErrString(`k8s_resource: label "Front End" must be lowercase letters`)
+}
+
+func TestEventSink(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+event_sink(url='https://hooks.example.com/tilt')
+event_sink(cmd='./notify.sh')
+`)
+
+	f.load()
+	assert.Equal(t, []model.EventSink{
+		{URL: "https://hooks.example.com/tilt"},
+		{Cmd: model.ToShellCmd("./notify.sh"), Workdir: f.Path()},
+	}, f.loadResult.EventSinks)
+}
+
+func TestEventSinkNeedsURLOrCmd(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+event_sink(url='https://hooks.example.com/tilt', cmd='./notify.sh')
+`)
+
+	f.loadErrString("event_sink: must specify exactly one of url or cmd")
+}
+
+func TestLocalResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+local_resource('api', cmd='go build ./cmd/api', deps=['cmd/api', 'pkg'], serve_cmd='./api --port=8080',
+               resource_deps=['foo'], redeploy_on_dependency_update=True)
+local_resource('codegen', cmd='make generate', deps='proto')
+`)
+
+	f.load()
+	f.assertNextManifest("foo")
+
+	api := f.assertNextManifest("api")
+	lt := api.LocalTarget()
+	assert.Equal(t, model.ToShellCmd("go build ./cmd/api"), lt.Cmd)
+	assert.Equal(t, model.ToShellCmd("./api --port=8080"), lt.ServeCmd)
+	assert.Equal(t, f.Path(), lt.Workdir)
+	assert.Equal(t, []string{f.JoinPath("cmd/api"), f.JoinPath("pkg")}, lt.Dependencies())
+	assert.Equal(t, []model.ManifestName{"foo"}, api.ResourceDependencies)
+	assert.True(t, api.RedeployOnDependencyUpdate)
+
+	codegen := f.assertNextManifest("codegen")
+	assert.True(t, codegen.LocalTarget().ServeCmd.Empty())
+	assert.Equal(t, []string{f.JoinPath("proto")}, codegen.LocalTarget().Dependencies())
+	f.assertNoMoreManifests()
+}
+
+func TestLocalResourceNeedsCmd(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource('api', deps=['cmd/api'])
+`)
+
+	f.loadErrString("local_resource: must specify a cmd, a serve_cmd, or both")
+}
+
+func TestLocalResourceNameConflict(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+local_resource('foo', cmd='make foo')
+`)
+
+	f.loadErrString(`local_resource: there's already a resource named "foo"`)
+}
+
+func TestReadinessCheckNeedsExactlyOneCheck(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', readiness_check=readiness_check(http_get='http://localhost:8000', log_regex='ready'))
+`)
+
+	f.loadErrString("readiness_check: readiness check must have only one of an HTTP GET, an exec command, or a log regex")
+}
+
 func TestUpdateModeK8S(t *testing.T) {
 	for _, testCase := range []struct {
 		name               string
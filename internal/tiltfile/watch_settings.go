@@ -0,0 +1,54 @@
+package tiltfile
+
+import (
+	"fmt"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/windmilleng/tilt/internal/model"
+)
+
+func (s *tiltfileState) watchSettingsFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var mode, pollCompare string
+	var pollIntervalVal starlark.Value
+	defaultIgnores := starlark.Bool(!s.watchSettings.NoDefaultIgnores)
+	dedupByChecksum := starlark.Bool(s.watchSettings.DedupByChecksum)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"mode?", &mode,
+		"poll_interval_ms?", &pollIntervalVal,
+		"poll_compare?", &pollCompare,
+		"default_ignores?", &defaultIgnores,
+		"dedup_by_checksum?", &dedupByChecksum,
+	); err != nil {
+		return nil, err
+	}
+
+	settings := s.watchSettings
+	if mode != "" {
+		settings.Mode = model.WatchMode(mode)
+	}
+	if pollCompare != "" {
+		settings.PollCompare = model.PollCompare(pollCompare)
+	}
+	if pollIntervalVal != nil {
+		ms, err := starlark.AsInt32(pollIntervalVal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: poll_interval_ms must be an int; got %s", fn.Name(), pollIntervalVal.Type())
+		}
+		if ms < 1 {
+			return nil, fmt.Errorf("%s: poll_interval_ms must be greater than 0; got %d", fn.Name(), ms)
+		}
+		settings.PollInterval = time.Duration(ms) * time.Millisecond
+	}
+	settings.NoDefaultIgnores = !bool(defaultIgnores)
+	settings.DedupByChecksum = bool(dedupByChecksum)
+
+	err := settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	s.watchSettings = settings
+	return starlark.None, nil
+}
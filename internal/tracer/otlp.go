@@ -0,0 +1,356 @@
+package tracer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	zipkin "github.com/openzipkin/zipkin-go-opentracing"
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/logger"
+)
+
+// OpenTelemetry collectors (and Jaeger, since 1.35) accept spans over OTLP.
+// We record spans with the zipkin tracer, like the windmill backend does,
+// and send them to the collector as OTLP/HTTP JSON. Configured with the
+// standard OpenTelemetry environment variables:
+//
+//	OTEL_EXPORTER_OTLP_ENDPOINT         (default http://localhost:4318)
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT  (the full URL, if it's not <endpoint>/v1/traces)
+//	OTEL_EXPORTER_OTLP_HEADERS          (key1=val1,key2=val2)
+//	OTEL_SERVICE_NAME                   (default tilt)
+const defaultOTLPEndpoint = "http://localhost:4318"
+
+const otlpFlushInterval = 5 * time.Second
+
+// Send spans early, instead of waiting for the next flush, once this many pile up.
+const otlpMaxBatchSize = 512
+
+// What OTLP calls the library that made the spans.
+const otlpScopeName = "github.com/windmilleng/tilt"
+
+func initOTLP(ctx context.Context) (func() error, error) {
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "tilt"
+	}
+
+	exporter := newOTLPExporter(ctx, otlpTracesURL(), serviceName, TagStrToMap(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")))
+	tracer, err := zipkin.NewTracer(exporter, zipkin.TraceID128Bit(true))
+	if err != nil {
+		_ = exporter.Close()
+		return nil, errors.Wrap(err, "unable to create tracer")
+	}
+
+	opentracing.SetGlobalTracer(tracer)
+	return exporter.Close, nil
+}
+
+func otlpTracesURL() string {
+	if url := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); url != "" {
+		return url
+	}
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultOTLPEndpoint
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+}
+
+// Collects finished spans, and sends them to the collector in batches.
+type otlpExporter struct {
+	ctx         context.Context
+	url         string
+	serviceName string
+	headers     map[string]string
+	client      *http.Client
+
+	mu    sync.Mutex
+	spans []zipkin.RawSpan
+
+	flush     chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+var _ zipkin.SpanRecorder = &otlpExporter{}
+
+func newOTLPExporter(ctx context.Context, url string, serviceName string, headers map[string]string) *otlpExporter {
+	e := &otlpExporter{
+		ctx:         ctx,
+		url:         url,
+		serviceName: serviceName,
+		headers:     headers,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		flush:       make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	go e.loop()
+	return e
+}
+
+func (e *otlpExporter) RecordSpan(span zipkin.RawSpan) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	full := len(e.spans) >= otlpMaxBatchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *otlpExporter) loop() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.flush:
+		case <-e.done:
+			e.export()
+			return
+		}
+		e.export()
+	}
+}
+
+// Sends the spans we have so far. If the collector isn't there, we drop them,
+// rather than hold on to more and more.
+func (e *otlpExporter) export() {
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mu.Unlock()
+
+	if len(spans) == 0 {
+		return
+	}
+
+	err := e.post(newOTLPTraces(e.serviceName, spans))
+	if err != nil {
+		logger.Get(e.ctx).Debugf("error sending %d spans to %s: %v", len(spans), e.url, err)
+	}
+}
+
+func (e *otlpExporter) post(traces otlpTraces) error {
+	body, err := json.Marshal(traces)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Sends whatever spans are left.
+func (e *otlpExporter) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.done)
+	})
+	<-e.stopped
+	return nil
+}
+
+// The JSON encoding of the OTLP ExportTraceServiceRequest. Trace and span IDs
+// are hex, and 64-bit ints are strings, as the OTLP spec says.
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+// Span kinds.
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+	otlpSpanKindClient   = 3
+)
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+// Status codes.
+const otlpStatusCodeError = 2
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func newOTLPTraces(serviceName string, spans []zipkin.RawSpan) otlpTraces {
+	result := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		result = append(result, newOTLPSpan(span))
+	}
+	return otlpTraces{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{newOTLPKeyValue("service.name", serviceName)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: otlpScopeName},
+				Spans: result,
+			}},
+		}},
+	}
+}
+
+func newOTLPSpan(span zipkin.RawSpan) otlpSpan {
+	result := otlpSpan{
+		TraceID:           fmt.Sprintf("%016x%016x", span.Context.TraceID.High, span.Context.TraceID.Low),
+		SpanID:            fmt.Sprintf("%016x", span.Context.SpanID),
+		Name:              span.Operation,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: unixNanoString(span.Start),
+		EndTimeUnixNano:   unixNanoString(span.Start.Add(span.Duration)),
+	}
+	if span.Context.ParentSpanID != nil {
+		result.ParentSpanID = fmt.Sprintf("%016x", *span.Context.ParentSpanID)
+	}
+
+	for k, v := range span.Tags {
+		switch {
+		case k == string(ext.SpanKind):
+			switch fmt.Sprintf("%v", v) {
+			case string(ext.SpanKindRPCServerEnum):
+				result.Kind = otlpSpanKindServer
+			case string(ext.SpanKindRPCClientEnum):
+				result.Kind = otlpSpanKindClient
+			}
+		case k == string(ext.Error):
+			if v == true {
+				result.Status.Code = otlpStatusCodeError
+			}
+		default:
+			result.Attributes = append(result.Attributes, newOTLPKeyValue(k, v))
+		}
+	}
+
+	for _, record := range span.Logs {
+		event := otlpEvent{
+			TimeUnixNano: unixNanoString(record.Timestamp),
+			Name:         "log",
+		}
+		for _, field := range record.Fields {
+			switch field.Key() {
+			case "event":
+				event.Name = fmt.Sprintf("%v", field.Value())
+			case "message":
+				if result.Status.Code == otlpStatusCodeError && result.Status.Message == "" {
+					result.Status.Message = fmt.Sprintf("%v", field.Value())
+				}
+				event.Attributes = append(event.Attributes, newOTLPKeyValue(field.Key(), field.Value()))
+			default:
+				event.Attributes = append(event.Attributes, newOTLPKeyValue(field.Key(), field.Value()))
+			}
+		}
+		result.Events = append(result.Events, event)
+	}
+	return result
+}
+
+func newOTLPKeyValue(key string, value interface{}) otlpKeyValue {
+	var v otlpAnyValue
+	switch value := value.(type) {
+	case bool:
+		v.BoolValue = &value
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s := fmt.Sprintf("%d", value)
+		v.IntValue = &s
+	case float32:
+		f := float64(value)
+		v.DoubleValue = &f
+	case float64:
+		v.DoubleValue = &value
+	default:
+		s := fmt.Sprintf("%v", value)
+		v.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
+
+func unixNanoString(t time.Time) string {
+	return fmt.Sprintf("%d", t.UnixNano())
+}
@@ -0,0 +1,124 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	zipkin "github.com/openzipkin/zipkin-go-opentracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTLPExport(t *testing.T) {
+	var mu sync.Mutex
+	var received []otlpTraces
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var traces otlpTraces
+		err := json.NewDecoder(r.Body).Decode(&traces)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, traces)
+		auth = r.Header.Get("Authorization")
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	exporter := newOTLPExporter(context.Background(), server.URL+"/v1/traces", "my-tilt",
+		map[string]string{"Authorization": "Bearer sekret"})
+	tracer, err := zipkin.NewTracer(exporter, zipkin.TraceID128Bit(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := tracer.StartSpan("update")
+	update.SetTag("resource", "fe")
+	update.SetTag("files_changed", 2)
+	build := tracer.StartSpan("Building Dockerfile", opentracing.ChildOf(update.Context()))
+	ext.Error.Set(build, true)
+	build.LogFields(log.String("event", "error"), log.String("message", "oh no"))
+	build.Finish()
+	update.Finish()
+
+	err = exporter.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "Bearer sekret", auth)
+	if assert.Len(t, received, 1) {
+		rs := received[0].ResourceSpans[0]
+		assert.Equal(t, "service.name", rs.Resource.Attributes[0].Key)
+		assert.Equal(t, "my-tilt", *rs.Resource.Attributes[0].Value.StringValue)
+
+		spans := rs.ScopeSpans[0].Spans
+		if assert.Len(t, spans, 2) {
+			b, u := spans[0], spans[1]
+			assert.Equal(t, "Building Dockerfile", b.Name)
+			assert.Equal(t, "update", u.Name)
+			assert.Len(t, u.TraceID, 32)
+			assert.Len(t, u.SpanID, 16)
+			assert.Equal(t, u.TraceID, b.TraceID)
+			assert.Equal(t, u.SpanID, b.ParentSpanID)
+			assert.Equal(t, "", u.ParentSpanID)
+
+			assert.Equal(t, otlpStatusCodeError, b.Status.Code)
+			assert.Equal(t, "oh no", b.Status.Message)
+			assert.Equal(t, "error", b.Events[0].Name)
+			assert.Equal(t, 0, u.Status.Code)
+
+			attrs := make(map[string]otlpAnyValue)
+			for _, kv := range u.Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			assert.Equal(t, "fe", *attrs["resource"].StringValue)
+			assert.Equal(t, "2", *attrs["files_changed"].IntValue)
+		}
+	}
+}
+
+func TestOTLPTracesURL(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint       string
+		tracesEndpoint string
+		expected       string
+	}{
+		{"", "", "http://localhost:4318/v1/traces"},
+		{"http://collector:4318/", "", "http://collector:4318/v1/traces"},
+		{"http://collector:4318", "http://jaeger:4318/traces", "http://jaeger:4318/traces"},
+	} {
+		t.Run(fmt.Sprintf("%s-%s", tc.endpoint, tc.tracesEndpoint), func(t *testing.T) {
+			defer setEnv(t, "OTEL_EXPORTER_OTLP_ENDPOINT", tc.endpoint)()
+			defer setEnv(t, "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", tc.tracesEndpoint)()
+			assert.Equal(t, tc.expected, otlpTracesURL())
+		})
+	}
+}
+
+func setEnv(t *testing.T, key, value string) func() {
+	old, ok := os.LookupEnv(key)
+	err := os.Setenv(key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if ok {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
@@ -25,6 +25,7 @@ const (
 	Windmill TracerBackend = iota
 	Lightstep
 	Jaeger
+	OTLP
 )
 
 type zipkinLogger struct {
@@ -46,6 +47,8 @@ func Init(ctx context.Context, tracer TracerBackend) (func() error, error) {
 		return initLightStep(ctx)
 	case Jaeger:
 		return initJaeger(ctx)
+	case OTLP:
+		return initOTLP(ctx)
 	default:
 		return nil, fmt.Errorf("Init: Invalid Tracer backend: %d", tracer)
 	}
@@ -95,6 +98,8 @@ func StringToTracerBackend(s string) (TracerBackend, error) {
 		return Lightstep, nil
 	case "jaeger":
 		return Jaeger, nil
+	case "otlp":
+		return OTLP, nil
 	default:
 		return Windmill, fmt.Errorf("Invalid Tracer backend: %s", s)
 	}
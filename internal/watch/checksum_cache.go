@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"os"
+	"sync"
+)
+
+// Remembers the contents of watched files, so that we can drop the changes
+// that didn't actually change anything.
+//
+// Some tools (and some file systems, like Docker Desktop's gRPC-FUSE mounts)
+// rewrite files with the same bytes, or touch whole trees at once. Without
+// this, every one of those kicks off a build that does nothing.
+//
+// We hash a file the first time it changes, rather than hashing the whole
+// tree up front, which could take a long time for a big repo. So the first
+// change to each file always counts.
+//
+// Ask about a file once the writes to it have settled down. A file that's
+// halfway through being rewritten looks changed.
+type ChecksumCache struct {
+	mu        sync.Mutex
+	checksums map[string]string
+}
+
+func NewChecksumCache() *ChecksumCache {
+	return &ChecksumCache{
+		checksums: make(map[string]string),
+	}
+}
+
+// Returns false only if we're sure the file has the same contents as the
+// last time we looked. Anything we can't hash (directories, deleted files,
+// files we can't read), and any file we haven't seen before, counts as a change.
+func (c *ChecksumCache) Changed(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		delete(c.checksums, path)
+		return true
+	}
+
+	sum, err := checksumFile(path)
+	if err != nil {
+		delete(c.checksums, path)
+		return true
+	}
+
+	old, ok := c.checksums[path]
+	c.checksums[path] = sum
+	return !ok || old != sum
+}
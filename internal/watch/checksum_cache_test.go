@@ -0,0 +1,48 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestChecksumCache(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("a.txt", "hello")
+	f.WriteFile("sub/b.txt", "hello")
+
+	c := NewChecksumCache()
+
+	// The first time we see a file, we don't know what it looked like before.
+	assert.True(t, c.Changed(f.JoinPath("a.txt")))
+	assert.True(t, c.Changed(f.JoinPath("sub", "b.txt")))
+
+	// Touching a file doesn't change it.
+	later := time.Now().Add(time.Hour)
+	err := os.Chtimes(f.JoinPath("a.txt"), later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, c.Changed(f.JoinPath("a.txt")))
+
+	f.WriteFile("sub/b.txt", "hello")
+	assert.False(t, c.Changed(f.JoinPath("sub", "b.txt")))
+
+	f.WriteFile("sub/b.txt", "goodbye")
+	assert.True(t, c.Changed(f.JoinPath("sub", "b.txt")))
+	assert.False(t, c.Changed(f.JoinPath("sub", "b.txt")))
+
+	// Files we haven't seen, and files that went away, changed.
+	f.WriteFile("c.txt", "hello")
+	assert.True(t, c.Changed(f.JoinPath("c.txt")))
+	f.Rm("a.txt")
+	assert.True(t, c.Changed(f.JoinPath("a.txt")))
+	assert.True(t, c.Changed(filepath.Join(f.Path(), "sub")))
+}
@@ -0,0 +1,113 @@
+package watch
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/windmilleng/tilt/internal/ospath"
+)
+
+// Directories that never have anything a build needs, and whose contents
+// churn whenever the user runs git, or their editor saves its settings.
+var defaultIgnoredDirs = []string{
+	".git", ".hg", ".svn", ".bzr",
+	".idea", ".vscode",
+}
+
+// Temp files, backups and lock files that editors write next to the file being saved.
+var defaultIgnoredFilePatterns = []string{
+	// Vim swap files (.main.go.swp, .main.go.swo, ...) and backups
+	"*.sw[a-p]", "*.swx", "*~",
+
+	// Vim writes this to check that it can create files in a directory
+	"4913",
+
+	// Emacs lock files and auto-saves
+	".#*", "#*#",
+}
+
+// Ignores editor temp files and version control internals, which would
+// otherwise kick off a build (or two) on every save.
+//
+// Applied to every watch, unless the Tiltfile turns it off with
+// watch_settings(default_ignores=False).
+type DefaultIgnores struct {
+	// The watched paths. We only look for ignored directories under them,
+	// so that watching a project inside (say) ~/.vscode still works.
+	roots []string
+}
+
+var _ PathMatcher = DefaultIgnores{}
+
+func NewDefaultIgnores(roots []string) DefaultIgnores {
+	absRoots := make([]string, 0, len(roots))
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err == nil {
+			root = abs
+		}
+		absRoots = append(absRoots, root)
+	}
+	return DefaultIgnores{roots: absRoots}
+}
+
+func (d DefaultIgnores) Matches(path string, isDir bool) (bool, error) {
+	if d.inDefaultIgnoredDir(path, isDir) {
+		return true, nil
+	}
+	if isDir {
+		return false, nil
+	}
+
+	base := filepath.Base(path)
+	for _, pattern := range defaultIgnoredFilePatterns {
+		matched, err := filepath.Match(pattern, base)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d DefaultIgnores) MatchesEntireDir(dir string) (bool, error) {
+	return d.inDefaultIgnoredDir(dir, true), nil
+}
+
+// Returns true if any directory on the path, below the watched root that
+// it's in, is one of defaultIgnoredDirs.
+func (d DefaultIgnores) inDefaultIgnoredDir(path string, isDir bool) bool {
+	rel, ok := d.relToRoot(path)
+	if !ok || rel == "." {
+		return false
+	}
+
+	dirs := strings.Split(filepath.ToSlash(rel), "/")
+	if !isDir {
+		dirs = dirs[:len(dirs)-1]
+	}
+	for _, d := range dirs {
+		for _, ignored := range defaultIgnoredDirs {
+			if d == ignored {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// The path relative to the innermost watched root that it's in.
+func (d DefaultIgnores) relToRoot(path string) (string, bool) {
+	result := ""
+	found := false
+	for _, root := range d.roots {
+		rel, ok := ospath.Child(root, path)
+		if ok && (!found || len(rel) < len(result)) {
+			result = rel
+			found = true
+		}
+	}
+	return result, found
+}
@@ -0,0 +1,72 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultIgnores(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"/src/main.go", false, false},
+		{"/src/.main.go.swp", false, true},
+		{"/src/.main.go.swo", false, true},
+		{"/src/main.go~", false, true},
+		{"/src/4913", false, true},
+		{"/src/.#main.go", false, true},
+		{"/src/#main.go#", false, true},
+		{"/src/.git", true, true},
+		{"/src/.git/index", false, true},
+		{"/src/.idea/workspace.xml", false, true},
+		{"/src/.vscode/settings.json", false, true},
+		{"/src/.gitignore", false, false},
+		{"/src/git/main.go", false, false},
+
+		// Patterns for files don't apply to directories.
+		{"/src/backup~", true, false},
+
+		// Only directories under the watched root count.
+		{"/src", true, false},
+		{"/elsewhere/.git/index", false, false},
+	} {
+		actual, err := NewDefaultIgnores([]string{"/src"}).Matches(tc.path, tc.isDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tc.expected, actual, tc.path)
+	}
+}
+
+func TestDefaultIgnoresEntireDir(t *testing.T) {
+	ignored, err := NewDefaultIgnores([]string{"/src"}).MatchesEntireDir("/src/.git/objects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ignored)
+
+	ignored, err = NewDefaultIgnores([]string{"/src"}).MatchesEntireDir("/src/pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, ignored)
+}
+
+func TestDefaultIgnoresRootInIgnoredDir(t *testing.T) {
+	d := NewDefaultIgnores([]string{"/home/me/.vscode/extensions/my-ext", "/home/me/.vscode/extensions/my-ext/lib"})
+	for path, expected := range map[string]bool{
+		"/home/me/.vscode/extensions/my-ext/main.ts":          false,
+		"/home/me/.vscode/extensions/my-ext/lib/util.ts":      false,
+		"/home/me/.vscode/extensions/my-ext/.git/index":       true,
+		"/home/me/.vscode/extensions/my-ext/lib/.idea/ws.xml": true,
+	} {
+		actual, err := d.Matches(path, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, actual, path)
+	}
+}
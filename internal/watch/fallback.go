@@ -0,0 +1,144 @@
+package watch
+
+import "sync"
+
+// A watcher that uses primary until it fails to start or reports an error,
+// then closes it and switches to the watcher that makeFallback makes.
+//
+// Watchman can go away at any time (the daemon gets restarted, or runs out of
+// its own OS watches), and we'd rather keep watching with a built-in watcher
+// than stop noticing changes.
+type fallbackNotify struct {
+	primary      Notify
+	makeFallback func() (Notify, error)
+	onFallback   func(err error)
+
+	mu      sync.Mutex
+	current Notify
+
+	events    chan FileEvent
+	errors    chan error
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// onFallback is called with the error that made us give up on primary.
+func NewFallbackWatcher(primary Notify, makeFallback func() (Notify, error), onFallback func(err error)) *fallbackNotify {
+	return &fallbackNotify{
+		primary:      primary,
+		makeFallback: makeFallback,
+		onFallback:   onFallback,
+		current:      primary,
+		events:       make(chan FileEvent),
+		errors:       make(chan error),
+		stop:         make(chan struct{}),
+	}
+}
+
+func (n *fallbackNotify) Start() error {
+	// Read from the watcher before starting it, so that it doesn't block
+	// on changes that come in while it's still setting up.
+	go n.forward(n.primary)
+
+	err := n.primary.Start()
+	if err != nil {
+		return n.fallBack(err)
+	}
+	return nil
+}
+
+func (n *fallbackNotify) forward(watcher Notify) {
+	for {
+		select {
+		case <-n.stop:
+			return
+		case e, ok := <-watcher.Events():
+			if !ok {
+				return
+			}
+			select {
+			case n.events <- e:
+			case <-n.stop:
+				return
+			}
+		case err, ok := <-watcher.Errors():
+			if !ok {
+				return
+			}
+			if watcher == n.primary {
+				err = n.fallBack(err)
+				if err == nil {
+					return
+				}
+			}
+			select {
+			case n.errors <- err:
+			case <-n.stop:
+				return
+			}
+			if watcher == n.primary {
+				return
+			}
+		}
+	}
+}
+
+// Returns an error if we couldn't start the fallback watcher either.
+func (n *fallbackNotify) fallBack(cause error) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	select {
+	case <-n.stop:
+		return nil
+	default:
+	}
+	if n.current != n.primary {
+		return nil
+	}
+
+	_ = n.primary.Close()
+	n.onFallback(cause)
+
+	fallback, err := n.makeFallback()
+	if err != nil {
+		return err
+	}
+	n.current = fallback
+	go n.forward(fallback)
+	return fallback.Start()
+}
+
+func (n *fallbackNotify) Close() error {
+	var err error
+	n.closeOnce.Do(func() {
+		close(n.stop)
+
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		err = n.current.Close()
+	})
+	return err
+}
+
+// Reports the paths of whichever watcher we're using now.
+func (n *fallbackNotify) WatchedPaths() []string {
+	n.mu.Lock()
+	current := n.current
+	n.mu.Unlock()
+	if r, ok := current.(WatchedPathsReporter); ok {
+		return r.WatchedPaths()
+	}
+	return nil
+}
+
+func (n *fallbackNotify) Events() chan FileEvent {
+	return n.events
+}
+
+func (n *fallbackNotify) Errors() chan error {
+	return n.errors
+}
+
+var _ Notify = &fallbackNotify{}
+var _ WatchedPathsReporter = &fallbackNotify{}
@@ -0,0 +1,115 @@
+package watch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbackOnStartError(t *testing.T) {
+	primary := newFakeNotify()
+	primary.startErr = fmt.Errorf("watch-project failed")
+	fallback := newFakeNotify()
+
+	var cause error
+	n := NewFallbackWatcher(primary, func() (Notify, error) { return fallback, nil }, func(err error) { cause = err })
+	defer func() { _ = n.Close() }()
+
+	err := n.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, primary.startErr, cause)
+	assert.True(t, primary.closed)
+	assert.True(t, fallback.started)
+
+	fallback.events <- FileEvent{Path: "a.txt"}
+	assertFallbackEvent(t, n, "a.txt")
+}
+
+func TestFallbackOnWatchError(t *testing.T) {
+	primary := newFakeNotify()
+	fallback := newFakeNotify()
+
+	fellBack := make(chan error, 1)
+	n := NewFallbackWatcher(primary, func() (Notify, error) { return fallback, nil }, func(err error) { fellBack <- err })
+	defer func() { _ = n.Close() }()
+
+	err := n.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	primary.events <- FileEvent{Path: "a.txt"}
+	assertFallbackEvent(t, n, "a.txt")
+
+	primary.errors <- fmt.Errorf("reading from watchman: EOF")
+	select {
+	case err := <-fellBack:
+		assert.Equal(t, "reading from watchman: EOF", err.Error())
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for fallback")
+	}
+
+	fallback.events <- FileEvent{Path: "b.txt"}
+	assertFallbackEvent(t, n, "b.txt")
+}
+
+func TestFallbackReportsCurrentWatchedPaths(t *testing.T) {
+	primary := newFakeNotify()
+	primary.paths = []string{"/src"}
+	primary.startErr = fmt.Errorf("watch-project failed")
+	fallback := newFakeNotify()
+	fallback.paths = []string{"/src", "/src/pkg"}
+
+	n := NewFallbackWatcher(primary, func() (Notify, error) { return fallback, nil }, func(err error) {})
+	defer func() { _ = n.Close() }()
+	assert.Equal(t, []string{"/src"}, n.WatchedPaths())
+
+	err := n.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"/src", "/src/pkg"}, n.WatchedPaths())
+}
+
+func assertFallbackEvent(t *testing.T, n Notify, path string) {
+	select {
+	case e := <-n.Events():
+		assert.Equal(t, path, e.Path)
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", path)
+	}
+}
+
+type fakeNotify struct {
+	startErr error
+	started  bool
+	closed   bool
+	paths    []string
+	events   chan FileEvent
+	errors   chan error
+}
+
+func newFakeNotify() *fakeNotify {
+	return &fakeNotify{
+		events: make(chan FileEvent),
+		errors: make(chan error),
+	}
+}
+
+func (n *fakeNotify) Start() error {
+	n.started = true
+	return n.startErr
+}
+
+func (n *fakeNotify) Close() error {
+	n.closed = true
+	return nil
+}
+
+func (n *fakeNotify) WatchedPaths() []string { return n.paths }
+func (n *fakeNotify) Events() chan FileEvent { return n.events }
+func (n *fakeNotify) Errors() chan error     { return n.errors }
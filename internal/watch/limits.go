@@ -0,0 +1,155 @@
+package watch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// The limit we recommend when a user runs out of inotify watches or instances.
+// Matches what most IDEs and file-syncing tools ask for.
+const (
+	recommendedMaxUserWatches   = 524288
+	recommendedMaxUserInstances = 512
+)
+
+// How many of the directories using the most watches to list in the error.
+const maxReportedWatchDirs = 10
+
+type WatchLimitKind string
+
+const (
+	// We couldn't add a watch, because the user is out of
+	// inotify watches (ENOSPC from inotify_add_watch).
+	WatchLimitWatches WatchLimitKind = "max_user_watches"
+
+	// We couldn't create a watcher, because the user is out of
+	// inotify instances or file descriptors (EMFILE from inotify_init).
+	WatchLimitInstances WatchLimitKind = "max_user_instances"
+)
+
+type DirWatchCount struct {
+	Dir   string
+	Count int
+}
+
+// Returned when the OS won't let us watch any more files.
+//
+// Everything that was watched before we hit the limit stays watched,
+// so callers can keep going with partial coverage.
+type WatchLimitError struct {
+	Kind WatchLimitKind
+
+	// The path we were trying to watch when we hit the limit.
+	Path string
+
+	// The current value of the limit, or 0 if we couldn't read it.
+	Limit int
+
+	// The directories using the most watches, in descending order.
+	Dirs []DirWatchCount
+
+	Err error
+}
+
+func (e *WatchLimitError) Error() string {
+	sysctl := fmt.Sprintf("fs.inotify.%s", e.Kind)
+	recommended := recommendedMaxUserWatches
+	if e.Kind == WatchLimitInstances {
+		recommended = recommendedMaxUserInstances
+	}
+
+	var sb strings.Builder
+	if e.Kind == WatchLimitInstances {
+		sb.WriteString("Tilt ran out of inotify instances (or file descriptors)")
+	} else {
+		sb.WriteString("Tilt ran out of inotify watches")
+	}
+	if e.Limit > 0 {
+		sb.WriteString(fmt.Sprintf(" (%s = %d)", sysctl, e.Limit))
+	}
+	if e.Path != "" {
+		sb.WriteString(fmt.Sprintf(" while watching %s", e.Path))
+	}
+	sb.WriteString(fmt.Sprintf(": %v\n", e.Err))
+	sb.WriteString("Changes to files that aren't watched won't trigger updates.\n")
+
+	if len(e.Dirs) > 0 {
+		sb.WriteString("Directories using the most watches:\n")
+		for _, d := range e.Dirs {
+			sb.WriteString(fmt.Sprintf("  %s: %d\n", d.Dir, d.Count))
+		}
+	}
+
+	sb.WriteString("To raise the limit, run:\n")
+	sb.WriteString(fmt.Sprintf("  sudo sysctl %s=%d\n", sysctl, recommended))
+	sb.WriteString("To make it permanent, add this line to /etc/sysctl.conf:\n")
+	sb.WriteString(fmt.Sprintf("  %s=%d", sysctl, recommended))
+	if e.Kind == WatchLimitInstances {
+		sb.WriteString("\nIf that doesn't help, raise the open file limit with `ulimit -n`.")
+	}
+	return sb.String()
+}
+
+func IsWatchLimitError(err error) bool {
+	_, ok := errors.Cause(err).(*WatchLimitError)
+	return ok
+}
+
+// Returns the kind of limit that err indicates we hit, if any.
+func watchLimitKind(err error) (WatchLimitKind, bool) {
+	switch errors.Cause(err) {
+	case syscall.ENOSPC:
+		return WatchLimitWatches, true
+	case syscall.EMFILE:
+		return WatchLimitInstances, true
+	}
+	return "", false
+}
+
+func readWatchLimit(kind WatchLimitKind) int {
+	contents, err := ioutil.ReadFile(filepath.Join("/proc/sys/fs/inotify", string(kind)))
+	if err != nil {
+		return 0
+	}
+	var limit int
+	_, err = fmt.Sscanf(strings.TrimSpace(string(contents)), "%d", &limit)
+	if err != nil {
+		return 0
+	}
+	return limit
+}
+
+// Groups the watched paths by the top-level directory under the root
+// they were watched for, so that a huge node_modules shows up as one line.
+func countWatchesByDir(watched map[string]string) []DirWatchCount {
+	counts := make(map[string]int)
+	for path, root := range watched {
+		dir := root
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			dir = filepath.Join(root, strings.Split(rel, string(filepath.Separator))[0])
+		}
+		counts[dir]++
+	}
+
+	result := make([]DirWatchCount, 0, len(counts))
+	for dir, count := range counts {
+		result = append(result, DirWatchCount{Dir: dir, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Dir < result[j].Dir
+	})
+	if len(result) > maxReportedWatchDirs {
+		result = result[:maxReportedWatchDirs]
+	}
+	return result
+}
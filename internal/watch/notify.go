@@ -1,12 +1,98 @@
 package watch
 
+import "github.com/windmilleng/tilt/internal/ospath"
+
 type FileEvent struct {
 	Path string
 }
 
 type Notify interface {
+	// Start watching the paths set at init time.
+	Start() error
+
+	// Stop watching and close all channels.
 	Close() error
-	Add(name string) error
+
 	Events() chan FileEvent
 	Errors() chan error
 }
+
+// A watcher that can tell us what it's registered with the OS (or Watchman),
+// so that `tilt dump watches` shows what the watcher is really doing.
+type WatchedPathsReporter interface {
+	// The paths we're watching right now, sorted. What a "path" is depends
+	// on the watcher: the naive watcher adds an OS watch for every directory,
+	// the polling watcher stats every file, and Watchman and FSEvents
+	// watch each root recursively.
+	WatchedPaths() []string
+}
+
+// When we watch a directory, we usually want to ignore some of the
+// files under it (e.g., the ones that a manifest's dockerignore excludes).
+//
+// Mirrors model.PathMatcher, so that matchers built for manifests
+// can be passed straight through.
+type PathMatcher interface {
+	Matches(file string, isDir bool) (bool, error)
+}
+
+// A PathMatcher that matches nothing.
+type EmptyMatcher struct{}
+
+func (EmptyMatcher) Matches(f string, isDir bool) (bool, error) { return false, nil }
+
+var _ PathMatcher = EmptyMatcher{}
+
+// Returns true if the matcher says the path should be ignored.
+// Errors are treated as "don't ignore", so that we err on the side
+// of reporting too many changes.
+func isIgnored(ignore PathMatcher, path string, isDir bool) bool {
+	if ignore == nil {
+		return false
+	}
+	ignored, err := ignore.Matches(path, isDir)
+	return err == nil && ignored
+}
+
+// Mirrors model.EntireDirMatcher. Matchers that implement it let us skip
+// walking (and watching) directories that are entirely ignored.
+type entireDirMatcher interface {
+	MatchesEntireDir(dir string) (bool, error)
+}
+
+// Returns true if the matcher says everything under dir should be ignored.
+func ignoresEntireDir(ignore PathMatcher, dir string) bool {
+	em, ok := ignore.(entireDirMatcher)
+	if !ok {
+		return false
+	}
+	ignored, err := em.MatchesEntireDir(dir)
+	return err == nil && ignored
+}
+
+// Returns the paths that aren't inside any of the other paths.
+// A recursive watch on those covers everything.
+func watchRoots(paths []string) []string {
+	var roots []string
+	for i, p := range paths {
+		covered := false
+		for j, other := range paths {
+			if i == j {
+				continue
+			}
+			if p == other && j < i {
+				// Keep the first of any duplicates.
+				covered = true
+				break
+			}
+			if p != other && ospath.IsChild(other, p) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			roots = append(roots, p)
+		}
+	}
+	return roots
+}
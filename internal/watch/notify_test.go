@@ -30,10 +30,7 @@ func TestEventOrdering(t *testing.T) {
 	for i, _ := range dirs {
 		dir := f.TempDir("watched")
 		dirs[i] = dir
-		err := f.notify.Add(dir)
-		if err != nil {
-			t.Fatal(err)
-		}
+		f.watch(dir)
 	}
 
 	f.fsync()
@@ -64,16 +61,13 @@ func TestWatchesAreRecursive(t *testing.T) {
 	f.MkdirAll(subPath)
 
 	// watch parent
-	err := f.notify.Add(root)
-	if err != nil {
-		t.Fatal(err)
-	}
+	f.watch(root)
 
 	f.fsync()
 	f.events = nil
 	// change sub directory
 	changeFilePath := filepath.Join(subPath, "change")
-	_, err = os.OpenFile(changeFilePath, os.O_RDONLY|os.O_CREATE, 0666)
+	_, err := os.OpenFile(changeFilePath, os.O_RDONLY|os.O_CREATE, 0666)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -88,10 +82,7 @@ func TestNewDirectoriesAreRecursivelyWatched(t *testing.T) {
 	root := f.TempDir("root")
 
 	// watch parent
-	err := f.notify.Add(root)
-	if err != nil {
-		t.Fatal(err)
-	}
+	f.watch(root)
 	f.fsync()
 	f.events = nil
 
@@ -101,7 +92,7 @@ func TestNewDirectoriesAreRecursivelyWatched(t *testing.T) {
 
 	// change something inside sub directory
 	changeFilePath := filepath.Join(subPath, "change")
-	_, err = os.OpenFile(changeFilePath, os.O_RDONLY|os.O_CREATE, 0666)
+	_, err := os.OpenFile(changeFilePath, os.O_RDONLY|os.O_CREATE, 0666)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -115,10 +106,7 @@ func TestWatchNonExistentPath(t *testing.T) {
 	root := f.TempDir("root")
 	path := filepath.Join(root, "change")
 
-	err := f.notify.Add(path)
-	if err != nil {
-		t.Fatal(err)
-	}
+	f.watch(path)
 
 	f.fsync()
 
@@ -135,10 +123,7 @@ func TestWatchNonExistentPathDoesNotFireSiblingEvent(t *testing.T) {
 	watchedFile := filepath.Join(root, "a.txt")
 	unwatchedSibling := filepath.Join(root, "b.txt")
 
-	err := f.notify.Add(watchedFile)
-	if err != nil {
-		t.Fatal(err)
-	}
+	f.watch(watchedFile)
 
 	f.fsync()
 
@@ -157,13 +142,10 @@ func TestRemove(t *testing.T) {
 	d1 := "hello\ngo\n"
 	f.WriteFile(path, d1)
 
-	err := f.notify.Add(path)
-	if err != nil {
-		t.Fatal(err)
-	}
+	f.watch(path)
 	f.fsync()
 	f.events = nil
-	err = os.Remove(path)
+	err := os.Remove(path)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -181,11 +163,9 @@ func TestRemoveAndAddBack(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = f.notify.Add(path)
-	if err != nil {
-		t.Fatal(err)
-	}
-	f.assertEvents(path)
+	f.watch(path)
+	f.fsync()
+	f.events = nil
 
 	err = os.Remove(path)
 	if err != nil {
@@ -213,14 +193,11 @@ func TestSingleFile(t *testing.T) {
 	d1 := "hello\ngo\n"
 	f.WriteFile(path, d1)
 
-	err := f.notify.Add(path)
-	if err != nil {
-		t.Fatal(err)
-	}
+	f.watch(path)
 	f.fsync()
 
 	d2 := []byte("hello\nworld\n")
-	err = ioutil.WriteFile(path, d2, 0644)
+	err := ioutil.WriteFile(path, d2, 0644)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -277,10 +254,7 @@ func TestWatchBrokenLink(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = f.notify.Add(newRoot.Path())
-	if err != nil {
-		t.Fatal(err)
-	}
+	f.watch(newRoot.Path())
 
 	os.Remove(link)
 	f.assertEvents(link)
@@ -294,15 +268,12 @@ func TestMoveAndReplace(t *testing.T) {
 	file := filepath.Join(root, "myfile")
 	f.WriteFile(file, "hello")
 
-	err := f.notify.Add(file)
-	if err != nil {
-		t.Fatal(err)
-	}
+	f.watch(file)
 
 	tmpFile := filepath.Join(root, ".myfile.swp")
 	f.WriteFile(tmpFile, "world")
 
-	err = os.Rename(tmpFile, file)
+	err := os.Rename(tmpFile, file)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -347,39 +318,125 @@ func TestWatchNonexistentDirectory(t *testing.T) {
 	f.assertEvents(file)
 }
 
-type notifyFixture struct {
-	*tempdir.TempDirFixture
-	notify  Notify
-	watched string
-	events  []FileEvent
-}
+func TestWatchSymlinkedDirectory(t *testing.T) {
+	f := newNotifyFixture(t)
+	defer f.tearDown()
 
-func newNotifyFixture(t *testing.T) *notifyFixture {
-	SetLimitChecksEnabled(false)
-	notify, err := NewWatcher()
+	target := f.TempDir("target")
+	root := f.TempDir("root")
+	link := filepath.Join(root, "linked")
+	err := os.Symlink(target, link)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	f := tempdir.NewTempDirFixture(t)
-	watched := f.TempDir("watched")
+	f.watch(root)
+	f.fsync()
+	f.events = nil
+
+	// Changes to the target show up under the link's path.
+	f.WriteFile(filepath.Join(target, "a.txt"), "hello")
+	f.assertEvents(filepath.Join(link, "a.txt"))
+}
+
+func TestWatchSymlinkCycle(t *testing.T) {
+	f := newNotifyFixture(t)
+	defer f.tearDown()
 
-	err = notify.Add(watched)
+	root := f.TempDir("root")
+	err := os.Symlink(root, filepath.Join(root, "loop"))
 	if err != nil {
 		t.Fatal(err)
 	}
-	return &notifyFixture{
-		TempDirFixture: f,
-		watched:        watched,
-		notify:         notify,
+
+	f.watch(root)
+	f.fsync()
+	f.events = nil
+
+	path := filepath.Join(root, "a.txt")
+	f.WriteFile(path, "hello")
+	f.assertEvents(path)
+}
+
+func TestIgnoredPathsDontFireEvents(t *testing.T) {
+	f := newNotifyFixtureWith(t, func(paths []string) (Notify, error) {
+		return NewWatcher(paths, suffixMatcher(".tmp"))
+	})
+	defer f.tearDown()
+
+	ignored := filepath.Join(f.watched, "a.tmp")
+	kept := filepath.Join(f.watched, "a.txt")
+	f.WriteFile(ignored, "hello")
+	f.WriteFile(kept, "hello")
+	f.assertEvents(kept)
+}
+
+func TestWatchRoots(t *testing.T) {
+	roots := watchRoots([]string{"/a/b", "/a", "/c", "/a/b/c", "/c", "/cd"})
+	expected := []string{"/a", "/c", "/cd"}
+	if strings.Join(roots, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected roots %v, got %v", expected, roots)
+	}
+}
+
+type suffixMatcher string
+
+func (m suffixMatcher) Matches(f string, isDir bool) (bool, error) {
+	return strings.HasSuffix(f, string(m)), nil
+}
+
+func (m suffixMatcher) MatchesEntireDir(f string) (bool, error) {
+	return m.Matches(f, true)
+}
+
+type notifyFixture struct {
+	*tempdir.TempDirFixture
+	newNotify func(paths []string) (Notify, error)
+	notify    Notify
+	paths     []string
+	watched   string
+	events    []FileEvent
+}
+
+func newNotifyFixture(t *testing.T) *notifyFixture {
+	return newNotifyFixtureWith(t, func(paths []string) (Notify, error) {
+		return NewWatcher(paths, EmptyMatcher{})
+	})
+}
+
+func newNotifyFixtureWith(t *testing.T, newNotify func(paths []string) (Notify, error)) *notifyFixture {
+	SetLimitChecksEnabled(false)
+
+	f := &notifyFixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		newNotify:      newNotify,
 	}
+	f.watched = f.TempDir("watched")
+	f.watch(f.watched)
+	return f
 }
 
+// Watchers are given all their paths up front, so watching a new path
+// means replacing the watcher.
 func (f *notifyFixture) watch(path string) {
-	err := f.notify.Add(path)
+	f.paths = append(f.paths, path)
+
+	if f.notify != nil {
+		err := f.notify.Close()
+		if err != nil {
+			f.T().Fatal(err)
+		}
+	}
+
+	notify, err := f.newNotify(f.paths)
+	if err != nil {
+		f.T().Fatal(err)
+	}
+	err = notify.Start()
 	if err != nil {
-		f.T().Fatalf("notify.Add: %s", path)
+		f.T().Fatalf("notify.Start: %v", err)
 	}
+	f.notify = notify
 }
 
 func (f *notifyFixture) assertEvents(expected ...string) {
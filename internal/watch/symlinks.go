@@ -0,0 +1,154 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/windmilleng/tilt/internal/ospath"
+)
+
+// A symlink to a directory that we followed while walking.
+type followedLink struct {
+	// Where the link is. Files under the directory are reported under this path.
+	path string
+
+	// The real path of the directory the link points to.
+	target string
+}
+
+// Like filepath.Walk, but follows symlinks to directories (as you'd find with
+// pnpm, Bazel's convenience symlinks, or a GOPATH full of links). Files under a
+// symlinked directory are reported under the link's path, because that's the
+// path the caller (and the caller's ignores) know about.
+//
+// We only follow a link if its target isn't somewhere we've already been:
+// under root, under one of the covered directories, or under another link we
+// followed. Nor do we follow a link to a directory that contains one of those.
+// That keeps symlink cycles from going on forever, and keeps us
+// from reporting the same file under two names.
+//
+// Returns the links we followed.
+func walkFollowingSymlinks(root string, covered []string, fn filepath.WalkFunc) ([]followedLink, error) {
+	w := &symlinkWalker{
+		fn:      fn,
+		visited: append([]string(nil), covered...),
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		real, realErr := filepath.EvalSymlinks(root)
+		if realErr == nil {
+			w.visited = append(w.visited, real)
+		}
+		err = w.walk(root, info)
+	}
+	if err == filepath.SkipDir {
+		err = nil
+	}
+	return w.links, err
+}
+
+// The real paths of the given paths, skipping the ones that don't exist.
+func realPaths(paths []string) []string {
+	var result []string
+	for _, p := range paths {
+		real, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			result = append(result, real)
+		}
+	}
+	return result
+}
+
+type symlinkWalker struct {
+	fn      filepath.WalkFunc
+	visited []string
+	links   []followedLink
+}
+
+// Mirrors filepath.walk.
+func (w *symlinkWalker) walk(path string, info os.FileInfo) error {
+	if !info.IsDir() {
+		return w.fn(path, info, nil)
+	}
+
+	names, err := readDirNames(path)
+	err1 := w.fn(path, info, err)
+	if err != nil || err1 != nil {
+		return err1
+	}
+
+	for _, name := range names {
+		child := filepath.Join(path, name)
+		childInfo, err := os.Lstat(child)
+		if err != nil {
+			err = w.fn(child, childInfo, err)
+			if err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+
+		linkCount := len(w.links)
+		followed := false
+		if childInfo.Mode()&os.ModeSymlink != 0 {
+			if target, real, ok := w.followable(child); ok {
+				w.visited = append(w.visited, real)
+				w.links = append(w.links, followedLink{path: child, target: real})
+				childInfo = target
+				followed = true
+			}
+		}
+
+		err = w.walk(child, childInfo)
+		if followed && err == filepath.SkipDir {
+			// The caller didn't want to look in there after all.
+			w.links = w.links[:linkCount]
+		}
+		if err != nil {
+			if !childInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the directory that link points to, if we should walk it.
+func (w *symlinkWalker) followable(link string) (os.FileInfo, string, bool) {
+	target, err := os.Stat(link)
+	if err != nil || !target.IsDir() {
+		// A broken link, or a link to a file. Report the link itself.
+		return nil, "", false
+	}
+
+	real, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return nil, "", false
+	}
+	for _, v := range w.visited {
+		// Following a link to a directory that contains somewhere we've been
+		// would walk that place again, under a different name.
+		if real == v || ospath.IsChild(v, real) || ospath.IsChild(real, v) {
+			return nil, "", false
+		}
+	}
+	return target, real, true
+}
+
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
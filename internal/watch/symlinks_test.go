@@ -0,0 +1,94 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestWalkFollowingSymlinks(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	root := f.TempDir("root")
+	target := f.TempDir("target")
+	f.WriteFile(filepath.Join(root, "a.txt"), "a")
+	f.WriteFile(filepath.Join(target, "b.txt"), "b")
+	f.MkdirAll(filepath.Join(root, "sub"))
+
+	symlink(t, target, filepath.Join(root, "linked"))
+	symlink(t, target, filepath.Join(root, "linked-again"))
+	symlink(t, root, filepath.Join(root, "sub", "loop"))
+	symlink(t, filepath.Join(root, "a.txt"), filepath.Join(root, "file-link"))
+
+	var walked []string
+	links, err := walkFollowingSymlinks(root, nil, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		walked = append(walked, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each directory is walked once, under the first name we find it by.
+	assert.Equal(t, []string{
+		".",
+		"a.txt",
+		"file-link",
+		"linked",
+		"linked/b.txt",
+		"linked-again",
+		"sub",
+		"sub/loop",
+	}, walked)
+	assert.Equal(t, []followedLink{{path: filepath.Join(root, "linked"), target: target}}, links)
+}
+
+func TestWalkDoesNotFollowLinkToAncestor(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	root := f.TempDir("root")
+	f.WriteFile(filepath.Join(root, "a.txt"), "a")
+	f.WriteFile(filepath.Join(f.Path(), "outside.txt"), "outside")
+
+	// The link's target contains root, so following it would walk root again.
+	symlink(t, f.Path(), filepath.Join(root, "up"))
+
+	var walked []string
+	links, err := walkFollowingSymlinks(root, nil, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		walked = append(walked, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{".", "a.txt", "up"}, walked)
+	assert.Empty(t, links)
+}
+
+func symlink(t *testing.T, oldname, newname string) {
+	err := os.Symlink(oldname, newname)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
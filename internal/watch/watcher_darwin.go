@@ -1,114 +1,135 @@
 package watch
 
 import (
+	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
-	"github.com/windmilleng/tilt/internal/ospath"
-
 	"github.com/windmilleng/fsevents"
+
+	"github.com/windmilleng/tilt/internal/ospath"
 )
 
 // A file watcher optimized for Darwin.
 // Uses FSEvents to avoid the terrible perf characteristics of kqueue.
+//
+// FSEvents watches are recursive, so we only need one stream per watched root,
+// no matter how many files are under it. All the streams are created up front
+// and started once; restarting a stream for every path we add is what used to
+// make startup slow on big repos.
+//
+// FSEvents reports changes by their real paths, and doesn't follow symlinks.
+// So we look for symlinked directories when we start, give each one its own
+// stream, and put the link's path back on the events. (Symlinks created after
+// we start aren't followed until the watch is restarted.)
 type darwinNotify struct {
+	streams []*darwinStream
+	ignore  PathMatcher
+	events  chan FileEvent
+	errors  chan error
+	stop    chan struct{}
+
+	closeOnce sync.Once
+}
+
+type darwinStream struct {
 	stream *fsevents.EventStream
-	events chan FileEvent
-	errors chan error
-	stop   chan struct{}
-
-	// TODO(nick): This mutex is needed for the case where we add paths after we
-	// start watching. But because fsevents supports recursive watches, we don't
-	// actually need this feature. We should change the api contract of wmNotify
-	// so that, for recursive watches, we can guarantee that the path list doesn't
-	// change.
-	sm *sync.Mutex
-
-	pathsWereWatching map[string]interface{}
+
+	// The path we're watching. FSEvents sends us one spurious create event
+	// for it after the history is done, which we skip.
+	root              string
 	sawAnyHistoryDone bool
+
+	// The real path of root, which is what FSEvents reports paths under.
+	realRoot string
+}
+
+// Translates a path from FSEvents back to the path we were asked to watch.
+func (s *darwinStream) pathFor(realPath string) string {
+	if s.realRoot == "" || s.realRoot == s.root {
+		return realPath
+	}
+	if realPath == s.realRoot {
+		return s.root
+	}
+	if rel, ok := ospath.Child(s.realRoot, realPath); ok {
+		return filepath.Join(s.root, rel)
+	}
+	return realPath
 }
 
-func (d *darwinNotify) loop() {
+func (d *darwinNotify) loop(s *darwinStream) {
 	for {
 		select {
 		case <-d.stop:
 			return
-		case events, ok := <-d.stream.Events:
+		case events, ok := <-s.stream.Events:
 			if !ok {
 				return
 			}
 
 			for _, e := range events {
-				e.Path = filepath.Join("/", e.Path)
+				e.Path = s.pathFor(filepath.Join("/", e.Path))
 
 				if e.Flags&fsevents.HistoryDone == fsevents.HistoryDone {
-					d.sm.Lock()
-					d.sawAnyHistoryDone = true
-					d.sm.Unlock()
+					s.sawAnyHistoryDone = true
 					continue
 				}
 
 				// We wait until we've seen the HistoryDone event for this watcher before processing any events
 				// so that we skip all of the "spurious" events that precede it.
-				if !d.sawAnyHistoryDone {
+				if !s.sawAnyHistoryDone {
 					continue
 				}
 
-				_, isPathWereWatching := d.pathsWereWatching[e.Path]
-				if e.Flags&fsevents.ItemIsDir == fsevents.ItemIsDir && e.Flags&fsevents.ItemCreated == fsevents.ItemCreated && isPathWereWatching {
+				isDir := e.Flags&fsevents.ItemIsDir == fsevents.ItemIsDir
+				if isDir && e.Flags&fsevents.ItemCreated == fsevents.ItemCreated && e.Path == s.root {
 					// This is the first create for the path that we're watching. We always get exactly one of these
 					// even after we get the HistoryDone event. Skip it.
 					continue
 				}
 
-				d.events <- FileEvent{
-					Path: e.Path,
+				if isIgnored(d.ignore, e.Path, isDir) {
+					continue
+				}
+
+				select {
+				case d.events <- FileEvent{Path: e.Path}:
+				case <-d.stop:
+					return
 				}
 			}
 		}
 	}
 }
 
-func (d *darwinNotify) Add(name string) error {
-	d.sm.Lock()
-	defer d.sm.Unlock()
-
-	es := d.stream
-
-	// Check if this is a subdirectory of any of the paths
-	// we're already watching.
-	for _, parent := range es.Paths {
-		if ospath.IsChild(parent, name) {
-			return nil
-		}
-	}
-
-	es.Paths = append(es.Paths, name)
-
-	if d.pathsWereWatching == nil {
-		d.pathsWereWatching = make(map[string]interface{})
+func (d *darwinNotify) Start() error {
+	for _, s := range d.streams {
+		s.stream.Start()
+		go d.loop(s)
 	}
-	d.pathsWereWatching[name] = struct{}{}
+	return nil
+}
 
-	if len(es.Paths) == 1 {
-		es.Start()
-		go d.loop()
-	} else {
-		es.Restart()
+func (d *darwinNotify) WatchedPaths() []string {
+	result := make([]string, 0, len(d.streams))
+	for _, s := range d.streams {
+		result = append(result, s.root)
 	}
-
-	return nil
+	sort.Strings(result)
+	return result
 }
 
 func (d *darwinNotify) Close() error {
-	d.sm.Lock()
-	defer d.sm.Unlock()
-
-	d.stream.Stop()
-	close(d.errors)
-	close(d.stop)
-
+	d.closeOnce.Do(func() {
+		for _, s := range d.streams {
+			s.stream.Stop()
+		}
+		close(d.errors)
+		close(d.stop)
+	})
 	return nil
 }
 
@@ -120,22 +141,68 @@ func (d *darwinNotify) Errors() chan error {
 	return d.errors
 }
 
-func NewWatcher() (Notify, error) {
+func NewWatcher(paths []string, ignore PathMatcher) (Notify, error) {
+	if ignore == nil {
+		ignore = EmptyMatcher{}
+	}
+
 	dw := &darwinNotify{
+		ignore: ignore,
+		events: make(chan FileEvent),
+		errors: make(chan error),
+		stop:   make(chan struct{}),
+	}
+
+	roots := watchRoots(paths)
+	realRoots := realPaths(roots)
+	for _, root := range roots {
+		dw.addStream(root, root)
+
+		links, err := symlinkedDirs(root, realRoots, ignore)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			dw.addStream(link.path, link.target)
+		}
+	}
+
+	return dw, nil
+}
+
+// Adds a stream that watches realPath, and reports changes under path.
+func (d *darwinNotify) addStream(path, realPath string) {
+	s := &darwinStream{
+		root: path,
 		stream: &fsevents.EventStream{
 			Latency: 1 * time.Millisecond,
 			Flags:   fsevents.FileEvents,
+			Paths:   []string{realPath},
 			// NOTE(dmiller): this corresponds to the `sinceWhen` parameter in FSEventStreamCreate
 			// https://developer.apple.com/documentation/coreservices/1443980-fseventstreamcreate
 			EventID: fsevents.LatestEventID(),
 		},
-		sm:     &sync.Mutex{},
-		events: make(chan FileEvent),
-		errors: make(chan error),
-		stop:   make(chan struct{}),
 	}
+	if real, err := filepath.EvalSymlinks(realPath); err == nil {
+		s.realRoot = real
+	}
+	d.streams = append(d.streams, s)
+}
 
-	return dw, nil
+// Finds the symlinked directories under root that point outside of it.
+func symlinkedDirs(root string, covered []string, ignore PathMatcher) ([]followedLink, error) {
+	return walkFollowingSymlinks(root, covered, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Files can go away while we look, and a root that doesn't
+			// exist yet has nothing under it.
+			return nil
+		}
+		if info.IsDir() && path != root && ignoresEntireDir(ignore, path) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
 }
 
 var _ Notify = &darwinNotify{}
+var _ WatchedPathsReporter = &darwinNotify{}
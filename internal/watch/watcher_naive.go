@@ -7,6 +7,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
 
 	"github.com/pkg/errors"
 	"github.com/windmilleng/fsnotify"
@@ -18,19 +21,63 @@ import (
 // Used on all non-Darwin systems (including Windows & Linux).
 //
 // All OS-specific codepaths are handled by fsnotify.
+//
+// On Linux, each watch uses up one of the user's inotify watches, and large
+// repos can run out. So we only ever watch directories (a directory watch
+// reports changes to the files in it), and if we do run out, we keep the
+// watches we have and return a WatchLimitError that explains how to fix it.
 type naiveNotify struct {
 	watcher       *fsnotify.Watcher
 	events        chan fsnotify.Event
 	wrappedEvents chan FileEvent
 	errors        chan error
+	ignore        PathMatcher
+
+	mu sync.Mutex
 
 	// Paths that we're watching that should be passed up to the caller.
 	// Note that we may have to watch ancestors of these paths
 	// in order to fulfill the API promise.
 	notifyList map[string]bool
+
+	// Every path we've added an OS watch for, mapped to the path in
+	// notifyList that it was added for.
+	watched map[string]string
+
+	// The real paths of everything in notifyList. We don't follow symlinks
+	// into these, because we're already watching them under their own names.
+	realRoots []string
+
+	// If non-zero, pretend the OS only gives us this many watches.
+	// For testing.
+	watchLimit int
+
+	// Whether we've already logged a WatchLimitError from the event loop.
+	loggedLimitError bool
+}
+
+func (d *naiveNotify) Start() error {
+	d.mu.Lock()
+	names := make([]string, 0, len(d.notifyList))
+	for name := range d.notifyList {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	d.realRoots = realPaths(names)
+	d.mu.Unlock()
+
+	go d.loop()
+
+	for _, name := range names {
+		err := d.add(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (d *naiveNotify) Add(name string) error {
+func (d *naiveNotify) add(name string) error {
 	fi, err := os.Stat(name)
 	if err != nil && !os.IsNotExist(err) {
 		return errors.Wrapf(err, "notify.Add(%q)", name)
@@ -38,44 +85,64 @@ func (d *naiveNotify) Add(name string) error {
 
 	// if it's a file that doesn't exist, watch its parent
 	if os.IsNotExist(err) {
-		err = d.watchAncestorOfMissingPath(name)
+		err = d.watchAncestorOfMissingPath(name, name)
 		if err != nil {
-			return errors.Wrapf(err, "watchAncestorOfMissingPath(%q)", name)
+			return wrapUnlessLimit(err, "watchAncestorOfMissingPath(%q)", name)
 		}
 	} else if fi.IsDir() {
-		err = d.watchRecursively(name)
+		err = d.watchRecursively(name, name)
 		if err != nil {
-			return errors.Wrapf(err, "notify.Add(%q)", name)
+			return wrapUnlessLimit(err, "notify.Add(%q)", name)
 		}
 	} else {
-		err = d.watcher.Add(name)
+		// Watching the file's directory also catches editors that save
+		// by replacing the file, and lets files in the same directory
+		// share a watch.
+		err = d.addWatch(filepath.Dir(name), name)
 		if err != nil {
-			return errors.Wrapf(err, "notify.Add(%q)", name)
+			return wrapUnlessLimit(err, "notify.Add(%q)", name)
 		}
 	}
-	d.notifyList[name] = true
 
 	return nil
 }
 
-func (d *naiveNotify) watchRecursively(dir string) error {
-	return filepath.Walk(dir, func(path string, mode os.FileInfo, err error) error {
+// Watches dir and everything under it, including directories that are
+// symlinked into it. Linux reports changes under a symlinked directory with
+// the path we watched it under, so the events come back with the link's path.
+func (d *naiveNotify) watchRecursively(dir string, root string) error {
+	_, err := walkFollowingSymlinks(dir, d.coveredDirs(), func(path string, mode os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		err = d.watcher.Add(path)
+		if !mode.IsDir() {
+			return nil
+		}
+
+		if path != dir && ignoresEntireDir(d.ignore, path) {
+			return filepath.SkipDir
+		}
+
+		err = d.addWatch(path, root)
 		if err != nil {
 			if os.IsNotExist(err) {
 				return nil
 			}
-			return errors.Wrapf(err, "watcher.Add(%q)", path)
+			return wrapUnlessLimit(err, "watcher.Add(%q)", path)
 		}
 		return nil
 	})
+	return err
 }
 
-func (d *naiveNotify) watchAncestorOfMissingPath(path string) error {
+func (d *naiveNotify) coveredDirs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.realRoots
+}
+
+func (d *naiveNotify) watchAncestorOfMissingPath(path string, root string) error {
 	if path == string(filepath.Separator) {
 		return fmt.Errorf("cannot watch root directory")
 	}
@@ -87,10 +154,69 @@ func (d *naiveNotify) watchAncestorOfMissingPath(path string) error {
 
 	if os.IsNotExist(err) {
 		parent := filepath.Dir(path)
-		return d.watchAncestorOfMissingPath(parent)
+		return d.watchAncestorOfMissingPath(parent, root)
+	}
+
+	return d.addWatch(path, root)
+}
+
+// Adds an OS watch for path, unless we already have one.
+func (d *naiveNotify) addWatch(path string, root string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.watched[path]; ok {
+		return nil
+	}
+
+	var err error
+	if d.watchLimit > 0 && len(d.watched) >= d.watchLimit {
+		err = syscall.ENOSPC
+	} else {
+		err = d.watcher.Add(path)
+	}
+	if err != nil {
+		if kind, ok := watchLimitKind(err); ok {
+			return d.limitError(kind, path, err)
+		}
+		return err
+	}
+
+	d.watched[path] = root
+	return nil
+}
+
+// Must hold the lock.
+func (d *naiveNotify) limitError(kind WatchLimitKind, path string, err error) *WatchLimitError {
+	limit := d.watchLimit
+	if limit == 0 {
+		limit = readWatchLimit(kind)
+	}
+	return &WatchLimitError{
+		Kind:  kind,
+		Path:  path,
+		Limit: limit,
+		Dirs:  countWatchesByDir(d.watched),
+		Err:   err,
+	}
+}
+
+func wrapUnlessLimit(err error, format string, args ...interface{}) error {
+	if IsWatchLimitError(err) {
+		return err
 	}
+	return errors.Wrapf(err, format, args...)
+}
 
-	return d.watcher.Add(path)
+func (d *naiveNotify) WatchedPaths() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make([]string, 0, len(d.watched))
+	for p := range d.watched {
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	return result
 }
 
 func (d *naiveNotify) Close() error {
@@ -110,7 +236,7 @@ func (d *naiveNotify) loop() {
 		isCreateOp := e.Op&fsnotify.Create == fsnotify.Create
 		shouldWalk := false
 		if isCreateOp {
-			isDir, err := isDir(e.Name)
+			isDir, err := d.isWalkableDir(e.Name)
 			if err != nil {
 				log.Printf("Error stat-ing file %s: %s", e.Name, err)
 				continue
@@ -118,7 +244,7 @@ func (d *naiveNotify) loop() {
 			shouldWalk = isDir
 		}
 		if shouldWalk {
-			err := filepath.Walk(e.Name, func(path string, mode os.FileInfo, err error) error {
+			_, err := walkFollowingSymlinks(e.Name, d.coveredDirs(), func(path string, mode os.FileInfo, err error) error {
 				if err != nil {
 					return err
 				}
@@ -127,13 +253,19 @@ func (d *naiveNotify) loop() {
 					Name: path,
 				}
 
-				if d.shouldNotify(newE) {
+				if mode.IsDir() && ignoresEntireDir(d.ignore, path) {
+					return filepath.SkipDir
+				}
+
+				root, ok := d.rootFor(newE.Name)
+				if ok && !isIgnored(d.ignore, path, mode.IsDir()) {
 					d.wrappedEvents <- FileEvent{newE.Name}
 
-					// TODO(dmiller): symlinks 😭
-					err = d.Add(path)
-					if err != nil {
-						log.Printf("Error watching path %s: %s", e.Name, err)
+					if mode.IsDir() {
+						err = d.addWatch(path, root)
+						if err != nil {
+							d.logWatchError(path, err)
+						}
 					}
 				}
 				return nil
@@ -147,38 +279,113 @@ func (d *naiveNotify) loop() {
 	}
 }
 
+func (d *naiveNotify) logWatchError(path string, err error) {
+	if !IsWatchLimitError(err) {
+		log.Printf("Error watching path %s: %s", path, err)
+		return
+	}
+
+	// Once we're out of watches, every new directory will fail the same way.
+	d.mu.Lock()
+	alreadyLogged := d.loggedLimitError
+	d.loggedLimitError = true
+	d.mu.Unlock()
+	if !alreadyLogged {
+		log.Printf("%v", err)
+	}
+}
+
+// Returns true if path is a new directory we should look in (and watch): a
+// real directory, or a symlink to a directory we aren't already watching.
+func (d *naiveNotify) isWalkableDir(path string) (bool, error) {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return fi.IsDir(), nil
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		// A broken link.
+		return false, nil
+	}
+	target, err := os.Stat(real)
+	if err != nil || !target.IsDir() {
+		return false, nil
+	}
+	for _, covered := range d.coveredDirs() {
+		if real == covered || ospath.IsChild(covered, real) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (d *naiveNotify) shouldNotify(e fsnotify.Event) bool {
-	if _, ok := d.notifyList[e.Name]; ok {
-		return true
-	} else {
-		// TODO(dmiller): maybe use a prefix tree here?
-		for path := range d.notifyList {
-			if ospath.IsChild(path, e.Name) {
-				return true
-			}
+	_, ok := d.rootFor(e.Name)
+	if !ok {
+		return false
+	}
+
+	isDir, err := isDir(e.Name)
+	if err != nil {
+		isDir = false
+	}
+	return !isIgnored(d.ignore, e.Name, isDir)
+}
+
+// Returns the path in notifyList that path is in, if any.
+func (d *naiveNotify) rootFor(path string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.notifyList[path]; ok {
+		return path, true
+	}
+
+	// TODO(dmiller): maybe use a prefix tree here?
+	root := ""
+	for p := range d.notifyList {
+		if ospath.IsChild(p, path) && len(p) > len(root) {
+			root = p
 		}
 	}
-	return false
+	return root, root != ""
 }
 
-func NewWatcher() (*naiveNotify, error) {
+func NewWatcher(paths []string, ignore PathMatcher) (*naiveNotify, error) {
+	if ignore == nil {
+		ignore = EmptyMatcher{}
+	}
+
 	fsw, err := fsnotify.NewWatcher()
 	if err != nil {
+		if kind, ok := watchLimitKind(err); ok {
+			return nil, &WatchLimitError{Kind: kind, Limit: readWatchLimit(kind), Err: err}
+		}
 		return nil, err
 	}
 
 	wrappedEvents := make(chan FileEvent)
+	notifyList := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		notifyList[path] = true
+	}
 
 	wmw := &naiveNotify{
 		watcher:       fsw,
 		events:        fsw.Events,
 		wrappedEvents: wrappedEvents,
 		errors:        fsw.Errors,
-		notifyList:    map[string]bool{},
+		ignore:        ignore,
+		notifyList:    notifyList,
+		watched:       map[string]string{},
 	}
 
-	go wmw.loop()
-
 	return wmw, nil
 }
 
@@ -193,3 +400,4 @@ func isDir(pth string) (bool, error) {
 }
 
 var _ Notify = &naiveNotify{}
+var _ WatchedPathsReporter = &naiveNotify{}
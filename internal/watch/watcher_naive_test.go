@@ -0,0 +1,113 @@
+// +build !darwin
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestNaiveOnlyWatchesDirectories(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	root := f.TempDir("root")
+	for _, p := range []string{"a.txt", "b.txt", "sub/c.txt", "sub/d.txt"} {
+		f.WriteFile(filepath.Join(root, p), "hello")
+	}
+
+	n := newTestNaiveNotify(t, 0, root, filepath.Join(root, "sub", "c.txt"))
+	defer func() { _ = n.Close() }()
+
+	err := n.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{root, filepath.Join(root, "sub")}, n.WatchedPaths())
+}
+
+func TestNaiveWatchesNewSymlinkedDirectory(t *testing.T) {
+	f := newNotifyFixture(t)
+	defer f.tearDown()
+
+	target := f.TempDir("target")
+	link := filepath.Join(f.watched, "linked")
+	err := os.Symlink(target, link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.fsync()
+	f.events = nil
+
+	f.WriteFile(filepath.Join(target, "a.txt"), "hello")
+	f.assertEvents(filepath.Join(link, "a.txt"))
+}
+
+func TestNaiveSkipsIgnoredDirectories(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	root := f.TempDir("root")
+	for _, p := range []string{"node_modules/a/x.js", "node_modules/b/x.js", "src/x.go"} {
+		f.WriteFile(filepath.Join(root, p), "hello")
+	}
+
+	n, err := NewWatcher([]string{root}, suffixMatcher("node_modules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = n.Close() }()
+
+	err = n.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{root, filepath.Join(root, "src")}, n.WatchedPaths())
+}
+
+func TestNaiveWatchLimit(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	root := f.TempDir("root")
+	for _, p := range []string{"node_modules/a/x.js", "node_modules/b/x.js", "src/x.go"} {
+		f.WriteFile(filepath.Join(root, p), "hello")
+	}
+
+	n := newTestNaiveNotify(t, 4, root)
+	defer func() { _ = n.Close() }()
+
+	err := n.Start()
+	if !IsWatchLimitError(err) {
+		t.Fatalf("Expected a WatchLimitError, got: %v", err)
+	}
+
+	limitErr := errors.Cause(err).(*WatchLimitError)
+	assert.Equal(t, WatchLimitWatches, limitErr.Kind)
+	assert.Equal(t, 4, limitErr.Limit)
+	assert.Equal(t, []DirWatchCount{
+		{Dir: filepath.Join(root, "node_modules"), Count: 3},
+		{Dir: root, Count: 1},
+	}, limitErr.Dirs)
+	assert.Contains(t, err.Error(), "sudo sysctl fs.inotify.max_user_watches=524288")
+
+	// The watches we added before running out stay in place.
+	assert.Len(t, n.WatchedPaths(), 4)
+}
+
+func newTestNaiveNotify(t *testing.T, limit int, paths ...string) *naiveNotify {
+	n, err := NewWatcher(paths, EmptyMatcher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.watchLimit = limit
+	return n
+}
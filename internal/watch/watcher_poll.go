@@ -0,0 +1,303 @@
+package watch
+
+import (
+	"crypto/sha1"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type PollOptions struct {
+	Interval time.Duration
+
+	// If true, compare file contents instead of modification times and sizes.
+	Checksum bool
+}
+
+// A file watcher that scans every watched path on an interval, and reports
+// what changed since the last scan.
+//
+// Much slower than the native watchers, but works on file systems that
+// don't support them (NFS mounts, some VM shared folders, some CI sandboxes).
+type pollNotify struct {
+	opts   PollOptions
+	ignore PathMatcher
+
+	mu    sync.Mutex
+	paths map[string]bool
+	files map[string]pollFileState
+
+	// The real paths of everything in paths, which we don't follow symlinks into.
+	realRoots []string
+
+	// Paths we couldn't read, and already warned about.
+	unreadable map[string]bool
+
+	events    chan FileEvent
+	errors    chan error
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+type pollFileState struct {
+	isDir    bool
+	modTime  time.Time
+	size     int64
+	mode     os.FileMode
+	checksum string
+}
+
+func NewPollingWatcher(paths []string, ignore PathMatcher, opts PollOptions) (*pollNotify, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if ignore == nil {
+		ignore = EmptyMatcher{}
+	}
+
+	n := &pollNotify{
+		opts:       opts,
+		ignore:     ignore,
+		paths:      make(map[string]bool),
+		files:      make(map[string]pollFileState),
+		unreadable: make(map[string]bool),
+		events:     make(chan FileEvent),
+		errors:     make(chan error),
+		stop:       make(chan struct{}),
+	}
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "NewPollingWatcher(%q)", p)
+		}
+		n.paths[abs] = true
+	}
+	return n, nil
+}
+
+func (n *pollNotify) Start() error {
+	n.mu.Lock()
+	var roots []string
+	for p := range n.paths {
+		roots = append(roots, p)
+	}
+	n.realRoots = realPaths(roots)
+	n.mu.Unlock()
+
+	// Record what's there now, so that we only report changes from here on.
+	files := make(map[string]pollFileState)
+	for p := range n.paths {
+		err := n.scan(p, files)
+		if err != nil {
+			return errors.Wrapf(err, "notify.Add(%q)", p)
+		}
+	}
+
+	n.mu.Lock()
+	n.files = files
+	n.mu.Unlock()
+
+	go n.loop()
+	return nil
+}
+
+func (n *pollNotify) WatchedPaths() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	result := make([]string, 0, len(n.files))
+	for p := range n.files {
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (n *pollNotify) Close() error {
+	n.closeOnce.Do(func() {
+		close(n.stop)
+	})
+	return nil
+}
+
+func (n *pollNotify) Events() chan FileEvent {
+	return n.events
+}
+
+func (n *pollNotify) Errors() chan error {
+	return n.errors
+}
+
+func (n *pollNotify) loop() {
+	ticker := time.NewTicker(n.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := n.poll()
+		if err != nil {
+			select {
+			case n.errors <- err:
+			case <-n.stop:
+				return
+			}
+		}
+
+		for _, p := range changed {
+			select {
+			case n.events <- FileEvent{Path: p}:
+			case <-n.stop:
+				return
+			}
+		}
+	}
+}
+
+// Scans all the watched paths, and returns the paths that changed
+// since the last scan, in order.
+func (n *pollNotify) poll() ([]string, error) {
+	files := make(map[string]pollFileState)
+	for p := range n.paths {
+		err := n.scan(p, files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var changed []string
+	for p, s := range files {
+		old, ok := n.files[p]
+		if (!ok || n.fileChanged(old, s)) && !isIgnored(n.ignore, p, s.isDir) {
+			changed = append(changed, p)
+		}
+	}
+	for p, s := range n.files {
+		if _, ok := files[p]; !ok && !isIgnored(n.ignore, p, s.isDir) {
+			changed = append(changed, p)
+		}
+	}
+	n.files = files
+
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func (n *pollNotify) fileChanged(old, s pollFileState) bool {
+	if old.isDir != s.isDir {
+		return true
+	}
+	if s.isDir {
+		// A directory's modification time changes whenever its children do.
+		// We report the children instead.
+		return false
+	}
+	if n.opts.Checksum && old.checksum != "" && s.checksum != "" {
+		return old.checksum != s.checksum || old.mode != s.mode
+	}
+	return !old.modTime.Equal(s.modTime) || old.size != s.size || old.mode != s.mode
+}
+
+// Adds the state of path (and everything under it, if it's a directory) to files.
+// A path that doesn't exist yet is fine; we'll see it when it's created.
+// So is a path we can't read; we skip it.
+func (n *pollNotify) scan(path string, files map[string]pollFileState) error {
+	_, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if os.IsPermission(err) {
+		n.warnUnreadable(path, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	covered := n.realRoots
+	n.mu.Unlock()
+
+	// Follow symlinked directories, and report the files in them under the
+	// link's path.
+	_, err = walkFollowingSymlinks(path, covered, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Files can go away in the middle of a scan.
+			if os.IsNotExist(err) {
+				return nil
+			}
+			if os.IsPermission(err) {
+				n.warnUnreadable(p, err)
+				return nil
+			}
+			return err
+		}
+
+		if info.IsDir() && p != path && ignoresEntireDir(n.ignore, p) {
+			return filepath.SkipDir
+		}
+
+		s := pollFileState{
+			isDir:   info.IsDir(),
+			modTime: info.ModTime(),
+			size:    info.Size(),
+			mode:    info.Mode(),
+		}
+		if n.opts.Checksum && info.Mode().IsRegular() {
+			s.checksum, err = checksumFile(p)
+			if err != nil {
+				if os.IsNotExist(errors.Cause(err)) {
+					return nil
+				}
+				if !os.IsPermission(errors.Cause(err)) {
+					return err
+				}
+				// Fall back to the modification time and size.
+				n.warnUnreadable(p, err)
+			}
+		}
+		files[p] = s
+		return nil
+	})
+	return err
+}
+
+// Logs that we're skipping path, once per path, so that we don't repeat
+// ourselves on every scan.
+func (n *pollNotify) warnUnreadable(path string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.unreadable[path] {
+		return
+	}
+	n.unreadable[path] = true
+	log.Printf("Warning: not watching %s: %v", path, err)
+}
+
+func checksumFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha1.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading %s", path)
+	}
+	return string(h.Sum(nil)), nil
+}
+
+var _ Notify = &pollNotify{}
+var _ WatchedPathsReporter = &pollNotify{}
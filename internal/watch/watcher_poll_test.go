@@ -0,0 +1,136 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPollCreateModifyRemove(t *testing.T) {
+	f := newPollNotifyFixture(t, false)
+	defer f.tearDown()
+
+	path := filepath.Join(f.watched, "a.txt")
+	f.WriteFile(path, "hello")
+	f.assertEvents(path)
+
+	f.events = nil
+	f.WriteFile(path, "goodbye")
+	f.assertEvents(path)
+
+	f.events = nil
+	err := os.Remove(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.assertEvents(path)
+}
+
+func TestPollNewDirectoriesAreRecursivelyWatched(t *testing.T) {
+	f := newPollNotifyFixture(t, false)
+	defer f.tearDown()
+
+	sub := filepath.Join(f.watched, "sub")
+	path := filepath.Join(sub, "a.txt")
+	f.MkdirAll(sub)
+	f.WriteFile(path, "hello")
+	f.assertEvents(sub, path)
+}
+
+func TestPollNonexistentPath(t *testing.T) {
+	f := newPollNotifyFixture(t, false)
+	defer f.tearDown()
+
+	path := f.JoinPath("root", "parent", "a.txt")
+	f.watch(path)
+	f.fsync()
+	f.events = nil
+
+	f.WriteFile(path, "hello")
+	f.assertEvents(path)
+}
+
+func TestPollFollowsSymlinkedDirectories(t *testing.T) {
+	f := newPollNotifyFixture(t, false)
+	defer f.tearDown()
+
+	target := f.TempDir("target")
+	link := filepath.Join(f.watched, "linked")
+	err := os.Symlink(target, link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.fsync()
+	f.events = nil
+
+	f.WriteFile(filepath.Join(target, "a.txt"), "hello")
+	f.assertEvents(filepath.Join(link, "a.txt"))
+}
+
+func TestPollChecksumIgnoresTouch(t *testing.T) {
+	f := newPollNotifyFixture(t, true)
+	defer f.tearDown()
+
+	path := filepath.Join(f.watched, "a.txt")
+	f.WriteFile(path, "hello")
+	f.fsync()
+	f.events = nil
+
+	// Same contents, new modification time.
+	later := time.Now().Add(time.Hour)
+	err := os.Chtimes(path, later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.assertEvents()
+
+	f.WriteFile(path, "goodbye")
+	f.assertEvents(path)
+}
+
+func TestPollMtimeSeesTouch(t *testing.T) {
+	f := newPollNotifyFixture(t, false)
+	defer f.tearDown()
+
+	path := filepath.Join(f.watched, "a.txt")
+	f.WriteFile(path, "hello")
+	f.fsync()
+	f.events = nil
+
+	later := time.Now().Add(time.Hour)
+	err := os.Chtimes(path, later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.assertEvents(path)
+}
+
+func TestPollSkipsUnreadableDirectories(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read everything")
+	}
+
+	f := newPollNotifyFixture(t, false)
+	defer f.tearDown()
+
+	secret := filepath.Join(f.watched, "secret")
+	f.MkdirAll(secret)
+	err := os.Chmod(secret, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chmod(secret, 0755) }()
+	f.fsync()
+	f.events = nil
+
+	path := filepath.Join(f.watched, "a.txt")
+	f.WriteFile(path, "hello")
+	f.assertEvents(path)
+}
+
+func newPollNotifyFixture(t *testing.T, checksum bool) *notifyFixture {
+	return newNotifyFixtureWith(t, func(paths []string) (Notify, error) {
+		return NewPollingWatcher(paths, EmptyMatcher{}, PollOptions{Interval: 10 * time.Millisecond, Checksum: checksum})
+	})
+}
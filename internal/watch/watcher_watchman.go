@@ -0,0 +1,449 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/windmilleng/tilt/internal/ospath"
+)
+
+// If set, the path to the Watchman socket. Saves us from asking the
+// watchman binary for it (and is how Watchman's own clients find it, too).
+const WatchmanSockEnv = "WATCHMAN_SOCK"
+
+const watchmanCommandTimeout = 10 * time.Second
+
+// How many directory levels to look through for fully-ignored directories
+// (like node_modules) to leave out of the Watchman query.
+const watchmanIgnoreProbeDepth = 2
+
+// Returned when we can't talk to a Watchman daemon, so the caller
+// should use one of the built-in watchers instead.
+type WatchmanUnavailableError struct {
+	Err error
+}
+
+func (e WatchmanUnavailableError) Error() string {
+	return fmt.Sprintf("Watchman unavailable: %v", e.Err)
+}
+
+func IsWatchmanUnavailable(err error) bool {
+	_, ok := errors.Cause(err).(WatchmanUnavailableError)
+	return ok
+}
+
+// A file watcher that subscribes to changes from a running Watchman daemon,
+// using Watchman's JSON protocol over its unix socket.
+//
+// Watchman already watches the repo for lots of other tools, so this saves
+// us from setting up our own OS watches, and Watchman does a better job of
+// not missing events than we do.
+type watchmanNotify struct {
+	paths  []string
+	ignore PathMatcher
+	client *watchmanClient
+
+	events    chan FileEvent
+	errors    chan error
+	stop      chan struct{}
+	closeOnce sync.Once
+
+	mu sync.Mutex
+
+	// Subscription names, mapped to the directory that
+	// file names in the subscription are relative to.
+	subs map[string]string
+}
+
+func NewWatchmanWatcher(paths []string, ignore PathMatcher) (*watchmanNotify, error) {
+	if ignore == nil {
+		ignore = EmptyMatcher{}
+	}
+
+	absPaths := make([]string, 0, len(paths))
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "NewWatchmanWatcher(%q)", p)
+		}
+		absPaths = append(absPaths, abs)
+	}
+
+	sockname, err := watchmanSockname()
+	if err != nil {
+		return nil, WatchmanUnavailableError{Err: err}
+	}
+
+	client, err := dialWatchman(sockname)
+	if err != nil {
+		return nil, WatchmanUnavailableError{Err: err}
+	}
+
+	return &watchmanNotify{
+		paths:  absPaths,
+		ignore: ignore,
+		client: client,
+		events: make(chan FileEvent),
+		errors: make(chan error),
+		stop:   make(chan struct{}),
+		subs:   make(map[string]string),
+	}, nil
+}
+
+func (d *watchmanNotify) Start() error {
+	go d.loop()
+
+	var dirs []string
+	for _, p := range d.paths {
+		dir, err := existingDir(p)
+		if err != nil {
+			return errors.Wrapf(err, "watchman(%q)", p)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	for i, dir := range watchRoots(dirs) {
+		resp, err := d.client.command("watch-project", dir)
+		if err != nil {
+			return errors.Wrapf(err, "watchman watch-project %s", dir)
+		}
+
+		query := map[string]interface{}{
+			"expression":              watchmanExpression(dir, d.ignore),
+			"fields":                  []string{"name", "exists", "type"},
+			"empty_on_fresh_instance": true,
+		}
+		if resp.RelativePath != "" {
+			query["relative_root"] = resp.RelativePath
+		}
+
+		// Register the subscription before we make it, because Watchman can
+		// send us changes before it answers.
+		name := fmt.Sprintf("tilt-%d-%p-%d", os.Getpid(), d, i)
+		d.mu.Lock()
+		d.subs[name] = dir
+		d.mu.Unlock()
+
+		_, err = d.client.command("subscribe", resp.Watch, name, query)
+		if err != nil {
+			return errors.Wrapf(err, "watchman subscribe %s", dir)
+		}
+	}
+	return nil
+}
+
+func (d *watchmanNotify) loop() {
+	for {
+		select {
+		case <-d.stop:
+			return
+		case err, ok := <-d.client.errors:
+			if !ok {
+				return
+			}
+			select {
+			case d.errors <- err:
+			case <-d.stop:
+				return
+			}
+		case pdu, ok := <-d.client.subscriptions:
+			if !ok {
+				return
+			}
+			if pdu.IsFreshInstance {
+				continue
+			}
+
+			d.mu.Lock()
+			dir, ok := d.subs[pdu.Subscription]
+			d.mu.Unlock()
+			if !ok {
+				continue
+			}
+
+			for _, f := range pdu.Files {
+				path := filepath.Join(dir, filepath.FromSlash(f.Name))
+				if !d.covers(path) || isIgnored(d.ignore, path, f.Type == "d") {
+					continue
+				}
+
+				select {
+				case d.events <- FileEvent{Path: path}:
+				case <-d.stop:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Watchman watches whole directories, but we may have only been asked
+// about a few files in them.
+func (d *watchmanNotify) covers(path string) bool {
+	for _, p := range d.paths {
+		if p == path || ospath.IsChild(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *watchmanNotify) WatchedPaths() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make([]string, 0, len(d.subs))
+	for _, dir := range d.subs {
+		result = append(result, dir)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (d *watchmanNotify) Close() error {
+	var err error
+	d.closeOnce.Do(func() {
+		close(d.stop)
+
+		// Watchman drops our subscriptions when we disconnect.
+		err = d.client.Close()
+	})
+	return err
+}
+
+func (d *watchmanNotify) Events() chan FileEvent {
+	return d.events
+}
+
+func (d *watchmanNotify) Errors() chan error {
+	return d.errors
+}
+
+// Builds a Watchman query expression that leaves out the directories
+// under dir that ignore says are entirely ignored, so that Watchman
+// doesn't bother sending us changes to them.
+//
+// We still check every file against ignore ourselves; this just cuts down
+// on the noise.
+func watchmanExpression(dir string, ignore PathMatcher) []interface{} {
+	excludes := []interface{}{"anyof"}
+	for _, ignored := range ignoredSubdirs(dir, ignore, watchmanIgnoreProbeDepth) {
+		rel, err := filepath.Rel(dir, ignored)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		excludes = append(excludes,
+			[]interface{}{"dirname", rel},
+			[]interface{}{"name", rel, "wholename"})
+	}
+
+	if len(excludes) == 1 {
+		return []interface{}{"true"}
+	}
+	return []interface{}{"not", excludes}
+}
+
+func ignoredSubdirs(dir string, ignore PathMatcher, depth int) []string {
+	if depth == 0 {
+		return nil
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, info.Name())
+		if ignoresEntireDir(ignore, path) {
+			result = append(result, path)
+			continue
+		}
+		result = append(result, ignoredSubdirs(path, ignore, depth-1)...)
+	}
+	return result
+}
+
+// Watchman can only watch directories that exist, so watch the
+// closest one that does.
+func existingDir(path string) (string, error) {
+	for {
+		info, err := os.Stat(path)
+		if err == nil {
+			if info.IsDir() {
+				return path, nil
+			}
+			return filepath.Dir(path), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", fmt.Errorf("cannot watch root directory")
+		}
+		path = parent
+	}
+}
+
+func watchmanSockname() (string, error) {
+	sock := os.Getenv(WatchmanSockEnv)
+	if sock != "" {
+		return sock, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), watchmanCommandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "watchman", "--output-encoding=json", "--no-pretty", "get-sockname").Output()
+	if err != nil {
+		return "", errors.Wrap(err, "watchman get-sockname")
+	}
+
+	var resp struct {
+		Sockname string `json:"sockname"`
+		Error    string `json:"error"`
+	}
+	err = json.Unmarshal(out, &resp)
+	if err != nil {
+		return "", errors.Wrap(err, "watchman get-sockname")
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("watchman get-sockname: %s", resp.Error)
+	}
+	if resp.Sockname == "" {
+		return "", fmt.Errorf("watchman get-sockname: no socket")
+	}
+	return resp.Sockname, nil
+}
+
+// A message from Watchman. We only decode the fields we use.
+type watchmanPDU struct {
+	Error        string `json:"error"`
+	Watch        string `json:"watch"`
+	RelativePath string `json:"relative_path"`
+
+	// Set on subscription updates, which can arrive at any time.
+	Subscription    string         `json:"subscription"`
+	Unilateral      bool           `json:"unilateral"`
+	IsFreshInstance bool           `json:"is_fresh_instance"`
+	Files           []watchmanFile `json:"files"`
+}
+
+type watchmanFile struct {
+	Name   string `json:"name"`
+	Exists bool   `json:"exists"`
+	Type   string `json:"type"`
+}
+
+// A connection to Watchman that speaks its JSON protocol:
+// one JSON value per line in each direction.
+type watchmanClient struct {
+	conn net.Conn
+	enc  *json.Encoder
+
+	// Serializes commands, because Watchman answers them in order.
+	cmdMu sync.Mutex
+
+	responses     chan watchmanPDU
+	subscriptions chan watchmanPDU
+	errors        chan error
+	closed        chan struct{}
+	closeOnce     sync.Once
+}
+
+func dialWatchman(sockname string) (*watchmanClient, error) {
+	conn, err := net.DialTimeout("unix", sockname, watchmanCommandTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to %s", sockname)
+	}
+
+	c := &watchmanClient{
+		conn:          conn,
+		enc:           json.NewEncoder(conn),
+		responses:     make(chan watchmanPDU),
+		subscriptions: make(chan watchmanPDU),
+		errors:        make(chan error),
+		closed:        make(chan struct{}),
+	}
+	go c.read()
+	return c, nil
+}
+
+func (c *watchmanClient) read() {
+	defer close(c.subscriptions)
+
+	dec := json.NewDecoder(c.conn)
+	for {
+		var pdu watchmanPDU
+		err := dec.Decode(&pdu)
+		if err != nil {
+			select {
+			case <-c.closed:
+			case c.errors <- errors.Wrap(err, "reading from watchman"):
+			}
+			return
+		}
+
+		ch := c.responses
+		if pdu.Subscription != "" || pdu.Unilateral {
+			ch = c.subscriptions
+		}
+
+		select {
+		case ch <- pdu:
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *watchmanClient) command(args ...interface{}) (watchmanPDU, error) {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	err := c.enc.Encode(args)
+	if err != nil {
+		return watchmanPDU{}, errors.Wrap(err, "writing to watchman")
+	}
+
+	select {
+	case pdu := <-c.responses:
+		if pdu.Error != "" {
+			return pdu, fmt.Errorf("watchman: %s", pdu.Error)
+		}
+		return pdu, nil
+	case <-c.closed:
+		return watchmanPDU{}, fmt.Errorf("watchman connection closed")
+	case <-time.After(watchmanCommandTimeout):
+		return watchmanPDU{}, fmt.Errorf("timed out waiting for watchman")
+	}
+}
+
+func (c *watchmanClient) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+var _ Notify = &watchmanNotify{}
+var _ WatchedPathsReporter = &watchmanNotify{}
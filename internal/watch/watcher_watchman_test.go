@@ -0,0 +1,245 @@
+package watch
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/windmilleng/tilt/internal/testutils/tempdir"
+)
+
+func TestWatchmanEvents(t *testing.T) {
+	f := newWatchmanFixture(t)
+	defer f.TearDown()
+
+	root := f.TempDir("root")
+	f.MkdirAll(filepath.Join(root, "node_modules", "left-pad"))
+	f.WriteFile(filepath.Join(root, "main.go"), "package main")
+
+	n := f.start([]string{root}, suffixMatcher("node_modules"))
+	defer func() { _ = n.Close() }()
+
+	sub := f.server.waitForSubscription(t)
+	assert.Equal(t, []interface{}{"not", []interface{}{"anyof",
+		[]interface{}{"dirname", "node_modules"},
+		[]interface{}{"name", "node_modules", "wholename"},
+	}}, sub.query["expression"])
+	assert.Equal(t, filepath.Base(root), sub.query["relative_root"])
+
+	f.server.send(t, map[string]interface{}{
+		"subscription": sub.name,
+		"files": []interface{}{
+			map[string]interface{}{"name": "main.go", "exists": true, "type": "f"},
+			map[string]interface{}{"name": "node_modules", "exists": true, "type": "d"},
+		},
+	})
+
+	f.assertEvents(n, filepath.Join(root, "main.go"))
+}
+
+func TestWatchmanOnlyReportsRequestedFiles(t *testing.T) {
+	f := newWatchmanFixture(t)
+	defer f.TearDown()
+
+	root := f.TempDir("root")
+	f.WriteFile(filepath.Join(root, "a.txt"), "a")
+	f.WriteFile(filepath.Join(root, "b.txt"), "b")
+
+	n := f.start([]string{filepath.Join(root, "a.txt")}, nil)
+	defer func() { _ = n.Close() }()
+
+	sub := f.server.waitForSubscription(t)
+	f.server.send(t, map[string]interface{}{
+		"subscription": sub.name,
+		"files": []interface{}{
+			map[string]interface{}{"name": "b.txt", "exists": true, "type": "f"},
+			map[string]interface{}{"name": "a.txt", "exists": true, "type": "f"},
+		},
+	})
+
+	f.assertEvents(n, filepath.Join(root, "a.txt"))
+}
+
+func TestWatchmanUnavailable(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	defer setenv(t, WatchmanSockEnv, f.JoinPath("no-such-socket"))()
+
+	_, err := NewWatchmanWatcher([]string{f.Path()}, nil)
+	if !IsWatchmanUnavailable(err) {
+		t.Fatalf("Expected Watchman to be unavailable, got: %v", err)
+	}
+}
+
+type watchmanFixture struct {
+	*tempdir.TempDirFixture
+	server   *fakeWatchmanServer
+	unsetenv func()
+}
+
+func newWatchmanFixture(t *testing.T) *watchmanFixture {
+	f := tempdir.NewTempDirFixture(t)
+	server := newFakeWatchmanServer(t, f.JoinPath("watchman.sock"))
+	return &watchmanFixture{
+		TempDirFixture: f,
+		server:         server,
+		unsetenv:       setenv(t, WatchmanSockEnv, server.sockname),
+	}
+}
+
+func (f *watchmanFixture) start(paths []string, ignore PathMatcher) Notify {
+	n, err := NewWatchmanWatcher(paths, ignore)
+	if err != nil {
+		f.T().Fatal(err)
+	}
+	err = n.Start()
+	if err != nil {
+		f.T().Fatal(err)
+	}
+	return n
+}
+
+func (f *watchmanFixture) assertEvents(n Notify, expected ...string) {
+	var actual []string
+	timeout := time.After(time.Second)
+	for len(actual) < len(expected) {
+		select {
+		case e := <-n.Events():
+			actual = append(actual, e.Path)
+		case err := <-n.Errors():
+			f.T().Fatal(err)
+		case <-timeout:
+			f.T().Fatalf("Timed out waiting for events. Got: %v. Expected: %v", actual, expected)
+		}
+	}
+
+	select {
+	case e := <-n.Events():
+		actual = append(actual, e.Path)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(f.T(), expected, actual)
+}
+
+func (f *watchmanFixture) TearDown() {
+	f.unsetenv()
+	f.server.close()
+	f.TempDirFixture.TearDown()
+}
+
+type fakeWatchmanSubscription struct {
+	name  string
+	query map[string]interface{}
+}
+
+// Speaks just enough of the Watchman protocol to test against.
+// Like the real thing, it watches the parent of whatever directory
+// it's asked to watch, and makes the rest a relative root.
+type fakeWatchmanServer struct {
+	sockname string
+	listener net.Listener
+	subs     chan fakeWatchmanSubscription
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newFakeWatchmanServer(t *testing.T, sockname string) *fakeWatchmanServer {
+	l, err := net.Listen("unix", sockname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeWatchmanServer{
+		sockname: sockname,
+		listener: l,
+		subs:     make(chan fakeWatchmanSubscription, 10),
+	}
+	go s.serve()
+	return s
+}
+
+func (s *fakeWatchmanServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+
+	dec := json.NewDecoder(conn)
+	for {
+		var cmd []interface{}
+		err := dec.Decode(&cmd)
+		if err != nil {
+			return
+		}
+
+		switch cmd[0] {
+		case "watch-project":
+			dir := cmd[1].(string)
+			s.write(map[string]interface{}{
+				"watch":         filepath.Dir(dir),
+				"relative_path": filepath.Base(dir),
+			})
+		case "subscribe":
+			name := cmd[2].(string)
+			s.write(map[string]interface{}{"subscribe": name, "clock": "c:0:1"})
+			s.subs <- fakeWatchmanSubscription{name: name, query: cmd[3].(map[string]interface{})}
+		default:
+			s.write(map[string]interface{}{"error": "unknown command"})
+		}
+	}
+}
+
+func (s *fakeWatchmanServer) write(pdu map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = json.NewEncoder(s.conn).Encode(pdu)
+}
+
+func (s *fakeWatchmanServer) send(t *testing.T, pdu map[string]interface{}) {
+	pdu["unilateral"] = true
+	s.write(pdu)
+}
+
+func (s *fakeWatchmanServer) waitForSubscription(t *testing.T) fakeWatchmanSubscription {
+	select {
+	case sub := <-s.subs:
+		return sub
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for a watchman subscription")
+	}
+	return fakeWatchmanSubscription{}
+}
+
+func (s *fakeWatchmanServer) close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+}
+
+func setenv(t *testing.T, key, value string) func() {
+	old, hadOld := os.LookupEnv(key)
+	err := os.Setenv(key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if hadOld {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
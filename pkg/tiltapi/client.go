@@ -0,0 +1,42 @@
+// Package tiltapi is the client for the gRPC API of a running `tilt up`,
+// for editor plugins and scripts. See tiltapi.proto for what it can do.
+package tiltapi
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+)
+
+// The port that `tilt up` serves the API on, unless it has a --grpc-port.
+const DefaultPort = 10352
+
+// Connects to the `tilt up` on this machine. If it has a --web-token, pass
+// that as the token; otherwise, pass "".
+func Dial(ctx context.Context, port int, token string) (TiltClient, *grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials(token)))
+	}
+
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("localhost:%d", port), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewTiltClient(conn), conn, nil
+}
+
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// The token goes over the connection in the clear, which is only OK because
+// Dial only connects to localhost. A client for a `tilt up` on another machine
+// would need TLS (and to return true here), or anyone on the network between
+// them could read the token and use it to change the user's resources.
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,1024 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: github.com/windmilleng/tilt/pkg/tiltapi/tiltapi.proto
+
+package tiltapi // import "github.com/windmilleng/tilt/pkg/tiltapi"
+
+/*
+A typed API for editor plugins and scripts to drive a running `tilt up`.
+
+Versioned by package name: we only add fields and methods to tilt.api.v1,
+and breaking changes go in a new package.
+*/
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type ListResourcesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListResourcesRequest) Reset()         { *m = ListResourcesRequest{} }
+func (m *ListResourcesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListResourcesRequest) ProtoMessage()    {}
+func (*ListResourcesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{0}
+}
+func (m *ListResourcesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListResourcesRequest.Unmarshal(m, b)
+}
+func (m *ListResourcesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListResourcesRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListResourcesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListResourcesRequest.Merge(dst, src)
+}
+func (m *ListResourcesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListResourcesRequest.Size(m)
+}
+func (m *ListResourcesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListResourcesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListResourcesRequest proto.InternalMessageInfo
+
+type WatchResourcesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchResourcesRequest) Reset()         { *m = WatchResourcesRequest{} }
+func (m *WatchResourcesRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchResourcesRequest) ProtoMessage()    {}
+func (*WatchResourcesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{1}
+}
+func (m *WatchResourcesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchResourcesRequest.Unmarshal(m, b)
+}
+func (m *WatchResourcesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchResourcesRequest.Marshal(b, m, deterministic)
+}
+func (dst *WatchResourcesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchResourcesRequest.Merge(dst, src)
+}
+func (m *WatchResourcesRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchResourcesRequest.Size(m)
+}
+func (m *WatchResourcesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchResourcesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchResourcesRequest proto.InternalMessageInfo
+
+type ListResourcesReply struct {
+	Resources            []*Resource `protobuf:"bytes,1,rep,name=resources,proto3" json:"resources,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ListResourcesReply) Reset()         { *m = ListResourcesReply{} }
+func (m *ListResourcesReply) String() string { return proto.CompactTextString(m) }
+func (*ListResourcesReply) ProtoMessage()    {}
+func (*ListResourcesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{2}
+}
+func (m *ListResourcesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListResourcesReply.Unmarshal(m, b)
+}
+func (m *ListResourcesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListResourcesReply.Marshal(b, m, deterministic)
+}
+func (dst *ListResourcesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListResourcesReply.Merge(dst, src)
+}
+func (m *ListResourcesReply) XXX_Size() int {
+	return xxx_messageInfo_ListResourcesReply.Size(m)
+}
+func (m *ListResourcesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListResourcesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListResourcesReply proto.InternalMessageInfo
+
+func (m *ListResourcesReply) GetResources() []*Resource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+type Resource struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// "tiltfile", "k8s", "docker-compose", "local", or "yaml".
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// "none" (never built), "pending", "in_progress", "ok", "error", or "canceled".
+	UpdateStatus string `protobuf:"bytes,3,opt,name=update_status,json=updateStatus,proto3" json:"update_status,omitempty"`
+	// "ok", "pending", or "error".
+	RuntimeStatus string `protobuf:"bytes,4,opt,name=runtime_status,json=runtimeStatus,proto3" json:"runtime_status,omitempty"`
+	// The status of the pod, container, or process, e.g., "Running".
+	Status string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	// "auto" or "manual".
+	TriggerMode    string               `protobuf:"bytes,6,opt,name=trigger_mode,json=triggerMode,proto3" json:"trigger_mode,omitempty"`
+	Disabled       bool                 `protobuf:"varint,7,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	UpdatesPaused  bool                 `protobuf:"varint,8,opt,name=updates_paused,json=updatesPaused,proto3" json:"updates_paused,omitempty"`
+	Endpoints      []string             `protobuf:"bytes,9,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	PodName        string               `protobuf:"bytes,10,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	PodRestarts    int32                `protobuf:"varint,11,opt,name=pod_restarts,json=podRestarts,proto3" json:"pod_restarts,omitempty"`
+	LastDeployTime *timestamp.Timestamp `protobuf:"bytes,12,opt,name=last_deploy_time,json=lastDeployTime,proto3" json:"last_deploy_time,omitempty"`
+	// Files that changed since the last build, waiting for the next one.
+	PendingChanges []string `protobuf:"bytes,13,rep,name=pending_changes,json=pendingChanges,proto3" json:"pending_changes,omitempty"`
+	CurrentBuild   *Build   `protobuf:"bytes,14,opt,name=current_build,json=currentBuild,proto3" json:"current_build,omitempty"`
+	// Newest first.
+	BuildHistory []*Build `protobuf:"bytes,15,rep,name=build_history,json=buildHistory,proto3" json:"build_history,omitempty"`
+	// The groups the Tiltfile put the resource in, e.g., "frontend".
+	Labels               []string `protobuf:"bytes,16,rep,name=labels,proto3" json:"labels,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Resource) Reset()         { *m = Resource{} }
+func (m *Resource) String() string { return proto.CompactTextString(m) }
+func (*Resource) ProtoMessage()    {}
+func (*Resource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{3}
+}
+func (m *Resource) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Resource.Unmarshal(m, b)
+}
+func (m *Resource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Resource.Marshal(b, m, deterministic)
+}
+func (dst *Resource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Resource.Merge(dst, src)
+}
+func (m *Resource) XXX_Size() int {
+	return xxx_messageInfo_Resource.Size(m)
+}
+func (m *Resource) XXX_DiscardUnknown() {
+	xxx_messageInfo_Resource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Resource proto.InternalMessageInfo
+
+func (m *Resource) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Resource) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Resource) GetUpdateStatus() string {
+	if m != nil {
+		return m.UpdateStatus
+	}
+	return ""
+}
+
+func (m *Resource) GetRuntimeStatus() string {
+	if m != nil {
+		return m.RuntimeStatus
+	}
+	return ""
+}
+
+func (m *Resource) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Resource) GetTriggerMode() string {
+	if m != nil {
+		return m.TriggerMode
+	}
+	return ""
+}
+
+func (m *Resource) GetDisabled() bool {
+	if m != nil {
+		return m.Disabled
+	}
+	return false
+}
+
+func (m *Resource) GetUpdatesPaused() bool {
+	if m != nil {
+		return m.UpdatesPaused
+	}
+	return false
+}
+
+func (m *Resource) GetEndpoints() []string {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
+}
+
+func (m *Resource) GetPodName() string {
+	if m != nil {
+		return m.PodName
+	}
+	return ""
+}
+
+func (m *Resource) GetPodRestarts() int32 {
+	if m != nil {
+		return m.PodRestarts
+	}
+	return 0
+}
+
+func (m *Resource) GetLastDeployTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastDeployTime
+	}
+	return nil
+}
+
+func (m *Resource) GetPendingChanges() []string {
+	if m != nil {
+		return m.PendingChanges
+	}
+	return nil
+}
+
+func (m *Resource) GetCurrentBuild() *Build {
+	if m != nil {
+		return m.CurrentBuild
+	}
+	return nil
+}
+
+func (m *Resource) GetBuildHistory() []*Build {
+	if m != nil {
+		return m.BuildHistory
+	}
+	return nil
+}
+
+func (m *Resource) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type Build struct {
+	StartTime *timestamp.Timestamp `protobuf:"bytes,1,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// Empty while the build is running.
+	FinishTime *timestamp.Timestamp `protobuf:"bytes,2,opt,name=finish_time,json=finishTime,proto3" json:"finish_time,omitempty"`
+	// Any of "init", "config", "crash", and "changed_files".
+	Reasons  []string `protobuf:"bytes,3,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	Edits    []string `protobuf:"bytes,4,rep,name=edits,proto3" json:"edits,omitempty"`
+	Error    string   `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Warnings []string `protobuf:"bytes,6,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// "live update" or "image build", for builds that updated a container.
+	UpdateType string `protobuf:"bytes,7,opt,name=update_type,json=updateType,proto3" json:"update_type,omitempty"`
+	// Why the build couldn't live update, if it fell back to an image build.
+	FallbackReason       string   `protobuf:"bytes,8,opt,name=fallback_reason,json=fallbackReason,proto3" json:"fallback_reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Build) Reset()         { *m = Build{} }
+func (m *Build) String() string { return proto.CompactTextString(m) }
+func (*Build) ProtoMessage()    {}
+func (*Build) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{4}
+}
+func (m *Build) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Build.Unmarshal(m, b)
+}
+func (m *Build) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Build.Marshal(b, m, deterministic)
+}
+func (dst *Build) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Build.Merge(dst, src)
+}
+func (m *Build) XXX_Size() int {
+	return xxx_messageInfo_Build.Size(m)
+}
+func (m *Build) XXX_DiscardUnknown() {
+	xxx_messageInfo_Build.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Build proto.InternalMessageInfo
+
+func (m *Build) GetStartTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.StartTime
+	}
+	return nil
+}
+
+func (m *Build) GetFinishTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.FinishTime
+	}
+	return nil
+}
+
+func (m *Build) GetReasons() []string {
+	if m != nil {
+		return m.Reasons
+	}
+	return nil
+}
+
+func (m *Build) GetEdits() []string {
+	if m != nil {
+		return m.Edits
+	}
+	return nil
+}
+
+func (m *Build) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Build) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *Build) GetUpdateType() string {
+	if m != nil {
+		return m.UpdateType
+	}
+	return ""
+}
+
+func (m *Build) GetFallbackReason() string {
+	if m != nil {
+		return m.FallbackReason
+	}
+	return ""
+}
+
+type TriggerRequest struct {
+	Names                []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	All                  bool     `protobuf:"varint,2,opt,name=all,proto3" json:"all,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TriggerRequest) Reset()         { *m = TriggerRequest{} }
+func (m *TriggerRequest) String() string { return proto.CompactTextString(m) }
+func (*TriggerRequest) ProtoMessage()    {}
+func (*TriggerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{5}
+}
+func (m *TriggerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TriggerRequest.Unmarshal(m, b)
+}
+func (m *TriggerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TriggerRequest.Marshal(b, m, deterministic)
+}
+func (dst *TriggerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TriggerRequest.Merge(dst, src)
+}
+func (m *TriggerRequest) XXX_Size() int {
+	return xxx_messageInfo_TriggerRequest.Size(m)
+}
+func (m *TriggerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TriggerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TriggerRequest proto.InternalMessageInfo
+
+func (m *TriggerRequest) GetNames() []string {
+	if m != nil {
+		return m.Names
+	}
+	return nil
+}
+
+func (m *TriggerRequest) GetAll() bool {
+	if m != nil {
+		return m.All
+	}
+	return false
+}
+
+type TriggerReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TriggerReply) Reset()         { *m = TriggerReply{} }
+func (m *TriggerReply) String() string { return proto.CompactTextString(m) }
+func (*TriggerReply) ProtoMessage()    {}
+func (*TriggerReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{6}
+}
+func (m *TriggerReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TriggerReply.Unmarshal(m, b)
+}
+func (m *TriggerReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TriggerReply.Marshal(b, m, deterministic)
+}
+func (dst *TriggerReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TriggerReply.Merge(dst, src)
+}
+func (m *TriggerReply) XXX_Size() int {
+	return xxx_messageInfo_TriggerReply.Size(m)
+}
+func (m *TriggerReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_TriggerReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TriggerReply proto.InternalMessageInfo
+
+type SetEnabledRequest struct {
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Enabled bool   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// When disabling, also delete the resource's objects.
+	Delete               bool     `protobuf:"varint,3,opt,name=delete,proto3" json:"delete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetEnabledRequest) Reset()         { *m = SetEnabledRequest{} }
+func (m *SetEnabledRequest) String() string { return proto.CompactTextString(m) }
+func (*SetEnabledRequest) ProtoMessage()    {}
+func (*SetEnabledRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{7}
+}
+func (m *SetEnabledRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetEnabledRequest.Unmarshal(m, b)
+}
+func (m *SetEnabledRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetEnabledRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetEnabledRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetEnabledRequest.Merge(dst, src)
+}
+func (m *SetEnabledRequest) XXX_Size() int {
+	return xxx_messageInfo_SetEnabledRequest.Size(m)
+}
+func (m *SetEnabledRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetEnabledRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetEnabledRequest proto.InternalMessageInfo
+
+func (m *SetEnabledRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SetEnabledRequest) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+func (m *SetEnabledRequest) GetDelete() bool {
+	if m != nil {
+		return m.Delete
+	}
+	return false
+}
+
+type SetEnabledReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetEnabledReply) Reset()         { *m = SetEnabledReply{} }
+func (m *SetEnabledReply) String() string { return proto.CompactTextString(m) }
+func (*SetEnabledReply) ProtoMessage()    {}
+func (*SetEnabledReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{8}
+}
+func (m *SetEnabledReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetEnabledReply.Unmarshal(m, b)
+}
+func (m *SetEnabledReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetEnabledReply.Marshal(b, m, deterministic)
+}
+func (dst *SetEnabledReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetEnabledReply.Merge(dst, src)
+}
+func (m *SetEnabledReply) XXX_Size() int {
+	return xxx_messageInfo_SetEnabledReply.Size(m)
+}
+func (m *SetEnabledReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetEnabledReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetEnabledReply proto.InternalMessageInfo
+
+type StreamLogsRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Keep the stream open, and send what the resource logs next.
+	Follow bool `protobuf:"varint,2,opt,name=follow,proto3" json:"follow,omitempty"`
+	// "tilt", "build", or "runtime". Empty for every source.
+	Source string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	// "info", "warn", or "error", for that level and above. Empty for info.
+	Level                string   `protobuf:"bytes,4,opt,name=level,proto3" json:"level,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamLogsRequest) Reset()         { *m = StreamLogsRequest{} }
+func (m *StreamLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamLogsRequest) ProtoMessage()    {}
+func (*StreamLogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{9}
+}
+func (m *StreamLogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamLogsRequest.Unmarshal(m, b)
+}
+func (m *StreamLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamLogsRequest.Marshal(b, m, deterministic)
+}
+func (dst *StreamLogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamLogsRequest.Merge(dst, src)
+}
+func (m *StreamLogsRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamLogsRequest.Size(m)
+}
+func (m *StreamLogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamLogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamLogsRequest proto.InternalMessageInfo
+
+func (m *StreamLogsRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StreamLogsRequest) GetFollow() bool {
+	if m != nil {
+		return m.Follow
+	}
+	return false
+}
+
+func (m *StreamLogsRequest) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *StreamLogsRequest) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+type LogChunk struct {
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// If true, the log started over (e.g., because the resource was
+	// recreated), and text replaces it. Otherwise, it goes on the end.
+	Replace bool `protobuf:"varint,2,opt,name=replace,proto3" json:"replace,omitempty"`
+	// The number of bytes to drop off the start of the log before adding
+	// text, because Tilt truncated it. Unset if replace is set.
+	Drop                 int64    `protobuf:"varint,3,opt,name=drop,proto3" json:"drop,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogChunk) Reset()         { *m = LogChunk{} }
+func (m *LogChunk) String() string { return proto.CompactTextString(m) }
+func (*LogChunk) ProtoMessage()    {}
+func (*LogChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_tiltapi_b095cfe380a712d6, []int{10}
+}
+func (m *LogChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogChunk.Unmarshal(m, b)
+}
+func (m *LogChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogChunk.Marshal(b, m, deterministic)
+}
+func (dst *LogChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogChunk.Merge(dst, src)
+}
+func (m *LogChunk) XXX_Size() int {
+	return xxx_messageInfo_LogChunk.Size(m)
+}
+func (m *LogChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogChunk proto.InternalMessageInfo
+
+func (m *LogChunk) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *LogChunk) GetReplace() bool {
+	if m != nil {
+		return m.Replace
+	}
+	return false
+}
+
+func (m *LogChunk) GetDrop() int64 {
+	if m != nil {
+		return m.Drop
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*ListResourcesRequest)(nil), "tilt.api.v1.ListResourcesRequest")
+	proto.RegisterType((*WatchResourcesRequest)(nil), "tilt.api.v1.WatchResourcesRequest")
+	proto.RegisterType((*ListResourcesReply)(nil), "tilt.api.v1.ListResourcesReply")
+	proto.RegisterType((*Resource)(nil), "tilt.api.v1.Resource")
+	proto.RegisterType((*Build)(nil), "tilt.api.v1.Build")
+	proto.RegisterType((*TriggerRequest)(nil), "tilt.api.v1.TriggerRequest")
+	proto.RegisterType((*TriggerReply)(nil), "tilt.api.v1.TriggerReply")
+	proto.RegisterType((*SetEnabledRequest)(nil), "tilt.api.v1.SetEnabledRequest")
+	proto.RegisterType((*SetEnabledReply)(nil), "tilt.api.v1.SetEnabledReply")
+	proto.RegisterType((*StreamLogsRequest)(nil), "tilt.api.v1.StreamLogsRequest")
+	proto.RegisterType((*LogChunk)(nil), "tilt.api.v1.LogChunk")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TiltClient is the client API for Tilt service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TiltClient interface {
+	// Every resource in the session, in Tiltfile order, with the Tiltfile first.
+	ListResources(ctx context.Context, in *ListResourcesRequest, opts ...grpc.CallOption) (*ListResourcesReply, error)
+	// Sends every resource now, then again each time any of them changes.
+	WatchResources(ctx context.Context, in *WatchResourcesRequest, opts ...grpc.CallOption) (Tilt_WatchResourcesClient, error)
+	// Builds the named resources, or rebuilds every resource from scratch.
+	Trigger(ctx context.Context, in *TriggerRequest, opts ...grpc.CallOption) (*TriggerReply, error)
+	// Turns the named resource on or off.
+	SetEnabled(ctx context.Context, in *SetEnabledRequest, opts ...grpc.CallOption) (*SetEnabledReply, error)
+	// Sends the resource's log, and with follow, whatever it logs after that.
+	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (Tilt_StreamLogsClient, error)
+}
+
+type tiltClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTiltClient(cc *grpc.ClientConn) TiltClient {
+	return &tiltClient{cc}
+}
+
+func (c *tiltClient) ListResources(ctx context.Context, in *ListResourcesRequest, opts ...grpc.CallOption) (*ListResourcesReply, error) {
+	out := new(ListResourcesReply)
+	err := c.cc.Invoke(ctx, "/tilt.api.v1.Tilt/ListResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tiltClient) WatchResources(ctx context.Context, in *WatchResourcesRequest, opts ...grpc.CallOption) (Tilt_WatchResourcesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Tilt_serviceDesc.Streams[0], "/tilt.api.v1.Tilt/WatchResources", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tiltWatchResourcesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Tilt_WatchResourcesClient interface {
+	Recv() (*ListResourcesReply, error)
+	grpc.ClientStream
+}
+
+type tiltWatchResourcesClient struct {
+	grpc.ClientStream
+}
+
+func (x *tiltWatchResourcesClient) Recv() (*ListResourcesReply, error) {
+	m := new(ListResourcesReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tiltClient) Trigger(ctx context.Context, in *TriggerRequest, opts ...grpc.CallOption) (*TriggerReply, error) {
+	out := new(TriggerReply)
+	err := c.cc.Invoke(ctx, "/tilt.api.v1.Tilt/Trigger", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tiltClient) SetEnabled(ctx context.Context, in *SetEnabledRequest, opts ...grpc.CallOption) (*SetEnabledReply, error) {
+	out := new(SetEnabledReply)
+	err := c.cc.Invoke(ctx, "/tilt.api.v1.Tilt/SetEnabled", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tiltClient) StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (Tilt_StreamLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Tilt_serviceDesc.Streams[1], "/tilt.api.v1.Tilt/StreamLogs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tiltStreamLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Tilt_StreamLogsClient interface {
+	Recv() (*LogChunk, error)
+	grpc.ClientStream
+}
+
+type tiltStreamLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tiltStreamLogsClient) Recv() (*LogChunk, error) {
+	m := new(LogChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TiltServer is the server API for Tilt service.
+type TiltServer interface {
+	// Every resource in the session, in Tiltfile order, with the Tiltfile first.
+	ListResources(context.Context, *ListResourcesRequest) (*ListResourcesReply, error)
+	// Sends every resource now, then again each time any of them changes.
+	WatchResources(*WatchResourcesRequest, Tilt_WatchResourcesServer) error
+	// Builds the named resources, or rebuilds every resource from scratch.
+	Trigger(context.Context, *TriggerRequest) (*TriggerReply, error)
+	// Turns the named resource on or off.
+	SetEnabled(context.Context, *SetEnabledRequest) (*SetEnabledReply, error)
+	// Sends the resource's log, and with follow, whatever it logs after that.
+	StreamLogs(*StreamLogsRequest, Tilt_StreamLogsServer) error
+}
+
+func RegisterTiltServer(s *grpc.Server, srv TiltServer) {
+	s.RegisterService(&_Tilt_serviceDesc, srv)
+}
+
+func _Tilt_ListResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TiltServer).ListResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tilt.api.v1.Tilt/ListResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TiltServer).ListResources(ctx, req.(*ListResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tilt_WatchResources_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchResourcesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TiltServer).WatchResources(m, &tiltWatchResourcesServer{stream})
+}
+
+type Tilt_WatchResourcesServer interface {
+	Send(*ListResourcesReply) error
+	grpc.ServerStream
+}
+
+type tiltWatchResourcesServer struct {
+	grpc.ServerStream
+}
+
+func (x *tiltWatchResourcesServer) Send(m *ListResourcesReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Tilt_Trigger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TiltServer).Trigger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tilt.api.v1.Tilt/Trigger",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TiltServer).Trigger(ctx, req.(*TriggerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tilt_SetEnabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetEnabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TiltServer).SetEnabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tilt.api.v1.Tilt/SetEnabled",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TiltServer).SetEnabled(ctx, req.(*SetEnabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tilt_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TiltServer).StreamLogs(m, &tiltStreamLogsServer{stream})
+}
+
+type Tilt_StreamLogsServer interface {
+	Send(*LogChunk) error
+	grpc.ServerStream
+}
+
+type tiltStreamLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tiltStreamLogsServer) Send(m *LogChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Tilt_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "tilt.api.v1.Tilt",
+	HandlerType: (*TiltServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListResources",
+			Handler:    _Tilt_ListResources_Handler,
+		},
+		{
+			MethodName: "Trigger",
+			Handler:    _Tilt_Trigger_Handler,
+		},
+		{
+			MethodName: "SetEnabled",
+			Handler:    _Tilt_SetEnabled_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchResources",
+			Handler:       _Tilt_WatchResources_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _Tilt_StreamLogs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "github.com/windmilleng/tilt/pkg/tiltapi/tiltapi.proto",
+}
+
+func init() {
+	proto.RegisterFile("github.com/windmilleng/tilt/pkg/tiltapi/tiltapi.proto", fileDescriptor_tiltapi_b095cfe380a712d6)
+}
+
+var fileDescriptor_tiltapi_b095cfe380a712d6 = []byte{
+	// 869 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xdb, 0x6e, 0x1b, 0x37,
+	0x10, 0xad, 0x2c, 0x5b, 0x97, 0xd1, 0xc5, 0x36, 0x91, 0xb8, 0x1b, 0x35, 0xa8, 0xe5, 0x2d, 0x8a,
+	0xe8, 0x49, 0x6a, 0x1c, 0x14, 0x6d, 0x91, 0xb7, 0x38, 0x45, 0x5b, 0x40, 0x2d, 0x8a, 0xb5, 0x8b,
+	0x22, 0x7d, 0x59, 0x50, 0x22, 0xbd, 0x22, 0x4c, 0x2d, 0x59, 0x92, 0x1b, 0x57, 0xff, 0xd1, 0xbf,
+	0xe8, 0xd7, 0xf4, 0x8f, 0x02, 0x0e, 0xb9, 0x76, 0xe4, 0x38, 0x97, 0xa7, 0xe5, 0x39, 0x73, 0x86,
+	0xc3, 0xcb, 0xe1, 0x2c, 0x7c, 0x5b, 0x08, 0xb7, 0xaa, 0x16, 0xd3, 0xa5, 0x5a, 0xcf, 0xae, 0x45,
+	0xc9, 0xd6, 0x42, 0x4a, 0x5e, 0x16, 0x33, 0x27, 0xa4, 0x9b, 0xe9, 0xab, 0x30, 0xa0, 0x5a, 0xd4,
+	0xdf, 0xa9, 0x36, 0xca, 0x29, 0xd2, 0xf3, 0x70, 0xea, 0xf1, 0xeb, 0xa7, 0xa3, 0xe3, 0x42, 0xa9,
+	0x42, 0xf2, 0x19, 0x86, 0x16, 0xd5, 0xe5, 0xcc, 0x89, 0x35, 0xb7, 0x8e, 0xae, 0x75, 0x50, 0xa7,
+	0x47, 0xf0, 0x60, 0x2e, 0xac, 0xcb, 0xb8, 0x55, 0x95, 0x59, 0x72, 0x9b, 0xf1, 0xbf, 0x2b, 0x6e,
+	0x5d, 0xfa, 0x39, 0x3c, 0xfc, 0x93, 0xba, 0xe5, 0xea, 0x9d, 0xc0, 0x2f, 0x40, 0xee, 0x24, 0x68,
+	0xb9, 0x21, 0xcf, 0xa0, 0x6b, 0x6a, 0x26, 0x69, 0x8c, 0x9b, 0x93, 0xde, 0xe9, 0xc3, 0xe9, 0x5b,
+	0x0b, 0x99, 0xd6, 0xfa, 0xec, 0x56, 0x97, 0xfe, 0xbf, 0x0b, 0x9d, 0x9a, 0x27, 0x04, 0x76, 0x4b,
+	0xba, 0xe6, 0x49, 0x63, 0xdc, 0x98, 0x74, 0x33, 0x1c, 0x7b, 0xce, 0x6d, 0x34, 0x4f, 0x76, 0x02,
+	0xe7, 0xc7, 0xe4, 0x2b, 0x18, 0x54, 0x9a, 0x51, 0xc7, 0x73, 0xeb, 0xa8, 0xab, 0x6c, 0xd2, 0xc4,
+	0x60, 0x3f, 0x90, 0xe7, 0xc8, 0x91, 0xaf, 0x61, 0x68, 0xaa, 0xd2, 0xef, 0xb5, 0x56, 0xed, 0xa2,
+	0x6a, 0x10, 0xd9, 0x28, 0x3b, 0x82, 0x56, 0x0c, 0xef, 0x61, 0x38, 0x22, 0x72, 0x02, 0x7d, 0x67,
+	0x44, 0x51, 0x70, 0x93, 0xaf, 0x15, 0xe3, 0x49, 0x0b, 0xa3, 0xbd, 0xc8, 0xfd, 0xaa, 0x18, 0x27,
+	0x23, 0xe8, 0x30, 0x61, 0xe9, 0x42, 0x72, 0x96, 0xb4, 0xc7, 0x8d, 0x49, 0x27, 0xbb, 0xc1, 0xbe,
+	0x7a, 0x58, 0x8d, 0xcd, 0x35, 0xad, 0x2c, 0x67, 0x49, 0x07, 0x15, 0x71, 0xe1, 0xf6, 0x77, 0x24,
+	0xc9, 0x63, 0xe8, 0xf2, 0x92, 0x69, 0x25, 0x4a, 0x67, 0x93, 0xee, 0xb8, 0x39, 0xe9, 0x66, 0xb7,
+	0x04, 0x79, 0x04, 0x1d, 0xad, 0x58, 0x8e, 0x67, 0x02, 0x58, 0xbf, 0xad, 0x15, 0xfb, 0xcd, 0x1f,
+	0xcb, 0x09, 0xf4, 0x7d, 0xc8, 0xf8, 0x8b, 0x34, 0xce, 0x26, 0xbd, 0x71, 0x63, 0xb2, 0x97, 0xf5,
+	0xb4, 0x62, 0x59, 0xa4, 0xc8, 0x4b, 0x38, 0x90, 0xd4, 0xba, 0x9c, 0x71, 0x2d, 0xd5, 0x26, 0xf7,
+	0x7b, 0x4e, 0xfa, 0xe3, 0xc6, 0xa4, 0x77, 0x3a, 0x9a, 0x06, 0x4b, 0x4c, 0x6b, 0x4b, 0x4c, 0x2f,
+	0x6a, 0x4b, 0x64, 0x43, 0x9f, 0xf3, 0x12, 0x53, 0x3c, 0x49, 0x9e, 0xc0, 0xbe, 0xe6, 0x25, 0x13,
+	0x65, 0x91, 0x2f, 0x57, 0xb4, 0x2c, 0xb8, 0x4d, 0x06, 0xb8, 0xce, 0x61, 0xa4, 0xcf, 0x02, 0x4b,
+	0xbe, 0x83, 0xc1, 0xb2, 0x32, 0x86, 0x97, 0x2e, 0x5f, 0x54, 0x42, 0xb2, 0x64, 0x88, 0xb5, 0xc8,
+	0x96, 0x05, 0x5e, 0xf8, 0x48, 0xd6, 0x8f, 0x42, 0x44, 0x3e, 0x11, 0x13, 0xf2, 0x95, 0xb0, 0x4e,
+	0x99, 0x4d, 0xb2, 0x3f, 0x6e, 0xbe, 0x2f, 0x11, 0x85, 0x3f, 0x07, 0x9d, 0xbf, 0x3a, 0x49, 0x17,
+	0x5c, 0xda, 0xe4, 0x00, 0x57, 0x14, 0x51, 0xfa, 0xdf, 0x0e, 0xec, 0x85, 0xa9, 0x7f, 0x00, 0xc0,
+	0xc3, 0x08, 0x9b, 0x6f, 0x7c, 0x74, 0xf3, 0x5d, 0x54, 0xe3, 0xbe, 0x9f, 0x43, 0xef, 0x52, 0x94,
+	0xc2, 0xae, 0x42, 0xee, 0xce, 0x47, 0x73, 0x21, 0xc8, 0x31, 0x39, 0x81, 0xb6, 0xe1, 0xd4, 0xaa,
+	0xd2, 0x5b, 0xd3, 0x2f, 0xad, 0x86, 0xe4, 0x01, 0xec, 0x71, 0x26, 0x9c, 0x37, 0xa3, 0xe7, 0x03,
+	0x40, 0xd6, 0x18, 0x65, 0xa2, 0x07, 0x03, 0xf0, 0xfe, 0xba, 0xa6, 0xa6, 0x14, 0x65, 0x61, 0x93,
+	0x16, 0xca, 0x6f, 0x30, 0x39, 0x86, 0x5e, 0x7c, 0x02, 0xf8, 0x3a, 0xda, 0x98, 0x07, 0x81, 0xba,
+	0xf0, 0x6f, 0xe4, 0x09, 0xec, 0x5f, 0x52, 0x29, 0x17, 0x74, 0x79, 0x95, 0x87, 0xe2, 0xe8, 0xc0,
+	0x6e, 0x36, 0xac, 0xe9, 0x0c, 0xd9, 0xf4, 0x7b, 0x18, 0x5e, 0x04, 0x53, 0xc7, 0xe7, 0xed, 0x57,
+	0xe3, 0x2d, 0x17, 0x1e, 0x71, 0x37, 0x0b, 0x80, 0x1c, 0x40, 0x93, 0x4a, 0x89, 0x07, 0xd1, 0xc9,
+	0xfc, 0x30, 0x1d, 0x42, 0xff, 0x26, 0x53, 0xcb, 0x4d, 0xfa, 0x0a, 0x0e, 0xcf, 0xb9, 0xfb, 0xb1,
+	0xc4, 0x17, 0x50, 0x4f, 0x76, 0xdf, 0x9b, 0x4e, 0xa0, 0xcd, 0x83, 0x2a, 0x4e, 0x57, 0x43, 0x7f,
+	0xa5, 0x8c, 0x4b, 0xee, 0x38, 0x3e, 0xe9, 0x4e, 0x16, 0x51, 0x7a, 0x08, 0xfb, 0x6f, 0x4f, 0xed,
+	0xab, 0xad, 0xe1, 0xf0, 0xdc, 0x19, 0x4e, 0xd7, 0x73, 0x55, 0xd8, 0x0f, 0x55, 0x3b, 0x82, 0xd6,
+	0xa5, 0x92, 0x52, 0x5d, 0xc7, 0x62, 0x11, 0x79, 0x3e, 0xf4, 0x9d, 0xd8, 0x3e, 0x22, 0xf2, 0xdb,
+	0x97, 0xfc, 0x35, 0x97, 0xb1, 0x5f, 0x04, 0x90, 0xce, 0xa1, 0x33, 0x57, 0xc5, 0xd9, 0xaa, 0x2a,
+	0xaf, 0x7c, 0x15, 0xc7, 0xff, 0x71, 0x75, 0x15, 0x3f, 0x0e, 0x57, 0xae, 0x25, 0x5d, 0xf2, 0x7a,
+	0x4f, 0x11, 0x7a, 0x35, 0x33, 0x4a, 0x63, 0x95, 0x66, 0x86, 0xe3, 0xd3, 0x7f, 0x9b, 0xb0, 0x7b,
+	0x21, 0xa4, 0x23, 0x7f, 0xc0, 0x60, 0xab, 0x95, 0x92, 0x93, 0x2d, 0xdb, 0xdf, 0xd7, 0x97, 0x47,
+	0xc7, 0x1f, 0x92, 0xf8, 0xa3, 0xf9, 0x8c, 0xbc, 0x82, 0xe1, 0x76, 0xeb, 0x26, 0xe9, 0x56, 0xd2,
+	0xbd, 0x7d, 0xfd, 0x13, 0x26, 0xfe, 0xa6, 0x41, 0xce, 0xa0, 0x1d, 0x6f, 0x9d, 0x7c, 0xb1, 0xa5,
+	0xdf, 0x76, 0xd1, 0xe8, 0xd1, 0xfd, 0xc1, 0xb0, 0xbe, 0x39, 0xc0, 0xed, 0x7d, 0x92, 0x2f, 0xb7,
+	0xa4, 0xef, 0x78, 0x68, 0xf4, 0xf8, 0xbd, 0xf1, 0x30, 0xdb, 0x4f, 0x00, 0xb7, 0x56, 0xb8, 0x3b,
+	0xdb, 0x5d, 0x8f, 0x8c, 0xb6, 0x7f, 0x4a, 0xf5, 0xa5, 0xfa, 0xbd, 0xbd, 0x78, 0xfa, 0xd7, 0xec,
+	0x13, 0x7f, 0xb8, 0xcf, 0xe3, 0x77, 0xd1, 0xc2, 0x56, 0xf0, 0xec, 0xcd, 0x00, 0xda, 0x20, 0x4e,
+	0xbd, 0xaa, 0x07, 0x00, 0x00,
+}
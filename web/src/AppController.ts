@@ -1,4 +1,5 @@
 import HUD from "./HUD"
+import applyViewPatch from "./viewPatch"
 
 // A Websocket that automatically retries.
 
@@ -12,6 +13,9 @@ class AppController {
   component: HUD
   disposed: boolean = false
 
+  // The last view we got from the socket, for applying patches to.
+  view: any = null
+
   /**
    * @param url The url of the websocket to pull data from
    * @param component The top-level component for the app.
@@ -44,8 +48,16 @@ class AppController {
       this.tryConnectCount = 0
 
       let data = JSON.parse(event.data)
+      if (data.Patch) {
+        if (!this.view) {
+          return
+        }
+        this.view = applyViewPatch(this.view, data.Patch)
+      } else {
+        this.view = data.View
+      }
       // @ts-ignore
-      this.component.setAppState({ View: data })
+      this.component.setAppState({ View: this.view })
     })
   }
 
@@ -59,6 +71,7 @@ class AppController {
   onSocketClose() {
     let wasAlive = this.liveSocket
     this.liveSocket = false
+    this.view = null
     if (this.disposed) {
       return
     }
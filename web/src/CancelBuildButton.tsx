@@ -0,0 +1,36 @@
+import React, { PureComponent } from "react"
+import "./CancelBuildButton.scss"
+import { sessionHeaders } from "./session"
+
+type CancelBuildButtonProps = {
+  resourceName: string
+}
+
+// Stops the resource's current build, and leaves it the way it was before.
+class CancelBuildButton extends PureComponent<CancelBuildButtonProps> {
+  constructor(props: CancelBuildButtonProps) {
+    super(props)
+    this.cancel = this.cancel.bind(this)
+  }
+
+  cancel() {
+    let url = `http://${window.location.host}/api/cancel`
+    fetch(url, {
+      method: "post",
+      headers: sessionHeaders(),
+      body: JSON.stringify({ name: this.props.resourceName }),
+    })
+  }
+
+  render() {
+    return (
+      <span className="CancelBuildButton">
+        <button type="button" onClick={this.cancel}>
+          Cancel build
+        </button>
+      </span>
+    )
+  }
+}
+
+export default CancelBuildButton
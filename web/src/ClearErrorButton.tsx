@@ -0,0 +1,36 @@
+import React, { PureComponent } from "react"
+import "./ClearErrorButton.scss"
+import { sessionHeaders } from "./session"
+
+type ClearErrorButtonProps = {
+  resourceName: string
+}
+
+// Hides the resource's last build error and pod restarts, once you've seen them.
+class ClearErrorButton extends PureComponent<ClearErrorButtonProps> {
+  constructor(props: ClearErrorButtonProps) {
+    super(props)
+    this.clear = this.clear.bind(this)
+  }
+
+  clear() {
+    let url = `http://${window.location.host}/api/clear-error`
+    fetch(url, {
+      method: "post",
+      headers: sessionHeaders(),
+      body: JSON.stringify({ name: this.props.resourceName }),
+    })
+  }
+
+  render() {
+    return (
+      <span className="ClearErrorButton">
+        <button type="button" onClick={this.clear}>
+          Clear error
+        </button>
+      </span>
+    )
+  }
+}
+
+export default ClearErrorButton
@@ -0,0 +1,40 @@
+import React, { PureComponent } from "react"
+import "./EnableButton.scss"
+import { sessionHeaders } from "./session"
+
+type EnableButtonProps = {
+  resourceName: string
+  isDisabled: boolean
+}
+
+// Turns the resource off (stops watching and building it), or back on.
+class EnableButton extends PureComponent<EnableButtonProps> {
+  constructor(props: EnableButtonProps) {
+    super(props)
+    this.toggle = this.toggle.bind(this)
+  }
+
+  toggle() {
+    let url = `http://${window.location.host}/api/enable`
+    fetch(url, {
+      method: "post",
+      headers: sessionHeaders(),
+      body: JSON.stringify({
+        name: this.props.resourceName,
+        enabled: this.props.isDisabled,
+      }),
+    })
+  }
+
+  render() {
+    return (
+      <span className="EnableButton">
+        <button type="button" onClick={this.toggle}>
+          {this.props.isDisabled ? "Enable" : "Disable"}
+        </button>
+      </span>
+    )
+  }
+}
+
+export default EnableButton
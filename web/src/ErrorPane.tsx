@@ -82,7 +82,7 @@ class ErrorPane extends PureComponent<ErrorsProps> {
       }
       if (r.buildHistory.length > 0) {
         let lastBuild = r.buildHistory[0]
-        if (lastBuild.Error !== null) {
+        if (lastBuild.Error !== null && !lastBuild.Canceled) {
           errorElements.push(
             <li key={"buildError" + r.name} className="ErrorPane-item">
               <header>
@@ -14,9 +14,10 @@ import { createBrowserHistory, History, UnregisterCallback } from "history"
 import { incr, pathToTag } from "./analytics"
 import TopBar from "./TopBar"
 import "./HUD.scss"
-import { ResourceView } from "./types"
+import { Build, ResourceView } from "./types"
 import ErrorPane, { ErrorResource } from "./ErrorPane"
 import PreviewList from "./PreviewList"
+import { isZeroTime } from "./time"
 
 type HudProps = {}
 
@@ -24,12 +25,16 @@ type Resource = {
   Name: string
   CombinedLog: string
   BuildHistory: Array<any>
+  CrashBackoffUntil: string
   CrashLog: string
   CurrentBuild: any
+  Disabled: boolean
+  UpdatesPaused: boolean
   DirectoriesWatched: Array<any>
   Endpoints: Array<string>
   PodID: string
   IsTiltfile: boolean
+  Labels: Array<string>
   LastDeployTime: string
   PathsWatched: Array<string>
   PendingBuildEdits: any
@@ -40,10 +45,16 @@ type Resource = {
     PodName: string
     PodRestarts: number
     PodUpdateStartTime: string
+    PodCPU: string
+    PodMemory: string
+    PodUsageWarnings: Array<string>
+    Drift: string
     YAML: string
   }
+  ReadinessCheckError: string
   RuntimeStatus: string
   ShowBuildStatus: boolean
+  WaitingOnReadinessCheck: boolean
 }
 
 type HudState = {
@@ -54,6 +65,7 @@ type HudState = {
     LogTimestamps: boolean
     SailEnabled: boolean
     SailURL: string
+    UpdatesPaused: boolean
   } | null
   IsSidebarClosed: boolean
 }
@@ -170,6 +182,23 @@ class HUD extends Component<HudProps, HudState> {
         props.match.params && props.match.params.name
           ? props.match.params.name
           : ""
+      let resource = resources.find(r => r.Name === name)
+      let isBuilding = Boolean(
+        resource &&
+          resource.CurrentBuild &&
+          !isZeroTime(resource.CurrentBuild.StartTime)
+      )
+      let lastBuild =
+        resource && resource.BuildHistory && resource.BuildHistory[0]
+      let hasError = Boolean(
+        resource &&
+          ((lastBuild && lastBuild.Error) ||
+            resource.CrashLog ||
+            (resource.ResourceInfo && resource.ResourceInfo.PodRestarts > 0))
+      )
+      let isDisabled = Boolean(resource && resource.Disabled)
+      let isPaused = Boolean(resource && resource.UpdatesPaused)
+      let isSessionPaused = Boolean(view && view.UpdatesPaused)
       return (
         <TopBar
           logUrl={name === "" ? this.path("/") : this.path(`/r/${name}`)}
@@ -180,6 +209,12 @@ class HUD extends Component<HudProps, HudState> {
           resourceView={t}
           sailEnabled={sailEnabled}
           sailUrl={sailUrl}
+          resourceName={name}
+          isBuilding={isBuilding}
+          hasError={hasError}
+          isDisabled={isDisabled}
+          isPaused={isPaused}
+          isSessionPaused={isSessionPaused}
         />
       )
     }
@@ -192,11 +227,13 @@ class HUD extends Component<HudProps, HudState> {
       let logs = ""
       let endpoints: Array<string> = []
       let podID: string = ""
+      let buildHistory: Array<Build> = []
       if (view && name !== "") {
         let r = view.Resources.find(r => r.Name === name)
         logs = (r && r.CombinedLog) || ""
         endpoints = (r && r.Endpoints) || []
         podID = (r && r.PodID) || ""
+        buildHistory = (r && r.BuildHistory) || []
       }
       return (
         <LogPane
@@ -204,6 +241,7 @@ class HUD extends Component<HudProps, HudState> {
           isExpanded={isSidebarClosed}
           endpoints={endpoints}
           podID={podID}
+          buildHistory={buildHistory}
         />
       )
     }
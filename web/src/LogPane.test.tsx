@@ -2,6 +2,8 @@ import React from "react"
 import ReactDOM from "react-dom"
 import LogPane from "./LogPane"
 import renderer from "react-test-renderer"
+import { mount } from "enzyme"
+import { Build } from "./types"
 
 it("renders without crashing", () => {
   let div = document.createElement("div")
@@ -19,6 +21,90 @@ it("renders without crashing", () => {
   ReactDOM.unmountComponentAtNode(div)
 })
 
+it("pages through previous builds", () => {
+  Element.prototype.scrollIntoView = jest.fn()
+  let build = (log: string, startTime: string): Build => ({
+    Error: null,
+    StartTime: startTime,
+    Log: log,
+    FinishTime: startTime,
+    Edits: null,
+  })
+  let buildHistory = [
+    build("build 3", "2019-04-10T15:37:03Z"),
+    build("build 2", "2019-04-10T15:37:02Z"),
+    build("", "2019-04-10T15:37:01Z"),
+  ]
+  let pane = mount(
+    <LogPane
+      log="all logs"
+      isExpanded={false}
+      endpoints={[]}
+      podID={""}
+      buildHistory={buildHistory}
+    />
+  )
+  let logText = () => pane.find(".logText").text()
+  let older = () => pane.find(".LogPane-builds button").first()
+  let newer = () => pane.find(".LogPane-builds button").last()
+
+  expect(logText()).toContain("all logs")
+  expect(newer().prop("disabled")).toBe(true)
+
+  older().simulate("click")
+  expect(logText()).toContain("build 3")
+  expect(pane.find(".LogPane-builds .label").text()).toEqual("Build 3/3")
+
+  older().simulate("click")
+  older().simulate("click")
+  expect(logText()).toContain("Tilt only keeps the logs of the last 2 builds")
+  expect(older().prop("disabled")).toBe(true)
+
+  // Stays on the same build when a new one comes in.
+  pane.setProps({
+    buildHistory: [build("build 4", "2019-04-10T15:37:04Z"), ...buildHistory],
+  })
+  expect(pane.find(".LogPane-builds .label").text()).toEqual("Build 1/4")
+
+  newer().simulate("click")
+  newer().simulate("click")
+  newer().simulate("click")
+  expect(logText()).toContain("build 4")
+  newer().simulate("click")
+  expect(logText()).toContain("all logs")
+})
+
+it("lists the files that triggered a build", () => {
+  Element.prototype.scrollIntoView = jest.fn()
+  let buildHistory: Array<Build> = [
+    {
+      Error: null,
+      StartTime: "2019-04-10T15:37:01Z",
+      Log: "build log",
+      FinishTime: "2019-04-10T15:37:02Z",
+      Edits: ["a.go", "b.go", "c.go", "d.go", "e.go"],
+    },
+  ]
+  let pane = mount(
+    <LogPane
+      log="all logs"
+      isExpanded={false}
+      endpoints={[]}
+      podID={""}
+      buildHistory={buildHistory}
+    />
+  )
+  expect(pane.find(".LogPane-edits")).toHaveLength(0)
+
+  pane.find(".LogPane-builds button").first().simulate("click")
+  expect(pane.find(".LogPane-edits li")).toHaveLength(3)
+
+  let more = pane.find(".LogPane-edits button")
+  expect(more.text()).toEqual("and 2 more")
+  more.simulate("click")
+  expect(pane.find(".LogPane-edits li")).toHaveLength(5)
+})
+
 it("renders logs", () => {
   const log = "hello\nworld\nfoo\nbar"
   const tree = renderer
@@ -1,20 +1,35 @@
 import React, { Component } from "react"
 import { ReactComponent as LogoWordmarkSvg } from "./assets/svg/logo-wordmark-gray.svg"
 import AnsiLine from "./AnsiLine"
+import TimeAgo from "react-timeago"
 import "./LogPane.scss"
+import { Build } from "./types"
 
 const WHEEL_DEBOUNCE_MS = 250
 
+// Tilt only keeps the logs of this many of a resource's most recent builds.
+// Keep in sync with model.BuildHistoryLogLimit.
+const BUILD_HISTORY_LOG_LIMIT = 2
+
+// How many of a build's changed files to list before "and N more".
+const EDITS_SHOWN = 3
+
 type LogPaneProps = {
   log: string
   message?: string
   isExpanded: boolean
   podID: string
   endpoints: string[]
+  buildHistory?: Array<Build>
 }
 type LogPaneState = {
   autoscroll: boolean
   lastWheelEventTimeMs: number
+
+  // The build whose log we're showing, by start time, so that we stay on it
+  // when new builds come in. Null shows the resource's whole log.
+  buildStartTime: string | null
+  allEdits: boolean
 }
 
 class LogPane extends Component<LogPaneProps, LogPaneState> {
@@ -27,10 +42,16 @@ class LogPane extends Component<LogPaneProps, LogPaneState> {
     this.state = {
       autoscroll: true,
       lastWheelEventTimeMs: 0,
+      buildStartTime: null,
+      allEdits: false,
     }
 
     this.refreshAutoScroll = this.refreshAutoScroll.bind(this)
     this.handleWheel = this.handleWheel.bind(this)
+    this.handleKeyDown = this.handleKeyDown.bind(this)
+    this.showOlderBuild = this.showOlderBuild.bind(this)
+    this.showNewerBuild = this.showNewerBuild.bind(this)
+    this.toggleAllEdits = this.toggleAllEdits.bind(this)
   }
 
   componentDidMount() {
@@ -40,6 +61,7 @@ class LogPane extends Component<LogPaneProps, LogPaneState> {
 
     window.addEventListener("scroll", this.refreshAutoScroll, { passive: true })
     window.addEventListener("wheel", this.handleWheel, { passive: true })
+    window.addEventListener("keydown", this.handleKeyDown)
   }
 
   componentDidUpdate() {
@@ -54,6 +76,7 @@ class LogPane extends Component<LogPaneProps, LogPaneState> {
   componentWillUnmount() {
     window.removeEventListener("scroll", this.refreshAutoScroll)
     window.removeEventListener("wheel", this.handleWheel)
+    window.removeEventListener("keydown", this.handleKeyDown)
     if (this.rafID) {
       clearTimeout(this.rafID)
     }
@@ -65,6 +88,60 @@ class LogPane extends Component<LogPaneProps, LogPaneState> {
     }
   }
 
+  // Like the terminal HUD, "," pages to older builds and "." to newer ones.
+  private handleKeyDown(event: KeyboardEvent) {
+    let target = event.target as HTMLElement | null
+    if (
+      target &&
+      (target.tagName === "INPUT" || target.tagName === "TEXTAREA")
+    ) {
+      return
+    }
+    if (event.key === ",") {
+      this.showOlderBuild()
+    } else if (event.key === ".") {
+      this.showNewerBuild()
+    }
+  }
+
+  private builds(): Array<Build> {
+    return this.props.buildHistory || []
+  }
+
+  // The index in the build history of the build we're showing, or -1 if
+  // we're showing the whole log.
+  private buildIndex(): number {
+    let startTime = this.state.buildStartTime
+    if (startTime === null) {
+      return -1
+    }
+    return this.builds().findIndex(b => b.StartTime === startTime)
+  }
+
+  private showBuild(index: number) {
+    let builds = this.builds()
+    let buildStartTime = index >= 0 ? builds[index].StartTime : null
+    this.setState({ buildStartTime, autoscroll: true })
+  }
+
+  showOlderBuild() {
+    let i = this.buildIndex()
+    if (i + 1 < this.builds().length) {
+      this.showBuild(i + 1)
+    }
+  }
+
+  showNewerBuild() {
+    let i = this.buildIndex()
+    if (i >= 0) {
+      this.showBuild(i - 1)
+    }
+  }
+
+  toggleAllEdits() {
+    this.setState(prevState => ({ allEdits: !prevState.allEdits }))
+  }
+
   private refreshAutoScroll() {
     if (this.rafID) {
       cancelAnimationFrame(this.rafID)
@@ -100,7 +177,21 @@ class LogPane extends Component<LogPaneProps, LogPaneState> {
   render() {
     let classes = `LogPane ${this.props.isExpanded ? "LogPane--expanded" : ""}`
 
+    let builds = this.builds()
+    let buildIndex = this.buildIndex()
+    let build = buildIndex >= 0 ? builds[buildIndex] : null
+
     let log = this.props.log
+    if (build) {
+      log = build.Log
+      if (!log && buildIndex >= BUILD_HISTORY_LOG_LIMIT) {
+        log = `(Tilt only keeps the logs of the last ${
+          BUILD_HISTORY_LOG_LIMIT
+        } builds)`
+      } else if (!log) {
+        log = "(no logs received)"
+      }
+    }
     if (!log || log.length === 0) {
       return (
         <section className={classes}>
@@ -134,6 +225,57 @@ class LogPane extends Component<LogPaneProps, LogPaneState> {
       </div>
     )
 
+    let buildsEl = builds.length > 0 && (
+      <div className="resourceInfo-item LogPane-builds">
+        <button
+          type="button"
+          onClick={this.showOlderBuild}
+          disabled={buildIndex + 1 >= builds.length}
+          title="Older build (,)"
+        >
+          &lsaquo;
+        </button>
+        <span className="label">
+          {build
+            ? `Build ${builds.length - buildIndex}/${builds.length}`
+            : "All logs"}
+        </span>
+        {build && <TimeAgo date={build.StartTime} />}
+        {build && build.Error && (
+          <span className="LogPane-buildError">Error</span>
+        )}
+        <button
+          type="button"
+          onClick={this.showNewerBuild}
+          disabled={buildIndex < 0}
+          title="Newer build (.)"
+        >
+          &rsaquo;
+        </button>
+      </div>
+    )
+
+    // The files that triggered the build, so you can tell why it rebuilt.
+    let edits = (build && build.Edits) || []
+    let shownEdits = this.state.allEdits ? edits : edits.slice(0, EDITS_SHOWN)
+    let editsEl = edits.length > 0 && (
+      <section className="LogPane-edits">
+        <span className="label">Changed:</span>
+        <ul>
+          {shownEdits.map(e => (
+            <li key={e}>{e}</li>
+          ))}
+        </ul>
+        {edits.length > EDITS_SHOWN && (
+          <button type="button" onClick={this.toggleAllEdits}>
+            {this.state.allEdits
+              ? "Show fewer"
+              : `and ${edits.length - EDITS_SHOWN} more`}
+          </button>
+        )}
+      </section>
+    )
+
     let logLines: Array<React.ReactElement> = []
     let lines = log.split("\n")
     logLines = lines.map(
@@ -157,11 +299,15 @@ class LogPane extends Component<LogPaneProps, LogPaneState> {
       <section className={classes}>
         {(endpoints || podID) && (
           <section className="resourceInfo">
+            {buildsEl}
             {podIDEl}
             {endpointsEl}
           </section>
         )}
-        <section className="logText">{logLines}</section>
+        <section className="logText">
+          {editsEl}
+          {logLines}
+        </section>
       </section>
     )
   }
@@ -0,0 +1,41 @@
+import React, { PureComponent } from "react"
+import "./PauseButton.scss"
+import { sessionHeaders } from "./session"
+
+type PauseButtonProps = {
+  resourceName?: string
+  isPaused: boolean
+}
+
+// Stops building file changes (for one resource, or the whole session)
+// until it's clicked again.
+class PauseButton extends PureComponent<PauseButtonProps> {
+  constructor(props: PauseButtonProps) {
+    super(props)
+    this.toggle = this.toggle.bind(this)
+  }
+
+  toggle() {
+    let url = `http://${window.location.host}/api/pause`
+    fetch(url, {
+      method: "post",
+      headers: sessionHeaders(),
+      body: JSON.stringify({
+        names: this.props.resourceName ? [this.props.resourceName] : [],
+        paused: !this.props.isPaused,
+      }),
+    })
+  }
+
+  render() {
+    return (
+      <span className="PauseButton">
+        <button type="button" onClick={this.toggle}>
+          {this.props.isPaused ? "Resume updates" : "Pause updates"}
+        </button>
+      </span>
+    )
+  }
+}
+
+export default PauseButton
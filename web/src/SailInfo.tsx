@@ -1,5 +1,6 @@
 import React, { PureComponent } from "react"
 import "./SailInfo.scss"
+import { sessionHeaders } from "./session"
 
 type SailProps = {
   sailEnabled: boolean
@@ -12,6 +13,7 @@ class SailInfo extends PureComponent<SailProps> {
 
     fetch(url, {
       method: "post",
+      headers: sessionHeaders(),
       body: "",
     })
   }
@@ -1,7 +1,7 @@
 import React from "react"
 import renderer from "react-test-renderer"
 import { MemoryRouter } from "react-router"
-import Sidebar, { SidebarItem } from "./Sidebar"
+import Sidebar, { SidebarItem, groupByLabel } from "./Sidebar"
 import { oneResource, oneResourceView, twoResourceView } from "./testdata.test"
 import { mount } from "enzyme"
 import { ResourceView } from "./types"
@@ -53,6 +53,34 @@ describe("sidebar", () => {
     expect(sidebar.find("li Link.has-warnings")).toHaveLength(1)
   })
 
+  it("renders usage and usage warnings", () => {
+    let items = oneResourceView().Resources.map((res: any) => {
+      res.BuildHistory[0].Error = ""
+      res.ResourceInfo = {
+        PodCPU: "250m",
+        PodMemory: "118Mi",
+        PodUsageWarnings: [
+          'Container "vigoda" is using 92% of its memory limit',
+        ],
+      }
+      return new SidebarItem(res)
+    })
+    let sidebar = mount(
+      <MemoryRouter initialEntries={["/"]}>
+        <Sidebar
+          isClosed={false}
+          items={items}
+          selected=""
+          toggleSidebar={null}
+          resourceView={ResourceView.Log}
+          pathBuilder={pathBuilder}
+        />
+      </MemoryRouter>
+    )
+    expect(sidebar.find("li Link.has-warnings")).toHaveLength(1)
+    expect(sidebar.find(".resLink-usage").text()).toEqual("250m · 118Mi")
+  })
+
   it("renders list of resources", () => {
     let items = twoResourceView().Resources.map((res: any) => {
       res.BuildHistory[0].Error = ""
@@ -124,4 +152,17 @@ describe("sidebar", () => {
 
     expect(tree).toMatchSnapshot()
   })
+
+  it("groups resources by their first label", () => {
+    let items = twoResourceView().Resources.map(
+      (res: any) => new SidebarItem(res)
+    )
+    expect(groupByLabel(items).map(g => g.label)).toEqual([null])
+
+    items[1].labels = ["backend", "infra"]
+    let groups = groupByLabel(items)
+    expect(groups.map(g => g.label)).toEqual(["backend", "unlabeled"])
+    expect(groups[0].items.map(item => item.name)).toEqual([items[1].name])
+    expect(groups[1].items.map(item => item.name)).toEqual([items[0].name])
+  })
 })
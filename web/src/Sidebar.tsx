@@ -23,6 +23,8 @@ class SidebarItem {
   lastDeployTime: string
   pendingBuildSince: string
   currentBuildStartTime: string
+  usage: string
+  labels: string[]
 
   /**
    * Create a pared down SidebarItem from a ResourceView
@@ -35,6 +37,11 @@ class SidebarItem {
     this.lastDeployTime = res.LastDeployTime
     this.pendingBuildSince = res.PendingBuildSince
     this.currentBuildStartTime = res.CurrentBuild.StartTime
+    this.labels = res.Labels || []
+
+    let info = res.ResourceInfo
+    this.usage =
+      info && info.PodCPU ? `${info.PodCPU} · ${info.PodMemory}` : ""
   }
 }
 
@@ -76,6 +83,40 @@ function timeAgoFormatter(
   }
 }
 
+type SidebarGroup = {
+  label: string | null
+  items: SidebarItem[]
+}
+
+// Once any resource has a label, group resources by their first label, in
+// the order the groups first appear, with the unlabeled ones at the end.
+// Without labels, one group with no heading.
+function groupByLabel(items: SidebarItem[]): SidebarGroup[] {
+  if (!items.some(item => item.labels.length > 0)) {
+    return [{ label: null, items: items }]
+  }
+
+  let groups: SidebarGroup[] = []
+  let byLabel: { [label: string]: SidebarGroup } = {}
+  let unlabeled: SidebarGroup = { label: "unlabeled", items: [] }
+  items.forEach(item => {
+    if (item.labels.length === 0) {
+      unlabeled.items.push(item)
+      return
+    }
+    let label = item.labels[0]
+    if (!byLabel[label]) {
+      byLabel[label] = { label: label, items: [] }
+      groups.push(byLabel[label])
+    }
+    byLabel[label].items.push(item)
+  })
+  if (unlabeled.items.length > 0) {
+    groups.push(unlabeled)
+  }
+  return groups
+}
+
 class Sidebar extends PureComponent<SidebarProps> {
   render() {
     let pb = this.props.pathBuilder
@@ -100,7 +141,7 @@ class Sidebar extends PureComponent<SidebarProps> {
       </li>
     )
 
-    let listItems = this.props.items.map(item => {
+    let renderItem = (item: SidebarItem) => {
       let link = `/r/${item.name}`
       if (this.props.resourceView === ResourceView.Preview) {
         link += "/preview"
@@ -131,10 +172,28 @@ class Sidebar extends PureComponent<SidebarProps> {
               {willBuild || building ? <DotBuildingSvg /> : <DotSvg />}
             </span>
             <span className="resLink-name">{item.name}</span>
+            {item.usage ? (
+              <span className="resLink-usage" title="CPU · memory">
+                {item.usage}
+              </span>
+            ) : null}
             <span>{hasBuilt ? timeAgo : ""}</span>
           </Link>
         </li>
       )
+    }
+
+    let listItems = groupByLabel(this.props.items).map(group => {
+      let items = group.items.map(renderItem)
+      if (group.label === null) {
+        return items
+      }
+      return [
+        <li key={`label:${group.label}`} className="Sidebar-group">
+          {group.label}
+        </li>,
+        ...items,
+      ]
     })
 
     return (
@@ -156,4 +215,4 @@ class Sidebar extends PureComponent<SidebarProps> {
 
 export default Sidebar
 
-export { SidebarItem }
+export { SidebarItem, groupByLabel }
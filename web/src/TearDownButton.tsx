@@ -0,0 +1,43 @@
+import React, { PureComponent } from "react"
+import "./TearDownButton.scss"
+import { sessionHeaders } from "./session"
+
+type TearDownButtonProps = {
+  resourceName: string
+}
+
+// Deletes the resource's objects, and takes it out of the session.
+class TearDownButton extends PureComponent<TearDownButtonProps> {
+  constructor(props: TearDownButtonProps) {
+    super(props)
+    this.tearDown = this.tearDown.bind(this)
+  }
+
+  tearDown() {
+    let name = this.props.resourceName
+    if (
+      !window.confirm(`Delete the objects of ${name}, and stop watching it?`)
+    ) {
+      return
+    }
+
+    let url = `http://${window.location.host}/api/down`
+    fetch(url, {
+      method: "post",
+      headers: sessionHeaders(),
+      body: JSON.stringify({ name: name }),
+    })
+  }
+
+  render() {
+    return (
+      <span className="TearDownButton">
+        <button type="button" onClick={this.tearDown}>
+          Tear down
+        </button>
+      </span>
+    )
+  }
+}
+
+export default TearDownButton
@@ -2,6 +2,12 @@ import React, { PureComponent } from "react"
 import { ResourceView } from "./types"
 import "./TopBar.scss"
 import SailInfo from "./SailInfo"
+import TearDownButton from "./TearDownButton"
+import CancelBuildButton from "./CancelBuildButton"
+import TriggerButton from "./TriggerButton"
+import ClearErrorButton from "./ClearErrorButton"
+import EnableButton from "./EnableButton"
+import PauseButton from "./PauseButton"
 import TabNav from "./TabNav"
 
 type TopBarProps = {
@@ -11,6 +17,12 @@ type TopBarProps = {
   resourceView: ResourceView
   sailEnabled: boolean
   sailUrl: string
+  resourceName?: string
+  isBuilding?: boolean
+  hasError?: boolean
+  isDisabled?: boolean
+  isPaused?: boolean
+  isSessionPaused?: boolean
 }
 
 class TopBar extends PureComponent<TopBarProps> {
@@ -24,6 +36,31 @@ class TopBar extends PureComponent<TopBarProps> {
           resourceView={this.props.resourceView}
         />
         <span className="TopBar-spacer">&nbsp;</span>
+        {this.props.resourceName && this.props.isBuilding ? (
+          <CancelBuildButton resourceName={this.props.resourceName} />
+        ) : null}
+        {this.props.resourceName && !this.props.isBuilding ? (
+          <TriggerButton resourceName={this.props.resourceName} />
+        ) : null}
+        {this.props.resourceName && this.props.hasError ? (
+          <ClearErrorButton resourceName={this.props.resourceName} />
+        ) : null}
+        {/* While the whole session is paused, the button resumes the session. */}
+        <PauseButton
+          resourceName={
+            this.props.isSessionPaused ? undefined : this.props.resourceName
+          }
+          isPaused={Boolean(this.props.isPaused || this.props.isSessionPaused)}
+        />
+        {this.props.resourceName ? (
+          <EnableButton
+            resourceName={this.props.resourceName}
+            isDisabled={Boolean(this.props.isDisabled)}
+          />
+        ) : null}
+        {this.props.resourceName ? (
+          <TearDownButton resourceName={this.props.resourceName} />
+        ) : null}
         <SailInfo
           sailEnabled={this.props.sailEnabled}
           sailUrl={this.props.sailUrl}
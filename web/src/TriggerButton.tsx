@@ -0,0 +1,36 @@
+import React, { PureComponent } from "react"
+import "./TriggerButton.scss"
+import { sessionHeaders } from "./session"
+
+type TriggerButtonProps = {
+  resourceName: string
+}
+
+// Builds the resource's pending changes now, the same as [space] in the HUD.
+class TriggerButton extends PureComponent<TriggerButtonProps> {
+  constructor(props: TriggerButtonProps) {
+    super(props)
+    this.trigger = this.trigger.bind(this)
+  }
+
+  trigger() {
+    let url = `http://${window.location.host}/api/trigger`
+    fetch(url, {
+      method: "post",
+      headers: sessionHeaders(),
+      body: JSON.stringify({ names: [this.props.resourceName] }),
+    })
+  }
+
+  render() {
+    return (
+      <span className="TriggerButton">
+        <button type="button" onClick={this.trigger}>
+          Build now
+        </button>
+      </span>
+    )
+  }
+}
+
+export default TriggerButton
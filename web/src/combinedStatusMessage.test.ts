@@ -17,6 +17,15 @@ describe("combined status message", () => {
     expect(actual).toBe("Updating snack…")
   })
 
+  it("should show every resource that's building", () => {
+    let data = oneResourceBuilding()
+    let other = Object.assign({}, data[0], { Name: "vigoda" })
+    let resources = [...data, other].map(r => new StatusItem(r))
+    let actual = combinedStatusMessage(resources)
+
+    expect(actual).toBe("Updating snack, vigoda…")
+  })
+
   it("should show the most recent resource that failed to build", () => {
     let data = oneResourceFailedToBuild()
     let resources = data.map((r: any) => new StatusItem(r))
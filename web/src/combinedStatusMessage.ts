@@ -7,7 +7,8 @@ const combinedStatusMessage = (resources: Array<StatusItem>): string => {
   )
 
   if (buildingResources.length > 0) {
-    return `Updating ${buildingResources[0].name}…`
+    let names = buildingResources.map(r => r.name).join(", ")
+    return `Updating ${names}…`
   }
 
   let containerCrashedResources = resources.filter(
@@ -0,0 +1,20 @@
+// The Tilt server only accepts requests that change anything if they carry
+// the token that it puts in this cookie when the page loads. Web pages on
+// other origins can't read it.
+const sessionCookieName = "tilt_session"
+
+function sessionToken(): string {
+  for (let cookie of document.cookie.split(";")) {
+    let [name, value] = cookie.trim().split("=")
+    if (name === sessionCookieName) {
+      return decodeURIComponent(value || "")
+    }
+  }
+  return ""
+}
+
+function sessionHeaders(): Record<string, string> {
+  return { "X-Tilt-Session": sessionToken() }
+}
+
+export { sessionHeaders }
@@ -48,4 +48,14 @@ describe("combinedStatus", () => {
     res.RuntimeStatus = "ok"
     expect(combinedStatus(res)).toBe("error")
   })
+
+  it("canceled when last build canceled", () => {
+    const ts = Date.now().toLocaleString()
+    let res = emptyResource()
+    res.BuildHistory = [
+      { StartTime: ts, Error: "Build canceled", Canceled: true },
+    ]
+    res.RuntimeStatus = "ok"
+    expect(combinedStatus(res)).toBe("canceled")
+  })
 })
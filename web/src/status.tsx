@@ -2,8 +2,9 @@ import { isZeroTime } from "./time"
 
 // A combination of runtime status and build status over a resource view.
 // 1) If there's a current or pending build, this is "pending".
-// 2) Otherwise, if there's a build error or runtime error, this is "error".
-// 3) Otherwise, we fallback to runtime status.
+// 2) Otherwise, if the last build was canceled, this is "canceled".
+// 3) Otherwise, if there's a build error or runtime error, this is "error".
+// 4) Otherwise, we fallback to runtime status.
 function combinedStatus(res: any): string {
   let runtimeStatus = res.RuntimeStatus
   let currentBuild = res.CurrentBuild
@@ -18,6 +19,9 @@ function combinedStatus(res: any): string {
   if (hasCurrentBuild || hasPendingBuild) {
     return "pending"
   }
+  if (lastBuild && lastBuild.Canceled) {
+    return "canceled"
+  }
   if (lastBuildError) {
     return "error"
   }
@@ -27,7 +31,25 @@ function combinedStatus(res: any): string {
 function warnings(res: any): string[] {
   let buildHistory = res.BuildHistory || []
   let lastBuild = buildHistory[0]
-  return (lastBuild && lastBuild.Warnings) || []
+  let buildWarnings = (lastBuild && lastBuild.Warnings) || []
+  let usageWarnings =
+    (res.ResourceInfo && res.ResourceInfo.PodUsageWarnings) || []
+  let result = buildWarnings.concat(usageWarnings)
+  if (res.ResourceInfo && res.ResourceInfo.Drift) {
+    result = result.concat([
+      "Modified outside of Tilt. Press r in the terminal to re-apply:\n" +
+        res.ResourceInfo.Drift,
+    ])
+  }
+  if (!isZeroTime(res.CrashBackoffUntil)) {
+    let ms = Date.parse(res.CrashBackoffUntil) - Date.now()
+    let secs = Math.max(1, Math.round(ms / 1000))
+    result = result.concat([`crash backoff: retrying in ${secs}s`])
+  }
+  if (res.WaitingOnReadinessCheck && res.ReadinessCheckError) {
+    result = result.concat(["Not ready: " + res.ReadinessCheckError])
+  }
+  return result
 }
 
 export { combinedStatus, warnings }
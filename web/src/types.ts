@@ -6,6 +6,7 @@ export enum ResourceView {
 
 export type Build = {
   Error: {} | string | null
+  Canceled?: boolean
   StartTime: string
   Log: string
   FinishTime: string
@@ -0,0 +1,124 @@
+import applyViewPatch, { ViewPatch } from "./viewPatch"
+
+function emptyPatch(names: Array<string>): ViewPatch {
+  return {
+    ResourceNames: names,
+    LogTimestamps: false,
+    UpdatesPaused: false,
+    SailEnabled: false,
+    SailURL: "",
+  }
+}
+
+it("appends to logs", () => {
+  let view = {
+    Log: "hello\n",
+    Resources: [{ Name: "fe", CombinedLog: "fe\n", ResourceInfo: null }],
+  }
+  let patch = emptyPatch(["fe"])
+  patch.Log = { Text: "world\n" }
+  patch.Resources = [{ Name: "fe", CombinedLog: { Text: "more\n" } }]
+
+  let actual = applyViewPatch(view, patch)
+  expect(actual.Log).toEqual("hello\nworld\n")
+  expect(actual.Resources[0].CombinedLog).toEqual("fe\nmore\n")
+})
+
+it("keeps logs when the rest of the resource changes", () => {
+  let view = {
+    Log: "",
+    Resources: [
+      {
+        Name: "fe",
+        CombinedLog: "fe\n",
+        ResourceInfo: { PodName: "fe-1", PodLog: "pod\n" },
+      },
+    ],
+  }
+  let patch = emptyPatch(["fe"])
+  patch.Resources = [
+    {
+      Name: "fe",
+      Resource: {
+        Name: "fe",
+        CombinedLog: "",
+        ResourceInfo: { PodName: "fe-1", PodLog: "", PodRestarts: 1 },
+      },
+      RuntimeLog: { Text: "again\n" },
+    },
+  ]
+
+  let actual = applyViewPatch(view, patch)
+  expect(actual.Resources[0].CombinedLog).toEqual("fe\n")
+  expect(actual.Resources[0].ResourceInfo.PodLog).toEqual("pod\nagain\n")
+  expect(actual.Resources[0].ResourceInfo.PodRestarts).toEqual(1)
+})
+
+it("adds, removes, and resets resources", () => {
+  let view = {
+    Log: "",
+    Resources: [
+      { Name: "fe", CombinedLog: "fe\n", ResourceInfo: null },
+      { Name: "be", CombinedLog: "be\n", ResourceInfo: null },
+    ],
+  }
+  let patch = emptyPatch(["db", "fe"])
+  patch.Resources = [
+    {
+      Name: "db",
+      Resource: { Name: "db", CombinedLog: "", ResourceInfo: null },
+      CombinedLog: { Text: "db\n", Reset: true },
+    },
+    { Name: "fe", CombinedLog: { Text: "new\n", Reset: true } },
+  ]
+
+  let actual = applyViewPatch(view, patch)
+  expect(actual.Resources.map((r: any) => r.Name)).toEqual(["db", "fe"])
+  expect(actual.Resources[0].CombinedLog).toEqual("db\n")
+  expect(actual.Resources[1].CombinedLog).toEqual("new\n")
+})
+
+it("drops the start of truncated logs", () => {
+  let view = { Log: "héllo\nworld\n", Resources: [] }
+  let patch = emptyPatch([])
+  patch.Log = { Text: "again\n", Drop: 7 }
+
+  let actual = applyViewPatch(view, patch)
+  expect(actual.Log).toEqual("world\nagain\n")
+})
+
+it("keeps the logs of builds it already has", () => {
+  let view = {
+    Log: "",
+    Resources: [
+      {
+        Name: "fe",
+        CombinedLog: "",
+        BuildHistory: [{ StartTime: "1", Log: "build 1\n" }],
+        ResourceInfo: null,
+      },
+    ],
+  }
+  let patch = emptyPatch(["fe"])
+  patch.Resources = [
+    {
+      Name: "fe",
+      Resource: {
+        Name: "fe",
+        CombinedLog: "",
+        BuildHistory: [
+          { StartTime: "2", Log: "" },
+          { StartTime: "1", Log: "" },
+        ],
+        ResourceInfo: null,
+      },
+      NewBuildLogs: ["build 2\n"],
+    },
+  ]
+
+  let actual = applyViewPatch(view, patch)
+  expect(actual.Resources[0].BuildHistory).toEqual([
+    { StartTime: "2", Log: "build 2\n" },
+    { StartTime: "1", Log: "build 1\n" },
+  ])
+})
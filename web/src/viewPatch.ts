@@ -0,0 +1,147 @@
+// The server sends the whole view when we connect, then a patch with only
+// what changed for each change after that.
+// See internal/hud/webview/patch.go.
+
+type LogPatch = {
+  Text: string
+  // How many bytes (of UTF-8) to drop off the start of the log,
+  // because the server truncated it.
+  Drop?: number
+  // If true, Text is the whole log. Otherwise, it goes on the end.
+  Reset?: boolean
+}
+
+type ResourcePatch = {
+  Name: string
+  // The whole resource, without the logs below, if anything else changed.
+  Resource?: any
+  CombinedLog?: LogPatch
+  RuntimeLog?: LogPatch
+  CurrentBuildLog?: LogPatch
+  // The logs of the builds at the start of Resource.BuildHistory that we
+  // haven't seen. The other builds keep the logs we already have.
+  NewBuildLogs?: Array<string>
+}
+
+export type ViewPatch = {
+  Log?: LogPatch
+  ResourceNames: Array<string> | null
+  Resources?: Array<ResourcePatch>
+  LogTimestamps: boolean
+  UpdatesPaused: boolean
+  SailEnabled: boolean
+  SailURL: string
+}
+
+function applyLogPatch(log: string, patch?: LogPatch): string {
+  if (!patch) {
+    return log
+  }
+  if (patch.Reset) {
+    return patch.Text
+  }
+  return dropBytes(log, patch.Drop || 0) + patch.Text
+}
+
+// The server counts in UTF-8 bytes, and we count in UTF-16 code units.
+export function dropBytes(s: string, n: number): string {
+  let i = 0
+  while (n > 0 && i < s.length) {
+    let c = s.codePointAt(i) as number
+    if (c < 0x80) {
+      n -= 1
+    } else if (c < 0x800) {
+      n -= 2
+    } else if (c < 0x10000) {
+      n -= 3
+    } else {
+      n -= 4
+    }
+    i += c >= 0x10000 ? 2 : 1
+  }
+  return s.slice(i)
+}
+
+// Builds that we've already seen keep their logs, since they never change.
+function applyBuildHistoryPatch(
+  oldHistory: Array<any>,
+  newHistory: Array<any>,
+  newLogs: Array<string>
+): Array<any> {
+  let oldLogs: { [startTime: string]: string } = {}
+  for (let b of oldHistory) {
+    oldLogs[b.StartTime] = b.Log
+  }
+  return newHistory.map((b, i) => ({
+    ...b,
+    Log: i < newLogs.length ? newLogs[i] : oldLogs[b.StartTime] || "",
+  }))
+}
+
+// k8s resources keep their runtime log in PodLog; the others, in Log.
+function runtimeLogKey(info: any): string {
+  return "PodLog" in info ? "PodLog" : "Log"
+}
+
+function applyResourcePatch(old: any, patch: ResourcePatch): any {
+  let res = { ...(patch.Resource || old) }
+  let oldCurrentBuild = (old && old.CurrentBuild) || {}
+  let oldInfo = (old && old.ResourceInfo) || {}
+
+  if (patch.Resource) {
+    res.BuildHistory = applyBuildHistoryPatch(
+      (old && old.BuildHistory) || [],
+      patch.Resource.BuildHistory || [],
+      patch.NewBuildLogs || []
+    )
+  }
+  res.CombinedLog = applyLogPatch(
+    (old && old.CombinedLog) || "",
+    patch.CombinedLog
+  )
+  if (res.CurrentBuild) {
+    res.CurrentBuild = {
+      ...res.CurrentBuild,
+      Log: applyLogPatch(oldCurrentBuild.Log || "", patch.CurrentBuildLog),
+    }
+  }
+  if (res.ResourceInfo) {
+    let key = runtimeLogKey(res.ResourceInfo)
+    res.ResourceInfo = {
+      ...res.ResourceInfo,
+      [key]: applyLogPatch(oldInfo[key] || "", patch.RuntimeLog),
+    }
+  }
+  return res
+}
+
+function applyViewPatch(view: any, patch: ViewPatch): any {
+  let oldResources: { [name: string]: any } = {}
+  for (let res of view.Resources || []) {
+    oldResources[res.Name] = res
+  }
+  let patches: { [name: string]: ResourcePatch } = {}
+  for (let p of patch.Resources || []) {
+    patches[p.Name] = p
+  }
+
+  let resources = (patch.ResourceNames || []).map(name => {
+    let p = patches[name]
+    if (!p) {
+      return oldResources[name]
+    }
+    return applyResourcePatch(oldResources[name], p)
+  })
+
+  return {
+    ...view,
+    Log: applyLogPatch(view.Log || "", patch.Log),
+    Resources: resources,
+    LogTimestamps: patch.LogTimestamps,
+    UpdatesPaused: patch.UpdatesPaused,
+    SailEnabled: patch.SailEnabled,
+    SailURL: patch.SailURL,
+  }
+}
+
+export default applyViewPatch